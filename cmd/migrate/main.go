@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/solumD/tasks-service/internal/migrate"
+	"github.com/solumD/tasks-service/internal/repository/persistent"
+	"github.com/solumD/tasks-service/pkg/logger"
+
+	// чистый Go драйвер SQLite для -sql-driver=sqlite
+	_ "modernc.org/sqlite"
+)
+
+func main() {
+	from := flag.String("from", "", "source storage type: file, sql, eventsourced")
+	fromPath := flag.String("from-path", "", "source location: storage dir for file, dsn for sql, event log for eventsourced")
+	to := flag.String("to", "", "target storage type: file, sql, eventsourced")
+	toPath := flag.String("to-path", "", "target location, same format as -from-path")
+	sqlDriver := flag.String("sql-driver", "sqlite", "database/sql driver for sql storage")
+	batchSize := flag.Int("batch", 500, "tasks per import batch")
+	checkpointPath := flag.String("checkpoint", "migrate.checkpoint.json", "progress file used to resume an interrupted migration")
+	loggerLevel := flag.String("log-level", "info", "debug, info, warn, error")
+	flag.Parse()
+
+	log := logger.NewLogger(*loggerLevel)
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	err := run(ctx, log, *from, *fromPath, *to, *toPath, *sqlDriver, *batchSize, *checkpointPath)
+	if err != nil {
+		log.Error("migration failed", logger.Error(err))
+		os.Exit(1)
+	}
+}
+
+// run открывает оба хранилища, переносит задачи, проекты и комментарии и закрывает хранилища
+func run(ctx context.Context, log *slog.Logger, from, fromPath, to, toPath, sqlDriver string, batchSize int, checkpointPath string) error {
+	src, err := persistent.Open(ctx, from, fromPath, sqlDriver)
+	if err != nil {
+		return fmt.Errorf("failed to open source: %w", err)
+	}
+	defer src.Close()
+
+	dst, err := persistent.Open(ctx, to, toPath, sqlDriver)
+	if err != nil {
+		return fmt.Errorf("failed to open target: %w", err)
+	}
+	defer dst.Close()
+
+	m, err := migrate.New(src.Migrate(), dst.Migrate(), batchSize, checkpointPath, log)
+	if err != nil {
+		return err
+	}
+
+	res, err := m.Run(ctx)
+	if err != nil {
+		return err
+	}
+
+	log.Info("migration completed",
+		logger.Int("tasks", res.Tasks),
+		logger.Int("imported", res.Imported),
+		logger.Any("resumed", res.Resumed),
+		logger.Int("next id", res.NextID),
+		logger.String("checksum", res.Checksum),
+		logger.Int("projects", res.Projects),
+		logger.Int("next project id", res.NextProjectID),
+		logger.Int("comments", res.Comments),
+		logger.Int("next comment id", res.NextCommentID),
+	)
+
+	return nil
+}
@@ -2,24 +2,85 @@ package app
 
 import (
 	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"slices"
 	"syscall"
 	"time"
 
+	"github.com/solumD/tasks-service/internal/backup"
+	"github.com/solumD/tasks-service/internal/cdc"
 	"github.com/solumD/tasks-service/internal/config"
 	hnd "github.com/solumD/tasks-service/internal/handler"
 	v1 "github.com/solumD/tasks-service/internal/handler/v1"
+	"github.com/solumD/tasks-service/internal/purger"
+	raftcore "github.com/solumD/tasks-service/internal/raft"
+	"github.com/solumD/tasks-service/internal/replication"
+	"github.com/solumD/tasks-service/internal/repository/disabled"
+	"github.com/solumD/tasks-service/internal/repository/eventsourced"
+	filerepo "github.com/solumD/tasks-service/internal/repository/file"
 	inmemory "github.com/solumD/tasks-service/internal/repository/in_memory"
+	raftrepo "github.com/solumD/tasks-service/internal/repository/raft"
+	sqlrepo "github.com/solumD/tasks-service/internal/repository/sql"
 	"github.com/solumD/tasks-service/internal/usecase"
 	httpserver "github.com/solumD/tasks-service/pkg/http_server"
 	"github.com/solumD/tasks-service/pkg/logger"
+	"github.com/solumD/tasks-service/pkg/middleware"
+
+	// чистый Go драйвер SQLite для SQL_DRIVER=sqlite
+	_ "modernc.org/sqlite"
 )
 
 const (
 	shutdownTimeout = 10 * time.Second
 )
 
+// taskStore хранилище задач с поддержкой резервного копирования и репликации
+type taskStore interface {
+	usecase.TaskRepo
+	backup.Store
+	replication.FollowerStore
+}
+
+// projectStore хранилище проектов с поддержкой резервного копирования
+type projectStore interface {
+	usecase.ProjectRepo
+	backup.ProjectStore
+}
+
+// raftTaskStore хранилище на кластере Raft вместе с сервером запросов других узлов
+type raftTaskStore struct {
+	taskStore
+	repo   io.Closer
+	server interface {
+		Shutdown(ctx context.Context) error
+	}
+}
+
+// Close сначала перестает принимать запросы других узлов, затем останавливает узел
+func (s *raftTaskStore) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	if err := s.server.Shutdown(ctx); err != nil {
+		s.repo.Close()
+		return err
+	}
+
+	return s.repo.Close()
+}
+
+// worker фоновый процесс узла
+type worker interface {
+	Run(ctx context.Context)
+	Stop()
+}
+
 func InitAndRun(ctx context.Context) {
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
@@ -28,11 +89,123 @@ func InitAndRun(ctx context.Context) {
 
 	log := logger.NewLogger(cfg.LoggerLevel())
 
-	taskRepo := inmemory.NewTaskRepo()
-	taskUsecase := usecase.NewTaskUsecase(taskRepo, log)
-	handler := v1.NewHandler(taskUsecase, log)
+	// поток изменений открывается раньше хранилищ: узел Raft пишет в него изменения
+	// кластера уже при запуске, применяя свой журнал
+	changes, err := cdc.NewLog(cfg.CDCPath(), cfg.CDCRetention())
+	if err != nil {
+		log.Error("failed to open change stream", logger.Error(err))
+		os.Exit(1)
+	}
+	log.Info("opened change stream", logger.String("path", cfg.CDCPath()), logger.Int("head", changes.Head()))
+
+	taskRepo, revisionRepo, projectRepo, commentRepo, err := newRepos(ctx, cfg, changes, log)
+	if err != nil {
+		log.Error("failed to init repos", logger.Error(err))
+		os.Exit(1)
+	}
+	log.Info("initialized repos", logger.String("storage type", cfg.StorageType()))
+
+	// проекты и комментарии хранятся только на узле, поэтому в кластере они отключены
+	if cfg.StorageType() == config.StorageRaft || cfg.ReplicationRole() != config.ReplicationStandalone {
+		projectRepo, err = disableProjects(ctx, projectRepo)
+		if err != nil {
+			log.Error("failed to disable projects", logger.Error(err))
+			os.Exit(1)
+		}
+		log.Info("disabled projects", logger.String("storage type", cfg.StorageType()), logger.String("role", cfg.ReplicationRole()))
+
+		// уже созданные комментарии остаются в хранилище и снова доступны, когда узел работает один
+		if closer, ok := commentRepo.(io.Closer); ok {
+			if err := closer.Close(); err != nil {
+				log.Error("failed to close comment repo", logger.Error(err))
+				os.Exit(1)
+			}
+		}
+		commentRepo = disabled.NewCommentRepo()
+		log.Info("disabled comments")
+	}
+
+	// ведущий узел оборачивает хранилища и записывает их изменения в журнал репликации
+	var (
+		tasks     replication.LeaderStore = taskRepo
+		revisions usecase.RevisionRepo    = revisionRepo
+		node      v1.ReplicationUsecase   = replication.NewStandalone()
+		follower  worker
+	)
+
+	switch cfg.ReplicationRole() {
+	case config.ReplicationLeader:
+		leader, err := replication.NewLeader(taskRepo, revisionRepo, cfg.ReplicationLogSize(), log)
+		if err != nil {
+			log.Error("failed to init replication leader", logger.Error(err))
+			os.Exit(1)
+		}
+
+		tasks, revisions, node = leader, leader, leader
+	case config.ReplicationFollower:
+		f := replication.NewFollower(taskRepo, revisionRepo, cfg.ReplicationLeaderURL(), cfg.ReplicationPollWait(), log)
+		node, follower = f, f
+	}
+	log.Info("initialized replication", logger.String("role", cfg.ReplicationRole()))
+
+	// в поток изменений попадают изменения, сделанные через юзкейс, и восстановление
+	// из резервной копии. Узел Raft сам пишет в поток все примененные изменения кластера
+	var (
+		recorded usecase.TaskRepo = cdc.NewRecorder(tasks, changes, log)
+		restored backup.Store     = cdc.NewRestoreRecorder(tasks, changes, log)
+	)
+	if cfg.StorageType() == config.StorageRaft {
+		recorded, restored = tasks, tasks
+	}
+
+	backups, err := backup.NewManager(restored, projectRepo, cfg.BackupDir(), backup.Retention{
+		KeepLast:   cfg.BackupKeepLast(),
+		KeepDaily:  cfg.BackupKeepDaily(),
+		KeepWeekly: cfg.BackupKeepWeekly(),
+	}, log)
+	if err != nil {
+		log.Error("failed to init backups", logger.Error(err))
+		os.Exit(1)
+	}
+
+	rules := usecase.Rules{
+		Hierarchy: usecase.Hierarchy{
+			OnDelete:     cfg.TaskDeleteChildren(),
+			AutoComplete: cfg.TaskAutoCompleteParent(),
+		},
+		Dependencies: usecase.Dependencies{
+			BlockDone: cfg.TaskBlockDone(),
+		},
+		Workflow: cfg.TaskWorkflow(),
+	}
+	taskUsecase := usecase.NewTaskUsecase(recorded, revisions, projectRepo, commentRepo, rules, log)
+	projectUsecase := usecase.NewProjectUsecase(projectRepo, taskUsecase, cfg.ProjectDeleteTasks(), log)
+	commentUsecase := usecase.NewCommentUsecase(commentRepo, taskUsecase, log)
+	handler := v1.NewHandler(taskUsecase, projectUsecase, commentUsecase, backups, node, changes, log)
+
+	// ведомый узел не очищает корзину сам, а получает очистку из журнала ведущего
+	trashPurger := purger.New(taskUsecase, cfg.TrashRetention(), cfg.TrashPurgeInterval(), log)
+	if follower == nil {
+		trashPurger.Run(ctx)
+		log.Info("started trash purger", logger.String("retention", cfg.TrashRetention().String()))
+	} else {
+		follower.Run(ctx)
+		log.Info("started replication follower", logger.String("leader url", cfg.ReplicationLeaderURL()))
+	}
+
+	backupScheduler := backup.NewScheduler(backups, cfg.BackupInterval(), log)
+	backupScheduler.Run(ctx)
+	log.Info("started backup scheduler", logger.String("backup dir", cfg.BackupDir()))
+
+	changeTrimmer := cdc.NewTrimmer(changes, cfg.CDCTrimInterval(), log)
+	changeTrimmer.Run(ctx)
+	log.Info("started change stream trimmer", logger.String("retention", cfg.CDCRetention().String()))
 
-	r := hnd.NewRouter(ctx, log, handler)
+	var r http.Handler = hnd.NewRouter(ctx, log, handler, cfg.AdminToken())
+	if follower != nil {
+		// ведомый узел только читает, запросы на изменение уходят на ведущий
+		r = middleware.NewMWReadOnly(cfg.ReplicationLeaderURL(), log)(r)
+	}
 
 	server := httpserver.New(cfg.ServerAddr(), r)
 	server.Run()
@@ -48,8 +221,203 @@ func InitAndRun(ctx context.Context) {
 	shutdownCtx, cancelShutdownCtx := context.WithTimeout(context.Background(), shutdownTimeout)
 	defer cancelShutdownCtx()
 
-	err := server.Shutdown(shutdownCtx)
+	err = server.Shutdown(shutdownCtx)
 	if err != nil {
 		log.Error("error while shutting down server", logger.Error(err))
 	}
+
+	trashPurger.Stop()
+	backupScheduler.Stop()
+	changeTrimmer.Stop()
+
+	if follower != nil {
+		follower.Stop()
+	}
+
+	if closer, ok := revisionRepo.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			log.Error("error while closing revision repo", logger.Error(err))
+		}
+	}
+
+	if closer, ok := projectRepo.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			log.Error("error while closing project repo", logger.Error(err))
+		}
+	}
+
+	if closer, ok := commentRepo.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			log.Error("error while closing comment repo", logger.Error(err))
+		}
+	}
+
+	if closer, ok := taskRepo.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			log.Error("error while closing task repo", logger.Error(err))
+		}
+	}
+
+	// узел Raft пишет в поток, пока не остановлен, поэтому поток закрывается последним
+	if err := changes.Close(); err != nil {
+		log.Error("error while closing change stream", logger.Error(err))
+	}
+}
+
+// disableProjects заменяет хранилище проектов на отключенное. Узел с проектами
+// не запускается: они остались бы только на нем, а задачи ссылались бы на них
+func disableProjects(ctx context.Context, projectRepo projectStore) (projectStore, error) {
+	projects, err := projectRepo.GetProjects(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if closer, ok := projectRepo.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(projects) > 0 {
+		return nil, fmt.Errorf("projects are not replicated, delete %d projects before enabling raft or replication", len(projects))
+	}
+
+	return disabled.NewProjectRepo(), nil
+}
+
+// newRepos создает хранилища задач, их ревизий, проектов и комментариев согласно типу хранилища из конфига
+func newRepos(ctx context.Context, cfg *config.Config, changes *cdc.Log, log *slog.Logger) (taskStore, usecase.RevisionRepo, projectStore, usecase.CommentRepo, error) {
+	switch cfg.StorageType() {
+	case config.StorageInMemory:
+		return inmemory.NewTaskRepo(), inmemory.NewRevisionRepo(), inmemory.NewProjectRepo(), inmemory.NewCommentRepo(), nil
+	case config.StorageInMemorySharded:
+		taskRepo, err := inmemory.NewShardedTaskRepo(cfg.InMemoryShards())
+		if err != nil {
+			return nil, nil, nil, nil, err
+		}
+
+		return taskRepo, inmemory.NewRevisionRepo(), inmemory.NewProjectRepo(), inmemory.NewCommentRepo(), nil
+	case config.StorageFile:
+		taskRepo, err := filerepo.NewTaskRepo(cfg.FileStorageDir(), cfg.FileStorageCompactEvery())
+		if err != nil {
+			return nil, nil, nil, nil, err
+		}
+
+		revisionRepo, err := filerepo.NewRevisionRepo(filepath.Join(cfg.FileStorageDir(), filerepo.RevisionsFileName))
+		if err != nil {
+			taskRepo.Close()
+			return nil, nil, nil, nil, err
+		}
+
+		projectRepo, err := filerepo.NewProjectRepo(filepath.Join(cfg.FileStorageDir(), filerepo.ProjectsFileName))
+		if err != nil {
+			revisionRepo.Close()
+			taskRepo.Close()
+			return nil, nil, nil, nil, err
+		}
+
+		commentRepo, err := filerepo.NewCommentRepo(filepath.Join(cfg.FileStorageDir(), filerepo.CommentsFileName))
+		if err != nil {
+			projectRepo.Close()
+			revisionRepo.Close()
+			taskRepo.Close()
+			return nil, nil, nil, nil, err
+		}
+
+		return taskRepo, revisionRepo, projectRepo, commentRepo, nil
+	case config.StorageSQL:
+		taskRepo, err := sqlrepo.NewTaskRepo(ctx, cfg.SQLDriver(), cfg.SQLDSN())
+		if err != nil {
+			return nil, nil, nil, nil, err
+		}
+
+		return taskRepo, taskRepo.RevisionRepo(), taskRepo.ProjectRepo(), taskRepo.CommentRepo(), nil
+	case config.StorageEventSourced:
+		store, err := eventsourced.NewFileStore(cfg.EventStorePath())
+		if err != nil {
+			return nil, nil, nil, nil, err
+		}
+
+		taskRepo, err := eventsourced.NewTaskRepo(ctx, store)
+		if err != nil {
+			store.Close()
+			return nil, nil, nil, nil, err
+		}
+
+		// ревизии, проекты и комментарии хранятся в отдельных файлах рядом с журналом событий
+		revisionRepo, err := filerepo.NewRevisionRepo(filepath.Join(filepath.Dir(cfg.EventStorePath()), filerepo.RevisionsFileName))
+		if err != nil {
+			taskRepo.Close()
+			return nil, nil, nil, nil, err
+		}
+
+		projectRepo, err := filerepo.NewProjectRepo(filepath.Join(filepath.Dir(cfg.EventStorePath()), filerepo.ProjectsFileName))
+		if err != nil {
+			revisionRepo.Close()
+			taskRepo.Close()
+			return nil, nil, nil, nil, err
+		}
+
+		commentRepo, err := filerepo.NewCommentRepo(filepath.Join(filepath.Dir(cfg.EventStorePath()), filerepo.CommentsFileName))
+		if err != nil {
+			projectRepo.Close()
+			revisionRepo.Close()
+			taskRepo.Close()
+			return nil, nil, nil, nil, err
+		}
+
+		return taskRepo, revisionRepo, projectRepo, commentRepo, nil
+	case config.StorageRaft:
+		return newRaftRepos(cfg, changes, log)
+	default:
+		return nil, nil, nil, nil, fmt.Errorf("unknown storage type: %s", cfg.StorageType())
+	}
+}
+
+// newRaftRepos запускает узел кластера Raft и сервер, на котором он принимает запросы других узлов.
+// Изменения, примененные узлом, записываются в поток changes
+func newRaftRepos(cfg *config.Config, changes *cdc.Log, log *slog.Logger) (taskStore, usecase.RevisionRepo, projectStore, usecase.CommentRepo, error) {
+	var bootstrap []raftcore.Server
+
+	for _, peer := range cfg.RaftPeers() {
+		bootstrap = append(bootstrap, raftcore.Server{ID: peer.ID, Address: peer.Address})
+	}
+
+	// узел не из начального состава присоединяется к работающему кластеру
+	if !slices.ContainsFunc(bootstrap, func(s raftcore.Server) bool { return s.ID == cfg.RaftNodeID() }) {
+		bootstrap = nil
+	}
+
+	storage, err := raftcore.NewFileStorage(cfg.RaftDir())
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+
+	// проекты не реплицируются, проекты узла проверяются и отключаются при запуске
+	projectRepo, err := filerepo.NewProjectRepo(filepath.Join(cfg.RaftDir(), filerepo.ProjectsFileName))
+	if err != nil {
+		storage.Close()
+		return nil, nil, nil, nil, err
+	}
+
+	transport := raftcore.NewHTTPTransport()
+
+	taskRepo, err := raftrepo.NewTaskRepo(raftcore.Config{
+		ID:                cfg.RaftNodeID(),
+		Bootstrap:         bootstrap,
+		HeartbeatInterval: cfg.RaftElectionTimeout() / 10,
+		ElectionTimeout:   cfg.RaftElectionTimeout(),
+		SnapshotThreshold: uint64(cfg.RaftSnapshotThreshold()),
+	}, storage, transport, cdc.NewClusterRecorder(changes, log), log)
+	if err != nil {
+		projectRepo.Close()
+		storage.Close()
+		return nil, nil, nil, nil, err
+	}
+
+	server := httpserver.New(cfg.RaftBindAddr(), raftcore.NewHTTPHandler(taskRepo.Node(), transport, log))
+	server.Run()
+	log.Info("started raft server", logger.String("raft address", cfg.RaftBindAddr()))
+
+	return &raftTaskStore{taskStore: taskRepo, repo: taskRepo, server: server}, taskRepo.RevisionRepo(), projectRepo, disabled.NewCommentRepo(), nil
 }
@@ -0,0 +1,226 @@
+package backup
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/solumD/tasks-service/internal/fsync"
+	"github.com/solumD/tasks-service/internal/migrate"
+	"github.com/solumD/tasks-service/internal/model"
+)
+
+const (
+	// formatVersion версия формата копий. Копии версии 1 не содержат проектов
+	formatVersion = 2
+	// formatVersionTasksOnly версия копий, созданных до появления проектов в копиях
+	formatVersionTasksOnly = 1
+
+	filePrefix = "tasks-"
+	fileSuffix = ".json.gz"
+	// fileTimeLayout время создания в имени файла, по нему копии сортируются и ротируются
+	fileTimeLayout = "20060102T150405.000Z"
+)
+
+var (
+	ErrBackupNotFound    = errors.New("backup not found")
+	ErrInvalidBackupName = errors.New("invalid backup name")
+	ErrBackupCorrupted   = errors.New("backup is corrupted")
+)
+
+// file содержимое файла резервной копии до сжатия
+type file struct {
+	FormatVersion int           `json:"format_version"`
+	CreatedAt     time.Time     `json:"created_at"`
+	NextID        int           `json:"next_id"`
+	Checksum      string        `json:"checksum"`
+	Tasks         []*model.Task `json:"tasks"`
+	// NextProjectID, ProjectsChecksum и Projects пусты в копиях версии 1
+	NextProjectID    int              `json:"next_project_id,omitempty"`
+	ProjectsChecksum string           `json:"projects_checksum,omitempty"`
+	Projects         []*model.Project `json:"projects,omitempty"`
+}
+
+// FileName возвращает имя файла копии, созданной в момент createdAt
+func FileName(createdAt time.Time) string {
+	return filePrefix + createdAt.UTC().Format(fileTimeLayout) + fileSuffix
+}
+
+// parseFileName возвращает время создания копии по имени ее файла
+func parseFileName(name string) (time.Time, bool) {
+	stamp, ok := strings.CutPrefix(name, filePrefix)
+	if !ok {
+		return time.Time{}, false
+	}
+
+	stamp, ok = strings.CutSuffix(stamp, fileSuffix)
+	if !ok {
+		return time.Time{}, false
+	}
+
+	createdAt, err := time.Parse(fileTimeLayout, stamp)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return createdAt, true
+}
+
+// write сжимает копию и атомарно записывает ее в path через временный файл
+func write(path string, f *file) error {
+	tmpPath := path + ".tmp"
+
+	out, err := os.OpenFile(tmpPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to create backup: %w", err)
+	}
+
+	zw := gzip.NewWriter(out)
+
+	if err := json.NewEncoder(zw).Encode(f); err != nil {
+		out.Close()
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to write backup: %w", err)
+	}
+
+	if err := zw.Close(); err != nil {
+		out.Close()
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to compress backup: %w", err)
+	}
+
+	if err := out.Sync(); err != nil {
+		out.Close()
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to sync backup: %w", err)
+	}
+
+	if err := out.Close(); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to close backup: %w", err)
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("failed to rename backup: %w", err)
+	}
+
+	return fsync.Dir(filepath.Dir(path))
+}
+
+// Verify читает копию из path и проверяет ее целостность: контрольную сумму
+// gzip, формат, контрольные суммы задач и проектов, уникальность ID и счетчики ID
+func Verify(path string) (*model.BackupInfo, error) {
+	f, err := read(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.BackupInfo{
+		Name:      filepath.Base(path),
+		CreatedAt: f.CreatedAt,
+		Tasks:     len(f.Tasks),
+		NextID:    f.NextID,
+		Checksum:  f.Checksum,
+		Projects:  len(f.Projects),
+	}, nil
+}
+
+func read(path string) (*file, error) {
+	in, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrBackupNotFound
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to open backup: %w", err)
+	}
+	defer in.Close()
+
+	zr, err := gzip.NewReader(in)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrBackupCorrupted, err)
+	}
+
+	// контрольная сумма gzip проверяется только при чтении до конца потока
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrBackupCorrupted, err)
+	}
+
+	var f file
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrBackupCorrupted, err)
+	}
+
+	if f.FormatVersion != formatVersion && f.FormatVersion != formatVersionTasksOnly {
+		return nil, fmt.Errorf("%w: unsupported format version %d", ErrBackupCorrupted, f.FormatVersion)
+	}
+
+	if checksum := migrate.Checksum(f.Tasks); checksum != f.Checksum {
+		return nil, fmt.Errorf("%w: checksum mismatch", ErrBackupCorrupted)
+	}
+
+	seen := make(map[int]bool, len(f.Tasks))
+	for _, task := range f.Tasks {
+		if task == nil || task.ID <= 0 || seen[task.ID] || task.ID >= f.NextID {
+			return nil, fmt.Errorf("%w: invalid task ids", ErrBackupCorrupted)
+		}
+
+		seen[task.ID] = true
+	}
+
+	if f.FormatVersion == formatVersionTasksOnly {
+		return &f, nil
+	}
+
+	if checksum := migrate.ProjectChecksum(f.Projects); checksum != f.ProjectsChecksum {
+		return nil, fmt.Errorf("%w: projects checksum mismatch", ErrBackupCorrupted)
+	}
+
+	seenProjects := make(map[int]bool, len(f.Projects))
+	for _, project := range f.Projects {
+		if project == nil || project.ID <= 0 || seenProjects[project.ID] || project.ID >= f.NextProjectID {
+			return nil, fmt.Errorf("%w: invalid project ids", ErrBackupCorrupted)
+		}
+
+		seenProjects[project.ID] = true
+	}
+
+	return &f, nil
+}
+
+// list возвращает имена и время создания копий в dir, от новых к старым.
+// Файлы с другими именами не учитываются
+func list(dir string) ([]entry, error) {
+	dirEntries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backup dir: %w", err)
+	}
+
+	entries := make([]entry, 0, len(dirEntries))
+	for _, de := range dirEntries {
+		if de.IsDir() {
+			continue
+		}
+
+		createdAt, ok := parseFileName(de.Name())
+		if !ok {
+			continue
+		}
+
+		entries = append(entries, entry{name: de.Name(), createdAt: createdAt})
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].createdAt.After(entries[j].createdAt)
+	})
+
+	return entries, nil
+}
@@ -0,0 +1,283 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/solumD/tasks-service/internal/migrate"
+	"github.com/solumD/tasks-service/internal/model"
+	"github.com/solumD/tasks-service/pkg/logger"
+)
+
+// Store хранилище задач, которое умеет отдавать и заменять все задачи целиком
+type Store interface {
+	// DumpTasks возвращает все задачи, включая корзину, и ID следующей задачи на один момент времени
+	DumpTasks(ctx context.Context) ([]*model.Task, int, error)
+	// ReplaceTasks атомарно заменяет все задачи, включая корзину, на tasks. Счетчик ID
+	// не уменьшается, а следующая задача получит ID не меньше nextID
+	ReplaceTasks(ctx context.Context, tasks []*model.Task, nextID int) error
+}
+
+// ProjectStore хранилище проектов, которое умеет отдавать и заменять все проекты целиком
+type ProjectStore interface {
+	// DumpProjects возвращает все проекты и ID следующего проекта на один момент времени
+	DumpProjects(ctx context.Context) ([]*model.Project, int, error)
+	// ReplaceProjects заменяет все проекты на projects. Счетчик ID не уменьшается,
+	// а следующий проект получит ID не меньше nextID
+	ReplaceProjects(ctx context.Context, projects []*model.Project, nextID int) error
+}
+
+type manager struct {
+	store     Store
+	projects  ProjectStore
+	dir       string
+	retention Retention
+	log       *slog.Logger
+}
+
+// NewManager создает менеджер резервных копий хранилища задач store и хранилища
+// проектов projects в директории dir
+func NewManager(store Store, projects ProjectStore, dir string, retention Retention, log *slog.Logger) (*manager, error) {
+	if retention.KeepLast <= 0 {
+		return nil, fmt.Errorf("keep last must be positive, got %d", retention.KeepLast)
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create backup dir: %w", err)
+	}
+
+	return &manager{
+		store:     store,
+		projects:  projects,
+		dir:       dir,
+		retention: retention,
+		log:       log,
+	}, nil
+}
+
+// Backup записывает сжатую копию всех задач, включая корзину, и проектов и удаляет
+// копии, которые не нужно хранить по правилам ротации
+func (m *manager) Backup(ctx context.Context) (*model.BackupInfo, error) {
+	const fn = "manager.Backup"
+	log := m.log.With(logger.String("fn", fn))
+
+	tasks, nextID, err := m.store.DumpTasks(ctx)
+	if err != nil {
+		log.Error("failed to dump tasks", logger.Error(err))
+
+		return nil, err
+	}
+
+	sort.Slice(tasks, func(i, j int) bool {
+		return tasks[i].ID < tasks[j].ID
+	})
+
+	// проекты читаются после задач: проект, удаленный между чтениями,
+	// при восстановлении убирается из задач
+	projects, nextProjectID, err := m.projects.DumpProjects(ctx)
+	if err != nil {
+		log.Error("failed to dump projects", logger.Error(err))
+
+		return nil, err
+	}
+
+	f := &file{
+		FormatVersion:    formatVersion,
+		CreatedAt:        time.Now().UTC().Truncate(time.Millisecond),
+		NextID:           nextID,
+		Checksum:         migrate.Checksum(tasks),
+		Tasks:            tasks,
+		NextProjectID:    nextProjectID,
+		ProjectsChecksum: migrate.ProjectChecksum(projects),
+		Projects:         projects,
+	}
+
+	// имя содержит время с точностью до миллисекунды, копии, созданные в одну
+	// миллисекунду (например, копия перед восстановлением), не затирают друг друга
+	name := FileName(f.CreatedAt)
+	for {
+		if _, err := os.Stat(filepath.Join(m.dir, name)); errors.Is(err, os.ErrNotExist) {
+			break
+		}
+
+		f.CreatedAt = f.CreatedAt.Add(time.Millisecond)
+		name = FileName(f.CreatedAt)
+	}
+
+	if err := write(filepath.Join(m.dir, name), f); err != nil {
+		log.Error("failed to write backup", logger.Error(err))
+
+		return nil, err
+	}
+
+	log.Info("created backup",
+		logger.String("backup", name),
+		logger.Int("tasks count", len(tasks)),
+		logger.Int("projects count", len(projects)),
+	)
+
+	// копия уже записана, поэтому ошибка ротации не делает ее неуспешной
+	if err := m.rotate(); err != nil {
+		log.Error("failed to rotate backups", logger.Error(err))
+	}
+
+	return &model.BackupInfo{
+		Name:      name,
+		CreatedAt: f.CreatedAt,
+		Tasks:     len(tasks),
+		NextID:    nextID,
+		Checksum:  f.Checksum,
+		Projects:  len(projects),
+	}, nil
+}
+
+// RestoreBackup проверяет копию name из директории копий и заменяет ею все проекты
+// и задачи. Перед заменой делается копия текущего состояния. Версии не уменьшаются:
+// задача или проект, которые есть в хранилище, получают версию больше текущей,
+// чтобы старые ETag не совпали с восстановленными. Копия версии 1 не содержит
+// проектов, поэтому текущие проекты остаются. Задача, проекта которой нет после
+// восстановления, восстанавливается вне проектов
+func (m *manager) RestoreBackup(ctx context.Context, name string) (*model.BackupInfo, error) {
+	const fn = "manager.RestoreBackup"
+	log := m.log.With(logger.String("fn", fn))
+
+	if _, ok := parseFileName(name); !ok || filepath.Base(name) != name {
+		return nil, ErrInvalidBackupName
+	}
+
+	f, err := read(filepath.Join(m.dir, name))
+	if err != nil {
+		log.Error("failed to read backup", logger.String("backup", name), logger.Error(err))
+
+		return nil, err
+	}
+
+	if _, err := m.Backup(ctx); err != nil {
+		return nil, fmt.Errorf("failed to back up current state: %w", err)
+	}
+
+	// проекты заменяются раньше задач, чтобы задачи не ссылались на отсутствующие проекты
+	projects, err := m.restoreProjects(ctx, f)
+	if err != nil {
+		log.Error("failed to replace projects", logger.Error(err))
+
+		return nil, err
+	}
+
+	current, _, err := m.store.DumpTasks(ctx)
+	if err != nil {
+		log.Error("failed to dump tasks", logger.Error(err))
+
+		return nil, err
+	}
+
+	versions := make(map[int]int, len(current))
+	for _, task := range current {
+		versions[task.ID] = task.Version
+	}
+
+	tasks := make([]*model.Task, 0, len(f.Tasks))
+	detached := 0
+	for _, task := range f.Tasks {
+		restored := task.Clone()
+
+		if version, ok := versions[task.ID]; ok && version >= restored.Version {
+			restored.Version = version + 1
+		}
+
+		if restored.ProjectID != 0 && !projects[restored.ProjectID] {
+			restored.ProjectID = 0
+			detached++
+		}
+
+		tasks = append(tasks, restored)
+	}
+
+	if detached > 0 {
+		log.Warn("restored tasks outside of missing projects", logger.Int("tasks count", detached))
+	}
+
+	if err := m.store.ReplaceTasks(ctx, tasks, f.NextID); err != nil {
+		log.Error("failed to replace tasks", logger.Error(err))
+
+		return nil, err
+	}
+
+	log.Info("restored backup",
+		logger.String("backup", name),
+		logger.Int("tasks count", len(tasks)),
+		logger.Int("projects count", len(projects)),
+	)
+
+	return &model.BackupInfo{
+		Name:      name,
+		CreatedAt: f.CreatedAt,
+		Tasks:     len(f.Tasks),
+		NextID:    f.NextID,
+		Checksum:  f.Checksum,
+		Projects:  len(f.Projects),
+	}, nil
+}
+
+// restoreProjects заменяет проекты проектами копии f, копия версии 1 проекты
+// не меняет. Возвращает ID проектов, которые есть после восстановления
+func (m *manager) restoreProjects(ctx context.Context, f *file) (map[int]bool, error) {
+	current, _, err := m.projects.DumpProjects(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if f.FormatVersion == formatVersionTasksOnly {
+		ids := make(map[int]bool, len(current))
+		for _, project := range current {
+			ids[project.ID] = true
+		}
+
+		return ids, nil
+	}
+
+	versions := make(map[int]int, len(current))
+	for _, project := range current {
+		versions[project.ID] = project.Version
+	}
+
+	ids := make(map[int]bool, len(f.Projects))
+	projects := make([]*model.Project, 0, len(f.Projects))
+	for _, project := range f.Projects {
+		restored := project.Clone()
+
+		if version, ok := versions[project.ID]; ok && version >= restored.Version {
+			restored.Version = version + 1
+		}
+
+		ids[restored.ID] = true
+		projects = append(projects, restored)
+	}
+
+	if err := m.projects.ReplaceProjects(ctx, projects, f.NextProjectID); err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+func (m *manager) rotate() error {
+	entries, err := list(m.dir)
+	if err != nil {
+		return err
+	}
+
+	for _, e := range expired(entries, m.retention) {
+		if err := os.Remove(filepath.Join(m.dir, e.name)); err != nil {
+			return fmt.Errorf("failed to remove backup %s: %w", e.name, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,58 @@
+package backup
+
+import (
+	"fmt"
+	"time"
+)
+
+// Retention сколько копий хранить при ротации. Копия остается, если она среди
+// KeepLast последних, последняя за один из KeepDaily последних дней или
+// последняя за одну из KeepWeekly последних недель (ISO, UTC)
+type Retention struct {
+	KeepLast   int
+	KeepDaily  int
+	KeepWeekly int
+}
+
+type entry struct {
+	name      string
+	createdAt time.Time
+}
+
+// expired возвращает копии, которые не нужно хранить. entries отсортированы от новых к старым
+func expired(entries []entry, retention Retention) []entry {
+	keep := make(map[string]bool, len(entries))
+
+	for i := 0; i < len(entries) && i < retention.KeepLast; i++ {
+		keep[entries[i].name] = true
+	}
+
+	days := make(map[string]bool)
+	weeks := make(map[string]bool)
+
+	for _, e := range entries {
+		createdAt := e.createdAt.UTC()
+
+		day := createdAt.Format(time.DateOnly)
+		if !days[day] && len(days) < retention.KeepDaily {
+			days[day] = true
+			keep[e.name] = true
+		}
+
+		year, week := createdAt.ISOWeek()
+		weekKey := fmt.Sprintf("%d-%d", year, week)
+		if !weeks[weekKey] && len(weeks) < retention.KeepWeekly {
+			weeks[weekKey] = true
+			keep[e.name] = true
+		}
+	}
+
+	result := make([]entry, 0)
+	for _, e := range entries {
+		if !keep[e.name] {
+			result = append(result, e)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,84 @@
+package backup
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/solumD/tasks-service/internal/model"
+	"github.com/solumD/tasks-service/pkg/logger"
+)
+
+// Backuper интерфейс создания резервной копии
+type Backuper interface {
+	Backup(ctx context.Context) (*model.BackupInfo, error)
+}
+
+type scheduler struct {
+	backuper Backuper
+	interval time.Duration
+	log      *slog.Logger
+
+	cancel context.CancelFunc
+	done   chan struct{}
+	once   *sync.Once
+}
+
+// NewScheduler создает фоновое резервное копирование каждые interval
+func NewScheduler(backuper Backuper, interval time.Duration, log *slog.Logger) *scheduler {
+	return &scheduler{
+		backuper: backuper,
+		interval: interval,
+		log:      log,
+		done:     make(chan struct{}),
+		once:     &sync.Once{},
+	}
+}
+
+// Run запускает резервное копирование в отдельной горутине. Первая копия делается
+// через interval, а не сразу, чтобы частые перезапуски не вытесняли старые копии
+func (s *scheduler) Run(ctx context.Context) {
+	ctx, s.cancel = context.WithCancel(ctx)
+
+	go func() {
+		defer close(s.done)
+
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			s.backup(ctx)
+		}
+	}()
+}
+
+// Stop останавливает резервное копирование и ждет завершения текущей копии
+func (s *scheduler) Stop() {
+	s.once.Do(func() {
+		if s.cancel == nil {
+			close(s.done)
+			return
+		}
+
+		s.cancel()
+	})
+
+	<-s.done
+}
+
+func (s *scheduler) backup(ctx context.Context) {
+	const fn = "scheduler.backup"
+	log := s.log.With(logger.String("fn", fn))
+
+	// ошибки не останавливают копирование, следующий проход повторит попытку
+	if _, err := s.backuper.Backup(ctx); err != nil {
+		log.Error("failed to create backup", logger.Error(err))
+	}
+}
@@ -0,0 +1,450 @@
+package tests
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/solumD/tasks-service/internal/backup"
+	"github.com/solumD/tasks-service/internal/migrate"
+	"github.com/solumD/tasks-service/internal/model"
+	"github.com/solumD/tasks-service/internal/repository/eventsourced"
+	filerepo "github.com/solumD/tasks-service/internal/repository/file"
+	inmemory "github.com/solumD/tasks-service/internal/repository/in_memory"
+	sqlrepo "github.com/solumD/tasks-service/internal/repository/sql"
+	"github.com/solumD/tasks-service/pkg/logger"
+
+	_ "modernc.org/sqlite"
+)
+
+// store хранилище, которое умеет и резервное копирование, и обычные изменения
+type store interface {
+	backup.Store
+	CreateTask(ctx context.Context, task *model.Task) (int, error)
+	UpdateTask(ctx context.Context, task *model.Task) error
+	DeleteTask(ctx context.Context, id int, version int) (*model.Task, error)
+}
+
+// stores хранилища задач. open для постоянных хранилищ повторно открывает
+// данные в той же директории
+var stores = []struct {
+	name       string
+	persistent bool
+	open       func(t *testing.T, dir string) store
+}{
+	{
+		name: "in_memory",
+		open: func(t *testing.T, _ string) store {
+			return inmemory.NewTaskRepo()
+		},
+	},
+	{
+		name: "in_memory_sharded",
+		open: func(t *testing.T, _ string) store {
+			r, _ := inmemory.NewShardedTaskRepo(4)
+			return r
+		},
+	},
+	{
+		name:       "file",
+		persistent: true,
+		open: func(t *testing.T, dir string) store {
+			r, err := filerepo.NewTaskRepo(dir, 1000)
+			if err != nil {
+				t.Fatalf("failed to open file repo: %v", err)
+			}
+			t.Cleanup(func() { r.Close() })
+
+			return r
+		},
+	},
+	{
+		name:       "sql",
+		persistent: true,
+		open: func(t *testing.T, dir string) store {
+			dsn := "file:" + filepath.Join(dir, "tasks.db") + "?_pragma=busy_timeout(5000)"
+
+			r, err := sqlrepo.NewTaskRepo(context.Background(), "sqlite", dsn)
+			if err != nil {
+				t.Fatalf("failed to open sql repo: %v", err)
+			}
+			t.Cleanup(func() { r.Close() })
+
+			return r
+		},
+	},
+	{
+		name:       "eventsourced",
+		persistent: true,
+		open: func(t *testing.T, dir string) store {
+			es, err := eventsourced.NewFileStore(filepath.Join(dir, "events.log"))
+			if err != nil {
+				t.Fatalf("failed to open event store: %v", err)
+			}
+
+			r, err := eventsourced.NewTaskRepo(context.Background(), es)
+			if err != nil {
+				t.Fatalf("failed to open eventsourced repo: %v", err)
+			}
+			t.Cleanup(func() { r.Close() })
+
+			return r
+		},
+	},
+}
+
+func newManager(t *testing.T, s backup.Store, dir string) backup.Backuper {
+	t.Helper()
+
+	m, err := backup.NewManager(s, inmemory.NewProjectRepo(), dir, backup.Retention{KeepLast: 100}, logger.NewMockLogger())
+	if err != nil {
+		t.Fatalf("failed to init backup manager: %v", err)
+	}
+
+	return m
+}
+
+func dump(t *testing.T, s backup.Store) ([]*model.Task, int) {
+	t.Helper()
+
+	tasks, nextID, err := s.DumpTasks(context.Background())
+	if err != nil {
+		t.Fatalf("failed to dump tasks: %v", err)
+	}
+
+	sort.Slice(tasks, func(i, j int) bool {
+		return tasks[i].ID < tasks[j].ID
+	})
+
+	return tasks, nextID
+}
+
+func TestBackupAndRestore(t *testing.T) {
+	for _, tt := range stores {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			dataDir := t.TempDir()
+			backupDir := t.TempDir()
+
+			s := tt.open(t, dataDir)
+
+			for _, title := range []string{"Task1", "Task2", "Task3"} {
+				if _, err := s.CreateTask(ctx, &model.Task{Title: title}); err != nil {
+					t.Fatalf("failed to create task: %v", err)
+				}
+			}
+
+			if _, err := s.DeleteTask(ctx, 3, 0); err != nil {
+				t.Fatalf("failed to delete task: %v", err)
+			}
+
+			m := newManager(t, s, backupDir)
+
+			info, err := m.Backup(ctx)
+			if err != nil {
+				t.Fatalf("failed to back up: %v", err)
+			}
+
+			if info.Tasks != 3 || info.NextID != 4 {
+				t.Fatalf("expected backup of 3 tasks with next id 4, got %+v", info)
+			}
+
+			// после копии задача 1 меняется, а задача 4 создается
+			if err := s.UpdateTask(ctx, &model.Task{ID: 1, Title: "Task1 updated"}); err != nil {
+				t.Fatalf("failed to update task: %v", err)
+			}
+
+			if _, err := s.CreateTask(ctx, &model.Task{Title: "Task4"}); err != nil {
+				t.Fatalf("failed to create task: %v", err)
+			}
+
+			beforeRestore, _ := dump(t, s)
+
+			restorer := m.(interface {
+				RestoreBackup(ctx context.Context, name string) (*model.BackupInfo, error)
+			})
+
+			if _, err := restorer.RestoreBackup(ctx, info.Name); err != nil {
+				t.Fatalf("failed to restore: %v", err)
+			}
+
+			if tt.persistent {
+				s = tt.open(t, dataDir)
+			}
+
+			tasks, nextID := dump(t, s)
+			if len(tasks) != 3 || tasks[0].Title != "Task1" || tasks[2].DeletedAt == nil {
+				t.Fatalf("expected tasks from backup, got %v", tasks)
+			}
+
+			// ID задачи, созданной после копии, не выдается повторно
+			if nextID != 5 {
+				t.Fatalf("expected next id 5 after restore, got %d", nextID)
+			}
+
+			// версии не уменьшаются, поэтому старые ETag не совпадут с восстановленной задачей
+			if tasks[0].Version <= beforeRestore[0].Version {
+				t.Fatalf("expected restored task version above %d, got %d", beforeRestore[0].Version, tasks[0].Version)
+			}
+
+			if err := s.UpdateTask(ctx, &model.Task{ID: 1, Title: "stale", Version: beforeRestore[0].Version}); err == nil {
+				t.Fatalf("expected stale version to conflict after restore")
+			}
+
+			entries, _ := os.ReadDir(backupDir)
+			if len(entries) != 2 {
+				t.Fatalf("expected backup of current state before restore, got %d files", len(entries))
+			}
+		})
+	}
+}
+
+func TestBackupAndRestoreProjects(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	s := inmemory.NewTaskRepo()
+	projects := inmemory.NewProjectRepo()
+
+	for _, name := range []string{"Home", "Work"} {
+		if _, err := projects.CreateProject(ctx, &model.Project{Name: name}); err != nil {
+			t.Fatalf("failed to create project: %v", err)
+		}
+	}
+
+	for _, task := range []*model.Task{{Title: "Task1", ProjectID: 1}, {Title: "Task2", ProjectID: 2}} {
+		if _, err := s.CreateTask(ctx, task); err != nil {
+			t.Fatalf("failed to create task: %v", err)
+		}
+	}
+
+	m, err := backup.NewManager(s, projects, dir, backup.Retention{KeepLast: 100}, logger.NewMockLogger())
+	if err != nil {
+		t.Fatalf("failed to init backup manager: %v", err)
+	}
+
+	info, err := m.Backup(ctx)
+	if err != nil {
+		t.Fatalf("failed to back up: %v", err)
+	}
+
+	if info.Projects != 2 {
+		t.Fatalf("expected backup of 2 projects, got %+v", info)
+	}
+
+	// после копии проект 1 меняется, проект 2 удаляется, а проект 3 создается
+	if err := projects.UpdateProject(ctx, &model.Project{ID: 1, Name: "House"}); err != nil {
+		t.Fatalf("failed to update project: %v", err)
+	}
+
+	if err := projects.DeleteProject(ctx, 2, 0); err != nil {
+		t.Fatalf("failed to delete project: %v", err)
+	}
+
+	if _, err := projects.CreateProject(ctx, &model.Project{Name: "Later"}); err != nil {
+		t.Fatalf("failed to create project: %v", err)
+	}
+
+	if _, err := m.RestoreBackup(ctx, info.Name); err != nil {
+		t.Fatalf("failed to restore: %v", err)
+	}
+
+	restored, nextProjectID, err := projects.DumpProjects(ctx)
+	if err != nil {
+		t.Fatalf("failed to dump projects: %v", err)
+	}
+
+	if len(restored) != 2 || restored[0].Name != "Home" || restored[1].Name != "Work" {
+		t.Fatalf("expected projects from backup, got %v", restored)
+	}
+
+	// версии проектов не уменьшаются, а ID проекта, созданного после копии, не выдается повторно
+	if restored[0].Version != 3 || nextProjectID != 4 {
+		t.Fatalf("expected project version 3 and next project id 4, got version %d and next id %d", restored[0].Version, nextProjectID)
+	}
+
+	tasks, _ := dump(t, s)
+	if tasks[0].ProjectID != 1 || tasks[1].ProjectID != 2 {
+		t.Fatalf("expected tasks to stay in their projects, got projects %d and %d", tasks[0].ProjectID, tasks[1].ProjectID)
+	}
+}
+
+// TestRestoreTasksOnlyBackup проверяет копию версии 1, созданную до появления
+// проектов в копиях: текущие проекты остаются, а задачи из отсутствующих
+// проектов восстанавливаются вне проектов
+func TestRestoreTasksOnlyBackup(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	tasks := []*model.Task{
+		{ID: 1, Title: "Task1", ProjectID: 1, Version: 1},
+		{ID: 2, Title: "Task2", ProjectID: 7, Version: 1},
+	}
+
+	name := backup.FileName(time.Now().Add(-time.Hour))
+	writeBackup(t, filepath.Join(dir, name), map[string]any{
+		"format_version": 1,
+		"created_at":     time.Now().Add(-time.Hour).UTC(),
+		"next_id":        3,
+		"checksum":       migrate.Checksum(tasks),
+		"tasks":          tasks,
+	})
+
+	s := inmemory.NewTaskRepo()
+	projects := inmemory.NewProjectRepo()
+	if _, err := projects.CreateProject(ctx, &model.Project{Name: "Home"}); err != nil {
+		t.Fatalf("failed to create project: %v", err)
+	}
+
+	m, _ := backup.NewManager(s, projects, dir, backup.Retention{KeepLast: 100}, logger.NewMockLogger())
+
+	if _, err := m.RestoreBackup(ctx, name); err != nil {
+		t.Fatalf("failed to restore: %v", err)
+	}
+
+	current, _, _ := projects.DumpProjects(ctx)
+	if len(current) != 1 || current[0].Name != "Home" {
+		t.Fatalf("expected current projects to stay, got %v", current)
+	}
+
+	restored, _ := dump(t, s)
+	if restored[0].ProjectID != 1 || restored[1].ProjectID != 0 {
+		t.Fatalf("expected task 2 outside of missing project, got projects %d and %d", restored[0].ProjectID, restored[1].ProjectID)
+	}
+}
+
+// writeBackup записывает сжатую копию с содержимым content
+func writeBackup(t *testing.T, path string, content any) {
+	t.Helper()
+
+	out, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("failed to create backup: %v", err)
+	}
+	defer out.Close()
+
+	zw := gzip.NewWriter(out)
+	if err := json.NewEncoder(zw).Encode(content); err != nil {
+		t.Fatalf("failed to write backup: %v", err)
+	}
+
+	if err := zw.Close(); err != nil {
+		t.Fatalf("failed to compress backup: %v", err)
+	}
+}
+
+func TestRestoreRejectsBadBackups(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	s := inmemory.NewTaskRepo()
+	if _, err := s.CreateTask(ctx, &model.Task{Title: "Task1"}); err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+
+	m, _ := backup.NewManager(s, inmemory.NewProjectRepo(), dir, backup.Retention{KeepLast: 100}, logger.NewMockLogger())
+
+	info, err := m.Backup(ctx)
+	if err != nil {
+		t.Fatalf("failed to back up: %v", err)
+	}
+
+	data, _ := os.ReadFile(filepath.Join(dir, info.Name))
+	corrupted := append([]byte(nil), data...)
+	corrupted[len(corrupted)/2] ^= 0xff
+
+	corruptedName := backup.FileName(time.Now().Add(time.Hour))
+	if err := os.WriteFile(filepath.Join(dir, corruptedName), corrupted, 0o644); err != nil {
+		t.Fatalf("failed to write corrupted backup: %v", err)
+	}
+
+	truncatedName := backup.FileName(time.Now().Add(2 * time.Hour))
+	if err := os.WriteFile(filepath.Join(dir, truncatedName), data[:len(data)-8], 0o644); err != nil {
+		t.Fatalf("failed to write truncated backup: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		backup      string
+		expectedErr error
+	}{
+		{name: "corrupted", backup: corruptedName, expectedErr: backup.ErrBackupCorrupted},
+		{name: "truncated", backup: truncatedName, expectedErr: backup.ErrBackupCorrupted},
+		{name: "missing", backup: backup.FileName(time.Now().Add(-time.Hour)), expectedErr: backup.ErrBackupNotFound},
+		{name: "path traversal", backup: "../" + info.Name, expectedErr: backup.ErrInvalidBackupName},
+		{name: "foreign file", backup: "tasks.db", expectedErr: backup.ErrInvalidBackupName},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := m.RestoreBackup(ctx, tt.backup); !errors.Is(err, tt.expectedErr) {
+				t.Fatalf("expected %v, got %v", tt.expectedErr, err)
+			}
+
+			if _, err := backup.Verify(filepath.Join(dir, tt.backup)); tt.expectedErr != backup.ErrInvalidBackupName && !errors.Is(err, tt.expectedErr) {
+				t.Fatalf("expected verify to return %v, got %v", tt.expectedErr, err)
+			}
+		})
+	}
+
+	if _, err := backup.Verify(filepath.Join(dir, info.Name)); err != nil {
+		t.Fatalf("expected intact backup to verify, got %v", err)
+	}
+}
+
+func TestRotation(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	// копии каждые 6 часов за 3 недели, самая новая - час назад
+	now := time.Now().UTC()
+	names := make([]string, 0)
+	for i := 0; i < 4*21; i++ {
+		name := backup.FileName(now.Add(-time.Hour - time.Duration(i)*6*time.Hour))
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o644); err != nil {
+			t.Fatalf("failed to write backup: %v", err)
+		}
+
+		names = append(names, name)
+	}
+
+	// чужие файлы ротация не трогает
+	if err := os.WriteFile(filepath.Join(dir, "notes.txt"), nil, 0o644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	retention := backup.Retention{KeepLast: 3, KeepDaily: 2, KeepWeekly: 2}
+
+	m, _ := backup.NewManager(inmemory.NewTaskRepo(), inmemory.NewProjectRepo(), dir, retention, logger.NewMockLogger())
+
+	info, err := m.Backup(ctx)
+	if err != nil {
+		t.Fatalf("failed to back up: %v", err)
+	}
+
+	entries, _ := os.ReadDir(dir)
+	left := make(map[string]bool, len(entries))
+	for _, e := range entries {
+		left[e.Name()] = true
+	}
+
+	if !left["notes.txt"] || !left[info.Name] || !left[names[0]] || !left[names[1]] {
+		t.Fatalf("expected new backup, two next ones and foreign file to be kept, got %v", left)
+	}
+
+	// 3 последних, не больше 2 дневных и 2 недельных (пересекаются с последними) и чужой файл
+	if len(left) > 3+2+2+1 || len(left) < 3+1+1 {
+		t.Fatalf("unexpected number of files after rotation: %d (%v)", len(left), left)
+	}
+
+	if left[names[len(names)-1]] && len(names) > 4*14 {
+		// самая старая копия старше двух недель и не может попасть в недельные
+		t.Fatalf("expected oldest backup to be rotated out")
+	}
+}
@@ -0,0 +1,94 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/solumD/tasks-service/internal/backup"
+	"github.com/solumD/tasks-service/internal/model"
+	"github.com/solumD/tasks-service/pkg/logger"
+)
+
+type mockBackuper struct {
+	mu     sync.Mutex
+	calls  int
+	err    error
+	called chan struct{}
+}
+
+func (m *mockBackuper) Backup(_ context.Context) (*model.BackupInfo, error) {
+	m.mu.Lock()
+	m.calls++
+	m.mu.Unlock()
+
+	select {
+	case m.called <- struct{}{}:
+	default:
+	}
+
+	return &model.BackupInfo{}, m.err
+}
+
+func TestSchedulerRunsPeriodically(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{name: "success"},
+		{name: "errors do not stop scheduler", err: errors.New("disk full")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := &mockBackuper{err: tt.err, called: make(chan struct{})}
+
+			s := backup.NewScheduler(b, time.Millisecond, logger.NewMockLogger())
+			s.Run(context.Background())
+
+			for i := 0; i < 3; i++ {
+				select {
+				case <-b.called:
+				case <-time.After(time.Second):
+					t.Fatalf("expected backup %d to run", i+1)
+				}
+			}
+
+			s.Stop()
+
+			b.mu.Lock()
+			calls := b.calls
+			b.mu.Unlock()
+
+			time.Sleep(5 * time.Millisecond)
+
+			b.mu.Lock()
+			defer b.mu.Unlock()
+
+			if b.calls != calls {
+				t.Fatalf("expected no backups after Stop, got %d more", b.calls-calls)
+			}
+		})
+	}
+}
+
+func TestSchedulerWaitsForFirstInterval(t *testing.T) {
+	b := &mockBackuper{called: make(chan struct{}, 1)}
+
+	s := backup.NewScheduler(b, time.Hour, logger.NewMockLogger())
+	s.Run(context.Background())
+	s.Stop()
+
+	if b.calls != 0 {
+		t.Fatalf("expected no backup before the first interval, got %d", b.calls)
+	}
+}
+
+func TestSchedulerStopWithoutRun(t *testing.T) {
+	s := backup.NewScheduler(&mockBackuper{}, time.Hour, logger.NewMockLogger())
+
+	s.Stop()
+	s.Stop()
+}
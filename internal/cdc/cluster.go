@@ -0,0 +1,61 @@
+package cdc
+
+import (
+	"log/slog"
+	"sync"
+
+	"github.com/solumD/tasks-service/internal/model"
+	"github.com/solumD/tasks-service/pkg/logger"
+)
+
+// clusterRecorder записывает в поток изменения, которые применяет автомат узла
+// кластера Raft. Автомат применяет все изменения кластера в одном порядке на каждом
+// узле, поэтому поток каждого узла содержит изменения, сделанные через любой узел
+type clusterRecorder struct {
+	changes *Log
+	log     *slog.Logger
+
+	mu *sync.Mutex
+	// lost событие не удалось записать, перед следующим записывается reset
+	lost bool
+}
+
+// NewClusterRecorder создает получатель изменений автомата кластера, который пишет их в changes
+func NewClusterRecorder(changes *Log, log *slog.Logger) *clusterRecorder {
+	return &clusterRecorder{
+		changes: changes,
+		log:     log,
+		mu:      &sync.Mutex{},
+	}
+}
+
+// Record записывает события в порядке применения. Изменение уже применено кластером
+// и не может быть отменено, поэтому событие, которое не удалось записать, пропускается,
+// а перед следующим событием записывается reset, чтобы потребитель загрузил задачи заново
+func (r *clusterRecorder) Record(events []*model.ChangeEvent) {
+	const fn = "clusterRecorder.Record"
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, event := range events {
+		if r.lost {
+			if err := appendEvent(r.changes, &model.ChangeEvent{Type: model.ChangeReset}, r.log); err != nil {
+				r.log.Error("failed to record change stream reset", logger.String("fn", fn), logger.Error(err))
+				continue
+			}
+
+			r.lost = false
+		}
+
+		if err := appendEvent(r.changes, event, r.log); err != nil {
+			r.log.Error("dropped change event",
+				logger.String("fn", fn),
+				logger.Int("position", event.Position),
+				logger.Error(err),
+			)
+
+			r.lost = true
+		}
+	}
+}
@@ -0,0 +1,322 @@
+package cdc
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/solumD/tasks-service/internal/fsync"
+	"github.com/solumD/tasks-service/internal/model"
+)
+
+const (
+	// FromOldest позиция чтения с самого старого хранимого события
+	FromOldest = -1
+
+	// maxBatch максимальное количество событий в одном ответе
+	maxBatch = 1000
+	// maxWait максимальное время ожидания новых событий, меньше таймаута записи HTTP сервера
+	maxWait = 5 * time.Second
+)
+
+var (
+	// ErrPositionExpired событий после запрошенной позиции уже нет в журнале
+	ErrPositionExpired = errors.New("change stream position has expired")
+	// ErrPositionAhead запрошенная позиция больше номера последнего события
+	ErrPositionAhead = errors.New("change stream position is ahead of the stream")
+)
+
+// Log журнал потока изменений задач. События дописываются в файл и сбрасываются на диск,
+// события старше retention удаляются вызовом Trim. Последнее событие не удаляется никогда,
+// поэтому после перезапуска нумерация продолжается с того же места
+type Log struct {
+	path      string
+	retention time.Duration
+
+	events []*model.ChangeEvent
+	head   int
+	// position номер последнего записанного изменения кластера
+	position int
+	file     *os.File
+	size     int64
+	// appended закрывается и заменяется новым каналом при каждой записи
+	appended chan struct{}
+	mu       *sync.Mutex
+}
+
+// NewLog открывает журнал path и загружает его в память.
+// Недописанная последняя строка (сбой посреди записи) отбрасывается,
+// испорченная запись в середине журнала считается ошибкой
+func NewLog(path string, retention time.Duration) (*Log, error) {
+	if retention <= 0 {
+		return nil, fmt.Errorf("change stream retention must be positive, got %s", retention)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create change stream dir: %w", err)
+	}
+
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open change stream log: %w", err)
+	}
+
+	l := &Log{
+		path:      path,
+		retention: retention,
+		appended:  make(chan struct{}),
+		mu:        &sync.Mutex{},
+	}
+
+	reader := bufio.NewReader(f)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			f.Close()
+			return nil, fmt.Errorf("failed to read change stream log: %w", err)
+		}
+
+		var event model.ChangeEvent
+		if err := json.Unmarshal(bytes.TrimSpace(line), &event); err != nil {
+			f.Close()
+			return nil, fmt.Errorf("corrupt change stream log record at offset %d: %w", l.size, err)
+		}
+
+		if len(l.events) > 0 && event.Seq != l.head+1 {
+			f.Close()
+			return nil, fmt.Errorf("change stream log is corrupted: event %d follows event %d", event.Seq, l.head)
+		}
+
+		l.events = append(l.events, &event)
+		l.head = event.Seq
+		l.position = max(l.position, event.Position)
+		l.size += int64(len(line))
+	}
+
+	if err := f.Truncate(l.size); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to truncate change stream log: %w", err)
+	}
+
+	if _, err := f.Seek(l.size, io.SeekStart); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to seek change stream log: %w", err)
+	}
+
+	l.file = f
+
+	return l, nil
+}
+
+// Append присваивает событию следующий номер и время и дописывает его в журнал.
+// Изменение кластера с уже записанным номером Position пропускается: узел повторно
+// применяет журнал кластера после перезапуска. Событие не должно меняться после добавления
+func (l *Log) Append(event *model.ChangeEvent) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file == nil {
+		return errors.New("change stream log is closed")
+	}
+
+	if event.Position != 0 && event.Position <= l.position {
+		return nil
+	}
+
+	event.Seq = l.head + 1
+	event.CreatedAt = time.Now().UTC()
+
+	line, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal change event: %w", err)
+	}
+
+	line = append(line, '\n')
+
+	if _, err := l.file.Write(line); err != nil {
+		l.rollback()
+		return fmt.Errorf("failed to write change event: %w", err)
+	}
+
+	if err := l.file.Sync(); err != nil {
+		l.rollback()
+		return fmt.Errorf("failed to sync change stream log: %w", err)
+	}
+
+	l.size += int64(len(line))
+	l.head = event.Seq
+	l.position = max(l.position, event.Position)
+	l.events = append(l.events, event)
+
+	close(l.appended)
+	l.appended = make(chan struct{})
+
+	return nil
+}
+
+// Read возвращает не больше limit событий после события с номером from. Если новых событий
+// нет, ждет их не дольше wait. При from равном FromOldest чтение начинается с самого старого
+// хранимого события
+func (l *Log) Read(ctx context.Context, from int, limit int, wait time.Duration) (*model.ChangeBatch, error) {
+	limit = min(max(limit, 1), maxBatch)
+
+	timer := time.NewTimer(min(wait, maxWait))
+	defer timer.Stop()
+
+	for {
+		l.mu.Lock()
+
+		first := l.first()
+		if from == FromOldest {
+			from = first - 1
+		}
+
+		switch {
+		case from > l.head:
+			l.mu.Unlock()
+			return nil, ErrPositionAhead
+		case from+1 < first:
+			l.mu.Unlock()
+			return nil, ErrPositionExpired
+		}
+
+		if from < l.head {
+			end := min(l.head, from+limit)
+			events := append([]*model.ChangeEvent(nil), l.events[from+1-first:end+1-first]...)
+			batch := &model.ChangeBatch{First: first, Head: l.head, Events: events}
+			l.mu.Unlock()
+
+			return batch, nil
+		}
+
+		appended := l.appended
+		batch := &model.ChangeBatch{First: first, Head: l.head, Events: []*model.ChangeEvent{}}
+		l.mu.Unlock()
+
+		select {
+		case <-appended:
+		case <-timer.C:
+			return batch, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// Trim удаляет события, записанные раньше now минус retention, кроме последнего,
+// и возвращает их количество. Журнал переписывается во временный файл и атомарно
+// переименовывается, поэтому при сбое на диске остается целый журнал
+func (l *Log) Trim(now time.Time) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file == nil {
+		return 0, errors.New("change stream log is closed")
+	}
+
+	cutoff := now.Add(-l.retention)
+
+	trimmed := 0
+	for trimmed < len(l.events)-1 && l.events[trimmed].CreatedAt.Before(cutoff) {
+		trimmed++
+	}
+
+	if trimmed == 0 {
+		return 0, nil
+	}
+
+	kept := l.events[trimmed:]
+
+	var buf bytes.Buffer
+	for _, event := range kept {
+		line, err := json.Marshal(event)
+		if err != nil {
+			return 0, fmt.Errorf("failed to marshal change event: %w", err)
+		}
+
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+
+	tmpPath := l.path + ".tmp"
+	if err := fsync.WriteFile(tmpPath, buf.Bytes()); err != nil {
+		return 0, err
+	}
+
+	if err := os.Rename(tmpPath, l.path); err != nil {
+		return 0, fmt.Errorf("failed to rename change stream log: %w", err)
+	}
+
+	if err := fsync.Dir(filepath.Dir(l.path)); err != nil {
+		return 0, err
+	}
+
+	f, err := os.OpenFile(l.path, os.O_RDWR, 0o644)
+	if err != nil {
+		return 0, fmt.Errorf("failed to reopen change stream log: %w", err)
+	}
+
+	if _, err := f.Seek(int64(buf.Len()), io.SeekStart); err != nil {
+		f.Close()
+		return 0, fmt.Errorf("failed to seek change stream log: %w", err)
+	}
+
+	l.file.Close()
+	l.file = f
+	l.size = int64(buf.Len())
+	// копия, чтобы не удерживать память удаленных событий
+	l.events = append([]*model.ChangeEvent(nil), kept...)
+
+	return trimmed, nil
+}
+
+// Head возвращает номер последнего события
+func (l *Log) Head() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.head
+}
+
+// Close закрывает журнал
+func (l *Log) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file == nil {
+		return nil
+	}
+
+	err := l.file.Close()
+	l.file = nil
+
+	return err
+}
+
+// first возвращает номер самого старого хранимого события, для пустого журнала - head+1.
+// Вызывается под mu
+func (l *Log) first() int {
+	if len(l.events) == 0 {
+		return l.head + 1
+	}
+
+	return l.events[0].Seq
+}
+
+func (l *Log) rollback() {
+	if err := l.file.Truncate(l.size); err == nil {
+		l.file.Seek(l.size, io.SeekStart)
+	}
+}
@@ -0,0 +1,237 @@
+package cdc
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/solumD/tasks-service/internal/model"
+	"github.com/solumD/tasks-service/internal/usecase"
+	"github.com/solumD/tasks-service/pkg/logger"
+)
+
+const (
+	// stripes количество блокировок, по которым распределяются задачи
+	stripes = 64
+
+	// appendAttempts количество попыток записать событие в журнал
+	appendAttempts = 3
+	// appendBackoff пауза между попытками записи события
+	appendBackoff = 10 * time.Millisecond
+)
+
+type recorder struct {
+	tasks   usecase.TaskRepo
+	changes *Log
+	log     *slog.Logger
+
+	// locks упорядочивают изменения хранилища и события одной задачи: события
+	// об изменениях задачи попадают в поток в том же порядке, в котором применены.
+	// Изменения разных задач не ждут друг друга
+	locks []*sync.Mutex
+	// creating удерживается на чтение каждым создающим задачу запросом до записи
+	// события created. Первое изменение задачи ждет на нем, пока создание не записано
+	creating *sync.RWMutex
+}
+
+// NewRecorder оборачивает хранилище задач и записывает каждое его изменение в поток changes.
+// Окончательное удаление из корзины событий не создает: событие deleted записывается
+// при перемещении задачи в корзину
+func NewRecorder(tasks usecase.TaskRepo, changes *Log, log *slog.Logger) *recorder {
+	locks := make([]*sync.Mutex, stripes)
+	for i := range locks {
+		locks[i] = &sync.Mutex{}
+	}
+
+	return &recorder{
+		tasks:    tasks,
+		changes:  changes,
+		log:      log,
+		locks:    locks,
+		creating: &sync.RWMutex{},
+	}
+}
+
+// CreateTask создает задачу и записывает событие created
+func (r *recorder) CreateTask(ctx context.Context, task *model.Task) (int, error) {
+	r.creating.RLock()
+	defer r.creating.RUnlock()
+
+	id, err := r.tasks.CreateTask(ctx, task)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := r.record(ctx, model.ChangeCreated, task); err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// GetAllTasks возвращает все задачи из хранилища
+func (r *recorder) GetAllTasks(ctx context.Context) ([]*model.Task, error) {
+	return r.tasks.GetAllTasks(ctx)
+}
+
+// GetTaskByID возвращает задачу по ID из хранилища
+func (r *recorder) GetTaskByID(ctx context.Context, id int) (*model.Task, error) {
+	return r.tasks.GetTaskByID(ctx, id)
+}
+
+// GetTasksByDue возвращает задачи из хранилища по сроку выполнения
+func (r *recorder) GetTasksByDue(ctx context.Context, from, to time.Time) ([]*model.Task, error) {
+	return r.tasks.GetTasksByDue(ctx, from, to)
+}
+
+// GetTasksByTags возвращает задачи из хранилища по меткам
+func (r *recorder) GetTasksByTags(ctx context.Context, tags []string, all bool) ([]*model.Task, error) {
+	return r.tasks.GetTasksByTags(ctx, tags, all)
+}
+
+// GetTasksByParent возвращает подзадачи из хранилища
+func (r *recorder) GetTasksByParent(ctx context.Context, parentID int) ([]*model.Task, error) {
+	return r.tasks.GetTasksByParent(ctx, parentID)
+}
+
+// GetTagCounts возвращает метки задач из хранилища
+func (r *recorder) GetTagCounts(ctx context.Context) ([]model.TagCount, error) {
+	return r.tasks.GetTagCounts(ctx)
+}
+
+// RenameTag переименовывает метку и записывает событие updated для каждой измененной задачи
+func (r *recorder) RenameTag(ctx context.Context, from, to string) ([]*model.Task, error) {
+	// задачи с меткой заранее неизвестны, поэтому блокируются все
+	for _, mu := range r.locks {
+		mu.Lock()
+		defer mu.Unlock()
+	}
+
+	renamed, err := r.tasks.RenameTag(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, task := range renamed {
+		if err := r.record(ctx, model.ChangeUpdated, task); err != nil {
+			return nil, err
+		}
+	}
+
+	return renamed, nil
+}
+
+// UpdateTask обновляет задачу и записывает событие updated
+func (r *recorder) UpdateTask(ctx context.Context, task *model.Task) error {
+	mu := r.lock(task.ID)
+	defer mu.Unlock()
+
+	if err := r.tasks.UpdateTask(ctx, task); err != nil {
+		return err
+	}
+
+	return r.record(ctx, model.ChangeUpdated, task)
+}
+
+// DeleteTask перемещает задачу в корзину и записывает событие deleted
+func (r *recorder) DeleteTask(ctx context.Context, id int, version int) (*model.Task, error) {
+	mu := r.lock(id)
+	defer mu.Unlock()
+
+	task, err := r.tasks.DeleteTask(ctx, id, version)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.record(ctx, model.ChangeDeleted, task); err != nil {
+		return nil, err
+	}
+
+	return task, nil
+}
+
+// GetDeletedTasks возвращает задачи из корзины
+func (r *recorder) GetDeletedTasks(ctx context.Context) ([]*model.Task, error) {
+	return r.tasks.GetDeletedTasks(ctx)
+}
+
+// RestoreTask восстанавливает задачу из корзины и записывает событие updated
+func (r *recorder) RestoreTask(ctx context.Context, id int) (*model.Task, error) {
+	mu := r.lock(id)
+	defer mu.Unlock()
+
+	task, err := r.tasks.RestoreTask(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.record(ctx, model.ChangeUpdated, task); err != nil {
+		return nil, err
+	}
+
+	return task, nil
+}
+
+// PurgeDeletedTasks очищает корзину без записи событий
+func (r *recorder) PurgeDeletedTasks(ctx context.Context, deletedBefore time.Time) (int, error) {
+	return r.tasks.PurgeDeletedTasks(ctx, deletedBefore)
+}
+
+// lock блокирует изменения задачи id и возвращает взятую блокировку
+func (r *recorder) lock(id int) *sync.Mutex {
+	mu := r.locks[uint(id)%stripes]
+	mu.Lock()
+
+	return mu
+}
+
+// record записывает событие с копией задачи. Первое изменение задачи записывается
+// только после события created. Изменение уже применено, поэтому запись события
+// повторяется, а если не удалась, ошибка возвращается клиенту
+func (r *recorder) record(ctx context.Context, typ model.ChangeType, task *model.Task) error {
+	if typ != model.ChangeCreated && task.Version == 2 {
+		r.creating.Lock()
+		r.creating.Unlock()
+	}
+
+	event := &model.ChangeEvent{
+		Type:   typ,
+		TaskID: task.ID,
+		Author: usecase.AuthorFromContext(ctx),
+		Task:   *task.Clone(),
+	}
+
+	if err := appendEvent(r.changes, event, r.log); err != nil {
+		return fmt.Errorf("failed to record change of task %d: %w", task.ID, err)
+	}
+
+	return nil
+}
+
+// appendEvent записывает событие в журнал changes, повторяя запись appendAttempts раз
+func appendEvent(changes *Log, event *model.ChangeEvent, log *slog.Logger) error {
+	const fn = "cdc.appendEvent"
+
+	var err error
+	for attempt := 1; attempt <= appendAttempts; attempt++ {
+		if err = changes.Append(event); err == nil {
+			return nil
+		}
+
+		log.Error("failed to append change event",
+			logger.String("fn", fn),
+			logger.String("type", string(event.Type)),
+			logger.Int("task id", event.TaskID),
+			logger.Int("attempt", attempt),
+			logger.Error(err),
+		)
+
+		if attempt < appendAttempts {
+			time.Sleep(appendBackoff)
+		}
+	}
+
+	return err
+}
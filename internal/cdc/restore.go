@@ -0,0 +1,57 @@
+package cdc
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/solumD/tasks-service/internal/model"
+	"github.com/solumD/tasks-service/internal/usecase"
+)
+
+// Store хранилище задач, которое умеет отдавать и заменять все задачи целиком
+type Store interface {
+	DumpTasks(ctx context.Context) ([]*model.Task, int, error)
+	ReplaceTasks(ctx context.Context, tasks []*model.Task, nextID int) error
+}
+
+type restoreRecorder struct {
+	store   Store
+	changes *Log
+	log     *slog.Logger
+}
+
+// NewRestoreRecorder оборачивает хранилище задач для восстановления из резервной копии
+// и после замены всех задач записывает в поток changes событие reset: по событиям
+// нельзя понять, какие задачи изменились, поэтому потребитель загружает их заново
+func NewRestoreRecorder(store Store, changes *Log, log *slog.Logger) *restoreRecorder {
+	return &restoreRecorder{
+		store:   store,
+		changes: changes,
+		log:     log,
+	}
+}
+
+// DumpTasks возвращает все задачи и ID следующей задачи из хранилища
+func (r *restoreRecorder) DumpTasks(ctx context.Context) ([]*model.Task, int, error) {
+	return r.store.DumpTasks(ctx)
+}
+
+// ReplaceTasks заменяет все задачи и записывает событие reset. Задачи уже заменены,
+// поэтому если событие не записано, ошибка возвращается клиенту
+func (r *restoreRecorder) ReplaceTasks(ctx context.Context, tasks []*model.Task, nextID int) error {
+	if err := r.store.ReplaceTasks(ctx, tasks, nextID); err != nil {
+		return err
+	}
+
+	event := &model.ChangeEvent{
+		Type:   model.ChangeReset,
+		Author: usecase.AuthorFromContext(ctx),
+	}
+
+	if err := appendEvent(r.changes, event, r.log); err != nil {
+		return fmt.Errorf("failed to record reset of tasks: %w", err)
+	}
+
+	return nil
+}
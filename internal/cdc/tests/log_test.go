@@ -0,0 +1,315 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/solumD/tasks-service/internal/cdc"
+	"github.com/solumD/tasks-service/internal/model"
+)
+
+func openLog(t *testing.T, path string) *cdc.Log {
+	t.Helper()
+
+	changes, err := cdc.NewLog(path, time.Hour)
+	if err != nil {
+		t.Fatalf("failed to open change stream: %v", err)
+	}
+	t.Cleanup(func() { changes.Close() })
+
+	return changes
+}
+
+func appendEvents(t *testing.T, changes *cdc.Log, count int) {
+	t.Helper()
+
+	for i := 0; i < count; i++ {
+		event := &model.ChangeEvent{Type: model.ChangeUpdated, TaskID: i + 1, Task: model.Task{ID: i + 1, Title: "task"}}
+		if err := changes.Append(event); err != nil {
+			t.Fatalf("failed to append event: %v", err)
+		}
+	}
+}
+
+func seqs(batch *model.ChangeBatch) []int {
+	result := make([]int, 0, len(batch.Events))
+	for _, event := range batch.Events {
+		result = append(result, event.Seq)
+	}
+
+	return result
+}
+
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func TestLogRead(t *testing.T) {
+	changes := openLog(t, filepath.Join(t.TempDir(), "changes.log"))
+	appendEvents(t, changes, 5)
+
+	tests := []struct {
+		name         string
+		from         int
+		limit        int
+		expectedSeqs []int
+		expectedErr  error
+	}{
+		{name: "from start", from: 0, limit: 10, expectedSeqs: []int{1, 2, 3, 4, 5}},
+		{name: "from oldest", from: cdc.FromOldest, limit: 10, expectedSeqs: []int{1, 2, 3, 4, 5}},
+		{name: "resume", from: 3, limit: 10, expectedSeqs: []int{4, 5}},
+		{name: "limit", from: 1, limit: 2, expectedSeqs: []int{2, 3}},
+		{name: "head", from: 5, limit: 10, expectedSeqs: []int{}},
+		{name: "ahead", from: 6, limit: 10, expectedErr: cdc.ErrPositionAhead},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			batch, err := changes.Read(context.Background(), tt.from, tt.limit, 0)
+			if !errors.Is(err, tt.expectedErr) {
+				t.Fatalf("expected error %v, got %v", tt.expectedErr, err)
+			}
+
+			if err != nil {
+				return
+			}
+
+			if !equalInts(seqs(batch), tt.expectedSeqs) {
+				t.Fatalf("expected events %v, got %v", tt.expectedSeqs, seqs(batch))
+			}
+
+			if batch.First != 1 || batch.Head != 5 {
+				t.Fatalf("expected first 1 and head 5, got %d and %d", batch.First, batch.Head)
+			}
+		})
+	}
+}
+
+func TestLogReadWaitsForEvents(t *testing.T) {
+	changes := openLog(t, filepath.Join(t.TempDir(), "changes.log"))
+
+	done := make(chan *model.ChangeBatch)
+	go func() {
+		batch, err := changes.Read(context.Background(), 0, 10, 5*time.Second)
+		if err != nil {
+			t.Errorf("failed to read change stream: %v", err)
+		}
+		done <- batch
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	appendEvents(t, changes, 1)
+
+	select {
+	case batch := <-done:
+		if !equalInts(seqs(batch), []int{1}) {
+			t.Fatalf("expected event 1, got %v", seqs(batch))
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected read to return after append")
+	}
+}
+
+func TestLogReadTimeout(t *testing.T) {
+	changes := openLog(t, filepath.Join(t.TempDir(), "changes.log"))
+
+	start := time.Now()
+
+	batch, err := changes.Read(context.Background(), 0, 10, 50*time.Millisecond)
+	if err != nil {
+		t.Fatalf("failed to read change stream: %v", err)
+	}
+
+	if len(batch.Events) != 0 || batch.First != 1 || batch.Head != 0 {
+		t.Fatalf("expected empty batch with first 1 and head 0, got %+v", batch)
+	}
+
+	if time.Since(start) < 50*time.Millisecond {
+		t.Fatal("expected read to wait")
+	}
+}
+
+func TestLogReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "changes.log")
+
+	changes, err := cdc.NewLog(path, time.Hour)
+	if err != nil {
+		t.Fatalf("failed to open change stream: %v", err)
+	}
+
+	appendEvents(t, changes, 3)
+	changes.Close()
+
+	// недописанное событие после сбоя
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		t.Fatalf("failed to open log file: %v", err)
+	}
+	f.WriteString(`{"Seq":4,"Type":"upd`)
+	f.Close()
+
+	reopened := openLog(t, path)
+	if reopened.Head() != 3 {
+		t.Fatalf("expected head 3, got %d", reopened.Head())
+	}
+
+	appendEvents(t, reopened, 1)
+
+	batch, err := reopened.Read(context.Background(), 2, 10, 0)
+	if err != nil {
+		t.Fatalf("failed to read change stream: %v", err)
+	}
+
+	if !equalInts(seqs(batch), []int{3, 4}) {
+		t.Fatalf("expected events [3 4], got %v", seqs(batch))
+	}
+}
+
+func TestLogFailsOnCorruptRecord(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "changes.log")
+
+	changes := openLog(t, path)
+	appendEvents(t, changes, 2)
+	changes.Close()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read log file: %v", err)
+	}
+
+	// испорченная запись перед целыми
+	corrupted := append([]byte("garbage\n"), data...)
+	if err := os.WriteFile(path, corrupted, 0o644); err != nil {
+		t.Fatalf("failed to write log file: %v", err)
+	}
+
+	if _, err := cdc.NewLog(path, time.Hour); err == nil {
+		t.Fatal("expected error on corrupt record")
+	}
+
+	after, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read log file: %v", err)
+	}
+
+	if string(after) != string(corrupted) {
+		t.Fatal("expected corrupt log to stay untouched")
+	}
+}
+
+func TestLogTrim(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "changes.log")
+	changes := openLog(t, path)
+	appendEvents(t, changes, 4)
+
+	trimmed, err := changes.Trim(time.Now())
+	if err != nil {
+		t.Fatalf("failed to trim change stream: %v", err)
+	}
+
+	if trimmed != 0 {
+		t.Fatalf("expected no events trimmed within retention, got %d", trimmed)
+	}
+
+	// все события старше срока хранения, последнее остается
+	trimmed, err = changes.Trim(time.Now().Add(2 * time.Hour))
+	if err != nil {
+		t.Fatalf("failed to trim change stream: %v", err)
+	}
+
+	if trimmed != 3 {
+		t.Fatalf("expected 3 events trimmed, got %d", trimmed)
+	}
+
+	if _, err := changes.Read(context.Background(), 2, 10, 0); !errors.Is(err, cdc.ErrPositionExpired) {
+		t.Fatalf("expected %v, got %v", cdc.ErrPositionExpired, err)
+	}
+
+	batch, err := changes.Read(context.Background(), cdc.FromOldest, 10, 0)
+	if err != nil {
+		t.Fatalf("failed to read change stream: %v", err)
+	}
+
+	if !equalInts(seqs(batch), []int{4}) || batch.First != 4 {
+		t.Fatalf("expected event 4 with first 4, got %v with first %d", seqs(batch), batch.First)
+	}
+
+	appendEvents(t, changes, 1)
+	changes.Close()
+
+	// нумерация продолжается после перезапуска
+	reopened := openLog(t, path)
+	appendEvents(t, reopened, 1)
+
+	batch, err = reopened.Read(context.Background(), 3, 10, 0)
+	if err != nil {
+		t.Fatalf("failed to read change stream: %v", err)
+	}
+
+	if !equalInts(seqs(batch), []int{4, 5, 6}) {
+		t.Fatalf("expected events [4 5 6], got %v", seqs(batch))
+	}
+}
+
+func TestLogSkipsRecordedPositions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "changes.log")
+
+	changes, err := cdc.NewLog(path, time.Hour)
+	if err != nil {
+		t.Fatalf("failed to open change stream: %v", err)
+	}
+
+	for _, position := range []int{1, 2, 2, 1, 3} {
+		event := &model.ChangeEvent{Type: model.ChangeUpdated, TaskID: 1, Position: position}
+		if err := changes.Append(event); err != nil {
+			t.Fatalf("failed to append event: %v", err)
+		}
+	}
+
+	if changes.Head() != 3 {
+		t.Fatalf("expected head 3, got %d", changes.Head())
+	}
+
+	changes.Close()
+
+	// после перезапуска узел повторно применяет журнал кластера
+	reopened := openLog(t, path)
+
+	for _, position := range []int{2, 3, 4} {
+		event := &model.ChangeEvent{Type: model.ChangeUpdated, TaskID: 1, Position: position}
+		if err := reopened.Append(event); err != nil {
+			t.Fatalf("failed to append event: %v", err)
+		}
+	}
+
+	// события не из кластера записываются всегда
+	appendEvents(t, reopened, 1)
+
+	batch, err := reopened.Read(context.Background(), 0, 10, 0)
+	if err != nil {
+		t.Fatalf("failed to read change stream: %v", err)
+	}
+
+	positions := make([]int, 0, len(batch.Events))
+	for _, event := range batch.Events {
+		positions = append(positions, event.Position)
+	}
+
+	if !equalInts(seqs(batch), []int{1, 2, 3, 4, 5}) || !equalInts(positions, []int{1, 2, 3, 4, 0}) {
+		t.Fatalf("expected events [1 2 3 4 5] of changes [1 2 3 4 0], got %v of %v", seqs(batch), positions)
+	}
+}
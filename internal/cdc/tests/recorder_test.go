@@ -0,0 +1,256 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/solumD/tasks-service/internal/cdc"
+	"github.com/solumD/tasks-service/internal/model"
+	inmemory "github.com/solumD/tasks-service/internal/repository/in_memory"
+	"github.com/solumD/tasks-service/internal/usecase"
+	"github.com/solumD/tasks-service/pkg/logger"
+)
+
+// usecaseTasks изменяющие методы юзкейса задач
+type usecaseTasks interface {
+	CreateTask(ctx context.Context, task *model.Task) (int, error)
+	UpdateTask(ctx context.Context, task *model.Task) error
+	DeleteTask(ctx context.Context, id int, version int) error
+	RestoreTask(ctx context.Context, id int) (*model.Task, error)
+	RevertTask(ctx context.Context, id int, rev int, version int) (*model.Task, error)
+}
+
+func newUsecase(t *testing.T) (usecaseTasks, *cdc.Log) {
+	t.Helper()
+
+	log := logger.NewMockLogger()
+	changes := openLog(t, filepath.Join(t.TempDir(), "changes.log"))
+
+	recorder := cdc.NewRecorder(inmemory.NewTaskRepo(), changes, log)
+
+	return usecase.NewTaskUsecase(recorder, inmemory.NewRevisionRepo(), inmemory.NewProjectRepo(), inmemory.NewCommentRepo(), usecase.Rules{}, log), changes
+}
+
+func TestRecorderEmitsEvents(t *testing.T) {
+	tasks, changes := newUsecase(t)
+	ctx := usecase.ContextWithAuthor(context.Background(), "alice")
+
+	id, err := tasks.CreateTask(ctx, &model.Task{Title: "A"})
+	if err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+
+	if err := tasks.UpdateTask(ctx, &model.Task{ID: id, Title: "B", Done: true}); err != nil {
+		t.Fatalf("failed to update task: %v", err)
+	}
+
+	// неуспешное изменение не попадает в поток
+	if err := tasks.UpdateTask(ctx, &model.Task{ID: id, Title: "C", Version: 1}); err == nil {
+		t.Fatal("expected version conflict")
+	}
+
+	if err := tasks.DeleteTask(ctx, id, 0); err != nil {
+		t.Fatalf("failed to delete task: %v", err)
+	}
+
+	if _, err := tasks.RestoreTask(ctx, id); err != nil {
+		t.Fatalf("failed to restore task: %v", err)
+	}
+
+	if _, err := tasks.RevertTask(ctx, id, 1, 0); err != nil {
+		t.Fatalf("failed to revert task: %v", err)
+	}
+
+	batch, err := changes.Read(context.Background(), 0, 100, 0)
+	if err != nil {
+		t.Fatalf("failed to read change stream: %v", err)
+	}
+
+	expected := []struct {
+		typ     model.ChangeType
+		title   string
+		version int
+		deleted bool
+	}{
+		{typ: model.ChangeCreated, title: "A", version: 1},
+		{typ: model.ChangeUpdated, title: "B", version: 2},
+		{typ: model.ChangeDeleted, title: "B", version: 3, deleted: true},
+		{typ: model.ChangeUpdated, title: "B", version: 4},
+		{typ: model.ChangeUpdated, title: "A", version: 5},
+	}
+
+	if len(batch.Events) != len(expected) {
+		t.Fatalf("expected %d events, got %d", len(expected), len(batch.Events))
+	}
+
+	for i, e := range expected {
+		event := batch.Events[i]
+
+		if event.Seq != i+1 || event.Type != e.typ || event.TaskID != id || event.Author != "alice" {
+			t.Fatalf("event %d: expected seq %d, type %s, task %d by alice, got %+v", i, i+1, e.typ, id, event)
+		}
+
+		if event.Task.Title != e.title || event.Task.Version != e.version || (event.Task.DeletedAt != nil) != e.deleted {
+			t.Fatalf("event %d: expected task %q v%d deleted=%v, got %+v", i, e.title, e.version, e.deleted, event.Task)
+		}
+
+		if event.CreatedAt.IsZero() {
+			t.Fatalf("event %d: expected created at", i)
+		}
+	}
+}
+
+func TestRecorderOrdersEventsByVersion(t *testing.T) {
+	const (
+		workers = 8
+		updates = 25
+	)
+
+	tasks, changes := newUsecase(t)
+	ctx := context.Background()
+
+	id, err := tasks.CreateTask(ctx, &model.Task{Title: "task"})
+	if err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+
+			for i := 0; i < updates; i++ {
+				if err := tasks.UpdateTask(ctx, &model.Task{ID: id, Title: fmt.Sprintf("%d-%d", w, i)}); err != nil {
+					t.Errorf("failed to update task: %v", err)
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	batch, err := changes.Read(ctx, 0, 1000, 0)
+	if err != nil {
+		t.Fatalf("failed to read change stream: %v", err)
+	}
+
+	if len(batch.Events) != workers*updates+1 {
+		t.Fatalf("expected %d events, got %d", workers*updates+1, len(batch.Events))
+	}
+
+	for i, event := range batch.Events {
+		if event.Task.Version != i+1 {
+			t.Fatalf("expected event %d to carry version %d, got %d", event.Seq, i+1, event.Task.Version)
+		}
+	}
+}
+
+func TestRecorderOrdersEventsPerTask(t *testing.T) {
+	const (
+		workers = 8
+		updates = 10
+	)
+
+	tasks, changes := newUsecase(t)
+	ctx := context.Background()
+
+	// изменения разных задач записываются параллельно
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+
+			id, err := tasks.CreateTask(ctx, &model.Task{Title: fmt.Sprintf("%d", w)})
+			if err != nil {
+				t.Errorf("failed to create task: %v", err)
+				return
+			}
+
+			for i := 0; i < updates; i++ {
+				if err := tasks.UpdateTask(ctx, &model.Task{ID: id, Title: fmt.Sprintf("%d-%d", w, i)}); err != nil {
+					t.Errorf("failed to update task: %v", err)
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	batch, err := changes.Read(ctx, 0, 1000, 0)
+	if err != nil {
+		t.Fatalf("failed to read change stream: %v", err)
+	}
+
+	if len(batch.Events) != workers*(updates+1) {
+		t.Fatalf("expected %d events, got %d", workers*(updates+1), len(batch.Events))
+	}
+
+	versions := make(map[int]int)
+	for _, event := range batch.Events {
+		if event.Task.Version != versions[event.TaskID]+1 {
+			t.Fatalf("event %d: expected task %d version %d, got %d", event.Seq, event.TaskID, versions[event.TaskID]+1, event.Task.Version)
+		}
+
+		if (event.Type == model.ChangeCreated) != (event.Task.Version == 1) {
+			t.Fatalf("event %d: unexpected type %s for version %d", event.Seq, event.Type, event.Task.Version)
+		}
+
+		versions[event.TaskID] = event.Task.Version
+	}
+}
+
+func TestRecorderFailsWhenEventNotRecorded(t *testing.T) {
+	tasks, changes := newUsecase(t)
+	ctx := context.Background()
+
+	id, err := tasks.CreateTask(ctx, &model.Task{Title: "task"})
+	if err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+
+	// закрытый журнал не принимает события
+	changes.Close()
+
+	if err := tasks.UpdateTask(ctx, &model.Task{ID: id, Title: "changed"}); err == nil {
+		t.Fatal("expected error when change event is not recorded")
+	}
+
+	if _, err := tasks.CreateTask(ctx, &model.Task{Title: "another"}); err == nil {
+		t.Fatal("expected error when change event is not recorded")
+	}
+}
+
+func TestRestoreRecorderEmitsReset(t *testing.T) {
+	ctx := usecase.ContextWithAuthor(context.Background(), "admin")
+	changes := openLog(t, filepath.Join(t.TempDir(), "changes.log"))
+
+	store := cdc.NewRestoreRecorder(inmemory.NewTaskRepo(), changes, logger.NewMockLogger())
+
+	if err := store.ReplaceTasks(ctx, []*model.Task{{ID: 3, Title: "restored", Version: 2}}, 5); err != nil {
+		t.Fatalf("failed to replace tasks: %v", err)
+	}
+
+	tasks, nextID, err := store.DumpTasks(ctx)
+	if err != nil || len(tasks) != 1 || nextID != 5 {
+		t.Fatalf("expected 1 restored task with next id 5, got %d tasks with next id %d (err %v)", len(tasks), nextID, err)
+	}
+
+	batch, err := changes.Read(ctx, 0, 10, 0)
+	if err != nil {
+		t.Fatalf("failed to read change stream: %v", err)
+	}
+
+	if len(batch.Events) != 1 || batch.Events[0].Type != model.ChangeReset || batch.Events[0].TaskID != 0 || batch.Events[0].Author != "admin" {
+		t.Fatalf("expected one reset event by admin, got %+v", batch.Events)
+	}
+
+	// закрытый журнал не принимает события, и восстановление завершается ошибкой
+	changes.Close()
+
+	if err := store.ReplaceTasks(ctx, nil, 5); err == nil {
+		t.Fatal("expected error when reset event is not recorded")
+	}
+}
@@ -0,0 +1,84 @@
+package cdc
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/solumD/tasks-service/pkg/logger"
+)
+
+type trimmer struct {
+	changes  *Log
+	interval time.Duration
+	log      *slog.Logger
+
+	cancel context.CancelFunc
+	done   chan struct{}
+	once   *sync.Once
+}
+
+// NewTrimmer создает фоновое удаление событий потока изменений, которые старше
+// срока хранения журнала. Удаление выполняется каждые interval
+func NewTrimmer(changes *Log, interval time.Duration, log *slog.Logger) *trimmer {
+	return &trimmer{
+		changes:  changes,
+		interval: interval,
+		log:      log,
+		done:     make(chan struct{}),
+		once:     &sync.Once{},
+	}
+}
+
+// Run запускает удаление в отдельной горутине. Первое удаление выполняется сразу
+func (t *trimmer) Run(ctx context.Context) {
+	ctx, t.cancel = context.WithCancel(ctx)
+
+	go func() {
+		defer close(t.done)
+
+		ticker := time.NewTicker(t.interval)
+		defer ticker.Stop()
+
+		for {
+			t.trim()
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop останавливает удаление и ждет завершения текущего прохода
+func (t *trimmer) Stop() {
+	t.once.Do(func() {
+		if t.cancel == nil {
+			close(t.done)
+			return
+		}
+
+		t.cancel()
+	})
+
+	<-t.done
+}
+
+func (t *trimmer) trim() {
+	const fn = "trimmer.trim"
+	log := t.log.With(logger.String("fn", fn))
+
+	// ошибки не останавливают удаление, следующий проход повторит попытку
+	trimmed, err := t.changes.Trim(time.Now().UTC())
+	if err != nil {
+		log.Error("failed to trim change stream", logger.Error(err))
+		return
+	}
+
+	if trimmed > 0 {
+		log.Info("trimmed change stream", logger.Int("events count", trimmed))
+	}
+}
@@ -3,8 +3,13 @@ package config
 import (
 	"log"
 	"net"
+	"net/url"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/solumD/tasks-service/internal/model"
 	"github.com/solumD/tasks-service/pkg/env"
 )
 
@@ -14,6 +19,72 @@ const (
 	httpServerHostEnv = "HTTP_SERVER_HOST"
 	httpServerPortEnv = "HTTP_SERVER_PORT"
 	loggerLevelEnv    = "LOGGER_LEVEL"
+
+	storageTypeEnv             = "STORAGE_TYPE"
+	inMemoryShardsEnv          = "IN_MEMORY_SHARDS"
+	fileStorageDirEnv          = "FILE_STORAGE_DIR"
+	fileStorageCompactEveryEnv = "FILE_STORAGE_COMPACT_EVERY"
+	sqlDriverEnv               = "SQL_DRIVER"
+	sqlDSNEnv                  = "SQL_DSN"
+	eventStorePathEnv          = "EVENT_STORE_PATH"
+
+	trashRetentionEnv     = "TRASH_RETENTION"
+	trashPurgeIntervalEnv = "TRASH_PURGE_INTERVAL"
+
+	backupDirEnv        = "BACKUP_DIR"
+	backupIntervalEnv   = "BACKUP_INTERVAL"
+	backupKeepLastEnv   = "BACKUP_KEEP_LAST"
+	backupKeepDailyEnv  = "BACKUP_KEEP_DAILY"
+	backupKeepWeeklyEnv = "BACKUP_KEEP_WEEKLY"
+
+	replicationRoleEnv      = "REPLICATION_ROLE"
+	replicationLogSizeEnv   = "REPLICATION_LOG_SIZE"
+	replicationLeaderURLEnv = "REPLICATION_LEADER_URL"
+	replicationPollWaitEnv  = "REPLICATION_POLL_WAIT"
+
+	raftNodeIDEnv            = "RAFT_NODE_ID"
+	raftBindAddrEnv          = "RAFT_BIND_ADDR"
+	raftPeersEnv             = "RAFT_PEERS"
+	raftDirEnv               = "RAFT_DIR"
+	raftElectionTimeoutEnv   = "RAFT_ELECTION_TIMEOUT"
+	raftSnapshotThresholdEnv = "RAFT_SNAPSHOT_THRESHOLD"
+
+	cdcPathEnv         = "CDC_PATH"
+	cdcRetentionEnv    = "CDC_RETENTION"
+	cdcTrimIntervalEnv = "CDC_TRIM_INTERVAL"
+
+	taskDeleteChildrenEnv     = "TASK_DELETE_CHILDREN"
+	taskAutoCompleteParentEnv = "TASK_AUTO_COMPLETE_PARENT"
+	taskBlockDoneEnv          = "TASK_BLOCK_DONE"
+	taskWorkflowEnv           = "TASK_WORKFLOW"
+
+	projectDeleteTasksEnv = "PROJECT_DELETE_TASKS"
+
+	adminTokenEnv = "ADMIN_TOKEN"
+)
+
+const (
+	// StorageInMemory хранилище в памяти процесса
+	StorageInMemory = "in_memory"
+	// StorageInMemorySharded хранилище в памяти процесса, разбитое на шарды
+	StorageInMemorySharded = "in_memory_sharded"
+	// StorageFile файловое хранилище с журналом упреждающей записи
+	StorageFile = "file"
+	// StorageSQL хранилище в SQL базе данных
+	StorageSQL = "sql"
+	// StorageEventSourced хранилище на журнале событий
+	StorageEventSourced = "eventsourced"
+	// StorageRaft хранилище, реплицируемое кластером Raft
+	StorageRaft = "raft"
+)
+
+const (
+	// ReplicationStandalone узел без репликации
+	ReplicationStandalone = "standalone"
+	// ReplicationLeader ведущий узел, отдающий журнал изменений ведомым
+	ReplicationLeader = "leader"
+	// ReplicationFollower ведомый узел только для чтения
+	ReplicationFollower = "follower"
 )
 
 // Config конфиг
@@ -21,6 +92,54 @@ type Config struct {
 	httpServerHost string
 	httpServerPort string
 	loggerLevel    string
+
+	storageType             string
+	inMemoryShards          int
+	fileStorageDir          string
+	fileStorageCompactEvery int
+	sqlDriver               string
+	sqlDSN                  string
+	eventStorePath          string
+
+	trashRetention     time.Duration
+	trashPurgeInterval time.Duration
+
+	backupDir        string
+	backupInterval   time.Duration
+	backupKeepLast   int
+	backupKeepDaily  int
+	backupKeepWeekly int
+
+	replicationRole      string
+	replicationLogSize   int
+	replicationLeaderURL string
+	replicationPollWait  time.Duration
+
+	raftNodeID            string
+	raftBindAddr          string
+	raftPeers             []RaftPeer
+	raftDir               string
+	raftElectionTimeout   time.Duration
+	raftSnapshotThreshold int
+
+	cdcPath         string
+	cdcRetention    time.Duration
+	cdcTrimInterval time.Duration
+
+	taskDeleteChildren     model.DeleteRule
+	taskAutoCompleteParent bool
+	taskBlockDone          bool
+	taskWorkflow           model.Workflow
+
+	projectDeleteTasks model.DeleteRule
+
+	adminToken string
+}
+
+// RaftPeer участник начального состава кластера Raft
+type RaftPeer struct {
+	ID      string
+	Address string
 }
 
 // ServerAddr возвращает адрес сервера
@@ -33,7 +152,173 @@ func (c *Config) LoggerLevel() string {
 	return c.loggerLevel
 }
 
-// MustLoad загружает конфиг из файла .env
+// StorageType возвращает тип хранилища задач
+func (c *Config) StorageType() string {
+	return c.storageType
+}
+
+// InMemoryShards возвращает количество шардов хранилища в памяти
+func (c *Config) InMemoryShards() int {
+	return c.inMemoryShards
+}
+
+// FileStorageDir возвращает директорию файлового хранилища
+func (c *Config) FileStorageDir() string {
+	return c.fileStorageDir
+}
+
+// FileStorageCompactEvery возвращает количество записей журнала, после которого делается снапшот
+func (c *Config) FileStorageCompactEvery() int {
+	return c.fileStorageCompactEvery
+}
+
+// SQLDriver возвращает имя драйвера database/sql
+func (c *Config) SQLDriver() string {
+	return c.sqlDriver
+}
+
+// SQLDSN возвращает строку подключения к базе
+func (c *Config) SQLDSN() string {
+	return c.sqlDSN
+}
+
+// EventStorePath возвращает путь к файлу журнала событий
+func (c *Config) EventStorePath() string {
+	return c.eventStorePath
+}
+
+// TrashRetention возвращает время, которое удаленная задача хранится в корзине
+func (c *Config) TrashRetention() time.Duration {
+	return c.trashRetention
+}
+
+// TrashPurgeInterval возвращает период запуска очистки корзины
+func (c *Config) TrashPurgeInterval() time.Duration {
+	return c.trashPurgeInterval
+}
+
+// BackupDir возвращает директорию резервных копий
+func (c *Config) BackupDir() string {
+	return c.backupDir
+}
+
+// BackupInterval возвращает период создания резервных копий
+func (c *Config) BackupInterval() time.Duration {
+	return c.backupInterval
+}
+
+// BackupKeepLast возвращает количество последних резервных копий, которые хранятся всегда
+func (c *Config) BackupKeepLast() int {
+	return c.backupKeepLast
+}
+
+// BackupKeepDaily возвращает количество дней, за каждый из которых хранится последняя копия
+func (c *Config) BackupKeepDaily() int {
+	return c.backupKeepDaily
+}
+
+// BackupKeepWeekly возвращает количество недель, за каждую из которых хранится последняя копия
+func (c *Config) BackupKeepWeekly() int {
+	return c.backupKeepWeekly
+}
+
+// ReplicationRole возвращает роль узла в репликации
+func (c *Config) ReplicationRole() string {
+	return c.replicationRole
+}
+
+// ReplicationLogSize возвращает количество последних записей журнала репликации ведущего узла
+func (c *Config) ReplicationLogSize() int {
+	return c.replicationLogSize
+}
+
+// ReplicationLeaderURL возвращает адрес ведущего узла
+func (c *Config) ReplicationLeaderURL() string {
+	return c.replicationLeaderURL
+}
+
+// ReplicationPollWait возвращает время ожидания новых записей журнала в одном запросе
+func (c *Config) ReplicationPollWait() time.Duration {
+	return c.replicationPollWait
+}
+
+// RaftNodeID возвращает идентификатор узла в кластере Raft
+func (c *Config) RaftNodeID() string {
+	return c.raftNodeID
+}
+
+// RaftBindAddr возвращает адрес, на котором узел принимает запросы других узлов кластера
+func (c *Config) RaftBindAddr() string {
+	return c.raftBindAddr
+}
+
+// RaftPeers возвращает начальный состав кластера. Если узла в нем нет,
+// узел запускается без состава и ждет, пока его добавят в кластер
+func (c *Config) RaftPeers() []RaftPeer {
+	return c.raftPeers
+}
+
+// RaftDir возвращает директорию журнала и снимков узла
+func (c *Config) RaftDir() string {
+	return c.raftDir
+}
+
+// RaftElectionTimeout возвращает время без лидера, после которого узел начинает выборы
+func (c *Config) RaftElectionTimeout() time.Duration {
+	return c.raftElectionTimeout
+}
+
+// RaftSnapshotThreshold возвращает количество записей журнала, после которого делается снимок
+func (c *Config) RaftSnapshotThreshold() int {
+	return c.raftSnapshotThreshold
+}
+
+// CDCPath возвращает путь к журналу потока изменений задач
+func (c *Config) CDCPath() string {
+	return c.cdcPath
+}
+
+// CDCRetention возвращает время, которое событие хранится в потоке изменений
+func (c *Config) CDCRetention() time.Duration {
+	return c.cdcRetention
+}
+
+// CDCTrimInterval возвращает период удаления устаревших событий потока изменений
+func (c *Config) CDCTrimInterval() time.Duration {
+	return c.cdcTrimInterval
+}
+
+// TaskDeleteChildren возвращает правило для подзадач удаляемой задачи
+func (c *Config) TaskDeleteChildren() model.DeleteRule {
+	return c.taskDeleteChildren
+}
+
+// TaskAutoCompleteParent возвращает, отмечается ли задача выполненной, когда выполнены все ее подзадачи
+func (c *Config) TaskAutoCompleteParent() bool {
+	return c.taskAutoCompleteParent
+}
+
+// TaskBlockDone возвращает, запрещено ли отмечать выполненной задачу с невыполненными зависимостями
+func (c *Config) TaskBlockDone() bool {
+	return c.taskBlockDone
+}
+
+// TaskWorkflow возвращает допустимые переходы между статусами задач
+func (c *Config) TaskWorkflow() model.Workflow {
+	return c.taskWorkflow
+}
+
+// ProjectDeleteTasks возвращает правило для задач удаляемого проекта
+func (c *Config) ProjectDeleteTasks() model.DeleteRule {
+	return c.projectDeleteTasks
+}
+
+// AdminToken возвращает токен административных эндпоинтов, пустой токен их выключает
+func (c *Config) AdminToken() string {
+	return c.adminToken
+}
+
+// MustLoad загружает конфиг из файла .env
 func MustLoad() *Config {
 	err := env.LoadEnv(configPath)
 	if err != nil {
@@ -55,9 +340,223 @@ func MustLoad() *Config {
 		log.Fatal("logger level not found")
 	}
 
-	return &Config{
+	cfg := &Config{
 		httpServerHost: serverHost,
 		httpServerPort: serverPort,
 		loggerLevel:    loggerLevel,
 	}
+
+	cfg.storageType = os.Getenv(storageTypeEnv)
+	if len(cfg.storageType) == 0 {
+		log.Fatal("storage type not found")
+	}
+
+	switch cfg.storageType {
+	case StorageInMemory:
+	case StorageInMemorySharded:
+		cfg.inMemoryShards = mustGetPositiveInt(inMemoryShardsEnv)
+	case StorageFile:
+		cfg.fileStorageDir = os.Getenv(fileStorageDirEnv)
+		if len(cfg.fileStorageDir) == 0 {
+			log.Fatal("file storage dir not found")
+		}
+
+		cfg.fileStorageCompactEvery = mustGetPositiveInt(fileStorageCompactEveryEnv)
+	case StorageSQL:
+		cfg.sqlDriver = os.Getenv(sqlDriverEnv)
+		if len(cfg.sqlDriver) == 0 {
+			log.Fatal("sql driver not found")
+		}
+
+		cfg.sqlDSN = os.Getenv(sqlDSNEnv)
+		if len(cfg.sqlDSN) == 0 {
+			log.Fatal("sql dsn not found")
+		}
+	case StorageEventSourced:
+		cfg.eventStorePath = os.Getenv(eventStorePathEnv)
+		if len(cfg.eventStorePath) == 0 {
+			log.Fatal("event store path not found")
+		}
+	case StorageRaft:
+		cfg.raftNodeID = os.Getenv(raftNodeIDEnv)
+		if len(cfg.raftNodeID) == 0 {
+			log.Fatal("raft node id not found")
+		}
+
+		cfg.raftBindAddr = os.Getenv(raftBindAddrEnv)
+		if _, _, err := net.SplitHostPort(cfg.raftBindAddr); err != nil {
+			log.Fatalf("%s must be host:port, got %q", raftBindAddrEnv, cfg.raftBindAddr)
+		}
+
+		cfg.raftPeers = mustGetRaftPeers(raftPeersEnv)
+
+		cfg.raftDir = os.Getenv(raftDirEnv)
+		if len(cfg.raftDir) == 0 {
+			log.Fatal("raft dir not found")
+		}
+
+		cfg.raftElectionTimeout = mustGetPositiveDuration(raftElectionTimeoutEnv)
+		cfg.raftSnapshotThreshold = mustGetPositiveInt(raftSnapshotThresholdEnv)
+	default:
+		log.Fatalf("unknown storage type: %s", cfg.storageType)
+	}
+
+	cfg.trashRetention = mustGetPositiveDuration(trashRetentionEnv)
+	cfg.trashPurgeInterval = mustGetPositiveDuration(trashPurgeIntervalEnv)
+
+	cfg.backupDir = os.Getenv(backupDirEnv)
+	if len(cfg.backupDir) == 0 {
+		log.Fatal("backup dir not found")
+	}
+
+	cfg.backupInterval = mustGetPositiveDuration(backupIntervalEnv)
+	cfg.backupKeepLast = mustGetPositiveInt(backupKeepLastEnv)
+	cfg.backupKeepDaily = mustGetNonNegativeInt(backupKeepDailyEnv)
+	cfg.backupKeepWeekly = mustGetNonNegativeInt(backupKeepWeeklyEnv)
+
+	cfg.replicationRole = os.Getenv(replicationRoleEnv)
+	if len(cfg.replicationRole) == 0 {
+		log.Fatal("replication role not found")
+	}
+
+	switch cfg.replicationRole {
+	case ReplicationStandalone:
+	case ReplicationLeader:
+		cfg.replicationLogSize = mustGetPositiveInt(replicationLogSizeEnv)
+	case ReplicationFollower:
+		cfg.replicationLeaderURL = os.Getenv(replicationLeaderURLEnv)
+
+		leaderURL, err := url.Parse(cfg.replicationLeaderURL)
+		if err != nil || (leaderURL.Scheme != "http" && leaderURL.Scheme != "https") || len(leaderURL.Host) == 0 {
+			log.Fatalf("%s must be an http(s) url, got %q", replicationLeaderURLEnv, cfg.replicationLeaderURL)
+		}
+
+		cfg.replicationPollWait = mustGetPositiveDuration(replicationPollWaitEnv)
+	default:
+		log.Fatalf("unknown replication role: %s", cfg.replicationRole)
+	}
+
+	cfg.cdcPath = os.Getenv(cdcPathEnv)
+	if len(cfg.cdcPath) == 0 {
+		log.Fatal("cdc path not found")
+	}
+
+	cfg.cdcRetention = mustGetPositiveDuration(cdcRetentionEnv)
+	cfg.cdcTrimInterval = mustGetPositiveDuration(cdcTrimIntervalEnv)
+
+	cfg.taskDeleteChildren = model.DeleteRule(os.Getenv(taskDeleteChildrenEnv))
+	switch cfg.taskDeleteChildren {
+	case model.DeleteBlock, model.DeleteCascade, model.DeleteOrphan:
+	default:
+		log.Fatalf("%s must be %s, %s or %s, got %q",
+			taskDeleteChildrenEnv, model.DeleteBlock, model.DeleteCascade, model.DeleteOrphan, cfg.taskDeleteChildren)
+	}
+
+	cfg.taskAutoCompleteParent = mustGetBool(taskAutoCompleteParentEnv)
+	cfg.taskBlockDone = mustGetBool(taskBlockDoneEnv)
+
+	workflow, err := model.ParseWorkflow(os.Getenv(taskWorkflowEnv))
+	if err != nil {
+		log.Fatalf("invalid %s: %v", taskWorkflowEnv, err)
+	}
+
+	cfg.taskWorkflow = workflow
+
+	cfg.projectDeleteTasks = model.DeleteRule(os.Getenv(projectDeleteTasksEnv))
+	switch cfg.projectDeleteTasks {
+	case model.DeleteBlock, model.DeleteCascade, model.DeleteOrphan:
+	default:
+		log.Fatalf("%s must be %s, %s or %s, got %q",
+			projectDeleteTasksEnv, model.DeleteBlock, model.DeleteCascade, model.DeleteOrphan, cfg.projectDeleteTasks)
+	}
+
+	// без токена административные эндпоинты выключены
+	cfg.adminToken = os.Getenv(adminTokenEnv)
+
+	// кластер Raft реплицирует данные сам, журнал ведущего узла ему не нужен
+	if cfg.storageType == StorageRaft && cfg.replicationRole != ReplicationStandalone {
+		log.Fatalf("%s must be %s for %s storage", replicationRoleEnv, ReplicationStandalone, StorageRaft)
+	}
+
+	return cfg
+}
+
+func mustGetPositiveInt(key string) int {
+	value := os.Getenv(key)
+	if len(value) == 0 {
+		log.Fatalf("%s not found", key)
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil || n <= 0 {
+		log.Fatalf("%s must be a positive integer, got %q", key, value)
+	}
+
+	return n
+}
+
+func mustGetNonNegativeInt(key string) int {
+	value := os.Getenv(key)
+	if len(value) == 0 {
+		log.Fatalf("%s not found", key)
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		log.Fatalf("%s must be a non-negative integer, got %q", key, value)
+	}
+
+	return n
+}
+
+func mustGetBool(key string) bool {
+	value := os.Getenv(key)
+	if len(value) == 0 {
+		log.Fatalf("%s not found", key)
+	}
+
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Fatalf("%s must be true or false, got %q", key, value)
+	}
+
+	return b
+}
+
+func mustGetPositiveDuration(key string) time.Duration {
+	value := os.Getenv(key)
+	if len(value) == 0 {
+		log.Fatalf("%s not found", key)
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		log.Fatalf("%s must be a positive duration, got %q", key, value)
+	}
+
+	return d
+}
+
+// mustGetRaftPeers разбирает список участников вида id=host:port,id=host:port.
+// Пустой список означает, что узел присоединяется к уже работающему кластеру
+func mustGetRaftPeers(key string) []RaftPeer {
+	value := os.Getenv(key)
+	if len(value) == 0 {
+		return nil
+	}
+
+	var peers []RaftPeer
+	seen := make(map[string]bool)
+
+	for _, item := range strings.Split(value, ",") {
+		id, addr, ok := strings.Cut(strings.TrimSpace(item), "=")
+		if _, _, err := net.SplitHostPort(addr); !ok || len(id) == 0 || err != nil || seen[id] {
+			log.Fatalf("%s must be a list of unique id=host:port, got %q", key, value)
+		}
+
+		seen[id] = true
+		peers = append(peers, RaftPeer{ID: id, Address: addr})
+	}
+
+	return peers
 }
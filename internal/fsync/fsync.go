@@ -0,0 +1,43 @@
+// Package fsync содержит запись файлов со сбросом на диск, общую для файловых хранилищ
+package fsync
+
+import (
+	"fmt"
+	"os"
+)
+
+// WriteFile записывает data в файл path и сбрасывает его на диск (fsync)
+func WriteFile(path string, data []byte) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to sync %s: %w", path, err)
+	}
+
+	return f.Close()
+}
+
+// Dir сбрасывает на диск каталог dir, чтобы пережили сбой созданные
+// и переименованные в нем файлы
+func Dir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return fmt.Errorf("failed to open dir %s: %w", dir, err)
+	}
+	defer d.Close()
+
+	if err := d.Sync(); err != nil {
+		return fmt.Errorf("failed to sync dir %s: %w", dir, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,42 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/solumD/tasks-service/internal/fsync"
+)
+
+func TestWriteFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "data.json")
+
+	// повторная запись заменяет содержимое целиком
+	for _, data := range []string{`{"long":"content"}`, `{}`} {
+		if err := fsync.WriteFile(path, []byte(data)); err != nil {
+			t.Fatalf("failed to write file: %v", err)
+		}
+
+		got, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("failed to read file: %v", err)
+		}
+
+		if string(got) != data {
+			t.Fatalf("expected %q, got %q", data, got)
+		}
+	}
+
+	if err := fsync.Dir(dir); err != nil {
+		t.Fatalf("failed to sync dir: %v", err)
+	}
+
+	if err := fsync.WriteFile(filepath.Join(dir, "missing", "data.json"), nil); err == nil {
+		t.Fatal("expected error for missing dir")
+	}
+
+	if err := fsync.Dir(filepath.Join(dir, "missing")); err == nil {
+		t.Fatal("expected error for missing dir")
+	}
+}
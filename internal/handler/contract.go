@@ -12,4 +12,38 @@ type Handler interface {
 	GetTaskByID(ctx context.Context) http.HandlerFunc
 	UpdateTask(ctx context.Context) http.HandlerFunc
 	DeleteTask(ctx context.Context) http.HandlerFunc
+	GetDeletedTasks(ctx context.Context) http.HandlerFunc
+	RestoreTask(ctx context.Context) http.HandlerFunc
+	GetTaskHistory(ctx context.Context) http.HandlerFunc
+	GetTaskRevision(ctx context.Context) http.HandlerFunc
+	DiffTaskRevisions(ctx context.Context) http.HandlerFunc
+	RevertTask(ctx context.Context) http.HandlerFunc
+	GetChildren(ctx context.Context) http.HandlerFunc
+	GetTaskTree(ctx context.Context) http.HandlerFunc
+	AddDependency(ctx context.Context) http.HandlerFunc
+	RemoveDependency(ctx context.Context) http.HandlerFunc
+	GetTasksInOrder(ctx context.Context) http.HandlerFunc
+	GetOccurrences(ctx context.Context) http.HandlerFunc
+	TransitionTask(ctx context.Context) http.HandlerFunc
+	MoveTask(ctx context.Context) http.HandlerFunc
+	CreateProject(ctx context.Context) http.HandlerFunc
+	GetProjects(ctx context.Context) http.HandlerFunc
+	GetProject(ctx context.Context) http.HandlerFunc
+	UpdateProject(ctx context.Context) http.HandlerFunc
+	DeleteProject(ctx context.Context) http.HandlerFunc
+	GetProjectTasks(ctx context.Context) http.HandlerFunc
+	CreateProjectTask(ctx context.Context) http.HandlerFunc
+	AddComment(ctx context.Context) http.HandlerFunc
+	GetComments(ctx context.Context) http.HandlerFunc
+	UpdateComment(ctx context.Context) http.HandlerFunc
+	DeleteComment(ctx context.Context) http.HandlerFunc
+	GetTags(ctx context.Context) http.HandlerFunc
+	RenameTag(ctx context.Context) http.HandlerFunc
+	ExportTasks(ctx context.Context) http.HandlerFunc
+	ImportTasks(ctx context.Context) http.HandlerFunc
+	RestoreBackup(ctx context.Context) http.HandlerFunc
+	GetReplicationLog(ctx context.Context) http.HandlerFunc
+	GetReplicationSnapshot(ctx context.Context) http.HandlerFunc
+	GetReplicationStatus(ctx context.Context) http.HandlerFunc
+	GetChanges(ctx context.Context) http.HandlerFunc
 }
@@ -8,10 +8,12 @@ import (
 	"github.com/solumD/tasks-service/pkg/middleware"
 )
 
-// NewRouter возвращает роутер для обработки запросов
-func NewRouter(ctx context.Context, log *slog.Logger, handler Handler) *http.ServeMux {
+// NewRouter возвращает роутер для обработки запросов. Административные эндпоинты
+// доступны только с токеном adminToken, при пустом токене они выключены
+func NewRouter(ctx context.Context, log *slog.Logger, handler Handler, adminToken string) *http.ServeMux {
 	r := http.NewServeMux()
 	loggerMW := middleware.NewMWLogger(log)
+	adminMW := middleware.NewMWAdminAuth(adminToken, log)
 
 	r.Handle(
 		"POST /todos",
@@ -38,5 +40,175 @@ func NewRouter(ctx context.Context, log *slog.Logger, handler Handler) *http.Ser
 		loggerMW(http.HandlerFunc(handler.DeleteTask(ctx))),
 	)
 
+	r.Handle(
+		"GET /todos/export",
+		loggerMW(http.HandlerFunc(handler.ExportTasks(ctx))),
+	)
+
+	r.Handle(
+		"POST /todos/import",
+		loggerMW(http.HandlerFunc(handler.ImportTasks(ctx))),
+	)
+
+	r.Handle(
+		"GET /todos/order",
+		loggerMW(http.HandlerFunc(handler.GetTasksInOrder(ctx))),
+	)
+
+	r.Handle(
+		"GET /todos/trash",
+		loggerMW(http.HandlerFunc(handler.GetDeletedTasks(ctx))),
+	)
+
+	r.Handle(
+		"POST /todos/{id}/restore",
+		loggerMW(http.HandlerFunc(handler.RestoreTask(ctx))),
+	)
+
+	r.Handle(
+		"GET /todos/{id}/history",
+		loggerMW(http.HandlerFunc(handler.GetTaskHistory(ctx))),
+	)
+
+	r.Handle(
+		"GET /todos/{id}/history/{rev}",
+		loggerMW(http.HandlerFunc(handler.GetTaskRevision(ctx))),
+	)
+
+	r.Handle(
+		"GET /todos/{id}/diff",
+		loggerMW(http.HandlerFunc(handler.DiffTaskRevisions(ctx))),
+	)
+
+	r.Handle(
+		"POST /todos/{id}/history/{rev}/revert",
+		loggerMW(http.HandlerFunc(handler.RevertTask(ctx))),
+	)
+
+	r.Handle(
+		"GET /todos/{id}/children",
+		loggerMW(http.HandlerFunc(handler.GetChildren(ctx))),
+	)
+
+	r.Handle(
+		"GET /todos/{id}/tree",
+		loggerMW(http.HandlerFunc(handler.GetTaskTree(ctx))),
+	)
+
+	r.Handle(
+		"POST /todos/{id}/dependencies",
+		loggerMW(http.HandlerFunc(handler.AddDependency(ctx))),
+	)
+
+	r.Handle(
+		"DELETE /todos/{id}/dependencies/{dep}",
+		loggerMW(http.HandlerFunc(handler.RemoveDependency(ctx))),
+	)
+
+	r.Handle(
+		"GET /todos/{id}/occurrences",
+		loggerMW(http.HandlerFunc(handler.GetOccurrences(ctx))),
+	)
+
+	r.Handle(
+		"POST /todos/{id}/transitions",
+		loggerMW(http.HandlerFunc(handler.TransitionTask(ctx))),
+	)
+
+	r.Handle(
+		"POST /todos/{id}/move",
+		loggerMW(http.HandlerFunc(handler.MoveTask(ctx))),
+	)
+
+	r.Handle(
+		"POST /todos/{id}/comments",
+		loggerMW(http.HandlerFunc(handler.AddComment(ctx))),
+	)
+
+	r.Handle(
+		"GET /todos/{id}/comments",
+		loggerMW(http.HandlerFunc(handler.GetComments(ctx))),
+	)
+
+	r.Handle(
+		"PUT /todos/{id}/comments/{cid}",
+		loggerMW(http.HandlerFunc(handler.UpdateComment(ctx))),
+	)
+
+	r.Handle(
+		"DELETE /todos/{id}/comments/{cid}",
+		loggerMW(http.HandlerFunc(handler.DeleteComment(ctx))),
+	)
+
+	r.Handle(
+		"POST /projects",
+		loggerMW(http.HandlerFunc(handler.CreateProject(ctx))),
+	)
+
+	r.Handle(
+		"GET /projects",
+		loggerMW(http.HandlerFunc(handler.GetProjects(ctx))),
+	)
+
+	r.Handle(
+		"GET /projects/{pid}",
+		loggerMW(http.HandlerFunc(handler.GetProject(ctx))),
+	)
+
+	r.Handle(
+		"PUT /projects/{pid}",
+		loggerMW(http.HandlerFunc(handler.UpdateProject(ctx))),
+	)
+
+	r.Handle(
+		"DELETE /projects/{pid}",
+		loggerMW(http.HandlerFunc(handler.DeleteProject(ctx))),
+	)
+
+	r.Handle(
+		"GET /projects/{pid}/todos",
+		loggerMW(http.HandlerFunc(handler.GetProjectTasks(ctx))),
+	)
+
+	r.Handle(
+		"POST /projects/{pid}/todos",
+		loggerMW(http.HandlerFunc(handler.CreateProjectTask(ctx))),
+	)
+
+	r.Handle(
+		"GET /tags",
+		loggerMW(http.HandlerFunc(handler.GetTags(ctx))),
+	)
+
+	r.Handle(
+		"POST /tags/{tag}/rename",
+		loggerMW(http.HandlerFunc(handler.RenameTag(ctx))),
+	)
+
+	r.Handle(
+		"POST /admin/restore",
+		loggerMW(adminMW(http.HandlerFunc(handler.RestoreBackup(ctx)))),
+	)
+
+	r.Handle(
+		"GET /replication/log",
+		loggerMW(http.HandlerFunc(handler.GetReplicationLog(ctx))),
+	)
+
+	r.Handle(
+		"GET /replication/snapshot",
+		loggerMW(http.HandlerFunc(handler.GetReplicationSnapshot(ctx))),
+	)
+
+	r.Handle(
+		"GET /replication/status",
+		loggerMW(http.HandlerFunc(handler.GetReplicationStatus(ctx))),
+	)
+
+	r.Handle(
+		"GET /changes",
+		loggerMW(http.HandlerFunc(handler.GetChanges(ctx))),
+	)
+
 	return r
 }
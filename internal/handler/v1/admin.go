@@ -0,0 +1,74 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/solumD/tasks-service/internal/backup"
+	"github.com/solumD/tasks-service/internal/handler/v1/dto"
+	"github.com/solumD/tasks-service/internal/usecase"
+	"github.com/solumD/tasks-service/pkg/logger"
+)
+
+// RestoreBackup обрабатывает запрос на восстановление всех задач из резервной копии
+func (h *handler) RestoreBackup(ctx context.Context) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const fn = "handler.RestoreBackup"
+		log := h.log.With(logger.String("fn", fn))
+
+		log.Info("new request")
+
+		var req dto.RestoreBackupReq
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			log.Error("failed to decode request", logger.Error(err))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusBadRequest, ErrFailedToDecodeReq)
+			return
+		}
+
+		log.Info("decoded request", logger.Any("request body", req))
+
+		info, err := h.backupUsecase.RestoreBackup(ctx, req.Backup)
+		if err != nil {
+			switch {
+			case errors.Is(err, backup.ErrInvalidBackupName):
+				log.Error("failed to restore backup", logger.Error(err))
+
+				h.errorResponse(w, contentTypeJSON, http.StatusBadRequest, err)
+			case errors.Is(err, backup.ErrBackupNotFound):
+				log.Error("failed to restore backup", logger.Error(err))
+
+				h.errorResponse(w, contentTypeJSON, http.StatusNotFound, err)
+			case errors.Is(err, backup.ErrBackupCorrupted):
+				log.Error("failed to restore backup", logger.Error(err))
+
+				h.errorResponse(w, contentTypeJSON, http.StatusUnprocessableEntity, err)
+			case errors.Is(err, usecase.ErrProjectsDisabled):
+				log.Error("failed to restore backup", logger.Error(err))
+
+				h.errorResponse(w, contentTypeJSON, http.StatusNotImplemented, err)
+			default:
+				log.Error("failed to restore backup", logger.Error(err))
+
+				h.errorResponse(w, contentTypeJSON, http.StatusInternalServerError, ErrFailedToRestoreBackup)
+			}
+
+			return
+		}
+
+		resp := dto.FromBackupInfoToResp(info)
+		respBody, err := json.Marshal(resp)
+		if err != nil {
+			log.Error("failed to marshal response", logger.Error(err))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusInternalServerError, ErrFailedToRestoreBackup)
+			return
+		}
+
+		log.Info("restored backup", logger.String("backup", info.Name), logger.Int("tasks count", info.Tasks))
+
+		h.response(w, contentTypeJSON, http.StatusOK, respBody)
+	}
+}
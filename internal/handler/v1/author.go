@@ -0,0 +1,16 @@
+package v1
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/solumD/tasks-service/internal/usecase"
+)
+
+// headerAuthor заголовок с автором изменения, который попадает в ревизию задачи
+const headerAuthor = "X-Author"
+
+// withAuthor добавляет в контекст автора изменения из заголовка запроса
+func withAuthor(ctx context.Context, r *http.Request) context.Context {
+	return usecase.ContextWithAuthor(ctx, r.Header.Get(headerAuthor))
+}
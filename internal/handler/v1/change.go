@@ -0,0 +1,100 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/solumD/tasks-service/internal/cdc"
+	"github.com/solumD/tasks-service/internal/handler/v1/dto"
+	"github.com/solumD/tasks-service/pkg/logger"
+)
+
+// defaultChangesLimit количество событий в ответе, если limit не указан
+const defaultChangesLimit = 100
+
+// GetChanges обрабатывает запрос потребителя на чтение потока изменений задач
+func (h *handler) GetChanges(_ context.Context) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const fn = "handler.GetChanges"
+		log := h.log.With(logger.String("fn", fn))
+
+		log.Info("new request")
+
+		query := r.URL.Query()
+
+		// без from чтение начинается с самого старого хранимого события
+		from := cdc.FromOldest
+		if value := query.Get("from"); value != "" {
+			var err error
+			from, err = strconv.Atoi(value)
+			if err != nil || from < 0 {
+				log.Error("failed to get stream position from query", logger.String("from", value))
+
+				h.errorResponse(w, contentTypeJSON, http.StatusBadRequest, ErrInvalidChangePosition)
+				return
+			}
+		}
+
+		limit := defaultChangesLimit
+		if value := query.Get("limit"); value != "" {
+			var err error
+			limit, err = strconv.Atoi(value)
+			if err != nil || limit <= 0 {
+				log.Error("failed to get limit from query", logger.String("limit", value))
+
+				h.errorResponse(w, contentTypeJSON, http.StatusBadRequest, ErrInvalidLimit)
+				return
+			}
+		}
+
+		var wait time.Duration
+		if value := query.Get("wait"); value != "" {
+			var err error
+			wait, err = time.ParseDuration(value)
+			if err != nil || wait < 0 {
+				log.Error("failed to get wait from query", logger.String("wait", value))
+
+				h.errorResponse(w, contentTypeJSON, http.StatusBadRequest, ErrInvalidWait)
+				return
+			}
+		}
+
+		// ожидание новых событий прерывается, когда потребитель закрывает соединение
+		batch, err := h.changeUsecase.Read(r.Context(), from, limit, wait)
+		if err != nil {
+			switch {
+			case errors.Is(err, cdc.ErrPositionExpired):
+				log.Error("failed to read change stream", logger.Error(err))
+
+				h.errorResponse(w, contentTypeJSON, http.StatusGone, err)
+			case errors.Is(err, cdc.ErrPositionAhead):
+				log.Error("failed to read change stream", logger.Error(err))
+
+				h.errorResponse(w, contentTypeJSON, http.StatusBadRequest, err)
+			default:
+				log.Error("failed to read change stream", logger.Error(err))
+
+				h.errorResponse(w, contentTypeJSON, http.StatusInternalServerError, ErrFailedToReadChanges)
+			}
+
+			return
+		}
+
+		resp := dto.FromChangeBatchToResp(batch)
+		respBody, err := json.Marshal(resp)
+		if err != nil {
+			log.Error("failed to marshal response", logger.Error(err))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusInternalServerError, ErrFailedToReadChanges)
+			return
+		}
+
+		log.Info("read change stream", logger.Int("from", from), logger.Int("events count", len(batch.Events)))
+
+		h.response(w, contentTypeJSON, http.StatusOK, respBody)
+	}
+}
@@ -0,0 +1,317 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/solumD/tasks-service/internal/handler/v1/dto"
+	"github.com/solumD/tasks-service/internal/model"
+	"github.com/solumD/tasks-service/internal/usecase"
+	"github.com/solumD/tasks-service/pkg/logger"
+)
+
+// AddComment обрабатывает запрос на добавление комментария к задаче или ответа на комментарий.
+// Автор комментария берется из заголовка X-Author
+func (h *handler) AddComment(ctx context.Context) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const fn = "handler.AddComment"
+		log := h.log.With(logger.String("fn", fn))
+		ctx := withAuthor(ctx, r)
+
+		log.Info("new request")
+
+		taskID, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			log.Error("failed to get task id from path", logger.Error(err))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusBadRequest, ErrInvalidTaskIDType)
+			return
+		}
+
+		log.Info("got task id from path", logger.Int("task id", taskID))
+
+		var req dto.CreateCommentReq
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			log.Error("failed to decode request", logger.Error(err))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusBadRequest, ErrFailedToDecodeReq)
+			return
+		}
+
+		log.Info("decoded request", logger.Any("request body", req))
+
+		comment := dto.FromCreateCommentReqToComment(req)
+		comment.TaskID = taskID
+
+		id, err := h.commentUsecase.AddComment(ctx, comment)
+		if err != nil {
+			if errors.Is(err, usecase.ErrEmptyCommentText) || errors.Is(err, usecase.ErrCommentTooLong) ||
+				errors.Is(err, usecase.ErrEmptyCommentAuthor) || errors.Is(err, usecase.ErrCommentParentNotFound) ||
+				errors.Is(err, usecase.ErrNestedReply) {
+				log.Error("failed to create comment", logger.Error(err))
+
+				h.errorResponse(w, contentTypeJSON, http.StatusBadRequest, err)
+				return
+			}
+
+			if errors.Is(err, usecase.ErrTaskNotFound) {
+				log.Error("failed to create comment", logger.Error(err))
+
+				h.errorResponse(w, contentTypeJSON, http.StatusNotFound, err)
+				return
+			}
+
+			if errors.Is(err, usecase.ErrCommentsDisabled) {
+				log.Error("failed to create comment", logger.Error(err))
+
+				h.errorResponse(w, contentTypeJSON, http.StatusNotImplemented, err)
+				return
+			}
+
+			log.Error("failed to create comment", logger.Error(err))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusInternalServerError, ErrFailedToCreateComment)
+			return
+		}
+
+		resp := &dto.CreateCommentResp{ID: id}
+		respBody, err := json.Marshal(resp)
+		if err != nil {
+			log.Error("failed to marshal response", logger.Error(err))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusInternalServerError, ErrFailedToCreateComment)
+			return
+		}
+
+		log.Info("created comment", logger.Int("task id", taskID), logger.Int("comment id", id))
+
+		h.response(w, contentTypeJSON, http.StatusCreated, respBody)
+	}
+}
+
+// GetComments обрабатывает запрос на получение страницы комментариев задачи с ответами на них.
+// Страница начинается после комментария из параметра after, ее размер задает limit
+func (h *handler) GetComments(ctx context.Context) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const fn = "handler.GetComments"
+		log := h.log.With(logger.String("fn", fn))
+
+		log.Info("new request")
+
+		taskID, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			log.Error("failed to get task id from path", logger.Error(err))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusBadRequest, ErrInvalidTaskIDType)
+			return
+		}
+
+		log.Info("got task id from path", logger.Int("task id", taskID))
+
+		query := r.URL.Query()
+
+		var after int
+		if value := query.Get("after"); value != "" {
+			after, err = strconv.Atoi(value)
+			if err != nil || after < 0 {
+				log.Error("failed to get after from query", logger.String("after", value))
+
+				h.errorResponse(w, contentTypeJSON, http.StatusBadRequest, ErrInvalidAfter)
+				return
+			}
+		}
+
+		// без limit юзкейс возвращает страницу размера по умолчанию
+		var limit int
+		if value := query.Get("limit"); value != "" {
+			limit, err = strconv.Atoi(value)
+			if err != nil || limit <= 0 {
+				log.Error("failed to get limit from query", logger.String("limit", value))
+
+				h.errorResponse(w, contentTypeJSON, http.StatusBadRequest, ErrInvalidLimit)
+				return
+			}
+		}
+
+		page, err := h.commentUsecase.GetComments(ctx, taskID, after, limit)
+		if err != nil {
+			if errors.Is(err, usecase.ErrInvalidCommentsLimit) {
+				log.Error("failed to get comments", logger.Error(err))
+
+				h.errorResponse(w, contentTypeJSON, http.StatusBadRequest, err)
+				return
+			}
+
+			if errors.Is(err, usecase.ErrTaskNotFound) {
+				log.Error("failed to get comments", logger.Error(err))
+
+				h.errorResponse(w, contentTypeJSON, http.StatusNotFound, err)
+				return
+			}
+
+			log.Error("failed to get comments", logger.Error(err))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusInternalServerError, ErrFailedToGetComments)
+			return
+		}
+
+		resp := dto.FromCommentPageToResp(page)
+		respBody, err := json.Marshal(resp)
+		if err != nil {
+			log.Error("failed to marshal response", logger.Error(err))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusInternalServerError, ErrFailedToGetComments)
+			return
+		}
+
+		log.Info("got comments", logger.Int("task id", taskID), logger.Int("threads count", len(page.Threads)))
+
+		h.response(w, contentTypeJSON, http.StatusOK, respBody)
+	}
+}
+
+// UpdateComment обрабатывает запрос на изменение текста комментария его автором
+func (h *handler) UpdateComment(ctx context.Context) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const fn = "handler.UpdateComment"
+		log := h.log.With(logger.String("fn", fn))
+		ctx := withAuthor(ctx, r)
+
+		log.Info("new request")
+
+		taskID, commentID, err := commentIDsFromPath(r)
+		if err != nil {
+			log.Error("failed to get ids from path", logger.Error(err))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusBadRequest, err)
+			return
+		}
+
+		log.Info("got ids from path", logger.Int("task id", taskID), logger.Int("comment id", commentID))
+
+		var req dto.UpdateCommentReq
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			log.Error("failed to decode request", logger.Error(err))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusBadRequest, ErrFailedToDecodeReq)
+			return
+		}
+
+		log.Info("decoded request", logger.Any("request body", req))
+
+		comment, err := h.commentUsecase.UpdateComment(ctx, &model.Comment{ID: commentID, TaskID: taskID, Text: req.Text})
+		if err != nil {
+			if errors.Is(err, usecase.ErrEmptyCommentText) || errors.Is(err, usecase.ErrCommentTooLong) ||
+				errors.Is(err, usecase.ErrEmptyCommentAuthor) {
+				log.Error("failed to update comment", logger.Error(err))
+
+				h.errorResponse(w, contentTypeJSON, http.StatusBadRequest, err)
+				return
+			}
+
+			if errors.Is(err, usecase.ErrCommentForbidden) {
+				log.Error("failed to update comment", logger.Error(err))
+
+				h.errorResponse(w, contentTypeJSON, http.StatusForbidden, err)
+				return
+			}
+
+			if errors.Is(err, usecase.ErrTaskNotFound) || errors.Is(err, usecase.ErrCommentNotFound) {
+				log.Error("failed to update comment", logger.Error(err))
+
+				h.errorResponse(w, contentTypeJSON, http.StatusNotFound, err)
+				return
+			}
+
+			log.Error("failed to update comment", logger.Error(err))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusInternalServerError, ErrFailedToUpdateComment)
+			return
+		}
+
+		resp := dto.FromCommentToDTO(comment)
+		respBody, err := json.Marshal(resp)
+		if err != nil {
+			log.Error("failed to marshal response", logger.Error(err))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusInternalServerError, ErrFailedToUpdateComment)
+			return
+		}
+
+		log.Info("updated comment", logger.Int("comment id", comment.ID))
+
+		h.response(w, contentTypeJSON, http.StatusOK, respBody)
+	}
+}
+
+// DeleteComment обрабатывает запрос на удаление комментария его автором вместе с ответами на него
+func (h *handler) DeleteComment(ctx context.Context) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const fn = "handler.DeleteComment"
+		log := h.log.With(logger.String("fn", fn))
+		ctx := withAuthor(ctx, r)
+
+		log.Info("new request")
+
+		taskID, commentID, err := commentIDsFromPath(r)
+		if err != nil {
+			log.Error("failed to get ids from path", logger.Error(err))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusBadRequest, err)
+			return
+		}
+
+		log.Info("got ids from path", logger.Int("task id", taskID), logger.Int("comment id", commentID))
+
+		err = h.commentUsecase.DeleteComment(ctx, taskID, commentID)
+		if err != nil {
+			if errors.Is(err, usecase.ErrEmptyCommentAuthor) {
+				log.Error("failed to delete comment", logger.Error(err))
+
+				h.errorResponse(w, contentTypeJSON, http.StatusBadRequest, err)
+				return
+			}
+
+			if errors.Is(err, usecase.ErrCommentForbidden) {
+				log.Error("failed to delete comment", logger.Error(err))
+
+				h.errorResponse(w, contentTypeJSON, http.StatusForbidden, err)
+				return
+			}
+
+			if errors.Is(err, usecase.ErrTaskNotFound) || errors.Is(err, usecase.ErrCommentNotFound) {
+				log.Error("failed to delete comment", logger.Error(err))
+
+				h.errorResponse(w, contentTypeJSON, http.StatusNotFound, err)
+				return
+			}
+
+			log.Error("failed to delete comment", logger.Error(err))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusInternalServerError, ErrFailedToDeleteComment)
+			return
+		}
+
+		log.Info("deleted comment", logger.Int("comment id", commentID))
+
+		h.response(w, contentTypeJSON, http.StatusOK, nil)
+	}
+}
+
+// commentIDsFromPath возвращает ID задачи и комментария из пути запроса
+func commentIDsFromPath(r *http.Request) (int, int, error) {
+	taskID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		return 0, 0, ErrInvalidTaskIDType
+	}
+
+	commentID, err := strconv.Atoi(r.PathValue("cid"))
+	if err != nil {
+		return 0, 0, ErrInvalidCommentIDType
+	}
+
+	return taskID, commentID, nil
+}
@@ -2,6 +2,7 @@ package v1
 
 import (
 	"context"
+	"time"
 
 	"github.com/solumD/tasks-service/internal/model"
 )
@@ -9,8 +10,61 @@ import (
 // TaskUsecase интерфейс изкейса Task
 type TaskUsecase interface {
 	CreateTask(ctx context.Context, task *model.Task) (int, error)
-	GetAllTasks(ctx context.Context) ([]*model.Task, error)
+	GetAllTasks(ctx context.Context, filter model.TaskFilter) ([]*model.Task, error)
 	GetTaskByID(ctx context.Context, id int) (*model.Task, error)
 	UpdateTask(ctx context.Context, task *model.Task) error
-	DeleteTask(ctx context.Context, id int) error
+	DeleteTask(ctx context.Context, id int, version int) error
+	GetDeletedTasks(ctx context.Context) ([]*model.Task, error)
+	RestoreTask(ctx context.Context, id int) (*model.Task, error)
+	GetTaskHistory(ctx context.Context, id int) ([]*model.Revision, error)
+	GetTaskRevision(ctx context.Context, id int, rev int) (*model.Revision, error)
+	DiffTaskRevisions(ctx context.Context, id int, from int, to int) ([]model.FieldChange, error)
+	RevertTask(ctx context.Context, id int, rev int, version int) (*model.Task, error)
+	ImportTasks(ctx context.Context, tasks []*model.Task, dryRun bool) []error
+	GetTags(ctx context.Context) ([]model.TagCount, error)
+	RenameTag(ctx context.Context, from, to string) ([]*model.Task, error)
+	GetChildren(ctx context.Context, id int) ([]*model.Task, error)
+	GetTaskTree(ctx context.Context, id int, depth int) (*model.TaskNode, error)
+	AddDependency(ctx context.Context, id int, dependsOn int, version int) (*model.Task, error)
+	RemoveDependency(ctx context.Context, id int, dependsOn int, version int) (*model.Task, error)
+	GetTasksInOrder(ctx context.Context) ([]*model.Task, error)
+	GetOccurrences(ctx context.Context, id int, count int) ([]time.Time, error)
+	TransitionTask(ctx context.Context, id int, to model.Status, version int) (*model.Task, error)
+}
+
+// ProjectUsecase интерфейс юзкейса Project
+type ProjectUsecase interface {
+	CreateProject(ctx context.Context, project *model.Project) (int, error)
+	GetProjects(ctx context.Context) ([]*model.Project, error)
+	GetProject(ctx context.Context, id int) (*model.Project, error)
+	UpdateProject(ctx context.Context, project *model.Project, archived bool) error
+	DeleteProject(ctx context.Context, id int, version int) error
+	GetProjectTasks(ctx context.Context, id int, filter model.TaskFilter) ([]*model.Task, error)
+	CreateProjectTask(ctx context.Context, id int, task *model.Task) (int, error)
+	MoveTask(ctx context.Context, id int, projectID int, version int) (*model.Task, error)
+}
+
+// CommentUsecase интерфейс юзкейса Comment
+type CommentUsecase interface {
+	AddComment(ctx context.Context, comment *model.Comment) (int, error)
+	GetComments(ctx context.Context, taskID int, after int, limit int) (*model.CommentPage, error)
+	UpdateComment(ctx context.Context, comment *model.Comment) (*model.Comment, error)
+	DeleteComment(ctx context.Context, taskID int, id int) error
+}
+
+// BackupUsecase интерфейс восстановления из резервных копий
+type BackupUsecase interface {
+	RestoreBackup(ctx context.Context, name string) (*model.BackupInfo, error)
+}
+
+// ReplicationUsecase интерфейс репликации задач между узлами
+type ReplicationUsecase interface {
+	ReadLog(ctx context.Context, followerID string, from int, wait time.Duration) (*model.LogBatch, error)
+	Snapshot(ctx context.Context) (*model.ReplicationSnapshot, error)
+	Status() *model.ReplicationStatus
+}
+
+// ChangeUsecase интерфейс потока изменений задач
+type ChangeUsecase interface {
+	Read(ctx context.Context, from int, limit int, wait time.Duration) (*model.ChangeBatch, error)
 }
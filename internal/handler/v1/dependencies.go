@@ -0,0 +1,199 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/solumD/tasks-service/internal/handler/v1/dto"
+	"github.com/solumD/tasks-service/internal/usecase"
+	"github.com/solumD/tasks-service/pkg/logger"
+)
+
+// AddDependency обрабатывает запрос на добавление задаче зависимости
+func (h *handler) AddDependency(ctx context.Context) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const fn = "handler.AddDependency"
+		log := h.log.With(logger.String("fn", fn))
+		ctx := withAuthor(ctx, r)
+
+		log.Info("new request")
+
+		taskID, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			log.Error("failed to get task id from path", logger.Error(err))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusBadRequest, ErrInvalidTaskIDType)
+			return
+		}
+
+		var req dto.AddDependencyReq
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			log.Error("failed to decode request", logger.Error(err))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusBadRequest, ErrFailedToDecodeReq)
+			return
+		}
+
+		log.Info("decoded request", logger.Int("task id", taskID), logger.Any("request body", req))
+
+		version, err := versionFromIfMatch(r, h.taskVersion(ctx, taskID))
+		if err != nil {
+			log.Error("failed to get task version from header", logger.Error(err))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusBadRequest, err)
+			return
+		}
+
+		task, err := h.taskUsecase.AddDependency(ctx, taskID, req.DependsOn, version)
+		if err != nil {
+			if errors.Is(err, usecase.ErrInvalidDependency) || errors.Is(err, usecase.ErrDependencyNotFound) ||
+				errors.Is(err, usecase.ErrDependencyCycle) {
+				log.Error("failed to add task dependency", logger.Error(err))
+
+				h.errorResponse(w, contentTypeJSON, http.StatusBadRequest, err)
+				return
+			}
+
+			if errors.Is(err, usecase.ErrTaskNotFound) {
+				log.Error("failed to add task dependency", logger.Error(err))
+
+				h.errorResponse(w, contentTypeJSON, http.StatusNotFound, err)
+				return
+			}
+
+			if errors.Is(err, usecase.ErrVersionConflict) {
+				log.Error("failed to add task dependency", logger.Error(err))
+
+				h.errorResponse(w, contentTypeJSON, http.StatusPreconditionFailed, err)
+				return
+			}
+
+			log.Error("failed to add task dependency", logger.Error(err))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusInternalServerError, ErrFailedToAddDependency)
+			return
+		}
+
+		resp := dto.FromTaskToResp(task)
+		respBody, err := json.Marshal(resp)
+		if err != nil {
+			log.Error("failed to marshal response", logger.Error(err))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusInternalServerError, ErrFailedToAddDependency)
+			return
+		}
+
+		log.Info("added task dependency", logger.Int("task id", taskID), logger.Int("depends on", req.DependsOn))
+
+		w.Header().Set(headerETag, etag(task.Version))
+		h.response(w, contentTypeJSON, http.StatusOK, respBody)
+	}
+}
+
+// RemoveDependency обрабатывает запрос на удаление зависимости задачи
+func (h *handler) RemoveDependency(ctx context.Context) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const fn = "handler.RemoveDependency"
+		log := h.log.With(logger.String("fn", fn))
+		ctx := withAuthor(ctx, r)
+
+		log.Info("new request")
+
+		taskID, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			log.Error("failed to get task id from path", logger.Error(err))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusBadRequest, ErrInvalidTaskIDType)
+			return
+		}
+
+		dependsOn, err := strconv.Atoi(r.PathValue("dep"))
+		if err != nil {
+			log.Error("failed to get dependency id from path", logger.Error(err))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusBadRequest, ErrInvalidDependencyID)
+			return
+		}
+
+		log.Info("got dependency from path", logger.Int("task id", taskID), logger.Int("depends on", dependsOn))
+
+		version, err := versionFromIfMatch(r, h.taskVersion(ctx, taskID))
+		if err != nil {
+			log.Error("failed to get task version from header", logger.Error(err))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusBadRequest, err)
+			return
+		}
+
+		task, err := h.taskUsecase.RemoveDependency(ctx, taskID, dependsOn, version)
+		if err != nil {
+			if errors.Is(err, usecase.ErrTaskNotFound) || errors.Is(err, usecase.ErrDependencyNotFound) {
+				log.Error("failed to remove task dependency", logger.Error(err))
+
+				h.errorResponse(w, contentTypeJSON, http.StatusNotFound, err)
+				return
+			}
+
+			if errors.Is(err, usecase.ErrVersionConflict) {
+				log.Error("failed to remove task dependency", logger.Error(err))
+
+				h.errorResponse(w, contentTypeJSON, http.StatusPreconditionFailed, err)
+				return
+			}
+
+			log.Error("failed to remove task dependency", logger.Error(err))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusInternalServerError, ErrFailedToRemoveDependency)
+			return
+		}
+
+		resp := dto.FromTaskToResp(task)
+		respBody, err := json.Marshal(resp)
+		if err != nil {
+			log.Error("failed to marshal response", logger.Error(err))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusInternalServerError, ErrFailedToRemoveDependency)
+			return
+		}
+
+		log.Info("removed task dependency", logger.Int("task id", taskID), logger.Int("depends on", dependsOn))
+
+		w.Header().Set(headerETag, etag(task.Version))
+		h.response(w, contentTypeJSON, http.StatusOK, respBody)
+	}
+}
+
+// GetTasksInOrder обрабатывает запрос на получение задач в порядке выполнения:
+// каждая задача идет после задач, от которых зависит
+func (h *handler) GetTasksInOrder(ctx context.Context) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const fn = "handler.GetTasksInOrder"
+		log := h.log.With(logger.String("fn", fn))
+
+		log.Info("new request")
+
+		tasks, err := h.taskUsecase.GetTasksInOrder(ctx)
+		if err != nil {
+			log.Error("failed to get tasks in order", logger.Error(err))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusInternalServerError, ErrFailedToGetOrder)
+			return
+		}
+
+		resp := dto.FromTasksListToResp(tasks)
+		respBody, err := json.Marshal(resp)
+		if err != nil {
+			log.Error("failed to marshal response", logger.Error(err))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusInternalServerError, ErrFailedToGetOrder)
+			return
+		}
+
+		log.Info("got tasks in order", logger.Int("tasks count", len(tasks)))
+
+		h.response(w, contentTypeJSON, http.StatusOK, respBody)
+	}
+}
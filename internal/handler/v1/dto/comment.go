@@ -0,0 +1,38 @@
+package dto
+
+import "time"
+
+type CreateCommentReq struct {
+	Text     string `json:"text"`
+	ParentID int    `json:"parent_id"`
+}
+
+type CreateCommentResp struct {
+	ID int `json:"id"`
+}
+
+type UpdateCommentReq struct {
+	Text string `json:"text"`
+}
+
+type CommentDTO struct {
+	ID        int        `json:"id"`
+	ParentID  int        `json:"parent_id,omitempty"`
+	Author    string     `json:"author"`
+	Text      string     `json:"text"`
+	CreatedAt time.Time  `json:"created_at"`
+	EditedAt  *time.Time `json:"edited_at,omitempty"`
+}
+
+// CommentThreadDTO комментарий верхнего уровня с ответами на него
+type CommentThreadDTO struct {
+	*CommentDTO
+	Replies []*CommentDTO `json:"replies"`
+}
+
+// GetCommentsResp страница комментариев; next - значение after для следующей страницы,
+// если она есть
+type GetCommentsResp struct {
+	Comments []*CommentThreadDTO `json:"comments"`
+	Next     int                 `json:"next,omitempty"`
+}
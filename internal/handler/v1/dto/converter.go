@@ -1,12 +1,24 @@
 package dto
 
-import "github.com/solumD/tasks-service/internal/model"
+import (
+	"time"
+
+	"github.com/solumD/tasks-service/internal/model"
+)
 
 func FromCreateReqToTask(req CreateTaskReq) *model.Task {
 	return &model.Task{
 		Title:       req.Title,
 		Description: req.Description,
 		Done:        req.Done,
+		Status:      model.Status(req.Status),
+		DueAt:       req.DueAt,
+		Priority:    model.Priority(req.Priority),
+		Tags:        req.Tags,
+		ParentID:    req.ParentID,
+		DependsOn:   req.DependsOn,
+		ProjectID:   req.ProjectID,
+		Recurrence:  req.Recurrence,
 	}
 }
 
@@ -16,14 +28,74 @@ func FromTaskToResp(task *model.Task) *GetTaskByIDResp {
 		Title:       task.Title,
 		Description: task.Description,
 		Done:        task.Done,
+		Status:      string(task.CurrentStatus()),
+		DueAt:       task.DueAt,
+		Priority:    task.Priority.String(),
+		Tags:        task.Tags,
+		ParentID:    task.ParentID,
+		DependsOn:   task.DependsOn,
+		ProjectID:   task.ProjectID,
+		Recurrence:  task.Recurrence,
+		Blocked:     task.Blocked,
+		CreatedAt:   createdAt(task),
+		Version:     task.Version,
 	}
 }
 
+func FromImportReqToTask(req ImportTaskReq) *model.Task {
+	task := FromCreateReqToTask(req.CreateTaskReq)
+	task.ID = req.ID
+	if req.CreatedAt != nil {
+		task.CreatedAt = req.CreatedAt.UTC()
+	}
+
+	return task
+}
+
+// createdAt возвращает время создания задачи, nil - задача создана до появления времени создания
+func createdAt(task *model.Task) *time.Time {
+	if task.CreatedAt.IsZero() {
+		return nil
+	}
+
+	createdAt := task.CreatedAt
+	return &createdAt
+}
+
 func FromUpdateReqToTask(req UpdateTaskReq) *model.Task {
 	return &model.Task{
 		Title:       req.Title,
 		Description: req.Description,
 		Done:        req.Done,
+		Status:      model.Status(req.Status),
+		DueAt:       req.DueAt,
+		Priority:    model.Priority(req.Priority),
+		Tags:        req.Tags,
+		ParentID:    req.ParentID,
+		DependsOn:   req.DependsOn,
+		ProjectID:   req.ProjectID,
+		Recurrence:  req.Recurrence,
+	}
+}
+
+func FromTaskToDTO(task *model.Task) *TaskDTO {
+	return &TaskDTO{
+		ID:          task.ID,
+		Title:       task.Title,
+		Description: task.Description,
+		Done:        task.Done,
+		Status:      string(task.CurrentStatus()),
+		DueAt:       task.DueAt,
+		Priority:    task.Priority.String(),
+		Tags:        task.Tags,
+		ParentID:    task.ParentID,
+		DependsOn:   task.DependsOn,
+		ProjectID:   task.ProjectID,
+		Recurrence:  task.Recurrence,
+		Blocked:     task.Blocked,
+		CreatedAt:   createdAt(task),
+		Version:     task.Version,
+		DeletedAt:   task.DeletedAt,
 	}
 }
 
@@ -31,15 +103,361 @@ func FromTasksListToResp(tasks []*model.Task) *GetAllTasksResp {
 	list := make([]*TaskDTO, 0, len(tasks))
 
 	for _, task := range tasks {
-		list = append(list, &TaskDTO{
-			ID:          task.ID,
-			Title:       task.Title,
-			Description: task.Description,
-			Done:        task.Done,
-		})
+		list = append(list, FromTaskToDTO(task))
 	}
 
 	return &GetAllTasksResp{
 		Tasks: list,
 	}
 }
+
+func FromTaskNodeToDTO(node *model.TaskNode) *TaskNodeDTO {
+	children := make([]*TaskNodeDTO, 0, len(node.Children))
+
+	for _, child := range node.Children {
+		children = append(children, FromTaskNodeToDTO(child))
+	}
+
+	return &TaskNodeDTO{
+		TaskDTO:     FromTaskToDTO(node.Task),
+		HasChildren: node.HasChildren,
+		Children:    children,
+	}
+}
+
+func FromTagCountsToResp(counts []model.TagCount) *GetTagsResp {
+	list := make([]*TagCountDTO, 0, len(counts))
+
+	for _, count := range counts {
+		list = append(list, &TagCountDTO{
+			Tag:   count.Tag,
+			Count: count.Count,
+		})
+	}
+
+	return &GetTagsResp{
+		Tags: list,
+	}
+}
+
+func FromRevisionToDTO(rev *model.Revision) *RevisionDTO {
+	return &RevisionDTO{
+		Rev:       rev.Rev,
+		Action:    string(rev.Action),
+		Author:    rev.Author,
+		CreatedAt: rev.CreatedAt,
+		Task:      FromTaskToDTO(&rev.Task),
+	}
+}
+
+func FromRevisionsListToResp(revisions []*model.Revision) *GetTaskHistoryResp {
+	list := make([]*RevisionDTO, 0, len(revisions))
+
+	for _, rev := range revisions {
+		list = append(list, FromRevisionToDTO(rev))
+	}
+
+	return &GetTaskHistoryResp{
+		Revisions: list,
+	}
+}
+
+func FromChangesToResp(from, to int, changes []model.FieldChange) *DiffTaskRevisionsResp {
+	list := make([]*FieldChangeDTO, 0, len(changes))
+
+	for _, change := range changes {
+		list = append(list, &FieldChangeDTO{
+			Field: change.Field,
+			From:  change.From,
+			To:    change.To,
+		})
+	}
+
+	return &DiffTaskRevisionsResp{
+		From:    from,
+		To:      to,
+		Changes: list,
+	}
+}
+
+func FromBackupInfoToResp(info *model.BackupInfo) *RestoreBackupResp {
+	return &RestoreBackupResp{
+		Backup:    info.Name,
+		CreatedAt: info.CreatedAt,
+		Tasks:     info.Tasks,
+		NextID:    info.NextID,
+		Checksum:  info.Checksum,
+		Projects:  info.Projects,
+	}
+}
+
+func FromTaskDTOToTask(task *TaskDTO) *model.Task {
+	t := &model.Task{
+		ID:          task.ID,
+		Title:       task.Title,
+		Description: task.Description,
+		Done:        task.Done,
+		Status:      model.Status(task.Status),
+		DueAt:       task.DueAt,
+		Priority:    model.Priority(task.Priority),
+		Tags:        task.Tags,
+		ParentID:    task.ParentID,
+		DependsOn:   task.DependsOn,
+		ProjectID:   task.ProjectID,
+		Recurrence:  task.Recurrence,
+		Version:     task.Version,
+		DeletedAt:   task.DeletedAt,
+	}
+
+	if task.CreatedAt != nil {
+		t.CreatedAt = task.CreatedAt.UTC()
+	}
+
+	return t
+}
+
+func FromLogBatchToResp(batch *model.LogBatch) *ReplicationLogResp {
+	entries := make([]*LogEntryDTO, 0, len(batch.Entries))
+
+	for _, entry := range batch.Entries {
+		e := &LogEntryDTO{
+			Seq:       entry.Seq,
+			Op:        string(entry.Op),
+			CreatedAt: entry.CreatedAt,
+			NextID:    entry.NextID,
+		}
+
+		for _, task := range entry.Tasks {
+			e.Tasks = append(e.Tasks, FromTaskToDTO(task))
+		}
+
+		if !entry.DeletedBefore.IsZero() {
+			deletedBefore := entry.DeletedBefore
+			e.DeletedBefore = &deletedBefore
+		}
+
+		if entry.Revision != nil {
+			e.Revision = FromRevisionToDTO(entry.Revision)
+		}
+
+		entries = append(entries, e)
+	}
+
+	return &ReplicationLogResp{
+		LeaderID: batch.LeaderID,
+		Head:     batch.Head,
+		Entries:  entries,
+	}
+}
+
+func FromReplicationLogRespToBatch(resp *ReplicationLogResp) *model.LogBatch {
+	entries := make([]*model.LogEntry, 0, len(resp.Entries))
+
+	for _, e := range resp.Entries {
+		entry := &model.LogEntry{
+			Seq:       e.Seq,
+			Op:        model.LogOp(e.Op),
+			CreatedAt: e.CreatedAt,
+			NextID:    e.NextID,
+		}
+
+		for _, task := range e.Tasks {
+			entry.Tasks = append(entry.Tasks, FromTaskDTOToTask(task))
+		}
+
+		if e.DeletedBefore != nil {
+			entry.DeletedBefore = *e.DeletedBefore
+		}
+
+		if e.Revision != nil && e.Revision.Task != nil {
+			entry.Revision = &model.Revision{
+				TaskID:    e.Revision.Task.ID,
+				Rev:       e.Revision.Rev,
+				Action:    model.RevisionAction(e.Revision.Action),
+				Author:    e.Revision.Author,
+				CreatedAt: e.Revision.CreatedAt,
+				Task:      *FromTaskDTOToTask(e.Revision.Task),
+			}
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return &model.LogBatch{
+		LeaderID: resp.LeaderID,
+		Head:     resp.Head,
+		Entries:  entries,
+	}
+}
+
+func FromReplicationSnapshotToResp(snapshot *model.ReplicationSnapshot) *ReplicationSnapshotResp {
+	tasks := make([]*TaskDTO, 0, len(snapshot.Tasks))
+
+	for _, task := range snapshot.Tasks {
+		tasks = append(tasks, FromTaskToDTO(task))
+	}
+
+	return &ReplicationSnapshotResp{
+		LeaderID: snapshot.LeaderID,
+		Seq:      snapshot.Seq,
+		NextID:   snapshot.NextID,
+		Tasks:    tasks,
+	}
+}
+
+func FromReplicationSnapshotRespToSnapshot(resp *ReplicationSnapshotResp) *model.ReplicationSnapshot {
+	tasks := make([]*model.Task, 0, len(resp.Tasks))
+
+	for _, task := range resp.Tasks {
+		tasks = append(tasks, FromTaskDTOToTask(task))
+	}
+
+	return &model.ReplicationSnapshot{
+		LeaderID: resp.LeaderID,
+		Seq:      resp.Seq,
+		NextID:   resp.NextID,
+		Tasks:    tasks,
+	}
+}
+
+func FromReplicationStatusToResp(status *model.ReplicationStatus) *ReplicationStatusResp {
+	resp := &ReplicationStatusResp{
+		Role:       string(status.Role),
+		NodeID:     status.NodeID,
+		LeaderURL:  status.LeaderURL,
+		LeaderID:   status.LeaderID,
+		Applied:    status.Applied,
+		Head:       status.Head,
+		LagEntries: status.Head - status.Applied,
+		LagSeconds: status.Lag.Seconds(),
+		Error:      status.LastError,
+	}
+
+	if !status.LastContactAt.IsZero() {
+		lastContactAt := status.LastContactAt
+		resp.LastContactAt = &lastContactAt
+	}
+
+	for _, f := range status.Followers {
+		resp.Followers = append(resp.Followers, &FollowerStatusDTO{
+			ID:         f.ID,
+			Applied:    f.Applied,
+			LagEntries: status.Head - f.Applied,
+			LastSeenAt: f.LastSeenAt,
+		})
+	}
+
+	return resp
+}
+
+func FromChangeBatchToResp(batch *model.ChangeBatch) *GetChangesResp {
+	events := make([]*ChangeEventDTO, 0, len(batch.Events))
+
+	for _, event := range batch.Events {
+		change := &ChangeEventDTO{
+			Seq:       event.Seq,
+			Type:      string(event.Type),
+			TaskID:    event.TaskID,
+			Author:    event.Author,
+			CreatedAt: event.CreatedAt,
+		}
+
+		// событие reset не относится к задаче
+		if event.Type != model.ChangeReset {
+			change.Task = FromTaskToDTO(&event.Task)
+		}
+
+		events = append(events, change)
+	}
+
+	return &GetChangesResp{
+		First:  batch.First,
+		Head:   batch.Head,
+		Events: events,
+	}
+}
+
+func FromCreateProjectReqToProject(req CreateProjectReq) *model.Project {
+	return &model.Project{
+		Name:        req.Name,
+		Description: req.Description,
+	}
+}
+
+func FromUpdateProjectReqToProject(req UpdateProjectReq) *model.Project {
+	return &model.Project{
+		Name:        req.Name,
+		Description: req.Description,
+	}
+}
+
+func FromProjectToDTO(project *model.Project) *ProjectDTO {
+	byStatus := make(map[string]int, len(project.Counts.ByStatus))
+	for status, count := range project.Counts.ByStatus {
+		byStatus[string(status)] = count
+	}
+
+	return &ProjectDTO{
+		ID:          project.ID,
+		Name:        project.Name,
+		Description: project.Description,
+		Archived:    project.Archived(),
+		ArchivedAt:  project.ArchivedAt,
+		Counts: &ProjectCountsDTO{
+			Total:    project.Counts.Total,
+			ByStatus: byStatus,
+		},
+		Version: project.Version,
+	}
+}
+
+func FromProjectsListToResp(projects []*model.Project) *GetProjectsResp {
+	list := make([]*ProjectDTO, 0, len(projects))
+
+	for _, project := range projects {
+		list = append(list, FromProjectToDTO(project))
+	}
+
+	return &GetProjectsResp{
+		Projects: list,
+	}
+}
+
+func FromCreateCommentReqToComment(req CreateCommentReq) *model.Comment {
+	return &model.Comment{
+		Text:     req.Text,
+		ParentID: req.ParentID,
+	}
+}
+
+func FromCommentToDTO(comment *model.Comment) *CommentDTO {
+	return &CommentDTO{
+		ID:        comment.ID,
+		ParentID:  comment.ParentID,
+		Author:    comment.Author,
+		Text:      comment.Text,
+		CreatedAt: comment.CreatedAt,
+		EditedAt:  comment.EditedAt,
+	}
+}
+
+func FromCommentPageToResp(page *model.CommentPage) *GetCommentsResp {
+	list := make([]*CommentThreadDTO, 0, len(page.Threads))
+
+	for _, thread := range page.Threads {
+		replies := make([]*CommentDTO, 0, len(thread.Replies))
+		for _, reply := range thread.Replies {
+			replies = append(replies, FromCommentToDTO(reply))
+		}
+
+		list = append(list, &CommentThreadDTO{
+			CommentDTO: FromCommentToDTO(thread.Comment),
+			Replies:    replies,
+		})
+	}
+
+	return &GetCommentsResp{
+		Comments: list,
+		Next:     page.Next,
+	}
+}
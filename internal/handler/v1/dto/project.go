@@ -0,0 +1,42 @@
+package dto
+
+import "time"
+
+type CreateProjectReq struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+type CreateProjectResp struct {
+	ID int `json:"id"`
+}
+
+type UpdateProjectReq struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Archived    bool   `json:"archived"`
+}
+
+type ProjectDTO struct {
+	ID          int               `json:"id"`
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	Archived    bool              `json:"archived"`
+	ArchivedAt  *time.Time        `json:"archived_at,omitempty"`
+	Counts      *ProjectCountsDTO `json:"counts"`
+	Version     int               `json:"version"`
+}
+
+// ProjectCountsDTO количество задач проекта не из корзины, всего и по статусам
+type ProjectCountsDTO struct {
+	Total    int            `json:"total"`
+	ByStatus map[string]int `json:"by_status"`
+}
+
+type GetProjectsResp struct {
+	Projects []*ProjectDTO `json:"projects"`
+}
+
+type MoveTaskReq struct {
+	ProjectID int `json:"project_id"`
+}
@@ -1,9 +1,19 @@
 package dto
 
+import "time"
+
 type CreateTaskReq struct {
-	Title       string `json:"title"`
-	Description string `json:"description"`
-	Done        bool   `json:"done"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	Done        bool       `json:"done"`
+	Status      string     `json:"status,omitempty"`
+	DueAt       *time.Time `json:"due_at,omitempty"`
+	Priority    string     `json:"priority,omitempty"`
+	Tags        []string   `json:"tags,omitempty"`
+	ParentID    int        `json:"parent_id,omitempty"`
+	DependsOn   []int      `json:"depends_on,omitempty"`
+	ProjectID   int        `json:"project_id,omitempty"`
+	Recurrence  string     `json:"recurrence,omitempty"`
 }
 
 type CreateTaskResp struct {
@@ -11,25 +21,217 @@ type CreateTaskResp struct {
 }
 
 type GetTaskByIDResp struct {
-	ID          int    `json:"id"`
-	Title       string `json:"title"`
-	Description string `json:"description"`
-	Done        bool   `json:"done"`
+	ID          int        `json:"id"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	Done        bool       `json:"done"`
+	Status      string     `json:"status"`
+	DueAt       *time.Time `json:"due_at,omitempty"`
+	Priority    string     `json:"priority"`
+	Tags        []string   `json:"tags,omitempty"`
+	ParentID    int        `json:"parent_id,omitempty"`
+	DependsOn   []int      `json:"depends_on,omitempty"`
+	ProjectID   int        `json:"project_id,omitempty"`
+	Recurrence  string     `json:"recurrence,omitempty"`
+	Blocked     bool       `json:"blocked,omitempty"`
+	CreatedAt   *time.Time `json:"created_at,omitempty"`
+	Version     int        `json:"version"`
 }
 
 type UpdateTaskReq struct {
-	Title       string `json:"title"`
-	Description string `json:"description"`
-	Done        bool   `json:"done"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	Done        bool       `json:"done"`
+	Status      string     `json:"status,omitempty"`
+	DueAt       *time.Time `json:"due_at,omitempty"`
+	Priority    string     `json:"priority,omitempty"`
+	Tags        []string   `json:"tags,omitempty"`
+	ParentID    int        `json:"parent_id,omitempty"`
+	DependsOn   []int      `json:"depends_on,omitempty"`
+	ProjectID   int        `json:"project_id,omitempty"`
+	Recurrence  string     `json:"recurrence,omitempty"`
 }
 
 type TaskDTO struct {
-	ID          int    `json:"id"`
-	Title       string `json:"title"`
-	Description string `json:"description"`
-	Done        bool   `json:"done"`
+	ID          int        `json:"id"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	Done        bool       `json:"done"`
+	Status      string     `json:"status"`
+	DueAt       *time.Time `json:"due_at,omitempty"`
+	Priority    string     `json:"priority"`
+	Tags        []string   `json:"tags,omitempty"`
+	ParentID    int        `json:"parent_id,omitempty"`
+	DependsOn   []int      `json:"depends_on,omitempty"`
+	ProjectID   int        `json:"project_id,omitempty"`
+	Recurrence  string     `json:"recurrence,omitempty"`
+	Blocked     bool       `json:"blocked,omitempty"`
+	CreatedAt   *time.Time `json:"created_at,omitempty"`
+	Version     int        `json:"version"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty"`
+}
+
+// TaskNodeDTO задача с подзадачами. HasChildren true и пустой Children означают,
+// что подзадачи есть, но лежат глубже запрошенной глубины
+type TaskNodeDTO struct {
+	*TaskDTO
+	HasChildren bool           `json:"has_children"`
+	Children    []*TaskNodeDTO `json:"children"`
+}
+
+type TagCountDTO struct {
+	Tag   string `json:"tag"`
+	Count int    `json:"count"`
+}
+
+type GetTagsResp struct {
+	Tags []*TagCountDTO `json:"tags"`
+}
+
+type TransitionTaskReq struct {
+	To string `json:"to"`
+}
+
+type AddDependencyReq struct {
+	DependsOn int `json:"depends_on"`
+}
+
+type GetOccurrencesResp struct {
+	Occurrences []time.Time `json:"occurrences"`
+}
+
+type RenameTagReq struct {
+	To string `json:"to"`
+}
+
+type RenameTagResp struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	// Renamed количество задач, у которых заменилась метка
+	Renamed int `json:"renamed"`
 }
 
 type GetAllTasksResp struct {
 	Tasks []*TaskDTO `json:"todos"`
 }
+
+type RevisionDTO struct {
+	Rev       int       `json:"rev"`
+	Action    string    `json:"action"`
+	Author    string    `json:"author,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	Task      *TaskDTO  `json:"task"`
+}
+
+type GetTaskHistoryResp struct {
+	Revisions []*RevisionDTO `json:"revisions"`
+}
+
+type FieldChangeDTO struct {
+	Field string `json:"field"`
+	From  any    `json:"from"`
+	To    any    `json:"to"`
+}
+
+type DiffTaskRevisionsResp struct {
+	From    int               `json:"from"`
+	To      int               `json:"to"`
+	Changes []*FieldChangeDTO `json:"changes"`
+}
+
+// ImportTaskReq задача из записи импорта. ID - id задачи из выгрузки, на него
+// ссылаются parent_id и depends_on других записей. Время создания из выгрузки сохраняется
+type ImportTaskReq struct {
+	ID int `json:"id"`
+	CreateTaskReq
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+}
+
+type ImportTasksResp struct {
+	DryRun bool `json:"dry_run"`
+	Total  int  `json:"total"`
+	// Imported количество созданных задач, при dry_run - количество задач, прошедших проверку
+	Imported int             `json:"imported"`
+	Failed   int             `json:"failed"`
+	Rows     []*ImportRowDTO `json:"rows"`
+}
+
+// ImportRowDTO результат импорта одной записи, Row начинается с 1
+type ImportRowDTO struct {
+	Row   int    `json:"row"`
+	ID    int    `json:"id,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+type RestoreBackupReq struct {
+	Backup string `json:"backup"`
+}
+
+type RestoreBackupResp struct {
+	Backup    string    `json:"backup"`
+	CreatedAt time.Time `json:"created_at"`
+	Tasks     int       `json:"tasks"`
+	NextID    int       `json:"next_id"`
+	Checksum  string    `json:"checksum"`
+	Projects  int       `json:"projects"`
+}
+
+type LogEntryDTO struct {
+	Seq           int          `json:"seq"`
+	Op            string       `json:"op"`
+	CreatedAt     time.Time    `json:"created_at"`
+	Tasks         []*TaskDTO   `json:"tasks,omitempty"`
+	NextID        int          `json:"next_id,omitempty"`
+	DeletedBefore *time.Time   `json:"deleted_before,omitempty"`
+	Revision      *RevisionDTO `json:"revision,omitempty"`
+}
+
+type ReplicationLogResp struct {
+	LeaderID string         `json:"leader_id"`
+	Head     int            `json:"head"`
+	Entries  []*LogEntryDTO `json:"entries"`
+}
+
+type ReplicationSnapshotResp struct {
+	LeaderID string     `json:"leader_id"`
+	Seq      int        `json:"seq"`
+	NextID   int        `json:"next_id"`
+	Tasks    []*TaskDTO `json:"tasks"`
+}
+
+type ReplicationStatusResp struct {
+	Role          string     `json:"role"`
+	NodeID        string     `json:"node_id"`
+	LeaderURL     string     `json:"leader_url,omitempty"`
+	LeaderID      string     `json:"leader_id,omitempty"`
+	Applied       int        `json:"applied"`
+	Head          int        `json:"head"`
+	LagEntries    int        `json:"lag_entries"`
+	LagSeconds    float64    `json:"lag_seconds"`
+	LastContactAt *time.Time `json:"last_contact_at,omitempty"`
+	Error         string     `json:"error,omitempty"`
+	// Followers заполнено только у ведущего узла
+	Followers []*FollowerStatusDTO `json:"followers,omitempty"`
+}
+
+type FollowerStatusDTO struct {
+	ID         string    `json:"id"`
+	Applied    int       `json:"applied"`
+	LagEntries int       `json:"lag_entries"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+}
+
+type ChangeEventDTO struct {
+	Seq       int       `json:"seq"`
+	Type      string    `json:"type"`
+	TaskID    int       `json:"task_id"`
+	Author    string    `json:"author,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	Task      *TaskDTO  `json:"task,omitempty"`
+}
+
+type GetChangesResp struct {
+	First  int               `json:"first"`
+	Head   int               `json:"head"`
+	Events []*ChangeEventDTO `json:"events"`
+}
@@ -0,0 +1,100 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	headerETag    = "ETag"
+	headerIfMatch = "If-Match"
+)
+
+var ErrInvalidIfMatch = errors.New("invalid If-Match header")
+
+// etag возвращает сильный ETag для версии задачи
+func etag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// versionFromIfMatch возвращает ожидаемую версию задачи или проекта из заголовка If-Match.
+// Отсутствующий заголовок и "*" означают любую версию (0): без заголовка версия
+// не проверяется. Заголовок может содержать список ETag через запятую, тогда
+// подходит любой из них: current читает текущую версию, и если она есть в списке,
+// возвращается она. Иначе возвращается первая версия из списка, и несовпадение
+// версий обнаружит юзкейс
+func versionFromIfMatch(r *http.Request, current func() (int, error)) (int, error) {
+	value := strings.TrimSpace(r.Header.Get(headerIfMatch))
+	if value == "" || value == "*" {
+		return 0, nil
+	}
+
+	var versions []int
+	for _, tag := range strings.Split(value, ",") {
+		version, err := parseETag(strings.TrimSpace(tag))
+		if err != nil {
+			return 0, err
+		}
+
+		versions = append(versions, version)
+	}
+
+	if len(versions) == 1 {
+		return versions[0], nil
+	}
+
+	// при ошибке чтения версию проверит юзкейс, он же вернет ошибку
+	if version, err := current(); err == nil && slices.Contains(versions, version) {
+		return version, nil
+	}
+
+	return versions[0], nil
+}
+
+// parseETag возвращает версию из сильного ETag
+func parseETag(tag string) (int, error) {
+	unquoted, ok := strings.CutPrefix(tag, `"`)
+	if !ok {
+		return 0, ErrInvalidIfMatch
+	}
+
+	unquoted, ok = strings.CutSuffix(unquoted, `"`)
+	if !ok {
+		return 0, ErrInvalidIfMatch
+	}
+
+	version, err := strconv.Atoi(unquoted)
+	if err != nil || version <= 0 {
+		return 0, ErrInvalidIfMatch
+	}
+
+	return version, nil
+}
+
+// taskVersion возвращает функцию чтения текущей версии задачи id
+func (h *handler) taskVersion(ctx context.Context, id int) func() (int, error) {
+	return func() (int, error) {
+		task, err := h.taskUsecase.GetTaskByID(ctx, id)
+		if err != nil {
+			return 0, err
+		}
+
+		return task.Version, nil
+	}
+}
+
+// projectVersion возвращает функцию чтения текущей версии проекта id
+func (h *handler) projectVersion(ctx context.Context, id int) func() (int, error) {
+	return func() (int, error) {
+		project, err := h.projectUsecase.GetProject(ctx, id)
+		if err != nil {
+			return 0, err
+		}
+
+		return project.Version, nil
+	}
+}
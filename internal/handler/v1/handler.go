@@ -15,24 +15,93 @@ const (
 )
 
 var (
-	ErrFailedToDecodeReq   = errors.New("failed to decode request")
-	ErrFailedToCreateTask  = errors.New("failed to create task")
-	ErrFailedToGetTaskByID = errors.New("failed to get task by id")
-	ErrFailedToUpdateTask  = errors.New("failed to update task")
-	ErrFailedToDeleteTask  = errors.New("failed to delete task")
-	ErrFailedToGetAllTasks = errors.New("failed to get all tasks")
-	ErrInvalidTaskIDType   = errors.New("invalid task id type")
+	ErrFailedToDecodeReq     = errors.New("failed to decode request")
+	ErrFailedToCreateTask    = errors.New("failed to create task")
+	ErrFailedToGetTaskByID   = errors.New("failed to get task by id")
+	ErrFailedToUpdateTask    = errors.New("failed to update task")
+	ErrFailedToDeleteTask    = errors.New("failed to delete task")
+	ErrFailedToGetAllTasks   = errors.New("failed to get all tasks")
+	ErrFailedToGetTrash      = errors.New("failed to get deleted tasks")
+	ErrFailedToRestoreTask   = errors.New("failed to restore task")
+	ErrFailedToGetHistory    = errors.New("failed to get task history")
+	ErrFailedToGetRevision   = errors.New("failed to get task revision")
+	ErrFailedToDiffTask      = errors.New("failed to diff task revisions")
+	ErrFailedToRevertTask    = errors.New("failed to revert task")
+	ErrInvalidTaskIDType     = errors.New("invalid task id type")
+	ErrInvalidRevision       = errors.New("invalid revision")
+	ErrFailedToRestoreBackup = errors.New("failed to restore backup")
+	ErrFailedToReadLog       = errors.New("failed to read replication log")
+	ErrFailedToGetSnapshot   = errors.New("failed to get replication snapshot")
+	ErrInvalidLogPosition    = errors.New("invalid replication log position")
+	ErrInvalidWait           = errors.New("invalid wait duration")
+	ErrFailedToReadChanges   = errors.New("failed to read change stream")
+	ErrInvalidChangePosition = errors.New("invalid change stream position")
+	ErrInvalidLimit          = errors.New("invalid limit")
+	ErrInvalidDueDate        = errors.New("invalid due date, expected RFC 3339")
+	ErrInvalidOverdue        = errors.New("invalid overdue value")
+	ErrInvalidSort           = errors.New("invalid sort field, expected id, created, priority, due or title")
+	ErrInvalidOrder          = errors.New("invalid sort order, expected asc or desc")
+	ErrInvalidMatch          = errors.New("invalid tag match, expected any or all")
+	ErrFailedToGetTags       = errors.New("failed to get tags")
+	ErrFailedToRenameTag     = errors.New("failed to rename tag")
+	ErrFailedToGetChildren   = errors.New("failed to get task children")
+	ErrFailedToGetTree       = errors.New("failed to get task tree")
+	ErrInvalidDepth          = errors.New("invalid tree depth")
+
+	ErrFailedToAddDependency    = errors.New("failed to add task dependency")
+	ErrFailedToRemoveDependency = errors.New("failed to remove task dependency")
+	ErrFailedToGetOrder         = errors.New("failed to get tasks in order")
+	ErrInvalidDependencyID      = errors.New("invalid dependency id type")
+
+	ErrFailedToGetOccurrences = errors.New("failed to get task occurrences")
+	ErrInvalidOccurrences     = errors.New("invalid occurrences count")
+
+	ErrFailedToTransitionTask = errors.New("failed to change task status")
+
+	ErrFailedToCreateProject   = errors.New("failed to create project")
+	ErrFailedToGetProjects     = errors.New("failed to get projects")
+	ErrFailedToGetProject      = errors.New("failed to get project")
+	ErrFailedToUpdateProject   = errors.New("failed to update project")
+	ErrFailedToDeleteProject   = errors.New("failed to delete project")
+	ErrFailedToGetProjectTasks = errors.New("failed to get project tasks")
+	ErrFailedToMoveTask        = errors.New("failed to move task")
+	ErrInvalidProjectIDType    = errors.New("invalid project id type")
+
+	ErrFailedToCreateComment = errors.New("failed to create comment")
+	ErrFailedToGetComments   = errors.New("failed to get comments")
+	ErrFailedToUpdateComment = errors.New("failed to update comment")
+	ErrFailedToDeleteComment = errors.New("failed to delete comment")
+	ErrInvalidCommentIDType  = errors.New("invalid comment id type")
+	ErrInvalidAfter          = errors.New("invalid after value")
 )
 
 type handler struct {
-	taskUsecase TaskUsecase
-	log         *slog.Logger
+	taskUsecase        TaskUsecase
+	projectUsecase     ProjectUsecase
+	commentUsecase     CommentUsecase
+	backupUsecase      BackupUsecase
+	replicationUsecase ReplicationUsecase
+	changeUsecase      ChangeUsecase
+	log                *slog.Logger
 }
 
-func NewHandler(taskUsecase TaskUsecase, log *slog.Logger) *handler {
+func NewHandler(
+	taskUsecase TaskUsecase,
+	projectUsecase ProjectUsecase,
+	commentUsecase CommentUsecase,
+	backupUsecase BackupUsecase,
+	replicationUsecase ReplicationUsecase,
+	changeUsecase ChangeUsecase,
+	log *slog.Logger,
+) *handler {
 	return &handler{
-		taskUsecase: taskUsecase,
-		log:         log,
+		taskUsecase:        taskUsecase,
+		projectUsecase:     projectUsecase,
+		commentUsecase:     commentUsecase,
+		backupUsecase:      backupUsecase,
+		replicationUsecase: replicationUsecase,
+		changeUsecase:      changeUsecase,
+		log:                log,
 	}
 }
 
@@ -0,0 +1,131 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/solumD/tasks-service/internal/handler/v1/dto"
+	"github.com/solumD/tasks-service/internal/usecase"
+	"github.com/solumD/tasks-service/pkg/logger"
+)
+
+// defaultTreeDepth глубина дерева задач, если depth не указан
+const defaultTreeDepth = 1
+
+// GetChildren обрабатывает запрос на получение подзадач задачи
+func (h *handler) GetChildren(ctx context.Context) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const fn = "handler.GetChildren"
+		log := h.log.With(logger.String("fn", fn))
+
+		log.Info("new request")
+
+		taskID, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			log.Error("failed to get task id from path", logger.Error(err))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusBadRequest, ErrInvalidTaskIDType)
+			return
+		}
+
+		log.Info("got task id from path", logger.Int("task id", taskID))
+
+		children, err := h.taskUsecase.GetChildren(ctx, taskID)
+		if err != nil {
+			if errors.Is(err, usecase.ErrTaskNotFound) {
+				log.Error("failed to get task children", logger.Error(err))
+
+				h.errorResponse(w, contentTypeJSON, http.StatusNotFound, err)
+				return
+			}
+
+			log.Error("failed to get task children", logger.Error(err))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusInternalServerError, ErrFailedToGetChildren)
+			return
+		}
+
+		resp := dto.FromTasksListToResp(children)
+		respBody, err := json.Marshal(resp)
+		if err != nil {
+			log.Error("failed to marshal response", logger.Error(err))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusInternalServerError, ErrFailedToGetChildren)
+			return
+		}
+
+		log.Info("got task children", logger.Int("task id", taskID), logger.Int("tasks count", len(children)))
+
+		h.response(w, contentTypeJSON, http.StatusOK, respBody)
+	}
+}
+
+// GetTaskTree обрабатывает запрос на получение задачи с деревом подзадач.
+// Параметр depth задает глубину дерева, 0 - только сама задача
+func (h *handler) GetTaskTree(ctx context.Context) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const fn = "handler.GetTaskTree"
+		log := h.log.With(logger.String("fn", fn))
+
+		log.Info("new request")
+
+		taskID, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			log.Error("failed to get task id from path", logger.Error(err))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusBadRequest, ErrInvalidTaskIDType)
+			return
+		}
+
+		depth := defaultTreeDepth
+		if value := r.URL.Query().Get("depth"); value != "" {
+			depth, err = strconv.Atoi(value)
+			if err != nil {
+				log.Error("failed to get depth from query", logger.String("depth", value))
+
+				h.errorResponse(w, contentTypeJSON, http.StatusBadRequest, ErrInvalidDepth)
+				return
+			}
+		}
+
+		log.Info("got task id from path", logger.Int("task id", taskID), logger.Int("depth", depth))
+
+		tree, err := h.taskUsecase.GetTaskTree(ctx, taskID, depth)
+		if err != nil {
+			if errors.Is(err, usecase.ErrInvalidDepth) {
+				log.Error("failed to get task tree", logger.Error(err))
+
+				h.errorResponse(w, contentTypeJSON, http.StatusBadRequest, err)
+				return
+			}
+
+			if errors.Is(err, usecase.ErrTaskNotFound) {
+				log.Error("failed to get task tree", logger.Error(err))
+
+				h.errorResponse(w, contentTypeJSON, http.StatusNotFound, err)
+				return
+			}
+
+			log.Error("failed to get task tree", logger.Error(err))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusInternalServerError, ErrFailedToGetTree)
+			return
+		}
+
+		resp := dto.FromTaskNodeToDTO(tree)
+		respBody, err := json.Marshal(resp)
+		if err != nil {
+			log.Error("failed to marshal response", logger.Error(err))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusInternalServerError, ErrFailedToGetTree)
+			return
+		}
+
+		log.Info("got task tree", logger.Int("task id", taskID))
+
+		h.response(w, contentTypeJSON, http.StatusOK, respBody)
+	}
+}
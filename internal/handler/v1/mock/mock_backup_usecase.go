@@ -0,0 +1,25 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/solumD/tasks-service/internal/model"
+)
+
+// MockBackupUsecase мок восстановления из резервных копий
+type MockBackupUsecase struct {
+	RestoreBackupFunc   func(ctx context.Context, name string) (*model.BackupInfo, error)
+	RestoreBackupCalled bool
+	RestoreBackupName   string
+}
+
+func (m *MockBackupUsecase) RestoreBackup(ctx context.Context, name string) (*model.BackupInfo, error) {
+	m.RestoreBackupCalled = true
+	m.RestoreBackupName = name
+
+	if m.RestoreBackupFunc != nil {
+		return m.RestoreBackupFunc(ctx, name)
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,30 @@
+package mock
+
+import (
+	"context"
+	"time"
+
+	"github.com/solumD/tasks-service/internal/model"
+)
+
+// MockChangeUsecase мок потока изменений задач
+type MockChangeUsecase struct {
+	ReadFunc   func(ctx context.Context, from int, limit int, wait time.Duration) (*model.ChangeBatch, error)
+	ReadCalled bool
+	ReadFrom   int
+	ReadLimit  int
+	ReadWait   time.Duration
+}
+
+func (m *MockChangeUsecase) Read(ctx context.Context, from int, limit int, wait time.Duration) (*model.ChangeBatch, error) {
+	m.ReadCalled = true
+	m.ReadFrom = from
+	m.ReadLimit = limit
+	m.ReadWait = wait
+
+	if m.ReadFunc != nil {
+		return m.ReadFunc(ctx, from, limit, wait)
+	}
+
+	return &model.ChangeBatch{}, nil
+}
@@ -0,0 +1,76 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/solumD/tasks-service/internal/model"
+)
+
+// MockCommentUsecase мок юзкейса Comment
+type MockCommentUsecase struct {
+	AddCommentFunc    func(ctx context.Context, comment *model.Comment) (int, error)
+	AddCommentCalled  bool
+	AddCommentComment *model.Comment
+
+	GetCommentsFunc   func(ctx context.Context, taskID int, after int, limit int) (*model.CommentPage, error)
+	GetCommentsCalled bool
+	GetCommentsTaskID int
+	GetCommentsAfter  int
+	GetCommentsLimit  int
+
+	UpdateCommentFunc    func(ctx context.Context, comment *model.Comment) (*model.Comment, error)
+	UpdateCommentCalled  bool
+	UpdateCommentComment *model.Comment
+
+	DeleteCommentFunc   func(ctx context.Context, taskID int, id int) error
+	DeleteCommentCalled bool
+	DeleteCommentTaskID int
+	DeleteCommentID     int
+}
+
+func (m *MockCommentUsecase) AddComment(ctx context.Context, comment *model.Comment) (int, error) {
+	m.AddCommentCalled = true
+	m.AddCommentComment = comment
+
+	if m.AddCommentFunc != nil {
+		return m.AddCommentFunc(ctx, comment)
+	}
+
+	return 0, nil
+}
+
+func (m *MockCommentUsecase) GetComments(ctx context.Context, taskID int, after int, limit int) (*model.CommentPage, error) {
+	m.GetCommentsCalled = true
+	m.GetCommentsTaskID = taskID
+	m.GetCommentsAfter = after
+	m.GetCommentsLimit = limit
+
+	if m.GetCommentsFunc != nil {
+		return m.GetCommentsFunc(ctx, taskID, after, limit)
+	}
+
+	return nil, nil
+}
+
+func (m *MockCommentUsecase) UpdateComment(ctx context.Context, comment *model.Comment) (*model.Comment, error) {
+	m.UpdateCommentCalled = true
+	m.UpdateCommentComment = comment
+
+	if m.UpdateCommentFunc != nil {
+		return m.UpdateCommentFunc(ctx, comment)
+	}
+
+	return nil, nil
+}
+
+func (m *MockCommentUsecase) DeleteComment(ctx context.Context, taskID int, id int) error {
+	m.DeleteCommentCalled = true
+	m.DeleteCommentTaskID = taskID
+	m.DeleteCommentID = id
+
+	if m.DeleteCommentFunc != nil {
+		return m.DeleteCommentFunc(ctx, taskID, id)
+	}
+
+	return nil
+}
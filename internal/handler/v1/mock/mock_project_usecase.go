@@ -0,0 +1,140 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/solumD/tasks-service/internal/model"
+)
+
+// MockProjectUsecase мок юзкейса Project
+type MockProjectUsecase struct {
+	CreateProjectFunc    func(ctx context.Context, project *model.Project) (int, error)
+	CreateProjectCalled  bool
+	CreateProjectProject *model.Project
+
+	GetProjectsFunc   func(ctx context.Context) ([]*model.Project, error)
+	GetProjectsCalled bool
+
+	GetProjectFunc   func(ctx context.Context, id int) (*model.Project, error)
+	GetProjectCalled bool
+	GetProjectID     int
+
+	UpdateProjectFunc     func(ctx context.Context, project *model.Project, archived bool) error
+	UpdateProjectCalled   bool
+	UpdateProjectProject  *model.Project
+	UpdateProjectArchived bool
+
+	DeleteProjectFunc    func(ctx context.Context, id int, version int) error
+	DeleteProjectCalled  bool
+	DeleteProjectID      int
+	DeleteProjectVersion int
+
+	GetProjectTasksFunc   func(ctx context.Context, id int, filter model.TaskFilter) ([]*model.Task, error)
+	GetProjectTasksCalled bool
+	GetProjectTasksID     int
+	GetProjectTasksFilter model.TaskFilter
+
+	CreateProjectTaskFunc   func(ctx context.Context, id int, task *model.Task) (int, error)
+	CreateProjectTaskCalled bool
+	CreateProjectTaskID     int
+	CreateProjectTaskTask   *model.Task
+
+	MoveTaskFunc      func(ctx context.Context, id int, projectID int, version int) (*model.Task, error)
+	MoveTaskCalled    bool
+	MoveTaskID        int
+	MoveTaskProjectID int
+	MoveTaskVersion   int
+}
+
+func (m *MockProjectUsecase) CreateProject(ctx context.Context, project *model.Project) (int, error) {
+	m.CreateProjectCalled = true
+	m.CreateProjectProject = project
+
+	if m.CreateProjectFunc != nil {
+		return m.CreateProjectFunc(ctx, project)
+	}
+
+	return 0, nil
+}
+
+func (m *MockProjectUsecase) GetProjects(ctx context.Context) ([]*model.Project, error) {
+	m.GetProjectsCalled = true
+
+	if m.GetProjectsFunc != nil {
+		return m.GetProjectsFunc(ctx)
+	}
+
+	return nil, nil
+}
+
+func (m *MockProjectUsecase) GetProject(ctx context.Context, id int) (*model.Project, error) {
+	m.GetProjectCalled = true
+	m.GetProjectID = id
+
+	if m.GetProjectFunc != nil {
+		return m.GetProjectFunc(ctx, id)
+	}
+
+	return nil, nil
+}
+
+func (m *MockProjectUsecase) UpdateProject(ctx context.Context, project *model.Project, archived bool) error {
+	m.UpdateProjectCalled = true
+	m.UpdateProjectProject = project
+	m.UpdateProjectArchived = archived
+
+	if m.UpdateProjectFunc != nil {
+		return m.UpdateProjectFunc(ctx, project, archived)
+	}
+
+	return nil
+}
+
+func (m *MockProjectUsecase) DeleteProject(ctx context.Context, id int, version int) error {
+	m.DeleteProjectCalled = true
+	m.DeleteProjectID = id
+	m.DeleteProjectVersion = version
+
+	if m.DeleteProjectFunc != nil {
+		return m.DeleteProjectFunc(ctx, id, version)
+	}
+
+	return nil
+}
+
+func (m *MockProjectUsecase) GetProjectTasks(ctx context.Context, id int, filter model.TaskFilter) ([]*model.Task, error) {
+	m.GetProjectTasksCalled = true
+	m.GetProjectTasksID = id
+	m.GetProjectTasksFilter = filter
+
+	if m.GetProjectTasksFunc != nil {
+		return m.GetProjectTasksFunc(ctx, id, filter)
+	}
+
+	return nil, nil
+}
+
+func (m *MockProjectUsecase) CreateProjectTask(ctx context.Context, id int, task *model.Task) (int, error) {
+	m.CreateProjectTaskCalled = true
+	m.CreateProjectTaskID = id
+	m.CreateProjectTaskTask = task
+
+	if m.CreateProjectTaskFunc != nil {
+		return m.CreateProjectTaskFunc(ctx, id, task)
+	}
+
+	return 0, nil
+}
+
+func (m *MockProjectUsecase) MoveTask(ctx context.Context, id int, projectID int, version int) (*model.Task, error) {
+	m.MoveTaskCalled = true
+	m.MoveTaskID = id
+	m.MoveTaskProjectID = projectID
+	m.MoveTaskVersion = version
+
+	if m.MoveTaskFunc != nil {
+		return m.MoveTaskFunc(ctx, id, projectID, version)
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,56 @@
+package mock
+
+import (
+	"context"
+	"time"
+
+	"github.com/solumD/tasks-service/internal/model"
+)
+
+// MockReplicationUsecase мок репликации
+type MockReplicationUsecase struct {
+	ReadLogFunc       func(ctx context.Context, followerID string, from int, wait time.Duration) (*model.LogBatch, error)
+	ReadLogCalled     bool
+	ReadLogFollowerID string
+	ReadLogFrom       int
+	ReadLogWait       time.Duration
+
+	SnapshotFunc   func(ctx context.Context) (*model.ReplicationSnapshot, error)
+	SnapshotCalled bool
+
+	StatusFunc   func() *model.ReplicationStatus
+	StatusCalled bool
+}
+
+func (m *MockReplicationUsecase) ReadLog(ctx context.Context, followerID string, from int, wait time.Duration) (*model.LogBatch, error) {
+	m.ReadLogCalled = true
+	m.ReadLogFollowerID = followerID
+	m.ReadLogFrom = from
+	m.ReadLogWait = wait
+
+	if m.ReadLogFunc != nil {
+		return m.ReadLogFunc(ctx, followerID, from, wait)
+	}
+
+	return &model.LogBatch{}, nil
+}
+
+func (m *MockReplicationUsecase) Snapshot(ctx context.Context) (*model.ReplicationSnapshot, error) {
+	m.SnapshotCalled = true
+
+	if m.SnapshotFunc != nil {
+		return m.SnapshotFunc(ctx)
+	}
+
+	return &model.ReplicationSnapshot{}, nil
+}
+
+func (m *MockReplicationUsecase) Status() *model.ReplicationStatus {
+	m.StatusCalled = true
+
+	if m.StatusFunc != nil {
+		return m.StatusFunc()
+	}
+
+	return &model.ReplicationStatus{}
+}
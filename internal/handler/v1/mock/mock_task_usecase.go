@@ -2,6 +2,7 @@ package mock
 
 import (
 	"context"
+	"time"
 
 	"github.com/solumD/tasks-service/internal/model"
 )
@@ -12,8 +13,9 @@ type MockTaskUsecase struct {
 	CreateTaskCalled bool
 	CreateTaskTask   *model.Task
 
-	GetAllTasksFunc   func(ctx context.Context) ([]*model.Task, error)
+	GetAllTasksFunc   func(ctx context.Context, filter model.TaskFilter) ([]*model.Task, error)
 	GetAllTasksCalled bool
+	GetAllTasksFilter model.TaskFilter
 
 	GetTaskByIDFunc   func(ctx context.Context, id int) (*model.Task, error)
 	GetTaskByIDCalled bool
@@ -23,9 +25,78 @@ type MockTaskUsecase struct {
 	UpdateTaskCalled bool
 	UpdateTaskTask   *model.Task
 
-	DeleteTaskFunc   func(ctx context.Context, id int) error
-	DeleteTaskCalled bool
-	DeleteTaskID     int
+	DeleteTaskFunc    func(ctx context.Context, id int, version int) error
+	DeleteTaskCalled  bool
+	DeleteTaskID      int
+	DeleteTaskVersion int
+
+	GetDeletedTasksFunc   func(ctx context.Context) ([]*model.Task, error)
+	GetDeletedTasksCalled bool
+
+	RestoreTaskFunc   func(ctx context.Context, id int) (*model.Task, error)
+	RestoreTaskCalled bool
+	RestoreTaskID     int
+
+	GetTaskHistoryFunc   func(ctx context.Context, id int) ([]*model.Revision, error)
+	GetTaskHistoryCalled bool
+
+	GetTaskRevisionFunc   func(ctx context.Context, id int, rev int) (*model.Revision, error)
+	GetTaskRevisionCalled bool
+
+	DiffTaskRevisionsFunc   func(ctx context.Context, id int, from int, to int) ([]model.FieldChange, error)
+	DiffTaskRevisionsCalled bool
+
+	RevertTaskFunc   func(ctx context.Context, id int, rev int, version int) (*model.Task, error)
+	RevertTaskCalled bool
+	RevertTaskCtx    context.Context
+
+	ImportTasksFunc   func(ctx context.Context, tasks []*model.Task, dryRun bool) []error
+	ImportTasksCalled bool
+	ImportTasksTasks  []*model.Task
+	ImportTasksDryRun bool
+
+	GetTagsFunc   func(ctx context.Context) ([]model.TagCount, error)
+	GetTagsCalled bool
+
+	RenameTagFunc   func(ctx context.Context, from, to string) ([]*model.Task, error)
+	RenameTagCalled bool
+	RenameTagFrom   string
+	RenameTagTo     string
+
+	GetChildrenFunc   func(ctx context.Context, id int) ([]*model.Task, error)
+	GetChildrenCalled bool
+	GetChildrenID     int
+
+	GetTaskTreeFunc   func(ctx context.Context, id int, depth int) (*model.TaskNode, error)
+	GetTaskTreeCalled bool
+	GetTaskTreeID     int
+	GetTaskTreeDepth  int
+
+	AddDependencyFunc      func(ctx context.Context, id int, dependsOn int, version int) (*model.Task, error)
+	AddDependencyCalled    bool
+	AddDependencyID        int
+	AddDependencyDependsOn int
+	AddDependencyVersion   int
+
+	RemoveDependencyFunc      func(ctx context.Context, id int, dependsOn int, version int) (*model.Task, error)
+	RemoveDependencyCalled    bool
+	RemoveDependencyID        int
+	RemoveDependencyDependsOn int
+	RemoveDependencyVersion   int
+
+	GetTasksInOrderFunc   func(ctx context.Context) ([]*model.Task, error)
+	GetTasksInOrderCalled bool
+
+	GetOccurrencesFunc   func(ctx context.Context, id int, count int) ([]time.Time, error)
+	GetOccurrencesCalled bool
+	GetOccurrencesID     int
+	GetOccurrencesCount  int
+
+	TransitionTaskFunc    func(ctx context.Context, id int, to model.Status, version int) (*model.Task, error)
+	TransitionTaskCalled  bool
+	TransitionTaskID      int
+	TransitionTaskTo      model.Status
+	TransitionTaskVersion int
 }
 
 func (m *MockTaskUsecase) CreateTask(ctx context.Context, task *model.Task) (int, error) {
@@ -39,11 +110,12 @@ func (m *MockTaskUsecase) CreateTask(ctx context.Context, task *model.Task) (int
 	return 0, nil
 }
 
-func (m *MockTaskUsecase) GetAllTasks(ctx context.Context) ([]*model.Task, error) {
+func (m *MockTaskUsecase) GetAllTasks(ctx context.Context, filter model.TaskFilter) ([]*model.Task, error) {
 	m.GetAllTasksCalled = true
+	m.GetAllTasksFilter = filter
 
 	if m.GetAllTasksFunc != nil {
-		return m.GetAllTasksFunc(ctx)
+		return m.GetAllTasksFunc(ctx, filter)
 	}
 
 	return nil, nil
@@ -71,13 +143,194 @@ func (m *MockTaskUsecase) UpdateTask(ctx context.Context, task *model.Task) erro
 	return nil
 }
 
-func (m *MockTaskUsecase) DeleteTask(ctx context.Context, id int) error {
+func (m *MockTaskUsecase) DeleteTask(ctx context.Context, id int, version int) error {
 	m.DeleteTaskCalled = true
 	m.DeleteTaskID = id
+	m.DeleteTaskVersion = version
 
 	if m.DeleteTaskFunc != nil {
-		return m.DeleteTaskFunc(ctx, id)
+		return m.DeleteTaskFunc(ctx, id, version)
 	}
 
 	return nil
 }
+
+func (m *MockTaskUsecase) GetDeletedTasks(ctx context.Context) ([]*model.Task, error) {
+	m.GetDeletedTasksCalled = true
+
+	if m.GetDeletedTasksFunc != nil {
+		return m.GetDeletedTasksFunc(ctx)
+	}
+
+	return nil, nil
+}
+
+func (m *MockTaskUsecase) RestoreTask(ctx context.Context, id int) (*model.Task, error) {
+	m.RestoreTaskCalled = true
+	m.RestoreTaskID = id
+
+	if m.RestoreTaskFunc != nil {
+		return m.RestoreTaskFunc(ctx, id)
+	}
+
+	return nil, nil
+}
+
+func (m *MockTaskUsecase) GetTaskHistory(ctx context.Context, id int) ([]*model.Revision, error) {
+	m.GetTaskHistoryCalled = true
+
+	if m.GetTaskHistoryFunc != nil {
+		return m.GetTaskHistoryFunc(ctx, id)
+	}
+
+	return nil, nil
+}
+
+func (m *MockTaskUsecase) GetTaskRevision(ctx context.Context, id int, rev int) (*model.Revision, error) {
+	m.GetTaskRevisionCalled = true
+
+	if m.GetTaskRevisionFunc != nil {
+		return m.GetTaskRevisionFunc(ctx, id, rev)
+	}
+
+	return nil, nil
+}
+
+func (m *MockTaskUsecase) DiffTaskRevisions(ctx context.Context, id int, from int, to int) ([]model.FieldChange, error) {
+	m.DiffTaskRevisionsCalled = true
+
+	if m.DiffTaskRevisionsFunc != nil {
+		return m.DiffTaskRevisionsFunc(ctx, id, from, to)
+	}
+
+	return nil, nil
+}
+
+func (m *MockTaskUsecase) RevertTask(ctx context.Context, id int, rev int, version int) (*model.Task, error) {
+	m.RevertTaskCalled = true
+	m.RevertTaskCtx = ctx
+
+	if m.RevertTaskFunc != nil {
+		return m.RevertTaskFunc(ctx, id, rev, version)
+	}
+
+	return nil, nil
+}
+
+func (m *MockTaskUsecase) ImportTasks(ctx context.Context, tasks []*model.Task, dryRun bool) []error {
+	m.ImportTasksCalled = true
+	m.ImportTasksTasks = tasks
+	m.ImportTasksDryRun = dryRun
+
+	if m.ImportTasksFunc != nil {
+		return m.ImportTasksFunc(ctx, tasks, dryRun)
+	}
+
+	return make([]error, len(tasks))
+}
+
+func (m *MockTaskUsecase) GetTags(ctx context.Context) ([]model.TagCount, error) {
+	m.GetTagsCalled = true
+
+	if m.GetTagsFunc != nil {
+		return m.GetTagsFunc(ctx)
+	}
+
+	return nil, nil
+}
+
+func (m *MockTaskUsecase) RenameTag(ctx context.Context, from, to string) ([]*model.Task, error) {
+	m.RenameTagCalled = true
+	m.RenameTagFrom = from
+	m.RenameTagTo = to
+
+	if m.RenameTagFunc != nil {
+		return m.RenameTagFunc(ctx, from, to)
+	}
+
+	return nil, nil
+}
+
+func (m *MockTaskUsecase) GetChildren(ctx context.Context, id int) ([]*model.Task, error) {
+	m.GetChildrenCalled = true
+	m.GetChildrenID = id
+
+	if m.GetChildrenFunc != nil {
+		return m.GetChildrenFunc(ctx, id)
+	}
+
+	return nil, nil
+}
+
+func (m *MockTaskUsecase) GetTaskTree(ctx context.Context, id int, depth int) (*model.TaskNode, error) {
+	m.GetTaskTreeCalled = true
+	m.GetTaskTreeID = id
+	m.GetTaskTreeDepth = depth
+
+	if m.GetTaskTreeFunc != nil {
+		return m.GetTaskTreeFunc(ctx, id, depth)
+	}
+
+	return nil, nil
+}
+
+func (m *MockTaskUsecase) AddDependency(ctx context.Context, id int, dependsOn int, version int) (*model.Task, error) {
+	m.AddDependencyCalled = true
+	m.AddDependencyID = id
+	m.AddDependencyDependsOn = dependsOn
+	m.AddDependencyVersion = version
+
+	if m.AddDependencyFunc != nil {
+		return m.AddDependencyFunc(ctx, id, dependsOn, version)
+	}
+
+	return nil, nil
+}
+
+func (m *MockTaskUsecase) RemoveDependency(ctx context.Context, id int, dependsOn int, version int) (*model.Task, error) {
+	m.RemoveDependencyCalled = true
+	m.RemoveDependencyID = id
+	m.RemoveDependencyDependsOn = dependsOn
+	m.RemoveDependencyVersion = version
+
+	if m.RemoveDependencyFunc != nil {
+		return m.RemoveDependencyFunc(ctx, id, dependsOn, version)
+	}
+
+	return nil, nil
+}
+
+func (m *MockTaskUsecase) GetTasksInOrder(ctx context.Context) ([]*model.Task, error) {
+	m.GetTasksInOrderCalled = true
+
+	if m.GetTasksInOrderFunc != nil {
+		return m.GetTasksInOrderFunc(ctx)
+	}
+
+	return nil, nil
+}
+
+func (m *MockTaskUsecase) GetOccurrences(ctx context.Context, id int, count int) ([]time.Time, error) {
+	m.GetOccurrencesCalled = true
+	m.GetOccurrencesID = id
+	m.GetOccurrencesCount = count
+
+	if m.GetOccurrencesFunc != nil {
+		return m.GetOccurrencesFunc(ctx, id, count)
+	}
+
+	return nil, nil
+}
+
+func (m *MockTaskUsecase) TransitionTask(ctx context.Context, id int, to model.Status, version int) (*model.Task, error) {
+	m.TransitionTaskCalled = true
+	m.TransitionTaskID = id
+	m.TransitionTaskTo = to
+	m.TransitionTaskVersion = version
+
+	if m.TransitionTaskFunc != nil {
+		return m.TransitionTaskFunc(ctx, id, to, version)
+	}
+
+	return nil, nil
+}
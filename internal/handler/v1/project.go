@@ -0,0 +1,513 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/solumD/tasks-service/internal/handler/v1/dto"
+	"github.com/solumD/tasks-service/internal/usecase"
+	"github.com/solumD/tasks-service/pkg/logger"
+)
+
+// CreateProject обрабатывает запрос на создание нового проекта
+func (h *handler) CreateProject(ctx context.Context) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const fn = "handler.CreateProject"
+		log := h.log.With(logger.String("fn", fn))
+
+		log.Info("new request")
+
+		var req dto.CreateProjectReq
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			log.Error("failed to decode request", logger.Error(err))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusBadRequest, ErrFailedToDecodeReq)
+			return
+		}
+
+		log.Info("decoded request", logger.Any("request body", req))
+
+		id, err := h.projectUsecase.CreateProject(ctx, dto.FromCreateProjectReqToProject(req))
+		if err != nil {
+			if errors.Is(err, usecase.ErrEmptyProjectName) {
+				log.Error("failed to create project", logger.Error(err))
+
+				h.errorResponse(w, contentTypeJSON, http.StatusBadRequest, err)
+				return
+			}
+
+			if errors.Is(err, usecase.ErrProjectsDisabled) {
+				log.Error("failed to create project", logger.Error(err))
+
+				h.errorResponse(w, contentTypeJSON, http.StatusNotImplemented, err)
+				return
+			}
+
+			log.Error("failed to create project", logger.Error(err))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusInternalServerError, ErrFailedToCreateProject)
+			return
+		}
+
+		resp := &dto.CreateProjectResp{ID: id}
+		respBody, err := json.Marshal(resp)
+		if err != nil {
+			log.Error("failed to marshal response", logger.Error(err))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusInternalServerError, ErrFailedToCreateProject)
+			return
+		}
+
+		log.Info("created project", logger.Int("project id", id))
+
+		h.response(w, contentTypeJSON, http.StatusCreated, respBody)
+	}
+}
+
+// GetProjects обрабатывает запрос на получение всех проектов с количеством их задач
+func (h *handler) GetProjects(ctx context.Context) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const fn = "handler.GetProjects"
+		log := h.log.With(logger.String("fn", fn))
+
+		log.Info("new request")
+
+		projects, err := h.projectUsecase.GetProjects(ctx)
+		if err != nil {
+			log.Error("failed to get projects", logger.Error(err))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusInternalServerError, ErrFailedToGetProjects)
+			return
+		}
+
+		resp := dto.FromProjectsListToResp(projects)
+		respBody, err := json.Marshal(resp)
+		if err != nil {
+			log.Error("failed to marshal response", logger.Error(err))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusInternalServerError, ErrFailedToGetProjects)
+			return
+		}
+
+		log.Info("got projects", logger.Int("projects count", len(projects)))
+
+		h.response(w, contentTypeJSON, http.StatusOK, respBody)
+	}
+}
+
+// GetProject обрабатывает запрос на получение проекта по id
+func (h *handler) GetProject(ctx context.Context) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const fn = "handler.GetProject"
+		log := h.log.With(logger.String("fn", fn))
+
+		log.Info("new request")
+
+		projectID, err := strconv.Atoi(r.PathValue("pid"))
+		if err != nil {
+			log.Error("failed to get project id from path", logger.Error(err))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusBadRequest, ErrInvalidProjectIDType)
+			return
+		}
+
+		log.Info("got project id from path", logger.Int("project id", projectID))
+
+		project, err := h.projectUsecase.GetProject(ctx, projectID)
+		if err != nil {
+			if errors.Is(err, usecase.ErrProjectNotFound) {
+				log.Error("failed to get project", logger.Error(err))
+
+				h.errorResponse(w, contentTypeJSON, http.StatusNotFound, err)
+				return
+			}
+
+			log.Error("failed to get project", logger.Error(err))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusInternalServerError, ErrFailedToGetProject)
+			return
+		}
+
+		resp := dto.FromProjectToDTO(project)
+		respBody, err := json.Marshal(resp)
+		if err != nil {
+			log.Error("failed to marshal response", logger.Error(err))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusInternalServerError, ErrFailedToGetProject)
+			return
+		}
+
+		log.Info("got project", logger.Int("project id", project.ID))
+
+		w.Header().Set(headerETag, etag(project.Version))
+		h.response(w, contentTypeJSON, http.StatusOK, respBody)
+	}
+}
+
+// UpdateProject обрабатывает запрос на обновление проекта, в том числе на его архивацию
+func (h *handler) UpdateProject(ctx context.Context) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const fn = "handler.UpdateProject"
+		log := h.log.With(logger.String("fn", fn))
+
+		log.Info("new request")
+
+		projectID, err := strconv.Atoi(r.PathValue("pid"))
+		if err != nil {
+			log.Error("failed to get project id from path", logger.Error(err))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusBadRequest, ErrInvalidProjectIDType)
+			return
+		}
+
+		log.Info("got project id from path", logger.Int("project id", projectID))
+
+		version, err := versionFromIfMatch(r, h.projectVersion(ctx, projectID))
+		if err != nil {
+			log.Error("failed to get project version from header", logger.Error(err))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusBadRequest, err)
+			return
+		}
+
+		var req dto.UpdateProjectReq
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			log.Error("failed to decode request", logger.Error(err))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusBadRequest, ErrFailedToDecodeReq)
+			return
+		}
+
+		log.Info("decoded request", logger.Any("request body", req))
+
+		project := dto.FromUpdateProjectReqToProject(req)
+		project.ID = projectID
+		project.Version = version
+
+		err = h.projectUsecase.UpdateProject(ctx, project, req.Archived)
+		if err != nil {
+			if errors.Is(err, usecase.ErrEmptyProjectName) {
+				log.Error("failed to update project", logger.Error(err))
+
+				h.errorResponse(w, contentTypeJSON, http.StatusBadRequest, err)
+				return
+			}
+
+			if errors.Is(err, usecase.ErrProjectNotFound) {
+				log.Error("failed to update project", logger.Error(err))
+
+				h.errorResponse(w, contentTypeJSON, http.StatusNotFound, err)
+				return
+			}
+
+			if errors.Is(err, usecase.ErrProjectVersionConflict) {
+				log.Error("failed to update project", logger.Error(err))
+
+				h.errorResponse(w, contentTypeJSON, http.StatusPreconditionFailed, err)
+				return
+			}
+
+			log.Error("failed to update project", logger.Error(err))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusInternalServerError, ErrFailedToUpdateProject)
+			return
+		}
+
+		log.Info("updated project", logger.Int("project id", project.ID))
+
+		w.Header().Set(headerETag, etag(project.Version))
+		h.response(w, contentTypeJSON, http.StatusOK, nil)
+	}
+}
+
+// DeleteProject обрабатывает запрос на удаление проекта
+func (h *handler) DeleteProject(ctx context.Context) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const fn = "handler.DeleteProject"
+		log := h.log.With(logger.String("fn", fn))
+		ctx := withAuthor(ctx, r)
+
+		log.Info("new request")
+
+		projectID, err := strconv.Atoi(r.PathValue("pid"))
+		if err != nil {
+			log.Error("failed to get project id from path", logger.Error(err))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusBadRequest, ErrInvalidProjectIDType)
+			return
+		}
+
+		log.Info("got project id from path", logger.Int("project id", projectID))
+
+		version, err := versionFromIfMatch(r, h.projectVersion(ctx, projectID))
+		if err != nil {
+			log.Error("failed to get project version from header", logger.Error(err))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusBadRequest, err)
+			return
+		}
+
+		err = h.projectUsecase.DeleteProject(ctx, projectID, version)
+		if err != nil {
+			if errors.Is(err, usecase.ErrProjectNotFound) {
+				log.Error("failed to delete project", logger.Error(err))
+
+				h.errorResponse(w, contentTypeJSON, http.StatusNotFound, err)
+				return
+			}
+
+			if errors.Is(err, usecase.ErrProjectVersionConflict) {
+				log.Error("failed to delete project", logger.Error(err))
+
+				h.errorResponse(w, contentTypeJSON, http.StatusPreconditionFailed, err)
+				return
+			}
+
+			if errors.Is(err, usecase.ErrProjectHasTasks) {
+				log.Error("failed to delete project", logger.Error(err))
+
+				h.errorResponse(w, contentTypeJSON, http.StatusConflict, err)
+				return
+			}
+
+			log.Error("failed to delete project", logger.Error(err))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusInternalServerError, ErrFailedToDeleteProject)
+			return
+		}
+
+		log.Info("deleted project", logger.Int("project id", projectID))
+
+		h.response(w, contentTypeJSON, http.StatusOK, nil)
+	}
+}
+
+// GetProjectTasks обрабатывает запрос на получение задач проекта. Параметры запроса
+// те же, что у GetAllTasks
+func (h *handler) GetProjectTasks(ctx context.Context) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const fn = "handler.GetProjectTasks"
+		log := h.log.With(logger.String("fn", fn))
+
+		log.Info("new request")
+
+		projectID, err := strconv.Atoi(r.PathValue("pid"))
+		if err != nil {
+			log.Error("failed to get project id from path", logger.Error(err))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusBadRequest, ErrInvalidProjectIDType)
+			return
+		}
+
+		filter, err := parseTaskFilter(r)
+		if err != nil {
+			log.Error("failed to get filter from query", logger.Error(err))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusBadRequest, err)
+			return
+		}
+
+		tasks, err := h.projectUsecase.GetProjectTasks(ctx, projectID, filter)
+		if err != nil {
+			if errors.Is(err, usecase.ErrProjectNotFound) {
+				log.Error("failed to get project tasks", logger.Error(err))
+
+				h.errorResponse(w, contentTypeJSON, http.StatusNotFound, err)
+				return
+			}
+
+			if errors.Is(err, usecase.ErrInvalidTag) {
+				log.Error("failed to get project tasks", logger.Error(err))
+
+				h.errorResponse(w, contentTypeJSON, http.StatusBadRequest, err)
+				return
+			}
+
+			log.Error("failed to get project tasks", logger.Error(err))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusInternalServerError, ErrFailedToGetProjectTasks)
+			return
+		}
+
+		resp := dto.FromTasksListToResp(tasks)
+		respBody, err := json.Marshal(resp)
+		if err != nil {
+			log.Error("failed to marshal response", logger.Error(err))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusInternalServerError, ErrFailedToGetProjectTasks)
+			return
+		}
+
+		log.Info("got project tasks", logger.Int("project id", projectID), logger.Int("tasks count", len(tasks)))
+
+		h.response(w, contentTypeJSON, http.StatusOK, respBody)
+	}
+}
+
+// CreateProjectTask обрабатывает запрос на создание задачи в проекте.
+// project_id из тела запроса не учитывается
+func (h *handler) CreateProjectTask(ctx context.Context) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const fn = "handler.CreateProjectTask"
+		log := h.log.With(logger.String("fn", fn))
+		ctx := withAuthor(ctx, r)
+
+		log.Info("new request")
+
+		projectID, err := strconv.Atoi(r.PathValue("pid"))
+		if err != nil {
+			log.Error("failed to get project id from path", logger.Error(err))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusBadRequest, ErrInvalidProjectIDType)
+			return
+		}
+
+		var req dto.CreateTaskReq
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			log.Error("failed to decode request", logger.Error(err))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusBadRequest, ErrFailedToDecodeReq)
+			return
+		}
+
+		log.Info("decoded request", logger.Int("project id", projectID), logger.Any("request body", req))
+
+		id, err := h.projectUsecase.CreateProjectTask(ctx, projectID, dto.FromCreateReqToTask(req))
+		if err != nil {
+			if errors.Is(err, usecase.ErrProjectNotFound) {
+				log.Error("failed to create project task", logger.Error(err))
+
+				h.errorResponse(w, contentTypeJSON, http.StatusNotFound, err)
+				return
+			}
+
+			if errors.Is(err, usecase.ErrEmptyTitle) || errors.Is(err, usecase.ErrInvalidDueDate) ||
+				errors.Is(err, usecase.ErrInvalidPriority) || errors.Is(err, usecase.ErrInvalidTag) ||
+				errors.Is(err, usecase.ErrParentNotFound) || errors.Is(err, usecase.ErrTaskCycle) ||
+				errors.Is(err, usecase.ErrInvalidDependency) || errors.Is(err, usecase.ErrDependencyNotFound) ||
+				errors.Is(err, usecase.ErrDependencyCycle) || errors.Is(err, usecase.ErrInvalidRecurrence) ||
+				errors.Is(err, usecase.ErrRecurrenceWithoutDue) || errors.Is(err, usecase.ErrInvalidStatus) {
+				log.Error("failed to create project task", logger.Error(err))
+
+				h.errorResponse(w, contentTypeJSON, http.StatusBadRequest, err)
+				return
+			}
+
+			if errors.Is(err, usecase.ErrTaskBlocked) || errors.Is(err, usecase.ErrInvalidTransition) ||
+				errors.Is(err, usecase.ErrProjectArchived) {
+				log.Error("failed to create project task", logger.Error(err))
+
+				h.errorResponse(w, contentTypeJSON, http.StatusConflict, err)
+				return
+			}
+
+			log.Error("failed to create project task", logger.Error(err))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusInternalServerError, ErrFailedToCreateTask)
+			return
+		}
+
+		resp := &dto.CreateTaskResp{ID: id}
+		respBody, err := json.Marshal(resp)
+		if err != nil {
+			log.Error("failed to marshal response", logger.Error(err))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusInternalServerError, ErrFailedToCreateTask)
+			return
+		}
+
+		log.Info("created project task", logger.Int("project id", projectID), logger.Int("task id", id))
+
+		h.response(w, contentTypeJSON, http.StatusCreated, respBody)
+	}
+}
+
+// MoveTask обрабатывает запрос на перенос задачи в другой проект
+func (h *handler) MoveTask(ctx context.Context) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const fn = "handler.MoveTask"
+		log := h.log.With(logger.String("fn", fn))
+		ctx := withAuthor(ctx, r)
+
+		log.Info("new request")
+
+		taskID, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			log.Error("failed to get task id from path", logger.Error(err))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusBadRequest, ErrInvalidTaskIDType)
+			return
+		}
+
+		var req dto.MoveTaskReq
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			log.Error("failed to decode request", logger.Error(err))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusBadRequest, ErrFailedToDecodeReq)
+			return
+		}
+
+		log.Info("decoded request", logger.Int("task id", taskID), logger.Any("request body", req))
+
+		version, err := versionFromIfMatch(r, h.taskVersion(ctx, taskID))
+		if err != nil {
+			log.Error("failed to get task version from header", logger.Error(err))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusBadRequest, err)
+			return
+		}
+
+		task, err := h.projectUsecase.MoveTask(ctx, taskID, req.ProjectID, version)
+		if err != nil {
+			if errors.Is(err, usecase.ErrProjectNotFound) {
+				log.Error("failed to move task", logger.Error(err))
+
+				h.errorResponse(w, contentTypeJSON, http.StatusBadRequest, err)
+				return
+			}
+
+			if errors.Is(err, usecase.ErrTaskNotFound) {
+				log.Error("failed to move task", logger.Error(err))
+
+				h.errorResponse(w, contentTypeJSON, http.StatusNotFound, err)
+				return
+			}
+
+			if errors.Is(err, usecase.ErrProjectArchived) {
+				log.Error("failed to move task", logger.Error(err))
+
+				h.errorResponse(w, contentTypeJSON, http.StatusConflict, err)
+				return
+			}
+
+			if errors.Is(err, usecase.ErrVersionConflict) {
+				log.Error("failed to move task", logger.Error(err))
+
+				h.errorResponse(w, contentTypeJSON, http.StatusPreconditionFailed, err)
+				return
+			}
+
+			log.Error("failed to move task", logger.Error(err))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusInternalServerError, ErrFailedToMoveTask)
+			return
+		}
+
+		resp := dto.FromTaskToResp(task)
+		respBody, err := json.Marshal(resp)
+		if err != nil {
+			log.Error("failed to marshal response", logger.Error(err))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusInternalServerError, ErrFailedToMoveTask)
+			return
+		}
+
+		log.Info("moved task", logger.Int("task id", taskID), logger.Int("project id", req.ProjectID))
+
+		w.Header().Set(headerETag, etag(task.Version))
+		h.response(w, contentTypeJSON, http.StatusOK, respBody)
+	}
+}
@@ -0,0 +1,82 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/solumD/tasks-service/internal/handler/v1/dto"
+	"github.com/solumD/tasks-service/internal/usecase"
+	"github.com/solumD/tasks-service/pkg/logger"
+)
+
+// defaultOccurrences количество повторений, если count не указан
+const defaultOccurrences = 5
+
+// GetOccurrences обрабатывает запрос на получение следующих повторений задачи
+func (h *handler) GetOccurrences(ctx context.Context) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const fn = "handler.GetOccurrences"
+		log := h.log.With(logger.String("fn", fn))
+
+		log.Info("new request")
+
+		taskID, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			log.Error("failed to get task id from path", logger.Error(err))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusBadRequest, ErrInvalidTaskIDType)
+			return
+		}
+
+		count := defaultOccurrences
+		if value := r.URL.Query().Get("count"); value != "" {
+			count, err = strconv.Atoi(value)
+			if err != nil {
+				log.Error("failed to get count from query", logger.String("count", value))
+
+				h.errorResponse(w, contentTypeJSON, http.StatusBadRequest, ErrInvalidOccurrences)
+				return
+			}
+		}
+
+		log.Info("got task id from path", logger.Int("task id", taskID), logger.Int("count", count))
+
+		occurrences, err := h.taskUsecase.GetOccurrences(ctx, taskID, count)
+		if err != nil {
+			if errors.Is(err, usecase.ErrInvalidOccurrences) {
+				log.Error("failed to get task occurrences", logger.Error(err))
+
+				h.errorResponse(w, contentTypeJSON, http.StatusBadRequest, err)
+				return
+			}
+
+			if errors.Is(err, usecase.ErrTaskNotFound) {
+				log.Error("failed to get task occurrences", logger.Error(err))
+
+				h.errorResponse(w, contentTypeJSON, http.StatusNotFound, err)
+				return
+			}
+
+			log.Error("failed to get task occurrences", logger.Error(err))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusInternalServerError, ErrFailedToGetOccurrences)
+			return
+		}
+
+		resp := dto.GetOccurrencesResp{Occurrences: occurrences}
+		respBody, err := json.Marshal(resp)
+		if err != nil {
+			log.Error("failed to marshal response", logger.Error(err))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusInternalServerError, ErrFailedToGetOccurrences)
+			return
+		}
+
+		log.Info("got task occurrences", logger.Int("task id", taskID), logger.Int("occurrences count", len(occurrences)))
+
+		h.response(w, contentTypeJSON, http.StatusOK, respBody)
+	}
+}
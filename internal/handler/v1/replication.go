@@ -0,0 +1,138 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/solumD/tasks-service/internal/handler/v1/dto"
+	"github.com/solumD/tasks-service/internal/replication"
+	"github.com/solumD/tasks-service/pkg/logger"
+)
+
+// GetReplicationLog обрабатывает запрос ведомого узла на чтение журнала репликации
+func (h *handler) GetReplicationLog(_ context.Context) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const fn = "handler.GetReplicationLog"
+		log := h.log.With(logger.String("fn", fn))
+
+		log.Info("new request")
+
+		query := r.URL.Query()
+
+		from, err := strconv.Atoi(query.Get("from"))
+		if err != nil || from < 0 {
+			log.Error("failed to get log position from query", logger.String("from", query.Get("from")))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusBadRequest, ErrInvalidLogPosition)
+			return
+		}
+
+		var wait time.Duration
+		if value := query.Get("wait"); value != "" {
+			wait, err = time.ParseDuration(value)
+			if err != nil || wait < 0 {
+				log.Error("failed to get wait from query", logger.String("wait", value))
+
+				h.errorResponse(w, contentTypeJSON, http.StatusBadRequest, ErrInvalidWait)
+				return
+			}
+		}
+
+		// ожидание новых записей прерывается, когда ведомый узел закрывает соединение
+		batch, err := h.replicationUsecase.ReadLog(r.Context(), query.Get("follower"), from, wait)
+		if err != nil {
+			switch {
+			case errors.Is(err, replication.ErrNotLeader):
+				log.Error("failed to read replication log", logger.Error(err))
+
+				h.errorResponse(w, contentTypeJSON, http.StatusConflict, err)
+			case errors.Is(err, replication.ErrLogTruncated):
+				log.Error("failed to read replication log", logger.Error(err))
+
+				h.errorResponse(w, contentTypeJSON, http.StatusGone, err)
+			default:
+				log.Error("failed to read replication log", logger.Error(err))
+
+				h.errorResponse(w, contentTypeJSON, http.StatusInternalServerError, ErrFailedToReadLog)
+			}
+
+			return
+		}
+
+		resp := dto.FromLogBatchToResp(batch)
+		respBody, err := json.Marshal(resp)
+		if err != nil {
+			log.Error("failed to marshal response", logger.Error(err))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusInternalServerError, ErrFailedToReadLog)
+			return
+		}
+
+		log.Info("read replication log", logger.Int("from", from), logger.Int("entries count", len(batch.Entries)))
+
+		h.response(w, contentTypeJSON, http.StatusOK, respBody)
+	}
+}
+
+// GetReplicationSnapshot обрабатывает запрос ведомого узла на снимок всех задач
+func (h *handler) GetReplicationSnapshot(ctx context.Context) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const fn = "handler.GetReplicationSnapshot"
+		log := h.log.With(logger.String("fn", fn))
+
+		log.Info("new request")
+
+		snapshot, err := h.replicationUsecase.Snapshot(ctx)
+		if err != nil {
+			if errors.Is(err, replication.ErrNotLeader) {
+				log.Error("failed to get replication snapshot", logger.Error(err))
+
+				h.errorResponse(w, contentTypeJSON, http.StatusConflict, err)
+				return
+			}
+
+			log.Error("failed to get replication snapshot", logger.Error(err))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusInternalServerError, ErrFailedToGetSnapshot)
+			return
+		}
+
+		resp := dto.FromReplicationSnapshotToResp(snapshot)
+		respBody, err := json.Marshal(resp)
+		if err != nil {
+			log.Error("failed to marshal response", logger.Error(err))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusInternalServerError, ErrFailedToGetSnapshot)
+			return
+		}
+
+		log.Info("got replication snapshot", logger.Int("seq", snapshot.Seq), logger.Int("tasks count", len(snapshot.Tasks)))
+
+		h.response(w, contentTypeJSON, http.StatusOK, respBody)
+	}
+}
+
+// GetReplicationStatus обрабатывает запрос на получение состояния репликации узла
+func (h *handler) GetReplicationStatus(_ context.Context) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const fn = "handler.GetReplicationStatus"
+		log := h.log.With(logger.String("fn", fn))
+
+		log.Info("new request")
+
+		resp := dto.FromReplicationStatusToResp(h.replicationUsecase.Status())
+		respBody, err := json.Marshal(resp)
+		if err != nil {
+			log.Error("failed to marshal response", logger.Error(err))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusInternalServerError, err)
+			return
+		}
+
+		h.response(w, contentTypeJSON, http.StatusOK, respBody)
+	}
+}
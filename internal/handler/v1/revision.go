@@ -0,0 +1,280 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/solumD/tasks-service/internal/handler/v1/dto"
+	"github.com/solumD/tasks-service/internal/usecase"
+	"github.com/solumD/tasks-service/pkg/logger"
+)
+
+// parseRevision разбирает номер ревизии, номера начинаются с 1
+func parseRevision(value string) (int, error) {
+	rev, err := strconv.Atoi(value)
+	if err != nil || rev <= 0 {
+		return 0, ErrInvalidRevision
+	}
+
+	return rev, nil
+}
+
+// GetTaskHistory обрабатывает запрос на получение всех ревизий задачи
+func (h *handler) GetTaskHistory(ctx context.Context) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const fn = "handler.GetTaskHistory"
+		log := h.log.With(logger.String("fn", fn))
+
+		log.Info("new request")
+
+		taskID, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			log.Error("failed to get task id from path", logger.Error(err))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusBadRequest, ErrInvalidTaskIDType)
+			return
+		}
+
+		log.Info("got task id from path", logger.Int("task id", taskID))
+
+		revisions, err := h.taskUsecase.GetTaskHistory(ctx, taskID)
+		if err != nil {
+			if errors.Is(err, usecase.ErrTaskNotFound) {
+				log.Error("failed to get task history", logger.Error(err))
+
+				h.errorResponse(w, contentTypeJSON, http.StatusNotFound, err)
+				return
+			}
+
+			log.Error("failed to get task history", logger.Error(err))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusInternalServerError, ErrFailedToGetHistory)
+			return
+		}
+
+		resp := dto.FromRevisionsListToResp(revisions)
+		respBody, err := json.Marshal(resp)
+		if err != nil {
+			log.Error("failed to marshal response", logger.Error(err))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusInternalServerError, ErrFailedToGetHistory)
+			return
+		}
+
+		log.Info("got task history", logger.Int("task id", taskID), logger.Int("revisions count", len(revisions)))
+
+		h.response(w, contentTypeJSON, http.StatusOK, respBody)
+	}
+}
+
+// GetTaskRevision обрабатывает запрос на получение одной ревизии задачи
+func (h *handler) GetTaskRevision(ctx context.Context) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const fn = "handler.GetTaskRevision"
+		log := h.log.With(logger.String("fn", fn))
+
+		log.Info("new request")
+
+		taskID, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			log.Error("failed to get task id from path", logger.Error(err))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusBadRequest, ErrInvalidTaskIDType)
+			return
+		}
+
+		rev, err := parseRevision(r.PathValue("rev"))
+		if err != nil {
+			log.Error("failed to get revision from path", logger.Error(err))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusBadRequest, err)
+			return
+		}
+
+		log.Info("got task revision from path", logger.Int("task id", taskID), logger.Int("rev", rev))
+
+		revision, err := h.taskUsecase.GetTaskRevision(ctx, taskID, rev)
+		if err != nil {
+			if errors.Is(err, usecase.ErrRevisionNotFound) {
+				log.Error("failed to get task revision", logger.Error(err))
+
+				h.errorResponse(w, contentTypeJSON, http.StatusNotFound, err)
+				return
+			}
+
+			log.Error("failed to get task revision", logger.Error(err))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusInternalServerError, ErrFailedToGetRevision)
+			return
+		}
+
+		resp := dto.FromRevisionToDTO(revision)
+		respBody, err := json.Marshal(resp)
+		if err != nil {
+			log.Error("failed to marshal response", logger.Error(err))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusInternalServerError, ErrFailedToGetRevision)
+			return
+		}
+
+		log.Info("got task revision", logger.Int("task id", taskID), logger.Int("rev", rev))
+
+		h.response(w, contentTypeJSON, http.StatusOK, respBody)
+	}
+}
+
+// DiffTaskRevisions обрабатывает запрос на сравнение двух ревизий задачи,
+// номера ревизий передаются в параметрах from и to
+func (h *handler) DiffTaskRevisions(ctx context.Context) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const fn = "handler.DiffTaskRevisions"
+		log := h.log.With(logger.String("fn", fn))
+
+		log.Info("new request")
+
+		taskID, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			log.Error("failed to get task id from path", logger.Error(err))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusBadRequest, ErrInvalidTaskIDType)
+			return
+		}
+
+		query := r.URL.Query()
+
+		from, err := parseRevision(query.Get("from"))
+		if err != nil {
+			log.Error("failed to get revision from query", logger.Error(err))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusBadRequest, err)
+			return
+		}
+
+		to, err := parseRevision(query.Get("to"))
+		if err != nil {
+			log.Error("failed to get revision from query", logger.Error(err))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusBadRequest, err)
+			return
+		}
+
+		log.Info("got revisions from query", logger.Int("task id", taskID), logger.Int("from", from), logger.Int("to", to))
+
+		changes, err := h.taskUsecase.DiffTaskRevisions(ctx, taskID, from, to)
+		if err != nil {
+			if errors.Is(err, usecase.ErrRevisionNotFound) {
+				log.Error("failed to diff task revisions", logger.Error(err))
+
+				h.errorResponse(w, contentTypeJSON, http.StatusNotFound, err)
+				return
+			}
+
+			log.Error("failed to diff task revisions", logger.Error(err))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusInternalServerError, ErrFailedToDiffTask)
+			return
+		}
+
+		resp := dto.FromChangesToResp(from, to, changes)
+		respBody, err := json.Marshal(resp)
+		if err != nil {
+			log.Error("failed to marshal response", logger.Error(err))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusInternalServerError, ErrFailedToDiffTask)
+			return
+		}
+
+		log.Info("diffed task revisions", logger.Int("task id", taskID), logger.Int("changes count", len(changes)))
+
+		h.response(w, contentTypeJSON, http.StatusOK, respBody)
+	}
+}
+
+// RevertTask обрабатывает запрос на откат задачи к ревизии
+func (h *handler) RevertTask(ctx context.Context) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const fn = "handler.RevertTask"
+		log := h.log.With(logger.String("fn", fn))
+		ctx := withAuthor(ctx, r)
+
+		log.Info("new request")
+
+		taskID, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			log.Error("failed to get task id from path", logger.Error(err))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusBadRequest, ErrInvalidTaskIDType)
+			return
+		}
+
+		rev, err := parseRevision(r.PathValue("rev"))
+		if err != nil {
+			log.Error("failed to get revision from path", logger.Error(err))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusBadRequest, err)
+			return
+		}
+
+		log.Info("got task revision from path", logger.Int("task id", taskID), logger.Int("rev", rev))
+
+		version, err := versionFromIfMatch(r, h.taskVersion(ctx, taskID))
+		if err != nil {
+			log.Error("failed to get task version from header", logger.Error(err))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusBadRequest, err)
+			return
+		}
+
+		task, err := h.taskUsecase.RevertTask(ctx, taskID, rev, version)
+		if err != nil {
+			if errors.Is(err, usecase.ErrTaskNotFound) || errors.Is(err, usecase.ErrRevisionNotFound) {
+				log.Error("failed to revert task", logger.Error(err))
+
+				h.errorResponse(w, contentTypeJSON, http.StatusNotFound, err)
+				return
+			}
+
+			if errors.Is(err, usecase.ErrVersionConflict) {
+				log.Error("failed to revert task", logger.Error(err))
+
+				h.errorResponse(w, contentTypeJSON, http.StatusPreconditionFailed, err)
+				return
+			}
+
+			// родителем из ревизии стала одна из подзадач, зависимость из ревизии
+			// замыкает цикл, ревизия отмечает выполненной заблокированную задачу
+			// или переход к статусу из ревизии не разрешен рабочим процессом,
+			// проект из ревизии в архиве
+			if errors.Is(err, usecase.ErrTaskCycle) || errors.Is(err, usecase.ErrDependencyCycle) ||
+				errors.Is(err, usecase.ErrTaskBlocked) || errors.Is(err, usecase.ErrInvalidTransition) ||
+				errors.Is(err, usecase.ErrProjectArchived) {
+				log.Error("failed to revert task", logger.Error(err))
+
+				h.errorResponse(w, contentTypeJSON, http.StatusConflict, err)
+				return
+			}
+
+			log.Error("failed to revert task", logger.Error(err))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusInternalServerError, ErrFailedToRevertTask)
+			return
+		}
+
+		resp := dto.FromTaskToResp(task)
+		respBody, err := json.Marshal(resp)
+		if err != nil {
+			log.Error("failed to marshal response", logger.Error(err))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusInternalServerError, ErrFailedToRevertTask)
+			return
+		}
+
+		log.Info("reverted task", logger.Int("task id", taskID), logger.Int("rev", rev))
+
+		w.Header().Set(headerETag, etag(task.Version))
+		h.response(w, contentTypeJSON, http.StatusOK, respBody)
+	}
+}
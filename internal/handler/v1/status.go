@@ -0,0 +1,103 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/solumD/tasks-service/internal/handler/v1/dto"
+	"github.com/solumD/tasks-service/internal/model"
+	"github.com/solumD/tasks-service/internal/usecase"
+	"github.com/solumD/tasks-service/pkg/logger"
+)
+
+// TransitionTask обрабатывает запрос на перевод задачи в другой статус
+func (h *handler) TransitionTask(ctx context.Context) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const fn = "handler.TransitionTask"
+		log := h.log.With(logger.String("fn", fn))
+		ctx := withAuthor(ctx, r)
+
+		log.Info("new request")
+
+		taskID, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			log.Error("failed to get task id from path", logger.Error(err))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusBadRequest, ErrInvalidTaskIDType)
+			return
+		}
+
+		var req dto.TransitionTaskReq
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			log.Error("failed to decode request", logger.Error(err))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusBadRequest, ErrFailedToDecodeReq)
+			return
+		}
+
+		log.Info("decoded request", logger.Int("task id", taskID), logger.Any("request body", req))
+
+		version, err := versionFromIfMatch(r, h.taskVersion(ctx, taskID))
+		if err != nil {
+			log.Error("failed to get task version from header", logger.Error(err))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusBadRequest, err)
+			return
+		}
+
+		task, err := h.taskUsecase.TransitionTask(ctx, taskID, model.Status(req.To), version)
+		if err != nil {
+			if errors.Is(err, usecase.ErrInvalidStatus) {
+				log.Error("failed to change task status", logger.Error(err))
+
+				h.errorResponse(w, contentTypeJSON, http.StatusBadRequest, err)
+				return
+			}
+
+			if errors.Is(err, usecase.ErrTaskNotFound) {
+				log.Error("failed to change task status", logger.Error(err))
+
+				h.errorResponse(w, contentTypeJSON, http.StatusNotFound, err)
+				return
+			}
+
+			// переход не разрешен рабочим процессом или задача с невыполненными
+			// зависимостями переводится в done
+			if errors.Is(err, usecase.ErrInvalidTransition) || errors.Is(err, usecase.ErrTaskBlocked) {
+				log.Error("failed to change task status", logger.Error(err))
+
+				h.errorResponse(w, contentTypeJSON, http.StatusConflict, err)
+				return
+			}
+
+			if errors.Is(err, usecase.ErrVersionConflict) {
+				log.Error("failed to change task status", logger.Error(err))
+
+				h.errorResponse(w, contentTypeJSON, http.StatusPreconditionFailed, err)
+				return
+			}
+
+			log.Error("failed to change task status", logger.Error(err))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusInternalServerError, ErrFailedToTransitionTask)
+			return
+		}
+
+		resp := dto.FromTaskToResp(task)
+		respBody, err := json.Marshal(resp)
+		if err != nil {
+			log.Error("failed to marshal response", logger.Error(err))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusInternalServerError, ErrFailedToTransitionTask)
+			return
+		}
+
+		log.Info("changed task status", logger.Int("task id", taskID), logger.String("status", req.To))
+
+		w.Header().Set(headerETag, etag(task.Version))
+		h.response(w, contentTypeJSON, http.StatusOK, respBody)
+	}
+}
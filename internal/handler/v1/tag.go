@@ -0,0 +1,93 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/solumD/tasks-service/internal/handler/v1/dto"
+	"github.com/solumD/tasks-service/internal/usecase"
+	"github.com/solumD/tasks-service/pkg/logger"
+)
+
+// GetTags обрабатывает запрос на получение меток с количеством задач у каждой
+func (h *handler) GetTags(ctx context.Context) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const fn = "handler.GetTags"
+		log := h.log.With(logger.String("fn", fn))
+
+		log.Info("new request")
+
+		counts, err := h.taskUsecase.GetTags(ctx)
+		if err != nil {
+			log.Error("failed to get tags", logger.Error(err))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusInternalServerError, ErrFailedToGetTags)
+			return
+		}
+
+		resp := dto.FromTagCountsToResp(counts)
+		respBody, err := json.Marshal(resp)
+		if err != nil {
+			log.Error("failed to marshal response", logger.Error(err))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusInternalServerError, ErrFailedToGetTags)
+			return
+		}
+
+		log.Info("got tags", logger.Int("tags count", len(counts)))
+
+		h.response(w, contentTypeJSON, http.StatusOK, respBody)
+	}
+}
+
+// RenameTag обрабатывает запрос на переименование метки у всех задач. Если новая
+// метка у задачи уже есть, метки сливаются
+func (h *handler) RenameTag(ctx context.Context) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const fn = "handler.RenameTag"
+		log := h.log.With(logger.String("fn", fn))
+		ctx := withAuthor(ctx, r)
+
+		log.Info("new request")
+
+		var req dto.RenameTagReq
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			log.Error("failed to decode request", logger.Error(err))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusBadRequest, ErrFailedToDecodeReq)
+			return
+		}
+
+		from := r.PathValue("tag")
+
+		renamed, err := h.taskUsecase.RenameTag(ctx, from, req.To)
+		if err != nil {
+			if errors.Is(err, usecase.ErrInvalidTag) {
+				log.Error("failed to rename tag", logger.Error(err))
+
+				h.errorResponse(w, contentTypeJSON, http.StatusBadRequest, err)
+				return
+			}
+
+			log.Error("failed to rename tag", logger.Error(err))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusInternalServerError, ErrFailedToRenameTag)
+			return
+		}
+
+		resp := &dto.RenameTagResp{From: from, To: req.To, Renamed: len(renamed)}
+		respBody, err := json.Marshal(resp)
+		if err != nil {
+			log.Error("failed to marshal response", logger.Error(err))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusInternalServerError, ErrFailedToRenameTag)
+			return
+		}
+
+		log.Info("renamed tag", logger.String("from", from), logger.String("to", req.To), logger.Int("tasks count", len(renamed)))
+
+		h.response(w, contentTypeJSON, http.StatusOK, respBody)
+	}
+}
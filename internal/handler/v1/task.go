@@ -4,11 +4,14 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/solumD/tasks-service/internal/handler/v1/dto"
+	"github.com/solumD/tasks-service/internal/model"
 	"github.com/solumD/tasks-service/internal/usecase"
 	"github.com/solumD/tasks-service/pkg/logger"
 )
@@ -18,6 +21,7 @@ func (h *handler) CreateTask(ctx context.Context) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const fn = "handler.CreateTask"
 		log := h.log.With(logger.String("fn", fn))
+		ctx := withAuthor(ctx, r)
 
 		log.Info("new request")
 
@@ -33,13 +37,27 @@ func (h *handler) CreateTask(ctx context.Context) http.HandlerFunc {
 
 		id, err := h.taskUsecase.CreateTask(ctx, dto.FromCreateReqToTask(req))
 		if err != nil {
-			if errors.Is(err, usecase.ErrEmptyTitle) {
+			if errors.Is(err, usecase.ErrEmptyTitle) || errors.Is(err, usecase.ErrInvalidDueDate) ||
+				errors.Is(err, usecase.ErrInvalidPriority) || errors.Is(err, usecase.ErrInvalidTag) ||
+				errors.Is(err, usecase.ErrParentNotFound) || errors.Is(err, usecase.ErrTaskCycle) ||
+				errors.Is(err, usecase.ErrInvalidDependency) || errors.Is(err, usecase.ErrDependencyNotFound) ||
+				errors.Is(err, usecase.ErrDependencyCycle) || errors.Is(err, usecase.ErrInvalidRecurrence) ||
+				errors.Is(err, usecase.ErrRecurrenceWithoutDue) || errors.Is(err, usecase.ErrInvalidStatus) ||
+				errors.Is(err, usecase.ErrProjectNotFound) {
 				log.Error("failed to create task", logger.Error(err))
 
 				h.errorResponse(w, contentTypeJSON, http.StatusBadRequest, err)
 				return
 			}
 
+			if errors.Is(err, usecase.ErrTaskBlocked) || errors.Is(err, usecase.ErrInvalidTransition) ||
+				errors.Is(err, usecase.ErrProjectArchived) {
+				log.Error("failed to create task", logger.Error(err))
+
+				h.errorResponse(w, contentTypeJSON, http.StatusConflict, err)
+				return
+			}
+
 			log.Error("failed to create task", logger.Error(err))
 
 			h.errorResponse(w, contentTypeJSON, http.StatusInternalServerError, ErrFailedToCreateTask)
@@ -61,7 +79,10 @@ func (h *handler) CreateTask(ctx context.Context) http.HandlerFunc {
 	}
 }
 
-// GetAllTasks обрабатывает запрос на получение всех задач
+// GetAllTasks обрабатывает запрос на получение всех задач. Параметры due_after и due_before
+// ограничивают срок выполнения (due_after включительно), overdue=true выбирает просроченные задачи.
+// Параметр tags задает метки через запятую, match - нужна ли задаче любая из них (any) или все (all).
+// Параметр sort задает поле сортировки (id, created, priority, due, title), order - направление (asc, desc)
 func (h *handler) GetAllTasks(ctx context.Context) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const fn = "handler.GetAllTasks"
@@ -69,8 +90,23 @@ func (h *handler) GetAllTasks(ctx context.Context) http.HandlerFunc {
 
 		log.Info("new request")
 
-		tasks, err := h.taskUsecase.GetAllTasks(ctx)
+		filter, err := parseTaskFilter(r)
 		if err != nil {
+			log.Error("failed to get filter from query", logger.Error(err))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusBadRequest, err)
+			return
+		}
+
+		tasks, err := h.taskUsecase.GetAllTasks(ctx, filter)
+		if err != nil {
+			if errors.Is(err, usecase.ErrInvalidTag) {
+				log.Error("failed to get all tasks", logger.Error(err))
+
+				h.errorResponse(w, contentTypeJSON, http.StatusBadRequest, err)
+				return
+			}
+
 			log.Error("failed to get all tasks", logger.Error(err))
 
 			h.errorResponse(w, contentTypeJSON, http.StatusInternalServerError, ErrFailedToGetAllTasks)
@@ -139,6 +175,7 @@ func (h *handler) GetTaskByID(ctx context.Context) http.HandlerFunc {
 
 		log.Info("got task by id", logger.Int("task id", task.ID))
 
+		w.Header().Set(headerETag, etag(task.Version))
 		h.response(w, contentTypeJSON, http.StatusOK, respBody)
 	}
 }
@@ -148,6 +185,7 @@ func (h *handler) UpdateTask(ctx context.Context) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const fn = "handler.UpdateTask"
 		log := h.log.With(logger.String("fn", fn))
+		ctx := withAuthor(ctx, r)
 
 		log.Info("new request")
 
@@ -164,6 +202,14 @@ func (h *handler) UpdateTask(ctx context.Context) http.HandlerFunc {
 
 		log.Info("got task id from path", logger.Int("task id", taskID))
 
+		version, err := versionFromIfMatch(r, h.taskVersion(ctx, taskID))
+		if err != nil {
+			log.Error("failed to get task version from header", logger.Error(err))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusBadRequest, err)
+			return
+		}
+
 		var req dto.UpdateTaskReq
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 			log.Error("failed to decode request", logger.Error(err))
@@ -176,16 +222,31 @@ func (h *handler) UpdateTask(ctx context.Context) http.HandlerFunc {
 
 		task := dto.FromUpdateReqToTask(req)
 		task.ID = taskID
+		task.Version = version
 
 		err = h.taskUsecase.UpdateTask(ctx, task)
 		if err != nil {
-			if errors.Is(err, usecase.ErrEmptyTitle) {
+			if errors.Is(err, usecase.ErrEmptyTitle) || errors.Is(err, usecase.ErrInvalidDueDate) ||
+				errors.Is(err, usecase.ErrInvalidPriority) || errors.Is(err, usecase.ErrInvalidTag) ||
+				errors.Is(err, usecase.ErrParentNotFound) || errors.Is(err, usecase.ErrTaskCycle) ||
+				errors.Is(err, usecase.ErrInvalidDependency) || errors.Is(err, usecase.ErrDependencyNotFound) ||
+				errors.Is(err, usecase.ErrDependencyCycle) || errors.Is(err, usecase.ErrInvalidRecurrence) ||
+				errors.Is(err, usecase.ErrRecurrenceWithoutDue) || errors.Is(err, usecase.ErrInvalidStatus) ||
+				errors.Is(err, usecase.ErrProjectNotFound) {
 				log.Error("failed to update task", logger.Error(err))
 
 				h.errorResponse(w, contentTypeJSON, http.StatusBadRequest, err)
 				return
 			}
 
+			if errors.Is(err, usecase.ErrTaskBlocked) || errors.Is(err, usecase.ErrInvalidTransition) ||
+				errors.Is(err, usecase.ErrProjectArchived) {
+				log.Error("failed to update task", logger.Error(err))
+
+				h.errorResponse(w, contentTypeJSON, http.StatusConflict, err)
+				return
+			}
+
 			if errors.Is(err, usecase.ErrTaskNotFound) {
 				log.Error("failed to update task", logger.Error(err))
 
@@ -193,6 +254,13 @@ func (h *handler) UpdateTask(ctx context.Context) http.HandlerFunc {
 				return
 			}
 
+			if errors.Is(err, usecase.ErrVersionConflict) {
+				log.Error("failed to update task", logger.Error(err))
+
+				h.errorResponse(w, contentTypeJSON, http.StatusPreconditionFailed, err)
+				return
+			}
+
 			log.Error("failed to update task", logger.Error(err))
 
 			h.errorResponse(w, contentTypeJSON, http.StatusInternalServerError, ErrFailedToUpdateTask)
@@ -201,6 +269,7 @@ func (h *handler) UpdateTask(ctx context.Context) http.HandlerFunc {
 
 		log.Info("updated task", logger.Int("task id", task.ID))
 
+		w.Header().Set(headerETag, etag(task.Version))
 		h.response(w, contentTypeJSON, http.StatusOK, nil)
 	}
 }
@@ -210,6 +279,7 @@ func (h *handler) DeleteTask(ctx context.Context) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const fn = "handler.DeleteTask"
 		log := h.log.With(logger.String("fn", fn))
+		ctx := withAuthor(ctx, r)
 
 		log.Info("new request")
 
@@ -226,7 +296,15 @@ func (h *handler) DeleteTask(ctx context.Context) http.HandlerFunc {
 
 		log.Info("got task id from path", logger.Int("task id", taskID))
 
-		err = h.taskUsecase.DeleteTask(ctx, taskID)
+		version, err := versionFromIfMatch(r, h.taskVersion(ctx, taskID))
+		if err != nil {
+			log.Error("failed to get task version from header", logger.Error(err))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusBadRequest, err)
+			return
+		}
+
+		err = h.taskUsecase.DeleteTask(ctx, taskID, version)
 		if err != nil {
 			if errors.Is(err, usecase.ErrTaskNotFound) {
 				log.Error("failed to delete task", logger.Error(err))
@@ -235,6 +313,20 @@ func (h *handler) DeleteTask(ctx context.Context) http.HandlerFunc {
 				return
 			}
 
+			if errors.Is(err, usecase.ErrVersionConflict) {
+				log.Error("failed to delete task", logger.Error(err))
+
+				h.errorResponse(w, contentTypeJSON, http.StatusPreconditionFailed, err)
+				return
+			}
+
+			if errors.Is(err, usecase.ErrTaskHasChildren) {
+				log.Error("failed to delete task", logger.Error(err))
+
+				h.errorResponse(w, contentTypeJSON, http.StatusConflict, err)
+				return
+			}
+
 			log.Error("failed to delete task", logger.Error(err))
 
 			h.errorResponse(w, contentTypeJSON, http.StatusInternalServerError, ErrFailedToDeleteTask)
@@ -246,3 +338,148 @@ func (h *handler) DeleteTask(ctx context.Context) http.HandlerFunc {
 		h.response(w, contentTypeJSON, http.StatusOK, nil)
 	}
 }
+
+// GetDeletedTasks обрабатывает запрос на получение задач из корзины
+func (h *handler) GetDeletedTasks(ctx context.Context) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const fn = "handler.GetDeletedTasks"
+		log := h.log.With(logger.String("fn", fn))
+
+		log.Info("new request")
+
+		tasks, err := h.taskUsecase.GetDeletedTasks(ctx)
+		if err != nil {
+			log.Error("failed to get deleted tasks", logger.Error(err))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusInternalServerError, ErrFailedToGetTrash)
+			return
+		}
+
+		resp := dto.FromTasksListToResp(tasks)
+		respBody, err := json.Marshal(resp)
+		if err != nil {
+			log.Error("failed to marshal response", logger.Error(err))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusInternalServerError, ErrFailedToGetTrash)
+			return
+		}
+
+		log.Info("got deleted tasks", logger.Int("tasks count", len(tasks)))
+
+		h.response(w, contentTypeJSON, http.StatusOK, respBody)
+	}
+}
+
+// RestoreTask обрабатывает запрос на восстановление задачи из корзины
+func (h *handler) RestoreTask(ctx context.Context) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const fn = "handler.RestoreTask"
+		log := h.log.With(logger.String("fn", fn))
+		ctx := withAuthor(ctx, r)
+
+		log.Info("new request")
+
+		taskID, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			log.Error("failed to get task id from path", logger.Error(err))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusBadRequest, ErrInvalidTaskIDType)
+			return
+		}
+
+		log.Info("got task id from path", logger.Int("task id", taskID))
+
+		task, err := h.taskUsecase.RestoreTask(ctx, taskID)
+		if err != nil {
+			if errors.Is(err, usecase.ErrTaskNotFound) {
+				log.Error("failed to restore task", logger.Error(err))
+
+				h.errorResponse(w, contentTypeJSON, http.StatusNotFound, err)
+				return
+			}
+
+			log.Error("failed to restore task", logger.Error(err))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusInternalServerError, ErrFailedToRestoreTask)
+			return
+		}
+
+		resp := dto.FromTaskToResp(task)
+		respBody, err := json.Marshal(resp)
+		if err != nil {
+			log.Error("failed to marshal response", logger.Error(err))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusInternalServerError, ErrFailedToRestoreTask)
+			return
+		}
+
+		log.Info("restored task", logger.Int("task id", task.ID))
+
+		w.Header().Set(headerETag, etag(task.Version))
+		h.response(w, contentTypeJSON, http.StatusOK, respBody)
+	}
+}
+
+// parseTaskFilter возвращает условия выборки и порядок задач из параметров запроса.
+// Сроки передаются в RFC 3339
+func parseTaskFilter(r *http.Request) (model.TaskFilter, error) {
+	query := r.URL.Query()
+
+	var filter model.TaskFilter
+
+	if value := query.Get("due_after"); value != "" {
+		dueAfter, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return model.TaskFilter{}, fmt.Errorf("%w: due_after %q", ErrInvalidDueDate, value)
+		}
+
+		filter.DueAfter = dueAfter
+	}
+
+	if value := query.Get("due_before"); value != "" {
+		dueBefore, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return model.TaskFilter{}, fmt.Errorf("%w: due_before %q", ErrInvalidDueDate, value)
+		}
+
+		filter.DueBefore = dueBefore
+	}
+
+	if value := query.Get("overdue"); value != "" {
+		overdue, err := strconv.ParseBool(value)
+		if err != nil {
+			return model.TaskFilter{}, fmt.Errorf("%w: %q", ErrInvalidOverdue, value)
+		}
+
+		filter.Overdue = overdue
+	}
+
+	if value := query.Get("tags"); value != "" {
+		filter.Tags = strings.Split(value, ",")
+	}
+
+	switch match := query.Get("match"); match {
+	case "", "any":
+	case "all":
+		filter.AllTags = true
+	default:
+		return model.TaskFilter{}, fmt.Errorf("%w: %q", ErrInvalidMatch, match)
+	}
+
+	switch sortBy := model.SortField(query.Get("sort")); sortBy {
+	case "", model.SortByID, model.SortByCreated, model.SortByPriority, model.SortByDue, model.SortByTitle:
+		filter.SortBy = sortBy
+	default:
+		return model.TaskFilter{}, fmt.Errorf("%w: %q", ErrInvalidSort, sortBy)
+	}
+
+	switch order := query.Get("order"); order {
+	case "", "asc":
+	case "desc":
+		filter.Desc = true
+	default:
+		return model.TaskFilter{}, fmt.Errorf("%w: %q", ErrInvalidOrder, order)
+	}
+
+	return filter, nil
+}
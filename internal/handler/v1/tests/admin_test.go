@@ -0,0 +1,118 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/solumD/tasks-service/internal/backup"
+	v1 "github.com/solumD/tasks-service/internal/handler/v1"
+	"github.com/solumD/tasks-service/internal/handler/v1/mock"
+	"github.com/solumD/tasks-service/internal/model"
+	"github.com/solumD/tasks-service/pkg/logger"
+)
+
+func TestHandler_RestoreBackup(t *testing.T) {
+	ctx := context.Background()
+	createdAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	name := backup.FileName(createdAt)
+
+	tests := []struct {
+		name                 string
+		body                 string
+		usecaseFunc          func(ctx context.Context, name string) (*model.BackupInfo, error)
+		expectedStatus       int
+		expectedRespContains string
+		expectedCalled       bool
+	}{
+		{
+			name:                 "invalid json",
+			body:                 `{"backup":`,
+			expectedStatus:       http.StatusBadRequest,
+			expectedRespContains: "failed to decode request",
+		},
+		{
+			name: "invalid name",
+			body: `{"backup":"../tasks.db"}`,
+			usecaseFunc: func(ctx context.Context, name string) (*model.BackupInfo, error) {
+				return nil, backup.ErrInvalidBackupName
+			},
+			expectedStatus:       http.StatusBadRequest,
+			expectedRespContains: backup.ErrInvalidBackupName.Error(),
+			expectedCalled:       true,
+		},
+		{
+			name: "not found",
+			body: fmt.Sprintf(`{"backup":%q}`, name),
+			usecaseFunc: func(ctx context.Context, name string) (*model.BackupInfo, error) {
+				return nil, backup.ErrBackupNotFound
+			},
+			expectedStatus:       http.StatusNotFound,
+			expectedRespContains: backup.ErrBackupNotFound.Error(),
+			expectedCalled:       true,
+		},
+		{
+			name: "corrupted",
+			body: fmt.Sprintf(`{"backup":%q}`, name),
+			usecaseFunc: func(ctx context.Context, name string) (*model.BackupInfo, error) {
+				return nil, fmt.Errorf("checksum mismatch: %w", backup.ErrBackupCorrupted)
+			},
+			expectedStatus:       http.StatusUnprocessableEntity,
+			expectedRespContains: "checksum mismatch",
+			expectedCalled:       true,
+		},
+		{
+			name: "store error",
+			body: fmt.Sprintf(`{"backup":%q}`, name),
+			usecaseFunc: func(ctx context.Context, name string) (*model.BackupInfo, error) {
+				return nil, errors.New("db error")
+			},
+			expectedStatus:       http.StatusInternalServerError,
+			expectedRespContains: v1.ErrFailedToRestoreBackup.Error(),
+			expectedCalled:       true,
+		},
+		{
+			name: "success",
+			body: fmt.Sprintf(`{"backup":%q}`, name),
+			usecaseFunc: func(ctx context.Context, name string) (*model.BackupInfo, error) {
+				return &model.BackupInfo{Name: name, CreatedAt: createdAt, Tasks: 3, NextID: 5, Checksum: "abc"}, nil
+			},
+			expectedStatus:       http.StatusOK,
+			expectedRespContains: `"next_id":5`,
+			expectedCalled:       true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockBackup := &mock.MockBackupUsecase{
+				RestoreBackupFunc: tt.usecaseFunc,
+			}
+
+			log := logger.NewMockLogger()
+			h := v1.NewHandler(&mock.MockTaskUsecase{}, &mock.MockProjectUsecase{}, &mock.MockCommentUsecase{}, mockBackup, &mock.MockReplicationUsecase{}, &mock.MockChangeUsecase{}, log)
+
+			req := httptest.NewRequest(http.MethodPost, "/admin/restore", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+
+			h.RestoreBackup(ctx).ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+
+			if !strings.Contains(w.Body.String(), tt.expectedRespContains) {
+				t.Fatalf("expected body to contain %q, got %q", tt.expectedRespContains, w.Body.String())
+			}
+
+			if mockBackup.RestoreBackupCalled != tt.expectedCalled {
+				t.Fatalf("expected RestoreBackup called = %v, got %v", tt.expectedCalled, mockBackup.RestoreBackupCalled)
+			}
+		})
+	}
+}
@@ -0,0 +1,171 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/solumD/tasks-service/internal/cdc"
+	v1 "github.com/solumD/tasks-service/internal/handler/v1"
+	"github.com/solumD/tasks-service/internal/handler/v1/mock"
+	"github.com/solumD/tasks-service/internal/model"
+	"github.com/solumD/tasks-service/pkg/logger"
+)
+
+func TestHandler_GetChanges(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name                 string
+		query                string
+		usecaseFunc          func(ctx context.Context, from int, limit int, wait time.Duration) (*model.ChangeBatch, error)
+		expectedStatus       int
+		expectedRespContains string
+		expectedCalled       bool
+		expectedFrom         int
+		expectedLimit        int
+		expectedWait         time.Duration
+	}{
+		{
+			name:                 "negative position",
+			query:                "from=-1",
+			expectedStatus:       http.StatusBadRequest,
+			expectedRespContains: v1.ErrInvalidChangePosition.Error(),
+		},
+		{
+			name:                 "invalid limit",
+			query:                "from=0&limit=0",
+			expectedStatus:       http.StatusBadRequest,
+			expectedRespContains: v1.ErrInvalidLimit.Error(),
+		},
+		{
+			name:                 "invalid wait",
+			query:                "from=0&wait=soon",
+			expectedStatus:       http.StatusBadRequest,
+			expectedRespContains: v1.ErrInvalidWait.Error(),
+		},
+		{
+			name:  "expired position",
+			query: "from=2",
+			usecaseFunc: func(ctx context.Context, from int, limit int, wait time.Duration) (*model.ChangeBatch, error) {
+				return nil, cdc.ErrPositionExpired
+			},
+			expectedStatus:       http.StatusGone,
+			expectedRespContains: cdc.ErrPositionExpired.Error(),
+			expectedCalled:       true,
+			expectedFrom:         2,
+			expectedLimit:        100,
+		},
+		{
+			name:  "position ahead",
+			query: "from=50",
+			usecaseFunc: func(ctx context.Context, from int, limit int, wait time.Duration) (*model.ChangeBatch, error) {
+				return nil, cdc.ErrPositionAhead
+			},
+			expectedStatus:       http.StatusBadRequest,
+			expectedRespContains: cdc.ErrPositionAhead.Error(),
+			expectedCalled:       true,
+			expectedFrom:         50,
+			expectedLimit:        100,
+		},
+		{
+			name:  "usecase error",
+			query: "from=0",
+			usecaseFunc: func(ctx context.Context, from int, limit int, wait time.Duration) (*model.ChangeBatch, error) {
+				return nil, errors.New("disk error")
+			},
+			expectedStatus:       http.StatusInternalServerError,
+			expectedRespContains: v1.ErrFailedToReadChanges.Error(),
+			expectedCalled:       true,
+			expectedLimit:        100,
+		},
+		{
+			name:                 "from oldest by default",
+			query:                "",
+			expectedStatus:       http.StatusOK,
+			expectedRespContains: `"events":[]`,
+			expectedCalled:       true,
+			expectedFrom:         cdc.FromOldest,
+			expectedLimit:        100,
+		},
+		{
+			name:  "success",
+			query: "from=3&limit=10&wait=2s",
+			usecaseFunc: func(ctx context.Context, from int, limit int, wait time.Duration) (*model.ChangeBatch, error) {
+				return &model.ChangeBatch{
+					First: 1,
+					Head:  4,
+					Events: []*model.ChangeEvent{
+						{Seq: 4, Type: model.ChangeDeleted, TaskID: 2, Author: "alice", Task: model.Task{ID: 2, Title: "A", Version: 3}},
+					},
+				}, nil
+			},
+			expectedStatus:       http.StatusOK,
+			expectedRespContains: `"seq":4,"type":"deleted","task_id":2,"author":"alice"`,
+			expectedCalled:       true,
+			expectedFrom:         3,
+			expectedLimit:        10,
+			expectedWait:         2 * time.Second,
+		},
+		{
+			name:  "reset without task",
+			query: "from=4",
+			usecaseFunc: func(ctx context.Context, from int, limit int, wait time.Duration) (*model.ChangeBatch, error) {
+				return &model.ChangeBatch{
+					First: 1,
+					Head:  5,
+					Events: []*model.ChangeEvent{
+						{Seq: 5, Type: model.ChangeReset, CreatedAt: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)},
+					},
+				}, nil
+			},
+			expectedStatus:       http.StatusOK,
+			expectedRespContains: `{"seq":5,"type":"reset","task_id":0,"created_at":"2024-05-01T12:00:00Z"}`,
+			expectedCalled:       true,
+			expectedFrom:         4,
+			expectedLimit:        100,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockChanges := &mock.MockChangeUsecase{
+				ReadFunc: tt.usecaseFunc,
+			}
+
+			log := logger.NewMockLogger()
+			h := v1.NewHandler(&mock.MockTaskUsecase{}, &mock.MockProjectUsecase{}, &mock.MockCommentUsecase{}, &mock.MockBackupUsecase{}, &mock.MockReplicationUsecase{}, mockChanges, log)
+
+			req := httptest.NewRequest(http.MethodGet, "/changes?"+tt.query, nil)
+			w := httptest.NewRecorder()
+
+			h.GetChanges(ctx).ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+
+			if !strings.Contains(w.Body.String(), tt.expectedRespContains) {
+				t.Fatalf("expected body to contain %q, got %q", tt.expectedRespContains, w.Body.String())
+			}
+
+			if mockChanges.ReadCalled != tt.expectedCalled {
+				t.Fatalf("expected Read called = %v, got %v", tt.expectedCalled, mockChanges.ReadCalled)
+			}
+
+			if !tt.expectedCalled {
+				return
+			}
+
+			if mockChanges.ReadFrom != tt.expectedFrom || mockChanges.ReadLimit != tt.expectedLimit || mockChanges.ReadWait != tt.expectedWait {
+				t.Fatalf("expected Read(%d, %d, %v), got Read(%d, %d, %v)",
+					tt.expectedFrom, tt.expectedLimit, tt.expectedWait,
+					mockChanges.ReadFrom, mockChanges.ReadLimit, mockChanges.ReadWait)
+			}
+		})
+	}
+}
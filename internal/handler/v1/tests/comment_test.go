@@ -0,0 +1,449 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	v1 "github.com/solumD/tasks-service/internal/handler/v1"
+	"github.com/solumD/tasks-service/internal/handler/v1/mock"
+	"github.com/solumD/tasks-service/internal/model"
+	"github.com/solumD/tasks-service/internal/usecase"
+	"github.com/solumD/tasks-service/pkg/logger"
+)
+
+func TestAddComment(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name           string
+		pathID         string
+		body           string
+		usecaseFunc    func(ctx context.Context, comment *model.Comment) (int, error)
+		expectedStatus int
+		expectedBody   string
+		expectedCalled bool
+	}{
+		{
+			name:   "success",
+			pathID: "1",
+			body:   `{"text":"hello","parent_id":2}`,
+			usecaseFunc: func(ctx context.Context, comment *model.Comment) (int, error) {
+				if comment.TaskID != 1 || comment.ParentID != 2 || comment.Text != "hello" {
+					return 0, errors.New("unexpected comment")
+				}
+
+				if usecase.AuthorFromContext(ctx) != "alice" {
+					return 0, errors.New("unexpected author")
+				}
+
+				return 3, nil
+			},
+			expectedStatus: http.StatusCreated,
+			expectedBody:   `{"id":3}`,
+			expectedCalled: true,
+		},
+		{
+			name:           "invalid ID",
+			pathID:         "abc",
+			body:           `{"text":"hello"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error_message":"invalid task id type"}`,
+		},
+		{
+			name:           "invalid body",
+			pathID:         "1",
+			body:           `{"text":1}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error_message":"failed to decode request"}`,
+		},
+		{
+			name:   "reply to reply",
+			pathID: "1",
+			body:   `{"text":"hello","parent_id":2}`,
+			usecaseFunc: func(ctx context.Context, comment *model.Comment) (int, error) {
+				return 0, usecase.ErrNestedReply
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error_message":"cannot reply to a reply"}`,
+			expectedCalled: true,
+		},
+		{
+			name:   "task not found",
+			pathID: "42",
+			body:   `{"text":"hello"}`,
+			usecaseFunc: func(ctx context.Context, comment *model.Comment) (int, error) {
+				return 0, usecase.NewTaskNotFoundError(comment.TaskID)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"error_message":"task not found: id 42"}`,
+			expectedCalled: true,
+		},
+		{
+			name:   "comments disabled",
+			pathID: "1",
+			body:   `{"text":"hello"}`,
+			usecaseFunc: func(ctx context.Context, comment *model.Comment) (int, error) {
+				return 0, usecase.ErrCommentsDisabled
+			},
+			expectedStatus: http.StatusNotImplemented,
+			expectedBody:   `{"error_message":"comments are not supported in raft and replication modes"}`,
+			expectedCalled: true,
+		},
+		{
+			name:   "usecase error",
+			pathID: "1",
+			body:   `{"text":"hello"}`,
+			usecaseFunc: func(ctx context.Context, comment *model.Comment) (int, error) {
+				return 0, errors.New("db error")
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"error_message":"failed to create comment"}`,
+			expectedCalled: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUsecase := &mock.MockCommentUsecase{AddCommentFunc: tt.usecaseFunc}
+			h := v1.NewHandler(&mock.MockTaskUsecase{}, &mock.MockProjectUsecase{}, mockUsecase, &mock.MockBackupUsecase{}, &mock.MockReplicationUsecase{}, &mock.MockChangeUsecase{}, logger.NewMockLogger())
+
+			req := httptest.NewRequest(http.MethodPost, "/todos/"+tt.pathID+"/comments", strings.NewReader(tt.body))
+			req.SetPathValue("id", tt.pathID)
+			req.Header.Set("X-Author", "alice")
+			w := httptest.NewRecorder()
+
+			h.AddComment(ctx).ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+
+			if strings.TrimSpace(w.Body.String()) != tt.expectedBody {
+				t.Fatalf("expected body %q, got %q", tt.expectedBody, w.Body.String())
+			}
+
+			if mockUsecase.AddCommentCalled != tt.expectedCalled {
+				t.Fatalf("expected usecase called = %v, got %v", tt.expectedCalled, mockUsecase.AddCommentCalled)
+			}
+		})
+	}
+}
+
+func TestGetComments(t *testing.T) {
+	ctx := context.Background()
+	createdAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		pathID         string
+		query          string
+		usecaseFunc    func(ctx context.Context, taskID int, after int, limit int) (*model.CommentPage, error)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:   "success",
+			pathID: "1",
+			query:  "?after=4&limit=1",
+			usecaseFunc: func(ctx context.Context, taskID int, after int, limit int) (*model.CommentPage, error) {
+				if after != 4 || limit != 1 {
+					return nil, errors.New("unexpected page")
+				}
+
+				return &model.CommentPage{
+					Threads: []*model.CommentThread{{
+						Comment: &model.Comment{ID: 5, TaskID: taskID, Author: "alice", Text: "hello", CreatedAt: createdAt},
+						Replies: []*model.Comment{{ID: 6, TaskID: taskID, ParentID: 5, Author: "bob", Text: "hi", CreatedAt: createdAt, EditedAt: &createdAt}},
+					}},
+					Next: 5,
+				}, nil
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"comments":[{"id":5,"author":"alice","text":"hello","created_at":"2026-03-01T12:00:00Z",` +
+				`"replies":[{"id":6,"parent_id":5,"author":"bob","text":"hi","created_at":"2026-03-01T12:00:00Z","edited_at":"2026-03-01T12:00:00Z"}]}],"next":5}`,
+		},
+		{
+			name:   "empty page",
+			pathID: "1",
+			usecaseFunc: func(ctx context.Context, taskID int, after int, limit int) (*model.CommentPage, error) {
+				return &model.CommentPage{Threads: []*model.CommentThread{}}, nil
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"comments":[]}`,
+		},
+		{
+			name:           "invalid after",
+			pathID:         "1",
+			query:          "?after=-1",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error_message":"invalid after value"}`,
+		},
+		{
+			name:           "invalid limit",
+			pathID:         "1",
+			query:          "?limit=abc",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error_message":"invalid limit"}`,
+		},
+		{
+			name:   "limit too large",
+			pathID: "1",
+			query:  "?limit=1000",
+			usecaseFunc: func(ctx context.Context, taskID int, after int, limit int) (*model.CommentPage, error) {
+				return nil, usecase.ErrInvalidCommentsLimit
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error_message":"comments limit must be from 1 to 100"}`,
+		},
+		{
+			name:   "task not found",
+			pathID: "42",
+			usecaseFunc: func(ctx context.Context, taskID int, after int, limit int) (*model.CommentPage, error) {
+				return nil, usecase.NewTaskNotFoundError(taskID)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"error_message":"task not found: id 42"}`,
+		},
+		{
+			name:   "usecase error",
+			pathID: "1",
+			usecaseFunc: func(ctx context.Context, taskID int, after int, limit int) (*model.CommentPage, error) {
+				return nil, errors.New("db error")
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"error_message":"failed to get comments"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUsecase := &mock.MockCommentUsecase{GetCommentsFunc: tt.usecaseFunc}
+			h := v1.NewHandler(&mock.MockTaskUsecase{}, &mock.MockProjectUsecase{}, mockUsecase, &mock.MockBackupUsecase{}, &mock.MockReplicationUsecase{}, &mock.MockChangeUsecase{}, logger.NewMockLogger())
+
+			req := httptest.NewRequest(http.MethodGet, "/todos/"+tt.pathID+"/comments"+tt.query, nil)
+			req.SetPathValue("id", tt.pathID)
+			w := httptest.NewRecorder()
+
+			h.GetComments(ctx).ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+
+			if strings.TrimSpace(w.Body.String()) != tt.expectedBody {
+				t.Fatalf("expected body %q, got %q", tt.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestUpdateComment(t *testing.T) {
+	ctx := context.Background()
+	createdAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	editedAt := createdAt.Add(time.Hour)
+
+	tests := []struct {
+		name           string
+		pathCID        string
+		body           string
+		usecaseFunc    func(ctx context.Context, comment *model.Comment) (*model.Comment, error)
+		expectedStatus int
+		expectedBody   string
+		expectedCalled bool
+	}{
+		{
+			name:    "success",
+			pathCID: "2",
+			body:    `{"text":"edited"}`,
+			usecaseFunc: func(ctx context.Context, comment *model.Comment) (*model.Comment, error) {
+				if comment.ID != 2 || comment.TaskID != 1 {
+					return nil, errors.New("unexpected comment")
+				}
+
+				return &model.Comment{ID: 2, TaskID: 1, Author: "alice", Text: comment.Text, CreatedAt: createdAt, EditedAt: &editedAt}, nil
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"id":2,"author":"alice","text":"edited","created_at":"2026-03-01T12:00:00Z","edited_at":"2026-03-01T13:00:00Z"}`,
+			expectedCalled: true,
+		},
+		{
+			name:           "invalid comment ID",
+			pathCID:        "abc",
+			body:           `{"text":"edited"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error_message":"invalid comment id type"}`,
+		},
+		{
+			name:    "empty text",
+			pathCID: "2",
+			body:    `{"text":""}`,
+			usecaseFunc: func(ctx context.Context, comment *model.Comment) (*model.Comment, error) {
+				return nil, usecase.ErrEmptyCommentText
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error_message":"comment text is empty"}`,
+			expectedCalled: true,
+		},
+		{
+			name:    "another author",
+			pathCID: "2",
+			body:    `{"text":"edited"}`,
+			usecaseFunc: func(ctx context.Context, comment *model.Comment) (*model.Comment, error) {
+				return nil, usecase.ErrCommentForbidden
+			},
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   `{"error_message":"comment belongs to another author"}`,
+			expectedCalled: true,
+		},
+		{
+			name:    "not found",
+			pathCID: "42",
+			body:    `{"text":"edited"}`,
+			usecaseFunc: func(ctx context.Context, comment *model.Comment) (*model.Comment, error) {
+				return nil, usecase.NewCommentNotFoundError(comment.ID)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"error_message":"comment not found: id 42"}`,
+			expectedCalled: true,
+		},
+		{
+			name:    "usecase error",
+			pathCID: "2",
+			body:    `{"text":"edited"}`,
+			usecaseFunc: func(ctx context.Context, comment *model.Comment) (*model.Comment, error) {
+				return nil, errors.New("db error")
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"error_message":"failed to update comment"}`,
+			expectedCalled: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUsecase := &mock.MockCommentUsecase{UpdateCommentFunc: tt.usecaseFunc}
+			h := v1.NewHandler(&mock.MockTaskUsecase{}, &mock.MockProjectUsecase{}, mockUsecase, &mock.MockBackupUsecase{}, &mock.MockReplicationUsecase{}, &mock.MockChangeUsecase{}, logger.NewMockLogger())
+
+			req := httptest.NewRequest(http.MethodPut, "/todos/1/comments/"+tt.pathCID, strings.NewReader(tt.body))
+			req.SetPathValue("id", "1")
+			req.SetPathValue("cid", tt.pathCID)
+			req.Header.Set("X-Author", "alice")
+			w := httptest.NewRecorder()
+
+			h.UpdateComment(ctx).ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+
+			if strings.TrimSpace(w.Body.String()) != tt.expectedBody {
+				t.Fatalf("expected body %q, got %q", tt.expectedBody, w.Body.String())
+			}
+
+			if mockUsecase.UpdateCommentCalled != tt.expectedCalled {
+				t.Fatalf("expected usecase called = %v, got %v", tt.expectedCalled, mockUsecase.UpdateCommentCalled)
+			}
+		})
+	}
+}
+
+func TestDeleteComment(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name           string
+		pathID         string
+		pathCID        string
+		usecaseFunc    func(ctx context.Context, taskID int, id int) error
+		expectedStatus int
+		expectedBody   string
+		expectedCalled bool
+	}{
+		{
+			name:    "success",
+			pathID:  "1",
+			pathCID: "2",
+			usecaseFunc: func(ctx context.Context, taskID int, id int) error {
+				if taskID != 1 || id != 2 || usecase.AuthorFromContext(ctx) != "alice" {
+					return errors.New("unexpected request")
+				}
+
+				return nil
+			},
+			expectedStatus: http.StatusOK,
+			expectedCalled: true,
+		},
+		{
+			name:           "invalid task ID",
+			pathID:         "abc",
+			pathCID:        "2",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error_message":"invalid task id type"}`,
+		},
+		{
+			name:    "another author",
+			pathID:  "1",
+			pathCID: "2",
+			usecaseFunc: func(ctx context.Context, taskID int, id int) error {
+				return usecase.ErrCommentForbidden
+			},
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   `{"error_message":"comment belongs to another author"}`,
+			expectedCalled: true,
+		},
+		{
+			name:    "task not found",
+			pathID:  "42",
+			pathCID: "2",
+			usecaseFunc: func(ctx context.Context, taskID int, id int) error {
+				return usecase.NewTaskNotFoundError(taskID)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"error_message":"task not found: id 42"}`,
+			expectedCalled: true,
+		},
+		{
+			name:    "usecase error",
+			pathID:  "1",
+			pathCID: "2",
+			usecaseFunc: func(ctx context.Context, taskID int, id int) error {
+				return errors.New("db error")
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"error_message":"failed to delete comment"}`,
+			expectedCalled: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUsecase := &mock.MockCommentUsecase{DeleteCommentFunc: tt.usecaseFunc}
+			h := v1.NewHandler(&mock.MockTaskUsecase{}, &mock.MockProjectUsecase{}, mockUsecase, &mock.MockBackupUsecase{}, &mock.MockReplicationUsecase{}, &mock.MockChangeUsecase{}, logger.NewMockLogger())
+
+			req := httptest.NewRequest(http.MethodDelete, "/todos/"+tt.pathID+"/comments/"+tt.pathCID, nil)
+			req.SetPathValue("id", tt.pathID)
+			req.SetPathValue("cid", tt.pathCID)
+			req.Header.Set("X-Author", "alice")
+			w := httptest.NewRecorder()
+
+			h.DeleteComment(ctx).ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+
+			if strings.TrimSpace(w.Body.String()) != tt.expectedBody {
+				t.Fatalf("expected body %q, got %q", tt.expectedBody, w.Body.String())
+			}
+
+			if mockUsecase.DeleteCommentCalled != tt.expectedCalled {
+				t.Fatalf("expected usecase called = %v, got %v", tt.expectedCalled, mockUsecase.DeleteCommentCalled)
+			}
+		})
+	}
+}
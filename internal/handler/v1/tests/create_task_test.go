@@ -74,7 +74,7 @@ func TestCreateTask(t *testing.T) {
 			}
 
 			log := logger.NewMockLogger()
-			h := v1.NewHandler(mockUsecase, log)
+			h := v1.NewHandler(mockUsecase, &mock.MockProjectUsecase{}, &mock.MockCommentUsecase{}, &mock.MockBackupUsecase{}, &mock.MockReplicationUsecase{}, &mock.MockChangeUsecase{}, log)
 
 			req := httptest.NewRequest(http.MethodPost, "/tasks", strings.NewReader(tt.reqBody))
 			w := httptest.NewRecorder()
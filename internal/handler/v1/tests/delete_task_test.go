@@ -10,6 +10,7 @@ import (
 
 	v1 "github.com/solumD/tasks-service/internal/handler/v1"
 	"github.com/solumD/tasks-service/internal/handler/v1/mock"
+	"github.com/solumD/tasks-service/internal/model"
 	"github.com/solumD/tasks-service/internal/usecase"
 	"github.com/solumD/tasks-service/pkg/logger"
 )
@@ -20,7 +21,9 @@ func TestHandler_DeleteTask(t *testing.T) {
 	tests := []struct {
 		name                 string
 		pathID               string
-		usecaseFunc          func(ctx context.Context, id int) error
+		ifMatch              string
+		currentVersion       int
+		usecaseFunc          func(ctx context.Context, id int, version int) error
 		expectedStatus       int
 		expectedRespContains string
 		expectedCalled       bool
@@ -36,7 +39,7 @@ func TestHandler_DeleteTask(t *testing.T) {
 		{
 			name:   "repo error",
 			pathID: "1",
-			usecaseFunc: func(ctx context.Context, id int) error {
+			usecaseFunc: func(ctx context.Context, id int, version int) error {
 				return errors.New("db error")
 			},
 			expectedStatus:       http.StatusInternalServerError,
@@ -46,17 +49,104 @@ func TestHandler_DeleteTask(t *testing.T) {
 		{
 			name:   "not found",
 			pathID: "1",
-			usecaseFunc: func(ctx context.Context, id int) error {
+			usecaseFunc: func(ctx context.Context, id int, version int) error {
 				return usecase.ErrTaskNotFound
 			},
 			expectedStatus:       http.StatusNotFound,
 			expectedRespContains: "task not found",
 			expectedCalled:       true,
 		},
+		{
+			name:   "has children",
+			pathID: "1",
+			usecaseFunc: func(ctx context.Context, id int, version int) error {
+				return usecase.ErrTaskHasChildren
+			},
+			expectedStatus:       http.StatusConflict,
+			expectedRespContains: "task has subtasks",
+			expectedCalled:       true,
+		},
+		{
+			name:                 "invalid If-Match",
+			pathID:               "1",
+			ifMatch:              `"abc"`,
+			usecaseFunc:          nil,
+			expectedStatus:       http.StatusBadRequest,
+			expectedRespContains: "invalid If-Match header",
+			expectedCalled:       false,
+		},
+		{
+			name:    "version conflict",
+			pathID:  "1",
+			ifMatch: `"2"`,
+			usecaseFunc: func(ctx context.Context, id int, version int) error {
+				if version != 2 {
+					return errors.New("unexpected version")
+				}
+
+				return usecase.ErrVersionConflict
+			},
+			expectedStatus:       http.StatusPreconditionFailed,
+			expectedRespContains: "task version conflict",
+			expectedCalled:       true,
+		},
+		{
+			name:           "If-Match list with current version",
+			pathID:         "1",
+			ifMatch:        `"2", "3"`,
+			currentVersion: 3,
+			usecaseFunc: func(ctx context.Context, id int, version int) error {
+				if version != 3 {
+					return errors.New("unexpected version")
+				}
+
+				return nil
+			},
+			expectedStatus: http.StatusOK,
+			expectedCalled: true,
+		},
+		{
+			name:           "If-Match list without current version",
+			pathID:         "1",
+			ifMatch:        `"1","2"`,
+			currentVersion: 3,
+			usecaseFunc: func(ctx context.Context, id int, version int) error {
+				if version != 1 {
+					return errors.New("unexpected version")
+				}
+
+				return usecase.ErrVersionConflict
+			},
+			expectedStatus:       http.StatusPreconditionFailed,
+			expectedRespContains: "task version conflict",
+			expectedCalled:       true,
+		},
+		{
+			name:                 "invalid If-Match list",
+			pathID:               "1",
+			ifMatch:              `"1", W/"2"`,
+			usecaseFunc:          nil,
+			expectedStatus:       http.StatusBadRequest,
+			expectedRespContains: "invalid If-Match header",
+			expectedCalled:       false,
+		},
+		{
+			name:   "without If-Match",
+			pathID: "1",
+			usecaseFunc: func(ctx context.Context, id int, version int) error {
+				if version != 0 {
+					return errors.New("unexpected version")
+				}
+
+				return nil
+			},
+			expectedStatus: http.StatusOK,
+			expectedCalled: true,
+		},
 		{
 			name:   "success",
 			pathID: "1",
-			usecaseFunc: func(ctx context.Context, id int) error {
+			usecaseFunc: func(ctx context.Context, id int, version int) error {
 				return nil
 			},
 			expectedStatus:       http.StatusOK,
@@ -69,12 +159,18 @@ func TestHandler_DeleteTask(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			mockUsecase := &mock.MockTaskUsecase{
 				DeleteTaskFunc: tt.usecaseFunc,
+				GetTaskByIDFunc: func(ctx context.Context, id int) (*model.Task, error) {
+					return &model.Task{ID: id, Version: tt.currentVersion}, nil
+				},
 			}
 
 			log := logger.NewMockLogger()
-			h := v1.NewHandler(mockUsecase, log)
+			h := v1.NewHandler(mockUsecase, &mock.MockProjectUsecase{}, &mock.MockCommentUsecase{}, &mock.MockBackupUsecase{}, &mock.MockReplicationUsecase{}, &mock.MockChangeUsecase{}, log)
 
 			req := httptest.NewRequest(http.MethodDelete, "/tasks/"+tt.pathID, nil)
+			if tt.ifMatch != "" {
+				req.Header.Set("If-Match", tt.ifMatch)
+			}
 			w := httptest.NewRecorder()
 
 			h.DeleteTask(ctx).ServeHTTP(w, req)
@@ -0,0 +1,300 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	v1 "github.com/solumD/tasks-service/internal/handler/v1"
+	"github.com/solumD/tasks-service/internal/handler/v1/mock"
+	"github.com/solumD/tasks-service/internal/model"
+	"github.com/solumD/tasks-service/internal/usecase"
+	"github.com/solumD/tasks-service/pkg/logger"
+)
+
+func TestAddDependency(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name              string
+		pathID            string
+		body              string
+		ifMatch           string
+		usecaseFunc       func(ctx context.Context, id int, dependsOn int, version int) (*model.Task, error)
+		expectedStatus    int
+		expectedBody      string
+		expectedETag      string
+		expectedCalled    bool
+		expectedDependsOn int
+		expectedVersion   int
+	}{
+		{
+			name:    "success",
+			pathID:  "3",
+			body:    `{"depends_on":1}`,
+			ifMatch: `"2"`,
+			usecaseFunc: func(ctx context.Context, id int, dependsOn int, version int) (*model.Task, error) {
+				return &model.Task{ID: id, Title: "A", Priority: model.PriorityNone, DependsOn: []int{dependsOn}, Blocked: true, Version: 3}, nil
+			},
+			expectedStatus:    http.StatusOK,
+			expectedBody:      `{"id":3,"title":"A","description":"","done":false,"status":"todo","priority":"none","depends_on":[1],"blocked":true,"version":3}`,
+			expectedETag:      `"3"`,
+			expectedCalled:    true,
+			expectedDependsOn: 1,
+			expectedVersion:   2,
+		},
+		{
+			name:           "invalid ID",
+			pathID:         "abc",
+			body:           `{"depends_on":1}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error_message":"invalid task id type"}`,
+		},
+		{
+			name:           "invalid body",
+			pathID:         "3",
+			body:           `{"depends_on":"one"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error_message":"failed to decode request"}`,
+		},
+		{
+			name:   "cycle",
+			pathID: "1",
+			body:   `{"depends_on":3}`,
+			usecaseFunc: func(ctx context.Context, id int, dependsOn int, version int) (*model.Task, error) {
+				return nil, usecase.ErrDependencyCycle
+			},
+			expectedStatus:    http.StatusBadRequest,
+			expectedBody:      `{"error_message":"task cannot depend on itself or on a task that depends on it"}`,
+			expectedCalled:    true,
+			expectedDependsOn: 3,
+		},
+		{
+			name:   "dependency not found",
+			pathID: "1",
+			body:   `{"depends_on":42}`,
+			usecaseFunc: func(ctx context.Context, id int, dependsOn int, version int) (*model.Task, error) {
+				return nil, usecase.ErrDependencyNotFound
+			},
+			expectedStatus:    http.StatusBadRequest,
+			expectedBody:      `{"error_message":"dependency task not found"}`,
+			expectedCalled:    true,
+			expectedDependsOn: 42,
+		},
+		{
+			name:   "task not found",
+			pathID: "42",
+			body:   `{"depends_on":1}`,
+			usecaseFunc: func(ctx context.Context, id int, dependsOn int, version int) (*model.Task, error) {
+				return nil, usecase.NewTaskNotFoundError(id)
+			},
+			expectedStatus:    http.StatusNotFound,
+			expectedBody:      `{"error_message":"task not found: id 42"}`,
+			expectedCalled:    true,
+			expectedDependsOn: 1,
+		},
+		{
+			name:    "version conflict",
+			pathID:  "3",
+			body:    `{"depends_on":1}`,
+			ifMatch: `"1"`,
+			usecaseFunc: func(ctx context.Context, id int, dependsOn int, version int) (*model.Task, error) {
+				return nil, usecase.ErrVersionConflict
+			},
+			expectedStatus:    http.StatusPreconditionFailed,
+			expectedBody:      `{"error_message":"task version conflict"}`,
+			expectedCalled:    true,
+			expectedDependsOn: 1,
+			expectedVersion:   1,
+		},
+		{
+			name:   "usecase error",
+			pathID: "3",
+			body:   `{"depends_on":1}`,
+			usecaseFunc: func(ctx context.Context, id int, dependsOn int, version int) (*model.Task, error) {
+				return nil, errors.New("db error")
+			},
+			expectedStatus:    http.StatusInternalServerError,
+			expectedBody:      `{"error_message":"failed to add task dependency"}`,
+			expectedCalled:    true,
+			expectedDependsOn: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUsecase := &mock.MockTaskUsecase{AddDependencyFunc: tt.usecaseFunc}
+			h := v1.NewHandler(mockUsecase, &mock.MockProjectUsecase{}, &mock.MockCommentUsecase{}, &mock.MockBackupUsecase{}, &mock.MockReplicationUsecase{}, &mock.MockChangeUsecase{}, logger.NewMockLogger())
+
+			req := httptest.NewRequest(http.MethodPost, "/todos/"+tt.pathID+"/dependencies", strings.NewReader(tt.body))
+			req.SetPathValue("id", tt.pathID)
+			if tt.ifMatch != "" {
+				req.Header.Set("If-Match", tt.ifMatch)
+			}
+			w := httptest.NewRecorder()
+
+			h.AddDependency(ctx).ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+
+			if strings.TrimSpace(w.Body.String()) != tt.expectedBody {
+				t.Fatalf("expected body %q, got %q", tt.expectedBody, w.Body.String())
+			}
+
+			if etag := w.Header().Get("ETag"); etag != tt.expectedETag {
+				t.Fatalf("expected ETag %q, got %q", tt.expectedETag, etag)
+			}
+
+			if mockUsecase.AddDependencyCalled != tt.expectedCalled {
+				t.Fatalf("expected usecase called = %v, got %v", tt.expectedCalled, mockUsecase.AddDependencyCalled)
+			}
+
+			if mockUsecase.AddDependencyDependsOn != tt.expectedDependsOn || mockUsecase.AddDependencyVersion != tt.expectedVersion {
+				t.Fatalf("expected dependency %d and version %d, got %d and %d", tt.expectedDependsOn, tt.expectedVersion,
+					mockUsecase.AddDependencyDependsOn, mockUsecase.AddDependencyVersion)
+			}
+		})
+	}
+}
+
+func TestRemoveDependency(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name           string
+		pathID         string
+		pathDep        string
+		usecaseFunc    func(ctx context.Context, id int, dependsOn int, version int) (*model.Task, error)
+		expectedStatus int
+		expectedBody   string
+		expectedCalled bool
+	}{
+		{
+			name:    "success",
+			pathID:  "3",
+			pathDep: "1",
+			usecaseFunc: func(ctx context.Context, id int, dependsOn int, version int) (*model.Task, error) {
+				return &model.Task{ID: id, Title: "A", Priority: model.PriorityNone, Version: 4}, nil
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"id":3,"title":"A","description":"","done":false,"status":"todo","priority":"none","version":4}`,
+			expectedCalled: true,
+		},
+		{
+			name:           "invalid dependency ID",
+			pathID:         "3",
+			pathDep:        "abc",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error_message":"invalid dependency id type"}`,
+		},
+		{
+			name:    "dependency not found",
+			pathID:  "3",
+			pathDep: "2",
+			usecaseFunc: func(ctx context.Context, id int, dependsOn int, version int) (*model.Task, error) {
+				return nil, usecase.ErrDependencyNotFound
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"error_message":"dependency task not found"}`,
+			expectedCalled: true,
+		},
+		{
+			name:    "usecase error",
+			pathID:  "3",
+			pathDep: "1",
+			usecaseFunc: func(ctx context.Context, id int, dependsOn int, version int) (*model.Task, error) {
+				return nil, errors.New("db error")
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"error_message":"failed to remove task dependency"}`,
+			expectedCalled: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUsecase := &mock.MockTaskUsecase{RemoveDependencyFunc: tt.usecaseFunc}
+			h := v1.NewHandler(mockUsecase, &mock.MockProjectUsecase{}, &mock.MockCommentUsecase{}, &mock.MockBackupUsecase{}, &mock.MockReplicationUsecase{}, &mock.MockChangeUsecase{}, logger.NewMockLogger())
+
+			req := httptest.NewRequest(http.MethodDelete, "/todos/"+tt.pathID+"/dependencies/"+tt.pathDep, nil)
+			req.SetPathValue("id", tt.pathID)
+			req.SetPathValue("dep", tt.pathDep)
+			w := httptest.NewRecorder()
+
+			h.RemoveDependency(ctx).ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+
+			if strings.TrimSpace(w.Body.String()) != tt.expectedBody {
+				t.Fatalf("expected body %q, got %q", tt.expectedBody, w.Body.String())
+			}
+
+			if mockUsecase.RemoveDependencyCalled != tt.expectedCalled {
+				t.Fatalf("expected usecase called = %v, got %v", tt.expectedCalled, mockUsecase.RemoveDependencyCalled)
+			}
+		})
+	}
+}
+
+func TestGetTasksInOrder(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name           string
+		usecaseFunc    func(ctx context.Context) ([]*model.Task, error)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "success",
+			usecaseFunc: func(ctx context.Context) ([]*model.Task, error) {
+				return []*model.Task{
+					{ID: 2, Title: "B", Priority: model.PriorityNone, Version: 1},
+					{ID: 1, Title: "A", Priority: model.PriorityNone, DependsOn: []int{2}, Blocked: true, Version: 1},
+				}, nil
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"todos":[{"id":2,"title":"B","description":"","done":false,"status":"todo","priority":"none","version":1},` +
+				`{"id":1,"title":"A","description":"","done":false,"status":"todo","priority":"none","depends_on":[2],"blocked":true,"version":1}]}`,
+		},
+		{
+			name: "usecase error",
+			usecaseFunc: func(ctx context.Context) ([]*model.Task, error) {
+				return nil, errors.New("db error")
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"error_message":"failed to get tasks in order"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUsecase := &mock.MockTaskUsecase{GetTasksInOrderFunc: tt.usecaseFunc}
+			h := v1.NewHandler(mockUsecase, &mock.MockProjectUsecase{}, &mock.MockCommentUsecase{}, &mock.MockBackupUsecase{}, &mock.MockReplicationUsecase{}, &mock.MockChangeUsecase{}, logger.NewMockLogger())
+
+			req := httptest.NewRequest(http.MethodGet, "/todos/order", nil)
+			w := httptest.NewRecorder()
+
+			h.GetTasksInOrder(ctx).ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+
+			if strings.TrimSpace(w.Body.String()) != tt.expectedBody {
+				t.Fatalf("expected body %q, got %q", tt.expectedBody, w.Body.String())
+			}
+
+			if !mockUsecase.GetTasksInOrderCalled {
+				t.Fatal("expected usecase to be called")
+			}
+		})
+	}
+}
@@ -5,28 +5,36 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"slices"
 	"strings"
 	"testing"
+	"time"
 
 	v1 "github.com/solumD/tasks-service/internal/handler/v1"
 	"github.com/solumD/tasks-service/internal/handler/v1/mock"
 	"github.com/solumD/tasks-service/internal/model"
+	"github.com/solumD/tasks-service/internal/usecase"
 	"github.com/solumD/tasks-service/pkg/logger"
 )
 
 func TestGetAllTasks(t *testing.T) {
 	ctx := context.Background()
 
+	dueAfter := time.Date(2026, time.March, 1, 0, 0, 0, 0, time.FixedZone("", 3*60*60))
+	dueBefore := time.Date(2026, time.April, 1, 0, 0, 0, 0, time.UTC)
+
 	tests := []struct {
 		name                 string
-		usecaseFunc          func(ctx context.Context) ([]*model.Task, error)
+		query                string
+		usecaseFunc          func(ctx context.Context, filter model.TaskFilter) ([]*model.Task, error)
 		expectedStatus       int
 		expectedRespContains string
 		expectedCalled       bool
+		expectedFilter       model.TaskFilter
 	}{
 		{
 			name: "repo error",
-			usecaseFunc: func(ctx context.Context) ([]*model.Task, error) {
+			usecaseFunc: func(ctx context.Context, filter model.TaskFilter) ([]*model.Task, error) {
 				return nil, errors.New("db error")
 			},
 			expectedStatus:       http.StatusInternalServerError,
@@ -35,7 +43,7 @@ func TestGetAllTasks(t *testing.T) {
 		},
 		{
 			name: "success",
-			usecaseFunc: func(ctx context.Context) ([]*model.Task, error) {
+			usecaseFunc: func(ctx context.Context, filter model.TaskFilter) ([]*model.Task, error) {
 				return []*model.Task{
 					{ID: 1, Title: "A"},
 					{ID: 2, Title: "B"},
@@ -45,6 +53,108 @@ func TestGetAllTasks(t *testing.T) {
 			expectedRespContains: `"title":"A"`,
 			expectedCalled:       true,
 		},
+		{
+			name:  "due range and overdue",
+			query: "?due_after=2026-03-01T00:00:00%2B03:00&due_before=2026-04-01T00:00:00Z&overdue=true",
+			usecaseFunc: func(ctx context.Context, filter model.TaskFilter) ([]*model.Task, error) {
+				due := time.Date(2026, time.March, 2, 9, 30, 0, 0, time.FixedZone("", 3*60*60))
+				return []*model.Task{{ID: 1, Title: "A", DueAt: &due}}, nil
+			},
+			expectedStatus:       http.StatusOK,
+			expectedRespContains: `"due_at":"2026-03-02T09:30:00+03:00"`,
+			expectedCalled:       true,
+			expectedFilter:       model.TaskFilter{DueAfter: dueAfter, DueBefore: dueBefore, Overdue: true},
+		},
+		{
+			name:  "sort by priority descending",
+			query: "?sort=priority&order=desc",
+			usecaseFunc: func(ctx context.Context, filter model.TaskFilter) ([]*model.Task, error) {
+				return []*model.Task{{ID: 1, Title: "A", Priority: model.PriorityUrgent}}, nil
+			},
+			expectedStatus:       http.StatusOK,
+			expectedRespContains: `"priority":"urgent"`,
+			expectedCalled:       true,
+			expectedFilter:       model.TaskFilter{SortBy: model.SortByPriority, Desc: true},
+		},
+		{
+			name:  "sort by id descending",
+			query: "?sort=id&order=desc",
+			usecaseFunc: func(ctx context.Context, filter model.TaskFilter) ([]*model.Task, error) {
+				return []*model.Task{{ID: 1, Title: "A"}}, nil
+			},
+			expectedStatus:       http.StatusOK,
+			expectedRespContains: `"id":1`,
+			expectedCalled:       true,
+			expectedFilter:       model.TaskFilter{SortBy: model.SortByID, Desc: true},
+		},
+		{
+			name:  "sort by created",
+			query: "?sort=created",
+			usecaseFunc: func(ctx context.Context, filter model.TaskFilter) ([]*model.Task, error) {
+				return []*model.Task{{ID: 1, Title: "A", CreatedAt: time.Date(2026, time.January, 10, 9, 0, 0, 0, time.UTC)}}, nil
+			},
+			expectedStatus:       http.StatusOK,
+			expectedRespContains: `"created_at":"2026-01-10T09:00:00Z"`,
+			expectedCalled:       true,
+			expectedFilter:       model.TaskFilter{SortBy: model.SortByCreated},
+		},
+		{
+			name:  "all of tags",
+			query: "?tags=home,work&match=all",
+			usecaseFunc: func(ctx context.Context, filter model.TaskFilter) ([]*model.Task, error) {
+				return []*model.Task{{ID: 1, Title: "A", Tags: []string{"home", "work"}}}, nil
+			},
+			expectedStatus:       http.StatusOK,
+			expectedRespContains: `"tags":["home","work"]`,
+			expectedCalled:       true,
+			expectedFilter:       model.TaskFilter{Tags: []string{"home", "work"}, AllTags: true},
+		},
+		{
+			name:  "invalid tag",
+			query: "?tags=home,",
+			usecaseFunc: func(ctx context.Context, filter model.TaskFilter) ([]*model.Task, error) {
+				return nil, usecase.ErrInvalidTag
+			},
+			expectedStatus:       http.StatusBadRequest,
+			expectedRespContains: usecase.ErrInvalidTag.Error(),
+			expectedCalled:       true,
+			expectedFilter:       model.TaskFilter{Tags: []string{"home", ""}},
+		},
+		{
+			name:                 "invalid match",
+			query:                "?tags=home&match=some",
+			expectedStatus:       http.StatusBadRequest,
+			expectedRespContains: "invalid tag match",
+			expectedCalled:       false,
+		},
+		{
+			name:                 "invalid sort",
+			query:                "?sort=version",
+			expectedStatus:       http.StatusBadRequest,
+			expectedRespContains: "invalid sort field",
+			expectedCalled:       false,
+		},
+		{
+			name:                 "invalid order",
+			query:                "?sort=title&order=up",
+			expectedStatus:       http.StatusBadRequest,
+			expectedRespContains: "invalid sort order",
+			expectedCalled:       false,
+		},
+		{
+			name:                 "invalid due date",
+			query:                "?due_before=tomorrow",
+			expectedStatus:       http.StatusBadRequest,
+			expectedRespContains: "invalid due date, expected RFC 3339",
+			expectedCalled:       false,
+		},
+		{
+			name:                 "invalid overdue",
+			query:                "?overdue=maybe",
+			expectedStatus:       http.StatusBadRequest,
+			expectedRespContains: "invalid overdue value",
+			expectedCalled:       false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -54,9 +164,9 @@ func TestGetAllTasks(t *testing.T) {
 			}
 
 			log := logger.NewMockLogger()
-			h := v1.NewHandler(mockUsecase, log)
+			h := v1.NewHandler(mockUsecase, &mock.MockProjectUsecase{}, &mock.MockCommentUsecase{}, &mock.MockBackupUsecase{}, &mock.MockReplicationUsecase{}, &mock.MockChangeUsecase{}, log)
 
-			req := httptest.NewRequest(http.MethodGet, "/tasks", nil)
+			req := httptest.NewRequest(http.MethodGet, "/tasks"+tt.query, nil)
 			w := httptest.NewRecorder()
 
 			h.GetAllTasks(ctx).ServeHTTP(w, req)
@@ -72,6 +182,13 @@ func TestGetAllTasks(t *testing.T) {
 			if mockUsecase.GetAllTasksCalled != tt.expectedCalled {
 				t.Fatalf("expected GetAllTasks called = %v, got %v", tt.expectedCalled, mockUsecase.GetAllTasksCalled)
 			}
+
+			got := mockUsecase.GetAllTasksFilter
+			if !got.DueAfter.Equal(tt.expectedFilter.DueAfter) || !got.DueBefore.Equal(tt.expectedFilter.DueBefore) ||
+				got.Overdue != tt.expectedFilter.Overdue || got.SortBy != tt.expectedFilter.SortBy || got.Desc != tt.expectedFilter.Desc ||
+				!slices.Equal(got.Tags, tt.expectedFilter.Tags) || got.AllTags != tt.expectedFilter.AllTags {
+				t.Fatalf("expected filter %+v, got %+v", tt.expectedFilter, got)
+			}
 		})
 	}
 }
@@ -24,6 +24,7 @@ func TestGetTaskByID(t *testing.T) {
 		usecaseFunc          func(ctx context.Context, id int) (*model.Task, error)
 		expectedStatus       int
 		expectedRespContains string
+		expectedETag         string
 		expectedCalled       bool
 	}{
 		{
@@ -58,9 +59,10 @@ func TestGetTaskByID(t *testing.T) {
 			name:   "success",
 			pathID: "1",
 			usecaseFunc: func(ctx context.Context, id int) (*model.Task, error) {
-				return &model.Task{ID: 1, Title: "Task1"}, nil
+				return &model.Task{ID: 1, Title: "Task1", Version: 3}, nil
 			},
 			expectedStatus:       http.StatusOK,
+			expectedETag:         `"3"`,
 			expectedRespContains: `"title":"Task1"`,
 			expectedCalled:       true,
 		},
@@ -73,7 +75,7 @@ func TestGetTaskByID(t *testing.T) {
 			}
 
 			log := logger.NewMockLogger()
-			h := v1.NewHandler(mockUsecase, log)
+			h := v1.NewHandler(mockUsecase, &mock.MockProjectUsecase{}, &mock.MockCommentUsecase{}, &mock.MockBackupUsecase{}, &mock.MockReplicationUsecase{}, &mock.MockChangeUsecase{}, log)
 
 			req := httptest.NewRequest(http.MethodGet, "/tasks/"+tt.pathID, nil)
 			w := httptest.NewRecorder()
@@ -88,6 +90,10 @@ func TestGetTaskByID(t *testing.T) {
 				t.Fatalf("expected body to contain %q, got %q", tt.expectedRespContains, w.Body.String())
 			}
 
+			if etag := w.Header().Get("ETag"); etag != tt.expectedETag {
+				t.Fatalf("expected ETag %q, got %q", tt.expectedETag, etag)
+			}
+
 			if mockUsecase.GetTaskByIDCalled != tt.expectedCalled {
 				t.Fatalf("expected GetTaskByID called = %v, got %v", tt.expectedCalled, mockUsecase.GetTaskByIDCalled)
 			}
@@ -0,0 +1,202 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	v1 "github.com/solumD/tasks-service/internal/handler/v1"
+	"github.com/solumD/tasks-service/internal/handler/v1/mock"
+	"github.com/solumD/tasks-service/internal/model"
+	"github.com/solumD/tasks-service/internal/usecase"
+	"github.com/solumD/tasks-service/pkg/logger"
+)
+
+func TestGetChildren(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name           string
+		pathID         string
+		usecaseFunc    func(ctx context.Context, id int) ([]*model.Task, error)
+		expectedStatus int
+		expectedBody   string
+		expectedCalled bool
+	}{
+		{
+			name:   "success",
+			pathID: "1",
+			usecaseFunc: func(ctx context.Context, id int) ([]*model.Task, error) {
+				return []*model.Task{{ID: 2, Title: "A", ParentID: id, Priority: model.PriorityNone, Version: 1}}, nil
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"todos":[{"id":2,"title":"A","description":"","done":false,"status":"todo","priority":"none","parent_id":1,"version":1}]}`,
+			expectedCalled: true,
+		},
+		{
+			name:           "invalid ID",
+			pathID:         "abc",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error_message":"invalid task id type"}`,
+		},
+		{
+			name:   "not found",
+			pathID: "1",
+			usecaseFunc: func(ctx context.Context, id int) ([]*model.Task, error) {
+				return nil, usecase.NewTaskNotFoundError(id)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"error_message":"task not found: id 1"}`,
+			expectedCalled: true,
+		},
+		{
+			name:   "usecase error",
+			pathID: "1",
+			usecaseFunc: func(ctx context.Context, id int) ([]*model.Task, error) {
+				return nil, errors.New("db error")
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"error_message":"failed to get task children"}`,
+			expectedCalled: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUsecase := &mock.MockTaskUsecase{GetChildrenFunc: tt.usecaseFunc}
+			h := v1.NewHandler(mockUsecase, &mock.MockProjectUsecase{}, &mock.MockCommentUsecase{}, &mock.MockBackupUsecase{}, &mock.MockReplicationUsecase{}, &mock.MockChangeUsecase{}, logger.NewMockLogger())
+
+			req := httptest.NewRequest(http.MethodGet, "/todos/"+tt.pathID+"/children", nil)
+			req.SetPathValue("id", tt.pathID)
+			w := httptest.NewRecorder()
+
+			h.GetChildren(ctx).ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+
+			if strings.TrimSpace(w.Body.String()) != tt.expectedBody {
+				t.Fatalf("expected body %q, got %q", tt.expectedBody, w.Body.String())
+			}
+
+			if mockUsecase.GetChildrenCalled != tt.expectedCalled {
+				t.Fatalf("expected usecase called = %v, got %v", tt.expectedCalled, mockUsecase.GetChildrenCalled)
+			}
+		})
+	}
+}
+
+func TestGetTaskTree(t *testing.T) {
+	ctx := context.Background()
+
+	tree := &model.TaskNode{
+		Task:        &model.Task{ID: 1, Title: "Root", Priority: model.PriorityNone, Version: 1},
+		HasChildren: true,
+		Children: []*model.TaskNode{
+			{Task: &model.Task{ID: 2, Title: "Child", Priority: model.PriorityNone, ParentID: 1, Version: 1}, HasChildren: true},
+		},
+	}
+
+	tests := []struct {
+		name           string
+		query          string
+		usecaseFunc    func(ctx context.Context, id int, depth int) (*model.TaskNode, error)
+		expectedStatus int
+		expectedBody   string
+		expectedDepth  int
+		expectedCalled bool
+	}{
+		{
+			name: "default depth",
+			usecaseFunc: func(ctx context.Context, id int, depth int) (*model.TaskNode, error) {
+				return tree, nil
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"id":1,"title":"Root","description":"","done":false,"status":"todo","priority":"none","version":1,"has_children":true,"children":[` +
+				`{"id":2,"title":"Child","description":"","done":false,"status":"todo","priority":"none","parent_id":1,"version":1,"has_children":true,"children":[]}]}`,
+			expectedDepth:  1,
+			expectedCalled: true,
+		},
+		{
+			name:  "explicit depth",
+			query: "?depth=3",
+			usecaseFunc: func(ctx context.Context, id int, depth int) (*model.TaskNode, error) {
+				return &model.TaskNode{Task: &model.Task{ID: id, Title: "Root", Priority: model.PriorityNone, Version: 1}}, nil
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"id":1,"title":"Root","description":"","done":false,"status":"todo","priority":"none","version":1,"has_children":false,"children":[]}`,
+			expectedDepth:  3,
+			expectedCalled: true,
+		},
+		{
+			name:           "invalid depth",
+			query:          "?depth=deep",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error_message":"invalid tree depth"}`,
+		},
+		{
+			name:  "depth out of range",
+			query: "?depth=100",
+			usecaseFunc: func(ctx context.Context, id int, depth int) (*model.TaskNode, error) {
+				return nil, usecase.ErrInvalidDepth
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error_message":"` + usecase.ErrInvalidDepth.Error() + `"}`,
+			expectedDepth:  100,
+			expectedCalled: true,
+		},
+		{
+			name: "not found",
+			usecaseFunc: func(ctx context.Context, id int, depth int) (*model.TaskNode, error) {
+				return nil, usecase.NewTaskNotFoundError(id)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"error_message":"task not found: id 1"}`,
+			expectedDepth:  1,
+			expectedCalled: true,
+		},
+		{
+			name: "usecase error",
+			usecaseFunc: func(ctx context.Context, id int, depth int) (*model.TaskNode, error) {
+				return nil, errors.New("db error")
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"error_message":"failed to get task tree"}`,
+			expectedDepth:  1,
+			expectedCalled: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUsecase := &mock.MockTaskUsecase{GetTaskTreeFunc: tt.usecaseFunc}
+			h := v1.NewHandler(mockUsecase, &mock.MockProjectUsecase{}, &mock.MockCommentUsecase{}, &mock.MockBackupUsecase{}, &mock.MockReplicationUsecase{}, &mock.MockChangeUsecase{}, logger.NewMockLogger())
+
+			req := httptest.NewRequest(http.MethodGet, "/todos/1/tree"+tt.query, nil)
+			req.SetPathValue("id", "1")
+			w := httptest.NewRecorder()
+
+			h.GetTaskTree(ctx).ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+
+			if strings.TrimSpace(w.Body.String()) != tt.expectedBody {
+				t.Fatalf("expected body %q, got %q", tt.expectedBody, w.Body.String())
+			}
+
+			if mockUsecase.GetTaskTreeCalled != tt.expectedCalled {
+				t.Fatalf("expected usecase called = %v, got %v", tt.expectedCalled, mockUsecase.GetTaskTreeCalled)
+			}
+
+			if tt.expectedCalled && mockUsecase.GetTaskTreeDepth != tt.expectedDepth {
+				t.Fatalf("expected depth %d, got %d", tt.expectedDepth, mockUsecase.GetTaskTreeDepth)
+			}
+		})
+	}
+}
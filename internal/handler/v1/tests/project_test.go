@@ -0,0 +1,460 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	v1 "github.com/solumD/tasks-service/internal/handler/v1"
+	"github.com/solumD/tasks-service/internal/handler/v1/mock"
+	"github.com/solumD/tasks-service/internal/model"
+	"github.com/solumD/tasks-service/internal/usecase"
+	"github.com/solumD/tasks-service/pkg/logger"
+)
+
+func TestCreateProject(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name           string
+		body           string
+		usecaseFunc    func(ctx context.Context, project *model.Project) (int, error)
+		expectedStatus int
+		expectedBody   string
+		expectedCalled bool
+	}{
+		{
+			name: "success",
+			body: `{"name":"Home","description":"chores"}`,
+			usecaseFunc: func(ctx context.Context, project *model.Project) (int, error) {
+				return 1, nil
+			},
+			expectedStatus: http.StatusCreated,
+			expectedBody:   `{"id":1}`,
+			expectedCalled: true,
+		},
+		{
+			name:           "invalid body",
+			body:           `{"name":1}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error_message":"failed to decode request"}`,
+		},
+		{
+			name: "empty name",
+			body: `{"name":""}`,
+			usecaseFunc: func(ctx context.Context, project *model.Project) (int, error) {
+				return 0, usecase.ErrEmptyProjectName
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error_message":"project name is empty"}`,
+			expectedCalled: true,
+		},
+		{
+			name: "projects disabled",
+			body: `{"name":"Home"}`,
+			usecaseFunc: func(ctx context.Context, project *model.Project) (int, error) {
+				return 0, usecase.ErrProjectsDisabled
+			},
+			expectedStatus: http.StatusNotImplemented,
+			expectedBody:   `{"error_message":"projects are not supported in raft and replication modes"}`,
+			expectedCalled: true,
+		},
+		{
+			name: "usecase error",
+			body: `{"name":"Home"}`,
+			usecaseFunc: func(ctx context.Context, project *model.Project) (int, error) {
+				return 0, errors.New("db error")
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"error_message":"failed to create project"}`,
+			expectedCalled: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUsecase := &mock.MockProjectUsecase{CreateProjectFunc: tt.usecaseFunc}
+			h := v1.NewHandler(&mock.MockTaskUsecase{}, mockUsecase, &mock.MockCommentUsecase{}, &mock.MockBackupUsecase{}, &mock.MockReplicationUsecase{}, &mock.MockChangeUsecase{}, logger.NewMockLogger())
+
+			req := httptest.NewRequest(http.MethodPost, "/projects", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+
+			h.CreateProject(ctx).ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+
+			if strings.TrimSpace(w.Body.String()) != tt.expectedBody {
+				t.Fatalf("expected body %q, got %q", tt.expectedBody, w.Body.String())
+			}
+
+			if mockUsecase.CreateProjectCalled != tt.expectedCalled {
+				t.Fatalf("expected usecase called = %v, got %v", tt.expectedCalled, mockUsecase.CreateProjectCalled)
+			}
+		})
+	}
+}
+
+func TestGetProject(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name           string
+		pathID         string
+		usecaseFunc    func(ctx context.Context, id int) (*model.Project, error)
+		expectedStatus int
+		expectedBody   string
+		expectedETag   string
+	}{
+		{
+			name:   "success",
+			pathID: "1",
+			usecaseFunc: func(ctx context.Context, id int) (*model.Project, error) {
+				return &model.Project{
+					ID:      id,
+					Name:    "Home",
+					Counts:  model.ProjectCounts{Total: 2, ByStatus: map[model.Status]int{model.StatusTodo: 2}},
+					Version: 3,
+				}, nil
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"id":1,"name":"Home","description":"","archived":false,"counts":{"total":2,"by_status":{"todo":2}},"version":3}`,
+			expectedETag:   `"3"`,
+		},
+		{
+			name:           "invalid ID",
+			pathID:         "abc",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error_message":"invalid project id type"}`,
+		},
+		{
+			name:   "not found",
+			pathID: "42",
+			usecaseFunc: func(ctx context.Context, id int) (*model.Project, error) {
+				return nil, usecase.NewProjectNotFoundError(id)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"error_message":"project not found: id 42"}`,
+		},
+		{
+			name:   "usecase error",
+			pathID: "1",
+			usecaseFunc: func(ctx context.Context, id int) (*model.Project, error) {
+				return nil, errors.New("db error")
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"error_message":"failed to get project"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUsecase := &mock.MockProjectUsecase{GetProjectFunc: tt.usecaseFunc}
+			h := v1.NewHandler(&mock.MockTaskUsecase{}, mockUsecase, &mock.MockCommentUsecase{}, &mock.MockBackupUsecase{}, &mock.MockReplicationUsecase{}, &mock.MockChangeUsecase{}, logger.NewMockLogger())
+
+			req := httptest.NewRequest(http.MethodGet, "/projects/"+tt.pathID, nil)
+			req.SetPathValue("pid", tt.pathID)
+			w := httptest.NewRecorder()
+
+			h.GetProject(ctx).ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+
+			if strings.TrimSpace(w.Body.String()) != tt.expectedBody {
+				t.Fatalf("expected body %q, got %q", tt.expectedBody, w.Body.String())
+			}
+
+			if etag := w.Header().Get("ETag"); etag != tt.expectedETag {
+				t.Fatalf("expected ETag %q, got %q", tt.expectedETag, etag)
+			}
+		})
+	}
+}
+
+func TestUpdateProject(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name             string
+		pathID           string
+		body             string
+		ifMatch          string
+		usecaseFunc      func(ctx context.Context, project *model.Project, archived bool) error
+		expectedStatus   int
+		expectedBody     string
+		expectedETag     string
+		expectedArchived bool
+	}{
+		{
+			name:    "archive",
+			pathID:  "1",
+			body:    `{"name":"Home","archived":true}`,
+			ifMatch: `"2"`,
+			usecaseFunc: func(ctx context.Context, project *model.Project, archived bool) error {
+				if project.Version != 2 {
+					return usecase.ErrProjectVersionConflict
+				}
+
+				project.Version = 3
+				return nil
+			},
+			expectedStatus:   http.StatusOK,
+			expectedETag:     `"3"`,
+			expectedArchived: true,
+		},
+		{
+			name:           "invalid ID",
+			pathID:         "abc",
+			body:           `{"name":"Home"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error_message":"invalid project id type"}`,
+		},
+		{
+			name:   "empty name",
+			pathID: "1",
+			body:   `{"name":""}`,
+			usecaseFunc: func(ctx context.Context, project *model.Project, archived bool) error {
+				return usecase.ErrEmptyProjectName
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error_message":"project name is empty"}`,
+		},
+		{
+			name:   "not found",
+			pathID: "42",
+			body:   `{"name":"Home"}`,
+			usecaseFunc: func(ctx context.Context, project *model.Project, archived bool) error {
+				return usecase.NewProjectNotFoundError(project.ID)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"error_message":"project not found: id 42"}`,
+		},
+		{
+			name:    "version conflict",
+			pathID:  "1",
+			body:    `{"name":"Home"}`,
+			ifMatch: `"1"`,
+			usecaseFunc: func(ctx context.Context, project *model.Project, archived bool) error {
+				return usecase.ErrProjectVersionConflict
+			},
+			expectedStatus: http.StatusPreconditionFailed,
+			expectedBody:   `{"error_message":"project version conflict"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUsecase := &mock.MockProjectUsecase{UpdateProjectFunc: tt.usecaseFunc}
+			h := v1.NewHandler(&mock.MockTaskUsecase{}, mockUsecase, &mock.MockCommentUsecase{}, &mock.MockBackupUsecase{}, &mock.MockReplicationUsecase{}, &mock.MockChangeUsecase{}, logger.NewMockLogger())
+
+			req := httptest.NewRequest(http.MethodPut, "/projects/"+tt.pathID, strings.NewReader(tt.body))
+			req.SetPathValue("pid", tt.pathID)
+			if tt.ifMatch != "" {
+				req.Header.Set("If-Match", tt.ifMatch)
+			}
+			w := httptest.NewRecorder()
+
+			h.UpdateProject(ctx).ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+
+			if strings.TrimSpace(w.Body.String()) != tt.expectedBody {
+				t.Fatalf("expected body %q, got %q", tt.expectedBody, w.Body.String())
+			}
+
+			if etag := w.Header().Get("ETag"); etag != tt.expectedETag {
+				t.Fatalf("expected ETag %q, got %q", tt.expectedETag, etag)
+			}
+
+			if mockUsecase.UpdateProjectArchived != tt.expectedArchived {
+				t.Fatalf("expected archived %v, got %v", tt.expectedArchived, mockUsecase.UpdateProjectArchived)
+			}
+		})
+	}
+}
+
+func TestDeleteProject(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name           string
+		pathID         string
+		usecaseFunc    func(ctx context.Context, id int, version int) error
+		expectedStatus int
+		expectedBody   string
+		expectedCalled bool
+	}{
+		{
+			name:           "success",
+			pathID:         "1",
+			expectedStatus: http.StatusOK,
+			expectedCalled: true,
+		},
+		{
+			name:           "invalid ID",
+			pathID:         "abc",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error_message":"invalid project id type"}`,
+		},
+		{
+			name:   "not found",
+			pathID: "42",
+			usecaseFunc: func(ctx context.Context, id int, version int) error {
+				return usecase.NewProjectNotFoundError(id)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"error_message":"project not found: id 42"}`,
+			expectedCalled: true,
+		},
+		{
+			name:   "has tasks",
+			pathID: "1",
+			usecaseFunc: func(ctx context.Context, id int, version int) error {
+				return usecase.ErrProjectHasTasks
+			},
+			expectedStatus: http.StatusConflict,
+			expectedBody:   `{"error_message":"project has tasks"}`,
+			expectedCalled: true,
+		},
+		{
+			name:   "usecase error",
+			pathID: "1",
+			usecaseFunc: func(ctx context.Context, id int, version int) error {
+				return errors.New("db error")
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"error_message":"failed to delete project"}`,
+			expectedCalled: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUsecase := &mock.MockProjectUsecase{DeleteProjectFunc: tt.usecaseFunc}
+			h := v1.NewHandler(&mock.MockTaskUsecase{}, mockUsecase, &mock.MockCommentUsecase{}, &mock.MockBackupUsecase{}, &mock.MockReplicationUsecase{}, &mock.MockChangeUsecase{}, logger.NewMockLogger())
+
+			req := httptest.NewRequest(http.MethodDelete, "/projects/"+tt.pathID, nil)
+			req.SetPathValue("pid", tt.pathID)
+			w := httptest.NewRecorder()
+
+			h.DeleteProject(ctx).ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+
+			if strings.TrimSpace(w.Body.String()) != tt.expectedBody {
+				t.Fatalf("expected body %q, got %q", tt.expectedBody, w.Body.String())
+			}
+
+			if mockUsecase.DeleteProjectCalled != tt.expectedCalled {
+				t.Fatalf("expected usecase called = %v, got %v", tt.expectedCalled, mockUsecase.DeleteProjectCalled)
+			}
+		})
+	}
+}
+
+func TestMoveTask(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name              string
+		pathID            string
+		body              string
+		usecaseFunc       func(ctx context.Context, id int, projectID int, version int) (*model.Task, error)
+		expectedStatus    int
+		expectedBody      string
+		expectedETag      string
+		expectedProjectID int
+	}{
+		{
+			name:   "success",
+			pathID: "3",
+			body:   `{"project_id":2}`,
+			usecaseFunc: func(ctx context.Context, id int, projectID int, version int) (*model.Task, error) {
+				return &model.Task{ID: id, Title: "A", ProjectID: projectID, Priority: model.PriorityNone, Version: 4}, nil
+			},
+			expectedStatus:    http.StatusOK,
+			expectedBody:      `{"id":3,"title":"A","description":"","done":false,"status":"todo","priority":"none","project_id":2,"version":4}`,
+			expectedETag:      `"4"`,
+			expectedProjectID: 2,
+		},
+		{
+			name:           "invalid ID",
+			pathID:         "abc",
+			body:           `{"project_id":2}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error_message":"invalid task id type"}`,
+		},
+		{
+			name:   "project not found",
+			pathID: "3",
+			body:   `{"project_id":42}`,
+			usecaseFunc: func(ctx context.Context, id int, projectID int, version int) (*model.Task, error) {
+				return nil, usecase.NewProjectNotFoundError(projectID)
+			},
+			expectedStatus:    http.StatusBadRequest,
+			expectedBody:      `{"error_message":"project not found: id 42"}`,
+			expectedProjectID: 42,
+		},
+		{
+			name:   "task not found",
+			pathID: "42",
+			body:   `{"project_id":2}`,
+			usecaseFunc: func(ctx context.Context, id int, projectID int, version int) (*model.Task, error) {
+				return nil, usecase.NewTaskNotFoundError(id)
+			},
+			expectedStatus:    http.StatusNotFound,
+			expectedBody:      `{"error_message":"task not found: id 42"}`,
+			expectedProjectID: 2,
+		},
+		{
+			name:   "archived project",
+			pathID: "3",
+			body:   `{"project_id":2}`,
+			usecaseFunc: func(ctx context.Context, id int, projectID int, version int) (*model.Task, error) {
+				return nil, usecase.ErrProjectArchived
+			},
+			expectedStatus:    http.StatusConflict,
+			expectedBody:      `{"error_message":"project is archived"}`,
+			expectedProjectID: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUsecase := &mock.MockProjectUsecase{MoveTaskFunc: tt.usecaseFunc}
+			h := v1.NewHandler(&mock.MockTaskUsecase{}, mockUsecase, &mock.MockCommentUsecase{}, &mock.MockBackupUsecase{}, &mock.MockReplicationUsecase{}, &mock.MockChangeUsecase{}, logger.NewMockLogger())
+
+			req := httptest.NewRequest(http.MethodPost, "/todos/"+tt.pathID+"/move", strings.NewReader(tt.body))
+			req.SetPathValue("id", tt.pathID)
+			w := httptest.NewRecorder()
+
+			h.MoveTask(ctx).ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+
+			if strings.TrimSpace(w.Body.String()) != tt.expectedBody {
+				t.Fatalf("expected body %q, got %q", tt.expectedBody, w.Body.String())
+			}
+
+			if etag := w.Header().Get("ETag"); etag != tt.expectedETag {
+				t.Fatalf("expected ETag %q, got %q", tt.expectedETag, etag)
+			}
+
+			if mockUsecase.MoveTaskProjectID != tt.expectedProjectID {
+				t.Fatalf("expected project id %d, got %d", tt.expectedProjectID, mockUsecase.MoveTaskProjectID)
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	v1 "github.com/solumD/tasks-service/internal/handler/v1"
+	"github.com/solumD/tasks-service/internal/handler/v1/mock"
+	"github.com/solumD/tasks-service/internal/usecase"
+	"github.com/solumD/tasks-service/pkg/logger"
+)
+
+func TestGetOccurrences(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name           string
+		pathID         string
+		query          string
+		usecaseFunc    func(ctx context.Context, id int, count int) ([]time.Time, error)
+		expectedStatus int
+		expectedBody   string
+		expectedCalled bool
+		expectedCount  int
+	}{
+		{
+			name:   "default count",
+			pathID: "1",
+			usecaseFunc: func(ctx context.Context, id int, count int) ([]time.Time, error) {
+				return []time.Time{
+					time.Date(2026, time.March, 9, 18, 0, 0, 0, time.FixedZone("", 3*60*60)),
+					time.Date(2026, time.March, 13, 18, 0, 0, 0, time.FixedZone("", 3*60*60)),
+				}, nil
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"occurrences":["2026-03-09T18:00:00+03:00","2026-03-13T18:00:00+03:00"]}`,
+			expectedCalled: true,
+			expectedCount:  5,
+		},
+		{
+			name:   "not recurring",
+			pathID: "1",
+			query:  "?count=3",
+			usecaseFunc: func(ctx context.Context, id int, count int) ([]time.Time, error) {
+				return []time.Time{}, nil
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"occurrences":[]}`,
+			expectedCalled: true,
+			expectedCount:  3,
+		},
+		{
+			name:           "invalid ID",
+			pathID:         "abc",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error_message":"invalid task id type"}`,
+		},
+		{
+			name:           "invalid count",
+			pathID:         "1",
+			query:          "?count=many",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error_message":"invalid occurrences count"}`,
+		},
+		{
+			name:   "count out of range",
+			pathID: "1",
+			query:  "?count=0",
+			usecaseFunc: func(ctx context.Context, id int, count int) ([]time.Time, error) {
+				return nil, usecase.ErrInvalidOccurrences
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error_message":"occurrences count must be from 1 to 100"}`,
+			expectedCalled: true,
+		},
+		{
+			name:   "task not found",
+			pathID: "42",
+			usecaseFunc: func(ctx context.Context, id int, count int) ([]time.Time, error) {
+				return nil, usecase.NewTaskNotFoundError(id)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"error_message":"task not found: id 42"}`,
+			expectedCalled: true,
+			expectedCount:  5,
+		},
+		{
+			name:   "usecase error",
+			pathID: "1",
+			usecaseFunc: func(ctx context.Context, id int, count int) ([]time.Time, error) {
+				return nil, errors.New("db error")
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"error_message":"failed to get task occurrences"}`,
+			expectedCalled: true,
+			expectedCount:  5,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUsecase := &mock.MockTaskUsecase{GetOccurrencesFunc: tt.usecaseFunc}
+			h := v1.NewHandler(mockUsecase, &mock.MockProjectUsecase{}, &mock.MockCommentUsecase{}, &mock.MockBackupUsecase{}, &mock.MockReplicationUsecase{}, &mock.MockChangeUsecase{}, logger.NewMockLogger())
+
+			req := httptest.NewRequest(http.MethodGet, "/todos/"+tt.pathID+"/occurrences"+tt.query, nil)
+			req.SetPathValue("id", tt.pathID)
+			w := httptest.NewRecorder()
+
+			h.GetOccurrences(ctx).ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+
+			if strings.TrimSpace(w.Body.String()) != tt.expectedBody {
+				t.Fatalf("expected body %q, got %q", tt.expectedBody, w.Body.String())
+			}
+
+			if mockUsecase.GetOccurrencesCalled != tt.expectedCalled {
+				t.Fatalf("expected usecase called = %v, got %v", tt.expectedCalled, mockUsecase.GetOccurrencesCalled)
+			}
+
+			if tt.expectedCount != 0 && mockUsecase.GetOccurrencesCount != tt.expectedCount {
+				t.Fatalf("expected count %d, got %d", tt.expectedCount, mockUsecase.GetOccurrencesCount)
+			}
+		})
+	}
+}
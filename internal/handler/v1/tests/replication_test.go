@@ -0,0 +1,224 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	v1 "github.com/solumD/tasks-service/internal/handler/v1"
+	"github.com/solumD/tasks-service/internal/handler/v1/mock"
+	"github.com/solumD/tasks-service/internal/model"
+	"github.com/solumD/tasks-service/internal/replication"
+	"github.com/solumD/tasks-service/pkg/logger"
+)
+
+func TestHandler_GetReplicationLog(t *testing.T) {
+	ctx := context.Background()
+	deletedBefore := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name                 string
+		query                string
+		usecaseFunc          func(ctx context.Context, followerID string, from int, wait time.Duration) (*model.LogBatch, error)
+		expectedStatus       int
+		expectedRespContains string
+		expectedCalled       bool
+		expectedWait         time.Duration
+	}{
+		{
+			name:                 "missing position",
+			query:                "",
+			expectedStatus:       http.StatusBadRequest,
+			expectedRespContains: "invalid replication log position",
+		},
+		{
+			name:                 "negative position",
+			query:                "from=-1",
+			expectedStatus:       http.StatusBadRequest,
+			expectedRespContains: "invalid replication log position",
+		},
+		{
+			name:                 "invalid wait",
+			query:                "from=0&wait=soon",
+			expectedStatus:       http.StatusBadRequest,
+			expectedRespContains: "invalid wait duration",
+		},
+		{
+			name:  "not leader",
+			query: "from=0",
+			usecaseFunc: func(ctx context.Context, followerID string, from int, wait time.Duration) (*model.LogBatch, error) {
+				return nil, replication.ErrNotLeader
+			},
+			expectedStatus:       http.StatusConflict,
+			expectedRespContains: replication.ErrNotLeader.Error(),
+			expectedCalled:       true,
+		},
+		{
+			name:  "truncated",
+			query: "from=0",
+			usecaseFunc: func(ctx context.Context, followerID string, from int, wait time.Duration) (*model.LogBatch, error) {
+				return nil, replication.ErrLogTruncated
+			},
+			expectedStatus:       http.StatusGone,
+			expectedRespContains: replication.ErrLogTruncated.Error(),
+			expectedCalled:       true,
+		},
+		{
+			name:  "usecase error",
+			query: "from=0",
+			usecaseFunc: func(ctx context.Context, followerID string, from int, wait time.Duration) (*model.LogBatch, error) {
+				return nil, errors.New("boom")
+			},
+			expectedStatus:       http.StatusInternalServerError,
+			expectedRespContains: v1.ErrFailedToReadLog.Error(),
+			expectedCalled:       true,
+		},
+		{
+			name:  "success",
+			query: "from=3&wait=2s&follower=f1",
+			usecaseFunc: func(ctx context.Context, followerID string, from int, wait time.Duration) (*model.LogBatch, error) {
+				return &model.LogBatch{
+					LeaderID: "l1",
+					Head:     4,
+					Entries: []*model.LogEntry{
+						{Seq: 4, Op: model.LogOpPurge, DeletedBefore: deletedBefore},
+					},
+				}, nil
+			},
+			expectedStatus:       http.StatusOK,
+			expectedRespContains: `"deleted_before":"2024-05-01T12:00:00Z"`,
+			expectedCalled:       true,
+			expectedWait:         2 * time.Second,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockReplication := &mock.MockReplicationUsecase{
+				ReadLogFunc: tt.usecaseFunc,
+			}
+
+			log := logger.NewMockLogger()
+			h := v1.NewHandler(&mock.MockTaskUsecase{}, &mock.MockProjectUsecase{}, &mock.MockCommentUsecase{}, &mock.MockBackupUsecase{}, mockReplication, &mock.MockChangeUsecase{}, log)
+
+			req := httptest.NewRequest(http.MethodGet, "/replication/log?"+tt.query, nil)
+			w := httptest.NewRecorder()
+
+			h.GetReplicationLog(ctx).ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+
+			if !strings.Contains(w.Body.String(), tt.expectedRespContains) {
+				t.Fatalf("expected body to contain %q, got %q", tt.expectedRespContains, w.Body.String())
+			}
+
+			if mockReplication.ReadLogCalled != tt.expectedCalled {
+				t.Fatalf("expected ReadLog called = %v, got %v", tt.expectedCalled, mockReplication.ReadLogCalled)
+			}
+
+			if mockReplication.ReadLogWait != tt.expectedWait {
+				t.Fatalf("expected wait %v, got %v", tt.expectedWait, mockReplication.ReadLogWait)
+			}
+		})
+	}
+}
+
+func TestHandler_GetReplicationSnapshot(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name                 string
+		usecaseFunc          func(ctx context.Context) (*model.ReplicationSnapshot, error)
+		expectedStatus       int
+		expectedRespContains string
+	}{
+		{
+			name: "not leader",
+			usecaseFunc: func(ctx context.Context) (*model.ReplicationSnapshot, error) {
+				return nil, replication.ErrNotLeader
+			},
+			expectedStatus:       http.StatusConflict,
+			expectedRespContains: replication.ErrNotLeader.Error(),
+		},
+		{
+			name: "store error",
+			usecaseFunc: func(ctx context.Context) (*model.ReplicationSnapshot, error) {
+				return nil, errors.New("db error")
+			},
+			expectedStatus:       http.StatusInternalServerError,
+			expectedRespContains: v1.ErrFailedToGetSnapshot.Error(),
+		},
+		{
+			name: "success",
+			usecaseFunc: func(ctx context.Context) (*model.ReplicationSnapshot, error) {
+				return &model.ReplicationSnapshot{LeaderID: "l1", Seq: 7, NextID: 3, Tasks: []*model.Task{{ID: 2, Title: "A", Version: 1}}}, nil
+			},
+			expectedStatus:       http.StatusOK,
+			expectedRespContains: `"seq":7,"next_id":3`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockReplication := &mock.MockReplicationUsecase{
+				SnapshotFunc: tt.usecaseFunc,
+			}
+
+			log := logger.NewMockLogger()
+			h := v1.NewHandler(&mock.MockTaskUsecase{}, &mock.MockProjectUsecase{}, &mock.MockCommentUsecase{}, &mock.MockBackupUsecase{}, mockReplication, &mock.MockChangeUsecase{}, log)
+
+			req := httptest.NewRequest(http.MethodGet, "/replication/snapshot", nil)
+			w := httptest.NewRecorder()
+
+			h.GetReplicationSnapshot(ctx).ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+
+			if !strings.Contains(w.Body.String(), tt.expectedRespContains) {
+				t.Fatalf("expected body to contain %q, got %q", tt.expectedRespContains, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandler_GetReplicationStatus(t *testing.T) {
+	ctx := context.Background()
+
+	mockReplication := &mock.MockReplicationUsecase{
+		StatusFunc: func() *model.ReplicationStatus {
+			return &model.ReplicationStatus{
+				Role:    model.ReplicationFollower,
+				NodeID:  "f1",
+				Applied: 5,
+				Head:    8,
+				Lag:     1500 * time.Millisecond,
+			}
+		},
+	}
+
+	log := logger.NewMockLogger()
+	h := v1.NewHandler(&mock.MockTaskUsecase{}, &mock.MockProjectUsecase{}, &mock.MockCommentUsecase{}, &mock.MockBackupUsecase{}, mockReplication, &mock.MockChangeUsecase{}, log)
+
+	req := httptest.NewRequest(http.MethodGet, "/replication/status", nil)
+	w := httptest.NewRecorder()
+
+	h.GetReplicationStatus(ctx).ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, w.Code)
+	}
+
+	for _, expected := range []string{`"role":"follower"`, `"lag_entries":3`, `"lag_seconds":1.5`} {
+		if !strings.Contains(w.Body.String(), expected) {
+			t.Fatalf("expected body to contain %q, got %q", expected, w.Body.String())
+		}
+	}
+}
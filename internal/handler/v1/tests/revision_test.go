@@ -0,0 +1,288 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	v1 "github.com/solumD/tasks-service/internal/handler/v1"
+	"github.com/solumD/tasks-service/internal/handler/v1/mock"
+	"github.com/solumD/tasks-service/internal/model"
+	"github.com/solumD/tasks-service/internal/usecase"
+	"github.com/solumD/tasks-service/pkg/logger"
+)
+
+func TestHandler_GetTaskHistory(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name                 string
+		pathID               string
+		usecaseFunc          func(ctx context.Context, id int) ([]*model.Revision, error)
+		expectedStatus       int
+		expectedRespContains string
+	}{
+		{
+			name:                 "invalid ID",
+			pathID:               "abc",
+			expectedStatus:       http.StatusBadRequest,
+			expectedRespContains: "invalid task id type",
+		},
+		{
+			name:   "not found",
+			pathID: "1",
+			usecaseFunc: func(ctx context.Context, id int) ([]*model.Revision, error) {
+				return nil, usecase.NewTaskNotFoundError(id)
+			},
+			expectedStatus:       http.StatusNotFound,
+			expectedRespContains: "task not found",
+		},
+		{
+			name:   "repo error",
+			pathID: "1",
+			usecaseFunc: func(ctx context.Context, id int) ([]*model.Revision, error) {
+				return nil, errors.New("db error")
+			},
+			expectedStatus:       http.StatusInternalServerError,
+			expectedRespContains: "failed to get task history",
+		},
+		{
+			name:   "success",
+			pathID: "1",
+			usecaseFunc: func(ctx context.Context, id int) ([]*model.Revision, error) {
+				return []*model.Revision{
+					{TaskID: id, Rev: 1, Action: model.RevisionCreated, Author: "alice", Task: model.Task{ID: id, Title: "A", Version: 1}},
+				}, nil
+			},
+			expectedStatus:       http.StatusOK,
+			expectedRespContains: `"action":"created","author":"alice"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUsecase := &mock.MockTaskUsecase{
+				GetTaskHistoryFunc: tt.usecaseFunc,
+			}
+
+			h := v1.NewHandler(mockUsecase, &mock.MockProjectUsecase{}, &mock.MockCommentUsecase{}, &mock.MockBackupUsecase{}, &mock.MockReplicationUsecase{}, &mock.MockChangeUsecase{}, logger.NewMockLogger())
+
+			req := httptest.NewRequest(http.MethodGet, "/todos/"+tt.pathID+"/history", nil)
+			req.SetPathValue("id", tt.pathID)
+			w := httptest.NewRecorder()
+
+			h.GetTaskHistory(ctx).ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+
+			if !strings.Contains(w.Body.String(), tt.expectedRespContains) {
+				t.Fatalf("expected body to contain %q, got %q", tt.expectedRespContains, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandler_GetTaskRevision(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name                 string
+		pathRev              string
+		usecaseFunc          func(ctx context.Context, id int, rev int) (*model.Revision, error)
+		expectedStatus       int
+		expectedRespContains string
+	}{
+		{
+			name:                 "invalid revision",
+			pathRev:              "0",
+			expectedStatus:       http.StatusBadRequest,
+			expectedRespContains: "invalid revision",
+		},
+		{
+			name:    "not found",
+			pathRev: "5",
+			usecaseFunc: func(ctx context.Context, id int, rev int) (*model.Revision, error) {
+				return nil, usecase.ErrRevisionNotFound
+			},
+			expectedStatus:       http.StatusNotFound,
+			expectedRespContains: "task revision not found",
+		},
+		{
+			name:    "success",
+			pathRev: "2",
+			usecaseFunc: func(ctx context.Context, id int, rev int) (*model.Revision, error) {
+				return &model.Revision{TaskID: id, Rev: rev, Action: model.RevisionUpdated, Task: model.Task{ID: id, Title: "B"}}, nil
+			},
+			expectedStatus:       http.StatusOK,
+			expectedRespContains: `"rev":2`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUsecase := &mock.MockTaskUsecase{
+				GetTaskRevisionFunc: tt.usecaseFunc,
+			}
+
+			h := v1.NewHandler(mockUsecase, &mock.MockProjectUsecase{}, &mock.MockCommentUsecase{}, &mock.MockBackupUsecase{}, &mock.MockReplicationUsecase{}, &mock.MockChangeUsecase{}, logger.NewMockLogger())
+
+			req := httptest.NewRequest(http.MethodGet, "/todos/1/history/"+tt.pathRev, nil)
+			req.SetPathValue("id", "1")
+			req.SetPathValue("rev", tt.pathRev)
+			w := httptest.NewRecorder()
+
+			h.GetTaskRevision(ctx).ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+
+			if !strings.Contains(w.Body.String(), tt.expectedRespContains) {
+				t.Fatalf("expected body to contain %q, got %q", tt.expectedRespContains, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandler_DiffTaskRevisions(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name                 string
+		query                string
+		usecaseFunc          func(ctx context.Context, id int, from int, to int) ([]model.FieldChange, error)
+		expectedStatus       int
+		expectedRespContains string
+	}{
+		{
+			name:                 "missing to",
+			query:                "?from=1",
+			expectedStatus:       http.StatusBadRequest,
+			expectedRespContains: "invalid revision",
+		},
+		{
+			name:  "not found",
+			query: "?from=1&to=9",
+			usecaseFunc: func(ctx context.Context, id int, from int, to int) ([]model.FieldChange, error) {
+				return nil, usecase.ErrRevisionNotFound
+			},
+			expectedStatus:       http.StatusNotFound,
+			expectedRespContains: "task revision not found",
+		},
+		{
+			name:  "success",
+			query: "?from=1&to=2",
+			usecaseFunc: func(ctx context.Context, id int, from int, to int) ([]model.FieldChange, error) {
+				return []model.FieldChange{{Field: "title", From: "A", To: "B"}}, nil
+			},
+			expectedStatus:       http.StatusOK,
+			expectedRespContains: `{"field":"title","from":"A","to":"B"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUsecase := &mock.MockTaskUsecase{
+				DiffTaskRevisionsFunc: tt.usecaseFunc,
+			}
+
+			h := v1.NewHandler(mockUsecase, &mock.MockProjectUsecase{}, &mock.MockCommentUsecase{}, &mock.MockBackupUsecase{}, &mock.MockReplicationUsecase{}, &mock.MockChangeUsecase{}, logger.NewMockLogger())
+
+			req := httptest.NewRequest(http.MethodGet, "/todos/1/diff"+tt.query, nil)
+			req.SetPathValue("id", "1")
+			w := httptest.NewRecorder()
+
+			h.DiffTaskRevisions(ctx).ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+
+			if !strings.Contains(w.Body.String(), tt.expectedRespContains) {
+				t.Fatalf("expected body to contain %q, got %q", tt.expectedRespContains, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandler_RevertTask(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name                 string
+		ifMatch              string
+		usecaseFunc          func(ctx context.Context, id int, rev int, version int) (*model.Task, error)
+		expectedStatus       int
+		expectedRespContains string
+		expectedETag         string
+	}{
+		{
+			name:    "version conflict",
+			ifMatch: `"2"`,
+			usecaseFunc: func(ctx context.Context, id int, rev int, version int) (*model.Task, error) {
+				return nil, usecase.ErrVersionConflict
+			},
+			expectedStatus:       http.StatusPreconditionFailed,
+			expectedRespContains: "task version conflict",
+		},
+		{
+			name: "revision not found",
+			usecaseFunc: func(ctx context.Context, id int, rev int, version int) (*model.Task, error) {
+				return nil, usecase.ErrRevisionNotFound
+			},
+			expectedStatus:       http.StatusNotFound,
+			expectedRespContains: "task revision not found",
+		},
+		{
+			name: "success",
+			usecaseFunc: func(ctx context.Context, id int, rev int, version int) (*model.Task, error) {
+				return &model.Task{ID: id, Title: "A", Version: 5}, nil
+			},
+			expectedStatus:       http.StatusOK,
+			expectedRespContains: `"title":"A"`,
+			expectedETag:         `"5"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUsecase := &mock.MockTaskUsecase{
+				RevertTaskFunc: tt.usecaseFunc,
+			}
+
+			h := v1.NewHandler(mockUsecase, &mock.MockProjectUsecase{}, &mock.MockCommentUsecase{}, &mock.MockBackupUsecase{}, &mock.MockReplicationUsecase{}, &mock.MockChangeUsecase{}, logger.NewMockLogger())
+
+			req := httptest.NewRequest(http.MethodPost, "/todos/1/history/1/revert", nil)
+			req.SetPathValue("id", "1")
+			req.SetPathValue("rev", "1")
+			req.Header.Set("X-Author", "bob")
+			if tt.ifMatch != "" {
+				req.Header.Set("If-Match", tt.ifMatch)
+			}
+			w := httptest.NewRecorder()
+
+			h.RevertTask(ctx).ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+
+			if !strings.Contains(w.Body.String(), tt.expectedRespContains) {
+				t.Fatalf("expected body to contain %q, got %q", tt.expectedRespContains, w.Body.String())
+			}
+
+			if etag := w.Header().Get("ETag"); etag != tt.expectedETag {
+				t.Fatalf("expected ETag %q, got %q", tt.expectedETag, etag)
+			}
+
+			if author := usecase.AuthorFromContext(mockUsecase.RevertTaskCtx); author != "bob" {
+				t.Fatalf("expected author bob in context, got %q", author)
+			}
+		})
+	}
+}
@@ -0,0 +1,176 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	v1 "github.com/solumD/tasks-service/internal/handler/v1"
+	"github.com/solumD/tasks-service/internal/handler/v1/mock"
+	"github.com/solumD/tasks-service/internal/model"
+	"github.com/solumD/tasks-service/internal/usecase"
+	"github.com/solumD/tasks-service/pkg/logger"
+)
+
+func TestTransitionTask(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name            string
+		pathID          string
+		body            string
+		ifMatch         string
+		usecaseFunc     func(ctx context.Context, id int, to model.Status, version int) (*model.Task, error)
+		expectedStatus  int
+		expectedBody    string
+		expectedETag    string
+		expectedCalled  bool
+		expectedTo      model.Status
+		expectedVersion int
+	}{
+		{
+			name:    "success",
+			pathID:  "3",
+			body:    `{"to":"in_progress"}`,
+			ifMatch: `"2"`,
+			usecaseFunc: func(ctx context.Context, id int, to model.Status, version int) (*model.Task, error) {
+				return &model.Task{ID: id, Title: "A", Status: to, Priority: model.PriorityNone, Version: 3}, nil
+			},
+			expectedStatus:  http.StatusOK,
+			expectedBody:    `{"id":3,"title":"A","description":"","done":false,"status":"in_progress","priority":"none","version":3}`,
+			expectedETag:    `"3"`,
+			expectedCalled:  true,
+			expectedTo:      model.StatusInProgress,
+			expectedVersion: 2,
+		},
+		{
+			name:           "invalid ID",
+			pathID:         "abc",
+			body:           `{"to":"done"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error_message":"invalid task id type"}`,
+		},
+		{
+			name:           "invalid body",
+			pathID:         "3",
+			body:           `{"to":1}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error_message":"failed to decode request"}`,
+		},
+		{
+			name:   "unknown status",
+			pathID: "3",
+			body:   `{"to":"later"}`,
+			usecaseFunc: func(ctx context.Context, id int, to model.Status, version int) (*model.Task, error) {
+				return nil, usecase.ErrInvalidStatus
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error_message":"unknown task status"}`,
+			expectedCalled: true,
+			expectedTo:     "later",
+		},
+		{
+			name:   "transition not allowed",
+			pathID: "3",
+			body:   `{"to":"review"}`,
+			usecaseFunc: func(ctx context.Context, id int, to model.Status, version int) (*model.Task, error) {
+				return nil, fmt.Errorf("%w: from %s to %s", usecase.ErrInvalidTransition, model.StatusDone, to)
+			},
+			expectedStatus: http.StatusConflict,
+			expectedBody:   `{"error_message":"task status transition is not allowed: from done to review"}`,
+			expectedCalled: true,
+			expectedTo:     model.StatusReview,
+		},
+		{
+			name:   "task blocked",
+			pathID: "3",
+			body:   `{"to":"done"}`,
+			usecaseFunc: func(ctx context.Context, id int, to model.Status, version int) (*model.Task, error) {
+				return nil, usecase.ErrTaskBlocked
+			},
+			expectedStatus: http.StatusConflict,
+			expectedBody:   `{"error_message":"task has unfinished dependencies"}`,
+			expectedCalled: true,
+			expectedTo:     model.StatusDone,
+		},
+		{
+			name:   "task not found",
+			pathID: "42",
+			body:   `{"to":"done"}`,
+			usecaseFunc: func(ctx context.Context, id int, to model.Status, version int) (*model.Task, error) {
+				return nil, usecase.NewTaskNotFoundError(id)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"error_message":"task not found: id 42"}`,
+			expectedCalled: true,
+			expectedTo:     model.StatusDone,
+		},
+		{
+			name:    "version conflict",
+			pathID:  "3",
+			body:    `{"to":"done"}`,
+			ifMatch: `"1"`,
+			usecaseFunc: func(ctx context.Context, id int, to model.Status, version int) (*model.Task, error) {
+				return nil, usecase.ErrVersionConflict
+			},
+			expectedStatus:  http.StatusPreconditionFailed,
+			expectedBody:    `{"error_message":"task version conflict"}`,
+			expectedCalled:  true,
+			expectedTo:      model.StatusDone,
+			expectedVersion: 1,
+		},
+		{
+			name:   "usecase error",
+			pathID: "3",
+			body:   `{"to":"done"}`,
+			usecaseFunc: func(ctx context.Context, id int, to model.Status, version int) (*model.Task, error) {
+				return nil, errors.New("db error")
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"error_message":"failed to change task status"}`,
+			expectedCalled: true,
+			expectedTo:     model.StatusDone,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUsecase := &mock.MockTaskUsecase{TransitionTaskFunc: tt.usecaseFunc}
+			h := v1.NewHandler(mockUsecase, &mock.MockProjectUsecase{}, &mock.MockCommentUsecase{}, &mock.MockBackupUsecase{}, &mock.MockReplicationUsecase{}, &mock.MockChangeUsecase{}, logger.NewMockLogger())
+
+			req := httptest.NewRequest(http.MethodPost, "/todos/"+tt.pathID+"/transitions", strings.NewReader(tt.body))
+			req.SetPathValue("id", tt.pathID)
+			if tt.ifMatch != "" {
+				req.Header.Set("If-Match", tt.ifMatch)
+			}
+			w := httptest.NewRecorder()
+
+			h.TransitionTask(ctx).ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+
+			if strings.TrimSpace(w.Body.String()) != tt.expectedBody {
+				t.Fatalf("expected body %q, got %q", tt.expectedBody, w.Body.String())
+			}
+
+			if etag := w.Header().Get("ETag"); etag != tt.expectedETag {
+				t.Fatalf("expected ETag %q, got %q", tt.expectedETag, etag)
+			}
+
+			if mockUsecase.TransitionTaskCalled != tt.expectedCalled {
+				t.Fatalf("expected usecase called = %v, got %v", tt.expectedCalled, mockUsecase.TransitionTaskCalled)
+			}
+
+			if mockUsecase.TransitionTaskTo != tt.expectedTo || mockUsecase.TransitionTaskVersion != tt.expectedVersion {
+				t.Fatalf("expected status %q and version %d, got %q and %d", tt.expectedTo, tt.expectedVersion,
+					mockUsecase.TransitionTaskTo, mockUsecase.TransitionTaskVersion)
+			}
+		})
+	}
+}
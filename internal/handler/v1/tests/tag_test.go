@@ -0,0 +1,153 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	v1 "github.com/solumD/tasks-service/internal/handler/v1"
+	"github.com/solumD/tasks-service/internal/handler/v1/mock"
+	"github.com/solumD/tasks-service/internal/model"
+	"github.com/solumD/tasks-service/internal/usecase"
+	"github.com/solumD/tasks-service/pkg/logger"
+)
+
+func TestGetTags(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name           string
+		usecaseFunc    func(ctx context.Context) ([]model.TagCount, error)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "success",
+			usecaseFunc: func(ctx context.Context) ([]model.TagCount, error) {
+				return []model.TagCount{{Tag: "home", Count: 2}, {Tag: "work", Count: 1}}, nil
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"tags":[{"tag":"home","count":2},{"tag":"work","count":1}]}`,
+		},
+		{
+			name:           "no tags",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"tags":[]}`,
+		},
+		{
+			name: "usecase error",
+			usecaseFunc: func(ctx context.Context) ([]model.TagCount, error) {
+				return nil, errors.New("db error")
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"error_message":"failed to get tags"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUsecase := &mock.MockTaskUsecase{GetTagsFunc: tt.usecaseFunc}
+			h := v1.NewHandler(mockUsecase, &mock.MockProjectUsecase{}, &mock.MockCommentUsecase{}, &mock.MockBackupUsecase{}, &mock.MockReplicationUsecase{}, &mock.MockChangeUsecase{}, logger.NewMockLogger())
+
+			req := httptest.NewRequest(http.MethodGet, "/tags", nil)
+			w := httptest.NewRecorder()
+
+			h.GetTags(ctx).ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+
+			if strings.TrimSpace(w.Body.String()) != tt.expectedBody {
+				t.Fatalf("expected body %q, got %q", tt.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestRenameTag(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name           string
+		tag            string
+		body           string
+		usecaseFunc    func(ctx context.Context, from, to string) ([]*model.Task, error)
+		expectedStatus int
+		expectedBody   string
+		expectedCalled bool
+	}{
+		{
+			name: "success",
+			tag:  "home",
+			body: `{"to":"house"}`,
+			usecaseFunc: func(ctx context.Context, from, to string) ([]*model.Task, error) {
+				return []*model.Task{{ID: 1}, {ID: 3}}, nil
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"from":"home","to":"house","renamed":2}`,
+			expectedCalled: true,
+		},
+		{
+			name:           "invalid body",
+			tag:            "home",
+			body:           `{"to":`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error_message":"failed to decode request"}`,
+		},
+		{
+			name: "invalid tag",
+			tag:  "home",
+			body: `{"to":""}`,
+			usecaseFunc: func(ctx context.Context, from, to string) ([]*model.Task, error) {
+				return nil, usecase.ErrInvalidTag
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error_message":"` + usecase.ErrInvalidTag.Error() + `"}`,
+			expectedCalled: true,
+		},
+		{
+			name: "usecase error",
+			tag:  "home",
+			body: `{"to":"house"}`,
+			usecaseFunc: func(ctx context.Context, from, to string) ([]*model.Task, error) {
+				return nil, errors.New("db error")
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"error_message":"failed to rename tag"}`,
+			expectedCalled: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUsecase := &mock.MockTaskUsecase{RenameTagFunc: tt.usecaseFunc}
+			h := v1.NewHandler(mockUsecase, &mock.MockProjectUsecase{}, &mock.MockCommentUsecase{}, &mock.MockBackupUsecase{}, &mock.MockReplicationUsecase{}, &mock.MockChangeUsecase{}, logger.NewMockLogger())
+
+			req := httptest.NewRequest(http.MethodPost, "/tags/"+tt.tag+"/rename", strings.NewReader(tt.body))
+			req.SetPathValue("tag", tt.tag)
+			w := httptest.NewRecorder()
+
+			h.RenameTag(ctx).ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+
+			if strings.TrimSpace(w.Body.String()) != tt.expectedBody {
+				t.Fatalf("expected body %q, got %q", tt.expectedBody, w.Body.String())
+			}
+
+			if mockUsecase.RenameTagCalled != tt.expectedCalled {
+				t.Fatalf("expected RenameTag called = %v, got %v", tt.expectedCalled, mockUsecase.RenameTagCalled)
+			}
+
+			if tt.expectedCalled && mockUsecase.RenameTagFrom != tt.tag {
+				t.Fatalf("expected rename from path tag %q, got %q", tt.tag, mockUsecase.RenameTagFrom)
+			}
+		})
+	}
+}
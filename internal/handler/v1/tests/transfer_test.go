@@ -0,0 +1,340 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	v1 "github.com/solumD/tasks-service/internal/handler/v1"
+	"github.com/solumD/tasks-service/internal/handler/v1/mock"
+	"github.com/solumD/tasks-service/internal/model"
+	"github.com/solumD/tasks-service/internal/usecase"
+	"github.com/solumD/tasks-service/pkg/logger"
+)
+
+func TestHandler_ExportTasks(t *testing.T) {
+	ctx := context.Background()
+
+	dueAt := time.Date(2026, time.March, 1, 18, 0, 0, 0, time.FixedZone("", 3*60*60))
+	createdAt := time.Date(2026, time.January, 10, 9, 0, 0, 0, time.UTC)
+
+	tasks := []*model.Task{
+		{ID: 1, Title: "A", Description: "first, with comma", DueAt: &dueAt, Recurrence: "FREQ=WEEKLY;BYDAY=MO,FR", Version: 1},
+		{ID: 2, Title: "B", Done: true, DueAt: &dueAt, Priority: model.PriorityHigh, Tags: []string{"home", "work"}, ParentID: 1, DependsOn: []int{1}, ProjectID: 4, CreatedAt: createdAt, Version: 3},
+	}
+
+	tests := []struct {
+		name                string
+		query               string
+		usecaseFunc         func(ctx context.Context, filter model.TaskFilter) ([]*model.Task, error)
+		expectedStatus      int
+		expectedContentType string
+		expectedBody        string
+	}{
+		{
+			name:                "default json",
+			query:               "",
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "application/json",
+			expectedBody: `[{"id":1,"title":"A","description":"first, with comma","done":false,"status":"todo","due_at":"2026-03-01T18:00:00+03:00","priority":"none","recurrence":"FREQ=WEEKLY;BYDAY=MO,FR","version":1},` +
+				`{"id":2,"title":"B","description":"","done":true,"status":"done","due_at":"2026-03-01T18:00:00+03:00","priority":"high","tags":["home","work"],"parent_id":1,"depends_on":[1],"project_id":4,"created_at":"2026-01-10T09:00:00Z","version":3}]` + "\n",
+		},
+		{
+			name:                "ndjson",
+			query:               "?format=ndjson",
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "application/x-ndjson",
+			expectedBody: `{"id":1,"title":"A","description":"first, with comma","done":false,"status":"todo","due_at":"2026-03-01T18:00:00+03:00","priority":"none","recurrence":"FREQ=WEEKLY;BYDAY=MO,FR","version":1}` + "\n" +
+				`{"id":2,"title":"B","description":"","done":true,"status":"done","due_at":"2026-03-01T18:00:00+03:00","priority":"high","tags":["home","work"],"parent_id":1,"depends_on":[1],"project_id":4,"created_at":"2026-01-10T09:00:00Z","version":3}` + "\n",
+		},
+		{
+			name:                "csv",
+			query:               "?format=csv",
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "text/csv",
+			expectedBody: "id,title,description,done,status,version,due_at,priority,tags,parent_id,depends_on,project_id,recurrence,created_at\n" +
+				"1,A,\"first, with comma\",false,todo,1,2026-03-01T18:00:00+03:00,none,,,,,\"FREQ=WEEKLY;BYDAY=MO,FR\",\n" +
+				"2,B,,true,done,3,2026-03-01T18:00:00+03:00,high,\"home,work\",1,1,4,,2026-01-10T09:00:00Z\n",
+		},
+		{
+			name:           "unknown format",
+			query:          "?format=xml",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error_message":"invalid format, expected json, ndjson or csv"}`,
+		},
+		{
+			name:  "usecase error",
+			query: "?format=csv",
+			usecaseFunc: func(ctx context.Context, filter model.TaskFilter) ([]*model.Task, error) {
+				return nil, errors.New("db error")
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"error_message":"failed to export tasks"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			usecaseFunc := tt.usecaseFunc
+			if usecaseFunc == nil {
+				usecaseFunc = func(ctx context.Context, filter model.TaskFilter) ([]*model.Task, error) {
+					return tasks, nil
+				}
+			}
+
+			mockUsecase := &mock.MockTaskUsecase{GetAllTasksFunc: usecaseFunc}
+			h := v1.NewHandler(mockUsecase, &mock.MockProjectUsecase{}, &mock.MockCommentUsecase{}, &mock.MockBackupUsecase{}, &mock.MockReplicationUsecase{}, &mock.MockChangeUsecase{}, logger.NewMockLogger())
+
+			req := httptest.NewRequest(http.MethodGet, "/todos/export"+tt.query, nil)
+			w := httptest.NewRecorder()
+
+			h.ExportTasks(ctx).ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+
+			if tt.expectedContentType != "" && w.Header().Get("Content-Type") != tt.expectedContentType {
+				t.Fatalf("expected content type %q, got %q", tt.expectedContentType, w.Header().Get("Content-Type"))
+			}
+
+			if w.Body.String() != tt.expectedBody {
+				t.Fatalf("expected body %q, got %q", tt.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandler_ImportTasks(t *testing.T) {
+	ctx := context.Background()
+
+	dueAt := time.Date(2026, time.March, 1, 18, 0, 0, 0, time.FixedZone("", 3*60*60))
+	createdAt := time.Date(2026, time.January, 10, 9, 0, 0, 0, time.UTC)
+
+	// юзкейс создает задачи с ID по порядку и отклоняет пустые названия
+	usecaseFunc := func(ctx context.Context, tasks []*model.Task, dryRun bool) []error {
+		errs := make([]error, len(tasks))
+		for i, task := range tasks {
+			if task.Title == "" {
+				errs[i] = usecase.ErrEmptyTitle
+				continue
+			}
+
+			if !dryRun {
+				task.ID = 10 + i
+			}
+		}
+
+		return errs
+	}
+
+	tests := []struct {
+		name                 string
+		query                string
+		body                 string
+		expectedStatus       int
+		expectedRespContains string
+		expectedTasks        []model.Task
+		expectedCalled       bool
+	}{
+		{
+			name:                 "json",
+			query:                "",
+			body:                 `[{"id":5,"title":"A","done":true},{"title":""},{"title":7}]`,
+			expectedStatus:       http.StatusOK,
+			expectedRespContains: `{"dry_run":false,"total":3,"imported":1,"failed":2,"rows":[{"row":1,"id":10},{"row":2,"error":"task title is empty"},{"row":3,"error":"failed to decode request: `,
+			expectedTasks:        []model.Task{{Title: "A", Done: true}, {}},
+			expectedCalled:       true,
+		},
+		{
+			name:                 "ndjson skips blank lines",
+			query:                "?format=ndjson",
+			body:                 "{\"title\":\"A\"}\n\n{\"title\":\"B\",\"description\":\"d\"}\n",
+			expectedStatus:       http.StatusOK,
+			expectedRespContains: `"rows":[{"row":1,"id":10},{"row":2,"id":11}]`,
+			expectedTasks:        []model.Task{{Title: "A"}, {Title: "B", Description: "d"}},
+			expectedCalled:       true,
+		},
+		{
+			name:                 "csv with columns in any order",
+			query:                "?format=csv",
+			body:                 "done,title\ntrue,A\nmaybe,B\n",
+			expectedStatus:       http.StatusOK,
+			expectedRespContains: `"rows":[{"row":1,"id":10},{"row":2,"error":"invalid done value: \"maybe\""}]`,
+			expectedTasks:        []model.Task{{Title: "A", Done: true}},
+			expectedCalled:       true,
+		},
+		{
+			name:                 "csv with due date",
+			query:                "?format=csv",
+			body:                 "title,due_at\nA,2026-03-01T18:00:00+03:00\nB,tomorrow\n",
+			expectedStatus:       http.StatusOK,
+			expectedRespContains: `"rows":[{"row":1,"id":10},{"row":2,"error":"invalid due_at value, expected RFC 3339: \"tomorrow\""}]`,
+			expectedTasks:        []model.Task{{Title: "A", DueAt: &dueAt}},
+			expectedCalled:       true,
+		},
+		{
+			name:                 "csv with priority",
+			query:                "?format=csv",
+			body:                 "title,priority\nA,urgent\nB,\n",
+			expectedStatus:       http.StatusOK,
+			expectedRespContains: `"rows":[{"row":1,"id":10},{"row":2,"id":11}]`,
+			expectedTasks:        []model.Task{{Title: "A", Priority: model.PriorityUrgent}, {Title: "B"}},
+			expectedCalled:       true,
+		},
+		{
+			name:                 "csv with tags",
+			query:                "?format=csv",
+			body:                 "title,tags\nA,\"home, work\"\nB,\n",
+			expectedStatus:       http.StatusOK,
+			expectedRespContains: `"rows":[{"row":1,"id":10},{"row":2,"id":11}]`,
+			expectedTasks:        []model.Task{{Title: "A", Tags: []string{"home", " work"}}, {Title: "B"}},
+			expectedCalled:       true,
+		},
+		{
+			name:                 "csv with recurrence",
+			query:                "?format=csv",
+			body:                 "title,due_at,recurrence\nA,2026-03-01T18:00:00+03:00, FREQ=DAILY;COUNT=3 \n",
+			expectedStatus:       http.StatusOK,
+			expectedRespContains: `"rows":[{"row":1,"id":10}]`,
+			expectedTasks:        []model.Task{{Title: "A", DueAt: &dueAt, Recurrence: "FREQ=DAILY;COUNT=3"}},
+			expectedCalled:       true,
+		},
+		{
+			name:                 "csv with status",
+			query:                "?format=csv",
+			body:                 "title,done,status\nA,false, in_progress \nB,true,\n",
+			expectedStatus:       http.StatusOK,
+			expectedRespContains: `"rows":[{"row":1,"id":10},{"row":2,"id":11}]`,
+			expectedTasks:        []model.Task{{Title: "A", Status: model.StatusInProgress}, {Title: "B", Done: true}},
+			expectedCalled:       true,
+		},
+		{
+			name:                 "json with created at",
+			query:                "",
+			body:                 `[{"title":"A","created_at":"2026-01-10T12:00:00+03:00"}]`,
+			expectedStatus:       http.StatusOK,
+			expectedRespContains: `"rows":[{"row":1,"id":10}]`,
+			expectedTasks:        []model.Task{{Title: "A", CreatedAt: createdAt}},
+			expectedCalled:       true,
+		},
+		{
+			name:                 "csv with created at",
+			query:                "?format=csv",
+			body:                 "title,created_at\nA,2026-01-10T09:00:00Z\nB,yesterday\n",
+			expectedStatus:       http.StatusOK,
+			expectedRespContains: `"rows":[{"row":1,"id":10},{"row":2,"error":"invalid created_at value, expected RFC 3339: \"yesterday\""}]`,
+			expectedTasks:        []model.Task{{Title: "A", CreatedAt: createdAt}},
+			expectedCalled:       true,
+		},
+		{
+			name:                 "csv with project",
+			query:                "?format=csv",
+			body:                 "title,project_id\nA,2\nB,\nC,work\n",
+			expectedStatus:       http.StatusOK,
+			expectedRespContains: `"rows":[{"row":1,"id":10},{"row":2,"id":11},{"row":3,"error":"invalid project_id value: \"work\""}]`,
+			expectedTasks:        []model.Task{{Title: "A", ProjectID: 2}, {Title: "B"}},
+			expectedCalled:       true,
+		},
+		{
+			name:                 "csv with parent",
+			query:                "?format=csv&dry_run=true",
+			body:                 "id,title,parent_id\n1,A,\n2,B,1\nx,C,\n3,D,one\n",
+			expectedStatus:       http.StatusOK,
+			expectedRespContains: `"rows":[{"row":1},{"row":2},{"row":3,"error":"invalid id value: \"x\""},{"row":4,"error":"invalid parent_id value: \"one\""}]`,
+			expectedTasks:        []model.Task{{ID: 1, Title: "A"}, {ID: 2, Title: "B", ParentID: 1}},
+			expectedCalled:       true,
+		},
+		{
+			name:                 "csv with dependencies",
+			query:                "?format=csv&dry_run=true",
+			body:                 "id,title,depends_on\n1,A,\n2,B,\"1, 3\"\n3,C,\n4,D,\"1,x\"\n",
+			expectedStatus:       http.StatusOK,
+			expectedRespContains: `"rows":[{"row":1},{"row":2},{"row":3},{"row":4,"error":"invalid depends_on value: \"1,x\""}]`,
+			expectedTasks:        []model.Task{{ID: 1, Title: "A"}, {ID: 2, Title: "B", DependsOn: []int{1, 3}}, {ID: 3, Title: "C"}},
+			expectedCalled:       true,
+		},
+		{
+			name:                 "json with parent",
+			query:                "?dry_run=true",
+			body:                 `[{"id":1,"title":"A"},{"id":2,"title":"B","parent_id":1}]`,
+			expectedStatus:       http.StatusOK,
+			expectedRespContains: `"rows":[{"row":1},{"row":2}]`,
+			expectedTasks:        []model.Task{{ID: 1, Title: "A"}, {ID: 2, Title: "B", ParentID: 1}},
+			expectedCalled:       true,
+		},
+		{
+			name:                 "dry run",
+			query:                "?format=ndjson&dry_run=true",
+			body:                 "{\"title\":\"A\"}\n{\"title\":\"\"}\n",
+			expectedStatus:       http.StatusOK,
+			expectedRespContains: `{"dry_run":true,"total":2,"imported":1,"failed":1,"rows":[{"row":1},{"row":2,"error":"task title is empty"}]}`,
+			expectedTasks:        []model.Task{{Title: "A"}, {}},
+			expectedCalled:       true,
+		},
+		{
+			name:                 "csv without title column",
+			query:                "?format=csv",
+			body:                 "name\nA\n",
+			expectedStatus:       http.StatusBadRequest,
+			expectedRespContains: "csv header has no title column",
+		},
+		{
+			name:                 "malformed json",
+			query:                "",
+			body:                 `{"title":"A"}`,
+			expectedStatus:       http.StatusBadRequest,
+			expectedRespContains: "failed to decode request",
+		},
+		{
+			name:                 "invalid dry run",
+			query:                "?dry_run=maybe",
+			body:                 `[]`,
+			expectedStatus:       http.StatusBadRequest,
+			expectedRespContains: "invalid dry_run value",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUsecase := &mock.MockTaskUsecase{ImportTasksFunc: usecaseFunc}
+			h := v1.NewHandler(mockUsecase, &mock.MockProjectUsecase{}, &mock.MockCommentUsecase{}, &mock.MockBackupUsecase{}, &mock.MockReplicationUsecase{}, &mock.MockChangeUsecase{}, logger.NewMockLogger())
+
+			req := httptest.NewRequest(http.MethodPost, "/todos/import"+tt.query, strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+
+			h.ImportTasks(ctx).ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+
+			if !strings.Contains(w.Body.String(), tt.expectedRespContains) {
+				t.Fatalf("expected body to contain %q, got %q", tt.expectedRespContains, w.Body.String())
+			}
+
+			if mockUsecase.ImportTasksCalled != tt.expectedCalled {
+				t.Fatalf("expected ImportTasks called = %v", tt.expectedCalled)
+			}
+
+			if len(mockUsecase.ImportTasksTasks) != len(tt.expectedTasks) {
+				t.Fatalf("expected %d tasks passed to usecase, got %d", len(tt.expectedTasks), len(mockUsecase.ImportTasksTasks))
+			}
+
+			for i, want := range tt.expectedTasks {
+				got := mockUsecase.ImportTasksTasks[i]
+				if got.Title != want.Title || got.Description != want.Description || got.Done != want.Done || got.Priority != want.Priority || got.ProjectID != want.ProjectID ||
+					got.ParentID != want.ParentID || !slices.Equal(got.DependsOn, want.DependsOn) || (mockUsecase.ImportTasksDryRun && got.ID != want.ID) ||
+					!slices.Equal(got.Tags, want.Tags) || !got.CreatedAt.Equal(want.CreatedAt) ||
+					(got.DueAt == nil) != (want.DueAt == nil) || (got.DueAt != nil && !got.DueAt.Equal(*want.DueAt)) {
+					t.Fatalf("expected task %+v at %d, got %+v", want, i, got)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,160 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	v1 "github.com/solumD/tasks-service/internal/handler/v1"
+	"github.com/solumD/tasks-service/internal/handler/v1/mock"
+	"github.com/solumD/tasks-service/internal/model"
+	"github.com/solumD/tasks-service/internal/usecase"
+	"github.com/solumD/tasks-service/pkg/logger"
+)
+
+func TestHandler_GetDeletedTasks(t *testing.T) {
+	ctx := context.Background()
+	deletedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name                 string
+		usecaseFunc          func(ctx context.Context) ([]*model.Task, error)
+		expectedStatus       int
+		expectedRespContains string
+	}{
+		{
+			name: "repo error",
+			usecaseFunc: func(ctx context.Context) ([]*model.Task, error) {
+				return nil, errors.New("db error")
+			},
+			expectedStatus:       http.StatusInternalServerError,
+			expectedRespContains: "failed to get deleted tasks",
+		},
+		{
+			name: "success",
+			usecaseFunc: func(ctx context.Context) ([]*model.Task, error) {
+				return []*model.Task{{ID: 1, Title: "A", Version: 2, DeletedAt: &deletedAt}}, nil
+			},
+			expectedStatus:       http.StatusOK,
+			expectedRespContains: `"deleted_at":"2024-05-01T12:00:00Z"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUsecase := &mock.MockTaskUsecase{
+				GetDeletedTasksFunc: tt.usecaseFunc,
+			}
+
+			log := logger.NewMockLogger()
+			h := v1.NewHandler(mockUsecase, &mock.MockProjectUsecase{}, &mock.MockCommentUsecase{}, &mock.MockBackupUsecase{}, &mock.MockReplicationUsecase{}, &mock.MockChangeUsecase{}, log)
+
+			req := httptest.NewRequest(http.MethodGet, "/todos/trash", nil)
+			w := httptest.NewRecorder()
+
+			h.GetDeletedTasks(ctx).ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+
+			if !strings.Contains(w.Body.String(), tt.expectedRespContains) {
+				t.Fatalf("expected body to contain %q, got %q", tt.expectedRespContains, w.Body.String())
+			}
+
+			if !mockUsecase.GetDeletedTasksCalled {
+				t.Fatalf("expected GetDeletedTasks to be called")
+			}
+		})
+	}
+}
+
+func TestHandler_RestoreTask(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name                 string
+		pathID               string
+		usecaseFunc          func(ctx context.Context, id int) (*model.Task, error)
+		expectedStatus       int
+		expectedRespContains string
+		expectedETag         string
+		expectedCalled       bool
+	}{
+		{
+			name:                 "invalid ID",
+			pathID:               "abc",
+			expectedStatus:       http.StatusBadRequest,
+			expectedRespContains: "invalid task id type",
+			expectedCalled:       false,
+		},
+		{
+			name:   "not in trash",
+			pathID: "1",
+			usecaseFunc: func(ctx context.Context, id int) (*model.Task, error) {
+				return nil, usecase.NewTaskNotFoundError(id)
+			},
+			expectedStatus:       http.StatusNotFound,
+			expectedRespContains: "task not found",
+			expectedCalled:       true,
+		},
+		{
+			name:   "repo error",
+			pathID: "1",
+			usecaseFunc: func(ctx context.Context, id int) (*model.Task, error) {
+				return nil, errors.New("db error")
+			},
+			expectedStatus:       http.StatusInternalServerError,
+			expectedRespContains: "failed to restore task",
+			expectedCalled:       true,
+		},
+		{
+			name:   "success",
+			pathID: "1",
+			usecaseFunc: func(ctx context.Context, id int) (*model.Task, error) {
+				return &model.Task{ID: id, Title: "A", Version: 3}, nil
+			},
+			expectedStatus:       http.StatusOK,
+			expectedRespContains: `"title":"A"`,
+			expectedETag:         `"3"`,
+			expectedCalled:       true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUsecase := &mock.MockTaskUsecase{
+				RestoreTaskFunc: tt.usecaseFunc,
+			}
+
+			log := logger.NewMockLogger()
+			h := v1.NewHandler(mockUsecase, &mock.MockProjectUsecase{}, &mock.MockCommentUsecase{}, &mock.MockBackupUsecase{}, &mock.MockReplicationUsecase{}, &mock.MockChangeUsecase{}, log)
+
+			req := httptest.NewRequest(http.MethodPost, "/todos/"+tt.pathID+"/restore", nil)
+			req.SetPathValue("id", tt.pathID)
+			w := httptest.NewRecorder()
+
+			h.RestoreTask(ctx).ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+
+			if !strings.Contains(w.Body.String(), tt.expectedRespContains) {
+				t.Fatalf("expected body to contain %q, got %q", tt.expectedRespContains, w.Body.String())
+			}
+
+			if etag := w.Header().Get("ETag"); etag != tt.expectedETag {
+				t.Fatalf("expected ETag %q, got %q", tt.expectedETag, etag)
+			}
+
+			if mockUsecase.RestoreTaskCalled != tt.expectedCalled {
+				t.Fatalf("expected RestoreTask called = %v, got %v", tt.expectedCalled, mockUsecase.RestoreTaskCalled)
+			}
+		})
+	}
+}
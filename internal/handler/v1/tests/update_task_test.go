@@ -21,6 +21,7 @@ func TestUpdateTask(t *testing.T) {
 	tests := []struct {
 		name                 string
 		pathID               string
+		ifMatch              string
 		reqBody              string
 		usecaseFunc          func(ctx context.Context, task *model.Task) error
 		expectedStatus       int
@@ -69,6 +70,124 @@ func TestUpdateTask(t *testing.T) {
 			expectedRespContains: "task title is empty",
 			expectedCalled:       true,
 		},
+		{
+			name:    "due date out of range",
+			pathID:  "1",
+			reqBody: `{"title":"Updated","due_at":"0000-01-01T00:00:00Z"}`,
+			usecaseFunc: func(ctx context.Context, task *model.Task) error {
+				return usecase.ErrInvalidDueDate
+			},
+			expectedStatus:       http.StatusBadRequest,
+			expectedRespContains: "task due date is out of range",
+			expectedCalled:       true,
+		},
+		{
+			name:    "unknown priority",
+			pathID:  "1",
+			reqBody: `{"title":"Updated","priority":"critical"}`,
+			usecaseFunc: func(ctx context.Context, task *model.Task) error {
+				return usecase.ErrInvalidPriority
+			},
+			expectedStatus:       http.StatusBadRequest,
+			expectedRespContains: "unknown task priority",
+			expectedCalled:       true,
+		},
+		{
+			name:    "parent cycle",
+			pathID:  "1",
+			reqBody: `{"title":"Updated","parent_id":2}`,
+			usecaseFunc: func(ctx context.Context, task *model.Task) error {
+				return usecase.ErrTaskCycle
+			},
+			expectedStatus:       http.StatusBadRequest,
+			expectedRespContains: "task cannot be a subtask of itself",
+			expectedCalled:       true,
+		},
+		{
+			name:    "parent not found",
+			pathID:  "1",
+			reqBody: `{"title":"Updated","parent_id":42}`,
+			usecaseFunc: func(ctx context.Context, task *model.Task) error {
+				return usecase.ErrParentNotFound
+			},
+			expectedStatus:       http.StatusBadRequest,
+			expectedRespContains: "parent task not found",
+			expectedCalled:       true,
+		},
+		{
+			name:    "dependency cycle",
+			pathID:  "1",
+			reqBody: `{"title":"Updated","depends_on":[2]}`,
+			usecaseFunc: func(ctx context.Context, task *model.Task) error {
+				return usecase.ErrDependencyCycle
+			},
+			expectedStatus:       http.StatusBadRequest,
+			expectedRespContains: "task cannot depend on itself",
+			expectedCalled:       true,
+		},
+		{
+			name:    "blocked by dependencies",
+			pathID:  "1",
+			reqBody: `{"title":"Updated","done":true,"depends_on":[2]}`,
+			usecaseFunc: func(ctx context.Context, task *model.Task) error {
+				return usecase.ErrTaskBlocked
+			},
+			expectedStatus:       http.StatusConflict,
+			expectedRespContains: "task has unfinished dependencies",
+			expectedCalled:       true,
+		},
+		{
+			name:    "invalid recurrence",
+			pathID:  "1",
+			reqBody: `{"title":"Updated","recurrence":"FREQ=YEARLY"}`,
+			usecaseFunc: func(ctx context.Context, task *model.Task) error {
+				if task.Recurrence != "FREQ=YEARLY" {
+					return errors.New("unexpected recurrence")
+				}
+
+				return usecase.ErrInvalidRecurrence
+			},
+			expectedStatus:       http.StatusBadRequest,
+			expectedRespContains: "invalid recurrence rule",
+			expectedCalled:       true,
+		},
+		{
+			name:    "recurrence without due date",
+			pathID:  "1",
+			reqBody: `{"title":"Updated","recurrence":"FREQ=DAILY"}`,
+			usecaseFunc: func(ctx context.Context, task *model.Task) error {
+				return usecase.ErrRecurrenceWithoutDue
+			},
+			expectedStatus:       http.StatusBadRequest,
+			expectedRespContains: "recurring task must have a due date",
+			expectedCalled:       true,
+		},
+		{
+			name:                 "invalid If-Match",
+			pathID:               "1",
+			ifMatch:              "3",
+			reqBody:              `{"title":"Updated"}`,
+			usecaseFunc:          nil,
+			expectedStatus:       http.StatusBadRequest,
+			expectedRespContains: "invalid If-Match header",
+			expectedCalled:       false,
+		},
+		{
+			name:    "version conflict",
+			pathID:  "1",
+			ifMatch: `"3"`,
+			reqBody: `{"title":"Updated"}`,
+			usecaseFunc: func(ctx context.Context, task *model.Task) error {
+				if task.Version != 3 {
+					return errors.New("unexpected version")
+				}
+
+				return usecase.ErrVersionConflict
+			},
+			expectedStatus:       http.StatusPreconditionFailed,
+			expectedRespContains: "task version conflict",
+			expectedCalled:       true,
+		},
 		{
 			name:    "success",
 			pathID:  "1",
@@ -89,9 +208,12 @@ func TestUpdateTask(t *testing.T) {
 			}
 
 			log := logger.NewMockLogger()
-			h := v1.NewHandler(mockUsecase, log)
+			h := v1.NewHandler(mockUsecase, &mock.MockProjectUsecase{}, &mock.MockCommentUsecase{}, &mock.MockBackupUsecase{}, &mock.MockReplicationUsecase{}, &mock.MockChangeUsecase{}, log)
 
 			req := httptest.NewRequest(http.MethodPut, "/tasks/"+tt.pathID, strings.NewReader(tt.reqBody))
+			if tt.ifMatch != "" {
+				req.Header.Set("If-Match", tt.ifMatch)
+			}
 			w := httptest.NewRecorder()
 
 			h.UpdateTask(ctx).ServeHTTP(w, req)
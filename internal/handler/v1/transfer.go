@@ -0,0 +1,514 @@
+package v1
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/solumD/tasks-service/internal/handler/v1/dto"
+	"github.com/solumD/tasks-service/internal/model"
+	"github.com/solumD/tasks-service/pkg/logger"
+)
+
+const (
+	formatJSON   = "json"
+	formatNDJSON = "ndjson"
+	formatCSV    = "csv"
+
+	contentTypeNDJSON = "application/x-ndjson"
+	contentTypeCSV    = "text/csv"
+
+	// maxNDJSONLine максимальная длина строки NDJSON при импорте
+	maxNDJSONLine = 1 << 20
+)
+
+var (
+	ErrFailedToExportTasks = errors.New("failed to export tasks")
+	ErrFailedToImportTasks = errors.New("failed to import tasks")
+	ErrInvalidFormat       = errors.New("invalid format, expected json, ndjson or csv")
+	ErrInvalidDryRun       = errors.New("invalid dry_run value")
+	ErrInvalidDoneValue    = errors.New("invalid done value")
+	ErrInvalidDueValue     = errors.New("invalid due_at value, expected RFC 3339")
+	ErrInvalidCreatedValue = errors.New("invalid created_at value, expected RFC 3339")
+	ErrInvalidProjectValue = errors.New("invalid project_id value")
+	ErrInvalidIDValue      = errors.New("invalid id value")
+	ErrInvalidParentValue  = errors.New("invalid parent_id value")
+	ErrInvalidDependsValue = errors.New("invalid depends_on value")
+	ErrMissingTitleColumn  = errors.New("csv header has no title column")
+)
+
+// csvHeader колонки CSV экспорта. При импорте обязательна только title,
+// колонка version игнорируется
+var csvHeader = []string{"id", "title", "description", "done", "status", "version", "due_at", "priority", "tags", "parent_id", "depends_on", "project_id", "recurrence", "created_at"}
+
+// importRow задача из записи импорта или ошибка разбора записи
+type importRow struct {
+	task *model.Task
+	err  error
+}
+
+// parseFormat возвращает формат из параметра format, по умолчанию json
+func parseFormat(r *http.Request) (string, error) {
+	format := r.URL.Query().Get("format")
+
+	switch format {
+	case "":
+		return formatJSON, nil
+	case formatJSON, formatNDJSON, formatCSV:
+		return format, nil
+	default:
+		return "", ErrInvalidFormat
+	}
+}
+
+// ExportTasks обрабатывает запрос на выгрузку всех задач. Задачи пишутся
+// в ответ по одной, без сборки всего тела в памяти
+func (h *handler) ExportTasks(ctx context.Context) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const fn = "handler.ExportTasks"
+		log := h.log.With(logger.String("fn", fn))
+
+		log.Info("new request")
+
+		format, err := parseFormat(r)
+		if err != nil {
+			log.Error("failed to get format from query", logger.Error(err))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusBadRequest, err)
+			return
+		}
+
+		tasks, err := h.taskUsecase.GetAllTasks(ctx, model.TaskFilter{})
+		if err != nil {
+			log.Error("failed to get all tasks", logger.Error(err))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusInternalServerError, ErrFailedToExportTasks)
+			return
+		}
+
+		contentType := map[string]string{
+			formatJSON:   contentTypeJSON,
+			formatNDJSON: contentTypeNDJSON,
+			formatCSV:    contentTypeCSV,
+		}[format]
+
+		w.Header().Add("Content-Type", contentType)
+		w.Header().Add("Content-Disposition", fmt.Sprintf(`attachment; filename="tasks.%s"`, format))
+		w.WriteHeader(http.StatusOK)
+
+		// после отправки заголовков об ошибке записи можно только залогировать
+		if err := writeTasks(w, format, tasks); err != nil {
+			log.Error("failed to write export", logger.Error(err))
+			return
+		}
+
+		log.Info("exported tasks", logger.String("format", format), logger.Int("tasks count", len(tasks)))
+	}
+}
+
+func writeTasks(w io.Writer, format string, tasks []*model.Task) error {
+	bw := bufio.NewWriter(w)
+
+	switch format {
+	case formatJSON, formatNDJSON:
+		if format == formatJSON {
+			bw.WriteString("[")
+		}
+
+		for i, task := range tasks {
+			line, err := json.Marshal(dto.FromTaskToDTO(task))
+			if err != nil {
+				return err
+			}
+
+			if format == formatJSON && i > 0 {
+				bw.WriteString(",")
+			}
+
+			bw.Write(line)
+
+			if format == formatNDJSON {
+				bw.WriteString("\n")
+			}
+		}
+
+		if format == formatJSON {
+			bw.WriteString("]\n")
+		}
+	case formatCSV:
+		cw := csv.NewWriter(bw)
+
+		if err := cw.Write(csvHeader); err != nil {
+			return err
+		}
+
+		for _, task := range tasks {
+			dueAt := ""
+			if task.DueAt != nil {
+				dueAt = task.DueAt.Format(time.RFC3339Nano)
+			}
+
+			// у задачи верхнего уровня родитель не заполняется
+			parentID := ""
+			if task.ParentID != 0 {
+				parentID = strconv.Itoa(task.ParentID)
+			}
+
+			// у задачи вне проектов проект не заполняется
+			projectID := ""
+			if task.ProjectID != 0 {
+				projectID = strconv.Itoa(task.ProjectID)
+			}
+
+			// у задачи, созданной до появления времени создания, оно не заполняется
+			createdAt := ""
+			if !task.CreatedAt.IsZero() {
+				createdAt = task.CreatedAt.Format(time.RFC3339Nano)
+			}
+
+			dependsOn := make([]string, 0, len(task.DependsOn))
+			for _, id := range task.DependsOn {
+				dependsOn = append(dependsOn, strconv.Itoa(id))
+			}
+
+			err := cw.Write([]string{
+				strconv.Itoa(task.ID),
+				task.Title,
+				task.Description,
+				strconv.FormatBool(task.Done),
+				string(task.CurrentStatus()),
+				strconv.Itoa(task.Version),
+				dueAt,
+				task.Priority.String(),
+				strings.Join(task.Tags, ","),
+				parentID,
+				strings.Join(dependsOn, ","),
+				projectID,
+				task.Recurrence,
+				createdAt,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		cw.Flush()
+		if err := cw.Error(); err != nil {
+			return err
+		}
+	}
+
+	return bw.Flush()
+}
+
+// ImportTasks обрабатывает запрос на загрузку задач. Каждая запись создается
+// как новая задача с теми же проверками, что и в POST /todos. Ошибочные записи
+// не мешают остальным и перечисляются в ответе
+func (h *handler) ImportTasks(ctx context.Context) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const fn = "handler.ImportTasks"
+		log := h.log.With(logger.String("fn", fn))
+		ctx := withAuthor(ctx, r)
+
+		log.Info("new request")
+
+		format, err := parseFormat(r)
+		if err != nil {
+			log.Error("failed to get format from query", logger.Error(err))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusBadRequest, err)
+			return
+		}
+
+		dryRun := false
+		if value := r.URL.Query().Get("dry_run"); value != "" {
+			dryRun, err = strconv.ParseBool(value)
+			if err != nil {
+				log.Error("failed to get dry_run from query", logger.Error(err))
+
+				h.errorResponse(w, contentTypeJSON, http.StatusBadRequest, ErrInvalidDryRun)
+				return
+			}
+		}
+
+		rows, err := readTasks(r.Body, format)
+		if err != nil {
+			log.Error("failed to decode request", logger.Error(err))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusBadRequest, fmt.Errorf("%w: %v", ErrFailedToDecodeReq, err))
+			return
+		}
+
+		log.Info("decoded request", logger.String("format", format), logger.Int("rows count", len(rows)))
+
+		tasks := make([]*model.Task, 0, len(rows))
+		for _, row := range rows {
+			if row.err == nil {
+				tasks = append(tasks, row.task)
+			}
+		}
+
+		errs := h.taskUsecase.ImportTasks(ctx, tasks, dryRun)
+
+		resp := &dto.ImportTasksResp{
+			DryRun: dryRun,
+			Total:  len(rows),
+			Rows:   make([]*dto.ImportRowDTO, 0, len(rows)),
+		}
+
+		next := 0
+		for i, row := range rows {
+			rowResp := &dto.ImportRowDTO{Row: i + 1}
+
+			if row.err == nil {
+				row.err = errs[next]
+				next++
+			}
+
+			switch {
+			case row.err != nil:
+				rowResp.Error = row.err.Error()
+				resp.Failed++
+			default:
+				// при dry_run задача не создана и нового ID у нее нет
+				if !dryRun {
+					rowResp.ID = row.task.ID
+				}
+				resp.Imported++
+			}
+
+			resp.Rows = append(resp.Rows, rowResp)
+		}
+
+		respBody, err := json.Marshal(resp)
+		if err != nil {
+			log.Error("failed to marshal response", logger.Error(err))
+
+			h.errorResponse(w, contentTypeJSON, http.StatusInternalServerError, ErrFailedToImportTasks)
+			return
+		}
+
+		log.Info("imported tasks", logger.Int("imported count", resp.Imported), logger.Int("failed count", resp.Failed))
+
+		h.response(w, contentTypeJSON, http.StatusOK, respBody)
+	}
+}
+
+// readTasks разбирает тело импорта. Ошибка возвращается, только если тело
+// не удалось разобрать целиком, ошибки отдельных записей попадают в importRow
+func readTasks(body io.Reader, format string) ([]importRow, error) {
+	switch format {
+	case formatNDJSON:
+		return readNDJSON(body)
+	case formatCSV:
+		return readCSV(body)
+	default:
+		return readJSON(body)
+	}
+}
+
+func decodeTask(raw []byte) importRow {
+	var req dto.ImportTaskReq
+	if err := json.Unmarshal(raw, &req); err != nil {
+		return importRow{err: fmt.Errorf("%w: %v", ErrFailedToDecodeReq, err)}
+	}
+
+	return importRow{task: dto.FromImportReqToTask(req)}
+}
+
+func readJSON(body io.Reader) ([]importRow, error) {
+	var raws []json.RawMessage
+	if err := json.NewDecoder(body).Decode(&raws); err != nil {
+		return nil, err
+	}
+
+	rows := make([]importRow, 0, len(raws))
+	for _, raw := range raws {
+		rows = append(rows, decodeTask(raw))
+	}
+
+	return rows, nil
+}
+
+// readNDJSON разбирает по одной задаче на строку, пустые строки пропускаются
+func readNDJSON(body io.Reader) ([]importRow, error) {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxNDJSONLine)
+
+	rows := make([]importRow, 0)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		rows = append(rows, decodeTask([]byte(line)))
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return rows, nil
+}
+
+// readCSV разбирает CSV с заголовком, колонки ищутся по имени
+func readCSV(body io.Reader) ([]importRow, error) {
+	cr := csv.NewReader(body)
+	cr.FieldsPerRecord = -1
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	if _, ok := columns["title"]; !ok {
+		return nil, ErrMissingTitleColumn
+	}
+
+	field := func(record []string, name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+
+		return record[i]
+	}
+
+	// intField возвращает число из колонки name, 0 - колонка пуста
+	intField := func(record []string, name string, errInvalid error) (int, error) {
+		value := strings.TrimSpace(field(record, name))
+		if value == "" {
+			return 0, nil
+		}
+
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return 0, fmt.Errorf("%w: %q", errInvalid, value)
+		}
+
+		return n, nil
+	}
+
+	rows := make([]importRow, 0)
+	for {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		task := &model.Task{
+			Title:       field(record, "title"),
+			Description: field(record, "description"),
+		}
+
+		// id из выгрузки нужен только для ссылок между записями
+		task.ID, err = intField(record, "id", ErrInvalidIDValue)
+		if err != nil {
+			rows = append(rows, importRow{err: err})
+			continue
+		}
+
+		if done := strings.TrimSpace(field(record, "done")); done != "" {
+			task.Done, err = strconv.ParseBool(done)
+			if err != nil {
+				rows = append(rows, importRow{err: fmt.Errorf("%w: %q", ErrInvalidDoneValue, done)})
+				continue
+			}
+		}
+
+		// статус важнее признака done, проверяет его юзкейс
+		task.Status = model.Status(strings.TrimSpace(field(record, "status")))
+
+		if value := strings.TrimSpace(field(record, "due_at")); value != "" {
+			dueAt, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				rows = append(rows, importRow{err: fmt.Errorf("%w: %q", ErrInvalidDueValue, value)})
+				continue
+			}
+
+			task.DueAt = &dueAt
+		}
+
+		task.Priority = model.Priority(strings.TrimSpace(field(record, "priority")))
+
+		// метки в одном поле через запятую, проверяет их юзкейс
+		if value := strings.TrimSpace(field(record, "tags")); value != "" {
+			task.Tags = strings.Split(value, ",")
+		}
+
+		// родитель ссылается на id из выгрузки, ссылки разрешает юзкейс
+		task.ParentID, err = intField(record, "parent_id", ErrInvalidParentValue)
+		if err != nil {
+			rows = append(rows, importRow{err: err})
+			continue
+		}
+
+		// зависимости в одном поле через запятую, ссылки разрешает юзкейс
+		if value := strings.TrimSpace(field(record, "depends_on")); value != "" {
+			task.DependsOn, err = parseIDs(value)
+			if err != nil {
+				rows = append(rows, importRow{err: fmt.Errorf("%w: %q", ErrInvalidDependsValue, value)})
+				continue
+			}
+		}
+
+		// существование проекта проверяет юзкейс
+		task.ProjectID, err = intField(record, "project_id", ErrInvalidProjectValue)
+		if err != nil {
+			rows = append(rows, importRow{err: err})
+			continue
+		}
+
+		// правило повторения проверяет юзкейс
+		task.Recurrence = strings.TrimSpace(field(record, "recurrence"))
+
+		if value := strings.TrimSpace(field(record, "created_at")); value != "" {
+			createdAt, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				rows = append(rows, importRow{err: fmt.Errorf("%w: %q", ErrInvalidCreatedValue, value)})
+				continue
+			}
+
+			task.CreatedAt = createdAt.UTC()
+		}
+
+		rows = append(rows, importRow{task: task})
+	}
+
+	return rows, nil
+}
+
+// parseIDs разбирает список id через запятую
+func parseIDs(value string) ([]int, error) {
+	parts := strings.Split(value, ",")
+
+	ids := make([]int, 0, len(parts))
+	for _, part := range parts {
+		id, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil {
+			return nil, err
+		}
+
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
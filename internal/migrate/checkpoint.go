@@ -0,0 +1,67 @@
+package migrate
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/solumD/tasks-service/internal/fsync"
+)
+
+// checkpoint прогресс прерванного переноса. Задачи переносятся по возрастанию ID,
+// поэтому достаточно помнить последний перенесенный ID
+type checkpoint struct {
+	SourceChecksum string `json:"source_checksum"`
+	SourceTasks    int    `json:"source_tasks"`
+	Started        bool   `json:"started"`
+	LastID         int    `json:"last_id"`
+}
+
+// loadCheckpoint читает контрольную точку, nil - переноса еще не было
+func loadCheckpoint(path string) (*checkpoint, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+
+	var cp checkpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal checkpoint: %w", err)
+	}
+
+	return &cp, nil
+}
+
+// saveCheckpoint атомарно заменяет контрольную точку через временный файл
+func saveCheckpoint(path string, cp *checkpoint) error {
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return fmt.Errorf("failed to marshal checkpoint: %w", err)
+	}
+
+	tmpPath := path + ".tmp"
+
+	if err := fsync.WriteFile(tmpPath, data); err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("failed to rename checkpoint: %w", err)
+	}
+
+	return fsync.Dir(filepath.Dir(path))
+}
+
+func removeCheckpoint(path string) error {
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove checkpoint: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,47 @@
+package migrate
+
+import (
+	"context"
+
+	"github.com/solumD/tasks-service/internal/model"
+)
+
+// Repo хранилище задач, из которого и в которое можно переносить задачи
+// с сохранением ID, версий и счетчика ID
+type Repo interface {
+	GetAllTasks(ctx context.Context) ([]*model.Task, error)
+	GetDeletedTasks(ctx context.Context) ([]*model.Task, error)
+	// NextID возвращает ID, который получит следующая созданная задача
+	NextID(ctx context.Context) (int, error)
+	// ImportTasks записывает задачи как есть, заменяя задачи с теми же ID, и сдвигает
+	// счетчик так, чтобы следующая задача получила ID не меньше nextID. Повторный
+	// импорт тех же задач ничего не меняет
+	ImportTasks(ctx context.Context, tasks []*model.Task, nextID int) error
+}
+
+// ProjectRepo хранилище проектов, которое умеет отдавать и заменять все проекты
+// целиком с сохранением ID, версий и счетчика ID
+type ProjectRepo interface {
+	// DumpProjects возвращает все проекты и ID следующего проекта на один момент времени
+	DumpProjects(ctx context.Context) ([]*model.Project, int, error)
+	// ReplaceProjects заменяет все проекты на projects. Счетчик ID не уменьшается,
+	// а следующий проект получит ID не меньше nextID
+	ReplaceProjects(ctx context.Context, projects []*model.Project, nextID int) error
+}
+
+// CommentRepo хранилище комментариев, которое умеет отдавать и заменять все
+// комментарии целиком с сохранением ID, авторов, времени и счетчика ID
+type CommentRepo interface {
+	// DumpComments возвращает все комментарии и ID следующего комментария на один момент времени
+	DumpComments(ctx context.Context) ([]*model.Comment, int, error)
+	// ReplaceComments заменяет все комментарии на comments. Счетчик ID не уменьшается,
+	// а следующий комментарий получит ID не меньше nextID
+	ReplaceComments(ctx context.Context, comments []*model.Comment, nextID int) error
+}
+
+// Storage хранилище, из которого и в которое переносятся задачи, их проекты и комментарии
+type Storage struct {
+	Tasks    Repo
+	Projects ProjectRepo
+	Comments CommentRepo
+}
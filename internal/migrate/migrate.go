@@ -0,0 +1,405 @@
+package migrate
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/solumD/tasks-service/internal/model"
+	"github.com/solumD/tasks-service/pkg/logger"
+)
+
+var (
+	ErrTargetNotEmpty = errors.New("target storage is not empty")
+	ErrSourceChanged  = errors.New("source storage changed since the interrupted migration")
+	ErrVerifyFailed   = errors.New("migrated data does not match source")
+)
+
+// Result итог переноса
+type Result struct {
+	// Tasks количество задач в источнике, включая корзину
+	Tasks int
+	// Imported количество задач, записанных этим запуском
+	Imported int
+	// Resumed перенос продолжен с контрольной точки
+	Resumed  bool
+	NextID   int
+	Checksum string
+	// Projects количество проектов в источнике
+	Projects         int
+	NextProjectID    int
+	ProjectsChecksum string
+	// Comments количество комментариев в источнике
+	Comments         int
+	NextCommentID    int
+	CommentsChecksum string
+}
+
+type migrator struct {
+	src            Storage
+	dst            Storage
+	batchSize      int
+	checkpointPath string
+	log            *slog.Logger
+}
+
+// New создает перенос задач из src в dst пачками по batchSize. После каждой пачки
+// прогресс сохраняется в файл checkpointPath, по которому прерванный перенос продолжается.
+// Проекты и комментарии переносятся целиком при каждом запуске
+func New(src, dst Storage, batchSize int, checkpointPath string, log *slog.Logger) (*migrator, error) {
+	if batchSize <= 0 {
+		return nil, fmt.Errorf("batch size must be positive, got %d", batchSize)
+	}
+
+	return &migrator{
+		src:            src,
+		dst:            dst,
+		batchSize:      batchSize,
+		checkpointPath: checkpointPath,
+		log:            log,
+	}, nil
+}
+
+// Run переносит все задачи источника, включая корзину, все проекты, комментарии
+// и счетчики ID, а затем сверяет количество и контрольные суммы задач, проектов
+// и комментариев в обоих хранилищах. Во время переноса источник не должен меняться
+func (m *migrator) Run(ctx context.Context) (*Result, error) {
+	const fn = "migrator.Run"
+
+	log := m.log.With(logger.String("fn", fn))
+
+	tasks, err := allTasks(ctx, m.src.Tasks)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read source: %w", err)
+	}
+
+	nextID, err := m.src.Tasks.NextID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read source next id: %w", err)
+	}
+
+	projects, nextProjectID, err := m.src.Projects.DumpProjects(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read source projects: %w", err)
+	}
+
+	comments, nextCommentID, err := m.src.Comments.DumpComments(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read source comments: %w", err)
+	}
+
+	res := &Result{
+		Tasks:            len(tasks),
+		NextID:           nextID,
+		Checksum:         Checksum(tasks),
+		Projects:         len(projects),
+		NextProjectID:    nextProjectID,
+		ProjectsChecksum: ProjectChecksum(projects),
+		Comments:         len(comments),
+		NextCommentID:    nextCommentID,
+		CommentsChecksum: CommentChecksum(comments),
+	}
+
+	cp, err := loadCheckpoint(m.checkpointPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if cp != nil {
+		if cp.SourceChecksum != res.Checksum || cp.SourceTasks != res.Tasks {
+			return nil, ErrSourceChanged
+		}
+
+		res.Resumed = true
+		log.Info("resuming migration", logger.Int("last id", cp.LastID))
+	} else {
+		existing, err := allTasks(ctx, m.dst.Tasks)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read target: %w", err)
+		}
+
+		existingProjects, _, err := m.dst.Projects.DumpProjects(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read target projects: %w", err)
+		}
+
+		existingComments, _, err := m.dst.Comments.DumpComments(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read target comments: %w", err)
+		}
+
+		if len(existing) > 0 || len(existingProjects) > 0 || len(existingComments) > 0 {
+			return nil, ErrTargetNotEmpty
+		}
+
+		// контрольная точка пишется до первой записи, чтобы после сбоя
+		// частично заполненное хранилище назначения не считалось чужим
+		cp = &checkpoint{SourceChecksum: res.Checksum, SourceTasks: res.Tasks}
+		if err := saveCheckpoint(m.checkpointPath, cp); err != nil {
+			return nil, err
+		}
+	}
+
+	// проектов немного, поэтому они переносятся целиком и после сбоя
+	// переносятся заново, так что задачи не ссылаются на отсутствующие проекты
+	if err := m.dst.Projects.ReplaceProjects(ctx, projects, nextProjectID); err != nil {
+		log.Error("failed to import projects", logger.Error(err))
+		return nil, fmt.Errorf("failed to import projects: %w", err)
+	}
+
+	log.Info("imported projects", logger.Int("projects", len(projects)), logger.Int("next project id", nextProjectID))
+
+	// комментарии ссылаются на задачи только по ID, поэтому их можно перенести
+	// до задач; как и проекты, после сбоя они переносятся заново
+	if err := m.dst.Comments.ReplaceComments(ctx, comments, nextCommentID); err != nil {
+		log.Error("failed to import comments", logger.Error(err))
+		return nil, fmt.Errorf("failed to import comments: %w", err)
+	}
+
+	log.Info("imported comments", logger.Int("comments", len(comments)), logger.Int("next comment id", nextCommentID))
+
+	pending := tasks[sort.Search(len(tasks), func(i int) bool {
+		return tasks[i].ID > cp.LastID
+	}):]
+
+	// пустой источник тоже переносится, чтобы сдвинуть счетчик ID
+	for len(pending) > 0 || !cp.Started {
+		batch := pending[:min(m.batchSize, len(pending))]
+		pending = pending[len(batch):]
+
+		if err := m.dst.Tasks.ImportTasks(ctx, batch, nextID); err != nil {
+			log.Error("failed to import batch", logger.Error(err))
+			return nil, fmt.Errorf("failed to import tasks: %w", err)
+		}
+
+		cp.Started = true
+		if len(batch) > 0 {
+			cp.LastID = batch[len(batch)-1].ID
+		}
+
+		if err := saveCheckpoint(m.checkpointPath, cp); err != nil {
+			return nil, err
+		}
+
+		res.Imported += len(batch)
+		log.Info("imported batch", logger.Int("tasks", len(batch)), logger.Int("last id", cp.LastID))
+	}
+
+	if err := m.verify(ctx, res); err != nil {
+		return nil, err
+	}
+
+	if err := removeCheckpoint(m.checkpointPath); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// verify сверяет задачи, проекты, комментарии и счетчики ID хранилища назначения с источником
+func (m *migrator) verify(ctx context.Context, res *Result) error {
+	tasks, err := allTasks(ctx, m.dst.Tasks)
+	if err != nil {
+		return fmt.Errorf("failed to read target: %w", err)
+	}
+
+	if len(tasks) != res.Tasks {
+		return fmt.Errorf("%w: %d tasks in source, %d in target", ErrVerifyFailed, res.Tasks, len(tasks))
+	}
+
+	if checksum := Checksum(tasks); checksum != res.Checksum {
+		return fmt.Errorf("%w: checksum %s in source, %s in target", ErrVerifyFailed, res.Checksum, checksum)
+	}
+
+	nextID, err := m.dst.Tasks.NextID(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to read target next id: %w", err)
+	}
+
+	if nextID < res.NextID {
+		return fmt.Errorf("%w: next id %d in source, %d in target", ErrVerifyFailed, res.NextID, nextID)
+	}
+
+	projects, nextProjectID, err := m.dst.Projects.DumpProjects(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to read target projects: %w", err)
+	}
+
+	if len(projects) != res.Projects {
+		return fmt.Errorf("%w: %d projects in source, %d in target", ErrVerifyFailed, res.Projects, len(projects))
+	}
+
+	if checksum := ProjectChecksum(projects); checksum != res.ProjectsChecksum {
+		return fmt.Errorf("%w: projects checksum %s in source, %s in target", ErrVerifyFailed, res.ProjectsChecksum, checksum)
+	}
+
+	if nextProjectID < res.NextProjectID {
+		return fmt.Errorf("%w: next project id %d in source, %d in target", ErrVerifyFailed, res.NextProjectID, nextProjectID)
+	}
+
+	comments, nextCommentID, err := m.dst.Comments.DumpComments(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to read target comments: %w", err)
+	}
+
+	if len(comments) != res.Comments {
+		return fmt.Errorf("%w: %d comments in source, %d in target", ErrVerifyFailed, res.Comments, len(comments))
+	}
+
+	if checksum := CommentChecksum(comments); checksum != res.CommentsChecksum {
+		return fmt.Errorf("%w: comments checksum %s in source, %s in target", ErrVerifyFailed, res.CommentsChecksum, checksum)
+	}
+
+	if nextCommentID < res.NextCommentID {
+		return fmt.Errorf("%w: next comment id %d in source, %d in target", ErrVerifyFailed, res.NextCommentID, nextCommentID)
+	}
+
+	return nil
+}
+
+// allTasks возвращает задачи хранилища вместе с корзиной, отсортированные по ID
+func allTasks(ctx context.Context, repo Repo) ([]*model.Task, error) {
+	tasks, err := repo.GetAllTasks(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	trash, err := repo.GetDeletedTasks(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks = append(tasks, trash...)
+
+	sort.Slice(tasks, func(i, j int) bool {
+		return tasks[i].ID < tasks[j].ID
+	})
+
+	return tasks, nil
+}
+
+// Checksum возвращает SHA-256 всех полей задач, отсортированных по ID.
+// Время удаления учитывается в UTC, срок выполнения - со смещением часового пояса
+func Checksum(tasks []*model.Task) string {
+	sorted := append([]*model.Task(nil), tasks...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].ID < sorted[j].ID
+	})
+
+	h := sha256.New()
+
+	for _, task := range sorted {
+		deletedAt := "-"
+		if task.DeletedAt != nil {
+			deletedAt = task.DeletedAt.UTC().Format(time.RFC3339Nano)
+		}
+
+		fmt.Fprintf(h, "%d\t%q\t%q\t%t\t%d\t%s",
+			task.ID, task.Title, task.Description, task.Done, task.Version, deletedAt)
+
+		// срок добавляется только при наличии, поэтому контрольные суммы
+		// задач без срока не изменились
+		if task.DueAt != nil {
+			fmt.Fprintf(h, "\t%s", task.DueAt.Format(time.RFC3339Nano))
+		}
+
+		// так же добавляется приоритет. Пустой приоритет равен PriorityNone,
+		// поэтому хранилища, записавшие его по-разному, дают одну сумму
+		if task.Priority.String() != string(model.PriorityNone) {
+			fmt.Fprintf(h, "\tpriority=%s", task.Priority)
+		}
+
+		// и метки: они не содержат запятых, поэтому перечисляются через запятую
+		if len(task.Tags) > 0 {
+			fmt.Fprintf(h, "\ttags=%s", strings.Join(task.Tags, ","))
+		}
+
+		// и родительская задача, если задача является подзадачей
+		if task.ParentID != 0 {
+			fmt.Fprintf(h, "\tparent=%d", task.ParentID)
+		}
+
+		// и зависимости
+		if len(task.DependsOn) > 0 {
+			fmt.Fprintf(h, "\tdepends=%s", strings.Trim(fmt.Sprint(task.DependsOn), "[]"))
+		}
+
+		// и статус, если он не выводится из признака выполнения: хранилища,
+		// записавшие пустой и выведенный статус, дают одну сумму
+		if status := task.CurrentStatus(); status != model.StatusFromDone(task.Done) {
+			fmt.Fprintf(h, "\tstatus=%s", status)
+		}
+
+		// и проект
+		if task.ProjectID != 0 {
+			fmt.Fprintf(h, "\tproject=%d", task.ProjectID)
+		}
+
+		// и правило повторения
+		if task.Recurrence != "" {
+			fmt.Fprintf(h, "\trecurrence=%s", task.Recurrence)
+		}
+
+		// и время создания, если оно известно
+		if !task.CreatedAt.IsZero() {
+			fmt.Fprintf(h, "\tcreated=%s", task.CreatedAt.UTC().Format(time.RFC3339Nano))
+		}
+
+		fmt.Fprint(h, "\n")
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// ProjectChecksum возвращает SHA-256 всех полей проектов, отсортированных по ID.
+// Время архивации учитывается в UTC
+func ProjectChecksum(projects []*model.Project) string {
+	sorted := append([]*model.Project(nil), projects...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].ID < sorted[j].ID
+	})
+
+	h := sha256.New()
+
+	for _, project := range sorted {
+		archivedAt := "-"
+		if project.ArchivedAt != nil {
+			archivedAt = project.ArchivedAt.UTC().Format(time.RFC3339Nano)
+		}
+
+		fmt.Fprintf(h, "%d\t%q\t%q\t%d\t%s\n",
+			project.ID, project.Name, project.Description, project.Version, archivedAt)
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// CommentChecksum возвращает SHA-256 всех полей комментариев, отсортированных по ID.
+// Время создания и изменения учитывается в UTC
+func CommentChecksum(comments []*model.Comment) string {
+	sorted := append([]*model.Comment(nil), comments...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].ID < sorted[j].ID
+	})
+
+	h := sha256.New()
+
+	for _, comment := range sorted {
+		editedAt := "-"
+		if comment.EditedAt != nil {
+			editedAt = comment.EditedAt.UTC().Format(time.RFC3339Nano)
+		}
+
+		fmt.Fprintf(h, "%d\t%d\t%d\t%q\t%q\t%s\t%s\n",
+			comment.ID, comment.TaskID, comment.ParentID, comment.Author, comment.Text,
+			comment.CreatedAt.UTC().Format(time.RFC3339Nano), editedAt)
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,437 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/solumD/tasks-service/internal/migrate"
+	"github.com/solumD/tasks-service/internal/model"
+	"github.com/solumD/tasks-service/internal/repository/eventsourced"
+	filerepo "github.com/solumD/tasks-service/internal/repository/file"
+	inmemory "github.com/solumD/tasks-service/internal/repository/in_memory"
+	sqlrepo "github.com/solumD/tasks-service/internal/repository/sql"
+	"github.com/solumD/tasks-service/pkg/logger"
+
+	_ "modernc.org/sqlite"
+)
+
+// repo хранилище, которое умеет и переносить задачи, и создавать новые
+type repo interface {
+	migrate.Repo
+	CreateTask(ctx context.Context, task *model.Task) (int, error)
+	UpdateTask(ctx context.Context, task *model.Task) error
+}
+
+// projectRepo хранилище, которое умеет и переносить проекты, и создавать новые
+type projectRepo interface {
+	migrate.ProjectRepo
+	CreateProject(ctx context.Context, project *model.Project) (int, error)
+	DeleteProject(ctx context.Context, id int, version int) error
+}
+
+// commentRepo хранилище, которое умеет и переносить комментарии, и создавать новые
+type commentRepo interface {
+	migrate.CommentRepo
+	CreateComment(ctx context.Context, comment *model.Comment) (int, error)
+	DeleteComment(ctx context.Context, id int) (int, error)
+}
+
+// storage хранилища задач, проектов и комментариев
+type storage struct {
+	tasks    repo
+	projects projectRepo
+	comments commentRepo
+}
+
+func (s storage) migrate() migrate.Storage {
+	return migrate.Storage{Tasks: s.tasks, Projects: s.projects, Comments: s.comments}
+}
+
+// newMemoryStorage возвращает пустые хранилища в памяти
+func newMemoryStorage() storage {
+	return storage{tasks: inmemory.NewTaskRepo(), projects: inmemory.NewProjectRepo(), comments: inmemory.NewCommentRepo()}
+}
+
+// openProjects открывает файл проектов в директории dir
+func openProjects(t *testing.T, dir string) projectRepo {
+	r, err := filerepo.NewProjectRepo(filepath.Join(dir, filerepo.ProjectsFileName))
+	if err != nil {
+		t.Fatalf("failed to open project repo: %v", err)
+	}
+
+	return r
+}
+
+// openComments открывает журнал комментариев в директории dir
+func openComments(t *testing.T, dir string) commentRepo {
+	r, err := filerepo.NewCommentRepo(filepath.Join(dir, filerepo.CommentsFileName))
+	if err != nil {
+		t.Fatalf("failed to open comment repo: %v", err)
+	}
+	t.Cleanup(func() { r.Close() })
+
+	return r
+}
+
+// targets хранилища назначения. open для постоянных хранилищ повторно
+// открывает данные в той же директории
+var targets = []struct {
+	name       string
+	persistent bool
+	open       func(t *testing.T, dir string) storage
+}{
+	{
+		name: "in_memory",
+		open: func(t *testing.T, _ string) storage {
+			return newMemoryStorage()
+		},
+	},
+	{
+		name: "in_memory_sharded",
+		open: func(t *testing.T, _ string) storage {
+			r, _ := inmemory.NewShardedTaskRepo(4)
+			return storage{tasks: r, projects: inmemory.NewProjectRepo(), comments: inmemory.NewCommentRepo()}
+		},
+	},
+	{
+		name:       "file",
+		persistent: true,
+		open: func(t *testing.T, dir string) storage {
+			r, err := filerepo.NewTaskRepo(dir, 2)
+			if err != nil {
+				t.Fatalf("failed to open file repo: %v", err)
+			}
+			t.Cleanup(func() { r.Close() })
+
+			return storage{tasks: r, projects: openProjects(t, dir), comments: openComments(t, dir)}
+		},
+	},
+	{
+		name:       "sql",
+		persistent: true,
+		open: func(t *testing.T, dir string) storage {
+			dsn := "file:" + filepath.Join(dir, "tasks.db") + "?_pragma=busy_timeout(5000)"
+
+			r, err := sqlrepo.NewTaskRepo(context.Background(), "sqlite", dsn)
+			if err != nil {
+				t.Fatalf("failed to open sql repo: %v", err)
+			}
+			t.Cleanup(func() { r.Close() })
+
+			return storage{tasks: r, projects: r.ProjectRepo(), comments: r.CommentRepo()}
+		},
+	},
+	{
+		name:       "eventsourced",
+		persistent: true,
+		open: func(t *testing.T, dir string) storage {
+			store, err := eventsourced.NewFileStore(filepath.Join(dir, "events.log"))
+			if err != nil {
+				t.Fatalf("failed to open event store: %v", err)
+			}
+
+			r, err := eventsourced.NewTaskRepo(context.Background(), store)
+			if err != nil {
+				t.Fatalf("failed to open eventsourced repo: %v", err)
+			}
+			t.Cleanup(func() { r.Close() })
+
+			return storage{tasks: r, projects: openProjects(t, dir), comments: openComments(t, dir)}
+		},
+	},
+}
+
+// newSource возвращает хранилище с обновленными задачами, задачей в корзине,
+// окончательно удаленной задачей с наибольшим ID, задачей в проекте,
+// удаленным проектом с наибольшим ID, комментарием с ответом к задаче
+// и удаленным комментарием с наибольшим ID
+func newSource(t *testing.T) storage {
+	ctx := context.Background()
+	src := inmemory.NewTaskRepo()
+	projects := inmemory.NewProjectRepo()
+	comments := inmemory.NewCommentRepo()
+
+	for _, name := range []string{"Home", "Old"} {
+		if _, err := projects.CreateProject(ctx, &model.Project{Name: name}); err != nil {
+			t.Fatalf("failed to create project: %v", err)
+		}
+	}
+
+	if err := projects.DeleteProject(ctx, 2, 0); err != nil {
+		t.Fatalf("failed to delete project: %v", err)
+	}
+
+	for _, title := range []string{"Task1", "Task2", "Task3", "Task4", "Task5"} {
+		task := &model.Task{Title: title, Description: title + " desc"}
+		if title == "Task1" {
+			task.ProjectID = 1
+		}
+
+		if _, err := src.CreateTask(ctx, task); err != nil {
+			t.Fatalf("failed to create task: %v", err)
+		}
+	}
+
+	if err := src.UpdateTask(ctx, &model.Task{ID: 2, Title: "Task2 updated", Done: true}); err != nil {
+		t.Fatalf("failed to update task: %v", err)
+	}
+
+	if _, err := src.DeleteTask(ctx, 3, 0); err != nil {
+		t.Fatalf("failed to delete task: %v", err)
+	}
+
+	if _, err := src.DeleteTask(ctx, 5, 0); err != nil {
+		t.Fatalf("failed to delete task: %v", err)
+	}
+
+	if _, err := src.PurgeDeletedTasks(ctx, time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("failed to purge trash: %v", err)
+	}
+
+	if _, err := src.DeleteTask(ctx, 4, 0); err != nil {
+		t.Fatalf("failed to delete task: %v", err)
+	}
+
+	editedAt := time.Date(2026, 3, 1, 13, 0, 0, 0, time.UTC)
+	for _, comment := range []*model.Comment{
+		{TaskID: 1, Author: "alice", Text: "hello", EditedAt: &editedAt},
+		{TaskID: 1, ParentID: 1, Author: "bob", Text: "reply"},
+		{TaskID: 2, Author: "alice", Text: "removed"},
+	} {
+		comment.CreatedAt = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+		if _, err := comments.CreateComment(ctx, comment); err != nil {
+			t.Fatalf("failed to create comment: %v", err)
+		}
+	}
+
+	if _, err := comments.DeleteComment(ctx, 3); err != nil {
+		t.Fatalf("failed to delete comment: %v", err)
+	}
+
+	return storage{tasks: src, projects: projects, comments: comments}
+}
+
+func checksumOf(t *testing.T, r migrate.Repo) string {
+	t.Helper()
+
+	tasks, err := r.GetAllTasks(context.Background())
+	if err != nil {
+		t.Fatalf("failed to get tasks: %v", err)
+	}
+
+	trash, err := r.GetDeletedTasks(context.Background())
+	if err != nil {
+		t.Fatalf("failed to get trash: %v", err)
+	}
+
+	return migrate.Checksum(append(tasks, trash...))
+}
+
+func TestMigrate(t *testing.T) {
+	log := logger.NewMockLogger()
+
+	for _, target := range targets {
+		t.Run(target.name, func(t *testing.T) {
+			ctx := context.Background()
+			dir := t.TempDir()
+
+			src := newSource(t)
+			dst := target.open(t, dir)
+
+			m, err := migrate.New(src.migrate(), dst.migrate(), 2, filepath.Join(dir, "checkpoint.json"), log)
+			if err != nil {
+				t.Fatalf("failed to init migration: %v", err)
+			}
+
+			res, err := m.Run(ctx)
+			if err != nil {
+				t.Fatalf("failed to migrate: %v", err)
+			}
+
+			if res.Tasks != 3 || res.Imported != 3 || res.Resumed || res.NextID != 6 || res.Projects != 1 || res.NextProjectID != 3 ||
+				res.Comments != 2 || res.NextCommentID != 4 {
+				t.Fatalf("unexpected result %+v", res)
+			}
+
+			if _, err := os.Stat(filepath.Join(dir, "checkpoint.json")); !errors.Is(err, os.ErrNotExist) {
+				t.Fatalf("expected checkpoint to be removed after success, got %v", err)
+			}
+
+			if target.persistent {
+				dst = target.open(t, dir)
+			}
+
+			if checksum := checksumOf(t, dst.tasks); checksum != res.Checksum {
+				t.Fatalf("expected target checksum %s, got %s", res.Checksum, checksum)
+			}
+
+			// ID окончательно удаленной задачи не выдается повторно
+			id, err := dst.tasks.CreateTask(ctx, &model.Task{Title: "Task6"})
+			if err != nil || id != 6 {
+				t.Fatalf("expected next id 6 in target, got %d (err %v)", id, err)
+			}
+
+			// проект задачи перенесен, а новый проект не получает ID перенесенного
+			// или удаленного проекта и не забирает себе задачу
+			projects, _, err := dst.projects.DumpProjects(ctx)
+			if err != nil || len(projects) != 1 || projects[0].ID != 1 || projects[0].Name != "Home" {
+				t.Fatalf("expected project Home in target, got %v (err %v)", projects, err)
+			}
+
+			projectID, err := dst.projects.CreateProject(ctx, &model.Project{Name: "New"})
+			if err != nil || projectID != 3 {
+				t.Fatalf("expected next project id 3 in target, got %d (err %v)", projectID, err)
+			}
+
+			tasks, err := dst.tasks.GetAllTasks(ctx)
+			if err != nil {
+				t.Fatalf("failed to get tasks: %v", err)
+			}
+
+			for _, task := range tasks {
+				if want := map[int]int{1: 1}[task.ID]; task.ProjectID != want {
+					t.Fatalf("expected task %d in project %d, got %d", task.ID, want, task.ProjectID)
+				}
+			}
+
+			// комментарии перенесены с авторами и временем, а ID удаленного
+			// комментария не выдается повторно
+			comments, _, err := dst.comments.DumpComments(ctx)
+			if err != nil || migrate.CommentChecksum(comments) != res.CommentsChecksum {
+				t.Fatalf("expected target comments checksum %s, got %v (err %v)", res.CommentsChecksum, comments, err)
+			}
+
+			commentID, err := dst.comments.CreateComment(ctx, &model.Comment{TaskID: 1, Author: "alice", Text: "new", CreatedAt: time.Now()})
+			if err != nil || commentID != 4 {
+				t.Fatalf("expected next comment id 4 in target, got %d (err %v)", commentID, err)
+			}
+		})
+	}
+}
+
+// failingRepo хранилище, которое падает на импорте после limit успешных пачек
+type failingRepo struct {
+	migrate.Repo
+	limit int
+}
+
+var errImport = errors.New("import failed")
+
+func (r *failingRepo) ImportTasks(ctx context.Context, tasks []*model.Task, nextID int) error {
+	if r.limit == 0 {
+		return errImport
+	}
+
+	r.limit--
+
+	return r.Repo.ImportTasks(ctx, tasks, nextID)
+}
+
+func TestMigrateResume(t *testing.T) {
+	ctx := context.Background()
+	log := logger.NewMockLogger()
+
+	tests := []struct {
+		name    string
+		limit   int
+		resumed int
+	}{
+		{name: "before first batch", limit: 0, resumed: 3},
+		{name: "after first batch", limit: 1, resumed: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkpointPath := filepath.Join(t.TempDir(), "checkpoint.json")
+			src := newSource(t)
+			dst := newMemoryStorage()
+
+			failing := migrate.Storage{Tasks: &failingRepo{Repo: dst.tasks, limit: tt.limit}, Projects: dst.projects, Comments: dst.comments}
+			m, _ := migrate.New(src.migrate(), failing, 2, checkpointPath, log)
+			if _, err := m.Run(ctx); !errors.Is(err, errImport) {
+				t.Fatalf("expected interrupted migration, got %v", err)
+			}
+
+			m, _ = migrate.New(src.migrate(), dst.migrate(), 2, checkpointPath, log)
+
+			res, err := m.Run(ctx)
+			if err != nil {
+				t.Fatalf("failed to resume migration: %v", err)
+			}
+
+			if !res.Resumed || res.Imported != tt.resumed {
+				t.Fatalf("expected resumed migration of %d tasks, got %+v", tt.resumed, res)
+			}
+
+			if checksum := checksumOf(t, dst.tasks); checksum != res.Checksum {
+				t.Fatalf("expected target checksum %s, got %s", res.Checksum, checksum)
+			}
+		})
+	}
+}
+
+func TestMigrateRefusesUnsafeStart(t *testing.T) {
+	ctx := context.Background()
+	log := logger.NewMockLogger()
+
+	t.Run("target not empty", func(t *testing.T) {
+		dst := newMemoryStorage()
+		if _, err := dst.tasks.CreateTask(ctx, &model.Task{Title: "Existing"}); err != nil {
+			t.Fatalf("failed to create task: %v", err)
+		}
+
+		m, _ := migrate.New(newSource(t).migrate(), dst.migrate(), 2, filepath.Join(t.TempDir(), "checkpoint.json"), log)
+		if _, err := m.Run(ctx); !errors.Is(err, migrate.ErrTargetNotEmpty) {
+			t.Fatalf("expected ErrTargetNotEmpty, got %v", err)
+		}
+	})
+
+	t.Run("target has comments", func(t *testing.T) {
+		dst := newMemoryStorage()
+		if _, err := dst.comments.CreateComment(ctx, &model.Comment{TaskID: 1, Author: "alice", Text: "Existing"}); err != nil {
+			t.Fatalf("failed to create comment: %v", err)
+		}
+
+		m, _ := migrate.New(newSource(t).migrate(), dst.migrate(), 2, filepath.Join(t.TempDir(), "checkpoint.json"), log)
+		if _, err := m.Run(ctx); !errors.Is(err, migrate.ErrTargetNotEmpty) {
+			t.Fatalf("expected ErrTargetNotEmpty, got %v", err)
+		}
+	})
+
+	t.Run("target has projects", func(t *testing.T) {
+		dst := newMemoryStorage()
+		if _, err := dst.projects.CreateProject(ctx, &model.Project{Name: "Existing"}); err != nil {
+			t.Fatalf("failed to create project: %v", err)
+		}
+
+		m, _ := migrate.New(newSource(t).migrate(), dst.migrate(), 2, filepath.Join(t.TempDir(), "checkpoint.json"), log)
+		if _, err := m.Run(ctx); !errors.Is(err, migrate.ErrTargetNotEmpty) {
+			t.Fatalf("expected ErrTargetNotEmpty, got %v", err)
+		}
+	})
+
+	t.Run("source changed", func(t *testing.T) {
+		checkpointPath := filepath.Join(t.TempDir(), "checkpoint.json")
+		src := newSource(t)
+		dst := newMemoryStorage()
+
+		failing := migrate.Storage{Tasks: &failingRepo{Repo: dst.tasks, limit: 1}, Projects: dst.projects, Comments: dst.comments}
+		m, _ := migrate.New(src.migrate(), failing, 2, checkpointPath, log)
+		if _, err := m.Run(ctx); !errors.Is(err, errImport) {
+			t.Fatalf("expected interrupted migration, got %v", err)
+		}
+
+		if err := src.tasks.UpdateTask(ctx, &model.Task{ID: 1, Title: "Changed"}); err != nil {
+			t.Fatalf("failed to update task: %v", err)
+		}
+
+		m, _ = migrate.New(src.migrate(), dst.migrate(), 2, checkpointPath, log)
+		if _, err := m.Run(ctx); !errors.Is(err, migrate.ErrSourceChanged) {
+			t.Fatalf("expected ErrSourceChanged, got %v", err)
+		}
+	})
+}
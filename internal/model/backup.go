@@ -0,0 +1,16 @@
+package model
+
+import "time"
+
+// BackupInfo сведения о резервной копии задач
+type BackupInfo struct {
+	// Name имя файла копии в директории резервных копий
+	Name      string
+	CreatedAt time.Time
+	// Tasks количество задач в копии, включая корзину
+	Tasks    int
+	NextID   int
+	Checksum string
+	// Projects количество проектов в копии
+	Projects int
+}
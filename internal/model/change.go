@@ -0,0 +1,40 @@
+package model
+
+import "time"
+
+// ChangeType вид изменения задачи в потоке изменений
+type ChangeType string
+
+const (
+	ChangeCreated ChangeType = "created"
+	ChangeUpdated ChangeType = "updated"
+	ChangeDeleted ChangeType = "deleted"
+	// ChangeReset все задачи заменены, например восстановлением из резервной копии,
+	// или часть событий пропущена. Событие не относится к задаче, потребитель
+	// заново загружает все задачи
+	ChangeReset ChangeType = "reset"
+)
+
+// ChangeEvent событие потока изменений задач. Событие содержит состояние задачи
+// после изменения, поэтому потребителю не нужно запрашивать задачу отдельно
+type ChangeEvent struct {
+	// Seq номер события, события нумеруются подряд начиная с 1 и не переиспользуются
+	Seq    int
+	Type   ChangeType
+	TaskID int
+	// Author автор изменения, пустая строка - неизвестен
+	Author    string
+	CreatedAt time.Time
+	Task      Task
+	// Position номер изменения в кластере Raft, одинаковый на всех узлах, 0 - изменение
+	// не из кластера. По нему узел пропускает уже записанные изменения
+	Position int `json:",omitempty"`
+}
+
+// ChangeBatch часть потока изменений
+type ChangeBatch struct {
+	// First номер самого старого хранимого события, Head номер последнего события
+	First  int
+	Head   int
+	Events []*ChangeEvent
+}
@@ -0,0 +1,49 @@
+package model
+
+import "time"
+
+// Comment комментарий к задаче
+type Comment struct {
+	ID     int
+	TaskID int
+	// ParentID комментарий, на который отвечает комментарий, 0 - комментарий верхнего уровня.
+	// Отвечать можно только на комментарии верхнего уровня
+	ParentID int
+	// Author автор комментария, только он может изменить или удалить комментарий
+	Author    string
+	Text      string
+	CreatedAt time.Time
+	// EditedAt время последнего изменения текста, nil - текст не менялся
+	EditedAt *time.Time
+}
+
+// CommentThread комментарий верхнего уровня с ответами на него по возрастанию ID
+type CommentThread struct {
+	Comment *Comment
+	Replies []*Comment
+}
+
+// CommentPage страница комментариев задачи
+type CommentPage struct {
+	Threads []*CommentThread
+	// Next ID последнего комментария верхнего уровня на странице, после которого
+	// начинается следующая страница, 0 - страница последняя
+	Next int
+}
+
+// Clone возвращает глубокую копию комментария, не разделяющую с ним память
+func (c *Comment) Clone() *Comment {
+	clone := *c
+
+	if c.EditedAt != nil {
+		editedAt := *c.EditedAt
+		clone.EditedAt = &editedAt
+	}
+
+	return &clone
+}
+
+// IsReply сообщает, является ли комментарий ответом на другой комментарий
+func (c *Comment) IsReply() bool {
+	return c.ParentID != 0
+}
@@ -0,0 +1,50 @@
+package model
+
+import "time"
+
+// Project проект, в который собираются задачи
+type Project struct {
+	ID          int
+	Name        string
+	Description string
+	// ArchivedAt время архивации проекта, nil - проект активен. В архивный проект
+	// нельзя добавлять задачи
+	ArchivedAt *time.Time
+	// Counts количество задач проекта. Вычисляется юзкейсом при чтении
+	// и в хранилище не записывается
+	Counts ProjectCounts
+	// Version увеличивается хранилищем при каждом изменении проекта.
+	// В запросе на изменение это ожидаемая текущая версия, 0 - без проверки
+	Version int
+}
+
+// ProjectCounts количество задач проекта не из корзины
+type ProjectCounts struct {
+	Total int
+	// ByStatus количество задач в каждом статусе, статусов без задач нет
+	ByStatus map[Status]int
+}
+
+// Clone возвращает глубокую копию проекта, не разделяющую с ним память
+func (p *Project) Clone() *Project {
+	clone := *p
+
+	if p.ArchivedAt != nil {
+		archivedAt := *p.ArchivedAt
+		clone.ArchivedAt = &archivedAt
+	}
+
+	if p.Counts.ByStatus != nil {
+		clone.Counts.ByStatus = make(map[Status]int, len(p.Counts.ByStatus))
+		for status, count := range p.Counts.ByStatus {
+			clone.Counts.ByStatus[status] = count
+		}
+	}
+
+	return &clone
+}
+
+// Archived сообщает, находится ли проект в архиве
+func (p *Project) Archived() bool {
+	return p.ArchivedAt != nil
+}
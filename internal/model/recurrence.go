@@ -0,0 +1,314 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Frequency частота повторения задачи
+type Frequency string
+
+const (
+	FrequencyDaily   Frequency = "DAILY"
+	FrequencyWeekly  Frequency = "WEEKLY"
+	FrequencyMonthly Frequency = "MONTHLY"
+)
+
+const (
+	// maxRecurrenceInterval наибольший шаг правила повторения
+	maxRecurrenceInterval = 1000
+	// maxRecurrencePeriods сколько периодов правила просматривается в поисках повторений:
+	// например, BYMONTHDAY=30 с INTERVAL=12 от февраля не дает повторений вовсе
+	maxRecurrencePeriods = 1000
+	// untilLayout формат UNTIL в UTC
+	untilLayout = "20060102T150405Z"
+	// untilDateLayout формат UNTIL без времени, повторения допускаются до конца дня по UTC
+	untilDateLayout = "20060102"
+)
+
+// weekdayNames названия дней недели в BYDAY
+var weekdayNames = map[string]time.Weekday{
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+	"SU": time.Sunday,
+}
+
+// RecurrenceRule правило повторения задачи, подмножество RRULE из RFC 5545:
+// FREQ (DAILY, WEEKLY или MONTHLY), INTERVAL, BYDAY (только с WEEKLY и без номеров),
+// BYMONTHDAY (только с MONTHLY), UNTIL и COUNT. Первое повторение - срок задачи,
+// недели начинаются с понедельника
+type RecurrenceRule struct {
+	Freq Frequency
+	// Interval шаг в днях, неделях или месяцах, не меньше 1
+	Interval int
+	// ByDay дни недели по порядку, начиная с понедельника
+	ByDay []time.Weekday
+	// ByMonthDay дни месяца по возрастанию, отрицательные считаются от конца месяца
+	ByMonthDay []int
+	// Until последний допустимый момент повторения, нулевое значение не ограничивает
+	Until time.Time
+	// Count сколько всего повторений, включая первое, 0 - без ограничения
+	Count int
+}
+
+// ParseRecurrenceRule разбирает правило вида FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,FR;COUNT=10.
+// Префикс RRULE: и регистр не важны
+func ParseRecurrenceRule(value string) (*RecurrenceRule, error) {
+	value = strings.ToUpper(strings.TrimSpace(value))
+	value = strings.TrimPrefix(value, "RRULE:")
+
+	rule := &RecurrenceRule{Interval: 1}
+	seen := make(map[string]struct{})
+
+	for _, part := range strings.Split(value, ";") {
+		name, val, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok || name == "" || val == "" {
+			return nil, fmt.Errorf("invalid rule part %q", part)
+		}
+
+		if _, ok := seen[name]; ok {
+			return nil, fmt.Errorf("duplicate rule part %s", name)
+		}
+
+		seen[name] = struct{}{}
+
+		var err error
+
+		switch name {
+		case "FREQ":
+			rule.Freq = Frequency(val)
+			if rule.Freq != FrequencyDaily && rule.Freq != FrequencyWeekly && rule.Freq != FrequencyMonthly {
+				return nil, fmt.Errorf("unsupported frequency %s", val)
+			}
+		case "INTERVAL":
+			rule.Interval, err = strconv.Atoi(val)
+			if err != nil || rule.Interval < 1 || rule.Interval > maxRecurrenceInterval {
+				return nil, fmt.Errorf("interval must be from 1 to %d", maxRecurrenceInterval)
+			}
+		case "BYDAY":
+			rule.ByDay, err = parseByDay(val)
+		case "BYMONTHDAY":
+			rule.ByMonthDay, err = parseByMonthDay(val)
+		case "UNTIL":
+			rule.Until, err = parseUntil(val)
+		case "COUNT":
+			rule.Count, err = strconv.Atoi(val)
+			if err != nil || rule.Count < 1 {
+				return nil, errors.New("count must be positive")
+			}
+		default:
+			return nil, fmt.Errorf("unsupported rule part %s", name)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if rule.Freq == "" {
+		return nil, errors.New("frequency is required")
+	}
+
+	if len(rule.ByDay) > 0 && rule.Freq != FrequencyWeekly {
+		return nil, errors.New("BYDAY is supported only with weekly frequency")
+	}
+
+	if len(rule.ByMonthDay) > 0 && rule.Freq != FrequencyMonthly {
+		return nil, errors.New("BYMONTHDAY is supported only with monthly frequency")
+	}
+
+	if rule.Count > 0 && !rule.Until.IsZero() {
+		return nil, errors.New("COUNT and UNTIL cannot be used together")
+	}
+
+	return rule, nil
+}
+
+// parseByDay разбирает дни недели через запятую
+func parseByDay(value string) ([]time.Weekday, error) {
+	days := make([]time.Weekday, 0, 7)
+
+	for _, name := range strings.Split(value, ",") {
+		day, ok := weekdayNames[name]
+		if !ok {
+			return nil, fmt.Errorf("invalid weekday %q", name)
+		}
+
+		if !slices.Contains(days, day) {
+			days = append(days, day)
+		}
+	}
+
+	slices.SortFunc(days, func(a, b time.Weekday) int {
+		return weekdayIndex(a) - weekdayIndex(b)
+	})
+
+	return days, nil
+}
+
+// parseByMonthDay разбирает дни месяца через запятую: от 1 до 31 или от -31 до -1
+func parseByMonthDay(value string) ([]int, error) {
+	days := make([]int, 0)
+
+	for _, item := range strings.Split(value, ",") {
+		day, err := strconv.Atoi(item)
+		if err != nil || day == 0 || day < -31 || day > 31 {
+			return nil, fmt.Errorf("invalid month day %q", item)
+		}
+
+		days = append(days, day)
+	}
+
+	slices.Sort(days)
+
+	return slices.Compact(days), nil
+}
+
+// parseUntil разбирает UNTIL в UTC или дату без времени
+func parseUntil(value string) (time.Time, error) {
+	if until, err := time.Parse(untilLayout, value); err == nil {
+		return until, nil
+	}
+
+	date, err := time.Parse(untilDateLayout, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid until %q, expected %s or %s", value, untilLayout, untilDateLayout)
+	}
+
+	return date.Add(24*time.Hour - time.Second), nil
+}
+
+// String возвращает правило в каноническом виде: части в постоянном порядке,
+// INTERVAL=1 опускается, UNTIL записывается в UTC
+func (r *RecurrenceRule) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+
+	if len(r.ByDay) > 0 {
+		names := make([]string, 0, len(r.ByDay))
+		for _, day := range r.ByDay {
+			names = append(names, strings.ToUpper(day.String()[:2]))
+		}
+
+		parts = append(parts, "BYDAY="+strings.Join(names, ","))
+	}
+
+	if len(r.ByMonthDay) > 0 {
+		days := make([]string, 0, len(r.ByMonthDay))
+		for _, day := range r.ByMonthDay {
+			days = append(days, strconv.Itoa(day))
+		}
+
+		parts = append(parts, "BYMONTHDAY="+strings.Join(days, ","))
+	}
+
+	if !r.Until.IsZero() {
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format(untilLayout))
+	}
+
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+
+	return strings.Join(parts, ";")
+}
+
+// Occurrences возвращает до n повторений после первого повторения start по возрастанию.
+// Повторения сохраняют время суток и часовой пояс start
+func (r *RecurrenceRule) Occurrences(start time.Time, n int) []time.Time {
+	limit := n
+	if r.Count > 0 {
+		limit = min(limit, r.Count-1)
+	}
+
+	occurrences := make([]time.Time, 0, max(limit, 0))
+
+	for period := 0; period < maxRecurrencePeriods && len(occurrences) < limit; period++ {
+		for _, t := range r.period(start, period) {
+			if !t.After(start) {
+				continue
+			}
+
+			if !r.Until.IsZero() && t.After(r.Until) {
+				return occurrences
+			}
+
+			occurrences = append(occurrences, t)
+			if len(occurrences) == limit {
+				return occurrences
+			}
+		}
+	}
+
+	return occurrences
+}
+
+// period возвращает повторения-кандидаты period-го периода правила по возрастанию
+func (r *RecurrenceRule) period(start time.Time, period int) []time.Time {
+	step := period * r.Interval
+
+	switch r.Freq {
+	case FrequencyWeekly:
+		if len(r.ByDay) == 0 {
+			return []time.Time{start.AddDate(0, 0, 7*step)}
+		}
+
+		monday := start.AddDate(0, 0, 7*step-weekdayIndex(start.Weekday()))
+
+		days := make([]time.Time, 0, len(r.ByDay))
+		for _, day := range r.ByDay {
+			days = append(days, monday.AddDate(0, 0, weekdayIndex(day)))
+		}
+
+		return days
+	case FrequencyMonthly:
+		first := time.Date(start.Year(), start.Month()+time.Month(step), 1,
+			start.Hour(), start.Minute(), start.Second(), start.Nanosecond(), start.Location())
+		last := time.Date(first.Year(), first.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+
+		monthDays := r.ByMonthDay
+		if len(monthDays) == 0 {
+			monthDays = []int{start.Day()}
+		}
+
+		// дни, которых нет в месяце, пропускаются
+		numbers := make([]int, 0, len(monthDays))
+		for _, day := range monthDays {
+			if day < 0 {
+				day = last + day + 1
+			}
+
+			if day >= 1 && day <= last {
+				numbers = append(numbers, day)
+			}
+		}
+
+		slices.Sort(numbers)
+		numbers = slices.Compact(numbers)
+
+		days := make([]time.Time, 0, len(numbers))
+		for _, day := range numbers {
+			days = append(days, first.AddDate(0, 0, day-1))
+		}
+
+		return days
+	default:
+		return []time.Time{start.AddDate(0, 0, step)}
+	}
+}
+
+// weekdayIndex возвращает номер дня недели от 0 у понедельника до 6 у воскресенья
+func weekdayIndex(day time.Weekday) int {
+	return (int(day) + 6) % 7
+}
@@ -0,0 +1,81 @@
+package model
+
+import "time"
+
+// LogOp вид записи журнала репликации
+type LogOp string
+
+const (
+	// LogOpUpsert задача в Tasks записывается как есть, счетчик ID сдвигается до NextID
+	LogOpUpsert LogOp = "upsert"
+	// LogOpPurge из корзины удаляются задачи, удаленные раньше DeletedBefore
+	LogOpPurge LogOp = "purge"
+	// LogOpReplace все задачи заменяются задачами из Tasks (восстановление из резервной копии)
+	LogOpReplace LogOp = "replace"
+	// LogOpRevision добавляется ревизия Revision
+	LogOpRevision LogOp = "revision"
+)
+
+// LogEntry запись журнала репликации. Записи описывают состояние после изменения,
+// а не само изменение, поэтому повторное применение записи ничего не меняет
+type LogEntry struct {
+	// Seq номер записи, записи нумеруются подряд начиная с 1
+	Seq           int
+	Op            LogOp
+	CreatedAt     time.Time
+	Tasks         []*Task
+	NextID        int
+	DeletedBefore time.Time
+	Revision      *Revision
+}
+
+// LogBatch часть журнала репликации ведущего узла
+type LogBatch struct {
+	LeaderID string
+	// Head номер последней записи журнала на момент ответа
+	Head    int
+	Entries []*LogEntry
+}
+
+// ReplicationSnapshot все задачи ведущего узла на момент записи журнала с номером Seq
+type ReplicationSnapshot struct {
+	LeaderID string
+	Seq      int
+	NextID   int
+	Tasks    []*Task
+}
+
+// ReplicationRole роль узла в репликации
+type ReplicationRole string
+
+const (
+	ReplicationStandalone ReplicationRole = "standalone"
+	ReplicationLeader     ReplicationRole = "leader"
+	ReplicationFollower   ReplicationRole = "follower"
+)
+
+// ReplicationStatus состояние репликации узла
+type ReplicationStatus struct {
+	Role   ReplicationRole
+	NodeID string
+	// LeaderURL и LeaderID заполнены только у ведомого узла
+	LeaderURL string
+	LeaderID  string
+	// Applied номер последней примененной записи журнала
+	Applied int
+	// Head номер последней записи журнала ведущего узла, известный узлу
+	Head int
+	// Lag время, в течение которого данные ведомого узла могут отставать от ведущего
+	Lag           time.Duration
+	LastContactAt time.Time
+	LastError     string
+	// Followers ведомые узлы, которые недавно читали журнал ведущего узла
+	Followers []*FollowerStatus
+}
+
+// FollowerStatus состояние ведомого узла глазами ведущего
+type FollowerStatus struct {
+	ID         string
+	Applied    int
+	LastSeenAt time.Time
+}
@@ -0,0 +1,41 @@
+package model
+
+import "time"
+
+// RevisionAction изменение, после которого записана ревизия
+type RevisionAction string
+
+const (
+	RevisionCreated  RevisionAction = "created"
+	RevisionUpdated  RevisionAction = "updated"
+	RevisionDeleted  RevisionAction = "deleted"
+	RevisionRestored RevisionAction = "restored"
+	RevisionReverted RevisionAction = "reverted"
+)
+
+// Revision неизменяемый снимок задачи после одного изменения
+type Revision struct {
+	TaskID int
+	// Rev номер ревизии, совпадает с версией задачи после изменения
+	Rev    int
+	Action RevisionAction
+	// Author автор изменения, пустая строка - неизвестен
+	Author    string
+	CreatedAt time.Time
+	Task      Task
+}
+
+// FieldChange изменение одного поля задачи между двумя ревизиями
+type FieldChange struct {
+	Field string
+	From  any
+	To    any
+}
+
+// Clone возвращает глубокую копию ревизии
+func (r *Revision) Clone() *Revision {
+	clone := *r
+	clone.Task = *r.Task.Clone()
+
+	return &clone
+}
@@ -0,0 +1,125 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Status статус задачи в рабочем процессе
+type Status string
+
+const (
+	StatusTodo       Status = "todo"
+	StatusInProgress Status = "in_progress"
+	StatusReview     Status = "review"
+	StatusDone       Status = "done"
+	// StatusBlocked задачу отложили вручную, в отличие от Task.Blocked,
+	// который вычисляется по зависимостям
+	StatusBlocked   Status = "blocked"
+	StatusCancelled Status = "cancelled"
+)
+
+// statuses все статусы в порядке рабочего процесса
+var statuses = []Status{StatusTodo, StatusInProgress, StatusReview, StatusDone, StatusBlocked, StatusCancelled}
+
+// Valid сообщает, известен ли статус
+func (s Status) Valid() bool {
+	return slices.Contains(statuses, s)
+}
+
+// Closed сообщает, завершена ли работа над задачей: она выполнена или отменена
+func (s Status) Closed() bool {
+	return s == StatusDone || s == StatusCancelled
+}
+
+// StatusFromDone возвращает статус задачи, у которой известен только признак выполнения
+func StatusFromDone(done bool) Status {
+	if done {
+		return StatusDone
+	}
+
+	return StatusTodo
+}
+
+// CurrentStatus возвращает статус задачи. Пустой статус у задач, созданных
+// до появления статусов, выводится из Done
+func (t *Task) CurrentStatus() Status {
+	if t.Status == "" {
+		return StatusFromDone(t.Done)
+	}
+
+	return t.Status
+}
+
+// Workflow допустимые переходы между статусами: для каждого статуса - статусы,
+// в которые из него можно перейти. Статус без переходов конечный
+type Workflow map[Status][]Status
+
+// DefaultWorkflow возвращает рабочий процесс todo -> in_progress -> review -> done,
+// в котором задачу можно отложить, отменить и открыть заново. Из todo и in_progress
+// можно сразу перейти в done, чтобы признак done из запросов продолжал работать
+func DefaultWorkflow() Workflow {
+	return Workflow{
+		StatusTodo:       {StatusInProgress, StatusDone, StatusBlocked, StatusCancelled},
+		StatusInProgress: {StatusTodo, StatusReview, StatusDone, StatusBlocked, StatusCancelled},
+		StatusReview:     {StatusInProgress, StatusDone},
+		StatusDone:       {StatusTodo},
+		StatusBlocked:    {StatusTodo, StatusInProgress, StatusCancelled},
+		StatusCancelled:  {StatusTodo},
+	}
+}
+
+// ParseWorkflow разбирает переходы вида todo=in_progress,done;in_progress=review.
+// Статусы, не упомянутые слева, конечные
+func ParseWorkflow(value string) (Workflow, error) {
+	workflow := make(Workflow)
+
+	for _, part := range strings.Split(value, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		from, to, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid workflow part %q, expected from=to,...", part)
+		}
+
+		fromStatus := Status(strings.TrimSpace(from))
+		if !fromStatus.Valid() {
+			return nil, fmt.Errorf("unknown status %q", from)
+		}
+
+		if _, ok := workflow[fromStatus]; ok {
+			return nil, fmt.Errorf("duplicate workflow status %s", fromStatus)
+		}
+
+		targets := make([]Status, 0)
+		for _, item := range strings.Split(to, ",") {
+			status := Status(strings.TrimSpace(item))
+			if !status.Valid() {
+				return nil, fmt.Errorf("unknown status %q", item)
+			}
+
+			if status != fromStatus && !slices.Contains(targets, status) {
+				targets = append(targets, status)
+			}
+		}
+
+		workflow[fromStatus] = targets
+	}
+
+	if len(workflow) == 0 {
+		return nil, errors.New("workflow has no transitions")
+	}
+
+	return workflow, nil
+}
+
+// Allows сообщает, можно ли перейти из статуса from в статус to.
+// Переход в тот же статус разрешен всегда
+func (w Workflow) Allows(from, to Status) bool {
+	return from == to || slices.Contains(w[from], to)
+}
@@ -1,9 +1,209 @@
 package model
 
+import (
+	"sort"
+	"time"
+)
+
 // Task модель задачи
 type Task struct {
 	ID          int
 	Title       string
 	Description string
-	Done        bool
+	// Done задача выполнена, совпадает с CurrentStatus() == StatusDone
+	Done bool
+	// Status статус в рабочем процессе, пустой у задач, созданных до появления статусов
+	Status Status
+	// DueAt срок выполнения со смещением часового пояса из запроса, nil - срок не задан
+	DueAt *time.Time
+	// Priority приоритет задачи, пустая строка у задач, созданных до появления приоритетов,
+	// равна PriorityNone
+	Priority Priority
+	// Tags метки задачи без повторов по возрастанию
+	Tags []string
+	// ParentID ID родительской задачи, 0 - задача верхнего уровня
+	ParentID int
+	// DependsOn ID задач, которые должны быть выполнены раньше этой, без повторов по возрастанию
+	DependsOn []int
+	// ProjectID ID проекта задачи, 0 - задача вне проектов
+	ProjectID int
+	// Recurrence правило повторения в каноническом виде RecurrenceRule.String(),
+	// пустая строка - задача не повторяется
+	Recurrence string
+	// Blocked у задачи есть невыполненная зависимость. Вычисляется юзкейсом
+	// при чтении и в хранилище не записывается
+	Blocked bool
+	// CreatedAt время создания задачи в UTC, не меняется при обновлениях. Нулевое
+	// у задач, созданных до появления времени создания
+	CreatedAt time.Time
+	// Version увеличивается хранилищем при каждом изменении задачи.
+	// В запросе на изменение это ожидаемая текущая версия, 0 - без проверки
+	Version int
+	// DeletedAt время перемещения задачи в корзину, nil - задача не удалена
+	DeletedAt *time.Time
+}
+
+// Clone возвращает глубокую копию задачи, не разделяющую с ней память
+func (t *Task) Clone() *Task {
+	clone := *t
+
+	if t.DueAt != nil {
+		dueAt := *t.DueAt
+		clone.DueAt = &dueAt
+	}
+
+	if t.DeletedAt != nil {
+		deletedAt := *t.DeletedAt
+		clone.DeletedAt = &deletedAt
+	}
+
+	if t.Tags != nil {
+		clone.Tags = append([]string(nil), t.Tags...)
+	}
+
+	if t.DependsOn != nil {
+		clone.DependsOn = append([]int(nil), t.DependsOn...)
+	}
+
+	return &clone
+}
+
+// HasTag сообщает, есть ли у задачи метка tag
+func (t *Task) HasTag(tag string) bool {
+	i := sort.SearchStrings(t.Tags, tag)
+	return i < len(t.Tags) && t.Tags[i] == tag
+}
+
+// RenameTag заменяет метку from на to с сохранением порядка меток. Если метка to
+// у задачи уже есть, метки сливаются в одну. Возвращает false, если метки from нет
+func (t *Task) RenameTag(from, to string) bool {
+	if !t.HasTag(from) {
+		return false
+	}
+
+	tags := make([]string, 0, len(t.Tags))
+	for _, tag := range t.Tags {
+		if tag != from && tag != to {
+			tags = append(tags, tag)
+		}
+	}
+
+	i := sort.SearchStrings(tags, to)
+	tags = append(tags, "")
+	copy(tags[i+1:], tags[i:])
+	tags[i] = to
+
+	t.Tags = tags
+
+	return true
+}
+
+// TaskNode задача вместе с подзадачами. Children пуст и у задачи без подзадач,
+// и у задачи на границе глубины дерева, HasChildren различает эти случаи
+type TaskNode struct {
+	Task        *Task
+	Children    []*TaskNode
+	HasChildren bool
+}
+
+// DeleteRule что происходит с подзадачами при удалении задачи
+type DeleteRule string
+
+const (
+	// DeleteBlock задачу с подзадачами удалить нельзя
+	DeleteBlock DeleteRule = "block"
+	// DeleteCascade подзадачи удаляются вместе с задачей
+	DeleteCascade DeleteRule = "cascade"
+	// DeleteOrphan подзадачи становятся задачами верхнего уровня
+	DeleteOrphan DeleteRule = "orphan"
+)
+
+// TagCount метка и количество задач с ней
+type TagCount struct {
+	Tag   string
+	Count int
+}
+
+// Priority уровень приоритета задачи
+type Priority string
+
+const (
+	PriorityNone   Priority = "none"
+	PriorityLow    Priority = "low"
+	PriorityMedium Priority = "medium"
+	PriorityHigh   Priority = "high"
+	PriorityUrgent Priority = "urgent"
+)
+
+// Rank возвращает порядковый номер приоритета от 0 у PriorityNone до 4 у PriorityUrgent,
+// -1 - неизвестный приоритет
+func (p Priority) Rank() int {
+	switch p {
+	case "", PriorityNone:
+		return 0
+	case PriorityLow:
+		return 1
+	case PriorityMedium:
+		return 2
+	case PriorityHigh:
+		return 3
+	case PriorityUrgent:
+		return 4
+	default:
+		return -1
+	}
+}
+
+// String возвращает название приоритета, пустой приоритет называется PriorityNone
+func (p Priority) String() string {
+	if p == "" {
+		return string(PriorityNone)
+	}
+
+	return string(p)
+}
+
+// SortField поле сортировки списка задач
+type SortField string
+
+const (
+	// SortByID порядок ID задач. Он совпадает с порядком создания, кроме импортированных
+	// задач, которые получают новые ID
+	SortByID SortField = "id"
+	// SortByCreated порядок времени создания, задачи без времени создания всегда в начале
+	SortByCreated  SortField = "created"
+	SortByPriority SortField = "priority"
+	// SortByDue порядок сроков выполнения, задачи без срока всегда в конце
+	SortByDue   SortField = "due"
+	SortByTitle SortField = "title"
+)
+
+// TaskFilter условия выборки и порядок задач. Нулевое значение поля означает отсутствие условия
+type TaskFilter struct {
+	// DueAfter нижняя граница срока выполнения включительно
+	DueAfter time.Time
+	// DueBefore верхняя граница срока выполнения, не включая ее
+	DueBefore time.Time
+	// Overdue выбирает невыполненные задачи с истекшим сроком
+	Overdue bool
+	// Tags выбирает задачи хотя бы с одной из меток, а с AllTags - со всеми метками
+	Tags    []string
+	AllTags bool
+	// ProjectID выбирает задачи проекта, 0 - задачи всех проектов и вне проектов
+	ProjectID int
+	// SortBy поле сортировки, пустое - SortByID. Задачи с равным значением поля
+	// упорядочиваются по ID
+	SortBy SortField
+	// Desc сортирует по убыванию
+	Desc bool
+}
+
+// HasTags сообщает, ограничивает ли фильтр метки задач
+func (f TaskFilter) HasTags() bool {
+	return len(f.Tags) > 0
+}
+
+// HasDue сообщает, ограничивает ли фильтр срок выполнения задач
+func (f TaskFilter) HasDue() bool {
+	return !f.DueAfter.IsZero() || !f.DueBefore.IsZero() || f.Overdue
 }
@@ -0,0 +1,146 @@
+package tests
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/solumD/tasks-service/internal/model"
+)
+
+func TestParseRecurrenceRule(t *testing.T) {
+	tests := []struct {
+		name        string
+		value       string
+		expected    string
+		expectedErr bool
+	}{
+		{name: "daily", value: "FREQ=DAILY", expected: "FREQ=DAILY"},
+		{name: "prefix and case", value: "rrule:freq=daily;interval=3", expected: "FREQ=DAILY;INTERVAL=3"},
+		{name: "interval one omitted", value: "FREQ=WEEKLY;INTERVAL=1", expected: "FREQ=WEEKLY"},
+		{name: "weekdays sorted from monday", value: "BYDAY=SU,FR,MO,FR;FREQ=WEEKLY", expected: "FREQ=WEEKLY;BYDAY=MO,FR,SU"},
+		{name: "month days", value: "FREQ=MONTHLY;BYMONTHDAY=-1,15,1", expected: "FREQ=MONTHLY;BYMONTHDAY=-1,1,15"},
+		{name: "until date", value: "FREQ=DAILY;UNTIL=20260310", expected: "FREQ=DAILY;UNTIL=20260310T235959Z"},
+		{name: "until time", value: "FREQ=DAILY;UNTIL=20260310T120000Z", expected: "FREQ=DAILY;UNTIL=20260310T120000Z"},
+		{name: "count", value: "FREQ=MONTHLY;COUNT=12", expected: "FREQ=MONTHLY;COUNT=12"},
+		{name: "empty", value: "", expectedErr: true},
+		{name: "no frequency", value: "INTERVAL=2", expectedErr: true},
+		{name: "unsupported frequency", value: "FREQ=YEARLY", expectedErr: true},
+		{name: "unsupported part", value: "FREQ=DAILY;BYHOUR=9", expectedErr: true},
+		{name: "duplicate part", value: "FREQ=DAILY;FREQ=WEEKLY", expectedErr: true},
+		{name: "zero interval", value: "FREQ=DAILY;INTERVAL=0", expectedErr: true},
+		{name: "weekday with number", value: "FREQ=WEEKLY;BYDAY=1MO", expectedErr: true},
+		{name: "weekdays with daily", value: "FREQ=DAILY;BYDAY=MO", expectedErr: true},
+		{name: "month day out of range", value: "FREQ=MONTHLY;BYMONTHDAY=32", expectedErr: true},
+		{name: "month days with weekly", value: "FREQ=WEEKLY;BYMONTHDAY=1", expectedErr: true},
+		{name: "local until", value: "FREQ=DAILY;UNTIL=20260310T120000", expectedErr: true},
+		{name: "count and until", value: "FREQ=DAILY;COUNT=2;UNTIL=20260310", expectedErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := model.ParseRecurrenceRule(tt.value)
+			if tt.expectedErr {
+				if err == nil {
+					t.Fatalf("expected error, got rule %q", rule)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if rule.String() != tt.expected {
+				t.Fatalf("expected rule %q, got %q", tt.expected, rule.String())
+			}
+		})
+	}
+}
+
+func TestRecurrenceOccurrences(t *testing.T) {
+	msk := time.FixedZone("", 3*60*60)
+	date := func(month time.Month, day int) time.Time {
+		return time.Date(2026, month, day, 9, 30, 0, 0, msk)
+	}
+
+	tests := []struct {
+		name     string
+		rule     string
+		start    time.Time
+		n        int
+		expected []time.Time
+	}{
+		{
+			name:     "every two days",
+			rule:     "FREQ=DAILY;INTERVAL=2",
+			start:    date(time.March, 30),
+			n:        3,
+			expected: []time.Time{date(time.April, 1), date(time.April, 3), date(time.April, 5)},
+		},
+		{
+			name:     "weekly on weekdays",
+			rule:     "FREQ=WEEKLY;BYDAY=MO,WE,FR",
+			start:    date(time.March, 4),
+			n:        4,
+			expected: []time.Time{date(time.March, 6), date(time.March, 9), date(time.March, 11), date(time.March, 13)},
+		},
+		{
+			name:     "every other week from sunday",
+			rule:     "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,SU",
+			start:    date(time.March, 1),
+			n:        3,
+			expected: []time.Time{date(time.March, 9), date(time.March, 15), date(time.March, 23)},
+		},
+		{
+			name:     "monthly skips short months",
+			rule:     "FREQ=MONTHLY",
+			start:    date(time.January, 31),
+			n:        2,
+			expected: []time.Time{date(time.March, 31), date(time.May, 31)},
+		},
+		{
+			name:     "last day of month",
+			rule:     "FREQ=MONTHLY;BYMONTHDAY=-1",
+			start:    date(time.January, 31),
+			n:        3,
+			expected: []time.Time{date(time.February, 28), date(time.March, 31), date(time.April, 30)},
+		},
+		{
+			name:     "count includes start",
+			rule:     "FREQ=DAILY;COUNT=3",
+			start:    date(time.March, 1),
+			n:        5,
+			expected: []time.Time{date(time.March, 2), date(time.March, 3)},
+		},
+		{
+			name:     "until is inclusive",
+			rule:     "FREQ=WEEKLY;UNTIL=20260315",
+			start:    date(time.March, 1),
+			n:        5,
+			expected: []time.Time{date(time.March, 8), date(time.March, 15)},
+		},
+		{
+			name:     "no day in any month",
+			rule:     "FREQ=MONTHLY;INTERVAL=12;BYMONTHDAY=30",
+			start:    date(time.February, 1),
+			n:        1,
+			expected: []time.Time{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := model.ParseRecurrenceRule(tt.rule)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			got := rule.Occurrences(tt.start, tt.n)
+			if !slices.EqualFunc(got, tt.expected, time.Time.Equal) {
+				t.Fatalf("expected occurrences %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
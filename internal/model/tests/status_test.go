@@ -0,0 +1,70 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/solumD/tasks-service/internal/model"
+)
+
+func TestParseWorkflow(t *testing.T) {
+	tests := []struct {
+		name        string
+		value       string
+		from        model.Status
+		to          model.Status
+		expected    bool
+		expectedErr bool
+	}{
+		{name: "listed transition", value: "todo=in_progress,done;in_progress=done", from: model.StatusTodo, to: model.StatusDone, expected: true},
+		{name: "missing transition", value: "todo=in_progress;in_progress=done", from: model.StatusTodo, to: model.StatusDone},
+		{name: "final status", value: "todo=done", from: model.StatusDone, to: model.StatusTodo},
+		{name: "same status", value: "todo=done", from: model.StatusDone, to: model.StatusDone, expected: true},
+		{name: "spaces and trailing separator", value: " todo = in_progress , done ; ", from: model.StatusTodo, to: model.StatusDone, expected: true},
+		{name: "empty", value: "", expectedErr: true},
+		{name: "no targets separator", value: "todo", expectedErr: true},
+		{name: "unknown source", value: "later=done", expectedErr: true},
+		{name: "unknown target", value: "todo=later", expectedErr: true},
+		{name: "duplicate source", value: "todo=done;todo=in_progress", expectedErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			workflow, err := model.ParseWorkflow(tt.value)
+			if tt.expectedErr {
+				if err == nil {
+					t.Fatalf("expected error, got workflow %v", workflow)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got := workflow.Allows(tt.from, tt.to); got != tt.expected {
+				t.Fatalf("expected transition from %s to %s allowed = %v, got %v", tt.from, tt.to, tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestCurrentStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		task     *model.Task
+		expected model.Status
+	}{
+		{name: "legacy open task", task: &model.Task{}, expected: model.StatusTodo},
+		{name: "legacy done task", task: &model.Task{Done: true}, expected: model.StatusDone},
+		{name: "explicit status", task: &model.Task{Status: model.StatusReview}, expected: model.StatusReview},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.task.CurrentStatus(); got != tt.expected {
+				t.Fatalf("expected status %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
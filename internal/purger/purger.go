@@ -0,0 +1,91 @@
+package purger
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/solumD/tasks-service/pkg/logger"
+)
+
+// TrashPurger интерфейс очистки корзины задач
+type TrashPurger interface {
+	PurgeDeletedTasks(ctx context.Context, retention time.Duration) (int, error)
+}
+
+type purger struct {
+	trash     TrashPurger
+	retention time.Duration
+	interval  time.Duration
+	log       *slog.Logger
+
+	cancel context.CancelFunc
+	done   chan struct{}
+	once   *sync.Once
+}
+
+// New создает фоновую очистку, которая каждые interval окончательно удаляет
+// задачи, пролежавшие в корзине дольше retention
+func New(trash TrashPurger, retention, interval time.Duration, log *slog.Logger) *purger {
+	return &purger{
+		trash:     trash,
+		retention: retention,
+		interval:  interval,
+		log:       log,
+		done:      make(chan struct{}),
+		once:      &sync.Once{},
+	}
+}
+
+// Run запускает очистку в отдельной горутине. Первая очистка выполняется сразу
+func (p *purger) Run(ctx context.Context) {
+	ctx, p.cancel = context.WithCancel(ctx)
+
+	go func() {
+		defer close(p.done)
+
+		ticker := time.NewTicker(p.interval)
+		defer ticker.Stop()
+
+		for {
+			p.purge(ctx)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop останавливает очистку и ждет завершения текущего прохода
+func (p *purger) Stop() {
+	p.once.Do(func() {
+		if p.cancel == nil {
+			close(p.done)
+			return
+		}
+
+		p.cancel()
+	})
+
+	<-p.done
+}
+
+func (p *purger) purge(ctx context.Context) {
+	const fn = "purger.purge"
+	log := p.log.With(logger.String("fn", fn))
+
+	// ошибки не останавливают очистку, следующий проход повторит попытку
+	purged, err := p.trash.PurgeDeletedTasks(ctx, p.retention)
+	if err != nil {
+		log.Error("failed to purge trash", logger.Error(err))
+		return
+	}
+
+	if purged > 0 {
+		log.Info("purged trash", logger.Int("tasks count", purged))
+	}
+}
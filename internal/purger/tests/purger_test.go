@@ -0,0 +1,96 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/solumD/tasks-service/internal/purger"
+	"github.com/solumD/tasks-service/pkg/logger"
+)
+
+type mockTrash struct {
+	mu         sync.Mutex
+	calls      int
+	retentions []time.Duration
+	err        error
+	called     chan struct{}
+}
+
+func (m *mockTrash) PurgeDeletedTasks(_ context.Context, retention time.Duration) (int, error) {
+	m.mu.Lock()
+	m.calls++
+	m.retentions = append(m.retentions, retention)
+	m.mu.Unlock()
+
+	select {
+	case m.called <- struct{}{}:
+	default:
+	}
+
+	return 1, m.err
+}
+
+func TestPurgerRunsPeriodically(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{name: "success"},
+		{name: "repo error does not stop purger", err: errors.New("db error")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trash := &mockTrash{err: tt.err, called: make(chan struct{})}
+			p := purger.New(trash, time.Hour, time.Millisecond, logger.NewMockLogger())
+
+			p.Run(context.Background())
+
+			for i := 0; i < 3; i++ {
+				select {
+				case <-trash.called:
+				case <-time.After(time.Second):
+					t.Fatalf("expected purge call %d", i+1)
+				}
+			}
+
+			p.Stop()
+
+			trash.mu.Lock()
+			defer trash.mu.Unlock()
+
+			for _, retention := range trash.retentions {
+				if retention != time.Hour {
+					t.Fatalf("expected retention %v, got %v", time.Hour, retention)
+				}
+			}
+		})
+	}
+}
+
+func TestPurgerStopsOnContextCancel(t *testing.T) {
+	trash := &mockTrash{called: make(chan struct{}, 1)}
+	p := purger.New(trash, time.Hour, time.Hour, logger.NewMockLogger())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p.Run(ctx)
+
+	select {
+	case <-trash.called:
+	case <-time.After(time.Second):
+		t.Fatalf("expected first purge right after start")
+	}
+
+	cancel()
+	p.Stop()
+
+	trash.mu.Lock()
+	defer trash.mu.Unlock()
+
+	if trash.calls != 1 {
+		t.Fatalf("expected 1 purge call, got %d", trash.calls)
+	}
+}
@@ -0,0 +1,114 @@
+package raft
+
+import (
+	"slices"
+
+	"github.com/solumD/tasks-service/pkg/logger"
+)
+
+func (n *Node) runApplier() {
+	defer n.wg.Done()
+
+	for {
+		select {
+		case <-n.ctx.Done():
+			return
+		case <-n.applyCh:
+			n.applyCommitted()
+			n.maybeSnapshot()
+		}
+	}
+}
+
+// applyCommitted применяет зафиксированные записи к конечному автомату. Записи
+// применяются без mu, чтобы долгие команды не останавливали обмен с другими узлами
+func (n *Node) applyCommitted() {
+	n.fsmMu.Lock()
+	defer n.fsmMu.Unlock()
+
+	for {
+		n.mu.Lock()
+		if n.stopped || n.lastApplied >= n.commitIndex {
+			n.mu.Unlock()
+			return
+		}
+
+		batch := cloneEntries(n.slice(n.lastApplied+1, n.commitIndex+1))
+		n.mu.Unlock()
+
+		for _, e := range batch {
+			var result []byte
+			if e.Type == EntryCommand {
+				result = n.fsm.Apply(e.Data)
+			}
+
+			n.mu.Lock()
+			n.lastApplied = e.Index
+			n.resolve(e, result)
+			n.notify()
+			n.mu.Unlock()
+		}
+	}
+}
+
+// resolve отдает результат записи клиенту, который ее предложил. Вызывается под mu
+func (n *Node) resolve(e Entry, result []byte) {
+	if w, ok := n.waiters[e.Index]; ok {
+		delete(n.waiters, e.Index)
+
+		if w.term == e.Term {
+			w.done <- applyResult{data: result}
+		} else {
+			w.done <- applyResult{err: ErrLeadershipLost}
+		}
+	}
+
+	// лидер, исключенный из кластера, уступает после фиксации нового состава
+	if e.Type == EntryConfig && e.Index == n.configIndex && n.state == StateLeader && !n.config.has(n.cfg.ID) {
+		n.log.Info("raft leader removed from configuration, stepping down")
+		n.becomeFollower(n.term, "")
+	}
+}
+
+// maybeSnapshot сжимает журнал, если с последнего снимка применено достаточно записей
+func (n *Node) maybeSnapshot() {
+	n.fsmMu.Lock()
+	defer n.fsmMu.Unlock()
+
+	n.mu.Lock()
+	index := n.lastApplied
+	if n.stopped || index-n.snapIndex < n.cfg.SnapshotThreshold {
+		n.mu.Unlock()
+		return
+	}
+	n.mu.Unlock()
+
+	// под fsmMu автомат не меняется, поэтому его состояние соответствует записи index
+	data, err := n.fsm.Snapshot()
+	if err != nil {
+		n.log.Error("failed to take raft snapshot", logger.Error(err))
+		return
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	term, _ := n.termAt(index)
+	config, _ := n.configAt(index)
+	snapshot := &Snapshot{
+		LastIndex:     index,
+		LastTerm:      term,
+		Configuration: config,
+		Data:          data,
+	}
+
+	if err := n.storage.SaveSnapshot(snapshot); err != nil {
+		n.log.Error("failed to save raft snapshot", logger.Error(err))
+		return
+	}
+
+	n.entries = slices.Clone(n.entries[index-n.snapIndex:])
+	n.snapIndex, n.snapTerm, n.snapConfig = index, term, config
+
+	n.log.Info("raft log compacted", logger.Any("index", index))
+}
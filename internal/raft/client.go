@@ -0,0 +1,318 @@
+package raft
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/solumD/tasks-service/pkg/logger"
+)
+
+// Apply предлагает команду кластеру и возвращает результат FSM.Apply после ее фиксации.
+// Ведомый узел пересылает команду лидеру. ErrTimeout и ErrStopped означают, что исход
+// неизвестен, остальные ошибки - что команда не применена
+func (n *Node) Apply(ctx context.Context, data []byte) ([]byte, error) {
+	var result []byte
+
+	err := n.withLeader(ctx, func(ctx context.Context) error {
+		var err error
+		result, err = n.applyLocal(ctx, EntryCommand, data)
+		return err
+	}, func(ctx context.Context, leader Server) error {
+		var resp applyResp
+		if err := n.transport.Call(ctx, leader, rpcApply, &applyReq{Data: data}, &resp); err != nil {
+			return err
+		}
+
+		result = resp.Data
+		return parseError(resp.Error)
+	})
+
+	return result, err
+}
+
+// Barrier ждет, пока узел применит все записи, зафиксированные к моменту вызова.
+// После Barrier чтение конечного автомата узла видит все подтвержденные изменения
+func (n *Node) Barrier(ctx context.Context) error {
+	var index uint64
+
+	err := n.withLeader(ctx, func(ctx context.Context) error {
+		var err error
+		index, err = n.readIndex(ctx)
+		return err
+	}, func(ctx context.Context, leader Server) error {
+		var resp readIndexResp
+		if err := n.transport.Call(ctx, leader, rpcReadIndex, &readIndexReq{}, &resp); err != nil {
+			return err
+		}
+
+		index = resp.Index
+		return parseError(resp.Error)
+	})
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, n.cfg.ApplyTimeout)
+	defer cancel()
+
+	return n.timeoutError(ctx, n.waitFor(ctx, func() (bool, error) {
+		return n.lastApplied >= index, nil
+	}))
+}
+
+// AddServer добавляет участника в кластер или меняет его адрес. Участник сразу получает
+// право голоса, поэтому добавлять следует по одному и только запущенные узлы
+func (n *Node) AddServer(ctx context.Context, server Server) error {
+	return n.changeConfig(ctx, changeAdd, server)
+}
+
+// RemoveServer исключает участника из кластера. Исключенный лидер уступает после фиксации изменения
+func (n *Node) RemoveServer(ctx context.Context, id string) error {
+	return n.changeConfig(ctx, changeRemove, Server{ID: id})
+}
+
+func (n *Node) changeConfig(ctx context.Context, op string, server Server) error {
+	return n.withLeader(ctx, func(ctx context.Context) error {
+		return n.changeConfigLocal(ctx, op, server)
+	}, func(ctx context.Context, leader Server) error {
+		var resp changeConfigResp
+		if err := n.transport.Call(ctx, leader, rpcChangeConfig, &changeConfigReq{Op: op, Server: server}, &resp); err != nil {
+			return err
+		}
+
+		return parseError(resp.Error)
+	})
+}
+
+// withLeader выполняет запрос на самом узле, если он лидер, или пересылает лидеру.
+// Пока лидер неизвестен или запрос точно до него не дошел, запрос повторяется до ApplyTimeout
+func (n *Node) withLeader(
+	ctx context.Context,
+	local func(ctx context.Context) error,
+	remote func(ctx context.Context, leader Server) error,
+) error {
+	ctx, cancel := context.WithTimeout(ctx, n.cfg.ApplyTimeout)
+	defer cancel()
+
+	for {
+		n.mu.Lock()
+		stopped := n.stopped
+		isLeader := n.state == StateLeader
+		leader, known := n.config.server(n.leaderID)
+		n.mu.Unlock()
+
+		var err error
+		switch {
+		case stopped:
+			return ErrStopped
+		case isLeader:
+			err = local(ctx)
+		case known:
+			err = remote(ctx, leader)
+		default:
+			err = ErrNoLeader
+		}
+
+		// запрос не дошел до лидера или лидер сменился до начала его обработки
+		if !errors.Is(err, ErrUnreachable) && !errors.Is(err, ErrNoLeader) && !errors.Is(err, ErrNotLeader) {
+			return n.timeoutError(ctx, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			if errors.Is(err, ErrNotLeader) {
+				err = ErrNoLeader
+			}
+			return n.timeoutError(ctx, err)
+		case <-time.After(n.cfg.HeartbeatInterval):
+		}
+	}
+}
+
+// timeoutError заменяет ошибки транспорта и истечения времени на ErrTimeout. Отмена
+// контекста клиентом возвращается как есть
+func (n *Node) timeoutError(ctx context.Context, err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, context.Canceled) && !errors.Is(context.Cause(ctx), context.DeadlineExceeded):
+		return err
+	case errors.Is(err, ErrUnreachable) || errors.Is(err, ErrNoLeader):
+		return ErrNoLeader
+	case errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled):
+		return ErrTimeout
+	}
+
+	for _, known := range knownErrors {
+		if errors.Is(err, known) {
+			return err
+		}
+	}
+
+	// прочие ошибки транспорта: запрос мог дойти до лидера
+	return ErrTimeout
+}
+
+// applyLocal добавляет запись в журнал лидера и ждет ее применения
+func (n *Node) applyLocal(ctx context.Context, typ EntryType, data []byte) ([]byte, error) {
+	n.mu.Lock()
+	w, err := n.propose(typ, data)
+	n.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	return n.wait(ctx, w)
+}
+
+// propose добавляет запись в журнал лидера. Вызывается под mu
+func (n *Node) propose(typ EntryType, data []byte) (*waiter, error) {
+	if n.stopped {
+		return nil, ErrStopped
+	}
+
+	if n.state != StateLeader {
+		return nil, ErrNotLeader
+	}
+
+	entry := Entry{Index: n.lastIndex() + 1, Term: n.term, Type: typ, Data: data}
+	if err := n.appendEntries([]Entry{entry}); err != nil {
+		return nil, err
+	}
+
+	w := &waiter{term: n.term, done: make(chan applyResult, 1)}
+	n.waiters[entry.Index] = w
+
+	n.advanceCommit()
+	n.broadcast()
+
+	return w, nil
+}
+
+func (n *Node) wait(ctx context.Context, w *waiter) ([]byte, error) {
+	select {
+	case res := <-w.done:
+		return res.data, res.err
+	case <-ctx.Done():
+		// запись остается в журнале и может быть применена позже
+		return nil, ctx.Err()
+	}
+}
+
+// readIndex возвращает индекс, после применения которого чтение видит все подтвержденные
+// изменения. Лидер сначала убеждается, что большинство все еще признает его лидерство
+func (n *Node) readIndex(ctx context.Context) (uint64, error) {
+	n.mu.Lock()
+	term := n.term
+	n.mu.Unlock()
+
+	stillLeader := func() error {
+		if n.state != StateLeader || n.term != term {
+			return ErrNotLeader
+		}
+
+		return nil
+	}
+
+	// до фиксации первой записи срока лидер не знает, какие записи зафиксированы
+	var index uint64
+	err := n.waitFor(ctx, func() (bool, error) {
+		if err := stillLeader(); err != nil {
+			return false, err
+		}
+
+		index = n.commitIndex
+		return n.commitIndex >= n.leaderStartIndex, nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	start := time.Now()
+	n.mu.Lock()
+	if err := stillLeader(); err == nil {
+		n.broadcast()
+	}
+	n.mu.Unlock()
+
+	err = n.waitFor(ctx, func() (bool, error) {
+		if err := stillLeader(); err != nil {
+			return false, err
+		}
+
+		return n.hasQuorumSince(start), nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return index, nil
+}
+
+// changeConfigLocal добавляет в журнал лидера новый состав кластера и ждет его применения.
+// Одновременно меняется не больше одного участника
+func (n *Node) changeConfigLocal(ctx context.Context, op string, server Server) error {
+	n.mu.Lock()
+
+	if n.state != StateLeader {
+		n.mu.Unlock()
+		return ErrNotLeader
+	}
+
+	if n.commitIndex < n.leaderStartIndex || n.configIndex > n.commitIndex {
+		n.mu.Unlock()
+		return ErrConfigChangeInProgress
+	}
+
+	config := n.config.clone()
+	current, exists := config.server(server.ID)
+
+	switch {
+	case op == changeAdd && exists && current.Address == server.Address,
+		op == changeRemove && !exists:
+		n.mu.Unlock()
+		return nil
+	case op == changeAdd:
+		config.Servers = append(config.Servers[:0:0], config.Servers...)
+		if exists {
+			for i := range config.Servers {
+				if config.Servers[i].ID == server.ID {
+					config.Servers[i] = server
+				}
+			}
+		} else {
+			config.Servers = append(config.Servers, server)
+		}
+	case op == changeRemove:
+		servers := config.Servers[:0:0]
+		for _, s := range config.Servers {
+			if s.ID != server.ID {
+				servers = append(servers, s)
+			}
+		}
+		config.Servers = servers
+	}
+
+	data, err := json.Marshal(config)
+	if err != nil {
+		n.mu.Unlock()
+		return err
+	}
+
+	w, err := n.propose(EntryConfig, data)
+	n.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	n.log.Info("raft configuration change proposed",
+		logger.String("op", op),
+		logger.String("server", server.ID),
+	)
+
+	_, err = n.wait(ctx, w)
+
+	return err
+}
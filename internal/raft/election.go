@@ -0,0 +1,170 @@
+package raft
+
+import (
+	"context"
+	"time"
+
+	"github.com/solumD/tasks-service/pkg/logger"
+)
+
+// startPreVote начинает предварительное голосование. Вызывается под mu
+func (n *Node) startPreVote() {
+	n.resetElectionDeadline()
+	n.runVote(true)
+}
+
+// startElection увеличивает срок и начинает выборы. Вызывается под mu
+func (n *Node) startElection() {
+	n.state = StateCandidate
+	n.term++
+	n.votedFor = n.cfg.ID
+	n.leaderID = ""
+	n.persistState()
+	n.resetElectionDeadline()
+	n.notify()
+
+	n.log.Info("raft election started", logger.Any("term", n.term))
+
+	n.runVote(false)
+}
+
+// runVote рассылает запросы голосов участникам кластера. Вызывается под mu
+func (n *Node) runVote(preVote bool) {
+	term := n.term
+	req := &requestVoteReq{
+		Term:         term,
+		CandidateID:  n.cfg.ID,
+		LastLogIndex: n.lastIndex(),
+		LastLogTerm:  n.lastTerm(),
+		PreVote:      preVote,
+	}
+	if preVote {
+		req.Term = term + 1
+	}
+
+	config := n.config.clone()
+	granted := 1
+
+	won := func() {
+		if preVote {
+			if n.leaderID != "" && time.Since(n.lastContact) < n.cfg.ElectionTimeout {
+				return
+			}
+
+			n.startElection()
+			return
+		}
+
+		n.becomeLeader()
+	}
+
+	if granted >= config.quorum() {
+		won()
+		return
+	}
+
+	for _, peer := range n.peers() {
+		go func() {
+			ctx, cancel := context.WithTimeout(n.ctx, n.cfg.ElectionTimeout)
+			defer cancel()
+
+			var resp requestVoteResp
+			if err := n.transport.Call(ctx, peer, rpcRequestVote, req, &resp); err != nil {
+				return
+			}
+
+			n.mu.Lock()
+			defer n.mu.Unlock()
+
+			if n.stopped {
+				return
+			}
+
+			if resp.Term > n.term && !resp.VoteGranted {
+				n.becomeFollower(resp.Term, "")
+				return
+			}
+
+			// результат устарел: узел уже в другом сроке или роли
+			if n.term != term || (preVote && n.state == StateLeader) || (!preVote && n.state != StateCandidate) {
+				return
+			}
+
+			if !resp.VoteGranted {
+				return
+			}
+
+			granted++
+			if granted == config.quorum() {
+				won()
+			}
+		}()
+	}
+}
+
+func (n *Node) handleRequestVote(req *requestVoteReq) *requestVoteResp {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	// узел, который недавно слышал лидера, не голосует за других
+	hasLeader := n.state == StateLeader ||
+		(n.leaderID != "" && time.Since(n.lastContact) < n.cfg.ElectionTimeout)
+	upToDate := req.LastLogTerm > n.lastTerm() ||
+		(req.LastLogTerm == n.lastTerm() && req.LastLogIndex >= n.lastIndex())
+
+	if req.PreVote {
+		return &requestVoteResp{
+			Term:        n.term,
+			VoteGranted: req.Term > n.term && !hasLeader && upToDate,
+		}
+	}
+
+	if req.Term < n.term || hasLeader {
+		return &requestVoteResp{Term: n.term}
+	}
+
+	if req.Term > n.term {
+		n.becomeFollower(req.Term, "")
+	}
+
+	if (n.votedFor != "" && n.votedFor != req.CandidateID) || !upToDate {
+		return &requestVoteResp{Term: n.term}
+	}
+
+	n.votedFor = req.CandidateID
+	n.persistState()
+	n.resetElectionDeadline()
+
+	return &requestVoteResp{Term: n.term, VoteGranted: true}
+}
+
+// becomeLeader делает узел лидером и добавляет в журнал пустую запись: записи
+// прошлых сроков считаются зафиксированными только вместе с ней. Вызывается под mu
+func (n *Node) becomeLeader() {
+	n.state = StateLeader
+	n.leaderID = n.cfg.ID
+	n.leaderSince = time.Now()
+	n.nextIndex = make(map[string]uint64)
+	n.matchIndex = make(map[string]uint64)
+	n.ackSent = make(map[string]time.Time)
+	n.inflight = make(map[string]bool)
+	n.pending = make(map[string]bool)
+
+	for _, peer := range n.peers() {
+		n.nextIndex[peer.ID] = n.lastIndex() + 1
+	}
+
+	n.log.Info("raft node became leader", logger.Any("term", n.term))
+
+	entry := Entry{Index: n.lastIndex() + 1, Term: n.term, Type: EntryNoop}
+	if err := n.appendEntries([]Entry{entry}); err != nil {
+		n.log.Error("failed to append raft noop entry", logger.Error(err))
+		n.becomeFollower(n.term, "")
+		return
+	}
+
+	n.leaderStartIndex = entry.Index
+	n.notify()
+	n.advanceCommit()
+	n.broadcast()
+}
@@ -0,0 +1,323 @@
+package raft
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+
+	"github.com/solumD/tasks-service/internal/fsync"
+)
+
+const (
+	stateFileName    = "state.json"
+	logFileName      = "log.jsonl"
+	snapshotFileName = "snapshot.json"
+)
+
+type persistentState struct {
+	Term     uint64 `json:"term"`
+	VotedFor string `json:"voted_for"`
+}
+
+type fileStorage struct {
+	dir string
+
+	state    persistentState
+	entries  []Entry
+	snapshot *Snapshot
+
+	log     *os.File
+	logSize int64
+	mu      *sync.Mutex
+}
+
+// NewFileStorage открывает хранилище узла в директории dir: срок и голос в state.json,
+// журнал по записи JSON на строку в log.jsonl и последний снимок в snapshot.json.
+// Недописанная последняя запись журнала без перевода строки, оставшаяся после падения,
+// отбрасывается, испорченная запись в другом месте журнала - ошибка
+func NewFileStorage(dir string) (*fileStorage, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create raft dir: %w", err)
+	}
+
+	s := &fileStorage{
+		dir: dir,
+		mu:  &sync.Mutex{},
+	}
+
+	if err := readJSON(filepath.Join(dir, stateFileName), &s.state); err != nil {
+		return nil, err
+	}
+
+	var snapshot Snapshot
+	switch err := readJSON(filepath.Join(dir, snapshotFileName), &snapshot); {
+	case err != nil:
+		return nil, err
+	case snapshot.LastIndex > 0:
+		s.snapshot = &snapshot
+	}
+
+	if err := s.openLog(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func (s *fileStorage) LoadState() (uint64, string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.state.Term, s.state.VotedFor, nil
+}
+
+func (s *fileStorage) SaveState(term uint64, votedFor string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state := persistentState{Term: term, VotedFor: votedFor}
+	if err := writeJSON(s.dir, stateFileName, state); err != nil {
+		return err
+	}
+
+	s.state = state
+
+	return nil
+}
+
+func (s *fileStorage) LoadEntries() ([]Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return cloneEntries(s.entries), nil
+}
+
+func (s *fileStorage) AppendEntries(entries []Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.log == nil {
+		return errors.New("raft storage is closed")
+	}
+
+	var buf bytes.Buffer
+	for _, e := range entries {
+		line, err := json.Marshal(e)
+		if err != nil {
+			return fmt.Errorf("failed to marshal raft entry: %w", err)
+		}
+
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+
+	if _, err := s.log.Write(buf.Bytes()); err != nil {
+		s.rollback()
+		return fmt.Errorf("failed to write raft log: %w", err)
+	}
+
+	if err := s.log.Sync(); err != nil {
+		s.rollback()
+		return fmt.Errorf("failed to sync raft log: %w", err)
+	}
+
+	s.logSize += int64(buf.Len())
+	s.entries = append(s.entries, cloneEntries(entries)...)
+
+	return nil
+}
+
+func (s *fileStorage) TruncateFrom(index uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.rewriteLog(slices.DeleteFunc(slices.Clone(s.entries), func(e Entry) bool {
+		return e.Index >= index
+	}))
+}
+
+func (s *fileStorage) LoadSnapshot() (*Snapshot, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.snapshot == nil {
+		return nil, nil
+	}
+
+	return cloneSnapshot(s.snapshot), nil
+}
+
+// SaveSnapshot сначала сохраняет снимок, а затем переписывает журнал. После падения
+// между этими шагами лишние записи журнала отбрасываются при открытии
+func (s *fileStorage) SaveSnapshot(snapshot *Snapshot) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := writeJSON(s.dir, snapshotFileName, snapshot); err != nil {
+		return err
+	}
+
+	s.snapshot = cloneSnapshot(snapshot)
+
+	return s.rewriteLog(slices.DeleteFunc(slices.Clone(s.entries), func(e Entry) bool {
+		return e.Index <= snapshot.LastIndex
+	}))
+}
+
+func (s *fileStorage) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.log == nil {
+		return nil
+	}
+
+	err := s.log.Close()
+	s.log = nil
+
+	return err
+}
+
+// openLog читает журнал, пропуская записи, уже вошедшие в снимок, и открывает его на дозапись
+func (s *fileStorage) openLog() error {
+	log, err := os.OpenFile(filepath.Join(s.dir, logFileName), os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open raft log: %w", err)
+	}
+
+	var valid int64
+
+	reader := bufio.NewReader(log)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			log.Close()
+			return fmt.Errorf("failed to read raft log: %w", err)
+		}
+
+		var e Entry
+		if err := json.Unmarshal(bytes.TrimSpace(line), &e); err != nil {
+			log.Close()
+			return fmt.Errorf("corrupt raft log record at offset %d: %w", valid, err)
+		}
+
+		if s.snapshot == nil || e.Index > s.snapshot.LastIndex {
+			s.entries = append(s.entries, e)
+		}
+
+		valid += int64(len(line))
+	}
+
+	if err := log.Truncate(valid); err != nil {
+		log.Close()
+		return fmt.Errorf("failed to truncate raft log: %w", err)
+	}
+
+	if _, err := log.Seek(valid, io.SeekStart); err != nil {
+		log.Close()
+		return fmt.Errorf("failed to seek raft log: %w", err)
+	}
+
+	s.log = log
+	s.logSize = valid
+
+	return nil
+}
+
+// rewriteLog атомарно заменяет журнал записями entries
+func (s *fileStorage) rewriteLog(entries []Entry) error {
+	if s.log == nil {
+		return errors.New("raft storage is closed")
+	}
+
+	var buf bytes.Buffer
+	for _, e := range entries {
+		line, err := json.Marshal(e)
+		if err != nil {
+			return fmt.Errorf("failed to marshal raft entry: %w", err)
+		}
+
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+
+	tmp := filepath.Join(s.dir, logFileName+".tmp")
+	if err := fsync.WriteFile(tmp, buf.Bytes()); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmp, filepath.Join(s.dir, logFileName)); err != nil {
+		return fmt.Errorf("failed to replace raft log: %w", err)
+	}
+
+	if err := fsync.Dir(s.dir); err != nil {
+		return err
+	}
+
+	log, err := os.OpenFile(filepath.Join(s.dir, logFileName), os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open raft log: %w", err)
+	}
+
+	s.log.Close()
+	s.log = log
+	s.logSize = int64(buf.Len())
+	s.entries = entries
+
+	return nil
+}
+
+// rollback отрезает недописанные записи
+func (s *fileStorage) rollback() {
+	if err := s.log.Truncate(s.logSize); err == nil {
+		s.log.Seek(s.logSize, io.SeekStart)
+	}
+}
+
+// readJSON читает файл path в v, отсутствующий файл оставляет v без изменений
+func readJSON(path string, v any) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to decode %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// writeJSON атомарно записывает v в файл name директории dir
+func writeJSON(dir string, name string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", name, err)
+	}
+
+	tmp := filepath.Join(dir, name+".tmp")
+	if err := fsync.WriteFile(tmp, data); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmp, filepath.Join(dir, name)); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", name, err)
+	}
+
+	return fsync.Dir(dir)
+}
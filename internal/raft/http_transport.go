@@ -0,0 +1,186 @@
+package raft
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/solumD/tasks-service/pkg/logger"
+)
+
+const (
+	rpcPathPrefix = "/raft/rpc/"
+	// maxRPCBodySize ограничение тела запроса между узлами, снимок передается целиком
+	maxRPCBodySize = 256 << 20
+)
+
+// HTTPTransport передает запросы между узлами по HTTP: POST http://{address}/raft/rpc/{rpc}
+// с телом в JSON. Для приема запросов транспорт нужно зарегистрировать как обработчик
+// пути /raft/rpc/ на адресе узла
+type HTTPTransport struct {
+	client  *http.Client
+	handler Handler
+	mu      *sync.RWMutex
+}
+
+// NewHTTPTransport возвращает HTTP-транспорт
+func NewHTTPTransport() *HTTPTransport {
+	return &HTTPTransport{
+		client: &http.Client{},
+		mu:     &sync.RWMutex{},
+	}
+}
+
+func (t *HTTPTransport) Call(ctx context.Context, target Server, rpc string, req any, resp any) error {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+
+	url := "http://" + target.Address + rpcPathPrefix + rpc
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	httpResp, err := t.client.Do(httpReq)
+	if err != nil {
+		// соединение не установлено, значит запрос точно не дошел
+		if opErr := (*net.OpError)(nil); errors.As(err, &opErr) && opErr.Op == "dial" {
+			return fmt.Errorf("%w: %v", ErrUnreachable, err)
+		}
+
+		return err
+	}
+	defer httpResp.Body.Close()
+
+	switch httpResp.StatusCode {
+	case http.StatusOK:
+		return json.NewDecoder(httpResp.Body).Decode(resp)
+	case http.StatusServiceUnavailable:
+		// узел остановлен и запрос не обработал
+		return ErrUnreachable
+	default:
+		msg, _ := io.ReadAll(io.LimitReader(httpResp.Body, 1024))
+		return fmt.Errorf("raft rpc %s to %s failed: %s: %s", rpc, target.ID, httpResp.Status, strings.TrimSpace(string(msg)))
+	}
+}
+
+func (t *HTTPTransport) Serve(handler Handler) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.handler = handler
+}
+
+// ServeHTTP принимает запросы других узлов
+func (t *HTTPTransport) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	t.mu.RLock()
+	handler := t.handler
+	t.mu.RUnlock()
+
+	if handler == nil {
+		http.Error(w, ErrStopped.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxRPCBodySize))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	out, err := handler(r.Context(), strings.TrimPrefix(r.URL.Path, rpcPathPrefix), body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(out)
+}
+
+type addServerReq struct {
+	ID      string `json:"id"`
+	Address string `json:"address"`
+}
+
+type errorResp struct {
+	Error string `json:"error"`
+}
+
+// NewHTTPHandler возвращает обработчик HTTP-адреса узла: запросы других узлов и служебные
+// запросы оператора GET /raft/status, POST /raft/servers и DELETE /raft/servers/{id}
+func NewHTTPHandler(node *Node, transport *HTTPTransport, log *slog.Logger) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.Handle(rpcPathPrefix, transport)
+
+	mux.HandleFunc("GET /raft/status", func(w http.ResponseWriter, r *http.Request) {
+		writeJSONResponse(w, http.StatusOK, node.Status())
+	})
+
+	mux.HandleFunc("POST /raft/servers", func(w http.ResponseWriter, r *http.Request) {
+		var req addServerReq
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.ID == "" || req.Address == "" {
+			writeJSONResponse(w, http.StatusBadRequest, &errorResp{Error: "id and address are required"})
+			return
+		}
+
+		if err := node.AddServer(r.Context(), Server{ID: req.ID, Address: req.Address}); err != nil {
+			log.Error("failed to add raft server", logger.String("server", req.ID), logger.Error(err))
+			writeJSONResponse(w, changeStatus(err), &errorResp{Error: err.Error()})
+			return
+		}
+
+		log.Info("raft server added", logger.String("server", req.ID), logger.String("address", req.Address))
+		writeJSONResponse(w, http.StatusOK, node.Status())
+	})
+
+	mux.HandleFunc("DELETE /raft/servers/{id}", func(w http.ResponseWriter, r *http.Request) {
+		id := r.PathValue("id")
+		if err := node.RemoveServer(r.Context(), id); err != nil {
+			log.Error("failed to remove raft server", logger.String("server", id), logger.Error(err))
+			writeJSONResponse(w, changeStatus(err), &errorResp{Error: err.Error()})
+			return
+		}
+
+		log.Info("raft server removed", logger.String("server", id))
+		writeJSONResponse(w, http.StatusOK, node.Status())
+	})
+
+	return mux
+}
+
+func changeStatus(err error) int {
+	switch {
+	case errors.Is(err, ErrConfigChangeInProgress):
+		return http.StatusConflict
+	case errors.Is(err, ErrNoLeader), errors.Is(err, ErrStopped):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, ErrTimeout):
+		return http.StatusGatewayTimeout
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func writeJSONResponse(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
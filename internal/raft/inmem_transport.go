@@ -0,0 +1,138 @@
+package raft
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+)
+
+// InmemNetwork сеть в памяти процесса для тестов кластера. Запросы доставляются
+// синхронно и без потерь, пока связь между узлами не разорвана явно
+type InmemNetwork struct {
+	handlers map[string]Handler
+	// blocked разорванные направленные связи from -> to
+	blocked map[[2]string]bool
+	mu      *sync.RWMutex
+}
+
+// NewInmemNetwork возвращает сеть в памяти без разрывов
+func NewInmemNetwork() *InmemNetwork {
+	return &InmemNetwork{
+		handlers: make(map[string]Handler),
+		blocked:  make(map[[2]string]bool),
+		mu:       &sync.RWMutex{},
+	}
+}
+
+// Transport возвращает транспорт узла id. Адрес узла в этой сети совпадает с его ID
+func (n *InmemNetwork) Transport(id string) Transport {
+	return &inmemTransport{
+		network: n,
+		id:      id,
+	}
+}
+
+// Partition разбивает сеть на группы: узлы из разных групп не видят друг друга,
+// а узлы, которых нет ни в одной группе, отрезаны от всех
+func (n *InmemNetwork) Partition(groups ...[]string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	group := make(map[string]int)
+	for i, g := range groups {
+		for _, id := range g {
+			group[id] = i + 1
+		}
+	}
+
+	n.blocked = make(map[[2]string]bool)
+	for from := range n.handlers {
+		for to := range n.handlers {
+			if from != to && (group[from] == 0 || group[from] != group[to]) {
+				n.blocked[[2]string{from, to}] = true
+			}
+		}
+	}
+}
+
+// Block разрывает связь в одну сторону: запросы from к to не доставляются,
+// а ответы to на запросы from теряются
+func (n *InmemNetwork) Block(from, to string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.blocked[[2]string{from, to}] = true
+}
+
+// Heal восстанавливает все связи
+func (n *InmemNetwork) Heal() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.blocked = make(map[[2]string]bool)
+}
+
+func (n *InmemNetwork) route(from, to string) (Handler, error) {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	handler := n.handlers[to]
+	if handler == nil || n.blocked[[2]string{from, to}] {
+		return nil, ErrUnreachable
+	}
+
+	return handler, nil
+}
+
+func (n *InmemNetwork) responseLost(from, to string) bool {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	return n.blocked[[2]string{to, from}]
+}
+
+type inmemTransport struct {
+	network *InmemNetwork
+	id      string
+}
+
+// Call передает запрос через JSON, как и сетевой транспорт, чтобы узлы не делили память
+func (t *inmemTransport) Call(ctx context.Context, target Server, rpc string, req any, resp any) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	handler, err := t.network.route(t.id, target.ID)
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+
+	out, err := handler(ctx, rpc, body)
+	if err != nil {
+		return err
+	}
+
+	if t.network.responseLost(t.id, target.ID) {
+		return errors.New("raft response lost")
+	}
+
+	return json.Unmarshal(out, resp)
+}
+
+func (t *inmemTransport) Serve(handler Handler) {
+	t.network.mu.Lock()
+	defer t.network.mu.Unlock()
+
+	if handler == nil {
+		delete(t.network.handlers, t.id)
+		return
+	}
+
+	t.network.handlers[t.id] = handler
+}
@@ -0,0 +1,552 @@
+package raft
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/solumD/tasks-service/pkg/logger"
+)
+
+const (
+	defaultHeartbeatInterval = 100 * time.Millisecond
+	defaultElectionTimeout   = time.Second
+	defaultApplyTimeout      = 5 * time.Second
+	defaultSnapshotThreshold = 8192
+
+	// maxAppendEntries максимальное количество записей в одном запросе AppendEntries
+	maxAppendEntries = 512
+)
+
+// Config настройки узла
+type Config struct {
+	ID string
+	// Bootstrap начальный состав кластера. Используется только при первом запуске с пустым
+	// хранилищем и должен совпадать на всех начальных узлах. Узел, запущенный с пустым
+	// Bootstrap, ждет, пока лидер добавит его в кластер
+	Bootstrap []Server
+	// HeartbeatInterval интервал между запросами лидера к ведомым узлам
+	HeartbeatInterval time.Duration
+	// ElectionTimeout минимальное время без лидера, после которого узел начинает выборы.
+	// Фактический срок выбирается случайно от ElectionTimeout до 2*ElectionTimeout
+	ElectionTimeout time.Duration
+	// ApplyTimeout время ожидания фиксации записи или ответа лидера
+	ApplyTimeout time.Duration
+	// SnapshotThreshold количество примененных записей, после которого журнал сжимается снимком
+	SnapshotThreshold uint64
+}
+
+func (c Config) withDefaults() Config {
+	if c.HeartbeatInterval <= 0 {
+		c.HeartbeatInterval = defaultHeartbeatInterval
+	}
+
+	if c.ElectionTimeout <= 0 {
+		c.ElectionTimeout = defaultElectionTimeout
+	}
+
+	if c.ApplyTimeout <= 0 {
+		c.ApplyTimeout = defaultApplyTimeout
+	}
+
+	if c.SnapshotThreshold == 0 {
+		c.SnapshotThreshold = defaultSnapshotThreshold
+	}
+
+	return c
+}
+
+type applyResult struct {
+	data []byte
+	err  error
+}
+
+// waiter ждет применения записи, которую предложил этот узел
+type waiter struct {
+	term uint64
+	done chan applyResult
+}
+
+// Node узел кластера Raft
+type Node struct {
+	cfg       Config
+	fsm       FSM
+	storage   Storage
+	transport Transport
+	log       *slog.Logger
+
+	// fsmMu сериализует применение записей, создание и установку снимков.
+	// Захватывается раньше mu
+	fsmMu *sync.Mutex
+	mu    *sync.Mutex
+
+	state    State
+	term     uint64
+	votedFor string
+	leaderID string
+	// lastContact время последнего запроса от лидера
+	lastContact      time.Time
+	electionDeadline time.Time
+
+	// entries записи журнала после снимка, entries[i].Index == snapIndex+i+1
+	entries    []Entry
+	snapIndex  uint64
+	snapTerm   uint64
+	snapConfig Configuration
+	// config последний состав кластера в журнале, он действует сразу после добавления записи
+	config      Configuration
+	configIndex uint64
+
+	commitIndex uint64
+	lastApplied uint64
+	waiters     map[uint64]*waiter
+
+	// состояние лидера
+	leaderSince      time.Time
+	leaderStartIndex uint64
+	nextIndex        map[string]uint64
+	matchIndex       map[string]uint64
+	// ackSent время отправки последнего запроса, на который узел ответил в текущем сроке
+	ackSent  map[string]time.Time
+	inflight map[string]bool
+	pending  map[string]bool
+
+	// changed закрывается и заменяется при каждом изменении состояния узла
+	changed chan struct{}
+	applyCh chan struct{}
+
+	stopped bool
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      *sync.WaitGroup
+	once    *sync.Once
+}
+
+// NewNode возвращает узел с конечным автоматом fsm, хранилищем storage и транспортом
+// transport. Узел начинает работу после Start
+func NewNode(cfg Config, fsm FSM, storage Storage, transport Transport, log *slog.Logger) *Node {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &Node{
+		cfg:       cfg.withDefaults(),
+		fsm:       fsm,
+		storage:   storage,
+		transport: transport,
+		log:       log.With(logger.String("raft_id", cfg.ID)),
+		fsmMu:     &sync.Mutex{},
+		mu:        &sync.Mutex{},
+		state:     StateFollower,
+		waiters:   make(map[uint64]*waiter),
+		changed:   make(chan struct{}),
+		applyCh:   make(chan struct{}, 1),
+		ctx:       ctx,
+		cancel:    cancel,
+		wg:        &sync.WaitGroup{},
+		once:      &sync.Once{},
+	}
+}
+
+// Start восстанавливает состояние узла из хранилища и запускает его
+func (n *Node) Start() error {
+	term, votedFor, err := n.storage.LoadState()
+	if err != nil {
+		return fmt.Errorf("failed to load raft state: %w", err)
+	}
+
+	snapshot, err := n.storage.LoadSnapshot()
+	if err != nil {
+		return fmt.Errorf("failed to load raft snapshot: %w", err)
+	}
+
+	entries, err := n.storage.LoadEntries()
+	if err != nil {
+		return fmt.Errorf("failed to load raft log: %w", err)
+	}
+
+	if term == 0 && snapshot == nil && len(entries) == 0 && len(n.cfg.Bootstrap) > 0 {
+		// состав сортируется, чтобы первая запись журнала совпадала на всех узлах
+		config := Configuration{Servers: slices.Clone(n.cfg.Bootstrap)}
+		slices.SortFunc(config.Servers, func(a, b Server) int {
+			return strings.Compare(a.ID, b.ID)
+		})
+
+		data, err := json.Marshal(config)
+		if err != nil {
+			return err
+		}
+
+		entries = []Entry{{Index: 1, Type: EntryConfig, Data: data}}
+		if err := n.storage.AppendEntries(entries); err != nil {
+			return fmt.Errorf("failed to bootstrap raft log: %w", err)
+		}
+	}
+
+	if snapshot != nil {
+		if err := n.fsm.Restore(snapshot.Data); err != nil {
+			return fmt.Errorf("failed to restore raft snapshot: %w", err)
+		}
+
+		n.snapIndex, n.snapTerm = snapshot.LastIndex, snapshot.LastTerm
+		n.snapConfig = snapshot.Configuration.clone()
+	}
+
+	n.term, n.votedFor = term, votedFor
+	n.entries = entries
+	n.commitIndex, n.lastApplied = n.snapIndex, n.snapIndex
+	n.reloadConfig()
+	n.resetElectionDeadline()
+
+	n.log.Info("raft node started",
+		logger.Any("term", n.term),
+		logger.Any("last_index", n.lastIndex()),
+		logger.Any("snapshot_index", n.snapIndex),
+	)
+
+	n.transport.Serve(n.handle)
+
+	n.wg.Add(2)
+	go n.runTicker()
+	go n.runApplier()
+
+	return nil
+}
+
+// Stop останавливает узел. Хранилище остается открытым
+func (n *Node) Stop() {
+	n.once.Do(func() {
+		n.transport.Serve(nil)
+
+		n.mu.Lock()
+		n.stopped = true
+		for index, w := range n.waiters {
+			w.done <- applyResult{err: ErrStopped}
+			delete(n.waiters, index)
+		}
+		n.notify()
+		n.mu.Unlock()
+
+		n.cancel()
+		n.wg.Wait()
+
+		n.log.Info("raft node stopped")
+	})
+}
+
+// Status возвращает текущее состояние узла
+func (n *Node) Status() Status {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	return Status{
+		ID:            n.cfg.ID,
+		State:         n.state,
+		Term:          n.term,
+		LeaderID:      n.leaderID,
+		CommitIndex:   n.commitIndex,
+		LastApplied:   n.lastApplied,
+		LastIndex:     n.lastIndex(),
+		SnapshotIndex: n.snapIndex,
+		Servers:       n.config.clone().Servers,
+	}
+}
+
+// Leader возвращает ID известного узлу лидера, пустая строка - лидер неизвестен
+func (n *Node) Leader() string {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	return n.leaderID
+}
+
+func (n *Node) runTicker() {
+	defer n.wg.Done()
+
+	ticker := time.NewTicker(n.cfg.HeartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-n.ctx.Done():
+			return
+		case <-ticker.C:
+			n.tick()
+		}
+	}
+}
+
+func (n *Node) tick() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.stopped {
+		return
+	}
+
+	now := time.Now()
+
+	if n.state == StateLeader {
+		// лидер, от которого отрезано большинство, уступает, чтобы клиенты не ждали его зря
+		if now.Sub(n.leaderSince) > n.cfg.ElectionTimeout && !n.hasQuorumSince(now.Add(-n.cfg.ElectionTimeout)) {
+			n.log.Warn("raft leader lost contact with quorum, stepping down", logger.Any("term", n.term))
+			n.becomeFollower(n.term, "")
+			return
+		}
+
+		n.broadcast()
+		return
+	}
+
+	if !now.After(n.electionDeadline) {
+		return
+	}
+
+	// лидер давно молчит: клиенты не должны ждать его ответа
+	if n.leaderID != "" && now.Sub(n.lastContact) > n.cfg.ElectionTimeout {
+		n.leaderID = ""
+		n.notify()
+	}
+
+	if n.config.has(n.cfg.ID) {
+		n.startPreVote()
+	}
+}
+
+// handle обрабатывает запросы других узлов
+func (n *Node) handle(ctx context.Context, rpc string, body []byte) ([]byte, error) {
+	var (
+		resp any
+		err  error
+	)
+
+	ctx, cancel := context.WithTimeout(ctx, n.cfg.ApplyTimeout)
+	defer cancel()
+
+	switch rpc {
+	case rpcRequestVote:
+		var req requestVoteReq
+		if err := json.Unmarshal(body, &req); err != nil {
+			return nil, err
+		}
+		resp = n.handleRequestVote(&req)
+	case rpcAppendEntries:
+		var req appendEntriesReq
+		if err := json.Unmarshal(body, &req); err != nil {
+			return nil, err
+		}
+		resp, err = n.handleAppendEntries(&req)
+	case rpcInstallSnapshot:
+		var req installSnapshotReq
+		if err := json.Unmarshal(body, &req); err != nil {
+			return nil, err
+		}
+		resp, err = n.handleInstallSnapshot(&req)
+	case rpcApply:
+		var req applyReq
+		if err := json.Unmarshal(body, &req); err != nil {
+			return nil, err
+		}
+		data, err := n.applyLocal(ctx, EntryCommand, req.Data)
+		resp = &applyResp{Data: data, Error: errorString(n.timeoutError(ctx, err))}
+	case rpcReadIndex:
+		index, err := n.readIndex(ctx)
+		resp = &readIndexResp{Index: index, Error: errorString(n.timeoutError(ctx, err))}
+	case rpcChangeConfig:
+		var req changeConfigReq
+		if err := json.Unmarshal(body, &req); err != nil {
+			return nil, err
+		}
+		resp = &changeConfigResp{Error: errorString(n.timeoutError(ctx, n.changeConfigLocal(ctx, req.Op, req.Server)))}
+	default:
+		return nil, fmt.Errorf("unknown raft rpc %q", rpc)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(resp)
+}
+
+// notify будит всех, кто ждет изменения состояния узла. Вызывается под mu
+func (n *Node) notify() {
+	close(n.changed)
+	n.changed = make(chan struct{})
+}
+
+// waitFor ждет, пока cond, которая вызывается под mu, не вернет true
+func (n *Node) waitFor(ctx context.Context, cond func() (bool, error)) error {
+	for {
+		n.mu.Lock()
+		if n.stopped {
+			n.mu.Unlock()
+			return ErrStopped
+		}
+
+		ok, err := cond()
+		changed := n.changed
+		n.mu.Unlock()
+
+		if ok || err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-changed:
+		}
+	}
+}
+
+func (n *Node) signalApplier() {
+	select {
+	case n.applyCh <- struct{}{}:
+	default:
+	}
+}
+
+func (n *Node) resetElectionDeadline() {
+	timeout := n.cfg.ElectionTimeout + rand.N(n.cfg.ElectionTimeout)
+	n.electionDeadline = time.Now().Add(timeout)
+}
+
+// becomeFollower переводит узел в ведомые со сроком term. Вызывается под mu
+func (n *Node) becomeFollower(term uint64, leaderID string) {
+	if term > n.term {
+		n.term, n.votedFor = term, ""
+		n.persistState()
+	}
+
+	if n.state != StateFollower {
+		n.log.Info("raft node became follower", logger.Any("term", n.term))
+	}
+
+	n.state = StateFollower
+	n.leaderID = leaderID
+	n.nextIndex, n.matchIndex, n.ackSent = nil, nil, nil
+	n.inflight, n.pending = nil, nil
+	n.notify()
+}
+
+func (n *Node) persistState() {
+	if err := n.storage.SaveState(n.term, n.votedFor); err != nil {
+		n.log.Error("failed to save raft state", logger.Error(err))
+	}
+}
+
+func (n *Node) lastIndex() uint64 {
+	return n.snapIndex + uint64(len(n.entries))
+}
+
+func (n *Node) lastTerm() uint64 {
+	if len(n.entries) == 0 {
+		return n.snapTerm
+	}
+
+	return n.entries[len(n.entries)-1].Term
+}
+
+// termAt возвращает срок записи index, false - записи нет в журнале или она сжата снимком
+func (n *Node) termAt(index uint64) (uint64, bool) {
+	switch {
+	case index == n.snapIndex:
+		return n.snapTerm, true
+	case index < n.snapIndex || index > n.lastIndex():
+		return 0, false
+	}
+
+	return n.entries[index-n.snapIndex-1].Term, true
+}
+
+// slice возвращает записи с индексами [from, to)
+func (n *Node) slice(from, to uint64) []Entry {
+	return n.entries[from-n.snapIndex-1 : to-n.snapIndex-1]
+}
+
+// appendEntries сохраняет записи в хранилище и добавляет их в журнал. Вызывается под mu
+func (n *Node) appendEntries(entries []Entry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	if err := n.storage.AppendEntries(entries); err != nil {
+		return fmt.Errorf("failed to append raft entries: %w", err)
+	}
+
+	n.entries = append(n.entries, entries...)
+
+	for _, e := range entries {
+		if e.Type == EntryConfig {
+			n.reloadConfig()
+			break
+		}
+	}
+
+	return nil
+}
+
+// truncateFrom удаляет из журнала записи с индексом не меньше index, а ждущие их
+// клиенты получают ErrLeadershipLost. Вызывается под mu
+func (n *Node) truncateFrom(index uint64) error {
+	if err := n.storage.TruncateFrom(index); err != nil {
+		return fmt.Errorf("failed to truncate raft log: %w", err)
+	}
+
+	n.entries = n.entries[:index-n.snapIndex-1]
+	n.failWaiters(func(i uint64) bool { return i >= index }, ErrLeadershipLost)
+	n.reloadConfig()
+
+	return nil
+}
+
+func (n *Node) failWaiters(match func(index uint64) bool, err error) {
+	for index, w := range n.waiters {
+		if match(index) {
+			w.done <- applyResult{err: err}
+			delete(n.waiters, index)
+		}
+	}
+}
+
+// reloadConfig находит последний состав кластера в журнале. Вызывается под mu
+func (n *Node) reloadConfig() {
+	n.config, n.configIndex = n.configAt(n.lastIndex())
+}
+
+// configAt возвращает состав кластера, действующий после записи index
+func (n *Node) configAt(index uint64) (Configuration, uint64) {
+	for i := min(index, n.lastIndex()); i > n.snapIndex; i-- {
+		e := n.entries[i-n.snapIndex-1]
+		if e.Type != EntryConfig {
+			continue
+		}
+
+		var config Configuration
+		if err := json.Unmarshal(e.Data, &config); err != nil {
+			n.log.Error("failed to decode raft configuration", logger.Error(err))
+			continue
+		}
+
+		return config, e.Index
+	}
+
+	return n.snapConfig.clone(), n.snapIndex
+}
+
+// peers возвращает участников кластера, кроме самого узла
+func (n *Node) peers() []Server {
+	peers := make([]Server, 0, len(n.config.Servers))
+	for _, s := range n.config.Servers {
+		if s.ID != n.cfg.ID {
+			peers = append(peers, s)
+		}
+	}
+
+	return peers
+}
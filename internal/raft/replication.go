@@ -0,0 +1,354 @@
+package raft
+
+import (
+	"context"
+	"time"
+
+	"github.com/solumD/tasks-service/pkg/logger"
+)
+
+// broadcast отправляет всем участникам новые записи или пустой запрос, подтверждающий
+// лидерство. Вызывается под mu
+func (n *Node) broadcast() {
+	for _, peer := range n.peers() {
+		n.replicate(peer)
+	}
+}
+
+// replicate запускает отправку записей участнику. К каждому участнику одновременно
+// идет не больше одного запроса, поэтому ответы приходят в порядке запросов. Вызывается под mu
+func (n *Node) replicate(peer Server) {
+	if n.inflight[peer.ID] {
+		n.pending[peer.ID] = true
+		return
+	}
+
+	if _, ok := n.nextIndex[peer.ID]; !ok {
+		// участник добавлен в кластер после того, как узел стал лидером
+		n.nextIndex[peer.ID] = n.lastIndex() + 1
+	}
+
+	n.inflight[peer.ID] = true
+	n.pending[peer.ID] = false
+
+	go n.runReplication(peer, n.term)
+}
+
+func (n *Node) runReplication(peer Server, term uint64) {
+	for {
+		n.mu.Lock()
+		if n.stopped || n.state != StateLeader || n.term != term || !n.config.has(peer.ID) {
+			n.finishReplication(peer, term)
+			n.mu.Unlock()
+			return
+		}
+
+		n.pending[peer.ID] = false
+		next := n.nextIndex[peer.ID]
+		sendSnapshot := next <= n.snapIndex
+
+		var req *appendEntriesReq
+		if !sendSnapshot {
+			prevTerm, _ := n.termAt(next - 1)
+			last := min(n.lastIndex(), next+maxAppendEntries-1)
+			req = &appendEntriesReq{
+				Term:         term,
+				LeaderID:     n.cfg.ID,
+				PrevLogIndex: next - 1,
+				PrevLogTerm:  prevTerm,
+				Entries:      cloneEntries(n.slice(next, last+1)),
+				LeaderCommit: n.commitIndex,
+			}
+		}
+		n.mu.Unlock()
+
+		var ok bool
+		if sendSnapshot {
+			ok = n.sendSnapshot(peer, term)
+		} else {
+			ok = n.sendAppendEntries(peer, term, req)
+		}
+
+		n.mu.Lock()
+		more := ok && n.state == StateLeader && n.term == term &&
+			(n.pending[peer.ID] || n.nextIndex[peer.ID] <= n.lastIndex())
+		if !more {
+			n.finishReplication(peer, term)
+			n.mu.Unlock()
+			return
+		}
+		n.mu.Unlock()
+	}
+}
+
+// finishReplication снимает отметку об отправке, если узел все еще лидер срока term. Вызывается под mu
+func (n *Node) finishReplication(peer Server, term uint64) {
+	if n.state == StateLeader && n.term == term {
+		delete(n.inflight, peer.ID)
+	}
+}
+
+// sendAppendEntries отправляет записи и обрабатывает ответ, false - участник не ответил
+// или узел больше не лидер
+func (n *Node) sendAppendEntries(peer Server, term uint64, req *appendEntriesReq) bool {
+	ctx, cancel := context.WithTimeout(n.ctx, n.cfg.ElectionTimeout)
+	defer cancel()
+
+	sentAt := time.Now()
+
+	var resp appendEntriesResp
+	if err := n.transport.Call(ctx, peer, rpcAppendEntries, req, &resp); err != nil {
+		return false
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if !n.acknowledge(peer, term, resp.Term, sentAt) {
+		return false
+	}
+
+	if !resp.Success {
+		// ConflictIndex не больше PrevLogIndex, поэтому лидер всегда сдвигается назад
+		n.nextIndex[peer.ID] = max(1, min(resp.ConflictIndex, req.PrevLogIndex))
+		return true
+	}
+
+	if match := req.PrevLogIndex + uint64(len(req.Entries)); match > n.matchIndex[peer.ID] {
+		n.matchIndex[peer.ID] = match
+		n.advanceCommit()
+	}
+	n.nextIndex[peer.ID] = max(n.nextIndex[peer.ID], n.matchIndex[peer.ID]+1)
+
+	return true
+}
+
+func (n *Node) sendSnapshot(peer Server, term uint64) bool {
+	snapshot, err := n.storage.LoadSnapshot()
+	if err != nil || snapshot == nil {
+		n.log.Error("failed to load raft snapshot for peer", logger.String("peer", peer.ID))
+		return false
+	}
+
+	ctx, cancel := context.WithTimeout(n.ctx, n.cfg.ApplyTimeout)
+	defer cancel()
+
+	sentAt := time.Now()
+	req := &installSnapshotReq{
+		Term:     term,
+		LeaderID: n.cfg.ID,
+		Snapshot: *snapshot,
+	}
+
+	var resp installSnapshotResp
+	if err := n.transport.Call(ctx, peer, rpcInstallSnapshot, req, &resp); err != nil {
+		return false
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if !n.acknowledge(peer, term, resp.Term, sentAt) {
+		return false
+	}
+
+	n.log.Info("raft snapshot sent to peer",
+		logger.String("peer", peer.ID),
+		logger.Any("index", snapshot.LastIndex),
+	)
+
+	n.matchIndex[peer.ID] = max(n.matchIndex[peer.ID], snapshot.LastIndex)
+	n.nextIndex[peer.ID] = n.matchIndex[peer.ID] + 1
+	n.advanceCommit()
+
+	return true
+}
+
+// acknowledge учитывает ответ участника, false - узел больше не лидер срока term. Вызывается под mu
+func (n *Node) acknowledge(peer Server, term, respTerm uint64, sentAt time.Time) bool {
+	if respTerm > n.term {
+		n.becomeFollower(respTerm, "")
+		return false
+	}
+
+	if n.stopped || n.state != StateLeader || n.term != term {
+		return false
+	}
+
+	if sentAt.After(n.ackSent[peer.ID]) {
+		n.ackSent[peer.ID] = sentAt
+		n.notify()
+	}
+
+	return true
+}
+
+// hasQuorumSince сообщает, подтвердило ли большинство участников лидерство
+// на запросы, отправленные не раньше since. Вызывается под mu
+func (n *Node) hasQuorumSince(since time.Time) bool {
+	acks := 0
+	for _, s := range n.config.Servers {
+		if s.ID == n.cfg.ID || !n.ackSent[s.ID].Before(since) {
+			acks++
+		}
+	}
+
+	return acks >= n.config.quorum()
+}
+
+// advanceCommit фиксирует записи, которые есть у большинства участников. Лидер фиксирует
+// по количеству копий только записи своего срока, предыдущие фиксируются вместе с ними. Вызывается под mu
+func (n *Node) advanceCommit() {
+	for index := n.lastIndex(); index > n.commitIndex; index-- {
+		if term, _ := n.termAt(index); term != n.term {
+			break
+		}
+
+		copies := 0
+		for _, s := range n.config.Servers {
+			if (s.ID == n.cfg.ID && n.lastIndex() >= index) || n.matchIndex[s.ID] >= index {
+				copies++
+			}
+		}
+
+		if copies >= n.config.quorum() {
+			n.commitIndex = index
+			n.notify()
+			n.signalApplier()
+			n.broadcast()
+			return
+		}
+	}
+}
+
+func (n *Node) handleAppendEntries(req *appendEntriesReq) (*appendEntriesResp, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if req.Term < n.term {
+		return &appendEntriesResp{Term: n.term}, nil
+	}
+
+	n.followLeader(req.Term, req.LeaderID)
+
+	prevIndex, prevTerm, entries := req.PrevLogIndex, req.PrevLogTerm, req.Entries
+	lastNew := req.PrevLogIndex + uint64(len(req.Entries))
+
+	// записи до снимка зафиксированы и совпадают с записями лидера
+	if prevIndex < n.snapIndex {
+		skip := n.snapIndex - prevIndex
+		if skip > uint64(len(entries)) {
+			return &appendEntriesResp{Term: n.term, Success: true}, nil
+		}
+
+		prevIndex, prevTerm, entries = n.snapIndex, entries[skip-1].Term, entries[skip:]
+	}
+
+	if prevIndex > n.lastIndex() {
+		return &appendEntriesResp{Term: n.term, ConflictIndex: n.lastIndex() + 1}, nil
+	}
+
+	if term, _ := n.termAt(prevIndex); term != prevTerm {
+		// лидер пропустит весь срок term разом, а не по одной записи
+		conflict := prevIndex
+		for conflict-1 > n.snapIndex {
+			if t, _ := n.termAt(conflict - 1); t != term {
+				break
+			}
+			conflict--
+		}
+
+		return &appendEntriesResp{Term: n.term, ConflictIndex: conflict}, nil
+	}
+
+	for i, e := range entries {
+		if e.Index > n.lastIndex() {
+			if err := n.appendEntries(entries[i:]); err != nil {
+				return nil, err
+			}
+			break
+		}
+
+		if term, _ := n.termAt(e.Index); term != e.Term {
+			if err := n.truncateFrom(e.Index); err != nil {
+				return nil, err
+			}
+			if err := n.appendEntries(entries[i:]); err != nil {
+				return nil, err
+			}
+			break
+		}
+	}
+
+	if commit := min(req.LeaderCommit, lastNew); commit > n.commitIndex {
+		n.commitIndex = commit
+		n.notify()
+		n.signalApplier()
+	}
+
+	return &appendEntriesResp{Term: n.term, Success: true}, nil
+}
+
+func (n *Node) handleInstallSnapshot(req *installSnapshotReq) (*installSnapshotResp, error) {
+	n.fsmMu.Lock()
+	defer n.fsmMu.Unlock()
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if req.Term < n.term {
+		return &installSnapshotResp{Term: n.term}, nil
+	}
+
+	n.followLeader(req.Term, req.LeaderID)
+
+	snapshot := &req.Snapshot
+	if snapshot.LastIndex <= n.lastApplied {
+		return &installSnapshotResp{Term: n.term}, nil
+	}
+
+	// записи после снимка остаются, если журнал совпадает с лидером в точке снимка
+	var keep []Entry
+	if term, ok := n.termAt(snapshot.LastIndex); ok && term == snapshot.LastTerm {
+		keep = cloneEntries(n.entries[snapshot.LastIndex-n.snapIndex:])
+	} else if err := n.storage.TruncateFrom(n.snapIndex + 1); err != nil {
+		return nil, err
+	}
+
+	if err := n.storage.SaveSnapshot(snapshot); err != nil {
+		return nil, err
+	}
+
+	if err := n.fsm.Restore(snapshot.Data); err != nil {
+		return nil, err
+	}
+
+	n.log.Info("raft snapshot installed", logger.Any("index", snapshot.LastIndex))
+
+	// исход записей, сжатых снимком, узлу неизвестен
+	n.failWaiters(func(i uint64) bool { return i <= snapshot.LastIndex }, ErrTimeout)
+	if keep == nil {
+		n.failWaiters(func(uint64) bool { return true }, ErrLeadershipLost)
+	}
+
+	n.entries = keep
+	n.snapIndex, n.snapTerm = snapshot.LastIndex, snapshot.LastTerm
+	n.snapConfig = snapshot.Configuration.clone()
+	n.reloadConfig()
+	n.commitIndex = max(n.commitIndex, snapshot.LastIndex)
+	n.lastApplied = snapshot.LastIndex
+	n.notify()
+	n.signalApplier()
+
+	return &installSnapshotResp{Term: n.term}, nil
+}
+
+// followLeader принимает лидера срока term. Вызывается под mu
+func (n *Node) followLeader(term uint64, leaderID string) {
+	if term > n.term || n.state != StateFollower || n.leaderID != leaderID {
+		n.becomeFollower(term, leaderID)
+	}
+
+	n.lastContact = time.Now()
+	n.resetElectionDeadline()
+}
@@ -0,0 +1,114 @@
+package raft
+
+import "errors"
+
+type requestVoteReq struct {
+	Term         uint64 `json:"term"`
+	CandidateID  string `json:"candidate_id"`
+	LastLogIndex uint64 `json:"last_log_index"`
+	LastLogTerm  uint64 `json:"last_log_term"`
+	// PreVote предварительное голосование: узел спрашивает, отдадут ли ему голоса,
+	// не увеличивая свой срок. Так отрезанный от кластера узел не сбивает лидера,
+	// когда связь восстанавливается
+	PreVote bool `json:"pre_vote,omitempty"`
+}
+
+type requestVoteResp struct {
+	Term        uint64 `json:"term"`
+	VoteGranted bool   `json:"vote_granted"`
+}
+
+type appendEntriesReq struct {
+	Term         uint64  `json:"term"`
+	LeaderID     string  `json:"leader_id"`
+	PrevLogIndex uint64  `json:"prev_log_index"`
+	PrevLogTerm  uint64  `json:"prev_log_term"`
+	Entries      []Entry `json:"entries,omitempty"`
+	LeaderCommit uint64  `json:"leader_commit"`
+}
+
+type appendEntriesResp struct {
+	Term    uint64 `json:"term"`
+	Success bool   `json:"success"`
+	// ConflictIndex индекс, с которого лидеру стоит повторить отправку при отказе
+	ConflictIndex uint64 `json:"conflict_index,omitempty"`
+}
+
+type installSnapshotReq struct {
+	Term     uint64   `json:"term"`
+	LeaderID string   `json:"leader_id"`
+	Snapshot Snapshot `json:"snapshot"`
+}
+
+type installSnapshotResp struct {
+	Term uint64 `json:"term"`
+}
+
+type applyReq struct {
+	Data []byte `json:"data"`
+}
+
+type applyResp struct {
+	Data  []byte `json:"data,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+type readIndexReq struct{}
+
+type readIndexResp struct {
+	Index uint64 `json:"index"`
+	Error string `json:"error,omitempty"`
+}
+
+const (
+	changeAdd    = "add"
+	changeRemove = "remove"
+)
+
+type changeConfigReq struct {
+	Op     string `json:"op"`
+	Server Server `json:"server"`
+}
+
+type changeConfigResp struct {
+	Error string `json:"error,omitempty"`
+}
+
+// knownErrors ошибки, которые узел восстанавливает из ответа другого узла
+var knownErrors = []error{
+	ErrNotLeader,
+	ErrNoLeader,
+	ErrTimeout,
+	ErrLeadershipLost,
+	ErrConfigChangeInProgress,
+	ErrStopped,
+	ErrUnreachable,
+}
+
+func errorString(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	return err.Error()
+}
+
+// parseError восстанавливает ошибку из ответа. Ошибка ErrUnreachable удаленного узла
+// не означает, что запрос не дошел до него, поэтому она заменяется на ErrNoLeader
+func parseError(s string) error {
+	if s == "" {
+		return nil
+	}
+
+	for _, err := range knownErrors {
+		if err.Error() == s {
+			if errors.Is(err, ErrUnreachable) {
+				return ErrNoLeader
+			}
+
+			return err
+		}
+	}
+
+	return errors.New(s)
+}
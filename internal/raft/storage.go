@@ -0,0 +1,127 @@
+package raft
+
+import (
+	"slices"
+	"sync"
+)
+
+// Storage постоянное хранилище узла: срок и голос, журнал и последний снимок.
+// Методы записи возвращаются только после того, как данные сохранены
+type Storage interface {
+	LoadState() (term uint64, votedFor string, err error)
+	SaveState(term uint64, votedFor string) error
+	// LoadEntries возвращает записи журнала по возрастанию индекса
+	LoadEntries() ([]Entry, error)
+	AppendEntries(entries []Entry) error
+	// TruncateFrom удаляет записи с индексом не меньше index
+	TruncateFrom(index uint64) error
+	// LoadSnapshot возвращает последний снимок, nil - снимка нет
+	LoadSnapshot() (*Snapshot, error)
+	// SaveSnapshot сохраняет снимок и удаляет записи журнала с индексом не больше snapshot.LastIndex
+	SaveSnapshot(snapshot *Snapshot) error
+	Close() error
+}
+
+type memoryStorage struct {
+	term     uint64
+	votedFor string
+	entries  []Entry
+	snapshot *Snapshot
+	mu       *sync.Mutex
+}
+
+// NewMemoryStorage возвращает хранилище в памяти. Оно переживает остановку и
+// повторный запуск узла в том же процессе, поэтому подходит для тестов падений
+func NewMemoryStorage() *memoryStorage {
+	return &memoryStorage{
+		mu: &sync.Mutex{},
+	}
+}
+
+func (s *memoryStorage) LoadState() (uint64, string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.term, s.votedFor, nil
+}
+
+func (s *memoryStorage) SaveState(term uint64, votedFor string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.term, s.votedFor = term, votedFor
+
+	return nil
+}
+
+func (s *memoryStorage) LoadEntries() ([]Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return cloneEntries(s.entries), nil
+}
+
+func (s *memoryStorage) AppendEntries(entries []Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.entries = append(s.entries, cloneEntries(entries)...)
+
+	return nil
+}
+
+func (s *memoryStorage) TruncateFrom(index uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.entries = slices.DeleteFunc(s.entries, func(e Entry) bool {
+		return e.Index >= index
+	})
+
+	return nil
+}
+
+func (s *memoryStorage) LoadSnapshot() (*Snapshot, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.snapshot == nil {
+		return nil, nil
+	}
+
+	return cloneSnapshot(s.snapshot), nil
+}
+
+func (s *memoryStorage) SaveSnapshot(snapshot *Snapshot) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.snapshot = cloneSnapshot(snapshot)
+	s.entries = slices.DeleteFunc(s.entries, func(e Entry) bool {
+		return e.Index <= snapshot.LastIndex
+	})
+
+	return nil
+}
+
+func (s *memoryStorage) Close() error {
+	return nil
+}
+
+func cloneEntries(entries []Entry) []Entry {
+	clone := make([]Entry, len(entries))
+	for i, e := range entries {
+		clone[i] = e
+		clone[i].Data = slices.Clone(e.Data)
+	}
+
+	return clone
+}
+
+func cloneSnapshot(snapshot *Snapshot) *Snapshot {
+	clone := *snapshot
+	clone.Configuration = snapshot.Configuration.clone()
+	clone.Data = slices.Clone(snapshot.Data)
+
+	return &clone
+}
@@ -0,0 +1,491 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/solumD/tasks-service/internal/raft"
+	"github.com/solumD/tasks-service/pkg/logger"
+)
+
+const waitTimeout = 10 * time.Second
+
+// listFSM автомат, который запоминает примененные команды по порядку
+type listFSM struct {
+	items []string
+	mu    *sync.Mutex
+}
+
+func newListFSM() *listFSM {
+	return &listFSM{mu: &sync.Mutex{}}
+}
+
+func (f *listFSM) Apply(data []byte) []byte {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.items = append(f.items, string(data))
+
+	return []byte(strconv.Itoa(len(f.items)))
+}
+
+func (f *listFSM) Snapshot() ([]byte, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return json.Marshal(f.items)
+}
+
+func (f *listFSM) Restore(data []byte) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.items = nil
+
+	return json.Unmarshal(data, &f.items)
+}
+
+func (f *listFSM) list() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return slices.Clone(f.items)
+}
+
+// cluster узлы в одном процессе, связанные сетью в памяти
+type cluster struct {
+	t         *testing.T
+	network   *raft.InmemNetwork
+	bootstrap []raft.Server
+	threshold uint64
+	// dir директория файловых хранилищ, пустая строка - хранилища в памяти
+	dir string
+
+	storages map[string]raft.Storage
+	fsms     map[string]*listFSM
+	nodes    map[string]*raft.Node
+}
+
+func newCluster(t *testing.T, size int, threshold uint64) *cluster {
+	t.Helper()
+
+	return newClusterIn(t, size, threshold, "")
+}
+
+// newClusterIn запускает кластер из size узлов с файловыми хранилищами в dir
+func newClusterIn(t *testing.T, size int, threshold uint64, dir string) *cluster {
+	t.Helper()
+
+	c := &cluster{
+		t:         t,
+		network:   raft.NewInmemNetwork(),
+		threshold: threshold,
+		dir:       dir,
+		storages:  make(map[string]raft.Storage),
+		fsms:      make(map[string]*listFSM),
+		nodes:     make(map[string]*raft.Node),
+	}
+
+	for i := 1; i <= size; i++ {
+		id := fmt.Sprintf("n%d", i)
+		c.bootstrap = append(c.bootstrap, raft.Server{ID: id, Address: id})
+	}
+
+	for _, s := range c.bootstrap {
+		c.start(s.ID, c.bootstrap)
+	}
+
+	t.Cleanup(func() {
+		for id := range c.nodes {
+			c.stop(id)
+		}
+	})
+
+	return c
+}
+
+// start запускает узел id. Повторный запуск использует прежнее хранилище, но новый автомат
+func (c *cluster) start(id string, bootstrap []raft.Server) {
+	c.t.Helper()
+
+	switch {
+	case c.dir != "":
+		storage, err := raft.NewFileStorage(filepath.Join(c.dir, id))
+		if err != nil {
+			c.t.Fatalf("failed to open storage of %s: %v", id, err)
+		}
+		c.storages[id] = storage
+	case c.storages[id] == nil:
+		c.storages[id] = raft.NewMemoryStorage()
+	}
+
+	c.fsms[id] = newListFSM()
+	node := raft.NewNode(raft.Config{
+		ID:                id,
+		Bootstrap:         bootstrap,
+		HeartbeatInterval: 20 * time.Millisecond,
+		ElectionTimeout:   150 * time.Millisecond,
+		ApplyTimeout:      2 * time.Second,
+		SnapshotThreshold: c.threshold,
+	}, c.fsms[id], c.storages[id], c.network.Transport(id), testLogger())
+
+	if err := node.Start(); err != nil {
+		c.t.Fatalf("failed to start %s: %v", id, err)
+	}
+
+	c.nodes[id] = node
+}
+
+func (c *cluster) stop(id string) {
+	c.nodes[id].Stop()
+	delete(c.nodes, id)
+
+	if c.dir != "" {
+		c.storages[id].Close()
+	}
+}
+
+// waitLeader ждет, пока все узлы из ids признают одного лидера, и возвращает его
+func (c *cluster) waitLeader(ids ...string) string {
+	c.t.Helper()
+
+	if len(ids) == 0 {
+		for id := range c.nodes {
+			ids = append(ids, id)
+		}
+	}
+
+	var leader string
+	c.eventually("single leader", func() bool {
+		leader = c.nodes[ids[0]].Leader()
+		if leader == "" || !slices.Contains(ids, leader) || c.nodes[leader].Status().State != raft.StateLeader {
+			return false
+		}
+
+		for _, id := range ids {
+			if c.nodes[id].Leader() != leader {
+				return false
+			}
+		}
+
+		return true
+	})
+
+	return leader
+}
+
+func (c *cluster) follower(leader string) string {
+	for id := range c.nodes {
+		if id != leader {
+			return id
+		}
+	}
+
+	c.t.Fatal("no followers")
+	return ""
+}
+
+func (c *cluster) apply(id string, cmd string) {
+	c.t.Helper()
+
+	if _, err := c.nodes[id].Apply(context.Background(), []byte(cmd)); err != nil {
+		c.t.Fatalf("failed to apply %q on %s: %v", cmd, id, err)
+	}
+}
+
+// waitApplied ждет, пока автоматы всех запущенных узлов не совпадут с want
+func (c *cluster) waitApplied(want []string) {
+	c.t.Helper()
+
+	c.eventually("all nodes applied commands", func() bool {
+		for id := range c.nodes {
+			if !slices.Equal(c.fsms[id].list(), want) {
+				return false
+			}
+		}
+
+		return true
+	})
+}
+
+func (c *cluster) eventually(what string, cond func() bool) {
+	c.t.Helper()
+
+	deadline := time.Now().Add(waitTimeout)
+	for time.Now().Before(deadline) {
+		if cond() {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	for id, node := range c.nodes {
+		c.t.Logf("%s: %+v, fsm %v", id, node.Status(), c.fsms[id].list())
+	}
+	c.t.Fatalf("timed out waiting for %s", what)
+}
+
+func commands(from, to int) []string {
+	var list []string
+	for i := from; i <= to; i++ {
+		list = append(list, fmt.Sprintf("cmd-%d", i))
+	}
+
+	return list
+}
+
+func TestElectionAndReplication(t *testing.T) {
+	for _, size := range []int{1, 3, 5} {
+		t.Run(fmt.Sprintf("%d nodes", size), func(t *testing.T) {
+			c := newCluster(t, size, 1000)
+			leader := c.waitLeader()
+
+			// команды через лидера и через ведомый узел, который пересылает их лидеру
+			var want []string
+			for i, cmd := range commands(1, 10) {
+				id := leader
+				if i%2 == 1 && size > 1 {
+					id = c.follower(leader)
+				}
+
+				res, err := c.nodes[id].Apply(context.Background(), []byte(cmd))
+				if err != nil {
+					t.Fatalf("failed to apply on %s: %v", id, err)
+				}
+
+				want = append(want, cmd)
+				if string(res) != strconv.Itoa(len(want)) {
+					t.Fatalf("Apply() result = %s, want %d", res, len(want))
+				}
+			}
+
+			c.waitApplied(want)
+		})
+	}
+}
+
+func TestBarrierReadsOwnWrites(t *testing.T) {
+	c := newCluster(t, 3, 1000)
+	leader := c.waitLeader()
+
+	for _, cmd := range commands(1, 5) {
+		c.apply(leader, cmd)
+
+		for id, node := range c.nodes {
+			if err := node.Barrier(context.Background()); err != nil {
+				t.Fatalf("Barrier() on %s error = %v", id, err)
+			}
+
+			if got := c.fsms[id].list(); got[len(got)-1] != cmd {
+				t.Fatalf("%s did not apply %s after Barrier(), fsm %v", id, cmd, got)
+			}
+		}
+	}
+}
+
+func TestPartition(t *testing.T) {
+	c := newCluster(t, 5, 1000)
+	old := c.waitLeader()
+	c.apply(old, "before")
+
+	var majority []string
+	for id := range c.nodes {
+		if id != old && len(majority) < 3 {
+			majority = append(majority, id)
+		}
+	}
+	minority := []string{old}
+	for id := range c.nodes {
+		if id != old && !slices.Contains(majority, id) {
+			minority = append(minority, id)
+		}
+	}
+
+	c.network.Partition(majority, minority)
+
+	// лидер в меньшинстве не может зафиксировать запись и уступает
+	_, err := c.nodes[old].Apply(context.Background(), []byte("lost"))
+	if err == nil {
+		t.Fatal("Apply() in minority succeeded")
+	}
+
+	c.waitLeader(majority...)
+	c.apply(majority[0], "after")
+	if st := c.nodes[old].Status(); st.State == raft.StateLeader {
+		t.Fatalf("old leader still leads in minority: %+v", st)
+	}
+
+	c.network.Heal()
+	c.waitLeader()
+	c.waitApplied([]string{"before", "after"})
+}
+
+func TestLeaderIsolatedByOneWayLink(t *testing.T) {
+	c := newCluster(t, 3, 1000)
+	leader := c.waitLeader()
+
+	// запросы лидера доходят, но ответы теряются: лидер должен уступить
+	for id := range c.nodes {
+		if id != leader {
+			c.network.Block(id, leader)
+		}
+	}
+
+	c.eventually("leader steps down", func() bool {
+		return c.nodes[leader].Status().State != raft.StateLeader
+	})
+
+	c.network.Heal()
+	leader = c.waitLeader()
+	c.apply(leader, "cmd")
+	c.waitApplied([]string{"cmd"})
+}
+
+func TestCrashAndRestart(t *testing.T) {
+	c := newCluster(t, 3, 1000)
+	leader := c.waitLeader()
+	c.apply(leader, "cmd-1")
+
+	// упавший ведомый узел догоняет кластер после перезапуска
+	crashed := c.follower(leader)
+	c.stop(crashed)
+
+	c.apply(leader, "cmd-2")
+	c.apply(leader, "cmd-3")
+
+	c.start(crashed, c.bootstrap)
+	c.waitApplied(commands(1, 3))
+
+	// перезапуск всего кластера
+	for _, s := range c.bootstrap {
+		c.stop(s.ID)
+	}
+	for _, s := range c.bootstrap {
+		c.start(s.ID, c.bootstrap)
+	}
+
+	leader = c.waitLeader()
+	c.apply(leader, "cmd-4")
+	c.waitApplied(commands(1, 4))
+}
+
+func TestCrashAndRestartWithFileStorage(t *testing.T) {
+	c := newClusterIn(t, 3, 4, t.TempDir())
+	leader := c.waitLeader()
+
+	for _, cmd := range commands(1, 10) {
+		c.apply(leader, cmd)
+	}
+
+	for _, s := range c.bootstrap {
+		c.stop(s.ID)
+	}
+	for _, s := range c.bootstrap {
+		c.start(s.ID, c.bootstrap)
+	}
+
+	leader = c.waitLeader()
+	c.apply(leader, "cmd-11")
+	c.waitApplied(commands(1, 11))
+}
+
+func TestSnapshotInstall(t *testing.T) {
+	c := newCluster(t, 3, 5)
+	leader := c.waitLeader()
+
+	lagging := c.follower(leader)
+	c.stop(lagging)
+
+	want := commands(1, 30)
+	for _, cmd := range want {
+		c.apply(leader, cmd)
+	}
+
+	c.eventually("leader compacts log", func() bool {
+		return c.nodes[leader].Status().SnapshotIndex > 10
+	})
+
+	c.start(lagging, c.bootstrap)
+	c.waitApplied(want)
+
+	if st := c.nodes[lagging].Status(); st.SnapshotIndex == 0 {
+		t.Fatalf("lagging node did not install snapshot: %+v", st)
+	}
+
+	// узел восстанавливается из своего снимка после перезапуска
+	c.stop(lagging)
+	c.start(lagging, c.bootstrap)
+	c.apply(leader, "cmd-31")
+	c.waitApplied(commands(1, 31))
+}
+
+func TestMembershipChanges(t *testing.T) {
+	c := newCluster(t, 3, 1000)
+	leader := c.waitLeader()
+	c.apply(leader, "cmd-1")
+
+	// новый узел запускается без начального состава и ждет, пока его добавят
+	follower := c.follower(leader)
+	c.start("n4", nil)
+	if err := c.nodes[follower].AddServer(context.Background(), raft.Server{ID: "n4", Address: "n4"}); err != nil {
+		t.Fatalf("AddServer() error = %v", err)
+	}
+
+	c.apply(leader, "cmd-2")
+	c.waitApplied(commands(1, 2))
+
+	if got := len(c.nodes["n4"].Status().Servers); got != 4 {
+		t.Fatalf("n4 sees %d servers, want 4", got)
+	}
+
+	// исключенный лидер уступает, оставшиеся выбирают нового
+	if err := c.nodes[leader].RemoveServer(context.Background(), leader); err != nil {
+		t.Fatalf("RemoveServer() error = %v", err)
+	}
+	c.stop(leader)
+
+	newLeader := c.waitLeader()
+	if newLeader == leader {
+		t.Fatal("removed leader is still the leader")
+	}
+
+	c.apply(newLeader, "cmd-3")
+	c.waitApplied(commands(1, 3))
+
+	if got := len(c.nodes[newLeader].Status().Servers); got != 3 {
+		t.Fatalf("cluster has %d servers, want 3", got)
+	}
+}
+
+func TestApplyWithoutQuorum(t *testing.T) {
+	c := newCluster(t, 3, 1000)
+	leader := c.waitLeader()
+
+	for id := range c.nodes {
+		if id != leader {
+			c.stop(id)
+		}
+	}
+
+	_, err := c.nodes[leader].Apply(context.Background(), []byte("cmd"))
+	if !errors.Is(err, raft.ErrTimeout) && !errors.Is(err, raft.ErrNoLeader) && !errors.Is(err, raft.ErrLeadershipLost) {
+		t.Fatalf("Apply() error = %v, want timeout, no leader or leadership lost", err)
+	}
+}
+
+func testLogger() *slog.Logger {
+	if os.Getenv("RAFT_DEBUG") != "" {
+		return slog.New(slog.NewTextHandler(os.Stderr, nil))
+	}
+	return logger.NewMockLogger()
+}
@@ -0,0 +1,196 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/solumD/tasks-service/internal/raft"
+)
+
+func entries(from, to uint64, term uint64) []raft.Entry {
+	var list []raft.Entry
+	for i := from; i <= to; i++ {
+		list = append(list, raft.Entry{Index: i, Term: term, Type: raft.EntryCommand, Data: []byte{byte(i)}})
+	}
+
+	return list
+}
+
+func indexes(list []raft.Entry) []uint64 {
+	var idx []uint64
+	for _, e := range list {
+		idx = append(idx, e.Index)
+	}
+
+	return idx
+}
+
+func TestStorage(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := []struct {
+		name string
+		open func(t *testing.T) raft.Storage
+	}{
+		{
+			name: "memory",
+			open: func(t *testing.T) raft.Storage {
+				return raft.NewMemoryStorage()
+			},
+		},
+		{
+			name: "file",
+			open: func(t *testing.T) raft.Storage {
+				s, err := raft.NewFileStorage(filepath.Join(dir, "node"))
+				if err != nil {
+					t.Fatalf("NewFileStorage() error = %v", err)
+				}
+
+				return s
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := tt.open(t)
+			defer s.Close()
+
+			if err := s.SaveState(3, "n2"); err != nil {
+				t.Fatalf("SaveState() error = %v", err)
+			}
+			if err := s.AppendEntries(entries(1, 10, 1)); err != nil {
+				t.Fatalf("AppendEntries() error = %v", err)
+			}
+			if err := s.TruncateFrom(8); err != nil {
+				t.Fatalf("TruncateFrom() error = %v", err)
+			}
+			if err := s.AppendEntries(entries(8, 9, 2)); err != nil {
+				t.Fatalf("AppendEntries() error = %v", err)
+			}
+
+			snapshot := &raft.Snapshot{
+				LastIndex:     4,
+				LastTerm:      1,
+				Configuration: raft.Configuration{Servers: []raft.Server{{ID: "n1", Address: "a"}}},
+				Data:          []byte("state"),
+			}
+			if err := s.SaveSnapshot(snapshot); err != nil {
+				t.Fatalf("SaveSnapshot() error = %v", err)
+			}
+
+			check := func(s raft.Storage) {
+				t.Helper()
+
+				term, votedFor, err := s.LoadState()
+				if err != nil || term != 3 || votedFor != "n2" {
+					t.Fatalf("LoadState() = %d, %q, %v", term, votedFor, err)
+				}
+
+				list, err := s.LoadEntries()
+				if err != nil {
+					t.Fatalf("LoadEntries() error = %v", err)
+				}
+				if got, want := indexes(list), []uint64{5, 6, 7, 8, 9}; !slices.Equal(got, want) {
+					t.Fatalf("LoadEntries() indexes = %v, want %v", got, want)
+				}
+				if list[len(list)-1].Term != 2 {
+					t.Fatalf("last entry term = %d, want 2", list[len(list)-1].Term)
+				}
+
+				got, err := s.LoadSnapshot()
+				if err != nil || got == nil || got.LastIndex != 4 || string(got.Data) != "state" || len(got.Configuration.Servers) != 1 {
+					t.Fatalf("LoadSnapshot() = %+v, %v", got, err)
+				}
+			}
+
+			check(s)
+
+			if tt.name == "file" {
+				s.Close()
+				s = tt.open(t)
+				check(s)
+			}
+		})
+	}
+}
+
+func TestFileStorageDropsTornTail(t *testing.T) {
+	dir := t.TempDir()
+
+	s, err := raft.NewFileStorage(dir)
+	if err != nil {
+		t.Fatalf("NewFileStorage() error = %v", err)
+	}
+
+	if err := s.AppendEntries(entries(1, 3, 1)); err != nil {
+		t.Fatalf("AppendEntries() error = %v", err)
+	}
+	s.Close()
+
+	// запись, которую узел не успел дописать до падения
+	f, err := os.OpenFile(filepath.Join(dir, "log.jsonl"), os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"index":4,"term":1,"ty`)
+	f.Close()
+
+	s, err = raft.NewFileStorage(dir)
+	if err != nil {
+		t.Fatalf("NewFileStorage() error = %v", err)
+	}
+	defer s.Close()
+
+	if err := s.AppendEntries(entries(4, 4, 1)); err != nil {
+		t.Fatalf("AppendEntries() error = %v", err)
+	}
+	s.Close()
+
+	s, err = raft.NewFileStorage(dir)
+	if err != nil {
+		t.Fatalf("NewFileStorage() error = %v", err)
+	}
+
+	list, _ := s.LoadEntries()
+	if got, want := indexes(list), []uint64{1, 2, 3, 4}; !slices.Equal(got, want) {
+		t.Fatalf("LoadEntries() indexes = %v, want %v", got, want)
+	}
+}
+
+func TestFileStorageFailsOnCorruptEntry(t *testing.T) {
+	dir := t.TempDir()
+
+	s, err := raft.NewFileStorage(dir)
+	if err != nil {
+		t.Fatalf("NewFileStorage() error = %v", err)
+	}
+
+	if err := s.AppendEntries(entries(1, 3, 1)); err != nil {
+		t.Fatalf("AppendEntries() error = %v", err)
+	}
+	s.Close()
+
+	path := filepath.Join(dir, "log.jsonl")
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// испорченная запись перед целыми не отбрасывается вместе с ними
+	corrupted := append([]byte("garbage\n"), data...)
+	if err := os.WriteFile(path, corrupted, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := raft.NewFileStorage(dir); err == nil {
+		t.Fatal("NewFileStorage() error = nil, want error on corrupt entry")
+	}
+
+	if after, _ := os.ReadFile(path); !slices.Equal(after, corrupted) {
+		t.Fatal("raft log changed after failed open")
+	}
+}
@@ -0,0 +1,26 @@
+package raft
+
+import "context"
+
+// Названия запросов между узлами
+const (
+	rpcRequestVote     = "request_vote"
+	rpcAppendEntries   = "append_entries"
+	rpcInstallSnapshot = "install_snapshot"
+	rpcApply           = "apply"
+	rpcReadIndex       = "read_index"
+	rpcChangeConfig    = "change_config"
+)
+
+// Handler обрабатывает входящий запрос rpc с телом req в JSON и возвращает ответ в JSON
+type Handler func(ctx context.Context, rpc string, req []byte) ([]byte, error)
+
+// Transport передает запросы между узлами кластера. Реализация выбирается при
+// создании узла: в памяти процесса для тестов или по HTTP
+type Transport interface {
+	// Call отправляет узлу target запрос rpc и декодирует ответ в resp. Если запрос
+	// точно не доставлен, возвращает ошибку, для которой errors.Is(err, ErrUnreachable)
+	Call(ctx context.Context, target Server, rpc string, req any, resp any) error
+	// Serve устанавливает обработчик входящих запросов узла, nil - узел не принимает запросы
+	Serve(handler Handler)
+}
@@ -0,0 +1,119 @@
+package raft
+
+import (
+	"errors"
+	"slices"
+)
+
+var (
+	// ErrNotLeader узел не лидер, а лидер неизвестен или недоступен
+	ErrNotLeader = errors.New("raft node is not the leader")
+	// ErrNoLeader в кластере нет лидера, до которого может достучаться узел
+	ErrNoLeader = errors.New("raft cluster has no reachable leader")
+	// ErrTimeout запрос не завершился вовремя, изменение могло быть применено, а могло и нет
+	ErrTimeout = errors.New("raft request timed out, outcome is unknown")
+	// ErrLeadershipLost лидер потерял лидерство до фиксации записи, изменение не применено
+	ErrLeadershipLost = errors.New("raft leadership lost before commit")
+	// ErrConfigChangeInProgress предыдущее изменение состава кластера еще не зафиксировано
+	ErrConfigChangeInProgress = errors.New("raft configuration change in progress")
+	// ErrStopped узел остановлен
+	ErrStopped = errors.New("raft node is stopped")
+	// ErrUnreachable запрос не доставлен узлу. Транспорт возвращает эту ошибку, только
+	// если узел точно не получил запрос, поэтому его можно безопасно повторить
+	ErrUnreachable = errors.New("raft node is unreachable")
+)
+
+// State роль узла в кластере
+type State string
+
+const (
+	StateFollower  State = "follower"
+	StateCandidate State = "candidate"
+	StateLeader    State = "leader"
+)
+
+// Server участник кластера. Address - адрес для транспорта
+type Server struct {
+	ID      string `json:"id"`
+	Address string `json:"address"`
+}
+
+// Configuration состав кластера, все участники голосуют
+type Configuration struct {
+	Servers []Server `json:"servers"`
+}
+
+func (c Configuration) server(id string) (Server, bool) {
+	for _, s := range c.Servers {
+		if s.ID == id {
+			return s, true
+		}
+	}
+
+	return Server{}, false
+}
+
+func (c Configuration) has(id string) bool {
+	_, ok := c.server(id)
+	return ok
+}
+
+func (c Configuration) quorum() int {
+	return len(c.Servers)/2 + 1
+}
+
+func (c Configuration) clone() Configuration {
+	return Configuration{Servers: slices.Clone(c.Servers)}
+}
+
+// EntryType вид записи журнала
+type EntryType string
+
+const (
+	// EntryCommand команда конечного автомата
+	EntryCommand EntryType = "command"
+	// EntryNoop пустая запись, которую лидер добавляет в начале своего срока
+	EntryNoop EntryType = "noop"
+	// EntryConfig новый состав кластера в формате JSON
+	EntryConfig EntryType = "config"
+)
+
+// Entry запись журнала
+type Entry struct {
+	Index uint64    `json:"index"`
+	Term  uint64    `json:"term"`
+	Type  EntryType `json:"type"`
+	Data  []byte    `json:"data,omitempty"`
+}
+
+// Snapshot состояние конечного автомата после применения записи LastIndex
+type Snapshot struct {
+	LastIndex     uint64        `json:"last_index"`
+	LastTerm      uint64        `json:"last_term"`
+	Configuration Configuration `json:"configuration"`
+	Data          []byte        `json:"data"`
+}
+
+// FSM конечный автомат, который реплицирует кластер. Apply должен быть
+// детерминированным: одинаковые команды в одинаковом порядке дают одинаковое состояние
+type FSM interface {
+	// Apply применяет команду и возвращает результат для узла, который ее предложил
+	Apply(data []byte) []byte
+	// Snapshot возвращает все состояние автомата
+	Snapshot() ([]byte, error)
+	// Restore заменяет состояние автомата снимком
+	Restore(data []byte) error
+}
+
+// Status состояние узла
+type Status struct {
+	ID            string   `json:"id"`
+	State         State    `json:"state"`
+	Term          uint64   `json:"term"`
+	LeaderID      string   `json:"leader_id,omitempty"`
+	CommitIndex   uint64   `json:"commit_index"`
+	LastApplied   uint64   `json:"last_applied"`
+	LastIndex     uint64   `json:"last_index"`
+	SnapshotIndex uint64   `json:"snapshot_index"`
+	Servers       []Server `json:"servers"`
+}
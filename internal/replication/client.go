@@ -0,0 +1,89 @@
+package replication
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/solumD/tasks-service/internal/handler/v1/dto"
+	"github.com/solumD/tasks-service/internal/model"
+)
+
+// requestTimeout запас времени на запрос к ведущему узлу сверх ожидания новых записей
+const requestTimeout = 10 * time.Second
+
+// client читает журнал и снимки ведущего узла по HTTP
+type client struct {
+	leaderURL  string
+	httpClient *http.Client
+}
+
+func newClient(leaderURL string) *client {
+	return &client{
+		leaderURL:  strings.TrimRight(leaderURL, "/"),
+		httpClient: &http.Client{},
+	}
+}
+
+// readLog запрашивает записи журнала после записи с номером from
+func (c *client) readLog(ctx context.Context, followerID string, from int, wait time.Duration) (*model.LogBatch, error) {
+	query := url.Values{}
+	query.Set("from", strconv.Itoa(from))
+	query.Set("wait", wait.String())
+	query.Set("follower", followerID)
+
+	var resp dto.ReplicationLogResp
+	if err := c.get(ctx, "/replication/log?"+query.Encode(), wait+requestTimeout, &resp); err != nil {
+		return nil, err
+	}
+
+	return dto.FromReplicationLogRespToBatch(&resp), nil
+}
+
+// snapshot запрашивает снимок всех задач ведущего узла
+func (c *client) snapshot(ctx context.Context) (*model.ReplicationSnapshot, error) {
+	var resp dto.ReplicationSnapshotResp
+	if err := c.get(ctx, "/replication/snapshot", requestTimeout, &resp); err != nil {
+		return nil, err
+	}
+
+	return dto.FromReplicationSnapshotRespToSnapshot(&resp), nil
+}
+
+func (c *client) get(ctx context.Context, path string, timeout time.Duration, v any) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.leaderURL+path, nil)
+	if err != nil {
+		return fmt.Errorf("failed to build request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to request leader: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusGone:
+		return ErrLogTruncated
+	default:
+		var errResp dto.ErrorResponse
+		json.NewDecoder(resp.Body).Decode(&errResp)
+
+		return fmt.Errorf("leader responded with status %d: %s", resp.StatusCode, errResp.ErrorMessage)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode leader response: %w", err)
+	}
+
+	return nil
+}
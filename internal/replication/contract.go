@@ -0,0 +1,77 @@
+package replication
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/solumD/tasks-service/internal/backup"
+	"github.com/solumD/tasks-service/internal/model"
+	"github.com/solumD/tasks-service/internal/usecase"
+)
+
+var (
+	// ErrNotLeader узел не ведущий и не отдает журнал репликации
+	ErrNotLeader = errors.New("node is not a replication leader")
+	// ErrLogTruncated запрошенных записей уже нет в журнале, ведомому узлу нужен снимок
+	ErrLogTruncated = errors.New("replication log position is not available")
+)
+
+// LeaderStore хранилище задач ведущего узла
+type LeaderStore interface {
+	usecase.TaskRepo
+	backup.Store
+}
+
+// FollowerStore хранилище задач ведомого узла, в которое применяются записи журнала
+type FollowerStore interface {
+	// ImportTasks записывает задачи как есть и сдвигает счетчик ID до nextID
+	ImportTasks(ctx context.Context, tasks []*model.Task, nextID int) error
+	ReplaceTasks(ctx context.Context, tasks []*model.Task, nextID int) error
+	PurgeDeletedTasks(ctx context.Context, deletedBefore time.Time) (int, error)
+}
+
+// RevisionStore хранилище ревизий ведомого узла
+type RevisionStore interface {
+	AddRevision(ctx context.Context, rev *model.Revision) error
+}
+
+// newNodeID возвращает случайный идентификатор узла. Ведущий узел получает новый
+// идентификатор при каждом запуске, поэтому ведомые узлы замечают его перезапуск
+func newNodeID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+
+	return hex.EncodeToString(b)
+}
+
+type standalone struct {
+	id string
+}
+
+// NewStandalone возвращает узел без репликации
+func NewStandalone() *standalone {
+	return &standalone{
+		id: newNodeID(),
+	}
+}
+
+// ReadLog всегда возвращает ErrNotLeader
+func (s *standalone) ReadLog(_ context.Context, _ string, _ int, _ time.Duration) (*model.LogBatch, error) {
+	return nil, ErrNotLeader
+}
+
+// Snapshot всегда возвращает ErrNotLeader
+func (s *standalone) Snapshot(_ context.Context) (*model.ReplicationSnapshot, error) {
+	return nil, ErrNotLeader
+}
+
+// Status возвращает состояние узла без репликации
+func (s *standalone) Status() *model.ReplicationStatus {
+	return &model.ReplicationStatus{
+		Role:   model.ReplicationStandalone,
+		NodeID: s.id,
+	}
+}
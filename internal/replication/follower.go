@@ -0,0 +1,280 @@
+package replication
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/solumD/tasks-service/internal/model"
+	"github.com/solumD/tasks-service/pkg/logger"
+)
+
+// retryDelay пауза перед повторным запросом к ведущему узлу после ошибки
+const retryDelay = time.Second
+
+type follower struct {
+	tasks     FollowerStore
+	revisions RevisionStore
+	client    *client
+	leaderURL string
+	id        string
+	wait      time.Duration
+	log       *slog.Logger
+
+	mu            *sync.Mutex
+	leaderID      string
+	applied       int
+	head          int
+	caughtUp      bool
+	caughtUpAt    time.Time
+	lastContactAt time.Time
+	lastErr       error
+
+	cancel context.CancelFunc
+	done   chan struct{}
+	once   *sync.Once
+}
+
+// NewFollower возвращает ведомый узел, который читает журнал ведущего узла leaderURL,
+// ожидая новые записи до wait, и применяет их к своим хранилищам задач и ревизий
+func NewFollower(tasks FollowerStore, revisions RevisionStore, leaderURL string, wait time.Duration, log *slog.Logger) *follower {
+	return &follower{
+		tasks:      tasks,
+		revisions:  revisions,
+		client:     newClient(leaderURL),
+		leaderURL:  leaderURL,
+		id:         newNodeID(),
+		wait:       wait,
+		log:        log,
+		mu:         &sync.Mutex{},
+		caughtUpAt: time.Now(),
+		done:       make(chan struct{}),
+		once:       &sync.Once{},
+	}
+}
+
+// Run запускает репликацию в отдельной горутине. Сначала узел загружает снимок
+// задач ведущего узла, затем применяет его журнал
+func (f *follower) Run(ctx context.Context) {
+	ctx, f.cancel = context.WithCancel(ctx)
+
+	go func() {
+		defer close(f.done)
+
+		for {
+			err := f.sync(ctx)
+			if ctx.Err() != nil {
+				return
+			}
+
+			if err == nil {
+				continue
+			}
+
+			f.fail(err)
+
+			// ошибки не останавливают репликацию, следующий запрос повторит попытку
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(retryDelay):
+			}
+		}
+	}()
+}
+
+// Stop останавливает репликацию и ждет завершения текущего запроса
+func (f *follower) Stop() {
+	f.once.Do(func() {
+		if f.cancel == nil {
+			close(f.done)
+			return
+		}
+
+		f.cancel()
+	})
+
+	<-f.done
+}
+
+// ReadLog всегда возвращает ErrNotLeader: ведомые узлы не передают журнал дальше
+func (f *follower) ReadLog(_ context.Context, _ string, _ int, _ time.Duration) (*model.LogBatch, error) {
+	return nil, ErrNotLeader
+}
+
+// Snapshot всегда возвращает ErrNotLeader
+func (f *follower) Snapshot(_ context.Context) (*model.ReplicationSnapshot, error) {
+	return nil, ErrNotLeader
+}
+
+// Status возвращает состояние репликации. Отставание равно нулю, пока последний запрос
+// к ведущему узлу успешен и узел применил все записи, иначе это время с момента,
+// когда узел в последний раз догнал ведущий
+func (f *follower) Status() *model.ReplicationStatus {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	status := &model.ReplicationStatus{
+		Role:          model.ReplicationFollower,
+		NodeID:        f.id,
+		LeaderURL:     f.leaderURL,
+		LeaderID:      f.leaderID,
+		Applied:       f.applied,
+		Head:          f.head,
+		LastContactAt: f.lastContactAt,
+	}
+
+	if !f.caughtUp {
+		status.Lag = time.Since(f.caughtUpAt)
+	}
+
+	if f.lastErr != nil {
+		status.LastError = f.lastErr.Error()
+	}
+
+	return status
+}
+
+// sync выполняет один запрос к ведущему узлу и применяет ответ
+func (f *follower) sync(ctx context.Context) error {
+	f.mu.Lock()
+	leaderID, applied := f.leaderID, f.applied
+	f.mu.Unlock()
+
+	if len(leaderID) == 0 {
+		return f.bootstrap(ctx)
+	}
+
+	batch, err := f.client.readLog(ctx, f.id, applied, f.wait)
+	if errors.Is(err, ErrLogTruncated) {
+		f.reset("replication log position is not available")
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if batch.LeaderID != leaderID {
+		f.reset("leader restarted")
+		return nil
+	}
+
+	for _, entry := range batch.Entries {
+		if entry.Seq != applied+1 {
+			return fmt.Errorf("unexpected log entry %d after %d", entry.Seq, applied)
+		}
+
+		if err := f.apply(ctx, entry); err != nil {
+			return fmt.Errorf("failed to apply log entry %d: %w", entry.Seq, err)
+		}
+
+		applied = entry.Seq
+
+		f.mu.Lock()
+		f.applied = applied
+		f.mu.Unlock()
+	}
+
+	f.contact(batch.Head)
+
+	return nil
+}
+
+// bootstrap заменяет все задачи снимком ведущего узла
+func (f *follower) bootstrap(ctx context.Context) error {
+	const fn = "follower.bootstrap"
+	log := f.log.With(logger.String("fn", fn))
+
+	snapshot, err := f.client.snapshot(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := f.tasks.ReplaceTasks(ctx, snapshot.Tasks, snapshot.NextID); err != nil {
+		return fmt.Errorf("failed to apply snapshot: %w", err)
+	}
+
+	f.mu.Lock()
+	f.leaderID = snapshot.LeaderID
+	f.applied = snapshot.Seq
+	f.mu.Unlock()
+
+	f.contact(snapshot.Seq)
+
+	log.Info("applied leader snapshot",
+		logger.String("leader id", snapshot.LeaderID),
+		logger.Int("seq", snapshot.Seq),
+		logger.Int("tasks count", len(snapshot.Tasks)),
+	)
+
+	return nil
+}
+
+// apply применяет запись журнала к хранилищам
+func (f *follower) apply(ctx context.Context, entry *model.LogEntry) error {
+	switch entry.Op {
+	case model.LogOpUpsert:
+		return f.tasks.ImportTasks(ctx, entry.Tasks, entry.NextID)
+	case model.LogOpReplace:
+		return f.tasks.ReplaceTasks(ctx, entry.Tasks, entry.NextID)
+	case model.LogOpPurge:
+		_, err := f.tasks.PurgeDeletedTasks(ctx, entry.DeletedBefore)
+		return err
+	case model.LogOpRevision:
+		if entry.Revision == nil {
+			return errors.New("revision entry without revision")
+		}
+
+		return f.revisions.AddRevision(ctx, entry.Revision)
+	default:
+		return fmt.Errorf("unknown log entry op: %s", entry.Op)
+	}
+}
+
+// contact запоминает успешный ответ ведущего узла с номером последней записи head
+func (f *follower) contact(head int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	now := time.Now()
+
+	f.head = head
+	f.lastContactAt = now.UTC()
+	f.lastErr = nil
+	f.caughtUp = f.applied >= head
+
+	if f.caughtUp {
+		f.caughtUpAt = now
+	}
+}
+
+func (f *follower) fail(err error) {
+	const fn = "follower.sync"
+	log := f.log.With(logger.String("fn", fn))
+
+	log.Error("failed to replicate from leader", logger.String("leader url", f.leaderURL), logger.Error(err))
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.caughtUp = false
+	f.lastErr = err
+}
+
+// reset сбрасывает позицию в журнале, следующий запрос загрузит снимок
+func (f *follower) reset(reason string) {
+	const fn = "follower.reset"
+	log := f.log.With(logger.String("fn", fn))
+
+	log.Warn("resynchronizing from leader snapshot", logger.String("reason", reason))
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.leaderID = ""
+	f.caughtUp = false
+}
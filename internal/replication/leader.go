@@ -0,0 +1,334 @@
+package replication
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/solumD/tasks-service/internal/model"
+	"github.com/solumD/tasks-service/internal/usecase"
+)
+
+const (
+	// maxBatch максимальное количество записей журнала в одном ответе
+	maxBatch = 1000
+	// maxWait максимальное время ожидания новых записей, меньше таймаута записи HTTP сервера
+	maxWait = 5 * time.Second
+	// followerTTL время, после которого молчащий ведомый узел пропадает из состояния
+	followerTTL = time.Minute
+)
+
+type leader struct {
+	tasks     LeaderStore
+	revisions usecase.RevisionRepo
+	id        string
+	entries   *entryLog
+	log       *slog.Logger
+
+	// mu упорядочивает изменения хранилища и записи журнала: записи об изменениях
+	// одной задачи попадают в журнал в том же порядке, в котором применены
+	mu *sync.Mutex
+
+	followers  map[string]*model.FollowerStatus
+	followerMu *sync.Mutex
+}
+
+// NewLeader возвращает ведущий узел. Он оборачивает хранилища задач и ревизий и
+// записывает каждое их изменение в журнал репликации из logSize последних записей
+func NewLeader(tasks LeaderStore, revisions usecase.RevisionRepo, logSize int, log *slog.Logger) (*leader, error) {
+	if logSize <= 0 {
+		return nil, fmt.Errorf("replication log size must be positive, got %d", logSize)
+	}
+
+	return &leader{
+		tasks:      tasks,
+		revisions:  revisions,
+		id:         newNodeID(),
+		entries:    newEntryLog(logSize),
+		log:        log,
+		mu:         &sync.Mutex{},
+		followers:  make(map[string]*model.FollowerStatus),
+		followerMu: &sync.Mutex{},
+	}, nil
+}
+
+// CreateTask создает задачу и записывает ее в журнал
+func (l *leader) CreateTask(ctx context.Context, task *model.Task) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	id, err := l.tasks.CreateTask(ctx, task)
+	if err != nil {
+		return 0, err
+	}
+
+	l.entries.append(&model.LogEntry{
+		Op:     model.LogOpUpsert,
+		Tasks:  []*model.Task{task.Clone()},
+		NextID: id + 1,
+	})
+
+	return id, nil
+}
+
+// GetAllTasks возвращает все задачи из хранилища
+func (l *leader) GetAllTasks(ctx context.Context) ([]*model.Task, error) {
+	return l.tasks.GetAllTasks(ctx)
+}
+
+// GetTaskByID возвращает задачу по ID из хранилища
+func (l *leader) GetTaskByID(ctx context.Context, id int) (*model.Task, error) {
+	return l.tasks.GetTaskByID(ctx, id)
+}
+
+// GetTasksByDue возвращает задачи из хранилища по сроку выполнения
+func (l *leader) GetTasksByDue(ctx context.Context, from, to time.Time) ([]*model.Task, error) {
+	return l.tasks.GetTasksByDue(ctx, from, to)
+}
+
+// GetTasksByTags возвращает задачи из хранилища по меткам
+func (l *leader) GetTasksByTags(ctx context.Context, tags []string, all bool) ([]*model.Task, error) {
+	return l.tasks.GetTasksByTags(ctx, tags, all)
+}
+
+// GetTasksByParent возвращает подзадачи из хранилища
+func (l *leader) GetTasksByParent(ctx context.Context, parentID int) ([]*model.Task, error) {
+	return l.tasks.GetTasksByParent(ctx, parentID)
+}
+
+// GetTagCounts возвращает метки задач из хранилища
+func (l *leader) GetTagCounts(ctx context.Context) ([]model.TagCount, error) {
+	return l.tasks.GetTagCounts(ctx)
+}
+
+// RenameTag переименовывает метку и записывает новые состояния задач в журнал одной записью
+func (l *leader) RenameTag(ctx context.Context, from, to string) ([]*model.Task, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	renamed, err := l.tasks.RenameTag(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(renamed) > 0 {
+		tasks := make([]*model.Task, 0, len(renamed))
+		for _, task := range renamed {
+			tasks = append(tasks, task.Clone())
+		}
+
+		l.entries.append(&model.LogEntry{
+			Op:    model.LogOpUpsert,
+			Tasks: tasks,
+		})
+	}
+
+	return renamed, nil
+}
+
+// UpdateTask обновляет задачу и записывает ее новое состояние в журнал
+func (l *leader) UpdateTask(ctx context.Context, task *model.Task) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err := l.tasks.UpdateTask(ctx, task); err != nil {
+		return err
+	}
+
+	l.entries.append(&model.LogEntry{
+		Op:    model.LogOpUpsert,
+		Tasks: []*model.Task{task.Clone()},
+	})
+
+	return nil
+}
+
+// DeleteTask перемещает задачу в корзину и записывает ее новое состояние в журнал
+func (l *leader) DeleteTask(ctx context.Context, id int, version int) (*model.Task, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	task, err := l.tasks.DeleteTask(ctx, id, version)
+	if err != nil {
+		return nil, err
+	}
+
+	l.entries.append(&model.LogEntry{
+		Op:    model.LogOpUpsert,
+		Tasks: []*model.Task{task.Clone()},
+	})
+
+	return task, nil
+}
+
+// GetDeletedTasks возвращает задачи из корзины
+func (l *leader) GetDeletedTasks(ctx context.Context) ([]*model.Task, error) {
+	return l.tasks.GetDeletedTasks(ctx)
+}
+
+// RestoreTask восстанавливает задачу из корзины и записывает ее новое состояние в журнал
+func (l *leader) RestoreTask(ctx context.Context, id int) (*model.Task, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	task, err := l.tasks.RestoreTask(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	l.entries.append(&model.LogEntry{
+		Op:    model.LogOpUpsert,
+		Tasks: []*model.Task{task.Clone()},
+	})
+
+	return task, nil
+}
+
+// PurgeDeletedTasks очищает корзину и записывает в журнал границу очистки
+func (l *leader) PurgeDeletedTasks(ctx context.Context, deletedBefore time.Time) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	purged, err := l.tasks.PurgeDeletedTasks(ctx, deletedBefore)
+	if err != nil {
+		return 0, err
+	}
+
+	if purged > 0 {
+		l.entries.append(&model.LogEntry{
+			Op:            model.LogOpPurge,
+			DeletedBefore: deletedBefore,
+		})
+	}
+
+	return purged, nil
+}
+
+// DumpTasks возвращает все задачи хранилища и ID следующей задачи
+func (l *leader) DumpTasks(ctx context.Context) ([]*model.Task, int, error) {
+	return l.tasks.DumpTasks(ctx)
+}
+
+// ReplaceTasks заменяет все задачи хранилища и записывает их в журнал
+func (l *leader) ReplaceTasks(ctx context.Context, tasks []*model.Task, nextID int) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err := l.tasks.ReplaceTasks(ctx, tasks, nextID); err != nil {
+		return err
+	}
+
+	replaced := make([]*model.Task, 0, len(tasks))
+	for _, task := range tasks {
+		replaced = append(replaced, task.Clone())
+	}
+
+	l.entries.append(&model.LogEntry{
+		Op:     model.LogOpReplace,
+		Tasks:  replaced,
+		NextID: nextID,
+	})
+
+	return nil
+}
+
+// AddRevision сохраняет ревизию и записывает ее в журнал
+func (l *leader) AddRevision(ctx context.Context, rev *model.Revision) error {
+	if err := l.revisions.AddRevision(ctx, rev); err != nil {
+		return err
+	}
+
+	l.entries.append(&model.LogEntry{
+		Op:       model.LogOpRevision,
+		Revision: rev.Clone(),
+	})
+
+	return nil
+}
+
+// GetRevisions возвращает ревизии задачи
+func (l *leader) GetRevisions(ctx context.Context, taskID int) ([]*model.Revision, error) {
+	return l.revisions.GetRevisions(ctx, taskID)
+}
+
+// GetRevision возвращает ревизию задачи
+func (l *leader) GetRevision(ctx context.Context, taskID int, rev int) (*model.Revision, error) {
+	return l.revisions.GetRevision(ctx, taskID, rev)
+}
+
+// ReadLog возвращает записи журнала после записи с номером from. Если новых записей нет,
+// ждет их не дольше wait. Ведомый узел followerID запоминается вместе с его позицией
+func (l *leader) ReadLog(ctx context.Context, followerID string, from int, wait time.Duration) (*model.LogBatch, error) {
+	if len(followerID) > 0 {
+		l.followerMu.Lock()
+		l.followers[followerID] = &model.FollowerStatus{
+			ID:         followerID,
+			Applied:    from,
+			LastSeenAt: time.Now().UTC(),
+		}
+		l.followerMu.Unlock()
+	}
+
+	entries, head, err := l.entries.read(ctx, from, maxBatch, min(wait, maxWait))
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.LogBatch{
+		LeaderID: l.id,
+		Head:     head,
+		Entries:  entries,
+	}, nil
+}
+
+// Snapshot возвращает все задачи хранилища и номер последней записи журнала, которая в них учтена
+func (l *leader) Snapshot(ctx context.Context) (*model.ReplicationSnapshot, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	tasks, nextID, err := l.tasks.DumpTasks(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.ReplicationSnapshot{
+		LeaderID: l.id,
+		Seq:      l.entries.lastSeq(),
+		NextID:   nextID,
+		Tasks:    tasks,
+	}, nil
+}
+
+// Status возвращает состояние ведущего узла и ведомых узлов, которые недавно читали журнал
+func (l *leader) Status() *model.ReplicationStatus {
+	head := l.entries.lastSeq()
+
+	l.followerMu.Lock()
+	followers := make([]*model.FollowerStatus, 0, len(l.followers))
+	for id, f := range l.followers {
+		if time.Since(f.LastSeenAt) > followerTTL {
+			delete(l.followers, id)
+			continue
+		}
+
+		clone := *f
+		followers = append(followers, &clone)
+	}
+	l.followerMu.Unlock()
+
+	sort.Slice(followers, func(i, j int) bool {
+		return followers[i].ID < followers[j].ID
+	})
+
+	return &model.ReplicationStatus{
+		Role:      model.ReplicationLeader,
+		NodeID:    l.id,
+		LeaderID:  l.id,
+		Applied:   head,
+		Head:      head,
+		Followers: followers,
+	}
+}
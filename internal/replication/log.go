@@ -0,0 +1,92 @@
+package replication
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/solumD/tasks-service/internal/model"
+)
+
+// entryLog журнал репликации в памяти, хранящий size последних записей
+type entryLog struct {
+	entries []*model.LogEntry
+	head    int
+	size    int
+	// appended закрывается и заменяется новым каналом при каждой записи
+	appended chan struct{}
+	mu       *sync.Mutex
+}
+
+func newEntryLog(size int) *entryLog {
+	return &entryLog{
+		size:     size,
+		appended: make(chan struct{}),
+		mu:       &sync.Mutex{},
+	}
+}
+
+// append присваивает записи следующий номер и дописывает ее в журнал.
+// Запись не должна меняться после добавления
+func (l *entryLog) append(entry *model.LogEntry) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.head++
+	entry.Seq = l.head
+	entry.CreatedAt = time.Now().UTC()
+
+	l.entries = append(l.entries, entry)
+	if len(l.entries) > l.size {
+		// копия, чтобы не удерживать память вытесненных записей
+		l.entries = append([]*model.LogEntry(nil), l.entries[len(l.entries)-l.size:]...)
+	}
+
+	close(l.appended)
+	l.appended = make(chan struct{})
+}
+
+// lastSeq возвращает номер последней записи
+func (l *entryLog) lastSeq() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.head
+}
+
+// read возвращает не больше limit записей после записи с номером from и номер последней
+// записи. Если новых записей нет, ждет их не дольше wait
+func (l *entryLog) read(ctx context.Context, from int, limit int, wait time.Duration) ([]*model.LogEntry, int, error) {
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	for {
+		l.mu.Lock()
+
+		first := l.head - len(l.entries) + 1
+		if from > l.head || from+1 < first {
+			l.mu.Unlock()
+			return nil, 0, ErrLogTruncated
+		}
+
+		if from < l.head {
+			end := min(l.head, from+limit)
+			entries := append([]*model.LogEntry(nil), l.entries[from+1-first:end+1-first]...)
+			head := l.head
+			l.mu.Unlock()
+
+			return entries, head, nil
+		}
+
+		appended, head := l.appended, l.head
+		l.mu.Unlock()
+
+		select {
+		case <-appended:
+		case <-timer.C:
+			return nil, head, nil
+		case <-ctx.Done():
+			return nil, 0, ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,410 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/solumD/tasks-service/internal/backup"
+	hnd "github.com/solumD/tasks-service/internal/handler"
+	v1 "github.com/solumD/tasks-service/internal/handler/v1"
+	"github.com/solumD/tasks-service/internal/handler/v1/dto"
+	"github.com/solumD/tasks-service/internal/handler/v1/mock"
+	"github.com/solumD/tasks-service/internal/model"
+	"github.com/solumD/tasks-service/internal/replication"
+	"github.com/solumD/tasks-service/internal/repository/disabled"
+	inmemory "github.com/solumD/tasks-service/internal/repository/in_memory"
+	"github.com/solumD/tasks-service/internal/usecase"
+	"github.com/solumD/tasks-service/pkg/logger"
+	"github.com/solumD/tasks-service/pkg/middleware"
+)
+
+const (
+	pollWait = 50 * time.Millisecond
+	// adminToken токен административных эндпоинтов узлов
+	adminToken = "secret"
+)
+
+// node экземпляр сервиса с хранилищами в памяти за тестовым HTTP сервером
+type node struct {
+	server  *httptest.Server
+	usecase interface {
+		PurgeDeletedTasks(ctx context.Context, retention time.Duration) (int, error)
+	}
+	backups interface {
+		Backup(ctx context.Context) (*model.BackupInfo, error)
+	}
+	// router подменяется при перезапуске узла по тому же адресу
+	router *atomic.Pointer[http.ServeMux]
+}
+
+// newLeader запускает ведущий узел с журналом из logSize записей. Если restarted не nil,
+// новый узел заменяет его по тому же адресу
+func newLeader(t *testing.T, logSize int, restarted *node) *node {
+	t.Helper()
+
+	ctx := context.Background()
+	log := logger.NewMockLogger()
+
+	taskRepo := inmemory.NewTaskRepo()
+
+	leader, err := replication.NewLeader(taskRepo, inmemory.NewRevisionRepo(), logSize, log)
+	if err != nil {
+		t.Fatalf("failed to init leader: %v", err)
+	}
+
+	backups, err := backup.NewManager(leader, disabled.NewProjectRepo(), t.TempDir(), backup.Retention{KeepLast: 10}, log)
+	if err != nil {
+		t.Fatalf("failed to init backups: %v", err)
+	}
+
+	taskUsecase := usecase.NewTaskUsecase(leader, leader, inmemory.NewProjectRepo(), inmemory.NewCommentRepo(), usecase.Rules{}, log)
+	router := hnd.NewRouter(ctx, log, v1.NewHandler(taskUsecase, &mock.MockProjectUsecase{}, &mock.MockCommentUsecase{}, backups, leader, &mock.MockChangeUsecase{}, log), adminToken)
+
+	if restarted != nil {
+		restarted.router.Store(router)
+
+		return &node{server: restarted.server, usecase: taskUsecase, backups: backups, router: restarted.router}
+	}
+
+	n := &node{usecase: taskUsecase, backups: backups, router: &atomic.Pointer[http.ServeMux]{}}
+	n.router.Store(router)
+
+	n.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n.router.Load().ServeHTTP(w, r)
+	}))
+	t.Cleanup(n.server.Close)
+
+	return n
+}
+
+// newFollower запускает ведомый узел ведущего узла leaderURL
+func newFollower(t *testing.T, leaderURL string) *node {
+	t.Helper()
+
+	ctx := context.Background()
+	log := logger.NewMockLogger()
+
+	taskRepo := inmemory.NewTaskRepo()
+	revisionRepo := inmemory.NewRevisionRepo()
+
+	follower := replication.NewFollower(taskRepo, revisionRepo, leaderURL, pollWait, log)
+	follower.Run(ctx)
+	t.Cleanup(follower.Stop)
+
+	backups, err := backup.NewManager(taskRepo, disabled.NewProjectRepo(), t.TempDir(), backup.Retention{KeepLast: 10}, log)
+	if err != nil {
+		t.Fatalf("failed to init backups: %v", err)
+	}
+
+	taskUsecase := usecase.NewTaskUsecase(taskRepo, revisionRepo, inmemory.NewProjectRepo(), inmemory.NewCommentRepo(), usecase.Rules{}, log)
+	router := hnd.NewRouter(ctx, log, v1.NewHandler(taskUsecase, &mock.MockProjectUsecase{}, &mock.MockCommentUsecase{}, backups, follower, &mock.MockChangeUsecase{}, log), adminToken)
+
+	server := httptest.NewServer(middleware.NewMWReadOnly(leaderURL, log)(router))
+	t.Cleanup(server.Close)
+
+	return &node{server: server, usecase: taskUsecase}
+}
+
+func do(t *testing.T, method, url string, body any) *http.Response {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if body != nil {
+		json.NewEncoder(&buf).Encode(body)
+	}
+
+	req, err := http.NewRequest(method, url, &buf)
+	if err != nil {
+		t.Fatalf("failed to build request: %v", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+adminToken)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("failed to send request: %v", err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+
+	return resp
+}
+
+func get[T any](t *testing.T, url string) T {
+	t.Helper()
+
+	resp := do(t, http.MethodGet, url, nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("GET %s: unexpected status %d", url, resp.StatusCode)
+	}
+
+	var v T
+	if err := json.NewDecoder(resp.Body).Decode(&v); err != nil {
+		t.Fatalf("GET %s: failed to decode response: %v", url, err)
+	}
+
+	return v
+}
+
+func createTask(t *testing.T, url string, title string) int {
+	t.Helper()
+
+	resp := do(t, http.MethodPost, url+"/todos", dto.CreateTaskReq{Title: title})
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+		t.Fatalf("failed to create task: status %d", resp.StatusCode)
+	}
+
+	var created dto.CreateTaskResp
+	json.NewDecoder(resp.Body).Decode(&created)
+
+	return created.ID
+}
+
+// state все задачи узла, включая корзину, в виде JSON для сравнения
+func state(t *testing.T, url string) string {
+	t.Helper()
+
+	todos := get[dto.GetAllTasksResp](t, url+"/todos")
+	trash := get[dto.GetAllTasksResp](t, url+"/todos/trash")
+
+	b, _ := json.Marshal([]any{todos, trash})
+
+	return string(b)
+}
+
+// eventually ждет, пока состояние ведомых узлов не совпадет с ведущим
+func eventually(t *testing.T, leader *node, followers ...*node) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		want := state(t, leader.server.URL)
+
+		converged := true
+		for _, f := range followers {
+			if state(t, f.server.URL) != want {
+				converged = false
+				break
+			}
+		}
+
+		if converged {
+			return
+		}
+
+		if time.Now().After(deadline) {
+			for _, f := range followers {
+				t.Logf("follower %s: %s", f.server.URL, state(t, f.server.URL))
+			}
+			t.Fatalf("followers did not converge with leader: %s", want)
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestReplication(t *testing.T) {
+	leader := newLeader(t, 1000, nil)
+	followers := []*node{
+		newFollower(t, leader.server.URL),
+		newFollower(t, leader.server.URL),
+	}
+
+	id1 := createTask(t, leader.server.URL, "Task1")
+	id2 := createTask(t, leader.server.URL, "Task2")
+	createTask(t, leader.server.URL, "Task3")
+
+	do(t, http.MethodPut, leader.server.URL+"/todos/1", dto.UpdateTaskReq{Title: "Task1 updated", Done: true})
+	do(t, http.MethodDelete, leader.server.URL+"/todos/2", nil)
+	do(t, http.MethodDelete, leader.server.URL+"/todos/3", nil)
+	do(t, http.MethodPost, leader.server.URL+"/todos/3/restore", nil)
+
+	eventually(t, leader, followers...)
+
+	for _, f := range followers {
+		task := get[dto.GetTaskByIDResp](t, f.server.URL+"/todos/1")
+		if task.ID != id1 || task.Title != "Task1 updated" || task.Version != 2 {
+			t.Fatalf("unexpected replicated task: %+v", task)
+		}
+
+		history := get[dto.GetTaskHistoryResp](t, f.server.URL+"/todos/3/history")
+		if len(history.Revisions) != 3 {
+			t.Fatalf("expected 3 replicated revisions, got %d", len(history.Revisions))
+		}
+	}
+
+	// очистка корзины на ведущем узле повторяется на ведомых
+	if purged, err := leader.usecase.PurgeDeletedTasks(context.Background(), 0); err != nil || purged != 1 {
+		t.Fatalf("expected to purge task %d, got %d, %v", id2, purged, err)
+	}
+
+	eventually(t, leader, followers...)
+
+	// восстановление из копии заменяет задачи и на ведомых узлах
+	info, err := leader.backups.Backup(context.Background())
+	if err != nil {
+		t.Fatalf("failed to back up: %v", err)
+	}
+
+	createTask(t, leader.server.URL, "Task4")
+	eventually(t, leader, followers...)
+
+	resp := do(t, http.MethodPost, leader.server.URL+"/admin/restore", dto.RestoreBackupReq{Backup: info.Name})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("failed to restore backup: status %d", resp.StatusCode)
+	}
+
+	eventually(t, leader, followers...)
+
+	// счетчик id ведомого узла сдвигается вместе с ведущим
+	if id := createTask(t, leader.server.URL, "Task5"); id != 5 {
+		t.Fatalf("expected id 5, got %d", id)
+	}
+
+	eventually(t, leader, followers...)
+}
+
+func TestFollowerRedirectsWrites(t *testing.T) {
+	leader := newLeader(t, 1000, nil)
+	follower := newFollower(t, leader.server.URL)
+
+	noRedirect := &http.Client{
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+	}{
+		{name: "create", method: http.MethodPost, path: "/todos"},
+		{name: "update", method: http.MethodPut, path: "/todos/1"},
+		{name: "delete with query", method: http.MethodDelete, path: "/todos/1?x=1"},
+		{name: "restore backup", method: http.MethodPost, path: "/admin/restore"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(tt.method, follower.server.URL+tt.path, bytes.NewBufferString(`{"title":"x"}`))
+
+			resp, err := noRedirect.Do(req)
+			if err != nil {
+				t.Fatalf("failed to send request: %v", err)
+			}
+			resp.Body.Close()
+
+			if resp.StatusCode != http.StatusTemporaryRedirect {
+				t.Fatalf("expected status %d, got %d", http.StatusTemporaryRedirect, resp.StatusCode)
+			}
+
+			if location := resp.Header.Get("Location"); location != leader.server.URL+tt.path {
+				t.Fatalf("expected redirect to %s, got %s", leader.server.URL+tt.path, location)
+			}
+		})
+	}
+
+	// клиент, который следует перенаправлению, создает задачу на ведущем узле
+	id := createTask(t, follower.server.URL, "Task1")
+	if id != 1 {
+		t.Fatalf("expected task to be created on leader with id 1, got %d", id)
+	}
+
+	eventually(t, leader, follower)
+
+	if resp := do(t, http.MethodGet, follower.server.URL+"/replication/log?from=0", nil); resp.StatusCode != http.StatusConflict {
+		t.Fatalf("expected follower to refuse serving log, got status %d", resp.StatusCode)
+	}
+}
+
+func TestFollowerBootstrapsFromSnapshot(t *testing.T) {
+	// журнал из 2 записей не хранит начало истории
+	leader := newLeader(t, 2, nil)
+
+	for _, title := range []string{"Task1", "Task2", "Task3", "Task4"} {
+		createTask(t, leader.server.URL, title)
+	}
+	do(t, http.MethodDelete, leader.server.URL+"/todos/4", nil)
+
+	if resp := do(t, http.MethodGet, leader.server.URL+"/replication/log?from=0", nil); resp.StatusCode != http.StatusGone {
+		t.Fatalf("expected truncated log position, got status %d", resp.StatusCode)
+	}
+
+	follower := newFollower(t, leader.server.URL)
+	eventually(t, leader, follower)
+
+	// ведомый узел, отставший больше чем на длину журнала, снова загружает снимок
+	for i := 0; i < 10; i++ {
+		createTask(t, leader.server.URL, "Task")
+	}
+
+	eventually(t, leader, follower)
+}
+
+func TestFollowerResyncsAfterLeaderRestart(t *testing.T) {
+	leader := newLeader(t, 1000, nil)
+
+	createTask(t, leader.server.URL, "Task1")
+	createTask(t, leader.server.URL, "Task2")
+
+	follower := newFollower(t, leader.server.URL)
+	eventually(t, leader, follower)
+
+	// новый ведущий узел по тому же адресу с другими данными
+	restarted := newLeader(t, 1000, leader)
+
+	createTask(t, leader.server.URL, "Other")
+
+	eventually(t, restarted, follower)
+
+	tasks := get[dto.GetAllTasksResp](t, follower.server.URL+"/todos")
+	if len(tasks.Tasks) != 1 || tasks.Tasks[0].Title != "Other" {
+		t.Fatalf("expected follower to replace its tasks after leader restart, got %+v", tasks.Tasks)
+	}
+}
+
+func TestReplicationStatus(t *testing.T) {
+	leader := newLeader(t, 1000, nil)
+
+	standby := get[dto.ReplicationStatusResp](t, leader.server.URL+"/replication/status")
+	if standby.Role != "leader" || standby.Head != 0 {
+		t.Fatalf("unexpected leader status: %+v", standby)
+	}
+
+	follower := newFollower(t, leader.server.URL)
+
+	createTask(t, leader.server.URL, "Task1")
+	createTask(t, leader.server.URL, "Task2")
+	eventually(t, leader, follower)
+
+	// позиция ведомого узла обновляется следующим запросом журнала
+	time.Sleep(2 * pollWait)
+
+	status := get[dto.ReplicationStatusResp](t, follower.server.URL+"/replication/status")
+	if status.Role != "follower" || status.Applied != 4 || status.LagEntries != 0 || status.LagSeconds != 0 || status.LastContactAt == nil {
+		t.Fatalf("unexpected follower status: %+v", status)
+	}
+
+	leaderStatus := get[dto.ReplicationStatusResp](t, leader.server.URL+"/replication/status")
+	if leaderStatus.Head != 4 || len(leaderStatus.Followers) != 1 || leaderStatus.Followers[0].ID != status.NodeID || leaderStatus.Followers[0].LagEntries != 0 {
+		t.Fatalf("unexpected leader status: %+v", leaderStatus)
+	}
+
+	// ведущий узел недоступен: ведомый продолжает отвечать на чтение и сообщает об отставании
+	leader.server.Close()
+	time.Sleep(3 * pollWait)
+
+	status = get[dto.ReplicationStatusResp](t, follower.server.URL+"/replication/status")
+	if status.Error == "" || status.LagSeconds == 0 {
+		t.Fatalf("expected follower to report lag while leader is down, got %+v", status)
+	}
+
+	if tasks := get[dto.GetAllTasksResp](t, follower.server.URL+"/todos"); len(tasks.Tasks) != 2 {
+		t.Fatalf("expected follower to serve reads while leader is down, got %d tasks", len(tasks.Tasks))
+	}
+}
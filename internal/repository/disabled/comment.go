@@ -0,0 +1,59 @@
+package disabled
+
+import (
+	"context"
+
+	"github.com/solumD/tasks-service/internal/model"
+	"github.com/solumD/tasks-service/internal/usecase"
+)
+
+// commentRepo хранилище комментариев для режимов, в которых комментарии не поддерживаются.
+// Комментарии не создаются, а чтение ведет себя как у пустого хранилища
+type commentRepo struct{}
+
+func NewCommentRepo() *commentRepo {
+	return &commentRepo{}
+}
+
+// CreateComment всегда возвращает usecase.ErrCommentsDisabled
+func (r *commentRepo) CreateComment(ctx context.Context, comment *model.Comment) (int, error) {
+	return 0, usecase.ErrCommentsDisabled
+}
+
+// GetComments возвращает пустой список комментариев
+func (r *commentRepo) GetComments(ctx context.Context, taskID int, after int, limit int) ([]*model.Comment, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return []*model.Comment{}, nil
+}
+
+// GetReplies возвращает пустой список ответов
+func (r *commentRepo) GetReplies(ctx context.Context, parentIDs []int) ([]*model.Comment, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return []*model.Comment{}, nil
+}
+
+// GetCommentByID всегда возвращает ошибку отсутствия комментария
+func (r *commentRepo) GetCommentByID(ctx context.Context, id int) (*model.Comment, error) {
+	return nil, usecase.NewCommentNotFoundError(id)
+}
+
+// UpdateComment всегда возвращает ошибку отсутствия комментария
+func (r *commentRepo) UpdateComment(ctx context.Context, comment *model.Comment) error {
+	return usecase.NewCommentNotFoundError(comment.ID)
+}
+
+// DeleteComment всегда возвращает ошибку отсутствия комментария
+func (r *commentRepo) DeleteComment(ctx context.Context, id int) (int, error) {
+	return 0, usecase.NewCommentNotFoundError(id)
+}
+
+// DeleteTaskComments ничего не удаляет
+func (r *commentRepo) DeleteTaskComments(ctx context.Context, taskIDs []int) (int, error) {
+	return 0, ctx.Err()
+}
@@ -0,0 +1,65 @@
+package disabled
+
+import (
+	"context"
+
+	"github.com/solumD/tasks-service/internal/model"
+	"github.com/solumD/tasks-service/internal/usecase"
+)
+
+// projectRepo хранилище проектов для режимов, в которых проекты не поддерживаются.
+// Проекты не создаются, а чтение ведет себя как у пустого хранилища, поэтому
+// задачи нельзя привязать к проекту
+type projectRepo struct{}
+
+func NewProjectRepo() *projectRepo {
+	return &projectRepo{}
+}
+
+// CreateProject всегда возвращает usecase.ErrProjectsDisabled
+func (r *projectRepo) CreateProject(ctx context.Context, project *model.Project) (int, error) {
+	return 0, usecase.ErrProjectsDisabled
+}
+
+// GetProjects возвращает пустой список проектов
+func (r *projectRepo) GetProjects(ctx context.Context) ([]*model.Project, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return []*model.Project{}, nil
+}
+
+// GetProjectByID всегда возвращает ошибку отсутствия проекта
+func (r *projectRepo) GetProjectByID(ctx context.Context, id int) (*model.Project, error) {
+	return nil, usecase.NewProjectNotFoundError(id)
+}
+
+// UpdateProject всегда возвращает ошибку отсутствия проекта
+func (r *projectRepo) UpdateProject(ctx context.Context, project *model.Project) error {
+	return usecase.NewProjectNotFoundError(project.ID)
+}
+
+// DeleteProject всегда возвращает ошибку отсутствия проекта
+func (r *projectRepo) DeleteProject(ctx context.Context, id int, version int) error {
+	return usecase.NewProjectNotFoundError(id)
+}
+
+// DumpProjects возвращает пустой список проектов
+func (r *projectRepo) DumpProjects(ctx context.Context) ([]*model.Project, int, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return []*model.Project{}, 1, nil
+}
+
+// ReplaceProjects принимает только пустой список проектов, иначе возвращает
+// usecase.ErrProjectsDisabled
+func (r *projectRepo) ReplaceProjects(ctx context.Context, projects []*model.Project, nextID int) error {
+	if len(projects) > 0 {
+		return usecase.ErrProjectsDisabled
+	}
+
+	return ctx.Err()
+}
@@ -0,0 +1,55 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/solumD/tasks-service/internal/model"
+	"github.com/solumD/tasks-service/internal/repository/disabled"
+	"github.com/solumD/tasks-service/internal/usecase"
+)
+
+func TestCommentRepo(t *testing.T) {
+	ctx := context.Background()
+	repo := disabled.NewCommentRepo()
+
+	if _, err := repo.CreateComment(ctx, &model.Comment{TaskID: 1, Author: "ann", Text: "hi"}); !errors.Is(err, usecase.ErrCommentsDisabled) {
+		t.Fatalf("expected ErrCommentsDisabled, got %v", err)
+	}
+
+	comments, err := repo.GetComments(ctx, 1, 0, 10)
+	if err != nil {
+		t.Fatalf("failed to get comments: %v", err)
+	}
+
+	if len(comments) != 0 {
+		t.Fatalf("expected no comments, got %d", len(comments))
+	}
+
+	replies, err := repo.GetReplies(ctx, []int{1})
+	if err != nil {
+		t.Fatalf("failed to get replies: %v", err)
+	}
+
+	if len(replies) != 0 {
+		t.Fatalf("expected no replies, got %d", len(replies))
+	}
+
+	if _, err := repo.GetCommentByID(ctx, 1); !errors.Is(err, usecase.ErrCommentNotFound) {
+		t.Fatalf("expected ErrCommentNotFound on get, got %v", err)
+	}
+
+	if err := repo.UpdateComment(ctx, &model.Comment{ID: 1, Text: "edited"}); !errors.Is(err, usecase.ErrCommentNotFound) {
+		t.Fatalf("expected ErrCommentNotFound on update, got %v", err)
+	}
+
+	if _, err := repo.DeleteComment(ctx, 1); !errors.Is(err, usecase.ErrCommentNotFound) {
+		t.Fatalf("expected ErrCommentNotFound on delete, got %v", err)
+	}
+
+	deleted, err := repo.DeleteTaskComments(ctx, []int{1})
+	if err != nil || deleted != 0 {
+		t.Fatalf("expected nothing deleted, got %d, %v", deleted, err)
+	}
+}
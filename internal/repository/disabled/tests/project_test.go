@@ -0,0 +1,54 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/solumD/tasks-service/internal/model"
+	"github.com/solumD/tasks-service/internal/repository/disabled"
+	"github.com/solumD/tasks-service/internal/usecase"
+)
+
+func TestProjectRepo(t *testing.T) {
+	ctx := context.Background()
+	repo := disabled.NewProjectRepo()
+
+	if _, err := repo.CreateProject(ctx, &model.Project{Name: "Home"}); !errors.Is(err, usecase.ErrProjectsDisabled) {
+		t.Fatalf("expected ErrProjectsDisabled, got %v", err)
+	}
+
+	projects, err := repo.GetProjects(ctx)
+	if err != nil {
+		t.Fatalf("failed to get projects: %v", err)
+	}
+
+	if len(projects) != 0 {
+		t.Fatalf("expected no projects, got %d", len(projects))
+	}
+
+	if _, err := repo.GetProjectByID(ctx, 1); !errors.Is(err, usecase.ErrProjectNotFound) {
+		t.Fatalf("expected ErrProjectNotFound on get, got %v", err)
+	}
+
+	if err := repo.UpdateProject(ctx, &model.Project{ID: 1, Name: "House"}); !errors.Is(err, usecase.ErrProjectNotFound) {
+		t.Fatalf("expected ErrProjectNotFound on update, got %v", err)
+	}
+
+	if err := repo.DeleteProject(ctx, 1, 0); !errors.Is(err, usecase.ErrProjectNotFound) {
+		t.Fatalf("expected ErrProjectNotFound on delete, got %v", err)
+	}
+
+	projects, next, err := repo.DumpProjects(ctx)
+	if err != nil || len(projects) != 0 || next != 1 {
+		t.Fatalf("expected empty dump, got %d projects with next id %d (err %v)", len(projects), next, err)
+	}
+
+	if err := repo.ReplaceProjects(ctx, nil, 5); err != nil {
+		t.Fatalf("expected empty replace to succeed, got %v", err)
+	}
+
+	if err := repo.ReplaceProjects(ctx, []*model.Project{{ID: 1, Name: "Home"}}, 2); !errors.Is(err, usecase.ErrProjectsDisabled) {
+		t.Fatalf("expected ErrProjectsDisabled on replace, got %v", err)
+	}
+}
@@ -0,0 +1,54 @@
+package eventsourced
+
+import (
+	"context"
+
+	"github.com/solumD/tasks-service/internal/model"
+)
+
+// DumpTasks возвращает копии всех задач, включая корзину, и ID следующей задачи
+// на один момент времени
+func (r *taskRepo) DumpTasks(ctx context.Context) ([]*model.Task, int, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tasks := make([]*model.Task, 0, len(r.state.tasks)+len(r.state.trash))
+
+	for _, task := range r.state.tasks {
+		tasks = append(tasks, task.Clone())
+	}
+
+	for _, task := range r.state.trash {
+		tasks = append(tasks, task.Clone())
+	}
+
+	return tasks, r.state.idCounter + 1, nil
+}
+
+// ReplaceTasks записывает одно событие TasksReplaced, которое заменяет все задачи,
+// включая корзину, на tasks. Счетчик ID не уменьшается, а следующая задача
+// получит ID не меньше nextID
+func (r *taskRepo) ReplaceTasks(ctx context.Context, tasks []*model.Task, nextID int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	data := TasksReplacedData{Tasks: make([]*model.Task, 0, len(tasks))}
+	for _, task := range tasks {
+		data.Tasks = append(data.Tasks, task.Clone())
+	}
+
+	event, err := newEvent(TasksReplaced, nextID-1, data)
+	if err != nil {
+		return err
+	}
+
+	return r.commit(ctx, []Event{event})
+}
@@ -0,0 +1,173 @@
+package eventsourced
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/solumD/tasks-service/internal/model"
+)
+
+// EventType тип события задачи
+type EventType string
+
+const (
+	TaskCreated   EventType = "TaskCreated"
+	TaskRenamed   EventType = "TaskRenamed"
+	TaskCompleted EventType = "TaskCompleted"
+	TaskReopened  EventType = "TaskReopened"
+	// TaskStatusChanged у задачи изменен статус. Выполнение и повторное открытие
+	// задачи дополнительно записываются событиями TaskCompleted и TaskReopened
+	TaskStatusChanged EventType = "TaskStatusChanged"
+	// TaskRescheduled у задачи изменен или снят срок выполнения
+	TaskRescheduled EventType = "TaskRescheduled"
+	// TaskReprioritized у задачи изменен приоритет
+	TaskReprioritized EventType = "TaskReprioritized"
+	// TaskRetagged у задачи изменены метки
+	TaskRetagged EventType = "TaskRetagged"
+	// TaskMoved у задачи изменена родительская задача
+	TaskMoved EventType = "TaskMoved"
+	// TaskProjectChanged задача перенесена в другой проект или из проекта
+	TaskProjectChanged EventType = "TaskProjectChanged"
+	// TaskDependenciesChanged у задачи изменены зависимости
+	TaskDependenciesChanged EventType = "TaskDependenciesChanged"
+	// TaskRecurrenceChanged у задачи изменено или снято правило повторения
+	TaskRecurrenceChanged EventType = "TaskRecurrenceChanged"
+	// TaskDeleted задача перемещена в корзину
+	TaskDeleted  EventType = "TaskDeleted"
+	TaskRestored EventType = "TaskRestored"
+	// TaskPurged задача окончательно удалена из корзины
+	TaskPurged EventType = "TaskPurged"
+	// TaskImported задача перенесена из другого хранилища вместе с версией и временем удаления
+	TaskImported EventType = "TaskImported"
+	// TaskIDsReserved ID до TaskID включительно больше не выдаются новым задачам
+	TaskIDsReserved EventType = "TaskIDsReserved"
+	// TasksReplaced все задачи заменены, например при восстановлении из резервной копии.
+	// ID до TaskID включительно больше не выдаются новым задачам
+	TasksReplaced EventType = "TasksReplaced"
+)
+
+// Event событие изменения задачи. Seq строго возрастает в пределах журнала
+type Event struct {
+	Seq        int64           `json:"seq"`
+	Type       EventType       `json:"type"`
+	TaskID     int             `json:"task_id"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	Data       json.RawMessage `json:"data,omitempty"`
+}
+
+// TaskCreatedData данные события TaskCreated. Без CreatedAt, в событиях до появления
+// времени создания, задача создана в момент события
+type TaskCreatedData struct {
+	Title       string         `json:"title"`
+	Description string         `json:"description"`
+	Done        bool           `json:"done"`
+	Status      model.Status   `json:"status,omitempty"`
+	DueAt       *time.Time     `json:"due_at,omitempty"`
+	Priority    model.Priority `json:"priority,omitempty"`
+	Tags        []string       `json:"tags,omitempty"`
+	ParentID    int            `json:"parent_id,omitempty"`
+	ProjectID   int            `json:"project_id,omitempty"`
+	DependsOn   []int          `json:"depends_on,omitempty"`
+	Recurrence  string         `json:"recurrence,omitempty"`
+	CreatedAt   time.Time      `json:"created_at,omitzero"`
+}
+
+// TaskRenamedData данные события TaskRenamed
+type TaskRenamedData struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+}
+
+// TaskStatusChangedData данные события TaskStatusChanged
+type TaskStatusChangedData struct {
+	Status model.Status `json:"status"`
+}
+
+// TaskRescheduledData данные события TaskRescheduled, DueAt равный nil снимает срок
+type TaskRescheduledData struct {
+	DueAt *time.Time `json:"due_at,omitempty"`
+}
+
+// TaskReprioritizedData данные события TaskReprioritized
+type TaskReprioritizedData struct {
+	Priority model.Priority `json:"priority"`
+}
+
+// TaskRetaggedData данные события TaskRetagged, Tags - все метки задачи после изменения
+type TaskRetaggedData struct {
+	Tags []string `json:"tags,omitempty"`
+}
+
+// TaskMovedData данные события TaskMoved, ParentID равный 0 делает задачу задачей верхнего уровня
+type TaskMovedData struct {
+	ParentID int `json:"parent_id,omitempty"`
+}
+
+// TaskProjectChangedData данные события TaskProjectChanged, ProjectID равный 0 выводит задачу из проекта
+type TaskProjectChangedData struct {
+	ProjectID int `json:"project_id,omitempty"`
+}
+
+// TaskDependenciesChangedData данные события TaskDependenciesChanged, DependsOn - все
+// зависимости задачи после изменения
+type TaskDependenciesChangedData struct {
+	DependsOn []int `json:"depends_on,omitempty"`
+}
+
+// TaskRecurrenceChangedData данные события TaskRecurrenceChanged, пустое Recurrence
+// снимает правило
+type TaskRecurrenceChangedData struct {
+	Recurrence string `json:"recurrence,omitempty"`
+}
+
+// TaskImportedData данные события TaskImported
+type TaskImportedData struct {
+	Title       string         `json:"title"`
+	Description string         `json:"description"`
+	Done        bool           `json:"done"`
+	Status      model.Status   `json:"status,omitempty"`
+	DueAt       *time.Time     `json:"due_at,omitempty"`
+	Priority    model.Priority `json:"priority,omitempty"`
+	Tags        []string       `json:"tags,omitempty"`
+	ParentID    int            `json:"parent_id,omitempty"`
+	ProjectID   int            `json:"project_id,omitempty"`
+	DependsOn   []int          `json:"depends_on,omitempty"`
+	Recurrence  string         `json:"recurrence,omitempty"`
+	CreatedAt   time.Time      `json:"created_at,omitzero"`
+	Version     int            `json:"version"`
+	DeletedAt   *time.Time     `json:"deleted_at,omitempty"`
+}
+
+// TasksReplacedData данные события TasksReplaced
+type TasksReplacedData struct {
+	Tasks []*model.Task `json:"tasks"`
+}
+
+func newEvent(eventType EventType, taskID int, data any) (Event, error) {
+	event := Event{
+		Type:       eventType,
+		TaskID:     taskID,
+		OccurredAt: time.Now().UTC(),
+	}
+
+	if data != nil {
+		raw, err := json.Marshal(data)
+		if err != nil {
+			return Event{}, fmt.Errorf("failed to marshal %s data: %w", eventType, err)
+		}
+
+		event.Data = raw
+	}
+
+	return event, nil
+}
+
+// DecodeData разбирает данные события в dst
+func (e Event) DecodeData(dst any) error {
+	if err := json.Unmarshal(e.Data, dst); err != nil {
+		return fmt.Errorf("failed to unmarshal %s data: %w", e.Type, err)
+	}
+
+	return nil
+}
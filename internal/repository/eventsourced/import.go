@@ -0,0 +1,73 @@
+package eventsourced
+
+import (
+	"context"
+
+	"github.com/solumD/tasks-service/internal/model"
+)
+
+// NextID возвращает ID, который получит следующая созданная задача
+func (r *taskRepo) NextID(ctx context.Context) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.state.idCounter + 1, nil
+}
+
+// ImportTasks записывает событие TaskImported для каждой задачи, заменяя задачи с теми же ID,
+// и событие TaskIDsReserved, если следующая задача иначе получила бы ID меньше nextID
+func (r *taskRepo) ImportTasks(ctx context.Context, tasks []*model.Task, nextID int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	events := make([]Event, 0, len(tasks)+1)
+	maxID := r.state.idCounter
+
+	for _, task := range tasks {
+		event, err := newEvent(TaskImported, task.ID, TaskImportedData{
+			Title:       task.Title,
+			Description: task.Description,
+			Done:        task.Done,
+			Status:      task.Status,
+			DueAt:       task.DueAt,
+			Priority:    task.Priority,
+			Tags:        task.Tags,
+			ParentID:    task.ParentID,
+			ProjectID:   task.ProjectID,
+			DependsOn:   task.DependsOn,
+			Recurrence:  task.Recurrence,
+			CreatedAt:   task.CreatedAt,
+			Version:     task.Version,
+			DeletedAt:   task.DeletedAt,
+		})
+		if err != nil {
+			return err
+		}
+
+		events = append(events, event)
+		maxID = max(maxID, task.ID)
+	}
+
+	if nextID-1 > maxID {
+		event, err := newEvent(TaskIDsReserved, nextID-1, nil)
+		if err != nil {
+			return err
+		}
+
+		events = append(events, event)
+	}
+
+	if len(events) == 0 {
+		return nil
+	}
+
+	return r.commit(ctx, events)
+}
@@ -0,0 +1,273 @@
+package eventsourced
+
+import (
+	"fmt"
+
+	"github.com/solumD/tasks-service/internal/model"
+	"github.com/solumD/tasks-service/internal/repository/index"
+)
+
+// Projection модель для чтения, которая строится из событий журнала.
+// Apply вызывается для каждого события по порядку: сначала при восстановлении
+// из журнала, затем для каждого нового события
+type Projection interface {
+	Apply(event Event) error
+}
+
+// taskProjection текущее состояние задач и корзины
+type taskProjection struct {
+	tasks map[int]*model.Task
+	trash map[int]*model.Task
+	// due, tags и children индексы сроков, меток и подзадач задач не из корзины
+	due       *index.Due
+	tags      *index.Tags
+	children  *index.Children
+	idCounter int
+}
+
+func newTaskProjection() *taskProjection {
+	return &taskProjection{
+		tasks:    make(map[int]*model.Task),
+		trash:    make(map[int]*model.Task),
+		due:      index.NewDue(),
+		tags:     index.NewTags(),
+		children: index.NewChildren(),
+	}
+}
+
+// Apply применяет событие к состоянию задач
+func (p *taskProjection) Apply(event Event) error {
+	switch event.Type {
+	case TaskCreated:
+		var data TaskCreatedData
+		if err := event.DecodeData(&data); err != nil {
+			return err
+		}
+
+		createdAt := data.CreatedAt
+		if createdAt.IsZero() {
+			createdAt = event.OccurredAt
+		}
+
+		p.tasks[event.TaskID] = &model.Task{
+			ID:          event.TaskID,
+			Title:       data.Title,
+			Description: data.Description,
+			Done:        data.Done,
+			Status:      data.Status,
+			DueAt:       data.DueAt,
+			Priority:    data.Priority,
+			Tags:        data.Tags,
+			ParentID:    data.ParentID,
+			ProjectID:   data.ProjectID,
+			DependsOn:   data.DependsOn,
+			Recurrence:  data.Recurrence,
+			CreatedAt:   createdAt,
+			Version:     1,
+		}
+		p.due.Put(event.TaskID, data.DueAt)
+		p.tags.Put(event.TaskID, data.Tags)
+		p.children.Put(event.TaskID, data.ParentID)
+
+		if event.TaskID > p.idCounter {
+			p.idCounter = event.TaskID
+		}
+
+		return nil
+	case TaskRestored:
+		task, ok := p.trash[event.TaskID]
+		if !ok {
+			return fmt.Errorf("event %d %s refers to unknown deleted task %d", event.Seq, event.Type, event.TaskID)
+		}
+
+		restored := *task
+		restored.Version++
+		restored.DeletedAt = nil
+
+		delete(p.trash, event.TaskID)
+		p.tasks[event.TaskID] = &restored
+		p.due.Put(event.TaskID, restored.DueAt)
+		p.tags.Put(event.TaskID, restored.Tags)
+		p.children.Put(event.TaskID, restored.ParentID)
+
+		return nil
+	case TaskPurged:
+		delete(p.trash, event.TaskID)
+
+		return nil
+	case TaskImported:
+		var data TaskImportedData
+		if err := event.DecodeData(&data); err != nil {
+			return err
+		}
+
+		task := &model.Task{
+			ID:          event.TaskID,
+			Title:       data.Title,
+			Description: data.Description,
+			Done:        data.Done,
+			Status:      data.Status,
+			DueAt:       data.DueAt,
+			Priority:    data.Priority,
+			Tags:        data.Tags,
+			ParentID:    data.ParentID,
+			ProjectID:   data.ProjectID,
+			DependsOn:   data.DependsOn,
+			Recurrence:  data.Recurrence,
+			CreatedAt:   data.CreatedAt,
+			Version:     data.Version,
+			DeletedAt:   data.DeletedAt,
+		}
+
+		delete(p.tasks, event.TaskID)
+		delete(p.trash, event.TaskID)
+		p.due.Remove(event.TaskID)
+		p.tags.Remove(event.TaskID)
+		p.children.Remove(event.TaskID)
+
+		if task.DeletedAt != nil {
+			p.trash[event.TaskID] = task
+		} else {
+			p.tasks[event.TaskID] = task
+			p.due.Put(event.TaskID, task.DueAt)
+			p.tags.Put(event.TaskID, task.Tags)
+			p.children.Put(event.TaskID, task.ParentID)
+		}
+
+		p.idCounter = max(p.idCounter, event.TaskID)
+
+		return nil
+	case TaskIDsReserved:
+		p.idCounter = max(p.idCounter, event.TaskID)
+
+		return nil
+	case TasksReplaced:
+		var data TasksReplacedData
+		if err := event.DecodeData(&data); err != nil {
+			return err
+		}
+
+		p.tasks = make(map[int]*model.Task, len(data.Tasks))
+		p.trash = make(map[int]*model.Task)
+		p.due = index.NewDue()
+		p.tags = index.NewTags()
+		p.children = index.NewChildren()
+
+		for _, task := range data.Tasks {
+			if task.DeletedAt != nil {
+				p.trash[task.ID] = task
+			} else {
+				p.tasks[task.ID] = task
+				p.due.Put(task.ID, task.DueAt)
+				p.tags.Put(task.ID, task.Tags)
+				p.children.Put(task.ID, task.ParentID)
+			}
+
+			p.idCounter = max(p.idCounter, task.ID)
+		}
+
+		p.idCounter = max(p.idCounter, event.TaskID)
+
+		return nil
+	}
+
+	task, ok := p.tasks[event.TaskID]
+	if !ok {
+		return fmt.Errorf("event %d %s refers to unknown task %d", event.Seq, event.Type, event.TaskID)
+	}
+
+	// задача заменяется копией, чтобы не менять значения, уже отданные читателям
+	updated := *task
+	updated.Version++
+
+	switch event.Type {
+	case TaskRenamed:
+		var data TaskRenamedData
+		if err := event.DecodeData(&data); err != nil {
+			return err
+		}
+
+		updated.Title = data.Title
+		updated.Description = data.Description
+	case TaskCompleted:
+		updated.Done = true
+	case TaskReopened:
+		updated.Done = false
+	case TaskStatusChanged:
+		var data TaskStatusChangedData
+		if err := event.DecodeData(&data); err != nil {
+			return err
+		}
+
+		updated.Status = data.Status
+	case TaskRescheduled:
+		var data TaskRescheduledData
+		if err := event.DecodeData(&data); err != nil {
+			return err
+		}
+
+		updated.DueAt = data.DueAt
+		p.due.Put(event.TaskID, data.DueAt)
+	case TaskReprioritized:
+		var data TaskReprioritizedData
+		if err := event.DecodeData(&data); err != nil {
+			return err
+		}
+
+		updated.Priority = data.Priority
+	case TaskRetagged:
+		var data TaskRetaggedData
+		if err := event.DecodeData(&data); err != nil {
+			return err
+		}
+
+		updated.Tags = data.Tags
+		p.tags.Put(event.TaskID, data.Tags)
+	case TaskMoved:
+		var data TaskMovedData
+		if err := event.DecodeData(&data); err != nil {
+			return err
+		}
+
+		updated.ParentID = data.ParentID
+		p.children.Put(event.TaskID, data.ParentID)
+	case TaskProjectChanged:
+		var data TaskProjectChangedData
+		if err := event.DecodeData(&data); err != nil {
+			return err
+		}
+
+		updated.ProjectID = data.ProjectID
+	case TaskDependenciesChanged:
+		var data TaskDependenciesChangedData
+		if err := event.DecodeData(&data); err != nil {
+			return err
+		}
+
+		updated.DependsOn = data.DependsOn
+	case TaskRecurrenceChanged:
+		var data TaskRecurrenceChangedData
+		if err := event.DecodeData(&data); err != nil {
+			return err
+		}
+
+		updated.Recurrence = data.Recurrence
+	case TaskDeleted:
+		deletedAt := event.OccurredAt
+		updated.DeletedAt = &deletedAt
+
+		delete(p.tasks, event.TaskID)
+		p.due.Remove(event.TaskID)
+		p.tags.Remove(event.TaskID)
+		p.children.Remove(event.TaskID)
+		p.trash[event.TaskID] = &updated
+
+		return nil
+	default:
+		return fmt.Errorf("unknown event type %q", event.Type)
+	}
+
+	p.tasks[event.TaskID] = &updated
+
+	return nil
+}
@@ -0,0 +1,198 @@
+package eventsourced
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// EventStore журнал событий, в который можно только дописывать
+type EventStore interface {
+	Append(ctx context.Context, events []Event) error
+	Load(ctx context.Context, fn func(Event) error) error
+	Close() error
+}
+
+type memoryStore struct {
+	events []Event
+	mu     *sync.RWMutex
+}
+
+// NewMemoryStore возвращает журнал событий в памяти процесса
+func NewMemoryStore() *memoryStore {
+	return &memoryStore{
+		mu: &sync.RWMutex{},
+	}
+}
+
+// Append дописывает события в журнал
+func (s *memoryStore) Append(_ context.Context, events []Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.events = append(s.events, events...)
+
+	return nil
+}
+
+// Load передает в fn все события журнала по порядку
+func (s *memoryStore) Load(ctx context.Context, fn func(Event) error) error {
+	s.mu.RLock()
+	events := s.events[:len(s.events):len(s.events)]
+	s.mu.RUnlock()
+
+	for _, event := range events {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if err := fn(event); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Close ничего не делает
+func (s *memoryStore) Close() error {
+	return nil
+}
+
+type fileStore struct {
+	file *os.File
+	size int64
+	mu   *sync.Mutex
+}
+
+// NewFileStore открывает журнал событий в файле path (одно событие в JSON на строку).
+// Недописанная последняя строка без перевода строки, оставшаяся после падения, отрезается,
+// испорченное событие в другом месте журнала - ошибка
+func NewFileStore(path string) (*fileStore, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open event log: %w", err)
+	}
+
+	s := &fileStore{
+		file: file,
+		mu:   &sync.Mutex{},
+	}
+
+	valid, err := s.scan(context.Background(), nil)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	if err := file.Truncate(valid); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to truncate event log: %w", err)
+	}
+
+	if _, err := file.Seek(valid, io.SeekStart); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to seek event log: %w", err)
+	}
+
+	s.size = valid
+
+	return s, nil
+}
+
+// Append дописывает события в журнал одной записью и сбрасывает ее на диск
+func (s *fileStore) Append(_ context.Context, events []Event) error {
+	var buf bytes.Buffer
+	for _, event := range events {
+		line, err := json.Marshal(event)
+		if err != nil {
+			return fmt.Errorf("failed to marshal event: %w", err)
+		}
+
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.file.Write(buf.Bytes()); err != nil {
+		s.rollback()
+		return fmt.Errorf("failed to write events: %w", err)
+	}
+
+	if err := s.file.Sync(); err != nil {
+		s.rollback()
+		return fmt.Errorf("failed to sync event log: %w", err)
+	}
+
+	s.size += int64(buf.Len())
+
+	return nil
+}
+
+// Load передает в fn все события журнала по порядку
+func (s *fileStore) Load(ctx context.Context, fn func(Event) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, err := s.scan(ctx, fn)
+
+	return err
+}
+
+// Close закрывает файл журнала
+func (s *fileStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.file.Close()
+}
+
+// scan читает журнал с начала и возвращает длину его целой части. Строка без перевода
+// строки в конце журнала - недописанное событие, она не читается
+func (s *fileStore) scan(ctx context.Context, fn func(Event) error) (int64, error) {
+	reader := bufio.NewReader(io.NewSectionReader(s.file, 0, 1<<62))
+
+	var valid int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return valid, err
+		}
+
+		line, err := reader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			return valid, nil
+		}
+
+		if err != nil {
+			return valid, fmt.Errorf("failed to read event log: %w", err)
+		}
+
+		var event Event
+		if err := json.Unmarshal(bytes.TrimSpace(line), &event); err != nil {
+			return valid, fmt.Errorf("corrupt event log record at offset %d: %w", valid, err)
+		}
+
+		if fn != nil {
+			if err := fn(event); err != nil {
+				return valid, err
+			}
+		}
+
+		valid += int64(len(line))
+	}
+}
+
+// rollback отрезает частично записанные события
+func (s *fileStore) rollback() {
+	if err := s.file.Truncate(s.size); err == nil {
+		s.file.Seek(s.size, io.SeekStart)
+	}
+}
@@ -0,0 +1,547 @@
+package eventsourced
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/solumD/tasks-service/internal/model"
+	"github.com/solumD/tasks-service/internal/usecase"
+)
+
+type taskRepo struct {
+	store       EventStore
+	state       *taskProjection
+	projections []Projection
+	history     map[int][]Event
+
+	mu  *sync.RWMutex
+	seq int64
+}
+
+// NewTaskRepo восстанавливает состояние задач и переданные проекции из журнала store
+func NewTaskRepo(ctx context.Context, store EventStore, projections ...Projection) (*taskRepo, error) {
+	r := &taskRepo{
+		store:       store,
+		state:       newTaskProjection(),
+		projections: projections,
+		history:     make(map[int][]Event),
+		mu:          &sync.RWMutex{},
+	}
+
+	err := store.Load(ctx, func(event Event) error {
+		if event.Seq <= r.seq {
+			return fmt.Errorf("event log is out of order: seq %d after %d", event.Seq, r.seq)
+		}
+
+		r.seq = event.Seq
+
+		return r.apply(event)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to replay event log: %w", err)
+	}
+
+	return r, nil
+}
+
+// CreateTask создает новую задачу в хранилище
+func (r *taskRepo) CreateTask(ctx context.Context, task *model.Task) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	id := r.state.idCounter + 1
+
+	event, err := newEvent(TaskCreated, id, TaskCreatedData{
+		Title:       task.Title,
+		Description: task.Description,
+		Done:        task.Done,
+		Status:      task.Status,
+		DueAt:       task.DueAt,
+		Priority:    task.Priority,
+		Tags:        task.Tags,
+		ParentID:    task.ParentID,
+		ProjectID:   task.ProjectID,
+		DependsOn:   task.DependsOn,
+		Recurrence:  task.Recurrence,
+		CreatedAt:   task.CreatedAt,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if err := r.commit(ctx, []Event{event}); err != nil {
+		return 0, err
+	}
+
+	task.ID = id
+	task.Version = 1
+
+	return id, nil
+}
+
+// GetAllTasks возвращает все задачи из хранилища
+func (r *taskRepo) GetAllTasks(ctx context.Context) ([]*model.Task, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tasks := make([]*model.Task, 0, len(r.state.tasks))
+
+	for _, task := range r.state.tasks {
+		tasks = append(tasks, task)
+	}
+
+	return tasks, nil
+}
+
+// GetTaskByID возвращает задачу по ID из хранилища
+func (r *taskRepo) GetTaskByID(ctx context.Context, id int) (*model.Task, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	task, ok := r.state.tasks[id]
+	if !ok {
+		return nil, usecase.NewTaskNotFoundError(id)
+	}
+
+	return task, nil
+}
+
+// GetTasksByDue возвращает задачи со сроком выполнения в диапазоне [from, to) по индексу сроков
+func (r *taskRepo) GetTasksByDue(ctx context.Context, from, to time.Time) ([]*model.Task, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	ids := r.state.due.Range(from, to)
+	tasks := make([]*model.Task, 0, len(ids))
+
+	for _, id := range ids {
+		tasks = append(tasks, r.state.tasks[id])
+	}
+
+	return tasks, nil
+}
+
+// GetTasksByTags возвращает задачи хотя бы с одной из меток tags, а при all - со всеми, по индексу меток
+func (r *taskRepo) GetTasksByTags(ctx context.Context, tags []string, all bool) ([]*model.Task, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	ids := r.state.tags.Match(tags, all)
+	tasks := make([]*model.Task, 0, len(ids))
+
+	for _, id := range ids {
+		tasks = append(tasks, r.state.tasks[id])
+	}
+
+	return tasks, nil
+}
+
+// GetTagCounts возвращает метки задач с количеством задач у каждой
+func (r *taskRepo) GetTagCounts(ctx context.Context) ([]model.TagCount, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.state.tags.Counts(), nil
+}
+
+// GetTasksByParent возвращает подзадачи задачи parentID по индексу подзадач
+func (r *taskRepo) GetTasksByParent(ctx context.Context, parentID int) ([]*model.Task, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	ids := r.state.children.IDs(parentID)
+	tasks := make([]*model.Task, 0, len(ids))
+
+	for _, id := range ids {
+		tasks = append(tasks, r.state.tasks[id])
+	}
+
+	return tasks, nil
+}
+
+// RenameTag записывает событие TaskRetagged для каждой задачи с меткой from.
+// События дописываются в журнал одним пакетом
+func (r *taskRepo) RenameTag(ctx context.Context, from, to string) ([]*model.Task, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	ids := r.state.tags.IDs(from)
+	if len(ids) == 0 {
+		return []*model.Task{}, nil
+	}
+
+	events := make([]Event, 0, len(ids))
+
+	for _, id := range ids {
+		task := r.state.tasks[id].Clone()
+		task.RenameTag(from, to)
+
+		event, err := newEvent(TaskRetagged, id, TaskRetaggedData{Tags: task.Tags})
+		if err != nil {
+			return nil, err
+		}
+
+		events = append(events, event)
+	}
+
+	if err := r.commit(ctx, events); err != nil {
+		return nil, err
+	}
+
+	renamed := make([]*model.Task, 0, len(ids))
+	for _, id := range ids {
+		renamed = append(renamed, r.state.tasks[id])
+	}
+
+	return renamed, nil
+}
+
+// UpdateTask записывает события, которые переводят задачу в переданное состояние,
+// если ее версия совпадает с task.Version. Каждое событие увеличивает версию задачи
+func (r *taskRepo) UpdateTask(ctx context.Context, task *model.Task) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, ok := r.state.tasks[task.ID]
+	if !ok {
+		return usecase.NewTaskNotFoundError(task.ID)
+	}
+
+	if task.Version != 0 && task.Version != current.Version {
+		return usecase.ErrVersionConflict
+	}
+
+	task.CreatedAt = current.CreatedAt
+
+	events := make([]Event, 0, 5)
+
+	if current.Title != task.Title || current.Description != task.Description {
+		event, err := newEvent(TaskRenamed, task.ID, TaskRenamedData{
+			Title:       task.Title,
+			Description: task.Description,
+		})
+		if err != nil {
+			return err
+		}
+
+		events = append(events, event)
+	}
+
+	if current.Done != task.Done {
+		eventType := TaskReopened
+		if task.Done {
+			eventType = TaskCompleted
+		}
+
+		event, err := newEvent(eventType, task.ID, nil)
+		if err != nil {
+			return err
+		}
+
+		events = append(events, event)
+	}
+
+	if current.Status != task.Status {
+		event, err := newEvent(TaskStatusChanged, task.ID, TaskStatusChangedData{Status: task.Status})
+		if err != nil {
+			return err
+		}
+
+		events = append(events, event)
+	}
+
+	if !equalDue(current.DueAt, task.DueAt) {
+		event, err := newEvent(TaskRescheduled, task.ID, TaskRescheduledData{DueAt: task.DueAt})
+		if err != nil {
+			return err
+		}
+
+		events = append(events, event)
+	}
+
+	if current.Priority != task.Priority {
+		event, err := newEvent(TaskReprioritized, task.ID, TaskReprioritizedData{Priority: task.Priority})
+		if err != nil {
+			return err
+		}
+
+		events = append(events, event)
+	}
+
+	if !slices.Equal(current.Tags, task.Tags) {
+		event, err := newEvent(TaskRetagged, task.ID, TaskRetaggedData{Tags: task.Tags})
+		if err != nil {
+			return err
+		}
+
+		events = append(events, event)
+	}
+
+	if current.ParentID != task.ParentID {
+		event, err := newEvent(TaskMoved, task.ID, TaskMovedData{ParentID: task.ParentID})
+		if err != nil {
+			return err
+		}
+
+		events = append(events, event)
+	}
+
+	if current.ProjectID != task.ProjectID {
+		event, err := newEvent(TaskProjectChanged, task.ID, TaskProjectChangedData{ProjectID: task.ProjectID})
+		if err != nil {
+			return err
+		}
+
+		events = append(events, event)
+	}
+
+	if !slices.Equal(current.DependsOn, task.DependsOn) {
+		event, err := newEvent(TaskDependenciesChanged, task.ID, TaskDependenciesChangedData{DependsOn: task.DependsOn})
+		if err != nil {
+			return err
+		}
+
+		events = append(events, event)
+	}
+
+	if current.Recurrence != task.Recurrence {
+		event, err := newEvent(TaskRecurrenceChanged, task.ID, TaskRecurrenceChangedData{Recurrence: task.Recurrence})
+		if err != nil {
+			return err
+		}
+
+		events = append(events, event)
+	}
+
+	if len(events) > 0 {
+		if err := r.commit(ctx, events); err != nil {
+			return err
+		}
+	}
+
+	task.Version = r.state.tasks[task.ID].Version
+
+	return nil
+}
+
+// DeleteTask перемещает задачу в корзину, если ее версия совпадает с version,
+// и возвращает ее
+func (r *taskRepo) DeleteTask(ctx context.Context, id int, version int) (*model.Task, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, ok := r.state.tasks[id]
+	if !ok {
+		return nil, usecase.NewTaskNotFoundError(id)
+	}
+
+	if version != 0 && version != current.Version {
+		return nil, usecase.ErrVersionConflict
+	}
+
+	event, err := newEvent(TaskDeleted, id, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.commit(ctx, []Event{event}); err != nil {
+		return nil, err
+	}
+
+	return r.state.trash[id], nil
+}
+
+// GetDeletedTasks возвращает все задачи из корзины
+func (r *taskRepo) GetDeletedTasks(ctx context.Context) ([]*model.Task, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tasks := make([]*model.Task, 0, len(r.state.trash))
+
+	for _, task := range r.state.trash {
+		tasks = append(tasks, task)
+	}
+
+	return tasks, nil
+}
+
+// RestoreTask возвращает задачу из корзины
+func (r *taskRepo) RestoreTask(ctx context.Context, id int) (*model.Task, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.state.trash[id]; !ok {
+		return nil, usecase.NewTaskNotFoundError(id)
+	}
+
+	event, err := newEvent(TaskRestored, id, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.commit(ctx, []Event{event}); err != nil {
+		return nil, err
+	}
+
+	return r.state.tasks[id], nil
+}
+
+// PurgeDeletedTasks окончательно удаляет задачи, попавшие в корзину раньше deletedBefore.
+// Для каждой задачи в журнал пишется событие TaskPurged
+func (r *taskRepo) PurgeDeletedTasks(ctx context.Context, deletedBefore time.Time) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	events := make([]Event, 0)
+
+	for id, task := range r.state.trash {
+		if !task.DeletedAt.Before(deletedBefore) {
+			continue
+		}
+
+		event, err := newEvent(TaskPurged, id, nil)
+		if err != nil {
+			return 0, err
+		}
+
+		events = append(events, event)
+	}
+
+	if len(events) == 0 {
+		return 0, nil
+	}
+
+	if err := r.commit(ctx, events); err != nil {
+		return 0, err
+	}
+
+	return len(events), nil
+}
+
+// History возвращает все события задачи по порядку, в том числе удаленной
+func (r *taskRepo) History(ctx context.Context, id int) ([]Event, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	events, ok := r.history[id]
+	if !ok {
+		return nil, usecase.NewTaskNotFoundError(id)
+	}
+
+	return append([]Event(nil), events...), nil
+}
+
+// Close закрывает журнал событий
+func (r *taskRepo) Close() error {
+	return r.store.Close()
+}
+
+// commit нумерует события, дописывает их в журнал и применяет к проекциям.
+// Вызывается под блокировкой на запись
+func (r *taskRepo) commit(ctx context.Context, events []Event) error {
+	for i := range events {
+		events[i].Seq = r.seq + int64(i) + 1
+	}
+
+	if err := r.store.Append(ctx, events); err != nil {
+		return fmt.Errorf("failed to append events: %w", err)
+	}
+
+	r.seq += int64(len(events))
+
+	for _, event := range events {
+		if err := r.apply(event); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *taskRepo) apply(event Event) error {
+	if err := r.state.Apply(event); err != nil {
+		return err
+	}
+
+	// резервирование ID и замена всех задач не относятся к какой-либо одной задаче
+	if event.Type != TaskIDsReserved && event.Type != TasksReplaced {
+		r.history[event.TaskID] = append(r.history[event.TaskID], event)
+	}
+
+	for _, projection := range r.projections {
+		if err := projection.Apply(event); err != nil {
+			return fmt.Errorf("failed to apply event %d to projection: %w", event.Seq, err)
+		}
+	}
+
+	return nil
+}
+
+// equalDue сравнивает сроки выполнения вместе с часовым поясом: перенос срока
+// в другой пояс тоже записывается событием
+func equalDue(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return a.Format(time.RFC3339Nano) == b.Format(time.RFC3339Nano)
+}
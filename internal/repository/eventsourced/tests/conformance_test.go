@@ -0,0 +1,22 @@
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/solumD/tasks-service/internal/repository/eventsourced"
+	"github.com/solumD/tasks-service/internal/repository/repotest"
+	"github.com/solumD/tasks-service/internal/usecase"
+)
+
+func TestConformance(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) usecase.TaskRepo {
+		repo, err := eventsourced.NewTaskRepo(context.Background(), eventsourced.NewMemoryStore())
+		if err != nil {
+			t.Fatalf("failed to open repo: %v", err)
+		}
+		t.Cleanup(func() { repo.Close() })
+
+		return repo
+	})
+}
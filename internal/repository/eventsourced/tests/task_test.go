@@ -0,0 +1,349 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/solumD/tasks-service/internal/model"
+	"github.com/solumD/tasks-service/internal/repository/eventsourced"
+	"github.com/solumD/tasks-service/internal/usecase"
+)
+
+// countingProjection считает события по типам
+type countingProjection struct {
+	counts map[eventsourced.EventType]int
+}
+
+func (p *countingProjection) Apply(event eventsourced.Event) error {
+	p.counts[event.Type]++
+	return nil
+}
+
+func TestUpdateEmitsEvents(t *testing.T) {
+	ctx := context.Background()
+
+	dueAt := time.Date(2026, time.March, 1, 18, 0, 0, 0, time.FixedZone("", 3*60*60))
+
+	tests := []struct {
+		name           string
+		update         *model.Task
+		expectedEvents []eventsourced.EventType
+	}{
+		{
+			name:           "no changes",
+			update:         &model.Task{ID: 1, Title: "Task1", Description: "Desc"},
+			expectedEvents: []eventsourced.EventType{eventsourced.TaskCreated},
+		},
+		{
+			name:           "rename",
+			update:         &model.Task{ID: 1, Title: "Renamed", Description: "Desc"},
+			expectedEvents: []eventsourced.EventType{eventsourced.TaskCreated, eventsourced.TaskRenamed},
+		},
+		{
+			name:           "complete",
+			update:         &model.Task{ID: 1, Title: "Task1", Description: "Desc", Done: true},
+			expectedEvents: []eventsourced.EventType{eventsourced.TaskCreated, eventsourced.TaskCompleted},
+		},
+		{
+			name:           "reschedule",
+			update:         &model.Task{ID: 1, Title: "Task1", Description: "Desc", DueAt: &dueAt},
+			expectedEvents: []eventsourced.EventType{eventsourced.TaskCreated, eventsourced.TaskRescheduled},
+		},
+		{
+			name:           "reprioritize",
+			update:         &model.Task{ID: 1, Title: "Task1", Description: "Desc", Priority: model.PriorityHigh},
+			expectedEvents: []eventsourced.EventType{eventsourced.TaskCreated, eventsourced.TaskReprioritized},
+		},
+		{
+			name:           "retag",
+			update:         &model.Task{ID: 1, Title: "Task1", Description: "Desc", Tags: []string{"home"}},
+			expectedEvents: []eventsourced.EventType{eventsourced.TaskCreated, eventsourced.TaskRetagged},
+		},
+		{
+			name:   "rename and complete",
+			update: &model.Task{ID: 1, Title: "Task1", Description: "New desc", Done: true},
+			expectedEvents: []eventsourced.EventType{
+				eventsourced.TaskCreated, eventsourced.TaskRenamed, eventsourced.TaskCompleted,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, err := eventsourced.NewTaskRepo(ctx, eventsourced.NewMemoryStore())
+			if err != nil {
+				t.Fatalf("failed to create repo: %v", err)
+			}
+
+			if _, err := repo.CreateTask(ctx, &model.Task{Title: "Task1", Description: "Desc"}); err != nil {
+				t.Fatalf("failed to create task: %v", err)
+			}
+
+			if err := repo.UpdateTask(ctx, tt.update); err != nil {
+				t.Fatalf("failed to update task: %v", err)
+			}
+
+			history, err := repo.History(ctx, 1)
+			if err != nil {
+				t.Fatalf("failed to get history: %v", err)
+			}
+
+			if len(history) != len(tt.expectedEvents) {
+				t.Fatalf("expected %d events, got %d", len(tt.expectedEvents), len(history))
+			}
+
+			for i, event := range history {
+				if event.Type != tt.expectedEvents[i] {
+					t.Fatalf("expected event %d to be %s, got %s", i, tt.expectedEvents[i], event.Type)
+				}
+			}
+
+			task, _ := repo.GetTaskByID(ctx, 1)
+			if task.Title != tt.update.Title || task.Description != tt.update.Description || task.Done != tt.update.Done ||
+				(task.DueAt == nil) != (tt.update.DueAt == nil) || len(task.Tags) != len(tt.update.Tags) {
+				t.Fatalf("expected projection %+v, got %+v", tt.update, task)
+			}
+		})
+	}
+}
+
+func TestReplayRebuildsProjections(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "events.log")
+
+	store, err := eventsourced.NewFileStore(path)
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+
+	repo, err := eventsourced.NewTaskRepo(ctx, store)
+	if err != nil {
+		t.Fatalf("failed to create repo: %v", err)
+	}
+
+	for _, title := range []string{"Task1", "Task2", "Task3"} {
+		if _, err := repo.CreateTask(ctx, &model.Task{Title: title}); err != nil {
+			t.Fatalf("failed to create task: %v", err)
+		}
+	}
+
+	if err := repo.UpdateTask(ctx, &model.Task{ID: 1, Title: "Task1", Done: true}); err != nil {
+		t.Fatalf("failed to update task: %v", err)
+	}
+
+	if err := repo.UpdateTask(ctx, &model.Task{ID: 1, Title: "Task1"}); err != nil {
+		t.Fatalf("failed to update task: %v", err)
+	}
+
+	dueAt := time.Date(2026, time.March, 1, 18, 0, 0, 0, time.UTC)
+	if err := repo.UpdateTask(ctx, &model.Task{ID: 2, Title: "Task2", DueAt: &dueAt}); err != nil {
+		t.Fatalf("failed to update task: %v", err)
+	}
+
+	if err := repo.UpdateTask(ctx, &model.Task{ID: 2, Title: "Task2", DueAt: &dueAt, Tags: []string{"home"}}); err != nil {
+		t.Fatalf("failed to update task: %v", err)
+	}
+
+	if _, err := repo.RenameTag(ctx, "home", "house"); err != nil {
+		t.Fatalf("failed to rename tag: %v", err)
+	}
+
+	if _, err := repo.DeleteTask(ctx, 3, 0); err != nil {
+		t.Fatalf("failed to delete task: %v", err)
+	}
+
+	repo.Close()
+
+	store, err = eventsourced.NewFileStore(path)
+	if err != nil {
+		t.Fatalf("failed to reopen store: %v", err)
+	}
+
+	counter := &countingProjection{counts: make(map[eventsourced.EventType]int)}
+
+	replayed, err := eventsourced.NewTaskRepo(ctx, store, counter)
+	if err != nil {
+		t.Fatalf("failed to replay repo: %v", err)
+	}
+	defer replayed.Close()
+
+	tasks, _ := replayed.GetAllTasks(ctx)
+	if len(tasks) != 2 {
+		t.Fatalf("expected 2 tasks after replay, got %d", len(tasks))
+	}
+
+	task, _ := replayed.GetTaskByID(ctx, 1)
+	if task == nil || task.Done {
+		t.Fatalf("expected reopened task 1, got %+v", task)
+	}
+
+	// индекс сроков тоже восстанавливается из журнала
+	due, err := replayed.GetTasksByDue(ctx, dueAt, dueAt.Add(time.Second))
+	if err != nil || len(due) != 1 || due[0].ID != 2 {
+		t.Fatalf("expected task 2 by due date after replay, got %v (err %v)", due, err)
+	}
+
+	// и индекс меток
+	tagged, err := replayed.GetTasksByTags(ctx, []string{"house"}, false)
+	if err != nil || len(tagged) != 1 || tagged[0].ID != 2 {
+		t.Fatalf("expected task 2 by tag after replay, got %v (err %v)", tagged, err)
+	}
+
+	if counter.counts[eventsourced.TaskCreated] != 3 || counter.counts[eventsourced.TaskCompleted] != 1 ||
+		counter.counts[eventsourced.TaskRescheduled] != 1 || counter.counts[eventsourced.TaskRetagged] != 2 ||
+		counter.counts[eventsourced.TaskReopened] != 1 || counter.counts[eventsourced.TaskDeleted] != 1 {
+		t.Fatalf("unexpected projection counts: %v", counter.counts)
+	}
+
+	history, err := replayed.History(ctx, 3)
+	if err != nil || len(history) != 2 || history[1].Type != eventsourced.TaskDeleted {
+		t.Fatalf("expected history of deleted task to be kept, got %v (err %v)", history, err)
+	}
+
+	id, err := replayed.CreateTask(ctx, &model.Task{Title: "Task4"})
+	if err != nil || id != 4 {
+		t.Fatalf("expected next id 4 after replay, got %d (err %v)", id, err)
+	}
+}
+
+func TestNotFound(t *testing.T) {
+	ctx := context.Background()
+
+	repo, err := eventsourced.NewTaskRepo(ctx, eventsourced.NewMemoryStore())
+	if err != nil {
+		t.Fatalf("failed to create repo: %v", err)
+	}
+
+	if err := repo.UpdateTask(ctx, &model.Task{ID: 1, Title: "Task"}); !errors.Is(err, usecase.ErrTaskNotFound) {
+		t.Fatalf("expected ErrTaskNotFound on update, got %v", err)
+	}
+
+	if _, err := repo.DeleteTask(ctx, 1, 0); !errors.Is(err, usecase.ErrTaskNotFound) {
+		t.Fatalf("expected ErrTaskNotFound on delete, got %v", err)
+	}
+
+	if _, err := repo.History(ctx, 1); !errors.Is(err, usecase.ErrTaskNotFound) {
+		t.Fatalf("expected ErrTaskNotFound on history, got %v", err)
+	}
+}
+
+func TestTrashEvents(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "events.log")
+
+	store, err := eventsourced.NewFileStore(path)
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+
+	repo, err := eventsourced.NewTaskRepo(ctx, store)
+	if err != nil {
+		t.Fatalf("failed to create repo: %v", err)
+	}
+
+	for _, title := range []string{"Task1", "Task2"} {
+		if _, err := repo.CreateTask(ctx, &model.Task{Title: title}); err != nil {
+			t.Fatalf("failed to create task: %v", err)
+		}
+	}
+
+	for _, id := range []int{1, 2} {
+		if _, err := repo.DeleteTask(ctx, id, 0); err != nil {
+			t.Fatalf("failed to delete task: %v", err)
+		}
+	}
+
+	task, err := repo.RestoreTask(ctx, 1)
+	if err != nil || task.Version != 3 || task.DeletedAt != nil {
+		t.Fatalf("expected restored task with version 3, got %+v (err %v)", task, err)
+	}
+
+	if _, err := repo.RestoreTask(ctx, 1); !errors.Is(err, usecase.ErrTaskNotFound) {
+		t.Fatalf("expected ErrTaskNotFound on restore of live task, got %v", err)
+	}
+
+	if purged, err := repo.PurgeDeletedTasks(ctx, time.Now().Add(time.Second)); err != nil || purged != 1 {
+		t.Fatalf("expected 1 purged task, got %d (err %v)", purged, err)
+	}
+
+	repo.Close()
+
+	store, err = eventsourced.NewFileStore(path)
+	if err != nil {
+		t.Fatalf("failed to reopen store: %v", err)
+	}
+
+	replayed, err := eventsourced.NewTaskRepo(ctx, store)
+	if err != nil {
+		t.Fatalf("failed to replay repo: %v", err)
+	}
+	defer replayed.Close()
+
+	if trash, _ := replayed.GetDeletedTasks(ctx); len(trash) != 0 {
+		t.Fatalf("expected empty trash after purge, got %v", trash)
+	}
+
+	if task, err := replayed.GetTaskByID(ctx, 1); err != nil || task.Version != 3 {
+		t.Fatalf("expected restored task 1 with version 3, got %+v (err %v)", task, err)
+	}
+
+	history, err := replayed.History(ctx, 2)
+	if err != nil || len(history) != 3 || history[2].Type != eventsourced.TaskPurged {
+		t.Fatalf("expected purge to be kept in history, got %v (err %v)", history, err)
+	}
+}
+
+func TestFileStoreTail(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "events.log")
+
+	store, err := eventsourced.NewFileStore(path)
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+
+	repo, err := eventsourced.NewTaskRepo(ctx, store)
+	if err != nil {
+		t.Fatalf("failed to create repo: %v", err)
+	}
+
+	if _, err := repo.CreateTask(ctx, &model.Task{Title: "Task1"}); err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+
+	repo.Close()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read log: %v", err)
+	}
+
+	// недописанное событие в конце журнала отрезается
+	if err := os.WriteFile(path, append(data, `{"type":"TaskCre`...), 0o644); err != nil {
+		t.Fatalf("failed to write log: %v", err)
+	}
+
+	store, err = eventsourced.NewFileStore(path)
+	if err != nil {
+		t.Fatalf("expected torn tail to be dropped, got %v", err)
+	}
+	store.Close()
+
+	// испорченное событие перед целыми - ошибка, журнал не обрезается
+	corrupted := append([]byte("garbage\n"), data...)
+	if err := os.WriteFile(path, corrupted, 0o644); err != nil {
+		t.Fatalf("failed to write log: %v", err)
+	}
+
+	if _, err := eventsourced.NewFileStore(path); err == nil {
+		t.Fatal("expected error on corrupt event in the middle of log")
+	}
+
+	if after, _ := os.ReadFile(path); string(after) != string(corrupted) {
+		t.Fatal("expected event log to stay untouched")
+	}
+}
@@ -0,0 +1,59 @@
+package file
+
+import (
+	"context"
+
+	"github.com/solumD/tasks-service/internal/model"
+)
+
+// DumpTasks возвращает копии всех задач, включая корзину, и ID следующей задачи
+// на один момент времени
+func (r *taskRepo) DumpTasks(ctx context.Context) ([]*model.Task, int, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tasks := make([]*model.Task, 0, len(r.tasks)+len(r.trash))
+
+	for _, task := range r.tasks {
+		tasks = append(tasks, task.Clone())
+	}
+
+	for _, task := range r.trash {
+		tasks = append(tasks, task.Clone())
+	}
+
+	return tasks, r.idCounter + 1, nil
+}
+
+// ReplaceTasks заменяет все задачи, включая корзину, на tasks. Замена пишется
+// в журнал одной записью, поэтому после сбоя она либо применена целиком, либо нет.
+// Счетчик ID не уменьшается, а следующая задача получит ID не меньше nextID
+func (r *taskRepo) ReplaceTasks(ctx context.Context, tasks []*model.Task, nextID int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	rec := walRecord{Op: opReplace, ID: nextID - 1, Tasks: make([]*model.Task, 0, len(tasks))}
+	for _, task := range tasks {
+		rec.Tasks = append(rec.Tasks, task.Clone())
+	}
+
+	if err := r.appendRecords(rec); err != nil {
+		return err
+	}
+
+	if err := r.apply(rec); err != nil {
+		return err
+	}
+
+	r.compactIfNeeded()
+
+	return nil
+}
@@ -0,0 +1,368 @@
+package file
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/solumD/tasks-service/internal/model"
+	"github.com/solumD/tasks-service/internal/repository/index"
+	"github.com/solumD/tasks-service/internal/usecase"
+)
+
+// CommentsFileName имя файла комментариев по умолчанию
+const CommentsFileName = "comments.log"
+
+// commentRecord запись журнала комментариев: Comment - созданный или измененный
+// комментарий, DeletedIDs - ID удаленных комментариев. Replace - замена всех
+// комментариев на Comments со сдвигом счетчика до NextID
+type commentRecord struct {
+	Comment    *model.Comment   `json:"comment,omitempty"`
+	DeletedIDs []int            `json:"deleted_ids,omitempty"`
+	Replace    bool             `json:"replace,omitempty"`
+	Comments   []*model.Comment `json:"comments,omitempty"`
+	NextID     int              `json:"next_id,omitempty"`
+}
+
+type commentRepo struct {
+	comments map[int]*model.Comment
+	index    *index.Comments
+
+	mu        *sync.RWMutex
+	idCounter int
+	log       *os.File
+	size      int64
+}
+
+// NewCommentRepo открывает журнал комментариев path и загружает его в память.
+// Каждое изменение дописывается в журнал и сбрасывается на диск до ответа; журнал
+// не сжимается, поэтому ID удаленных комментариев не выдаются повторно.
+// Недописанная последняя строка (сбой посреди записи) отбрасывается,
+// испорченная запись в середине журнала считается ошибкой
+func NewCommentRepo(path string) (*commentRepo, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create comments dir: %w", err)
+	}
+
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open comments log: %w", err)
+	}
+
+	r := &commentRepo{
+		comments: make(map[int]*model.Comment),
+		index:    index.NewComments(),
+		mu:       &sync.RWMutex{},
+	}
+
+	reader := bufio.NewReader(f)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			f.Close()
+			return nil, fmt.Errorf("failed to read comments log: %w", err)
+		}
+
+		var rec commentRecord
+		if err := json.Unmarshal(bytes.TrimSpace(line), &rec); err != nil {
+			f.Close()
+			return nil, fmt.Errorf("corrupt comments log record at offset %d: %w", r.size, err)
+		}
+
+		r.apply(rec)
+		r.size += int64(len(line))
+	}
+
+	if err := f.Truncate(r.size); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to truncate comments log: %w", err)
+	}
+
+	if _, err := f.Seek(r.size, io.SeekStart); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to seek comments log: %w", err)
+	}
+
+	r.log = f
+
+	return r, nil
+}
+
+// CreateComment создает новый комментарий в хранилище
+func (r *commentRepo) CreateComment(ctx context.Context, comment *model.Comment) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored := comment.Clone()
+	stored.ID = r.idCounter + 1
+
+	if err := r.append(commentRecord{Comment: stored}); err != nil {
+		return 0, err
+	}
+
+	comment.ID = stored.ID
+
+	return comment.ID, nil
+}
+
+// GetComments возвращает до limit комментариев верхнего уровня задачи taskID с ID больше after
+func (r *commentRepo) GetComments(ctx context.Context, taskID int, after int, limit int) ([]*model.Comment, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.byIDs(r.index.Threads(taskID, after, limit)), nil
+}
+
+// GetReplies возвращает ответы на комментарии parentIDs по возрастанию ID
+func (r *commentRepo) GetReplies(ctx context.Context, parentIDs []int) ([]*model.Comment, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	ids := make([]int, 0)
+	for _, parentID := range parentIDs {
+		ids = append(ids, r.index.Replies(parentID)...)
+	}
+
+	sort.Ints(ids)
+
+	return r.byIDs(ids), nil
+}
+
+// GetCommentByID возвращает комментарий по ID из хранилища
+func (r *commentRepo) GetCommentByID(ctx context.Context, id int) (*model.Comment, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	comment, ok := r.comments[id]
+	if !ok {
+		return nil, usecase.NewCommentNotFoundError(id)
+	}
+
+	return comment.Clone(), nil
+}
+
+// UpdateComment меняет текст и время изменения комментария
+func (r *commentRepo) UpdateComment(ctx context.Context, comment *model.Comment) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, ok := r.comments[comment.ID]
+	if !ok {
+		return usecase.NewCommentNotFoundError(comment.ID)
+	}
+
+	stored := current.Clone()
+	stored.Text = comment.Text
+	stored.EditedAt = comment.Clone().EditedAt
+
+	return r.append(commentRecord{Comment: stored})
+}
+
+// DeleteComment удаляет комментарий вместе с ответами на него
+func (r *commentRepo) DeleteComment(ctx context.Context, id int) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.comments[id]; !ok {
+		return 0, usecase.NewCommentNotFoundError(id)
+	}
+
+	ids := append(r.index.Replies(id), id)
+	if err := r.append(commentRecord{DeletedIDs: ids}); err != nil {
+		return 0, err
+	}
+
+	return len(ids), nil
+}
+
+// DeleteTaskComments удаляет все комментарии задач taskIDs
+func (r *commentRepo) DeleteTaskComments(ctx context.Context, taskIDs []int) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	ids := make([]int, 0)
+	for _, taskID := range taskIDs {
+		for _, id := range r.index.AllThreads(taskID) {
+			ids = append(ids, id)
+			ids = append(ids, r.index.Replies(id)...)
+		}
+	}
+
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	if err := r.append(commentRecord{DeletedIDs: ids}); err != nil {
+		return 0, err
+	}
+
+	return len(ids), nil
+}
+
+// DumpComments возвращает копии всех комментариев по возрастанию ID и ID следующего
+// комментария на один момент времени
+func (r *commentRepo) DumpComments(ctx context.Context) ([]*model.Comment, int, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	ids := make([]int, 0, len(r.comments))
+	for id := range r.comments {
+		ids = append(ids, id)
+	}
+
+	sort.Ints(ids)
+
+	return r.byIDs(ids), r.idCounter + 1, nil
+}
+
+// ReplaceComments заменяет все комментарии на comments одной записью журнала.
+// Счетчик ID не уменьшается, а следующий комментарий получит ID не меньше nextID
+func (r *commentRepo) ReplaceComments(ctx context.Context, comments []*model.Comment, nextID int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.append(commentRecord{Replace: true, Comments: comments, NextID: nextID})
+}
+
+// Close закрывает журнал комментариев
+func (r *commentRepo) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.log == nil {
+		return nil
+	}
+
+	err := r.log.Close()
+	r.log = nil
+
+	return err
+}
+
+// append дописывает запись в журнал и применяет ее к комментариям в памяти.
+// Если запись не удалась, журнал обрезается до прежнего размера
+func (r *commentRepo) append(rec commentRecord) error {
+	if r.log == nil {
+		return errors.New("comments log is closed")
+	}
+
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("failed to marshal comment record: %w", err)
+	}
+
+	line = append(line, '\n')
+
+	if _, err := r.log.Write(line); err != nil {
+		r.rollback()
+		return fmt.Errorf("failed to write comment record: %w", err)
+	}
+
+	if err := r.log.Sync(); err != nil {
+		r.rollback()
+		return fmt.Errorf("failed to sync comments log: %w", err)
+	}
+
+	r.size += int64(len(line))
+
+	r.apply(rec)
+
+	return nil
+}
+
+// apply применяет запись журнала к комментариям в памяти
+func (r *commentRepo) apply(rec commentRecord) {
+	if rec.Replace {
+		r.comments = make(map[int]*model.Comment, len(rec.Comments))
+		r.index = index.NewComments()
+		for _, comment := range rec.Comments {
+			stored := comment.Clone()
+			r.comments[stored.ID] = stored
+			r.index.Put(stored)
+			r.idCounter = max(r.idCounter, stored.ID)
+		}
+
+		r.idCounter = max(r.idCounter, rec.NextID-1)
+	}
+
+	if rec.Comment != nil {
+		comment := rec.Comment.Clone()
+		r.comments[comment.ID] = comment
+		r.index.Put(comment)
+		r.idCounter = max(r.idCounter, comment.ID)
+	}
+
+	for _, id := range rec.DeletedIDs {
+		comment, ok := r.comments[id]
+		if !ok {
+			continue
+		}
+
+		r.index.Remove(comment)
+		delete(r.comments, id)
+	}
+}
+
+// byIDs возвращает копии комментариев ids в том же порядке
+func (r *commentRepo) byIDs(ids []int) []*model.Comment {
+	comments := make([]*model.Comment, 0, len(ids))
+	for _, id := range ids {
+		comments = append(comments, r.comments[id].Clone())
+	}
+
+	return comments
+}
+
+func (r *commentRepo) rollback() {
+	if err := r.log.Truncate(r.size); err == nil {
+		r.log.Seek(r.size, io.SeekStart)
+	}
+}
@@ -0,0 +1,59 @@
+package file
+
+import (
+	"context"
+
+	"github.com/solumD/tasks-service/internal/model"
+)
+
+// NextID возвращает ID, который получит следующая созданная задача
+func (r *taskRepo) NextID(ctx context.Context) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.idCounter + 1, nil
+}
+
+// ImportTasks записывает задачи с их ID, версиями и временем удаления, заменяя
+// задачи с теми же ID, и сдвигает счетчик так, чтобы следующая задача получила ID не меньше nextID.
+// Все записи пачки попадают в журнал одним сбросом на диск
+func (r *taskRepo) ImportTasks(ctx context.Context, tasks []*model.Task, nextID int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	records := make([]walRecord, 0, len(tasks)+1)
+
+	for _, task := range tasks {
+		records = append(records, walRecord{Op: opImport, ID: task.ID, Task: task.Clone()})
+	}
+
+	if nextID-1 > r.idCounter {
+		records = append(records, walRecord{Op: opReserve, ID: nextID - 1})
+	}
+
+	if len(records) == 0 {
+		return nil
+	}
+
+	if err := r.appendRecords(records...); err != nil {
+		return err
+	}
+
+	for _, rec := range records {
+		if err := r.apply(rec); err != nil {
+			return err
+		}
+	}
+
+	r.compactIfNeeded()
+
+	return nil
+}
@@ -0,0 +1,271 @@
+package file
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/solumD/tasks-service/internal/fsync"
+	"github.com/solumD/tasks-service/internal/model"
+	"github.com/solumD/tasks-service/internal/usecase"
+)
+
+// ProjectsFileName имя файла проектов по умолчанию
+const ProjectsFileName = "projects.json"
+
+// projectsFile содержимое файла проектов
+type projectsFile struct {
+	IDCounter int              `json:"id_counter"`
+	Projects  []*model.Project `json:"projects"`
+}
+
+type projectRepo struct {
+	projects map[int]*model.Project
+
+	mu        *sync.RWMutex
+	idCounter int
+
+	path string
+}
+
+// NewProjectRepo открывает файл проектов path и загружает его в память.
+// Проектов немного, поэтому файл целиком перезаписывается при каждом изменении
+// через временный файл и переименование
+func NewProjectRepo(path string) (*projectRepo, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create projects dir: %w", err)
+	}
+
+	r := &projectRepo{
+		projects: make(map[int]*model.Project),
+		mu:       &sync.RWMutex{},
+		path:     path,
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return r, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read projects file: %w", err)
+	}
+
+	var file projectsFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal projects file: %w", err)
+	}
+
+	for _, project := range file.Projects {
+		r.projects[project.ID] = project
+	}
+
+	r.idCounter = file.IDCounter
+
+	return r, nil
+}
+
+// CreateProject создает новый проект в хранилище
+func (r *projectRepo) CreateProject(ctx context.Context, project *model.Project) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored := storedProject(project)
+	stored.ID = r.idCounter + 1
+	stored.Version = 1
+
+	r.projects[stored.ID] = stored
+	if err := r.save(stored.ID); err != nil {
+		delete(r.projects, stored.ID)
+		return 0, err
+	}
+
+	r.idCounter = stored.ID
+	project.ID = stored.ID
+	project.Version = stored.Version
+
+	return project.ID, nil
+}
+
+// GetProjects возвращает все проекты по возрастанию ID
+func (r *projectRepo) GetProjects(ctx context.Context) ([]*model.Project, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.sorted(), nil
+}
+
+// GetProjectByID возвращает проект по ID из хранилища
+func (r *projectRepo) GetProjectByID(ctx context.Context, id int) (*model.Project, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	project, ok := r.projects[id]
+	if !ok {
+		return nil, usecase.NewProjectNotFoundError(id)
+	}
+
+	return project.Clone(), nil
+}
+
+// UpdateProject обновляет проект, если project.Version 0 или совпадает с текущей версией
+func (r *projectRepo) UpdateProject(ctx context.Context, project *model.Project) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, ok := r.projects[project.ID]
+	if !ok {
+		return usecase.NewProjectNotFoundError(project.ID)
+	}
+
+	if project.Version != 0 && project.Version != current.Version {
+		return usecase.ErrProjectVersionConflict
+	}
+
+	stored := storedProject(project)
+	stored.Version = current.Version + 1
+
+	r.projects[project.ID] = stored
+	if err := r.save(r.idCounter); err != nil {
+		r.projects[project.ID] = current
+		return err
+	}
+
+	project.Version = stored.Version
+
+	return nil
+}
+
+// DeleteProject удаляет проект, если version 0 или совпадает с текущей версией
+func (r *projectRepo) DeleteProject(ctx context.Context, id int, version int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, ok := r.projects[id]
+	if !ok {
+		return usecase.NewProjectNotFoundError(id)
+	}
+
+	if version != 0 && version != current.Version {
+		return usecase.ErrProjectVersionConflict
+	}
+
+	delete(r.projects, id)
+	if err := r.save(r.idCounter); err != nil {
+		r.projects[id] = current
+		return err
+	}
+
+	return nil
+}
+
+// Close ничего не держит открытым и нужен для единообразия с другими хранилищами
+func (r *projectRepo) Close() error {
+	return nil
+}
+
+// save перезаписывает файл проектов текущим состоянием со счетчиком ID idCounter
+func (r *projectRepo) save(idCounter int) error {
+	data, err := json.Marshal(projectsFile{IDCounter: idCounter, Projects: r.sorted()})
+	if err != nil {
+		return fmt.Errorf("failed to marshal projects: %w", err)
+	}
+
+	tmpPath := r.path + ".tmp"
+	if err := fsync.WriteFile(tmpPath, data); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmpPath, r.path); err != nil {
+		return fmt.Errorf("failed to rename projects file: %w", err)
+	}
+
+	return fsync.Dir(filepath.Dir(r.path))
+}
+
+func (r *projectRepo) sorted() []*model.Project {
+	projects := make([]*model.Project, 0, len(r.projects))
+	for _, project := range r.projects {
+		projects = append(projects, project.Clone())
+	}
+
+	sort.Slice(projects, func(i, j int) bool {
+		return projects[i].ID < projects[j].ID
+	})
+
+	return projects
+}
+
+// storedProject возвращает копию проекта для записи в хранилище без вычисляемых полей
+func storedProject(project *model.Project) *model.Project {
+	clone := project.Clone()
+	clone.Counts = model.ProjectCounts{}
+
+	return clone
+}
+
+// DumpProjects возвращает копии всех проектов по возрастанию ID и ID следующего
+// проекта на один момент времени
+func (r *projectRepo) DumpProjects(ctx context.Context) ([]*model.Project, int, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.sorted(), r.idCounter + 1, nil
+}
+
+// ReplaceProjects заменяет все проекты на projects одной перезаписью файла.
+// Счетчик ID не уменьшается, а следующий проект получит ID не меньше nextID
+func (r *projectRepo) ReplaceProjects(ctx context.Context, projects []*model.Project, nextID int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current := r.projects
+
+	r.projects = make(map[int]*model.Project, len(projects))
+	for _, project := range projects {
+		r.projects[project.ID] = storedProject(project)
+	}
+
+	idCounter := max(r.idCounter, nextID-1)
+	if err := r.save(idCounter); err != nil {
+		r.projects = current
+		return err
+	}
+
+	r.idCounter = idCounter
+
+	return nil
+}
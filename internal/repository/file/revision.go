@@ -0,0 +1,195 @@
+package file
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/solumD/tasks-service/internal/model"
+	"github.com/solumD/tasks-service/internal/usecase"
+)
+
+// RevisionsFileName имя файла ревизий по умолчанию
+const RevisionsFileName = "revisions.log"
+
+type revisionRepo struct {
+	revisions map[int][]*model.Revision
+
+	mu   *sync.RWMutex
+	log  *os.File
+	size int64
+}
+
+// NewRevisionRepo открывает журнал ревизий path и загружает его в память.
+// Ревизии неизменяемы, поэтому журнал только дописывается и не сжимается.
+// Недописанная последняя строка без перевода строки (сбой посреди записи) отбрасывается,
+// испорченная запись в другом месте журнала - ошибка
+func NewRevisionRepo(path string) (*revisionRepo, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create revisions dir: %w", err)
+	}
+
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open revisions log: %w", err)
+	}
+
+	r := &revisionRepo{
+		revisions: make(map[int][]*model.Revision),
+		mu:        &sync.RWMutex{},
+	}
+
+	reader := bufio.NewReader(f)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			f.Close()
+			return nil, fmt.Errorf("failed to read revisions log: %w", err)
+		}
+
+		var rev model.Revision
+		if err := json.Unmarshal(bytes.TrimSpace(line), &rev); err != nil {
+			f.Close()
+			return nil, fmt.Errorf("corrupt revisions log record at offset %d: %w", r.size, err)
+		}
+
+		r.insert(&rev)
+		r.size += int64(len(line))
+	}
+
+	if err := f.Truncate(r.size); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to truncate revisions log: %w", err)
+	}
+
+	if _, err := f.Seek(r.size, io.SeekStart); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to seek revisions log: %w", err)
+	}
+
+	r.log = f
+
+	return r, nil
+}
+
+// AddRevision дописывает ревизию в журнал, если ревизии с тем же номером у задачи еще нет
+func (r *revisionRepo) AddRevision(_ context.Context, rev *model.Revision) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.log == nil {
+		return errors.New("revisions log is closed")
+	}
+
+	if _, ok := r.find(rev.TaskID, rev.Rev); ok {
+		return nil
+	}
+
+	line, err := json.Marshal(rev)
+	if err != nil {
+		return fmt.Errorf("failed to marshal revision: %w", err)
+	}
+
+	line = append(line, '\n')
+
+	if _, err := r.log.Write(line); err != nil {
+		r.rollback()
+		return fmt.Errorf("failed to write revision: %w", err)
+	}
+
+	if err := r.log.Sync(); err != nil {
+		r.rollback()
+		return fmt.Errorf("failed to sync revisions log: %w", err)
+	}
+
+	r.size += int64(len(line))
+
+	r.insert(rev.Clone())
+
+	return nil
+}
+
+// GetRevisions возвращает ревизии задачи по возрастанию номера
+func (r *revisionRepo) GetRevisions(_ context.Context, taskID int) ([]*model.Revision, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	revisions := make([]*model.Revision, 0, len(r.revisions[taskID]))
+
+	for _, rev := range r.revisions[taskID] {
+		revisions = append(revisions, rev.Clone())
+	}
+
+	return revisions, nil
+}
+
+// GetRevision возвращает ревизию rev задачи
+func (r *revisionRepo) GetRevision(_ context.Context, taskID int, rev int) (*model.Revision, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	i, ok := r.find(taskID, rev)
+	if !ok {
+		return nil, usecase.ErrRevisionNotFound
+	}
+
+	return r.revisions[taskID][i].Clone(), nil
+}
+
+// Close закрывает журнал ревизий
+func (r *revisionRepo) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.log == nil {
+		return nil
+	}
+
+	err := r.log.Close()
+	r.log = nil
+
+	return err
+}
+
+// find ищет позицию ревизии rev в отсортированном списке ревизий задачи
+func (r *revisionRepo) find(taskID int, rev int) (int, bool) {
+	revisions := r.revisions[taskID]
+
+	i := sort.Search(len(revisions), func(i int) bool {
+		return revisions[i].Rev >= rev
+	})
+
+	return i, i < len(revisions) && revisions[i].Rev == rev
+}
+
+// insert вставляет ревизию с сохранением порядка, дубликаты пропускаются
+func (r *revisionRepo) insert(rev *model.Revision) {
+	i, ok := r.find(rev.TaskID, rev.Rev)
+	if ok {
+		return
+	}
+
+	revisions := append(r.revisions[rev.TaskID], nil)
+	copy(revisions[i+1:], revisions[i:])
+	revisions[i] = rev
+
+	r.revisions[rev.TaskID] = revisions
+}
+
+func (r *revisionRepo) rollback() {
+	if err := r.log.Truncate(r.size); err == nil {
+		r.log.Seek(r.size, io.SeekStart)
+	}
+}
@@ -0,0 +1,714 @@
+package file
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/solumD/tasks-service/internal/fsync"
+	"github.com/solumD/tasks-service/internal/model"
+	"github.com/solumD/tasks-service/internal/repository/index"
+	"github.com/solumD/tasks-service/internal/usecase"
+)
+
+const (
+	walFileName      = "wal.log"
+	snapshotFileName = "snapshot.json"
+	snapshotTmpName  = "snapshot.json.tmp"
+
+	opCreate  = "create"
+	opUpdate  = "update"
+	opTrash   = "trash"
+	opRestore = "restore"
+	opDelete  = "delete"
+	// opImport задача перенесена из другого хранилища как есть
+	opImport = "import"
+	// opReserve счетчик ID поднят до ID записи
+	opReserve = "reserve"
+	// opReplace все задачи заменены на Tasks, счетчик ID поднят до ID записи
+	opReplace = "replace"
+	// opRetag метки задач Tasks изменены одной записью
+	opRetag = "retag"
+)
+
+// walRecord запись журнала упреждающей записи
+type walRecord struct {
+	Op    string        `json:"op"`
+	ID    int           `json:"id"`
+	Task  *model.Task   `json:"task,omitempty"`
+	Tasks []*model.Task `json:"tasks,omitempty"`
+}
+
+// snapshot снимок состояния хранилища. Задачи из корзины хранятся
+// вместе с остальными и отличаются заполненным DeletedAt
+type snapshot struct {
+	IDCounter int           `json:"id_counter"`
+	Tasks     []*model.Task `json:"tasks"`
+}
+
+type taskRepo struct {
+	tasks map[int]*model.Task
+	trash map[int]*model.Task
+	// due, tags и children индексы сроков, меток и подзадач задач не из корзины,
+	// строятся при загрузке
+	due      *index.Due
+	tags     *index.Tags
+	children *index.Children
+
+	mu        *sync.RWMutex
+	idCounter int
+
+	dir          string
+	wal          *os.File
+	walSize      int64
+	walRecords   int
+	compactEvery int
+}
+
+// NewTaskRepo открывает файловое хранилище в директории dir и восстанавливает
+// его состояние из снапшота и журнала. Снапшот делается после каждых
+// compactEvery записей в журнал
+func NewTaskRepo(dir string, compactEvery int) (*taskRepo, error) {
+	if compactEvery <= 0 {
+		return nil, fmt.Errorf("compact threshold must be positive, got %d", compactEvery)
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create storage dir: %w", err)
+	}
+
+	r := &taskRepo{
+		tasks:        make(map[int]*model.Task),
+		trash:        make(map[int]*model.Task),
+		due:          index.NewDue(),
+		tags:         index.NewTags(),
+		children:     index.NewChildren(),
+		mu:           &sync.RWMutex{},
+		dir:          dir,
+		compactEvery: compactEvery,
+	}
+
+	if err := r.loadSnapshot(); err != nil {
+		return nil, err
+	}
+
+	if err := r.replayWAL(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// CreateTask создает новую задачу в хранилище
+func (r *taskRepo) CreateTask(ctx context.Context, task *model.Task) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	id := r.idCounter + 1
+
+	stored := *task
+	stored.ID = id
+	stored.Version = 1
+
+	if err := r.appendRecords(walRecord{Op: opCreate, ID: id, Task: &stored}); err != nil {
+		return 0, err
+	}
+
+	r.idCounter = id
+	task.ID = id
+	task.Version = stored.Version
+	r.put(task)
+
+	r.compactIfNeeded()
+
+	return id, nil
+}
+
+// GetAllTasks возвращает все задачи из хранилища
+func (r *taskRepo) GetAllTasks(ctx context.Context) ([]*model.Task, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tasks := make([]*model.Task, 0, len(r.tasks))
+
+	for _, task := range r.tasks {
+		tasks = append(tasks, task)
+	}
+
+	return tasks, nil
+}
+
+// GetTaskByID возвращает задачу по ID из хранилища
+func (r *taskRepo) GetTaskByID(ctx context.Context, id int) (*model.Task, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	task, ok := r.tasks[id]
+	if !ok {
+		return nil, usecase.NewTaskNotFoundError(id)
+	}
+
+	return task, nil
+}
+
+// GetTasksByDue возвращает задачи со сроком выполнения в диапазоне [from, to) по индексу сроков
+func (r *taskRepo) GetTasksByDue(ctx context.Context, from, to time.Time) ([]*model.Task, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	ids := r.due.Range(from, to)
+	tasks := make([]*model.Task, 0, len(ids))
+
+	for _, id := range ids {
+		tasks = append(tasks, r.tasks[id])
+	}
+
+	return tasks, nil
+}
+
+// GetTasksByTags возвращает задачи хотя бы с одной из меток tags, а при all - со всеми, по индексу меток
+func (r *taskRepo) GetTasksByTags(ctx context.Context, tags []string, all bool) ([]*model.Task, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	ids := r.tags.Match(tags, all)
+	tasks := make([]*model.Task, 0, len(ids))
+
+	for _, id := range ids {
+		tasks = append(tasks, r.tasks[id])
+	}
+
+	return tasks, nil
+}
+
+// GetTagCounts возвращает метки задач с количеством задач у каждой
+func (r *taskRepo) GetTagCounts(ctx context.Context) ([]model.TagCount, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.tags.Counts(), nil
+}
+
+// GetTasksByParent возвращает подзадачи задачи parentID по индексу подзадач
+func (r *taskRepo) GetTasksByParent(ctx context.Context, parentID int) ([]*model.Task, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	ids := r.children.IDs(parentID)
+	tasks := make([]*model.Task, 0, len(ids))
+
+	for _, id := range ids {
+		tasks = append(tasks, r.tasks[id])
+	}
+
+	return tasks, nil
+}
+
+// RenameTag заменяет метку from на to у всех задач. Измененные задачи пишутся
+// в журнал одной записью, поэтому после сбоя переименование не окажется частичным
+func (r *taskRepo) RenameTag(ctx context.Context, from, to string) ([]*model.Task, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	ids := r.tags.IDs(from)
+	if len(ids) == 0 {
+		return []*model.Task{}, nil
+	}
+
+	renamed := make([]*model.Task, 0, len(ids))
+
+	for _, id := range ids {
+		// выданные читателям задачи не меняются, переименовываются копии
+		task := r.tasks[id].Clone()
+		task.RenameTag(from, to)
+		task.Version++
+
+		renamed = append(renamed, task)
+	}
+
+	if err := r.appendRecords(walRecord{Op: opRetag, Tasks: renamed}); err != nil {
+		return nil, err
+	}
+
+	for _, task := range renamed {
+		r.put(task)
+	}
+
+	r.compactIfNeeded()
+
+	return renamed, nil
+}
+
+// UpdateTask обновляет задачу в хранилище, если ее версия совпадает с task.Version
+func (r *taskRepo) UpdateTask(ctx context.Context, task *model.Task) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, ok := r.tasks[task.ID]
+	if !ok {
+		return usecase.NewTaskNotFoundError(task.ID)
+	}
+
+	if task.Version != 0 && task.Version != current.Version {
+		return usecase.ErrVersionConflict
+	}
+
+	task.CreatedAt = current.CreatedAt
+
+	stored := *task
+	stored.Version = current.Version + 1
+
+	if err := r.appendRecords(walRecord{Op: opUpdate, ID: task.ID, Task: &stored}); err != nil {
+		return err
+	}
+
+	task.Version = stored.Version
+	r.put(task)
+	r.compactIfNeeded()
+
+	return nil
+}
+
+// DeleteTask перемещает задачу в корзину, если ее версия совпадает с version,
+// и возвращает ее
+func (r *taskRepo) DeleteTask(ctx context.Context, id int, version int) (*model.Task, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, ok := r.tasks[id]
+	if !ok {
+		return nil, usecase.NewTaskNotFoundError(id)
+	}
+
+	if version != 0 && version != current.Version {
+		return nil, usecase.ErrVersionConflict
+	}
+
+	deletedAt := time.Now().UTC()
+
+	trashed := *current
+	trashed.Version++
+	trashed.DeletedAt = &deletedAt
+
+	if err := r.appendRecords(walRecord{Op: opTrash, ID: id, Task: &trashed}); err != nil {
+		return nil, err
+	}
+
+	r.put(&trashed)
+	r.compactIfNeeded()
+
+	return &trashed, nil
+}
+
+// GetDeletedTasks возвращает все задачи из корзины
+func (r *taskRepo) GetDeletedTasks(ctx context.Context) ([]*model.Task, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tasks := make([]*model.Task, 0, len(r.trash))
+
+	for _, task := range r.trash {
+		tasks = append(tasks, task)
+	}
+
+	return tasks, nil
+}
+
+// RestoreTask возвращает задачу из корзины
+func (r *taskRepo) RestoreTask(ctx context.Context, id int) (*model.Task, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	trashed, ok := r.trash[id]
+	if !ok {
+		return nil, usecase.NewTaskNotFoundError(id)
+	}
+
+	restored := *trashed
+	restored.Version++
+	restored.DeletedAt = nil
+
+	if err := r.appendRecords(walRecord{Op: opRestore, ID: id, Task: &restored}); err != nil {
+		return nil, err
+	}
+
+	r.put(&restored)
+	r.compactIfNeeded()
+
+	return &restored, nil
+}
+
+// PurgeDeletedTasks окончательно удаляет задачи, попавшие в корзину раньше deletedBefore
+func (r *taskRepo) PurgeDeletedTasks(ctx context.Context, deletedBefore time.Time) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	records := make([]walRecord, 0)
+
+	for id, task := range r.trash {
+		if task.DeletedAt.Before(deletedBefore) {
+			records = append(records, walRecord{Op: opDelete, ID: id})
+		}
+	}
+
+	if len(records) == 0 {
+		return 0, nil
+	}
+
+	if err := r.appendRecords(records...); err != nil {
+		return 0, err
+	}
+
+	for _, rec := range records {
+		delete(r.trash, rec.ID)
+	}
+
+	r.compactIfNeeded()
+
+	return len(records), nil
+}
+
+// Close делает финальный снапшот и закрывает журнал
+func (r *taskRepo) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.wal == nil {
+		return nil
+	}
+
+	var err error
+	if r.walRecords > 0 {
+		err = r.compact()
+	}
+
+	if errClose := r.wal.Close(); errClose != nil && err == nil {
+		err = fmt.Errorf("failed to close wal: %w", errClose)
+	}
+
+	r.wal = nil
+
+	return err
+}
+
+// appendRecords дописывает записи в журнал и дожидается их сброса на диск.
+// Вызывается под блокировкой на запись
+func (r *taskRepo) appendRecords(recs ...walRecord) error {
+	if r.wal == nil {
+		return errors.New("file storage is closed")
+	}
+
+	var buf bytes.Buffer
+	for _, rec := range recs {
+		line, err := json.Marshal(rec)
+		if err != nil {
+			return fmt.Errorf("failed to marshal wal record: %w", err)
+		}
+
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+
+	line := buf.Bytes()
+
+	if _, err := r.wal.Write(line); err != nil {
+		r.rollbackWAL()
+		return fmt.Errorf("failed to write wal record: %w", err)
+	}
+
+	if err := r.wal.Sync(); err != nil {
+		r.rollbackWAL()
+		return fmt.Errorf("failed to sync wal: %w", err)
+	}
+
+	r.walSize += int64(len(line))
+	r.walRecords += len(recs)
+
+	return nil
+}
+
+// rollbackWAL отрезает недописанную запись, чтобы следующие записи
+// не оказались в журнале после мусора
+func (r *taskRepo) rollbackWAL() {
+	if err := r.wal.Truncate(r.walSize); err == nil {
+		r.wal.Seek(r.walSize, io.SeekStart)
+	}
+}
+
+// apply применяет запись журнала к состоянию в памяти
+func (r *taskRepo) apply(rec walRecord) error {
+	switch rec.Op {
+	case opCreate, opUpdate, opTrash, opRestore, opImport:
+		if rec.Task == nil {
+			return fmt.Errorf("wal record %q for task %d has no task", rec.Op, rec.ID)
+		}
+
+		r.put(rec.Task)
+		if rec.ID > r.idCounter {
+			r.idCounter = rec.ID
+		}
+	case opDelete:
+		delete(r.tasks, rec.ID)
+		delete(r.trash, rec.ID)
+		r.due.Remove(rec.ID)
+		r.tags.Remove(rec.ID)
+		r.children.Remove(rec.ID)
+	case opReserve:
+		if rec.ID > r.idCounter {
+			r.idCounter = rec.ID
+		}
+	case opReplace:
+		r.tasks = make(map[int]*model.Task, len(rec.Tasks))
+		r.trash = make(map[int]*model.Task)
+		r.due = index.NewDue()
+		r.tags = index.NewTags()
+		r.children = index.NewChildren()
+
+		for _, task := range rec.Tasks {
+			r.put(task)
+			if task.ID > r.idCounter {
+				r.idCounter = task.ID
+			}
+		}
+
+		if rec.ID > r.idCounter {
+			r.idCounter = rec.ID
+		}
+	case opRetag:
+		for _, task := range rec.Tasks {
+			r.put(task)
+		}
+	default:
+		return fmt.Errorf("unknown wal record op %q", rec.Op)
+	}
+
+	return nil
+}
+
+// put кладет задачу в список задач или в корзину в зависимости от DeletedAt
+// и обновляет индексы сроков, меток и подзадач
+func (r *taskRepo) put(task *model.Task) {
+	if task.DeletedAt != nil {
+		delete(r.tasks, task.ID)
+		r.due.Remove(task.ID)
+		r.tags.Remove(task.ID)
+		r.children.Remove(task.ID)
+		r.trash[task.ID] = task
+
+		return
+	}
+
+	delete(r.trash, task.ID)
+	r.tasks[task.ID] = task
+	r.due.Put(task.ID, task.DueAt)
+	r.tags.Put(task.ID, task.Tags)
+	r.children.Put(task.ID, task.ParentID)
+}
+
+// compactIfNeeded делает снапшот, если журнал дорос до порога. Ошибка
+// снапшота не делает операцию неуспешной: запись уже сброшена в журнал,
+// а снапшот будет повторен при следующей записи или при закрытии
+func (r *taskRepo) compactIfNeeded() {
+	if r.walRecords < r.compactEvery {
+		return
+	}
+
+	_ = r.compact()
+}
+
+// compact записывает снапшот текущего состояния и очищает журнал.
+// Снапшот сначала пишется во временный файл и атомарно переименовывается,
+// поэтому при сбое на диске всегда остается целый снапшот
+func (r *taskRepo) compact() error {
+	snap := snapshot{
+		IDCounter: r.idCounter,
+		Tasks:     make([]*model.Task, 0, len(r.tasks)+len(r.trash)),
+	}
+
+	for _, task := range r.tasks {
+		snap.Tasks = append(snap.Tasks, task)
+	}
+
+	for _, task := range r.trash {
+		snap.Tasks = append(snap.Tasks, task)
+	}
+
+	sort.Slice(snap.Tasks, func(i, j int) bool {
+		return snap.Tasks[i].ID < snap.Tasks[j].ID
+	})
+
+	data, err := json.Marshal(snap)
+	if err != nil {
+		return fmt.Errorf("failed to marshal snapshot: %w", err)
+	}
+
+	tmpPath := filepath.Join(r.dir, snapshotTmpName)
+	if err := fsync.WriteFile(tmpPath, data); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmpPath, filepath.Join(r.dir, snapshotFileName)); err != nil {
+		return fmt.Errorf("failed to rename snapshot: %w", err)
+	}
+
+	if err := fsync.Dir(r.dir); err != nil {
+		return err
+	}
+
+	if err := r.wal.Truncate(0); err != nil {
+		return fmt.Errorf("failed to truncate wal: %w", err)
+	}
+
+	if _, err := r.wal.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to seek wal: %w", err)
+	}
+
+	if err := r.wal.Sync(); err != nil {
+		return fmt.Errorf("failed to sync wal: %w", err)
+	}
+
+	r.walSize = 0
+	r.walRecords = 0
+
+	return nil
+}
+
+func (r *taskRepo) loadSnapshot() error {
+	data, err := os.ReadFile(filepath.Join(r.dir, snapshotFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to read snapshot: %w", err)
+	}
+
+	var snap snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return fmt.Errorf("failed to unmarshal snapshot: %w", err)
+	}
+
+	for _, task := range snap.Tasks {
+		r.put(task)
+	}
+
+	r.idCounter = snap.IDCounter
+
+	return nil
+}
+
+// replayWAL применяет журнал поверх снапшота и открывает его на дозапись.
+// Недописанная последняя строка без перевода строки (сбой посреди записи) отбрасывается,
+// а испорченная запись в любом другом месте - ошибка: отбросить ее вместе со всеми
+// следующими записями значило бы молча потерять сохраненные изменения
+func (r *taskRepo) replayWAL() error {
+	wal, err := os.OpenFile(filepath.Join(r.dir, walFileName), os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open wal: %w", err)
+	}
+
+	var valid int64
+
+	reader := bufio.NewReader(wal)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			wal.Close()
+			return fmt.Errorf("failed to read wal: %w", err)
+		}
+
+		var rec walRecord
+		if err := json.Unmarshal(bytes.TrimSpace(line), &rec); err != nil {
+			wal.Close()
+			return fmt.Errorf("corrupt wal record at offset %d: %w", valid, err)
+		}
+
+		if err := r.apply(rec); err != nil {
+			wal.Close()
+			return err
+		}
+
+		valid += int64(len(line))
+		r.walRecords++
+	}
+
+	if err := wal.Truncate(valid); err != nil {
+		wal.Close()
+		return fmt.Errorf("failed to truncate wal: %w", err)
+	}
+
+	if _, err := wal.Seek(valid, io.SeekStart); err != nil {
+		wal.Close()
+		return fmt.Errorf("failed to seek wal: %w", err)
+	}
+
+	r.wal = wal
+	r.walSize = valid
+
+	return nil
+}
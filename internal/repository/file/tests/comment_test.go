@@ -0,0 +1,127 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/solumD/tasks-service/internal/model"
+	filerepo "github.com/solumD/tasks-service/internal/repository/file"
+	"github.com/solumD/tasks-service/internal/usecase"
+)
+
+func TestCommentsAfterRestart(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), filerepo.CommentsFileName)
+
+	repo, err := filerepo.NewCommentRepo(path)
+	if err != nil {
+		t.Fatalf("failed to open repo: %v", err)
+	}
+
+	createdAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	// 1 и 3 - комментарии задачи 7, 2 - ответ на 1, 4 - ответ на 3
+	for _, comment := range []*model.Comment{
+		{TaskID: 7, Author: "alice", Text: "first", CreatedAt: createdAt},
+		{TaskID: 7, ParentID: 1, Author: "bob", Text: "reply", CreatedAt: createdAt},
+		{TaskID: 7, Author: "alice", Text: "second", CreatedAt: createdAt},
+		{TaskID: 7, ParentID: 3, Author: "bob", Text: "reply", CreatedAt: createdAt},
+	} {
+		if _, err := repo.CreateComment(ctx, comment); err != nil {
+			t.Fatalf("failed to create comment: %v", err)
+		}
+	}
+
+	editedAt := createdAt.Add(time.Hour)
+	if err := repo.UpdateComment(ctx, &model.Comment{ID: 1, Text: "edited", EditedAt: &editedAt}); err != nil {
+		t.Fatalf("failed to update comment: %v", err)
+	}
+
+	if _, err := repo.DeleteComment(ctx, 3); err != nil {
+		t.Fatalf("failed to delete comment: %v", err)
+	}
+
+	repo.Close()
+
+	// недописанная запись отбрасывается при открытии
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		t.Fatalf("failed to open log: %v", err)
+	}
+	f.WriteString(`{"comment":{"id":5`)
+	f.Close()
+
+	reopened, err := filerepo.NewCommentRepo(path)
+	if err != nil {
+		t.Fatalf("failed to reopen repo: %v", err)
+	}
+	defer reopened.Close()
+
+	comments, _ := reopened.GetComments(ctx, 7, 0, 10)
+	if len(comments) != 1 || comments[0].Text != "edited" || comments[0].EditedAt == nil || !comments[0].EditedAt.Equal(editedAt) {
+		t.Fatalf("unexpected comments after restart: %+v", comments)
+	}
+
+	replies, _ := reopened.GetReplies(ctx, []int{1, 3})
+	if len(replies) != 1 || replies[0].ID != 2 {
+		t.Fatalf("expected only reply 2 after restart, got %+v", replies)
+	}
+
+	if _, err := reopened.GetCommentByID(ctx, 4); !errors.Is(err, usecase.ErrCommentNotFound) {
+		t.Fatalf("expected deleted reply not found, got %v", err)
+	}
+
+	id, err := reopened.CreateComment(ctx, &model.Comment{TaskID: 7, Author: "alice", Text: "third", CreatedAt: createdAt})
+	if err != nil {
+		t.Fatalf("failed to create comment: %v", err)
+	}
+
+	if id != 5 {
+		t.Fatalf("expected id 5 after restart, got %d", id)
+	}
+}
+
+func TestCommentRepoFailsOnCorruptRecord(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), filerepo.CommentsFileName)
+
+	repo, err := filerepo.NewCommentRepo(path)
+	if err != nil {
+		t.Fatalf("failed to open repo: %v", err)
+	}
+
+	comment := &model.Comment{TaskID: 7, Author: "alice", Text: "first", CreatedAt: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)}
+	if _, err := repo.CreateComment(ctx, comment); err != nil {
+		t.Fatalf("failed to create comment: %v", err)
+	}
+
+	repo.Close()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read log: %v", err)
+	}
+
+	// испорченная запись перед целой
+	corrupted := append([]byte("garbage\n"), data...)
+	if err := os.WriteFile(path, corrupted, 0o644); err != nil {
+		t.Fatalf("failed to write log: %v", err)
+	}
+
+	if _, err := filerepo.NewCommentRepo(path); err == nil {
+		t.Fatal("expected error on corrupt record")
+	}
+
+	after, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read log: %v", err)
+	}
+
+	if string(after) != string(corrupted) {
+		t.Fatal("expected corrupt log to stay untouched")
+	}
+}
@@ -0,0 +1,46 @@
+package tests
+
+import (
+	"path/filepath"
+	"testing"
+
+	filerepo "github.com/solumD/tasks-service/internal/repository/file"
+	"github.com/solumD/tasks-service/internal/repository/repotest"
+	"github.com/solumD/tasks-service/internal/usecase"
+)
+
+func TestConformance(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) usecase.TaskRepo {
+		repo, err := filerepo.NewTaskRepo(t.TempDir(), 1000)
+		if err != nil {
+			t.Fatalf("failed to open repo: %v", err)
+		}
+		t.Cleanup(func() { repo.Close() })
+
+		return repo
+	})
+}
+
+func TestProjectConformance(t *testing.T) {
+	repotest.RunProjects(t, func(t *testing.T) usecase.ProjectRepo {
+		repo, err := filerepo.NewProjectRepo(filepath.Join(t.TempDir(), filerepo.ProjectsFileName))
+		if err != nil {
+			t.Fatalf("failed to open repo: %v", err)
+		}
+		t.Cleanup(func() { repo.Close() })
+
+		return repo
+	})
+}
+
+func TestCommentConformance(t *testing.T) {
+	repotest.RunComments(t, func(t *testing.T) usecase.CommentRepo {
+		repo, err := filerepo.NewCommentRepo(filepath.Join(t.TempDir(), filerepo.CommentsFileName))
+		if err != nil {
+			t.Fatalf("failed to open repo: %v", err)
+		}
+		t.Cleanup(func() { repo.Close() })
+
+		return repo
+	})
+}
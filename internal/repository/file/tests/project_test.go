@@ -0,0 +1,62 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/solumD/tasks-service/internal/model"
+	filerepo "github.com/solumD/tasks-service/internal/repository/file"
+	"github.com/solumD/tasks-service/internal/usecase"
+)
+
+func TestProjectsAfterRestart(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), filerepo.ProjectsFileName)
+
+	repo, err := filerepo.NewProjectRepo(path)
+	if err != nil {
+		t.Fatalf("failed to open repo: %v", err)
+	}
+
+	for _, name := range []string{"Home", "Work", "Garden"} {
+		if _, err := repo.CreateProject(ctx, &model.Project{Name: name}); err != nil {
+			t.Fatalf("failed to create project: %v", err)
+		}
+	}
+
+	if err := repo.UpdateProject(ctx, &model.Project{ID: 1, Name: "House"}); err != nil {
+		t.Fatalf("failed to update project: %v", err)
+	}
+
+	if err := repo.DeleteProject(ctx, 3, 0); err != nil {
+		t.Fatalf("failed to delete project: %v", err)
+	}
+
+	repo.Close()
+
+	reopened, err := filerepo.NewProjectRepo(path)
+	if err != nil {
+		t.Fatalf("failed to reopen repo: %v", err)
+	}
+	defer reopened.Close()
+
+	projects, _ := reopened.GetProjects(ctx)
+	if len(projects) != 2 || projects[0].Name != "House" || projects[0].Version != 2 || projects[1].Name != "Work" {
+		t.Fatalf("unexpected projects after restart: %+v", projects)
+	}
+
+	if _, err := reopened.GetProjectByID(ctx, 3); !errors.Is(err, usecase.ErrProjectNotFound) {
+		t.Fatalf("expected deleted project not found, got %v", err)
+	}
+
+	id, err := reopened.CreateProject(ctx, &model.Project{Name: "Garage"})
+	if err != nil {
+		t.Fatalf("failed to create project: %v", err)
+	}
+
+	if id != 4 {
+		t.Fatalf("expected id 4 after restart, got %d", id)
+	}
+}
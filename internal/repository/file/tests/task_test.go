@@ -0,0 +1,340 @@
+package tests
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/solumD/tasks-service/internal/model"
+	filerepo "github.com/solumD/tasks-service/internal/repository/file"
+)
+
+func TestReplayAfterRestart(t *testing.T) {
+	tests := []struct {
+		name         string
+		compactEvery int
+	}{
+		{name: "wal only", compactEvery: 1000},
+		{name: "snapshot and wal", compactEvery: 2},
+		{name: "snapshot only", compactEvery: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			dir := t.TempDir()
+
+			repo, err := filerepo.NewTaskRepo(dir, tt.compactEvery)
+			if err != nil {
+				t.Fatalf("failed to open repo: %v", err)
+			}
+
+			for _, title := range []string{"Task1", "Task2", "Task3"} {
+				if _, err := repo.CreateTask(ctx, &model.Task{Title: title}); err != nil {
+					t.Fatalf("failed to create task: %v", err)
+				}
+			}
+
+			dueAt := time.Date(2026, time.March, 1, 18, 0, 0, 0, time.FixedZone("", 3*60*60))
+			if err := repo.UpdateTask(ctx, &model.Task{ID: 2, Title: "Task2 updated", Done: true, DueAt: &dueAt}); err != nil {
+				t.Fatalf("failed to update task: %v", err)
+			}
+
+			if err := repo.UpdateTask(ctx, &model.Task{ID: 1, Title: "Task1", Tags: []string{"home"}}); err != nil {
+				t.Fatalf("failed to update task: %v", err)
+			}
+
+			if _, err := repo.RenameTag(ctx, "home", "house"); err != nil {
+				t.Fatalf("failed to rename tag: %v", err)
+			}
+
+			if _, err := repo.DeleteTask(ctx, 3, 0); err != nil {
+				t.Fatalf("failed to delete task: %v", err)
+			}
+
+			// repo не закрывается и не делает финальный снапшот, как при падении процесса
+			reopened, err := filerepo.NewTaskRepo(dir, tt.compactEvery)
+			if err != nil {
+				t.Fatalf("failed to reopen repo: %v", err)
+			}
+
+			tasks, _ := reopened.GetAllTasks(ctx)
+			if len(tasks) != 2 {
+				t.Fatalf("expected 2 tasks after replay, got %d", len(tasks))
+			}
+
+			task, _ := reopened.GetTaskByID(ctx, 2)
+			if task == nil || task.Title != "Task2 updated" || !task.Done {
+				t.Fatalf("expected updated task 2, got %v", task)
+			}
+
+			due, _ := reopened.GetTasksByDue(ctx, dueAt, time.Time{})
+			if len(due) != 1 || due[0].ID != 2 || due[0].DueAt.Format(time.RFC3339) != dueAt.Format(time.RFC3339) {
+				t.Fatalf("expected due index to be restored with task 2, got %v", due)
+			}
+
+			tagged, _ := reopened.GetTasksByTags(ctx, []string{"house"}, false)
+			if len(tagged) != 1 || tagged[0].ID != 1 || tagged[0].Version != 3 {
+				t.Fatalf("expected renamed tag to be restored with task 1 at version 3, got %v", tagged)
+			}
+
+			id, err := reopened.CreateTask(ctx, &model.Task{Title: "Task4"})
+			if err != nil {
+				t.Fatalf("failed to create task: %v", err)
+			}
+
+			if id != 4 {
+				t.Fatalf("expected id counter to be restored and next id = 4, got %d", id)
+			}
+
+			reopened.Close()
+		})
+	}
+}
+
+func TestReplayIgnoresTornTail(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	repo, err := filerepo.NewTaskRepo(dir, 1000)
+	if err != nil {
+		t.Fatalf("failed to open repo: %v", err)
+	}
+
+	if _, err := repo.CreateTask(ctx, &model.Task{Title: "Task1"}); err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+
+	f, err := os.OpenFile(filepath.Join(dir, "wal.log"), os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		t.Fatalf("failed to open wal: %v", err)
+	}
+	f.WriteString(`{"op":"create","id":2,"task":{"ID":2,"Ti`)
+	f.Close()
+
+	reopened, err := filerepo.NewTaskRepo(dir, 1000)
+	if err != nil {
+		t.Fatalf("failed to reopen repo: %v", err)
+	}
+	defer reopened.Close()
+
+	tasks, _ := reopened.GetAllTasks(ctx)
+	if len(tasks) != 1 {
+		t.Fatalf("expected torn record to be dropped, got %d tasks", len(tasks))
+	}
+
+	id, err := reopened.CreateTask(ctx, &model.Task{Title: "Task2"})
+	if err != nil || id != 2 {
+		t.Fatalf("expected id 2 after torn tail, got %d (err %v)", id, err)
+	}
+
+	again, err := filerepo.NewTaskRepo(dir, 1000)
+	if err != nil {
+		t.Fatalf("failed to reopen repo: %v", err)
+	}
+	defer again.Close()
+
+	task, _ := again.GetTaskByID(ctx, 2)
+	if task == nil || task.Title != "Task2" {
+		t.Fatalf("expected task written after torn tail to survive, got %v", task)
+	}
+}
+
+func TestReplayFailsOnCorruptRecord(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	repo, err := filerepo.NewTaskRepo(dir, 1000)
+	if err != nil {
+		t.Fatalf("failed to open repo: %v", err)
+	}
+
+	for _, title := range []string{"Task1", "Task2", "Task3"} {
+		if _, err := repo.CreateTask(ctx, &model.Task{Title: title}); err != nil {
+			t.Fatalf("failed to create task: %v", err)
+		}
+	}
+
+	// хранилище не закрывается: Close перенес бы журнал в снапшот
+	path := filepath.Join(dir, "wal.log")
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read wal: %v", err)
+	}
+
+	// портится вторая из трех записей, перевод строки после нее остается
+	lines := strings.SplitAfter(string(data), "\n")
+	lines[1] = strings.Repeat("x", len(lines[1])-1) + "\n"
+	corrupted := strings.Join(lines, "")
+
+	if err := os.WriteFile(path, []byte(corrupted), 0o644); err != nil {
+		t.Fatalf("failed to write wal: %v", err)
+	}
+
+	if _, err := filerepo.NewTaskRepo(dir, 1000); err == nil {
+		t.Fatal("expected error on corrupt record in the middle of wal")
+	}
+
+	// записи после испорченной остаются в журнале
+	after, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read wal: %v", err)
+	}
+
+	if string(after) != corrupted {
+		t.Fatal("expected wal to stay untouched after failed replay")
+	}
+}
+
+func TestTrashSurvivesRestart(t *testing.T) {
+	tests := []struct {
+		name         string
+		compactEvery int
+	}{
+		{name: "wal only", compactEvery: 1000},
+		{name: "snapshot only", compactEvery: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			dir := t.TempDir()
+
+			repo, err := filerepo.NewTaskRepo(dir, tt.compactEvery)
+			if err != nil {
+				t.Fatalf("failed to open repo: %v", err)
+			}
+
+			for _, title := range []string{"Task1", "Task2", "Task3"} {
+				if _, err := repo.CreateTask(ctx, &model.Task{Title: title}); err != nil {
+					t.Fatalf("failed to create task: %v", err)
+				}
+			}
+
+			for _, id := range []int{1, 2, 3} {
+				if _, err := repo.DeleteTask(ctx, id, 0); err != nil {
+					t.Fatalf("failed to delete task: %v", err)
+				}
+			}
+
+			if _, err := repo.RestoreTask(ctx, 1); err != nil {
+				t.Fatalf("failed to restore task: %v", err)
+			}
+
+			if purged, err := repo.PurgeDeletedTasks(ctx, time.Now().Add(time.Second)); err != nil || purged != 2 {
+				t.Fatalf("expected 2 purged tasks, got %d (err %v)", purged, err)
+			}
+
+			if _, err := repo.CreateTask(ctx, &model.Task{Title: "Task4"}); err != nil {
+				t.Fatalf("failed to create task: %v", err)
+			}
+
+			if _, err := repo.DeleteTask(ctx, 4, 0); err != nil {
+				t.Fatalf("failed to delete task: %v", err)
+			}
+
+			reopened, err := filerepo.NewTaskRepo(dir, tt.compactEvery)
+			if err != nil {
+				t.Fatalf("failed to reopen repo: %v", err)
+			}
+			defer reopened.Close()
+
+			tasks, _ := reopened.GetAllTasks(ctx)
+			if len(tasks) != 1 || tasks[0].ID != 1 || tasks[0].DeletedAt != nil {
+				t.Fatalf("expected only restored task 1, got %v", tasks)
+			}
+
+			trash, _ := reopened.GetDeletedTasks(ctx)
+			if len(trash) != 1 || trash[0].ID != 4 || trash[0].DeletedAt == nil {
+				t.Fatalf("expected only task 4 in trash, got %v", trash)
+			}
+		})
+	}
+}
+
+func TestRevisionRepoReplay(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), filerepo.RevisionsFileName)
+
+	repo, err := filerepo.NewRevisionRepo(path)
+	if err != nil {
+		t.Fatalf("failed to open revision repo: %v", err)
+	}
+
+	for _, rev := range []int{1, 2, 2} {
+		err := repo.AddRevision(ctx, &model.Revision{
+			TaskID: 1,
+			Rev:    rev,
+			Action: model.RevisionUpdated,
+			Author: "alice",
+			Task:   model.Task{ID: 1, Title: "Task", Version: rev},
+		})
+		if err != nil {
+			t.Fatalf("failed to add revision: %v", err)
+		}
+	}
+
+	repo.Close()
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		t.Fatalf("failed to open revisions log: %v", err)
+	}
+	f.WriteString(`{"TaskID":1,"Rev":3,"Ac`)
+	f.Close()
+
+	reopened, err := filerepo.NewRevisionRepo(path)
+	if err != nil {
+		t.Fatalf("failed to reopen revision repo: %v", err)
+	}
+	defer reopened.Close()
+
+	revisions, _ := reopened.GetRevisions(ctx, 1)
+	if len(revisions) != 2 || revisions[1].Author != "alice" || revisions[1].Task.Version != 2 {
+		t.Fatalf("expected 2 revisions after replay, got %+v", revisions)
+	}
+
+	if err := reopened.AddRevision(ctx, &model.Revision{TaskID: 1, Rev: 3}); err != nil {
+		t.Fatalf("failed to add revision after torn tail: %v", err)
+	}
+
+	if _, err := reopened.GetRevision(ctx, 1, 3); err != nil {
+		t.Fatalf("expected revision 3, got %v", err)
+	}
+}
+
+func TestRevisionRepoFailsOnCorruptRecord(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), filerepo.RevisionsFileName)
+
+	repo, err := filerepo.NewRevisionRepo(path)
+	if err != nil {
+		t.Fatalf("failed to open revision repo: %v", err)
+	}
+
+	for _, rev := range []int{1, 2} {
+		if err := repo.AddRevision(ctx, &model.Revision{TaskID: 1, Rev: rev, Task: model.Task{ID: 1, Version: rev}}); err != nil {
+			t.Fatalf("failed to add revision: %v", err)
+		}
+	}
+
+	repo.Close()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read log: %v", err)
+	}
+
+	// перед целыми записями оказывается испорченная
+	if err := os.WriteFile(path, append([]byte("garbage\n"), data...), 0o644); err != nil {
+		t.Fatalf("failed to write log: %v", err)
+	}
+
+	if _, err := filerepo.NewRevisionRepo(path); err == nil {
+		t.Fatal("expected error on corrupt record in the middle of revisions log")
+	}
+}
@@ -0,0 +1,137 @@
+package inmemory
+
+import (
+	"context"
+
+	"github.com/solumD/tasks-service/internal/model"
+	"github.com/solumD/tasks-service/internal/repository/index"
+)
+
+// DumpTasks возвращает копии всех задач, включая корзину, и ID следующей задачи
+// на один момент времени
+func (r *taskRepo) DumpTasks(ctx context.Context) ([]*model.Task, int, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tasks := make([]*model.Task, 0, len(r.tasks)+len(r.trash))
+
+	for _, task := range r.tasks {
+		tasks = append(tasks, task.Clone())
+	}
+
+	for _, task := range r.trash {
+		tasks = append(tasks, task.Clone())
+	}
+
+	return tasks, r.idCounter + 1, nil
+}
+
+// ReplaceTasks заменяет все задачи, включая корзину, на tasks. Счетчик ID
+// не уменьшается, а следующая задача получит ID не меньше nextID
+func (r *taskRepo) ReplaceTasks(ctx context.Context, tasks []*model.Task, nextID int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.tasks = make(map[int]*model.Task, len(tasks))
+	r.trash = make(map[int]*model.Task)
+	r.due = index.NewDue()
+	r.tags = index.NewTags()
+	r.children = index.NewChildren()
+
+	for _, task := range tasks {
+		stored := task.Clone()
+
+		if stored.DeletedAt != nil {
+			r.trash[stored.ID] = stored
+		} else {
+			r.tasks[stored.ID] = stored
+			r.due.Put(stored.ID, stored.DueAt)
+			r.tags.Put(stored.ID, stored.Tags)
+			r.children.Put(stored.ID, stored.ParentID)
+		}
+
+		r.idCounter = max(r.idCounter, stored.ID)
+	}
+
+	r.idCounter = max(r.idCounter, nextID-1)
+
+	r.snapshot.Store(nil)
+
+	return nil
+}
+
+// DumpTasks возвращает копии всех задач, включая корзину, и ID следующей задачи.
+// На время чтения блокируются все шарды, поэтому результат соответствует одному моменту
+func (r *shardedTaskRepo) DumpTasks(ctx context.Context) ([]*model.Task, int, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	for _, s := range r.shards {
+		s.mu.RLock()
+		defer s.mu.RUnlock()
+	}
+
+	tasks := make([]*model.Task, 0)
+
+	for _, s := range r.shards {
+		for _, task := range s.tasks {
+			tasks = append(tasks, task.Clone())
+		}
+
+		for _, task := range s.trash {
+			tasks = append(tasks, task.Clone())
+		}
+	}
+
+	return tasks, int(r.idCounter.Load()) + 1, nil
+}
+
+// ReplaceTasks заменяет все задачи, включая корзину, на tasks под блокировкой всех шардов.
+// Счетчик ID не уменьшается, а следующая задача получит ID не меньше nextID
+func (r *shardedTaskRepo) ReplaceTasks(ctx context.Context, tasks []*model.Task, nextID int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	for _, s := range r.shards {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+	}
+
+	for _, s := range r.shards {
+		s.tasks = make(map[int]*model.Task)
+		s.trash = make(map[int]*model.Task)
+		s.due = index.NewDue()
+		s.tags = index.NewTags()
+		s.children = index.NewChildren()
+	}
+
+	for _, task := range tasks {
+		stored := task.Clone()
+		s := r.shard(stored.ID)
+
+		if stored.DeletedAt != nil {
+			s.trash[stored.ID] = stored
+		} else {
+			s.tasks[stored.ID] = stored
+			s.due.Put(stored.ID, stored.DueAt)
+			s.tags.Put(stored.ID, stored.Tags)
+			s.children.Put(stored.ID, stored.ParentID)
+		}
+
+		r.advanceIDCounter(stored.ID)
+	}
+
+	r.advanceIDCounter(nextID - 1)
+
+	return nil
+}
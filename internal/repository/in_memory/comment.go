@@ -0,0 +1,223 @@
+package inmemory
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/solumD/tasks-service/internal/model"
+	"github.com/solumD/tasks-service/internal/repository/index"
+	"github.com/solumD/tasks-service/internal/usecase"
+)
+
+// commentRepo хранилище комментариев в памяти. Как и задачи, комментарии копируются
+// на запись и на чтение
+type commentRepo struct {
+	comments map[int]*model.Comment
+	index    *index.Comments
+
+	mu        *sync.RWMutex
+	idCounter int
+}
+
+func NewCommentRepo() *commentRepo {
+	return &commentRepo{
+		comments: make(map[int]*model.Comment),
+		index:    index.NewComments(),
+		mu:       &sync.RWMutex{},
+	}
+}
+
+// CreateComment создает новый комментарий в хранилище
+func (r *commentRepo) CreateComment(ctx context.Context, comment *model.Comment) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.idCounter++
+	comment.ID = r.idCounter
+
+	stored := comment.Clone()
+	r.comments[stored.ID] = stored
+	r.index.Put(stored)
+
+	return comment.ID, nil
+}
+
+// GetComments возвращает до limit комментариев верхнего уровня задачи taskID с ID больше after
+func (r *commentRepo) GetComments(ctx context.Context, taskID int, after int, limit int) ([]*model.Comment, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.byIDs(r.index.Threads(taskID, after, limit)), nil
+}
+
+// GetReplies возвращает ответы на комментарии parentIDs по возрастанию ID
+func (r *commentRepo) GetReplies(ctx context.Context, parentIDs []int) ([]*model.Comment, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	ids := make([]int, 0)
+	for _, parentID := range parentIDs {
+		ids = append(ids, r.index.Replies(parentID)...)
+	}
+
+	replies := r.byIDs(ids)
+	sort.Slice(replies, func(i, j int) bool {
+		return replies[i].ID < replies[j].ID
+	})
+
+	return replies, nil
+}
+
+// GetCommentByID возвращает комментарий по ID из хранилища
+func (r *commentRepo) GetCommentByID(ctx context.Context, id int) (*model.Comment, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	comment, ok := r.comments[id]
+	if !ok {
+		return nil, usecase.NewCommentNotFoundError(id)
+	}
+
+	return comment.Clone(), nil
+}
+
+// UpdateComment меняет текст и время изменения комментария
+func (r *commentRepo) UpdateComment(ctx context.Context, comment *model.Comment) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, ok := r.comments[comment.ID]
+	if !ok {
+		return usecase.NewCommentNotFoundError(comment.ID)
+	}
+
+	stored := current.Clone()
+	stored.Text = comment.Text
+	stored.EditedAt = comment.Clone().EditedAt
+	r.comments[stored.ID] = stored
+
+	return nil
+}
+
+// DeleteComment удаляет комментарий вместе с ответами на него
+func (r *commentRepo) DeleteComment(ctx context.Context, id int) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	comment, ok := r.comments[id]
+	if !ok {
+		return 0, usecase.NewCommentNotFoundError(id)
+	}
+
+	return r.remove(append(r.index.Replies(comment.ID), comment.ID)), nil
+}
+
+// DeleteTaskComments удаляет все комментарии задач taskIDs
+func (r *commentRepo) DeleteTaskComments(ctx context.Context, taskIDs []int) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	deleted := 0
+	for _, taskID := range taskIDs {
+		for _, id := range r.index.AllThreads(taskID) {
+			deleted += r.remove(append(r.index.Replies(id), id))
+		}
+	}
+
+	return deleted, nil
+}
+
+// remove удаляет комментарии ids из хранилища и индекса и возвращает их количество
+func (r *commentRepo) remove(ids []int) int {
+	for _, id := range ids {
+		r.index.Remove(r.comments[id])
+		delete(r.comments, id)
+	}
+
+	return len(ids)
+}
+
+// byIDs возвращает копии комментариев ids в том же порядке
+func (r *commentRepo) byIDs(ids []int) []*model.Comment {
+	comments := make([]*model.Comment, 0, len(ids))
+	for _, id := range ids {
+		comments = append(comments, r.comments[id].Clone())
+	}
+
+	return comments
+}
+
+// DumpComments возвращает копии всех комментариев по возрастанию ID и ID следующего
+// комментария на один момент времени
+func (r *commentRepo) DumpComments(ctx context.Context) ([]*model.Comment, int, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	comments := make([]*model.Comment, 0, len(r.comments))
+	for _, comment := range r.comments {
+		comments = append(comments, comment.Clone())
+	}
+
+	sort.Slice(comments, func(i, j int) bool {
+		return comments[i].ID < comments[j].ID
+	})
+
+	return comments, r.idCounter + 1, nil
+}
+
+// ReplaceComments заменяет все комментарии на comments. Счетчик ID не уменьшается,
+// а следующий комментарий получит ID не меньше nextID
+func (r *commentRepo) ReplaceComments(ctx context.Context, comments []*model.Comment, nextID int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.comments = make(map[int]*model.Comment, len(comments))
+	r.index = index.NewComments()
+	for _, comment := range comments {
+		stored := comment.Clone()
+		r.comments[stored.ID] = stored
+		r.index.Put(stored)
+		r.idCounter = max(r.idCounter, stored.ID)
+	}
+
+	r.idCounter = max(r.idCounter, nextID-1)
+
+	return nil
+}
@@ -0,0 +1,111 @@
+package inmemory
+
+import (
+	"context"
+
+	"github.com/solumD/tasks-service/internal/model"
+)
+
+// NextID возвращает ID, который получит следующая созданная задача
+func (r *taskRepo) NextID(ctx context.Context) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.idCounter + 1, nil
+}
+
+// ImportTasks записывает задачи с их ID, версиями и временем удаления, заменяя
+// задачи с теми же ID, и сдвигает счетчик так, чтобы следующая задача получила ID не меньше nextID
+func (r *taskRepo) ImportTasks(ctx context.Context, tasks []*model.Task, nextID int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, task := range tasks {
+		stored := task.Clone()
+
+		if stored.DeletedAt != nil {
+			delete(r.tasks, stored.ID)
+			r.due.Remove(stored.ID)
+			r.tags.Remove(stored.ID)
+			r.children.Remove(stored.ID)
+			r.trash[stored.ID] = stored
+		} else {
+			delete(r.trash, stored.ID)
+			r.tasks[stored.ID] = stored
+			r.due.Put(stored.ID, stored.DueAt)
+			r.tags.Put(stored.ID, stored.Tags)
+			r.children.Put(stored.ID, stored.ParentID)
+		}
+
+		r.idCounter = max(r.idCounter, stored.ID)
+	}
+
+	r.idCounter = max(r.idCounter, nextID-1)
+
+	r.snapshot.Store(nil)
+
+	return nil
+}
+
+// NextID возвращает ID, который получит следующая созданная задача
+func (r *shardedTaskRepo) NextID(ctx context.Context) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	return int(r.idCounter.Load()) + 1, nil
+}
+
+// ImportTasks записывает задачи с их ID, версиями и временем удаления, заменяя
+// задачи с теми же ID, и сдвигает счетчик так, чтобы следующая задача получила ID не меньше nextID
+func (r *shardedTaskRepo) ImportTasks(ctx context.Context, tasks []*model.Task, nextID int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	for _, task := range tasks {
+		stored := task.Clone()
+
+		s := r.shard(stored.ID)
+
+		s.mu.Lock()
+		if stored.DeletedAt != nil {
+			delete(s.tasks, stored.ID)
+			s.due.Remove(stored.ID)
+			s.tags.Remove(stored.ID)
+			s.children.Remove(stored.ID)
+			s.trash[stored.ID] = stored
+		} else {
+			delete(s.trash, stored.ID)
+			s.tasks[stored.ID] = stored
+			s.due.Put(stored.ID, stored.DueAt)
+			s.tags.Put(stored.ID, stored.Tags)
+			s.children.Put(stored.ID, stored.ParentID)
+		}
+		s.mu.Unlock()
+
+		r.advanceIDCounter(stored.ID)
+	}
+
+	r.advanceIDCounter(nextID - 1)
+
+	return nil
+}
+
+// advanceIDCounter поднимает счетчик ID до id, если он меньше
+func (r *shardedTaskRepo) advanceIDCounter(id int) {
+	for {
+		current := r.idCounter.Load()
+		if current >= int64(id) || r.idCounter.CompareAndSwap(current, int64(id)) {
+			return
+		}
+	}
+}
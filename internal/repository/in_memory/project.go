@@ -0,0 +1,178 @@
+package inmemory
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/solumD/tasks-service/internal/model"
+	"github.com/solumD/tasks-service/internal/usecase"
+)
+
+// projectRepo хранилище проектов в памяти. Как и задачи, проекты копируются
+// на запись и на чтение
+type projectRepo struct {
+	projects map[int]*model.Project
+
+	mu        *sync.RWMutex
+	idCounter int
+}
+
+func NewProjectRepo() *projectRepo {
+	return &projectRepo{
+		projects: make(map[int]*model.Project),
+		mu:       &sync.RWMutex{},
+	}
+}
+
+// CreateProject создает новый проект в хранилище
+func (r *projectRepo) CreateProject(ctx context.Context, project *model.Project) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.idCounter++
+	project.ID = r.idCounter
+	project.Version = 1
+	r.projects[project.ID] = storedProject(project)
+
+	return project.ID, nil
+}
+
+// GetProjects возвращает все проекты по возрастанию ID
+func (r *projectRepo) GetProjects(ctx context.Context) ([]*model.Project, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	projects := make([]*model.Project, 0, len(r.projects))
+	for _, project := range r.projects {
+		projects = append(projects, project.Clone())
+	}
+
+	sort.Slice(projects, func(i, j int) bool {
+		return projects[i].ID < projects[j].ID
+	})
+
+	return projects, nil
+}
+
+// GetProjectByID возвращает проект по ID из хранилища
+func (r *projectRepo) GetProjectByID(ctx context.Context, id int) (*model.Project, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	project, ok := r.projects[id]
+	if !ok {
+		return nil, usecase.NewProjectNotFoundError(id)
+	}
+
+	return project.Clone(), nil
+}
+
+// UpdateProject обновляет проект, если project.Version 0 или совпадает с текущей версией
+func (r *projectRepo) UpdateProject(ctx context.Context, project *model.Project) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, ok := r.projects[project.ID]
+	if !ok {
+		return usecase.NewProjectNotFoundError(project.ID)
+	}
+
+	if project.Version != 0 && project.Version != current.Version {
+		return usecase.ErrProjectVersionConflict
+	}
+
+	project.Version = current.Version + 1
+	r.projects[project.ID] = storedProject(project)
+
+	return nil
+}
+
+// DeleteProject удаляет проект, если version 0 или совпадает с текущей версией
+func (r *projectRepo) DeleteProject(ctx context.Context, id int, version int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, ok := r.projects[id]
+	if !ok {
+		return usecase.NewProjectNotFoundError(id)
+	}
+
+	if version != 0 && version != current.Version {
+		return usecase.ErrProjectVersionConflict
+	}
+
+	delete(r.projects, id)
+
+	return nil
+}
+
+// storedProject возвращает копию проекта для записи в хранилище без вычисляемых полей
+func storedProject(project *model.Project) *model.Project {
+	clone := project.Clone()
+	clone.Counts = model.ProjectCounts{}
+
+	return clone
+}
+
+// DumpProjects возвращает копии всех проектов по возрастанию ID и ID следующего
+// проекта на один момент времени
+func (r *projectRepo) DumpProjects(ctx context.Context) ([]*model.Project, int, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	projects := make([]*model.Project, 0, len(r.projects))
+	for _, project := range r.projects {
+		projects = append(projects, project.Clone())
+	}
+
+	sort.Slice(projects, func(i, j int) bool {
+		return projects[i].ID < projects[j].ID
+	})
+
+	return projects, r.idCounter + 1, nil
+}
+
+// ReplaceProjects заменяет все проекты на projects. Счетчик ID не уменьшается,
+// а следующий проект получит ID не меньше nextID
+func (r *projectRepo) ReplaceProjects(ctx context.Context, projects []*model.Project, nextID int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.projects = make(map[int]*model.Project, len(projects))
+	for _, project := range projects {
+		r.projects[project.ID] = storedProject(project)
+	}
+
+	r.idCounter = max(r.idCounter, nextID-1)
+
+	return nil
+}
@@ -0,0 +1,77 @@
+package inmemory
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/solumD/tasks-service/internal/model"
+	"github.com/solumD/tasks-service/internal/usecase"
+)
+
+type revisionRepo struct {
+	revisions map[int][]*model.Revision
+
+	mu *sync.RWMutex
+}
+
+func NewRevisionRepo() *revisionRepo {
+	return &revisionRepo{
+		revisions: make(map[int][]*model.Revision),
+		mu:        &sync.RWMutex{},
+	}
+}
+
+// AddRevision сохраняет ревизию, если ревизии с тем же номером у задачи еще нет
+func (r *revisionRepo) AddRevision(_ context.Context, rev *model.Revision) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	revisions := r.revisions[rev.TaskID]
+
+	i := sort.Search(len(revisions), func(i int) bool {
+		return revisions[i].Rev >= rev.Rev
+	})
+	if i < len(revisions) && revisions[i].Rev == rev.Rev {
+		return nil
+	}
+
+	revisions = append(revisions, nil)
+	copy(revisions[i+1:], revisions[i:])
+	revisions[i] = rev.Clone()
+
+	r.revisions[rev.TaskID] = revisions
+
+	return nil
+}
+
+// GetRevisions возвращает ревизии задачи по возрастанию номера
+func (r *revisionRepo) GetRevisions(_ context.Context, taskID int) ([]*model.Revision, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	revisions := make([]*model.Revision, 0, len(r.revisions[taskID]))
+
+	for _, rev := range r.revisions[taskID] {
+		revisions = append(revisions, rev.Clone())
+	}
+
+	return revisions, nil
+}
+
+// GetRevision возвращает ревизию rev задачи
+func (r *revisionRepo) GetRevision(_ context.Context, taskID int, rev int) (*model.Revision, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	revisions := r.revisions[taskID]
+
+	i := sort.Search(len(revisions), func(i int) bool {
+		return revisions[i].Rev >= rev
+	})
+	if i == len(revisions) || revisions[i].Rev != rev {
+		return nil, usecase.ErrRevisionNotFound
+	}
+
+	return revisions[i].Clone(), nil
+}
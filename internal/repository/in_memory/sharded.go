@@ -0,0 +1,385 @@
+package inmemory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/solumD/tasks-service/internal/model"
+	"github.com/solumD/tasks-service/internal/repository/index"
+	"github.com/solumD/tasks-service/internal/usecase"
+)
+
+// shard часть задач со своей блокировкой
+type shard struct {
+	tasks map[int]*model.Task
+	trash map[int]*model.Task
+	// due, tags и children индексы сроков, меток и подзадач задач шарда не из корзины.
+	// Подзадача индексируется в своем шарде, а не в шарде родителя
+	due      *index.Due
+	tags     *index.Tags
+	children *index.Children
+
+	mu *sync.RWMutex
+}
+
+// shardedTaskRepo хранилище в памяти, разбитое на шарды по ID задачи.
+// Запись в разные шарды не конкурирует за одну блокировку, а ID выдаются
+// атомарным счетчиком без блокировок
+type shardedTaskRepo struct {
+	shards    []*shard
+	idCounter *atomic.Int64
+}
+
+// NewShardedTaskRepo создает хранилище в памяти из shards шардов
+func NewShardedTaskRepo(shards int) (*shardedTaskRepo, error) {
+	if shards <= 0 {
+		return nil, fmt.Errorf("shards count must be positive, got %d", shards)
+	}
+
+	r := &shardedTaskRepo{
+		shards:    make([]*shard, shards),
+		idCounter: &atomic.Int64{},
+	}
+
+	for i := range r.shards {
+		r.shards[i] = &shard{
+			tasks:    make(map[int]*model.Task),
+			trash:    make(map[int]*model.Task),
+			due:      index.NewDue(),
+			tags:     index.NewTags(),
+			children: index.NewChildren(),
+			mu:       &sync.RWMutex{},
+		}
+	}
+
+	return r, nil
+}
+
+// CreateTask создает новую задачу в хранилище
+func (r *shardedTaskRepo) CreateTask(ctx context.Context, task *model.Task) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	id := int(r.idCounter.Add(1))
+
+	task.ID = id
+	task.Version = 1
+
+	s := r.shard(id)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.tasks[id] = task.Clone()
+	s.due.Put(id, task.DueAt)
+	s.tags.Put(id, task.Tags)
+	s.children.Put(id, task.ParentID)
+
+	return id, nil
+}
+
+// GetAllTasks возвращает все задачи из хранилища. Шарды читаются по очереди,
+// поэтому результат не является снимком на один момент времени
+func (r *shardedTaskRepo) GetAllTasks(ctx context.Context) ([]*model.Task, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	tasks := make([]*model.Task, 0)
+
+	for _, s := range r.shards {
+		s.mu.RLock()
+		for _, task := range s.tasks {
+			tasks = append(tasks, task.Clone())
+		}
+		s.mu.RUnlock()
+	}
+
+	return tasks, nil
+}
+
+// GetTaskByID возвращает задачу по ID из хранилища
+func (r *shardedTaskRepo) GetTaskByID(ctx context.Context, id int) (*model.Task, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s := r.shard(id)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	task, ok := s.tasks[id]
+	if !ok {
+		return nil, usecase.NewTaskNotFoundError(id)
+	}
+
+	return task.Clone(), nil
+}
+
+// GetTasksByDue возвращает задачи со сроком выполнения в диапазоне [from, to)
+// по индексам сроков шардов. Как и GetAllTasks, шарды читаются по очереди
+func (r *shardedTaskRepo) GetTasksByDue(ctx context.Context, from, to time.Time) ([]*model.Task, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	tasks := make([]*model.Task, 0)
+
+	for _, s := range r.shards {
+		s.mu.RLock()
+		for _, id := range s.due.Range(from, to) {
+			tasks = append(tasks, s.tasks[id].Clone())
+		}
+		s.mu.RUnlock()
+	}
+
+	return tasks, nil
+}
+
+// GetTasksByTags возвращает задачи хотя бы с одной из меток tags, а при all - со всеми,
+// по индексам меток шардов. Как и GetAllTasks, шарды читаются по очереди
+func (r *shardedTaskRepo) GetTasksByTags(ctx context.Context, tags []string, all bool) ([]*model.Task, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	tasks := make([]*model.Task, 0)
+
+	for _, s := range r.shards {
+		s.mu.RLock()
+		for _, id := range s.tags.Match(tags, all) {
+			tasks = append(tasks, s.tasks[id].Clone())
+		}
+		s.mu.RUnlock()
+	}
+
+	return tasks, nil
+}
+
+// GetTasksByParent возвращает подзадачи задачи parentID по индексам подзадач шардов
+func (r *shardedTaskRepo) GetTasksByParent(ctx context.Context, parentID int) ([]*model.Task, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	tasks := make([]*model.Task, 0)
+
+	for _, s := range r.shards {
+		s.mu.RLock()
+		for _, id := range s.children.IDs(parentID) {
+			tasks = append(tasks, s.tasks[id].Clone())
+		}
+		s.mu.RUnlock()
+	}
+
+	return tasks, nil
+}
+
+// GetTagCounts возвращает метки задач с количеством задач у каждой, складывая счетчики шардов
+func (r *shardedTaskRepo) GetTagCounts(ctx context.Context) ([]model.TagCount, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	merged := make(map[string]int)
+
+	for _, s := range r.shards {
+		s.mu.RLock()
+		for _, count := range s.tags.Counts() {
+			merged[count.Tag] += count.Count
+		}
+		s.mu.RUnlock()
+	}
+
+	counts := make([]model.TagCount, 0, len(merged))
+	for tag, count := range merged {
+		counts = append(counts, model.TagCount{Tag: tag, Count: count})
+	}
+
+	sort.Slice(counts, func(i, j int) bool {
+		return counts[i].Tag < counts[j].Tag
+	})
+
+	return counts, nil
+}
+
+// RenameTag заменяет метку from на to у всех задач. Блокируются все шарды сразу,
+// поэтому переименование видно целиком
+func (r *shardedTaskRepo) RenameTag(ctx context.Context, from, to string) ([]*model.Task, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, s := range r.shards {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+	}
+
+	renamed := make([]*model.Task, 0)
+
+	for _, s := range r.shards {
+		for _, id := range s.tags.IDs(from) {
+			task := s.tasks[id].Clone()
+			task.RenameTag(from, to)
+			task.Version++
+
+			s.tasks[id] = task
+			s.tags.Put(id, task.Tags)
+			renamed = append(renamed, task.Clone())
+		}
+	}
+
+	return renamed, nil
+}
+
+// UpdateTask обновляет задачу в хранилище, если ее версия совпадает с task.Version
+func (r *shardedTaskRepo) UpdateTask(ctx context.Context, task *model.Task) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s := r.shard(task.ID)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current, ok := s.tasks[task.ID]
+	if !ok {
+		return usecase.NewTaskNotFoundError(task.ID)
+	}
+
+	if task.Version != 0 && task.Version != current.Version {
+		return usecase.ErrVersionConflict
+	}
+
+	task.Version = current.Version + 1
+	task.CreatedAt = current.CreatedAt
+	s.tasks[task.ID] = task.Clone()
+	s.due.Put(task.ID, task.DueAt)
+	s.tags.Put(task.ID, task.Tags)
+	s.children.Put(task.ID, task.ParentID)
+
+	return nil
+}
+
+// DeleteTask перемещает задачу в корзину, если ее версия совпадает с version,
+// и возвращает ее
+func (r *shardedTaskRepo) DeleteTask(ctx context.Context, id int, version int) (*model.Task, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s := r.shard(id)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current, ok := s.tasks[id]
+	if !ok {
+		return nil, usecase.NewTaskNotFoundError(id)
+	}
+
+	if version != 0 && version != current.Version {
+		return nil, usecase.ErrVersionConflict
+	}
+
+	deletedAt := time.Now().UTC()
+
+	trashed := current.Clone()
+	trashed.Version++
+	trashed.DeletedAt = &deletedAt
+
+	delete(s.tasks, id)
+	s.due.Remove(id)
+	s.tags.Remove(id)
+	s.children.Remove(id)
+	s.trash[id] = trashed
+
+	return trashed.Clone(), nil
+}
+
+// GetDeletedTasks возвращает все задачи из корзины
+func (r *shardedTaskRepo) GetDeletedTasks(ctx context.Context) ([]*model.Task, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	tasks := make([]*model.Task, 0)
+
+	for _, s := range r.shards {
+		s.mu.RLock()
+		for _, task := range s.trash {
+			tasks = append(tasks, task.Clone())
+		}
+		s.mu.RUnlock()
+	}
+
+	return tasks, nil
+}
+
+// RestoreTask возвращает задачу из корзины
+func (r *shardedTaskRepo) RestoreTask(ctx context.Context, id int) (*model.Task, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s := r.shard(id)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	trashed, ok := s.trash[id]
+	if !ok {
+		return nil, usecase.NewTaskNotFoundError(id)
+	}
+
+	restored := trashed.Clone()
+	restored.Version++
+	restored.DeletedAt = nil
+
+	delete(s.trash, id)
+	s.tasks[id] = restored
+	s.due.Put(id, restored.DueAt)
+	s.tags.Put(id, restored.Tags)
+	s.children.Put(id, restored.ParentID)
+
+	return restored.Clone(), nil
+}
+
+// PurgeDeletedTasks окончательно удаляет задачи, попавшие в корзину раньше deletedBefore
+func (r *shardedTaskRepo) PurgeDeletedTasks(ctx context.Context, deletedBefore time.Time) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	purged := 0
+
+	for _, s := range r.shards {
+		s.mu.Lock()
+		for id, task := range s.trash {
+			if task.DeletedAt.Before(deletedBefore) {
+				delete(s.trash, id)
+				purged++
+			}
+		}
+		s.mu.Unlock()
+	}
+
+	return purged, nil
+}
+
+// shard возвращает шард задачи. Соседние ID попадают в разные шарды
+func (r *shardedTaskRepo) shard(id int) *shard {
+	i := id % len(r.shards)
+	if i < 0 {
+		i += len(r.shards)
+	}
+
+	return r.shards[i]
+}
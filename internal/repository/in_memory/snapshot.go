@@ -0,0 +1,35 @@
+package inmemory
+
+import (
+	"time"
+
+	"github.com/solumD/tasks-service/internal/model"
+)
+
+// Snapshot неизменяемый снимок задач хранилища на один момент времени.
+// Снимок может одновременно использоваться несколькими читателями
+type Snapshot struct {
+	tasks   []*model.Task
+	takenAt time.Time
+}
+
+// Tasks возвращает копии задач снимка
+func (s *Snapshot) Tasks() []*model.Task {
+	tasks := make([]*model.Task, 0, len(s.tasks))
+
+	for _, task := range s.tasks {
+		tasks = append(tasks, task.Clone())
+	}
+
+	return tasks
+}
+
+// Len возвращает количество задач в снимке
+func (s *Snapshot) Len() int {
+	return len(s.tasks)
+}
+
+// TakenAt возвращает время создания снимка
+func (s *Snapshot) TakenAt() time.Time {
+	return s.takenAt
+}
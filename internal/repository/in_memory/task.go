@@ -3,86 +3,349 @@ package inmemory
 import (
 	"context"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/solumD/tasks-service/internal/model"
+	"github.com/solumD/tasks-service/internal/repository/index"
+	"github.com/solumD/tasks-service/internal/usecase"
 )
 
+// taskRepo хранилище в памяти. Хранилище владеет своими задачами: на запись
+// и на чтение задачи копируются, поэтому изменение переданных или полученных
+// значений не затрагивает хранилище
 type taskRepo struct {
 	tasks map[int]*model.Task
+	trash map[int]*model.Task
+	// due, tags и children индексы сроков, меток и подзадач задач не из корзины
+	due      *index.Due
+	tags     *index.Tags
+	children *index.Children
 
 	mu        *sync.RWMutex
 	idCounter int
+
+	// snapshot кэш последнего снимка задач, сбрасывается при каждой записи
+	snapshot *atomic.Pointer[Snapshot]
 }
 
 func NewTaskRepo() *taskRepo {
 	return &taskRepo{
 		tasks:     make(map[int]*model.Task),
+		trash:     make(map[int]*model.Task),
+		due:       index.NewDue(),
+		tags:      index.NewTags(),
+		children:  index.NewChildren(),
 		mu:        &sync.RWMutex{},
 		idCounter: 0,
+		snapshot:  &atomic.Pointer[Snapshot]{},
 	}
 }
 
 // CreateTask создает новую задачу в хранилище
-func (r *taskRepo) CreateTask(_ context.Context, task *model.Task) (int, error) {
+func (r *taskRepo) CreateTask(ctx context.Context, task *model.Task) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
 	r.idCounter++
 	task.ID = r.idCounter
-	r.tasks[task.ID] = task
+	task.Version = 1
+	r.tasks[task.ID] = task.Clone()
+	r.due.Put(task.ID, task.DueAt)
+	r.tags.Put(task.ID, task.Tags)
+	r.children.Put(task.ID, task.ParentID)
+
+	r.snapshot.Store(nil)
 
 	return task.ID, nil
 }
 
-// GetAllTasks возвращает все задачи из хранилища
-func (r *taskRepo) GetAllTasks(_ context.Context) ([]*model.Task, error) {
+// GetAllTasks возвращает копии всех задач из одного снимка хранилища
+func (r *taskRepo) GetAllTasks(ctx context.Context) ([]*model.Task, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return r.Snapshot(ctx).Tasks(), nil
+}
+
+// GetTaskByID возвращает задачу по ID из хранилища
+func (r *taskRepo) GetTaskByID(ctx context.Context, id int) (*model.Task, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
-	tasks := make([]*model.Task, 0, len(r.tasks))
+	task, ok := r.tasks[id]
+	if !ok {
+		return nil, usecase.NewTaskNotFoundError(id)
+	}
 
-	for _, task := range r.tasks {
-		tasks = append(tasks, task)
+	return task.Clone(), nil
+}
+
+// GetTasksByDue возвращает задачи со сроком выполнения в диапазоне [from, to) по индексу сроков
+func (r *taskRepo) GetTasksByDue(ctx context.Context, from, to time.Time) ([]*model.Task, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	ids := r.due.Range(from, to)
+	tasks := make([]*model.Task, 0, len(ids))
+
+	for _, id := range ids {
+		tasks = append(tasks, r.tasks[id].Clone())
 	}
 
 	return tasks, nil
 }
 
-// GetTaskByID возвращает задачу по ID из хранилища
-func (r *taskRepo) GetTaskByID(_ context.Context, id int) (*model.Task, error) {
+// GetTasksByTags возвращает задачи хотя бы с одной из меток tags, а при all - со всеми, по индексу меток
+func (r *taskRepo) GetTasksByTags(ctx context.Context, tags []string, all bool) ([]*model.Task, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
-	task := r.tasks[id]
+	ids := r.tags.Match(tags, all)
+	tasks := make([]*model.Task, 0, len(ids))
+
+	for _, id := range ids {
+		tasks = append(tasks, r.tasks[id].Clone())
+	}
 
-	return task, nil
+	return tasks, nil
 }
 
-// UpdateTask обновляет задачу в хранилище
-func (r taskRepo) UpdateTask(_ context.Context, task *model.Task) error {
+// GetTasksByParent возвращает подзадачи задачи parentID по индексу подзадач
+func (r *taskRepo) GetTasksByParent(ctx context.Context, parentID int) ([]*model.Task, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	ids := r.children.IDs(parentID)
+	tasks := make([]*model.Task, 0, len(ids))
+
+	for _, id := range ids {
+		tasks = append(tasks, r.tasks[id].Clone())
+	}
+
+	return tasks, nil
+}
+
+// GetTagCounts возвращает метки задач с количеством задач у каждой
+func (r *taskRepo) GetTagCounts(ctx context.Context) ([]model.TagCount, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.tags.Counts(), nil
+}
+
+// RenameTag заменяет метку from на to у всех задач под одной блокировкой
+func (r *taskRepo) RenameTag(ctx context.Context, from, to string) ([]*model.Task, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	r.tasks[task.ID] = task
+	ids := r.tags.IDs(from)
+	renamed := make([]*model.Task, 0, len(ids))
+
+	for _, id := range ids {
+		task := r.tasks[id].Clone()
+		task.RenameTag(from, to)
+		task.Version++
+
+		r.tasks[id] = task
+		r.tags.Put(id, task.Tags)
+		renamed = append(renamed, task.Clone())
+	}
+
+	if len(renamed) > 0 {
+		r.snapshot.Store(nil)
+	}
+
+	return renamed, nil
+}
+
+// UpdateTask обновляет задачу в хранилище, если ее версия совпадает с task.Version
+func (r *taskRepo) UpdateTask(ctx context.Context, task *model.Task) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, ok := r.tasks[task.ID]
+	if !ok {
+		return usecase.NewTaskNotFoundError(task.ID)
+	}
+
+	if task.Version != 0 && task.Version != current.Version {
+		return usecase.ErrVersionConflict
+	}
+
+	task.Version = current.Version + 1
+	task.CreatedAt = current.CreatedAt
+	r.tasks[task.ID] = task.Clone()
+	r.due.Put(task.ID, task.DueAt)
+	r.tags.Put(task.ID, task.Tags)
+	r.children.Put(task.ID, task.ParentID)
+
+	r.snapshot.Store(nil)
 
 	return nil
 }
 
-// DeleteTask удаляет задачу из хранилища
-func (r *taskRepo) DeleteTask(_ context.Context, id int) error {
+// DeleteTask перемещает задачу в корзину, если ее версия совпадает с version,
+// и возвращает ее
+func (r *taskRepo) DeleteTask(ctx context.Context, id int, version int) (*model.Task, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
+	current, ok := r.tasks[id]
+	if !ok {
+		return nil, usecase.NewTaskNotFoundError(id)
+	}
+
+	if version != 0 && version != current.Version {
+		return nil, usecase.ErrVersionConflict
+	}
+
+	deletedAt := time.Now().UTC()
+
+	trashed := current.Clone()
+	trashed.Version++
+	trashed.DeletedAt = &deletedAt
+
 	delete(r.tasks, id)
+	r.due.Remove(id)
+	r.tags.Remove(id)
+	r.children.Remove(id)
+	r.trash[id] = trashed
 
-	return nil
+	r.snapshot.Store(nil)
+
+	return trashed.Clone(), nil
+}
+
+// GetDeletedTasks возвращает все задачи из корзины
+func (r *taskRepo) GetDeletedTasks(ctx context.Context) ([]*model.Task, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tasks := make([]*model.Task, 0, len(r.trash))
+
+	for _, task := range r.trash {
+		tasks = append(tasks, task.Clone())
+	}
+
+	return tasks, nil
+}
+
+// RestoreTask возвращает задачу из корзины
+func (r *taskRepo) RestoreTask(ctx context.Context, id int) (*model.Task, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	trashed, ok := r.trash[id]
+	if !ok {
+		return nil, usecase.NewTaskNotFoundError(id)
+	}
+
+	restored := trashed.Clone()
+	restored.Version++
+	restored.DeletedAt = nil
+
+	delete(r.trash, id)
+	r.tasks[id] = restored
+	r.due.Put(id, restored.DueAt)
+	r.tags.Put(id, restored.Tags)
+	r.children.Put(id, restored.ParentID)
+
+	r.snapshot.Store(nil)
+
+	return restored.Clone(), nil
 }
 
-func (r *taskRepo) IsTaskExistByID(_ context.Context, id int) (bool, error) {
+// PurgeDeletedTasks окончательно удаляет задачи, попавшие в корзину раньше deletedBefore
+func (r *taskRepo) PurgeDeletedTasks(ctx context.Context, deletedBefore time.Time) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	purged := 0
+
+	for id, task := range r.trash {
+		if task.DeletedAt.Before(deletedBefore) {
+			delete(r.trash, id)
+			purged++
+		}
+	}
+
+	return purged, nil
+}
+
+// Snapshot возвращает неизменяемый снимок задач на момент вызова. Снимок
+// кэшируется до следующей записи, поэтому списки без изменений между ними
+// не берут блокировку и не обходят хранилище заново
+func (r *taskRepo) Snapshot(_ context.Context) *Snapshot {
+	if snap := r.snapshot.Load(); snap != nil {
+		return snap
+	}
+
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
-	_, exist := r.tasks[id]
+	// снимок сохраняется под блокировкой на чтение, чтобы запись не могла
+	// сбросить кэш между построением снимка и его сохранением
+	if snap := r.snapshot.Load(); snap != nil {
+		return snap
+	}
+
+	tasks := make([]*model.Task, 0, len(r.tasks))
+	for _, task := range r.tasks {
+		tasks = append(tasks, task.Clone())
+	}
+
+	snap := &Snapshot{tasks: tasks, takenAt: time.Now().UTC()}
+	r.snapshot.Store(snap)
 
-	return exist, nil
+	return snap
 }
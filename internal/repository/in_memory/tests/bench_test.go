@@ -0,0 +1,78 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync/atomic"
+	"testing"
+
+	"github.com/solumD/tasks-service/internal/model"
+)
+
+const benchTasks = 1024
+
+// BenchmarkTaskRepo сравнивает реализации хранилища в памяти при разной доле
+// записей среди параллельных операций. Запуск:
+//
+//	go test -bench TaskRepo -cpu 1,4,16 ./internal/repository/in_memory/tests/
+func BenchmarkTaskRepo(b *testing.B) {
+	mixes := []struct {
+		name         string
+		writePercent int
+	}{
+		{name: "reads-100", writePercent: 0},
+		{name: "reads-90", writePercent: 10},
+		{name: "reads-50", writePercent: 50},
+		{name: "writes-100", writePercent: 100},
+	}
+
+	for _, factory := range repoFactories {
+		for _, mix := range mixes {
+			b.Run(fmt.Sprintf("%s/%s", factory.name, mix.name), func(b *testing.B) {
+				ctx := context.Background()
+				repo := factory.new()
+
+				for i := 0; i < benchTasks; i++ {
+					if _, err := repo.CreateTask(ctx, &model.Task{Title: "task"}); err != nil {
+						b.Fatalf("failed to create task: %v", err)
+					}
+				}
+
+				var seed atomic.Int64
+
+				b.ResetTimer()
+				b.RunParallel(func(pb *testing.PB) {
+					rnd := rand.New(rand.NewSource(seed.Add(1)))
+
+					for pb.Next() {
+						id := rnd.Intn(benchTasks) + 1
+
+						if rnd.Intn(100) < mix.writePercent {
+							repo.UpdateTask(ctx, &model.Task{ID: id, Title: "updated"})
+						} else {
+							repo.GetTaskByID(ctx, id)
+						}
+					}
+				})
+			})
+		}
+	}
+}
+
+// BenchmarkTaskRepoCreate сравнивает выдачу ID при параллельном создании задач
+func BenchmarkTaskRepoCreate(b *testing.B) {
+	for _, factory := range repoFactories {
+		b.Run(factory.name, func(b *testing.B) {
+			ctx := context.Background()
+			repo := factory.new()
+
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					repo.CreateTask(ctx, &model.Task{Title: "task"})
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package tests
+
+import (
+	"testing"
+
+	inmemory "github.com/solumD/tasks-service/internal/repository/in_memory"
+	"github.com/solumD/tasks-service/internal/repository/repotest"
+	"github.com/solumD/tasks-service/internal/usecase"
+)
+
+func TestConformance(t *testing.T) {
+	for _, factory := range repoFactories {
+		t.Run(factory.name, func(t *testing.T) {
+			repotest.Run(t, func(t *testing.T) usecase.TaskRepo {
+				return factory.new()
+			})
+		})
+	}
+}
+
+func TestProjectConformance(t *testing.T) {
+	repotest.RunProjects(t, func(t *testing.T) usecase.ProjectRepo {
+		return inmemory.NewProjectRepo()
+	})
+}
+
+func TestCommentConformance(t *testing.T) {
+	repotest.RunComments(t, func(t *testing.T) usecase.CommentRepo {
+		return inmemory.NewCommentRepo()
+	})
+}
@@ -0,0 +1,167 @@
+package tests
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/solumD/tasks-service/internal/model"
+	inmemory "github.com/solumD/tasks-service/internal/repository/in_memory"
+)
+
+func TestValuesAreCopied(t *testing.T) {
+	for _, factory := range repoFactories {
+		t.Run(factory.name, func(t *testing.T) {
+			ctx := context.Background()
+			repo := factory.new()
+
+			task := &model.Task{Title: "Task1"}
+			id, err := repo.CreateTask(ctx, task)
+			if err != nil {
+				t.Fatalf("failed to create task: %v", err)
+			}
+
+			task.Title = "mutated after create"
+
+			got, _ := repo.GetTaskByID(ctx, id)
+			got.Title = "mutated after get"
+
+			all, _ := repo.GetAllTasks(ctx)
+			all[0].Title = "mutated after list"
+
+			update := &model.Task{ID: id, Title: "Task1 updated"}
+			if err := repo.UpdateTask(ctx, update); err != nil {
+				t.Fatalf("failed to update task: %v", err)
+			}
+
+			update.Title = "mutated after update"
+
+			got, _ = repo.GetTaskByID(ctx, id)
+			if got.Title != "Task1 updated" {
+				t.Fatalf("expected caller mutations not to leak into storage, got %q", got.Title)
+			}
+
+			trashed, err := repo.DeleteTask(ctx, id, 0)
+			if err != nil {
+				t.Fatalf("failed to delete task: %v", err)
+			}
+
+			deletedAt := *trashed.DeletedAt
+			*trashed.DeletedAt = time.Time{}
+
+			trash, _ := repo.GetDeletedTasks(ctx)
+			if len(trash) != 1 || !trash[0].DeletedAt.Equal(deletedAt) {
+				t.Fatalf("expected deleted_at not to be shared with caller, got %v", trash)
+			}
+		})
+	}
+}
+
+// TestConcurrentMutationOfReturnedValues запускается с -race: вызывающие свободно
+// меняют полученные задачи, пока другие горутины пишут в хранилище
+func TestConcurrentMutationOfReturnedValues(t *testing.T) {
+	for _, factory := range repoFactories {
+		t.Run(factory.name, func(t *testing.T) {
+			ctx := context.Background()
+			repo := factory.new()
+
+			for i := 0; i < stressTasks; i++ {
+				if _, err := repo.CreateTask(ctx, &model.Task{Title: "task"}); err != nil {
+					t.Fatalf("failed to create task: %v", err)
+				}
+			}
+
+			var wg sync.WaitGroup
+			for w := 0; w < stressWorkers; w++ {
+				wg.Add(1)
+
+				go func(worker int) {
+					defer wg.Done()
+
+					for i := 0; i < stressOpsPerWork; i++ {
+						id := (worker+i)%stressTasks + 1
+
+						switch i % 4 {
+						case 0:
+							tasks, _ := repo.GetAllTasks(ctx)
+							for _, task := range tasks {
+								task.Title = "mutated"
+								task.Version = -1
+							}
+						case 1:
+							if task, err := repo.GetTaskByID(ctx, id); err == nil {
+								task.Done = !task.Done
+							}
+						case 2:
+							task := &model.Task{ID: id, Title: "updated"}
+							if err := repo.UpdateTask(ctx, task); err == nil {
+								task.Title = "mutated"
+							}
+						case 3:
+							if task, err := repo.DeleteTask(ctx, id, 0); err == nil {
+								*task.DeletedAt = time.Time{}
+								repo.RestoreTask(ctx, id)
+							}
+						}
+					}
+				}(w)
+			}
+
+			wg.Wait()
+
+			tasks, _ := repo.GetAllTasks(ctx)
+			for _, task := range tasks {
+				if task.Title == "mutated" || task.Version < 1 || task.DeletedAt != nil {
+					t.Fatalf("caller mutation leaked into storage: %+v", task)
+				}
+			}
+		})
+	}
+}
+
+func TestSnapshotIsPointInTime(t *testing.T) {
+	ctx := context.Background()
+	repo := inmemory.NewTaskRepo()
+
+	for _, title := range []string{"Task1", "Task2"} {
+		if _, err := repo.CreateTask(ctx, &model.Task{Title: title}); err != nil {
+			t.Fatalf("failed to create task: %v", err)
+		}
+	}
+
+	snap := repo.Snapshot(ctx)
+	if again := repo.Snapshot(ctx); again != snap {
+		t.Fatalf("expected snapshot to be reused while there are no writes")
+	}
+
+	if err := repo.UpdateTask(ctx, &model.Task{ID: 1, Title: "Task1 updated"}); err != nil {
+		t.Fatalf("failed to update task: %v", err)
+	}
+
+	if _, err := repo.CreateTask(ctx, &model.Task{Title: "Task3"}); err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+
+	if snap.Len() != 2 {
+		t.Fatalf("expected old snapshot to keep 2 tasks, got %d", snap.Len())
+	}
+
+	for _, task := range snap.Tasks() {
+		if task.Title == "Task1 updated" {
+			t.Fatalf("expected old snapshot not to see later update")
+		}
+
+		task.Title = "mutated"
+	}
+
+	for _, task := range snap.Tasks() {
+		if task.Title == "mutated" {
+			t.Fatalf("expected snapshot to be immutable")
+		}
+	}
+
+	if fresh := repo.Snapshot(ctx); fresh == snap || fresh.Len() != 3 {
+		t.Fatalf("expected new snapshot with 3 tasks after writes, got %d", fresh.Len())
+	}
+}
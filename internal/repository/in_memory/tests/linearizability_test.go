@@ -0,0 +1,147 @@
+package tests
+
+import (
+	"errors"
+	"math"
+
+	"github.com/solumD/tasks-service/internal/usecase"
+)
+
+// Проверка линеаризуемости по алгоритму Wing & Gong: ищется такой порядок
+// операций, который не противоречит их реальному времени (операция не может
+// быть поставлена раньше той, что завершилась до ее начала) и в котором
+// последовательная модель выдает те же результаты, что и хранилище.
+// Операции над разными задачами независимы, поэтому история проверяется
+// отдельно для каждой задачи.
+
+type opKind int
+
+const (
+	opGet opKind = iota
+	opUpdate
+	opDelete
+)
+
+type opResult int
+
+const (
+	resultOK opResult = iota
+	resultNotFound
+	resultConflict
+	resultUnexpected
+)
+
+// operation одна операция над задачей с моментами вызова и возврата
+type operation struct {
+	kind      opKind
+	call, ret int64
+
+	title   string
+	version int
+
+	result     opResult
+	outTitle   string
+	outVersion int
+}
+
+// taskState состояние задачи в последовательной модели
+type taskState struct {
+	exists  bool
+	title   string
+	version int
+}
+
+func classify(err error) opResult {
+	switch {
+	case err == nil:
+		return resultOK
+	case errors.Is(err, usecase.ErrTaskNotFound):
+		return resultNotFound
+	case errors.Is(err, usecase.ErrVersionConflict):
+		return resultConflict
+	default:
+		return resultUnexpected
+	}
+}
+
+// step применяет операцию к модели и сообщает, совпадает ли результат с наблюдаемым
+func step(s taskState, op operation) (taskState, bool) {
+	if !s.exists {
+		return s, op.result == resultNotFound
+	}
+
+	switch op.kind {
+	case opGet:
+		return s, op.result == resultOK && op.outTitle == s.title && op.outVersion == s.version
+	case opUpdate:
+		if op.version != 0 && op.version != s.version {
+			return s, op.result == resultConflict
+		}
+
+		next := taskState{exists: true, title: op.title, version: s.version + 1}
+
+		return next, op.result == resultOK && op.outVersion == next.version
+	case opDelete:
+		if op.version != 0 && op.version != s.version {
+			return s, op.result == resultConflict
+		}
+
+		return taskState{}, op.result == resultOK
+	}
+
+	return s, false
+}
+
+type memoKey struct {
+	done  uint64
+	state taskState
+}
+
+// linearizable проверяет историю одной задачи (не больше 64 операций)
+func linearizable(init taskState, ops []operation) bool {
+	if len(ops) > 64 {
+		panic("history is too long")
+	}
+
+	all := uint64(1)<<len(ops) - 1
+	if len(ops) == 64 {
+		all = math.MaxUint64
+	}
+
+	failed := make(map[memoKey]bool)
+
+	var search func(done uint64, s taskState) bool
+	search = func(done uint64, s taskState) bool {
+		if done == all {
+			return true
+		}
+
+		key := memoKey{done: done, state: s}
+		if failed[key] {
+			return false
+		}
+
+		minRet := int64(math.MaxInt64)
+		for i, op := range ops {
+			if done&(1<<i) == 0 && op.ret < minRet {
+				minRet = op.ret
+			}
+		}
+
+		for i, op := range ops {
+			if done&(1<<i) != 0 || op.call > minRet {
+				continue
+			}
+
+			if next, ok := step(s, op); ok && search(done|1<<i, next) {
+				return true
+			}
+		}
+
+		failed[key] = true
+
+		return false
+	}
+
+	return search(0, init)
+}
@@ -0,0 +1,62 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/solumD/tasks-service/internal/model"
+	inmemory "github.com/solumD/tasks-service/internal/repository/in_memory"
+	"github.com/solumD/tasks-service/internal/usecase"
+)
+
+func TestRevisionRepo(t *testing.T) {
+	ctx := context.Background()
+	repo := inmemory.NewRevisionRepo()
+
+	// ревизии могут прийти не по порядку, а повторная запись игнорируется
+	writes := []struct {
+		rev   int
+		title string
+	}{
+		{rev: 2, title: "first write wins"},
+		{rev: 1, title: "Task"},
+		{rev: 3, title: "Task"},
+		{rev: 2, title: "overwritten"},
+	}
+
+	for _, w := range writes {
+		if err := repo.AddRevision(ctx, &model.Revision{TaskID: 1, Rev: w.rev, Task: model.Task{Title: w.title}}); err != nil {
+			t.Fatalf("failed to add revision: %v", err)
+		}
+	}
+
+	revisions, err := repo.GetRevisions(ctx, 1)
+	if err != nil || len(revisions) != 3 {
+		t.Fatalf("expected 3 revisions, got %d (err %v)", len(revisions), err)
+	}
+
+	for i, rev := range revisions {
+		if rev.Rev != i+1 {
+			t.Fatalf("expected revisions sorted by rev, got %d at position %d", rev.Rev, i)
+		}
+	}
+
+	rev, err := repo.GetRevision(ctx, 1, 2)
+	if err != nil || rev.Task.Title != "first write wins" {
+		t.Fatalf("expected first written revision 2, got %+v (err %v)", rev, err)
+	}
+
+	rev.Task.Title = "mutated"
+	if again, _ := repo.GetRevision(ctx, 1, 2); again.Task.Title != "first write wins" {
+		t.Fatalf("expected stored revision to be immutable, got %q", again.Task.Title)
+	}
+
+	if _, err := repo.GetRevision(ctx, 1, 4); !errors.Is(err, usecase.ErrRevisionNotFound) {
+		t.Fatalf("expected ErrRevisionNotFound, got %v", err)
+	}
+
+	if revisions, _ := repo.GetRevisions(ctx, 2); len(revisions) != 0 {
+		t.Fatalf("expected no revisions for unknown task, got %d", len(revisions))
+	}
+}
@@ -0,0 +1,229 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/solumD/tasks-service/internal/model"
+	inmemory "github.com/solumD/tasks-service/internal/repository/in_memory"
+	"github.com/solumD/tasks-service/internal/usecase"
+)
+
+const (
+	stressRounds     = 20
+	stressTasks      = 8
+	stressWorkers    = 8
+	stressOpsPerWork = 40
+)
+
+// repoFactories реализации хранилища в памяти, которые проверяются одинаково
+var repoFactories = []struct {
+	name string
+	new  func() usecase.TaskRepo
+}{
+	{
+		name: "single lock",
+		new: func() usecase.TaskRepo {
+			return inmemory.NewTaskRepo()
+		},
+	},
+	{
+		name: "sharded",
+		new: func() usecase.TaskRepo {
+			repo, _ := inmemory.NewShardedTaskRepo(16)
+			return repo
+		},
+	},
+}
+
+func TestLinearizableUnderParallelCRUD(t *testing.T) {
+	for _, factory := range repoFactories {
+		t.Run(factory.name, func(t *testing.T) {
+			testLinearizableUnderParallelCRUD(t, factory.new)
+		})
+	}
+}
+
+func testLinearizableUnderParallelCRUD(t *testing.T, newRepo func() usecase.TaskRepo) {
+	for round := 0; round < stressRounds; round++ {
+		ctx := context.Background()
+		repo := newRepo()
+
+		initial := make(map[int]taskState, stressTasks)
+		for i := 0; i < stressTasks; i++ {
+			task := &model.Task{Title: fmt.Sprintf("initial-%d", i)}
+			id, err := repo.CreateTask(ctx, task)
+			if err != nil {
+				t.Fatalf("failed to create task: %v", err)
+			}
+
+			initial[id] = taskState{exists: true, title: task.Title, version: task.Version}
+		}
+
+		var (
+			clock   atomic.Int64
+			mu      sync.Mutex
+			history = make(map[int][]operation, stressTasks)
+			wg      sync.WaitGroup
+		)
+
+		for w := 0; w < stressWorkers; w++ {
+			wg.Add(1)
+
+			go func(worker int) {
+				defer wg.Done()
+
+				rnd := rand.New(rand.NewSource(int64(round*stressWorkers + worker)))
+				seen := make(map[int]int)
+
+				for i := 0; i < stressOpsPerWork; i++ {
+					id := rnd.Intn(stressTasks) + 1
+					op := operation{}
+
+					// ожидаемая версия: любая, последняя увиденная или заведомо случайная
+					switch rnd.Intn(3) {
+					case 1:
+						op.version = seen[id]
+					case 2:
+						op.version = rnd.Intn(4) + 1
+					}
+
+					switch n := rnd.Intn(20); {
+					case n < 10:
+						op.kind = opGet
+						op.version = 0
+						op.call = clock.Add(1)
+						task, err := repo.GetTaskByID(ctx, id)
+						op.ret = clock.Add(1)
+
+						op.result = classify(err)
+						if err == nil {
+							op.outTitle = task.Title
+							op.outVersion = task.Version
+							seen[id] = task.Version
+						}
+					case n < 18:
+						op.kind = opUpdate
+						op.title = fmt.Sprintf("w%d-op%d", worker, i)
+						task := &model.Task{ID: id, Title: op.title, Version: op.version}
+
+						op.call = clock.Add(1)
+						err := repo.UpdateTask(ctx, task)
+						op.ret = clock.Add(1)
+
+						op.result = classify(err)
+						if err == nil {
+							op.outVersion = task.Version
+							seen[id] = task.Version
+						}
+					default:
+						op.kind = opDelete
+						op.call = clock.Add(1)
+						_, err := repo.DeleteTask(ctx, id, op.version)
+						op.ret = clock.Add(1)
+
+						op.result = classify(err)
+					}
+
+					mu.Lock()
+					history[id] = append(history[id], op)
+					mu.Unlock()
+				}
+			}(w)
+		}
+
+		wg.Wait()
+
+		for id, ops := range history {
+			for _, op := range ops {
+				if op.result == resultUnexpected {
+					t.Fatalf("round %d: unexpected error kind for task %d: %+v", round, id, op)
+				}
+			}
+
+			if !linearizable(initial[id], ops) {
+				t.Fatalf("round %d: history of task %d is not linearizable: %+v", round, id, ops)
+			}
+		}
+	}
+}
+
+func TestParallelCreateAssignsUniqueIDs(t *testing.T) {
+	for _, factory := range repoFactories {
+		t.Run(factory.name, func(t *testing.T) {
+			testParallelCreateAssignsUniqueIDs(t, factory.new())
+		})
+	}
+}
+
+func testParallelCreateAssignsUniqueIDs(t *testing.T, repo usecase.TaskRepo) {
+	ctx := context.Background()
+
+	const total = stressWorkers * stressOpsPerWork
+
+	ids := make(chan int, total)
+
+	var wg sync.WaitGroup
+	for w := 0; w < stressWorkers; w++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for i := 0; i < stressOpsPerWork; i++ {
+				id, err := repo.CreateTask(ctx, &model.Task{Title: "task"})
+				if err != nil {
+					t.Errorf("failed to create task: %v", err)
+					return
+				}
+
+				ids <- id
+			}
+		}()
+	}
+
+	wg.Wait()
+	close(ids)
+
+	seen := make(map[int]bool, total)
+	for id := range ids {
+		if seen[id] {
+			t.Fatalf("id %d assigned twice", id)
+		}
+
+		seen[id] = true
+	}
+
+	for id := 1; id <= total; id++ {
+		if !seen[id] {
+			t.Fatalf("expected ids 1..%d without gaps, %d is missing", total, id)
+		}
+	}
+
+	tasks, err := repo.GetAllTasks(ctx)
+	if err != nil || len(tasks) != total {
+		t.Fatalf("expected %d tasks, got %d (err %v)", total, len(tasks), err)
+	}
+}
+
+func TestCheckerRejectsStaleRead(t *testing.T) {
+	// запись завершилась до начала чтения, но чтение вернуло старое значение
+	ops := []operation{
+		{kind: opUpdate, call: 1, ret: 2, title: "new", result: resultOK, outVersion: 2},
+		{kind: opGet, call: 3, ret: 4, result: resultOK, outTitle: "old", outVersion: 1},
+	}
+
+	if linearizable(taskState{exists: true, title: "old", version: 1}, ops) {
+		t.Fatalf("expected stale read to be rejected")
+	}
+
+	// те же операции, но пересекающиеся по времени, линеаризуемы
+	ops[0].ret, ops[1].call = 3, 2
+	if !linearizable(taskState{exists: true, title: "old", version: 1}, ops) {
+		t.Fatalf("expected concurrent read of old value to be accepted")
+	}
+}
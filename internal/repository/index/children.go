@@ -0,0 +1,56 @@
+package index
+
+// Children индекс подзадач: для каждой родительской задачи множество ID ее подзадач.
+// Индекс не потокобезопасен, его защищает блокировка хранилища
+type Children struct {
+	byParent map[int]map[int]struct{}
+	parentOf map[int]int
+}
+
+// NewChildren возвращает пустой индекс подзадач
+func NewChildren() *Children {
+	return &Children{
+		byParent: make(map[int]map[int]struct{}),
+		parentOf: make(map[int]int),
+	}
+}
+
+// Put записывает родителя задачи id, parentID 0 убирает задачу из индекса
+func (x *Children) Put(id int, parentID int) {
+	x.Remove(id)
+
+	if parentID == 0 {
+		return
+	}
+
+	ids, ok := x.byParent[parentID]
+	if !ok {
+		ids = make(map[int]struct{})
+		x.byParent[parentID] = ids
+	}
+
+	ids[id] = struct{}{}
+	x.parentOf[id] = parentID
+}
+
+// Remove убирает задачу id из индекса. Подзадачи задачи id остаются в индексе
+func (x *Children) Remove(id int) {
+	parentID, ok := x.parentOf[id]
+	if !ok {
+		return
+	}
+
+	ids := x.byParent[parentID]
+	delete(ids, id)
+
+	if len(ids) == 0 {
+		delete(x.byParent, parentID)
+	}
+
+	delete(x.parentOf, id)
+}
+
+// IDs возвращает ID подзадач задачи parentID по возрастанию
+func (x *Children) IDs(parentID int) []int {
+	return sortedIDs(x.byParent[parentID])
+}
@@ -0,0 +1,98 @@
+package index
+
+import (
+	"sort"
+
+	"github.com/solumD/tasks-service/internal/model"
+)
+
+// Comments индекс комментариев: для каждой задачи ID ее комментариев верхнего уровня,
+// для каждого комментария - ID ответов на него, те и другие по возрастанию.
+// Страница комментариев находится двоичным поиском и не обходит остальные комментарии.
+// Индекс не потокобезопасен, его защищает блокировка хранилища
+type Comments struct {
+	threads map[int][]int
+	replies map[int][]int
+}
+
+// NewComments возвращает пустой индекс комментариев
+func NewComments() *Comments {
+	return &Comments{
+		threads: make(map[int][]int),
+		replies: make(map[int][]int),
+	}
+}
+
+// Put добавляет комментарий в индекс. Задача и родитель комментария не меняются,
+// поэтому повторный Put ничего не делает
+func (x *Comments) Put(comment *model.Comment) {
+	if comment.IsReply() {
+		x.replies[comment.ParentID] = insertID(x.replies[comment.ParentID], comment.ID)
+		return
+	}
+
+	x.threads[comment.TaskID] = insertID(x.threads[comment.TaskID], comment.ID)
+}
+
+// Remove убирает комментарий из индекса. Ответы на комментарий остаются в индексе
+func (x *Comments) Remove(comment *model.Comment) {
+	if comment.IsReply() {
+		removeID(x.replies, comment.ParentID, comment.ID)
+		return
+	}
+
+	removeID(x.threads, comment.TaskID, comment.ID)
+}
+
+// Threads возвращает не больше limit ID комментариев верхнего уровня задачи taskID,
+// которые больше after, по возрастанию
+func (x *Comments) Threads(taskID int, after int, limit int) []int {
+	ids := x.threads[taskID]
+
+	i := sort.SearchInts(ids, after+1)
+	j := min(len(ids), i+limit)
+
+	return append(make([]int, 0, j-i), ids[i:j]...)
+}
+
+// AllThreads возвращает ID всех комментариев верхнего уровня задачи taskID по возрастанию
+func (x *Comments) AllThreads(taskID int) []int {
+	return append(make([]int, 0, len(x.threads[taskID])), x.threads[taskID]...)
+}
+
+// Replies возвращает ID ответов на комментарий parentID по возрастанию
+func (x *Comments) Replies(parentID int) []int {
+	return append(make([]int, 0, len(x.replies[parentID])), x.replies[parentID]...)
+}
+
+// insertID вставляет id в упорядоченный список ids без повторов
+func insertID(ids []int, id int) []int {
+	i := sort.SearchInts(ids, id)
+	if i < len(ids) && ids[i] == id {
+		return ids
+	}
+
+	ids = append(ids, 0)
+	copy(ids[i+1:], ids[i:])
+	ids[i] = id
+
+	return ids
+}
+
+// removeID убирает id из упорядоченного списка lists[key]
+func removeID(lists map[int][]int, key int, id int) {
+	ids := lists[key]
+
+	i := sort.SearchInts(ids, id)
+	if i == len(ids) || ids[i] != id {
+		return
+	}
+
+	ids = append(ids[:i], ids[i+1:]...)
+	if len(ids) == 0 {
+		delete(lists, key)
+		return
+	}
+
+	lists[key] = ids
+}
@@ -0,0 +1,99 @@
+package index
+
+import (
+	"sort"
+	"time"
+)
+
+type dueEntry struct {
+	at time.Time
+	id int
+}
+
+// Due индекс задач по сроку выполнения: ID задач, упорядоченные по сроку и ID.
+// Выборка диапазона сроков находит границы двоичным поиском и не обходит остальные задачи.
+// Индекс не потокобезопасен, его защищает блокировка хранилища
+type Due struct {
+	entries []dueEntry
+	byID    map[int]time.Time
+}
+
+// NewDue возвращает пустой индекс сроков
+func NewDue() *Due {
+	return &Due{
+		byID: make(map[int]time.Time),
+	}
+}
+
+// Put записывает срок задачи id, due равный nil убирает задачу из индекса
+func (x *Due) Put(id int, due *time.Time) {
+	x.Remove(id)
+
+	if due == nil {
+		return
+	}
+
+	entry := dueEntry{at: due.UTC(), id: id}
+
+	i := x.search(entry)
+	x.entries = append(x.entries, dueEntry{})
+	copy(x.entries[i+1:], x.entries[i:])
+	x.entries[i] = entry
+
+	x.byID[id] = entry.at
+}
+
+// Remove убирает задачу id из индекса
+func (x *Due) Remove(id int) {
+	at, ok := x.byID[id]
+	if !ok {
+		return
+	}
+
+	i := x.search(dueEntry{at: at, id: id})
+	x.entries = append(x.entries[:i], x.entries[i+1:]...)
+
+	delete(x.byID, id)
+}
+
+// Range возвращает ID задач со сроком не раньше from и раньше to в порядке срока.
+// Нулевое from или to означает отсутствие границы
+func (x *Due) Range(from, to time.Time) []int {
+	start := 0
+	if !from.IsZero() {
+		start = sort.Search(len(x.entries), func(i int) bool {
+			return !x.entries[i].at.Before(from)
+		})
+	}
+
+	end := len(x.entries)
+	if !to.IsZero() {
+		end = sort.Search(len(x.entries), func(i int) bool {
+			return !x.entries[i].at.Before(to)
+		})
+	}
+
+	ids := make([]int, 0, max(end-start, 0))
+	for i := start; i < end; i++ {
+		ids = append(ids, x.entries[i].id)
+	}
+
+	return ids
+}
+
+// Len возвращает количество задач в индексе
+func (x *Due) Len() int {
+	return len(x.entries)
+}
+
+// search возвращает позицию entry в упорядоченном списке
+func (x *Due) search(entry dueEntry) int {
+	return sort.Search(len(x.entries), func(i int) bool {
+		e := x.entries[i]
+		if !e.at.Equal(entry.at) {
+			return e.at.After(entry.at)
+		}
+
+		return e.id >= entry.id
+	})
+}
@@ -0,0 +1,136 @@
+package index
+
+import (
+	"sort"
+
+	"github.com/solumD/tasks-service/internal/model"
+)
+
+// Tags индекс задач по меткам: для каждой метки множество ID задач с ней.
+// Индекс не потокобезопасен, его защищает блокировка хранилища
+type Tags struct {
+	byTag map[string]map[int]struct{}
+	byID  map[int][]string
+}
+
+// NewTags возвращает пустой индекс меток
+func NewTags() *Tags {
+	return &Tags{
+		byTag: make(map[string]map[int]struct{}),
+		byID:  make(map[int][]string),
+	}
+}
+
+// Put записывает метки задачи id, пустой список убирает задачу из индекса
+func (x *Tags) Put(id int, tags []string) {
+	x.Remove(id)
+
+	if len(tags) == 0 {
+		return
+	}
+
+	for _, tag := range tags {
+		ids, ok := x.byTag[tag]
+		if !ok {
+			ids = make(map[int]struct{})
+			x.byTag[tag] = ids
+		}
+
+		ids[id] = struct{}{}
+	}
+
+	x.byID[id] = append([]string(nil), tags...)
+}
+
+// Remove убирает задачу id из индекса
+func (x *Tags) Remove(id int) {
+	for _, tag := range x.byID[id] {
+		ids := x.byTag[tag]
+		delete(ids, id)
+
+		if len(ids) == 0 {
+			delete(x.byTag, tag)
+		}
+	}
+
+	delete(x.byID, id)
+}
+
+// IDs возвращает ID задач с меткой tag по возрастанию
+func (x *Tags) IDs(tag string) []int {
+	ids := make([]int, 0, len(x.byTag[tag]))
+	for id := range x.byTag[tag] {
+		ids = append(ids, id)
+	}
+
+	sort.Ints(ids)
+
+	return ids
+}
+
+// Match возвращает по возрастанию ID задач хотя бы с одной из меток tags,
+// а при all - со всеми метками
+func (x *Tags) Match(tags []string, all bool) []int {
+	if len(tags) == 0 {
+		return []int{}
+	}
+
+	if !all {
+		seen := make(map[int]struct{})
+		for _, tag := range tags {
+			for id := range x.byTag[tag] {
+				seen[id] = struct{}{}
+			}
+		}
+
+		return sortedIDs(seen)
+	}
+
+	// пересечение строится от самой редкой метки
+	smallest := x.byTag[tags[0]]
+	for _, tag := range tags[1:] {
+		if len(x.byTag[tag]) < len(smallest) {
+			smallest = x.byTag[tag]
+		}
+	}
+
+	matched := make(map[int]struct{}, len(smallest))
+
+next:
+	for id := range smallest {
+		for _, tag := range tags {
+			if _, ok := x.byTag[tag][id]; !ok {
+				continue next
+			}
+		}
+
+		matched[id] = struct{}{}
+	}
+
+	return sortedIDs(matched)
+}
+
+// Counts возвращает метки по возрастанию с количеством задач у каждой
+func (x *Tags) Counts() []model.TagCount {
+	counts := make([]model.TagCount, 0, len(x.byTag))
+	for tag, ids := range x.byTag {
+		counts = append(counts, model.TagCount{Tag: tag, Count: len(ids)})
+	}
+
+	sort.Slice(counts, func(i, j int) bool {
+		return counts[i].Tag < counts[j].Tag
+	})
+
+	return counts
+}
+
+func sortedIDs(set map[int]struct{}) []int {
+	ids := make([]int, 0, len(set))
+	for id := range set {
+		ids = append(ids, id)
+	}
+
+	sort.Ints(ids)
+
+	return ids
+}
@@ -0,0 +1,41 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/solumD/tasks-service/internal/repository/index"
+)
+
+func TestChildren(t *testing.T) {
+	children := index.NewChildren()
+	children.Put(3, 1)
+	children.Put(2, 1)
+	children.Put(4, 2)
+	children.Put(5, 0)
+
+	if ids := children.IDs(1); !equalIDs(ids, []int{2, 3}) {
+		t.Fatalf("expected children [2 3], got %v", ids)
+	}
+
+	// перенос задачи меняет обоих родителей
+	children.Put(3, 2)
+
+	if ids := children.IDs(1); !equalIDs(ids, []int{2}) {
+		t.Fatalf("expected children [2] after move, got %v", ids)
+	}
+
+	if ids := children.IDs(2); !equalIDs(ids, []int{3, 4}) {
+		t.Fatalf("expected children [3 4] after move, got %v", ids)
+	}
+
+	children.Remove(4)
+	children.Put(3, 0)
+
+	if ids := children.IDs(2); !equalIDs(ids, []int{}) {
+		t.Fatalf("expected no children, got %v", ids)
+	}
+
+	if ids := children.IDs(5); !equalIDs(ids, []int{}) {
+		t.Fatalf("expected no children of top level task, got %v", ids)
+	}
+}
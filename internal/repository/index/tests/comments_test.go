@@ -0,0 +1,60 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/solumD/tasks-service/internal/model"
+	"github.com/solumD/tasks-service/internal/repository/index"
+)
+
+func TestComments(t *testing.T) {
+	comments := index.NewComments()
+
+	first := &model.Comment{ID: 1, TaskID: 7}
+	reply := &model.Comment{ID: 2, TaskID: 7, ParentID: 1}
+	other := &model.Comment{ID: 3, TaskID: 8}
+	second := &model.Comment{ID: 4, TaskID: 7}
+	third := &model.Comment{ID: 5, TaskID: 7}
+
+	for _, comment := range []*model.Comment{third, reply, first, other, second, first} {
+		comments.Put(comment)
+	}
+
+	if ids := comments.Threads(7, 0, 10); !equalIDs(ids, []int{1, 4, 5}) {
+		t.Fatalf("expected threads [1 4 5], got %v", ids)
+	}
+
+	if ids := comments.Threads(7, 1, 1); !equalIDs(ids, []int{4}) {
+		t.Fatalf("expected page [4], got %v", ids)
+	}
+
+	if ids := comments.Threads(7, 5, 10); !equalIDs(ids, []int{}) {
+		t.Fatalf("expected empty page, got %v", ids)
+	}
+
+	if ids := comments.Replies(1); !equalIDs(ids, []int{2}) {
+		t.Fatalf("expected replies [2], got %v", ids)
+	}
+
+	// удаление комментария верхнего уровня не трогает ответы на него
+	comments.Remove(first)
+	comments.Remove(other)
+
+	if ids := comments.AllThreads(7); !equalIDs(ids, []int{4, 5}) {
+		t.Fatalf("expected threads [4 5] after remove, got %v", ids)
+	}
+
+	if ids := comments.AllThreads(8); !equalIDs(ids, []int{}) {
+		t.Fatalf("expected no threads, got %v", ids)
+	}
+
+	if ids := comments.Replies(1); !equalIDs(ids, []int{2}) {
+		t.Fatalf("expected replies [2] after parent remove, got %v", ids)
+	}
+
+	comments.Remove(reply)
+
+	if ids := comments.Replies(1); !equalIDs(ids, []int{}) {
+		t.Fatalf("expected no replies, got %v", ids)
+	}
+}
@@ -0,0 +1,84 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/solumD/tasks-service/internal/repository/index"
+)
+
+func equalIDs(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func TestDueRange(t *testing.T) {
+	base := time.Date(2026, time.March, 1, 12, 0, 0, 0, time.UTC)
+	at := func(hours int) *time.Time {
+		t := base.Add(time.Duration(hours) * time.Hour).In(time.FixedZone("", 3*60*60))
+		return &t
+	}
+
+	due := index.NewDue()
+	due.Put(3, at(2))
+	due.Put(1, at(2))
+	due.Put(2, at(-1))
+	due.Put(4, at(5))
+	due.Put(5, nil)
+
+	tests := []struct {
+		name     string
+		from, to time.Time
+		expected []int
+	}{
+		{name: "unbounded", expected: []int{2, 1, 3, 4}},
+		{name: "from inclusive", from: *at(2), expected: []int{1, 3, 4}},
+		{name: "to exclusive", to: *at(2), expected: []int{2}},
+		{name: "bounded", from: base, to: *at(3), expected: []int{1, 3}},
+		{name: "empty", from: *at(6), expected: []int{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if ids := due.Range(tt.from, tt.to); !equalIDs(ids, tt.expected) {
+				t.Fatalf("expected %v, got %v", tt.expected, ids)
+			}
+		})
+	}
+}
+
+func TestDuePutReplacesAndRemoves(t *testing.T) {
+	base := time.Date(2026, time.March, 1, 12, 0, 0, 0, time.UTC)
+	later := base.Add(time.Hour)
+
+	due := index.NewDue()
+	due.Put(1, &base)
+	due.Put(2, &base)
+
+	// новый срок заменяет старый
+	due.Put(1, &later)
+	if ids := due.Range(time.Time{}, time.Time{}); !equalIDs(ids, []int{2, 1}) {
+		t.Fatalf("expected [2 1] after reschedule, got %v", ids)
+	}
+
+	// nil снимает срок
+	due.Put(2, nil)
+	due.Remove(3)
+	if ids := due.Range(time.Time{}, time.Time{}); !equalIDs(ids, []int{1}) || due.Len() != 1 {
+		t.Fatalf("expected only task 1, got %v", ids)
+	}
+
+	due.Remove(1)
+	if due.Len() != 0 {
+		t.Fatalf("expected empty index, got %d entries", due.Len())
+	}
+}
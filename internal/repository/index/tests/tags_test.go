@@ -0,0 +1,71 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/solumD/tasks-service/internal/model"
+	"github.com/solumD/tasks-service/internal/repository/index"
+)
+
+func TestTagsMatch(t *testing.T) {
+	tags := index.NewTags()
+	tags.Put(3, []string{"home", "urgent"})
+	tags.Put(1, []string{"work"})
+	tags.Put(2, []string{"home", "work"})
+	tags.Put(4, nil)
+
+	tests := []struct {
+		name     string
+		tags     []string
+		all      bool
+		expected []int
+	}{
+		{name: "any", tags: []string{"work", "urgent"}, expected: []int{1, 2, 3}},
+		{name: "all", tags: []string{"home", "work"}, all: true, expected: []int{2}},
+		{name: "all with unknown tag", tags: []string{"home", "gym"}, all: true, expected: []int{}},
+		{name: "unknown tag", tags: []string{"gym"}, expected: []int{}},
+		{name: "no tags", expected: []int{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if ids := tags.Match(tt.tags, tt.all); !equalIDs(ids, tt.expected) {
+				t.Fatalf("expected %v, got %v", tt.expected, ids)
+			}
+		})
+	}
+}
+
+func TestTagsPutReplacesAndCounts(t *testing.T) {
+	tags := index.NewTags()
+	tags.Put(1, []string{"home", "work"})
+	tags.Put(2, []string{"work"})
+
+	// новые метки заменяют старые
+	tags.Put(1, []string{"gym"})
+	tags.Remove(3)
+
+	expected := []model.TagCount{{Tag: "gym", Count: 1}, {Tag: "work", Count: 1}}
+	counts := tags.Counts()
+
+	if len(counts) != len(expected) {
+		t.Fatalf("expected counts %v, got %v", expected, counts)
+	}
+
+	for i := range expected {
+		if counts[i] != expected[i] {
+			t.Fatalf("expected counts %v, got %v", expected, counts)
+		}
+	}
+
+	if ids := tags.IDs("work"); !equalIDs(ids, []int{2}) {
+		t.Fatalf("expected only task 2 with tag work, got %v", ids)
+	}
+
+	tags.Remove(2)
+	tags.Put(1, nil)
+
+	if counts := tags.Counts(); len(counts) != 0 {
+		t.Fatalf("expected empty index, got %v", counts)
+	}
+}
@@ -0,0 +1,145 @@
+// Package persistent открывает постоянные хранилища задач для служебных команд
+package persistent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/solumD/tasks-service/internal/backup"
+	"github.com/solumD/tasks-service/internal/config"
+	"github.com/solumD/tasks-service/internal/migrate"
+	"github.com/solumD/tasks-service/internal/repository/eventsourced"
+	filerepo "github.com/solumD/tasks-service/internal/repository/file"
+	sqlrepo "github.com/solumD/tasks-service/internal/repository/sql"
+)
+
+// compactEvery порог снапшота файлового хранилища, при закрытии снапшот делается в любом случае
+const compactEvery = 1000
+
+// Repo открытое постоянное хранилище задач
+type Repo interface {
+	migrate.Repo
+	backup.Store
+	io.Closer
+}
+
+// ProjectRepo открытое постоянное хранилище проектов
+type ProjectRepo interface {
+	migrate.ProjectRepo
+	backup.ProjectStore
+}
+
+// Storage открытые хранилища задач, проектов и комментариев одного постоянного хранилища
+type Storage struct {
+	Tasks    Repo
+	Projects ProjectRepo
+	Comments migrate.CommentRepo
+
+	closers []io.Closer
+}
+
+// Migrate возвращает хранилища для переноса данных
+func (s *Storage) Migrate() migrate.Storage {
+	return migrate.Storage{Tasks: s.Tasks, Projects: s.Projects, Comments: s.Comments}
+}
+
+// Close закрывает все хранилища в порядке, обратном открытию
+func (s *Storage) Close() error {
+	var errs []error
+	for i := len(s.closers) - 1; i >= 0; i-- {
+		errs = append(errs, s.closers[i].Close())
+	}
+
+	return errors.Join(errs...)
+}
+
+// Open открывает хранилище типа storageType. location - директория для file,
+// строка подключения для sql и файл журнала событий для eventsourced. Проекты
+// и комментарии хранятся так же, как в сервисе: в той же базе для sql и в файлах
+// проектов и комментариев рядом с задачами для file и eventsourced. Хранилища в памяти живут только
+// внутри процесса сервиса, поэтому открыть их нельзя
+func Open(ctx context.Context, storageType, location, sqlDriver string) (*Storage, error) {
+	if location == "" {
+		return nil, fmt.Errorf("location of %q storage is empty", storageType)
+	}
+
+	switch storageType {
+	case config.StorageFile:
+		taskRepo, err := filerepo.NewTaskRepo(location, compactEvery)
+		if err != nil {
+			return nil, err
+		}
+
+		projectRepo, err := filerepo.NewProjectRepo(filepath.Join(location, filerepo.ProjectsFileName))
+		if err != nil {
+			taskRepo.Close()
+			return nil, err
+		}
+
+		commentRepo, err := filerepo.NewCommentRepo(filepath.Join(location, filerepo.CommentsFileName))
+		if err != nil {
+			projectRepo.Close()
+			taskRepo.Close()
+			return nil, err
+		}
+
+		return &Storage{
+			Tasks:    taskRepo,
+			Projects: projectRepo,
+			Comments: commentRepo,
+			closers:  []io.Closer{taskRepo, projectRepo, commentRepo},
+		}, nil
+	case config.StorageSQL:
+		taskRepo, err := sqlrepo.NewTaskRepo(ctx, sqlDriver, location)
+		if err != nil {
+			return nil, err
+		}
+
+		return &Storage{
+			Tasks:    taskRepo,
+			Projects: taskRepo.ProjectRepo(),
+			Comments: taskRepo.CommentRepo(),
+			closers:  []io.Closer{taskRepo},
+		}, nil
+	case config.StorageEventSourced:
+		store, err := eventsourced.NewFileStore(location)
+		if err != nil {
+			return nil, err
+		}
+
+		taskRepo, err := eventsourced.NewTaskRepo(ctx, store)
+		if err != nil {
+			store.Close()
+			return nil, err
+		}
+
+		projectRepo, err := filerepo.NewProjectRepo(filepath.Join(filepath.Dir(location), filerepo.ProjectsFileName))
+		if err != nil {
+			taskRepo.Close()
+			return nil, err
+		}
+
+		commentRepo, err := filerepo.NewCommentRepo(filepath.Join(filepath.Dir(location), filerepo.CommentsFileName))
+		if err != nil {
+			projectRepo.Close()
+			taskRepo.Close()
+			return nil, err
+		}
+
+		return &Storage{
+			Tasks:    taskRepo,
+			Projects: projectRepo,
+			Comments: commentRepo,
+			closers:  []io.Closer{taskRepo, projectRepo, commentRepo},
+		}, nil
+	case config.StorageInMemory, config.StorageInMemorySharded:
+		return nil, fmt.Errorf("%q storage is not persistent and cannot be opened by a separate process", storageType)
+	case config.StorageRaft:
+		return nil, fmt.Errorf("%q storage is owned by the cluster node and cannot be opened by a separate process", storageType)
+	default:
+		return nil, fmt.Errorf("unknown storage type: %q", storageType)
+	}
+}
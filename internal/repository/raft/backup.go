@@ -0,0 +1,34 @@
+package raft
+
+import (
+	"context"
+
+	"github.com/solumD/tasks-service/internal/model"
+)
+
+// DumpTasks возвращает копии всех задач, включая корзину, и ID следующей задачи
+// на один момент времени
+func (r *taskRepo) DumpTasks(ctx context.Context) ([]*model.Task, int, error) {
+	if err := r.barrier(ctx); err != nil {
+		return nil, 0, err
+	}
+
+	r.fsm.mu.RLock()
+	defer r.fsm.mu.RUnlock()
+
+	tasks := make([]*model.Task, 0, len(r.fsm.tasks))
+
+	for _, task := range r.fsm.tasks {
+		tasks = append(tasks, task.Clone())
+	}
+
+	return tasks, r.fsm.idCounter + 1, nil
+}
+
+// ReplaceTasks заменяет все задачи, включая корзину, на tasks одной записью журнала.
+// Счетчик ID не уменьшается, а следующая задача получит ID не меньше nextID
+func (r *taskRepo) ReplaceTasks(ctx context.Context, tasks []*model.Task, nextID int) error {
+	_, err := r.apply(ctx, &command{Op: opReplace, Tasks: tasks, NextID: nextID})
+
+	return err
+}
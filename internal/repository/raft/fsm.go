@@ -0,0 +1,364 @@
+package raft
+
+import (
+	"encoding/json"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/solumD/tasks-service/internal/model"
+	"github.com/solumD/tasks-service/internal/repository/index"
+)
+
+const (
+	opCreate   = "create"
+	opUpdate   = "update"
+	opDelete   = "delete"
+	opRestore  = "restore"
+	opPurge    = "purge"
+	opImport   = "import"
+	opReplace  = "replace"
+	opRevision = "revision"
+	opRetag    = "retag"
+
+	codeNotFound        = "not_found"
+	codeVersionConflict = "version_conflict"
+)
+
+// command изменение хранилища, которое реплицирует кластер. Все значения, зависящие
+// от узла, например время удаления, выбирает узел, предложивший команду, поэтому
+// применение команды детерминировано
+type command struct {
+	Op       string          `json:"op"`
+	ID       int             `json:"id,omitempty"`
+	Version  int             `json:"version,omitempty"`
+	At       time.Time       `json:"at,omitzero"`
+	Task     *model.Task     `json:"task,omitempty"`
+	Tasks    []*model.Task   `json:"tasks,omitempty"`
+	NextID   int             `json:"next_id,omitempty"`
+	Revision *model.Revision `json:"revision,omitempty"`
+	// From и To метки для opRetag
+	From string `json:"from,omitempty"`
+	To   string `json:"to,omitempty"`
+	// Author автор изменения для потока изменений
+	Author string `json:"author,omitempty"`
+}
+
+// result результат применения команды, Code - причина отказа
+type result struct {
+	Code  string      `json:"code,omitempty"`
+	Task  *model.Task `json:"task,omitempty"`
+	Count int         `json:"count,omitempty"`
+	// Tasks задачи, измененные командой над несколькими задачами
+	Tasks []*model.Task `json:"tasks,omitempty"`
+}
+
+// snapshot состояние автомата. Задачи из корзины хранятся вместе с остальными
+// и отличаются заполненным DeletedAt
+type snapshot struct {
+	IDCounter int               `json:"id_counter"`
+	Tasks     []*model.Task     `json:"tasks"`
+	Revisions []*model.Revision `json:"revisions"`
+	Position  int               `json:"position,omitempty"`
+}
+
+// ChangeSink получатель событий потока изменений, которые создает автомат. Record
+// вызывается после применения каждой команды в порядке применения
+type ChangeSink interface {
+	Record(events []*model.ChangeEvent)
+}
+
+// fsm задачи и ревизии одного узла, изменяются только командами из журнала кластера
+type fsm struct {
+	tasks map[int]*model.Task
+	// due, tags и children индексы сроков, меток и подзадач задач не из корзины
+	due       *index.Due
+	tags      *index.Tags
+	children  *index.Children
+	revisions map[int][]*model.Revision
+	idCounter int
+	// position номер последнего изменения задач, одинаковый на всех узлах
+	position int
+	changes  ChangeSink
+
+	mu *sync.RWMutex
+}
+
+func newFSM(changes ChangeSink) *fsm {
+	return &fsm{
+		tasks:     make(map[int]*model.Task),
+		due:       index.NewDue(),
+		tags:      index.NewTags(),
+		children:  index.NewChildren(),
+		revisions: make(map[int][]*model.Revision),
+		changes:   changes,
+		mu:        &sync.RWMutex{},
+	}
+}
+
+// Apply применяет команду и передает ее события в поток изменений.
+// Некорректная команда не меняет состояние
+func (f *fsm) Apply(data []byte) []byte {
+	var cmd command
+	if err := json.Unmarshal(data, &cmd); err != nil {
+		return nil
+	}
+
+	f.mu.Lock()
+	res := f.apply(&cmd)
+	events := f.events(&cmd, res)
+	f.mu.Unlock()
+
+	if len(events) > 0 {
+		f.changes.Record(events)
+	}
+
+	out, _ := json.Marshal(res)
+
+	return out
+}
+
+// events возвращает события потока изменений примененной команды и присваивает
+// им номера изменений. Вызывается под mu
+func (f *fsm) events(cmd *command, res *result) []*model.ChangeEvent {
+	if res.Code != "" {
+		return nil
+	}
+
+	var events []*model.ChangeEvent
+
+	add := func(typ model.ChangeType, task *model.Task) {
+		f.position++
+
+		event := &model.ChangeEvent{Type: typ, Author: cmd.Author, Position: f.position}
+		if task != nil {
+			event.TaskID = task.ID
+			event.Task = *task.Clone()
+		}
+
+		events = append(events, event)
+	}
+
+	switch cmd.Op {
+	case opCreate:
+		add(model.ChangeCreated, res.Task)
+	case opUpdate, opRestore:
+		add(model.ChangeUpdated, res.Task)
+	case opDelete:
+		add(model.ChangeDeleted, res.Task)
+	case opRetag:
+		for _, task := range res.Tasks {
+			add(model.ChangeUpdated, task)
+		}
+	case opReplace:
+		add(model.ChangeReset, nil)
+	}
+
+	return events
+}
+
+func (f *fsm) apply(cmd *command) *result {
+	switch cmd.Op {
+	case opCreate:
+		f.idCounter++
+
+		task := cmd.Task.Clone()
+		task.ID = f.idCounter
+		task.Version = 1
+		task.DeletedAt = nil
+		f.put(task)
+
+		return &result{Task: task.Clone()}
+	case opUpdate:
+		current, ok := f.tasks[cmd.Task.ID]
+		if !ok || current.DeletedAt != nil {
+			return &result{Code: codeNotFound}
+		}
+
+		if cmd.Task.Version != 0 && cmd.Task.Version != current.Version {
+			return &result{Code: codeVersionConflict}
+		}
+
+		task := cmd.Task.Clone()
+		task.Version = current.Version + 1
+		task.CreatedAt = current.CreatedAt
+		task.DeletedAt = nil
+		f.put(task)
+
+		return &result{Task: task.Clone()}
+	case opDelete:
+		current, ok := f.tasks[cmd.ID]
+		if !ok || current.DeletedAt != nil {
+			return &result{Code: codeNotFound}
+		}
+
+		if cmd.Version != 0 && cmd.Version != current.Version {
+			return &result{Code: codeVersionConflict}
+		}
+
+		deletedAt := cmd.At.UTC()
+		current.Version++
+		current.DeletedAt = &deletedAt
+		f.put(current)
+
+		return &result{Task: current.Clone()}
+	case opRestore:
+		current, ok := f.tasks[cmd.ID]
+		if !ok || current.DeletedAt == nil {
+			return &result{Code: codeNotFound}
+		}
+
+		current.Version++
+		current.DeletedAt = nil
+		f.put(current)
+
+		return &result{Task: current.Clone()}
+	case opPurge:
+		purged := 0
+
+		for id, task := range f.tasks {
+			if task.DeletedAt != nil && task.DeletedAt.Before(cmd.At) {
+				delete(f.tasks, id)
+				purged++
+			}
+		}
+
+		return &result{Count: purged}
+	case opImport:
+		for _, task := range cmd.Tasks {
+			f.put(task.Clone())
+			f.idCounter = max(f.idCounter, task.ID)
+		}
+
+		f.idCounter = max(f.idCounter, cmd.NextID-1)
+
+		return &result{}
+	case opReplace:
+		f.tasks = make(map[int]*model.Task, len(cmd.Tasks))
+		f.due = index.NewDue()
+		f.tags = index.NewTags()
+		f.children = index.NewChildren()
+
+		for _, task := range cmd.Tasks {
+			f.put(task.Clone())
+			f.idCounter = max(f.idCounter, task.ID)
+		}
+
+		f.idCounter = max(f.idCounter, cmd.NextID-1)
+
+		return &result{}
+	case opRevision:
+		f.addRevision(cmd.Revision)
+
+		return &result{}
+	case opRetag:
+		renamed := make([]*model.Task, 0)
+
+		for _, id := range f.tags.IDs(cmd.From) {
+			task := f.tasks[id].Clone()
+			task.RenameTag(cmd.From, cmd.To)
+			task.Version++
+			f.put(task)
+
+			renamed = append(renamed, task.Clone())
+		}
+
+		return &result{Tasks: renamed}
+	default:
+		return &result{}
+	}
+}
+
+// put сохраняет задачу и обновляет индексы сроков, меток и подзадач: задачи из корзины в индексы не попадают
+func (f *fsm) put(task *model.Task) {
+	f.tasks[task.ID] = task
+
+	if task.DeletedAt != nil {
+		f.due.Remove(task.ID)
+		f.tags.Remove(task.ID)
+		f.children.Remove(task.ID)
+		return
+	}
+
+	f.due.Put(task.ID, task.DueAt)
+	f.tags.Put(task.ID, task.Tags)
+	f.children.Put(task.ID, task.ParentID)
+}
+
+// addRevision сохраняет ревизию, если ревизии с тем же номером у задачи еще нет
+func (f *fsm) addRevision(rev *model.Revision) {
+	revisions := f.revisions[rev.TaskID]
+
+	i := sort.Search(len(revisions), func(i int) bool {
+		return revisions[i].Rev >= rev.Rev
+	})
+	if i < len(revisions) && revisions[i].Rev == rev.Rev {
+		return
+	}
+
+	revisions = append(revisions, nil)
+	copy(revisions[i+1:], revisions[i:])
+	revisions[i] = rev.Clone()
+
+	f.revisions[rev.TaskID] = revisions
+}
+
+func (f *fsm) Snapshot() ([]byte, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	snap := snapshot{
+		IDCounter: f.idCounter,
+		Tasks:     make([]*model.Task, 0, len(f.tasks)),
+		Position:  f.position,
+	}
+
+	for _, task := range f.tasks {
+		snap.Tasks = append(snap.Tasks, task)
+	}
+
+	for _, revisions := range f.revisions {
+		snap.Revisions = append(snap.Revisions, revisions...)
+	}
+
+	return json.Marshal(snap)
+}
+
+// Restore заменяет состояние снимком. Изменения до снимка не проходят через Apply,
+// поэтому в поток записывается reset с номером последнего изменения снимка:
+// узел, который уже записал это изменение, его пропускает
+func (f *fsm) Restore(data []byte) error {
+	var snap snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return err
+	}
+
+	f.restore(&snap)
+
+	if snap.Position > 0 {
+		f.changes.Record([]*model.ChangeEvent{{Type: model.ChangeReset, Position: snap.Position}})
+	}
+
+	return nil
+}
+
+func (f *fsm) restore(snap *snapshot) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.idCounter = snap.IDCounter
+	f.position = snap.Position
+	f.tasks = make(map[int]*model.Task, len(snap.Tasks))
+	f.due = index.NewDue()
+	f.tags = index.NewTags()
+	f.children = index.NewChildren()
+	f.revisions = make(map[int][]*model.Revision)
+
+	for _, task := range snap.Tasks {
+		f.put(task)
+	}
+
+	for _, rev := range snap.Revisions {
+		f.addRevision(rev)
+	}
+}
@@ -0,0 +1,27 @@
+package raft
+
+import (
+	"context"
+
+	"github.com/solumD/tasks-service/internal/model"
+)
+
+// NextID возвращает ID, который получит следующая созданная задача
+func (r *taskRepo) NextID(ctx context.Context) (int, error) {
+	if err := r.barrier(ctx); err != nil {
+		return 0, err
+	}
+
+	r.fsm.mu.RLock()
+	defer r.fsm.mu.RUnlock()
+
+	return r.fsm.idCounter + 1, nil
+}
+
+// ImportTasks записывает задачи с их ID, версиями и временем удаления, заменяя
+// задачи с теми же ID, и сдвигает счетчик так, чтобы следующая задача получила ID не меньше nextID
+func (r *taskRepo) ImportTasks(ctx context.Context, tasks []*model.Task, nextID int) error {
+	_, err := r.apply(ctx, &command{Op: opImport, Tasks: tasks, NextID: nextID})
+
+	return err
+}
@@ -0,0 +1,65 @@
+package raft
+
+import (
+	"context"
+
+	"github.com/solumD/tasks-service/internal/model"
+	"github.com/solumD/tasks-service/internal/usecase"
+)
+
+type revisionRepo struct {
+	tasks *taskRepo
+}
+
+// RevisionRepo возвращает хранилище ревизий, которое реплицируется тем же кластером,
+// что и задачи. Узел останавливается вместе с хранилищем задач
+func (r *taskRepo) RevisionRepo() *revisionRepo {
+	return &revisionRepo{tasks: r}
+}
+
+// AddRevision сохраняет ревизию, если ревизии с тем же номером у задачи еще нет
+func (r *revisionRepo) AddRevision(ctx context.Context, rev *model.Revision) error {
+	_, err := r.tasks.apply(ctx, &command{Op: opRevision, Revision: rev})
+
+	return err
+}
+
+// GetRevisions возвращает ревизии задачи по возрастанию номера
+func (r *revisionRepo) GetRevisions(ctx context.Context, taskID int) ([]*model.Revision, error) {
+	if err := r.tasks.barrier(ctx); err != nil {
+		return nil, err
+	}
+
+	state := r.tasks.fsm
+
+	state.mu.RLock()
+	defer state.mu.RUnlock()
+
+	revisions := make([]*model.Revision, 0, len(state.revisions[taskID]))
+
+	for _, rev := range state.revisions[taskID] {
+		revisions = append(revisions, rev.Clone())
+	}
+
+	return revisions, nil
+}
+
+// GetRevision возвращает ревизию rev задачи
+func (r *revisionRepo) GetRevision(ctx context.Context, taskID int, rev int) (*model.Revision, error) {
+	if err := r.tasks.barrier(ctx); err != nil {
+		return nil, err
+	}
+
+	state := r.tasks.fsm
+
+	state.mu.RLock()
+	defer state.mu.RUnlock()
+
+	for _, revision := range state.revisions[taskID] {
+		if revision.Rev == rev {
+			return revision.Clone(), nil
+		}
+	}
+
+	return nil, usecase.ErrRevisionNotFound
+}
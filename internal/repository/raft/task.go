@@ -0,0 +1,314 @@
+package raft
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/solumD/tasks-service/internal/model"
+	raftcore "github.com/solumD/tasks-service/internal/raft"
+	"github.com/solumD/tasks-service/internal/usecase"
+)
+
+// taskRepo хранилище задач, реплицируемое кластером Raft. Изменения проходят через
+// журнал лидера и применяются, когда их сохранило большинство узлов. Чтение на любом
+// узле сначала дожидается применения всех подтвержденных изменений, поэтому узел
+// никогда не отдает устаревшие данные
+type taskRepo struct {
+	node    *raftcore.Node
+	fsm     *fsm
+	storage raftcore.Storage
+}
+
+// NewTaskRepo запускает узел кластера cfg с хранилищем storage и транспортом transport.
+// Изменения задач, примененные узлом, включая сделанные через другие узлы, передаются
+// в changes. Хранилище закрывается вместе с репозиторием
+func NewTaskRepo(cfg raftcore.Config, storage raftcore.Storage, transport raftcore.Transport, changes ChangeSink, log *slog.Logger) (*taskRepo, error) {
+	state := newFSM(changes)
+
+	node := raftcore.NewNode(cfg, state, storage, transport, log)
+	if err := node.Start(); err != nil {
+		return nil, err
+	}
+
+	return &taskRepo{
+		node:    node,
+		fsm:     state,
+		storage: storage,
+	}, nil
+}
+
+// Node возвращает узел кластера, например для служебных запросов оператора
+func (r *taskRepo) Node() *raftcore.Node {
+	return r.node
+}
+
+// Close останавливает узел и закрывает его хранилище
+func (r *taskRepo) Close() error {
+	r.node.Stop()
+
+	return r.storage.Close()
+}
+
+// CreateTask создает новую задачу в хранилище
+func (r *taskRepo) CreateTask(ctx context.Context, task *model.Task) (int, error) {
+	res, err := r.apply(ctx, &command{Op: opCreate, Task: task})
+	if err != nil {
+		return 0, err
+	}
+
+	task.ID = res.Task.ID
+	task.Version = res.Task.Version
+
+	return task.ID, nil
+}
+
+// GetAllTasks возвращает копии всех задач, кроме корзины
+func (r *taskRepo) GetAllTasks(ctx context.Context) ([]*model.Task, error) {
+	return r.list(ctx, false)
+}
+
+// GetTaskByID возвращает задачу по ID из хранилища
+func (r *taskRepo) GetTaskByID(ctx context.Context, id int) (*model.Task, error) {
+	if err := r.barrier(ctx); err != nil {
+		return nil, err
+	}
+
+	r.fsm.mu.RLock()
+	defer r.fsm.mu.RUnlock()
+
+	task, ok := r.fsm.tasks[id]
+	if !ok || task.DeletedAt != nil {
+		return nil, usecase.NewTaskNotFoundError(id)
+	}
+
+	return task.Clone(), nil
+}
+
+// GetTasksByDue возвращает копии задач со сроком выполнения в диапазоне [from, to) по индексу сроков
+func (r *taskRepo) GetTasksByDue(ctx context.Context, from, to time.Time) ([]*model.Task, error) {
+	if err := r.barrier(ctx); err != nil {
+		return nil, err
+	}
+
+	r.fsm.mu.RLock()
+	defer r.fsm.mu.RUnlock()
+
+	ids := r.fsm.due.Range(from, to)
+	tasks := make([]*model.Task, 0, len(ids))
+
+	for _, id := range ids {
+		tasks = append(tasks, r.fsm.tasks[id].Clone())
+	}
+
+	return tasks, nil
+}
+
+// GetTasksByTags возвращает копии задач хотя бы с одной из меток tags, а при all - со всеми,
+// по индексу меток
+func (r *taskRepo) GetTasksByTags(ctx context.Context, tags []string, all bool) ([]*model.Task, error) {
+	if err := r.barrier(ctx); err != nil {
+		return nil, err
+	}
+
+	r.fsm.mu.RLock()
+	defer r.fsm.mu.RUnlock()
+
+	ids := r.fsm.tags.Match(tags, all)
+	tasks := make([]*model.Task, 0, len(ids))
+
+	for _, id := range ids {
+		tasks = append(tasks, r.fsm.tasks[id].Clone())
+	}
+
+	return tasks, nil
+}
+
+// GetTasksByParent возвращает копии подзадач задачи parentID по индексу подзадач
+func (r *taskRepo) GetTasksByParent(ctx context.Context, parentID int) ([]*model.Task, error) {
+	if err := r.barrier(ctx); err != nil {
+		return nil, err
+	}
+
+	r.fsm.mu.RLock()
+	defer r.fsm.mu.RUnlock()
+
+	ids := r.fsm.children.IDs(parentID)
+	tasks := make([]*model.Task, 0, len(ids))
+
+	for _, id := range ids {
+		tasks = append(tasks, r.fsm.tasks[id].Clone())
+	}
+
+	return tasks, nil
+}
+
+// GetTagCounts возвращает метки задач с количеством задач у каждой
+func (r *taskRepo) GetTagCounts(ctx context.Context) ([]model.TagCount, error) {
+	if err := r.barrier(ctx); err != nil {
+		return nil, err
+	}
+
+	r.fsm.mu.RLock()
+	defer r.fsm.mu.RUnlock()
+
+	return r.fsm.tags.Counts(), nil
+}
+
+// RenameTag заменяет метку from на to у всех задач одной командой кластера
+func (r *taskRepo) RenameTag(ctx context.Context, from, to string) ([]*model.Task, error) {
+	res, err := r.apply(ctx, &command{Op: opRetag, From: from, To: to})
+	if err != nil {
+		return nil, err
+	}
+
+	if res.Tasks == nil {
+		return []*model.Task{}, nil
+	}
+
+	return res.Tasks, nil
+}
+
+// UpdateTask обновляет задачу в хранилище, если ее версия совпадает с task.Version
+func (r *taskRepo) UpdateTask(ctx context.Context, task *model.Task) error {
+	res, err := r.apply(ctx, &command{Op: opUpdate, Task: task})
+	if err != nil {
+		return err
+	}
+
+	if err := resultError(res, task.ID); err != nil {
+		return err
+	}
+
+	task.Version = res.Task.Version
+	task.CreatedAt = res.Task.CreatedAt
+
+	return nil
+}
+
+// DeleteTask перемещает задачу в корзину, если ее версия совпадает с version,
+// и возвращает ее. Время удаления выбирает узел, принявший запрос
+func (r *taskRepo) DeleteTask(ctx context.Context, id int, version int) (*model.Task, error) {
+	res, err := r.apply(ctx, &command{Op: opDelete, ID: id, Version: version, At: time.Now().UTC()})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := resultError(res, id); err != nil {
+		return nil, err
+	}
+
+	return res.Task, nil
+}
+
+// GetDeletedTasks возвращает все задачи из корзины
+func (r *taskRepo) GetDeletedTasks(ctx context.Context) ([]*model.Task, error) {
+	return r.list(ctx, true)
+}
+
+// RestoreTask возвращает задачу из корзины
+func (r *taskRepo) RestoreTask(ctx context.Context, id int) (*model.Task, error) {
+	res, err := r.apply(ctx, &command{Op: opRestore, ID: id})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := resultError(res, id); err != nil {
+		return nil, err
+	}
+
+	return res.Task, nil
+}
+
+// PurgeDeletedTasks окончательно удаляет задачи, попавшие в корзину раньше deletedBefore
+func (r *taskRepo) PurgeDeletedTasks(ctx context.Context, deletedBefore time.Time) (int, error) {
+	res, err := r.apply(ctx, &command{Op: opPurge, At: deletedBefore})
+	if err != nil {
+		return 0, err
+	}
+
+	return res.Count, nil
+}
+
+// list возвращает задачи из корзины или все остальные
+func (r *taskRepo) list(ctx context.Context, deleted bool) ([]*model.Task, error) {
+	if err := r.barrier(ctx); err != nil {
+		return nil, err
+	}
+
+	r.fsm.mu.RLock()
+	defer r.fsm.mu.RUnlock()
+
+	tasks := make([]*model.Task, 0, len(r.fsm.tasks))
+
+	for _, task := range r.fsm.tasks {
+		if (task.DeletedAt != nil) == deleted {
+			tasks = append(tasks, task.Clone())
+		}
+	}
+
+	return tasks, nil
+}
+
+// apply предлагает команду кластеру и ждет результата ее применения
+func (r *taskRepo) apply(ctx context.Context, cmd *command) (*result, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	cmd.Author = usecase.AuthorFromContext(ctx)
+
+	data, err := json.Marshal(cmd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal raft command: %w", err)
+	}
+
+	out, err := r.node.Apply(ctx, data)
+	if err != nil {
+		return nil, clusterError(ctx, err)
+	}
+
+	var res result
+	if err := json.Unmarshal(out, &res); err != nil {
+		return nil, fmt.Errorf("failed to decode raft command result: %w", err)
+	}
+
+	return &res, nil
+}
+
+// barrier дожидается применения на узле всех подтвержденных изменений
+func (r *taskRepo) barrier(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if err := r.node.Barrier(ctx); err != nil {
+		return clusterError(ctx, err)
+	}
+
+	return nil
+}
+
+// clusterError возвращает ошибку отмененного контекста как есть, а остальные
+// ошибки кластера дополняет контекстом
+func clusterError(ctx context.Context, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil && errors.Is(err, ctxErr) {
+		return ctxErr
+	}
+
+	return fmt.Errorf("raft cluster: %w", err)
+}
+
+func resultError(res *result, id int) error {
+	switch res.Code {
+	case codeNotFound:
+		return usecase.NewTaskNotFoundError(id)
+	case codeVersionConflict:
+		return usecase.ErrVersionConflict
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,153 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/solumD/tasks-service/internal/model"
+	"github.com/solumD/tasks-service/internal/usecase"
+)
+
+// events возвращает все события потока изменений узла id
+func (c *cluster) events(id string) []*model.ChangeEvent {
+	c.t.Helper()
+
+	batch, err := c.nodes[id].changes.Read(context.Background(), 0, 1000, 0)
+	if err != nil {
+		c.t.Fatalf("failed to read change stream of %s: %v", id, err)
+	}
+
+	return batch.Events
+}
+
+// waitPosition ждет, пока узел id запишет в поток изменение кластера position
+func (c *cluster) waitPosition(id string, position int) []*model.ChangeEvent {
+	c.t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		events := c.events(id)
+		if len(events) > 0 && events[len(events)-1].Position >= position {
+			return events
+		}
+
+		if time.Now().After(deadline) {
+			c.t.Fatalf("%s did not record change %d, got %d events", id, position, len(events))
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestChangeStreamOnEveryNode(t *testing.T) {
+	c := newCluster(t, 3)
+	leader := c.waitLeader()
+	follower := c.follower(leader)
+	ctx := usecase.ContextWithAuthor(context.Background(), "alice")
+
+	// изменения через разные узлы попадают в поток каждого узла
+	task := &model.Task{Title: "Task1"}
+	if _, err := c.nodes[follower].tasks.CreateTask(ctx, task); err != nil {
+		t.Fatalf("CreateTask() error = %v", err)
+	}
+
+	if err := c.nodes[leader].tasks.UpdateTask(ctx, &model.Task{ID: task.ID, Title: "Task1 updated"}); err != nil {
+		t.Fatalf("UpdateTask() error = %v", err)
+	}
+
+	// неуспешное изменение не попадает в поток
+	if err := c.nodes[leader].tasks.UpdateTask(ctx, &model.Task{ID: task.ID, Title: "stale", Version: 1}); err == nil {
+		t.Fatal("UpdateTask() with stale version succeeded")
+	}
+
+	if _, err := c.nodes[follower].tasks.DeleteTask(ctx, task.ID, 0); err != nil {
+		t.Fatalf("DeleteTask() error = %v", err)
+	}
+
+	expected := []model.ChangeType{model.ChangeCreated, model.ChangeUpdated, model.ChangeDeleted}
+
+	for id := range c.nodes {
+		events := c.waitPosition(id, 3)
+		if len(events) != len(expected) {
+			t.Fatalf("expected %d events on %s, got %d", len(expected), id, len(events))
+		}
+
+		for i, typ := range expected {
+			event := events[i]
+			if event.Seq != i+1 || event.Position != i+1 || event.Type != typ || event.TaskID != task.ID || event.Author != "alice" || event.Task.Version != i+1 {
+				t.Fatalf("event %d on %s: expected %s of task %d v%d by alice, got %+v", i, id, typ, task.ID, i+1, event)
+			}
+		}
+	}
+
+	// перезапущенный узел повторно применяет журнал, но не дублирует события
+	c.stop(follower)
+	c.start(follower)
+	c.waitLeader()
+
+	if _, err := c.nodes[leader].tasks.CreateTask(ctx, &model.Task{Title: "Task2"}); err != nil {
+		t.Fatalf("CreateTask() error = %v", err)
+	}
+
+	events := c.waitPosition(follower, 4)
+	if len(events) != 4 || events[3].Type != model.ChangeCreated {
+		t.Fatalf("expected 4 events on restarted %s, got %d", follower, len(events))
+	}
+
+	// восстановление из копии заменяет все задачи и записывается событием reset
+	if err := c.nodes[follower].tasks.ReplaceTasks(ctx, []*model.Task{{ID: 1, Title: "Task1", Version: 5}}, 3); err != nil {
+		t.Fatalf("ReplaceTasks() error = %v", err)
+	}
+
+	for id := range c.nodes {
+		events := c.waitPosition(id, 5)
+		if last := events[len(events)-1]; last.Type != model.ChangeReset || last.TaskID != 0 {
+			t.Fatalf("expected reset event on %s, got %+v", id, last)
+		}
+	}
+}
+
+func TestChangeStreamResetsAfterSnapshot(t *testing.T) {
+	ctx := context.Background()
+	c := newCluster(t, 3)
+	leader := c.waitLeader()
+	follower := c.follower(leader)
+
+	c.stop(follower)
+
+	// лидер сжимает журнал снимком, и отставший узел догоняет кластер через снимок
+	for i := 1; i <= 30; i++ {
+		if _, err := c.nodes[leader].tasks.CreateTask(ctx, &model.Task{Title: fmt.Sprintf("Task%d", i)}); err != nil {
+			t.Fatalf("CreateTask() error = %v", err)
+		}
+	}
+
+	c.start(follower)
+	c.waitLeader()
+
+	if _, err := c.nodes[leader].tasks.CreateTask(ctx, &model.Task{Title: "Task31"}); err != nil {
+		t.Fatalf("CreateTask() error = %v", err)
+	}
+
+	// изменения из снимка заменены событием reset, следующие изменения идут как обычно
+	events := c.waitPosition(follower, 31)
+
+	resets := 0
+	for _, event := range events {
+		if event.Type == model.ChangeReset {
+			resets++
+		}
+	}
+
+	last := events[len(events)-1]
+	if resets != 1 || last.Type != model.ChangeCreated || last.Task.Title != "Task31" {
+		t.Fatalf("expected one reset before Task31 on %s, got %d resets and last event %+v", follower, resets, last)
+	}
+
+	// на узлах, записавших все изменения, reset нет
+	if events := c.waitPosition(leader, 31); len(events) != 31 {
+		t.Fatalf("expected 31 events on leader, got %d", len(events))
+	}
+}
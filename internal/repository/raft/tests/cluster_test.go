@@ -0,0 +1,144 @@
+package tests
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/solumD/tasks-service/internal/backup"
+	"github.com/solumD/tasks-service/internal/cdc"
+	"github.com/solumD/tasks-service/internal/migrate"
+	raftcore "github.com/solumD/tasks-service/internal/raft"
+	raftrepo "github.com/solumD/tasks-service/internal/repository/raft"
+	"github.com/solumD/tasks-service/internal/usecase"
+	"github.com/solumD/tasks-service/pkg/logger"
+)
+
+// node хранилища одного узла кластера
+type node struct {
+	tasks interface {
+		usecase.TaskRepo
+		backup.Store
+		migrate.Repo
+		Node() *raftcore.Node
+	}
+	revisions usecase.RevisionRepo
+	changes   *cdc.Log
+}
+
+// cluster узлы хранилища в одном процессе, связанные сетью в памяти
+type cluster struct {
+	t         *testing.T
+	network   *raftcore.InmemNetwork
+	bootstrap []raftcore.Server
+	storages  map[string]raftcore.Storage
+	nodes     map[string]*node
+	// dir директория потоков изменений узлов, поток переживает перезапуск узла
+	dir string
+}
+
+func newCluster(t *testing.T, size int) *cluster {
+	t.Helper()
+
+	c := &cluster{
+		t:        t,
+		network:  raftcore.NewInmemNetwork(),
+		storages: make(map[string]raftcore.Storage),
+		nodes:    make(map[string]*node),
+		dir:      t.TempDir(),
+	}
+
+	for i := 1; i <= size; i++ {
+		id := fmt.Sprintf("n%d", i)
+		c.bootstrap = append(c.bootstrap, raftcore.Server{ID: id, Address: id})
+	}
+
+	for _, s := range c.bootstrap {
+		c.start(s.ID)
+	}
+
+	t.Cleanup(func() {
+		for id := range c.nodes {
+			c.stop(id)
+		}
+	})
+
+	return c
+}
+
+// start запускает узел id, перезапущенный узел получает прежнее хранилище Raft
+func (c *cluster) start(id string) {
+	c.t.Helper()
+
+	if c.storages[id] == nil {
+		c.storages[id] = raftcore.NewMemoryStorage()
+	}
+
+	log := logger.NewMockLogger()
+
+	changes, err := cdc.NewLog(filepath.Join(c.dir, id+".log"), time.Hour)
+	if err != nil {
+		c.t.Fatalf("failed to open change stream of %s: %v", id, err)
+	}
+
+	repo, err := raftrepo.NewTaskRepo(raftcore.Config{
+		ID:                id,
+		Bootstrap:         c.bootstrap,
+		HeartbeatInterval: 20 * time.Millisecond,
+		ElectionTimeout:   150 * time.Millisecond,
+		ApplyTimeout:      3 * time.Second,
+		SnapshotThreshold: 20,
+	}, c.storages[id], c.network.Transport(id), cdc.NewClusterRecorder(changes, log), log)
+	if err != nil {
+		c.t.Fatalf("failed to start %s: %v", id, err)
+	}
+
+	c.nodes[id] = &node{tasks: repo, revisions: repo.RevisionRepo(), changes: changes}
+}
+
+// stop останавливает узел, не закрывая его хранилище Raft, и закрывает его поток изменений
+func (c *cluster) stop(id string) {
+	c.nodes[id].tasks.Node().Stop()
+	c.nodes[id].changes.Close()
+	delete(c.nodes, id)
+}
+
+// waitLeader ждет, пока все запущенные узлы признают одного лидера
+func (c *cluster) waitLeader() string {
+	c.t.Helper()
+
+	deadline := time.Now().Add(10 * time.Second)
+	for time.Now().Before(deadline) {
+		leader, agreed := "", true
+
+		for _, n := range c.nodes {
+			l := n.tasks.Node().Leader()
+			if l == "" || (leader != "" && l != leader) {
+				agreed = false
+				break
+			}
+			leader = l
+		}
+
+		if agreed && c.nodes[leader] != nil {
+			return leader
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	c.t.Fatal("cluster did not elect a leader")
+	return ""
+}
+
+func (c *cluster) follower(leader string) string {
+	for id := range c.nodes {
+		if id != leader {
+			return id
+		}
+	}
+
+	c.t.Fatal("no followers")
+	return ""
+}
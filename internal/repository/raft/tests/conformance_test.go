@@ -0,0 +1,25 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/solumD/tasks-service/internal/repository/repotest"
+	"github.com/solumD/tasks-service/internal/usecase"
+)
+
+func TestConformance(t *testing.T) {
+	// запросы к ведомому узлу проходят через лидера, результат должен быть тем же
+	for _, name := range []string{"leader", "follower"} {
+		t.Run(name, func(t *testing.T) {
+			repotest.Run(t, func(t *testing.T) usecase.TaskRepo {
+				c := newCluster(t, 3)
+				id := c.waitLeader()
+				if name == "follower" {
+					id = c.follower(id)
+				}
+
+				return c.nodes[id].tasks
+			})
+		})
+	}
+}
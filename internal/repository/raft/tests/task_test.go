@@ -0,0 +1,132 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/solumD/tasks-service/internal/model"
+	"github.com/solumD/tasks-service/internal/usecase"
+)
+
+func TestReadsOnEveryNodeSeeAcknowledgedWrites(t *testing.T) {
+	ctx := context.Background()
+	c := newCluster(t, 3)
+	leader := c.waitLeader()
+
+	task := &model.Task{Title: "Task1"}
+	if _, err := c.nodes[leader].tasks.CreateTask(ctx, task); err != nil {
+		t.Fatalf("CreateTask() error = %v", err)
+	}
+
+	rev := &model.Revision{TaskID: task.ID, Rev: 1, Action: model.RevisionCreated, CreatedAt: time.Now().UTC(), Task: *task}
+	if err := c.nodes[leader].revisions.AddRevision(ctx, rev); err != nil {
+		t.Fatalf("AddRevision() error = %v", err)
+	}
+
+	for id, n := range c.nodes {
+		got, err := n.tasks.GetTaskByID(ctx, task.ID)
+		if err != nil || got.Title != "Task1" || got.Version != 1 {
+			t.Fatalf("GetTaskByID() on %s = %+v, %v", id, got, err)
+		}
+
+		revisions, err := n.revisions.GetRevisions(ctx, task.ID)
+		if err != nil || len(revisions) != 1 {
+			t.Fatalf("GetRevisions() on %s = %v, %v", id, revisions, err)
+		}
+
+		if _, err := n.revisions.GetRevision(ctx, task.ID, 2); !errors.Is(err, usecase.ErrRevisionNotFound) {
+			t.Fatalf("GetRevision() on %s error = %v, want ErrRevisionNotFound", id, err)
+		}
+	}
+}
+
+func TestLeaderFailover(t *testing.T) {
+	ctx := context.Background()
+	c := newCluster(t, 3)
+	leader := c.waitLeader()
+
+	for i := 1; i <= 30; i++ {
+		if _, err := c.nodes[leader].tasks.CreateTask(ctx, &model.Task{Title: fmt.Sprintf("Task%d", i)}); err != nil {
+			t.Fatalf("CreateTask() error = %v", err)
+		}
+	}
+
+	c.stop(leader)
+	newLeader := c.waitLeader()
+
+	// ID продолжаются с того же места, а удаленная задача получает время удаления лидера
+	task := &model.Task{Title: "Task31"}
+	if _, err := c.nodes[newLeader].tasks.CreateTask(ctx, task); err != nil || task.ID != 31 {
+		t.Fatalf("CreateTask() after failover = %d, %v", task.ID, err)
+	}
+
+	trashed, err := c.nodes[newLeader].tasks.DeleteTask(ctx, 1, 1)
+	if err != nil {
+		t.Fatalf("DeleteTask() error = %v", err)
+	}
+
+	// прежний лидер догоняет кластер, в том числе через снимок
+	c.start(leader)
+	c.waitLeader()
+
+	deleted, err := c.nodes[leader].tasks.GetDeletedTasks(ctx)
+	if err != nil || len(deleted) != 1 || !deleted[0].DeletedAt.Equal(*trashed.DeletedAt) {
+		t.Fatalf("GetDeletedTasks() on restarted node = %v, %v", deleted, err)
+	}
+
+	tasks, err := c.nodes[leader].tasks.GetAllTasks(ctx)
+	if err != nil || len(tasks) != 30 {
+		t.Fatalf("GetAllTasks() on restarted node = %d tasks, %v", len(tasks), err)
+	}
+}
+
+func TestWritesFailWithoutQuorum(t *testing.T) {
+	c := newCluster(t, 3)
+	leader := c.waitLeader()
+
+	for id := range c.nodes {
+		if id != leader {
+			c.stop(id)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+
+	if _, err := c.nodes[leader].tasks.CreateTask(ctx, &model.Task{Title: "Task1"}); err == nil {
+		t.Fatal("CreateTask() without quorum succeeded")
+	}
+}
+
+func TestReplaceAndImport(t *testing.T) {
+	ctx := context.Background()
+	c := newCluster(t, 3)
+	leader := c.waitLeader()
+	follower := c.follower(leader)
+
+	deletedAt := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	tasks := []*model.Task{
+		{ID: 3, Title: "Task3", Version: 2},
+		{ID: 7, Title: "Task7", Version: 4, DeletedAt: &deletedAt},
+	}
+
+	if err := c.nodes[follower].tasks.ReplaceTasks(ctx, tasks, 10); err != nil {
+		t.Fatalf("ReplaceTasks() error = %v", err)
+	}
+
+	dumped, nextID, err := c.nodes[leader].tasks.DumpTasks(ctx)
+	if err != nil || len(dumped) != 2 || nextID != 10 {
+		t.Fatalf("DumpTasks() = %d tasks, next id %d, %v", len(dumped), nextID, err)
+	}
+
+	if err := c.nodes[leader].tasks.ImportTasks(ctx, []*model.Task{{ID: 12, Title: "Task12", Version: 1}}, 0); err != nil {
+		t.Fatalf("ImportTasks() error = %v", err)
+	}
+
+	if next, err := c.nodes[follower].tasks.NextID(ctx); err != nil || next != 13 {
+		t.Fatalf("NextID() = %d, %v, want 13", next, err)
+	}
+}
@@ -0,0 +1,389 @@
+package repotest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/solumD/tasks-service/internal/model"
+	"github.com/solumD/tasks-service/internal/usecase"
+)
+
+// commentStore хранилище комментариев, которое умеет отдавать и заменять все комментарии целиком
+type commentStore interface {
+	DumpComments(ctx context.Context) ([]*model.Comment, int, error)
+	ReplaceComments(ctx context.Context, comments []*model.Comment, nextID int) error
+}
+
+// CommentFactory создает пустое хранилище комментариев для одного теста
+type CommentFactory func(t *testing.T) usecase.CommentRepo
+
+// RunComments проверяет хранилище, которое создает newRepo, на соответствие контракту usecase.CommentRepo
+func RunComments(t *testing.T, newRepo CommentFactory) {
+	tests := []struct {
+		name string
+		test func(t *testing.T, repo usecase.CommentRepo)
+	}{
+		{name: "create and get", test: testCommentCreate},
+		{name: "not found", test: testCommentNotFound},
+		{name: "pages and replies", test: testCommentPages},
+		{name: "update", test: testCommentUpdate},
+		{name: "delete", test: testCommentDelete},
+		{name: "delete task comments", test: testDeleteTaskComments},
+		{name: "dump and replace", test: testCommentReplace},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.test(t, newRepo(t))
+		})
+	}
+}
+
+// addComments создает комментарии в заданном порядке
+func addComments(t *testing.T, repo usecase.CommentRepo, comments ...*model.Comment) {
+	t.Helper()
+
+	for _, comment := range comments {
+		if comment.Author == "" {
+			comment.Author = "alice"
+		}
+
+		if comment.Text == "" {
+			comment.Text = "text"
+		}
+
+		if comment.CreatedAt.IsZero() {
+			comment.CreatedAt = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+		}
+
+		if _, err := repo.CreateComment(context.Background(), comment); err != nil {
+			t.Fatalf("failed to create comment: %v", err)
+		}
+	}
+}
+
+// commentIDs возвращает ID комментариев по порядку
+func commentIDs(comments []*model.Comment) []int {
+	ids := make([]int, 0, len(comments))
+	for _, comment := range comments {
+		ids = append(ids, comment.ID)
+	}
+
+	return ids
+}
+
+func testCommentCreate(t *testing.T, repo usecase.CommentRepo) {
+	ctx := context.Background()
+	createdAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	for want := 1; want <= 2; want++ {
+		comment := &model.Comment{TaskID: 7, Author: "alice", Text: "hello", CreatedAt: createdAt}
+
+		id, err := repo.CreateComment(ctx, comment)
+		if err != nil {
+			t.Fatalf("failed to create comment: %v", err)
+		}
+
+		if id != want || comment.ID != id {
+			t.Fatalf("expected id %d, got id %d (comment.ID %d)", want, id, comment.ID)
+		}
+	}
+
+	got, err := repo.GetCommentByID(ctx, 2)
+	if err != nil {
+		t.Fatalf("failed to get comment: %v", err)
+	}
+
+	if got.TaskID != 7 || got.ParentID != 0 || got.Author != "alice" || got.Text != "hello" || !got.CreatedAt.Equal(createdAt) || got.EditedAt != nil {
+		t.Fatalf("unexpected comment: %+v", got)
+	}
+
+	// изменение полученной копии не меняет хранилище
+	got.Text = "changed"
+
+	again, _ := repo.GetCommentByID(ctx, 2)
+	if again.Text != "hello" {
+		t.Fatalf("expected stored text to stay, got %q", again.Text)
+	}
+}
+
+func testCommentNotFound(t *testing.T, repo usecase.CommentRepo) {
+	ctx := context.Background()
+
+	if _, err := repo.GetCommentByID(ctx, 42); !errors.Is(err, usecase.ErrCommentNotFound) {
+		t.Fatalf("expected ErrCommentNotFound on get, got %v", err)
+	}
+
+	if err := repo.UpdateComment(ctx, &model.Comment{ID: 42, Text: "x"}); !errors.Is(err, usecase.ErrCommentNotFound) {
+		t.Fatalf("expected ErrCommentNotFound on update, got %v", err)
+	}
+
+	if _, err := repo.DeleteComment(ctx, 42); !errors.Is(err, usecase.ErrCommentNotFound) {
+		t.Fatalf("expected ErrCommentNotFound on delete, got %v", err)
+	}
+
+	comments, err := repo.GetComments(ctx, 7, 0, 10)
+	if err != nil {
+		t.Fatalf("failed to get comments: %v", err)
+	}
+
+	if comments == nil || len(comments) != 0 {
+		t.Fatalf("expected empty non-nil list, got %#v", comments)
+	}
+
+	replies, err := repo.GetReplies(ctx, []int{})
+	if err != nil {
+		t.Fatalf("failed to get replies: %v", err)
+	}
+
+	if replies == nil || len(replies) != 0 {
+		t.Fatalf("expected empty non-nil replies, got %#v", replies)
+	}
+}
+
+func testCommentPages(t *testing.T, repo usecase.CommentRepo) {
+	ctx := context.Background()
+
+	// 1, 3, 5, 6 - комментарии задачи 7, 2 - другой задачи, 4 и 7 - ответы на 1, 8 - ответ на 3
+	addComments(t, repo,
+		&model.Comment{TaskID: 7},
+		&model.Comment{TaskID: 8},
+		&model.Comment{TaskID: 7},
+		&model.Comment{TaskID: 7, ParentID: 1},
+		&model.Comment{TaskID: 7},
+		&model.Comment{TaskID: 7},
+		&model.Comment{TaskID: 7, ParentID: 1},
+		&model.Comment{TaskID: 7, ParentID: 3},
+	)
+
+	pages := []struct {
+		after int
+		limit int
+		want  []int
+	}{
+		{after: 0, limit: 10, want: []int{1, 3, 5, 6}},
+		{after: 0, limit: 2, want: []int{1, 3}},
+		{after: 3, limit: 2, want: []int{5, 6}},
+		{after: 6, limit: 2, want: []int{}},
+	}
+
+	for _, page := range pages {
+		comments, err := repo.GetComments(ctx, 7, page.after, page.limit)
+		if err != nil {
+			t.Fatalf("failed to get comments: %v", err)
+		}
+
+		if !equalIDs(commentIDs(comments), page.want) {
+			t.Fatalf("after %d limit %d: expected %v, got %v", page.after, page.limit, page.want, commentIDs(comments))
+		}
+	}
+
+	replies, err := repo.GetReplies(ctx, []int{3, 1, 5})
+	if err != nil {
+		t.Fatalf("failed to get replies: %v", err)
+	}
+
+	if !equalIDs(commentIDs(replies), []int{4, 7, 8}) {
+		t.Fatalf("expected replies [4 7 8], got %v", commentIDs(replies))
+	}
+
+	if replies[0].ParentID != 1 || replies[2].ParentID != 3 {
+		t.Fatalf("unexpected reply parents: %+v", replies)
+	}
+}
+
+func testCommentUpdate(t *testing.T, repo usecase.CommentRepo) {
+	ctx := context.Background()
+
+	comment := &model.Comment{TaskID: 7, Text: "hello"}
+	addComments(t, repo, comment)
+
+	editedAt := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+
+	// меняются только текст и время изменения
+	update := &model.Comment{ID: comment.ID, TaskID: 8, Author: "bob", Text: "bye", EditedAt: &editedAt}
+	if err := repo.UpdateComment(ctx, update); err != nil {
+		t.Fatalf("failed to update comment: %v", err)
+	}
+
+	got, err := repo.GetCommentByID(ctx, comment.ID)
+	if err != nil {
+		t.Fatalf("failed to get comment: %v", err)
+	}
+
+	if got.Text != "bye" || got.EditedAt == nil || !got.EditedAt.Equal(editedAt) || got.TaskID != 7 || got.Author != "alice" {
+		t.Fatalf("unexpected comment after update: %+v", got)
+	}
+}
+
+func testCommentDelete(t *testing.T, repo usecase.CommentRepo) {
+	ctx := context.Background()
+
+	// 1 и 4 - комментарии задачи 7, 2 и 3 - ответы на 1, 5 - ответ на 4
+	addComments(t, repo,
+		&model.Comment{TaskID: 7},
+		&model.Comment{TaskID: 7, ParentID: 1},
+		&model.Comment{TaskID: 7, ParentID: 1},
+		&model.Comment{TaskID: 7},
+		&model.Comment{TaskID: 7, ParentID: 4},
+	)
+
+	deleted, err := repo.DeleteComment(ctx, 5)
+	if err != nil {
+		t.Fatalf("failed to delete reply: %v", err)
+	}
+
+	if deleted != 1 {
+		t.Fatalf("expected 1 deleted reply, got %d", deleted)
+	}
+
+	deleted, err = repo.DeleteComment(ctx, 1)
+	if err != nil {
+		t.Fatalf("failed to delete comment: %v", err)
+	}
+
+	if deleted != 3 {
+		t.Fatalf("expected comment deleted with 2 replies, got %d", deleted)
+	}
+
+	for _, id := range []int{1, 2, 3, 5} {
+		if _, err := repo.GetCommentByID(ctx, id); !errors.Is(err, usecase.ErrCommentNotFound) {
+			t.Fatalf("expected comment %d not found, got %v", id, err)
+		}
+	}
+
+	comments, _ := repo.GetComments(ctx, 7, 0, 10)
+	if !equalIDs(commentIDs(comments), []int{4}) {
+		t.Fatalf("expected comments [4], got %v", commentIDs(comments))
+	}
+
+	// ID удаленного комментария не выдается повторно
+	next := &model.Comment{TaskID: 7}
+	addComments(t, repo, next)
+
+	if next.ID != 6 {
+		t.Fatalf("expected id 6, got %d", next.ID)
+	}
+}
+
+func testDeleteTaskComments(t *testing.T, repo usecase.CommentRepo) {
+	ctx := context.Background()
+
+	// 1 и 3 - комментарии задачи 7, 2 - ответ на 1, 4 - комментарий задачи 8, 5 - задачи 9
+	addComments(t, repo,
+		&model.Comment{TaskID: 7},
+		&model.Comment{TaskID: 7, ParentID: 1},
+		&model.Comment{TaskID: 7},
+		&model.Comment{TaskID: 8},
+		&model.Comment{TaskID: 9},
+	)
+
+	deleted, err := repo.DeleteTaskComments(ctx, []int{7, 9, 42})
+	if err != nil {
+		t.Fatalf("failed to delete task comments: %v", err)
+	}
+
+	if deleted != 4 {
+		t.Fatalf("expected 4 deleted comments, got %d", deleted)
+	}
+
+	for _, taskID := range []int{7, 9} {
+		comments, _ := repo.GetComments(ctx, taskID, 0, 10)
+		if len(comments) != 0 {
+			t.Fatalf("expected no comments of task %d, got %v", taskID, commentIDs(comments))
+		}
+	}
+
+	if _, err := repo.GetCommentByID(ctx, 2); !errors.Is(err, usecase.ErrCommentNotFound) {
+		t.Fatalf("expected reply deleted with task comments, got %v", err)
+	}
+
+	comments, _ := repo.GetComments(ctx, 8, 0, 10)
+	if !equalIDs(commentIDs(comments), []int{4}) {
+		t.Fatalf("expected comments of task 8 to stay, got %v", commentIDs(comments))
+	}
+
+	deleted, err = repo.DeleteTaskComments(ctx, []int{})
+	if err != nil || deleted != 0 {
+		t.Fatalf("expected nothing deleted for empty list, got %d, %v", deleted, err)
+	}
+}
+
+func testCommentReplace(t *testing.T, repo usecase.CommentRepo) {
+	ctx := context.Background()
+
+	store, ok := repo.(commentStore)
+	if !ok {
+		t.Fatalf("comment repo %T cannot dump and replace comments", repo)
+	}
+
+	addComments(t, repo,
+		&model.Comment{TaskID: 7},
+		&model.Comment{TaskID: 7, ParentID: 1},
+	)
+
+	comments, next, err := store.DumpComments(ctx)
+	if err != nil {
+		t.Fatalf("failed to dump comments: %v", err)
+	}
+
+	if !equalIDs(commentIDs(comments), []int{1, 2}) || comments[1].ParentID != 1 || next != 3 {
+		t.Fatalf("expected comments [1 2] with next id 3, got %v with next id %d", commentIDs(comments), next)
+	}
+
+	createdAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	editedAt := createdAt.Add(time.Hour)
+	restored := []*model.Comment{
+		{ID: 5, TaskID: 9, Author: "bob", Text: "restored", CreatedAt: createdAt, EditedAt: &editedAt},
+		{ID: 6, TaskID: 9, ParentID: 5, Author: "alice", Text: "reply", CreatedAt: createdAt},
+	}
+
+	if err := store.ReplaceComments(ctx, restored, 8); err != nil {
+		t.Fatalf("failed to replace comments: %v", err)
+	}
+
+	if _, err := repo.GetCommentByID(ctx, 1); !errors.Is(err, usecase.ErrCommentNotFound) {
+		t.Fatalf("expected replaced comment to be removed, got %v", err)
+	}
+
+	got, err := repo.GetCommentByID(ctx, 5)
+	if err != nil {
+		t.Fatalf("failed to get restored comment: %v", err)
+	}
+
+	if got.TaskID != 9 || got.Author != "bob" || got.Text != "restored" || !got.CreatedAt.Equal(createdAt) || got.EditedAt == nil || !got.EditedAt.Equal(editedAt) {
+		t.Fatalf("unexpected restored comment: %+v", got)
+	}
+
+	// индексы страниц и ответов перестроены
+	threads, _ := repo.GetComments(ctx, 9, 0, 10)
+	replies, _ := repo.GetReplies(ctx, []int{5})
+	if !equalIDs(commentIDs(threads), []int{5}) || !equalIDs(commentIDs(replies), []int{6}) {
+		t.Fatalf("expected thread [5] with replies [6], got %v with %v", commentIDs(threads), commentIDs(replies))
+	}
+
+	if comments, _ := repo.GetComments(ctx, 7, 0, 10); len(comments) != 0 {
+		t.Fatalf("expected no comments of task 7, got %v", commentIDs(comments))
+	}
+
+	comment := &model.Comment{TaskID: 9}
+	addComments(t, repo, comment)
+
+	if comment.ID != 8 {
+		t.Fatalf("expected new comment id 8, got %d", comment.ID)
+	}
+
+	// счетчик ID не уменьшается
+	if err := store.ReplaceComments(ctx, nil, 2); err != nil {
+		t.Fatalf("failed to replace comments: %v", err)
+	}
+
+	comment = &model.Comment{TaskID: 9}
+	addComments(t, repo, comment)
+
+	if comment.ID != 9 {
+		t.Fatalf("expected new comment id 9, got %d", comment.ID)
+	}
+}
@@ -0,0 +1,237 @@
+package repotest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/solumD/tasks-service/internal/model"
+	"github.com/solumD/tasks-service/internal/usecase"
+)
+
+// projectStore хранилище проектов, которое умеет отдавать и заменять все проекты целиком
+type projectStore interface {
+	DumpProjects(ctx context.Context) ([]*model.Project, int, error)
+	ReplaceProjects(ctx context.Context, projects []*model.Project, nextID int) error
+}
+
+// ProjectFactory создает пустое хранилище проектов для одного теста
+type ProjectFactory func(t *testing.T) usecase.ProjectRepo
+
+// RunProjects проверяет хранилище, которое создает newRepo, на соответствие контракту usecase.ProjectRepo
+func RunProjects(t *testing.T, newRepo ProjectFactory) {
+	tests := []struct {
+		name string
+		test func(t *testing.T, repo usecase.ProjectRepo)
+	}{
+		{name: "create and get", test: testProjectCreate},
+		{name: "not found", test: testProjectNotFound},
+		{name: "update", test: testProjectUpdate},
+		{name: "delete", test: testProjectDelete},
+		{name: "dump and replace", test: testProjectReplace},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.test(t, newRepo(t))
+		})
+	}
+}
+
+func testProjectCreate(t *testing.T, repo usecase.ProjectRepo) {
+	ctx := context.Background()
+
+	for want, name := range []string{"Home", "Work"} {
+		project := &model.Project{Name: name, Description: name + " desc", Counts: model.ProjectCounts{Total: 5}}
+
+		id, err := repo.CreateProject(ctx, project)
+		if err != nil {
+			t.Fatalf("failed to create project: %v", err)
+		}
+
+		if id != want+1 || project.ID != id || project.Version != 1 {
+			t.Fatalf("expected id %d in version 1, got id %d (project.ID %d) in version %d", want+1, id, project.ID, project.Version)
+		}
+	}
+
+	got, err := repo.GetProjectByID(ctx, 2)
+	if err != nil {
+		t.Fatalf("failed to get project: %v", err)
+	}
+
+	if got.Name != "Work" || got.Description != "Work desc" || got.Archived() || got.Counts.Total != 0 {
+		t.Fatalf("unexpected project: %+v", got)
+	}
+
+	projects, err := repo.GetProjects(ctx)
+	if err != nil {
+		t.Fatalf("failed to get projects: %v", err)
+	}
+
+	if len(projects) != 2 || projects[0].ID != 1 || projects[1].ID != 2 {
+		t.Fatalf("expected projects 1 and 2 in order, got %+v", projects)
+	}
+}
+
+func testProjectNotFound(t *testing.T, repo usecase.ProjectRepo) {
+	ctx := context.Background()
+
+	if _, err := repo.GetProjectByID(ctx, 42); !errors.Is(err, usecase.ErrProjectNotFound) {
+		t.Fatalf("expected ErrProjectNotFound on get, got %v", err)
+	}
+
+	if err := repo.UpdateProject(ctx, &model.Project{ID: 42, Name: "X"}); !errors.Is(err, usecase.ErrProjectNotFound) {
+		t.Fatalf("expected ErrProjectNotFound on update, got %v", err)
+	}
+
+	if err := repo.DeleteProject(ctx, 42, 0); !errors.Is(err, usecase.ErrProjectNotFound) {
+		t.Fatalf("expected ErrProjectNotFound on delete, got %v", err)
+	}
+
+	projects, err := repo.GetProjects(ctx)
+	if err != nil {
+		t.Fatalf("failed to get projects: %v", err)
+	}
+
+	if projects == nil || len(projects) != 0 {
+		t.Fatalf("expected empty non-nil list, got %#v", projects)
+	}
+}
+
+func testProjectUpdate(t *testing.T, repo usecase.ProjectRepo) {
+	ctx := context.Background()
+
+	project := &model.Project{Name: "Home"}
+	if _, err := repo.CreateProject(ctx, project); err != nil {
+		t.Fatalf("failed to create project: %v", err)
+	}
+
+	archivedAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	update := &model.Project{ID: project.ID, Name: "House", Description: "desc", ArchivedAt: &archivedAt, Version: 1}
+	if err := repo.UpdateProject(ctx, update); err != nil {
+		t.Fatalf("failed to update project: %v", err)
+	}
+
+	if update.Version != 2 {
+		t.Fatalf("expected version 2, got %d", update.Version)
+	}
+
+	stale := &model.Project{ID: project.ID, Name: "Stale", Version: 1}
+	if err := repo.UpdateProject(ctx, stale); !errors.Is(err, usecase.ErrProjectVersionConflict) {
+		t.Fatalf("expected ErrProjectVersionConflict, got %v", err)
+	}
+
+	got, err := repo.GetProjectByID(ctx, project.ID)
+	if err != nil {
+		t.Fatalf("failed to get project: %v", err)
+	}
+
+	if got.Name != "House" || got.Description != "desc" || got.ArchivedAt == nil || !got.ArchivedAt.Equal(archivedAt) || got.Version != 2 {
+		t.Fatalf("unexpected project after update: %+v", got)
+	}
+
+	got.ArchivedAt = nil
+	if err := repo.UpdateProject(ctx, got); err != nil {
+		t.Fatalf("failed to unarchive project: %v", err)
+	}
+
+	got, err = repo.GetProjectByID(ctx, project.ID)
+	if err != nil {
+		t.Fatalf("failed to get project: %v", err)
+	}
+
+	if got.Archived() || got.Version != 3 {
+		t.Fatalf("expected active project in version 3, got %+v", got)
+	}
+}
+
+func testProjectDelete(t *testing.T, repo usecase.ProjectRepo) {
+	ctx := context.Background()
+
+	first := &model.Project{Name: "Home"}
+	if _, err := repo.CreateProject(ctx, first); err != nil {
+		t.Fatalf("failed to create project: %v", err)
+	}
+
+	if err := repo.DeleteProject(ctx, first.ID, 2); !errors.Is(err, usecase.ErrProjectVersionConflict) {
+		t.Fatalf("expected ErrProjectVersionConflict, got %v", err)
+	}
+
+	if err := repo.DeleteProject(ctx, first.ID, 1); err != nil {
+		t.Fatalf("failed to delete project: %v", err)
+	}
+
+	if _, err := repo.GetProjectByID(ctx, first.ID); !errors.Is(err, usecase.ErrProjectNotFound) {
+		t.Fatalf("expected ErrProjectNotFound after delete, got %v", err)
+	}
+
+	// ID удаленного проекта не выдается повторно
+	second := &model.Project{Name: "Work"}
+	if _, err := repo.CreateProject(ctx, second); err != nil {
+		t.Fatalf("failed to create project: %v", err)
+	}
+
+	if second.ID == first.ID {
+		t.Fatalf("expected new id, got reused id %d", second.ID)
+	}
+}
+
+func testProjectReplace(t *testing.T, repo usecase.ProjectRepo) {
+	ctx := context.Background()
+
+	store, ok := repo.(projectStore)
+	if !ok {
+		t.Fatalf("project repo %T cannot dump and replace projects", repo)
+	}
+
+	for _, name := range []string{"Home", "Work"} {
+		if _, err := repo.CreateProject(ctx, &model.Project{Name: name}); err != nil {
+			t.Fatalf("failed to create project: %v", err)
+		}
+	}
+
+	projects, next, err := store.DumpProjects(ctx)
+	if err != nil {
+		t.Fatalf("failed to dump projects: %v", err)
+	}
+
+	if len(projects) != 2 || projects[0].Name != "Home" || projects[1].Name != "Work" || next != 3 {
+		t.Fatalf("expected projects Home and Work with next id 3, got %d projects with next id %d", len(projects), next)
+	}
+
+	archivedAt := time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)
+	restored := &model.Project{ID: 5, Name: "Restored", Description: "desc", ArchivedAt: &archivedAt, Version: 4}
+
+	if err := store.ReplaceProjects(ctx, []*model.Project{restored}, 7); err != nil {
+		t.Fatalf("failed to replace projects: %v", err)
+	}
+
+	if _, err := repo.GetProjectByID(ctx, 1); !errors.Is(err, usecase.ErrProjectNotFound) {
+		t.Fatalf("expected replaced project to be removed, got %v", err)
+	}
+
+	got, err := repo.GetProjectByID(ctx, 5)
+	if err != nil {
+		t.Fatalf("failed to get restored project: %v", err)
+	}
+
+	if got.Name != "Restored" || got.Description != "desc" || got.Version != 4 || got.ArchivedAt == nil || !got.ArchivedAt.Equal(archivedAt) {
+		t.Fatalf("unexpected restored project: %+v", got)
+	}
+
+	id, err := repo.CreateProject(ctx, &model.Project{Name: "New"})
+	if err != nil || id != 7 {
+		t.Fatalf("expected new project id 7, got %d (err %v)", id, err)
+	}
+
+	// счетчик ID не уменьшается
+	if err := store.ReplaceProjects(ctx, nil, 2); err != nil {
+		t.Fatalf("failed to replace projects: %v", err)
+	}
+
+	id, err = repo.CreateProject(ctx, &model.Project{Name: "Newer"})
+	if err != nil || id != 8 {
+		t.Fatalf("expected new project id 8, got %d (err %v)", id, err)
+	}
+}
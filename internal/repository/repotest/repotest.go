@@ -0,0 +1,1139 @@
+// Package repotest содержит набор тестов на соответствие контракту usecase.TaskRepo.
+// Новое хранилище подключается одним вызовом Run из его тестов
+package repotest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/solumD/tasks-service/internal/model"
+	"github.com/solumD/tasks-service/internal/usecase"
+)
+
+// Factory создает пустое хранилище для одного теста. Освобождение ресурсов
+// хранилища регистрируется через t.Cleanup
+type Factory func(t *testing.T) usecase.TaskRepo
+
+const (
+	concurrentWorkers = 8
+	concurrentOps     = 25
+)
+
+// Run проверяет хранилище, которое создает newRepo, на соответствие контракту usecase.TaskRepo
+func Run(t *testing.T, newRepo Factory) {
+	tests := []struct {
+		name string
+		test func(t *testing.T, repo usecase.TaskRepo)
+	}{
+		{name: "id assignment", test: testIDAssignment},
+		{name: "get all", test: testGetAll},
+		{name: "not found", test: testNotFound},
+		{name: "update", test: testUpdate},
+		{name: "version conflict", test: testVersionConflict},
+		{name: "trash", test: testTrash},
+		{name: "purge", test: testPurge},
+		{name: "due", test: testDue},
+		{name: "priority", test: testPriority},
+		{name: "tags", test: testTags},
+		{name: "rename tag", test: testRenameTag},
+		{name: "parent", test: testParent},
+		{name: "depends on", test: testDependsOn},
+		{name: "recurrence", test: testRecurrence},
+		{name: "status", test: testStatus},
+		{name: "project", test: testProject},
+		{name: "created at", test: testCreatedAt},
+		{name: "context cancellation", test: testContextCancellation},
+		{name: "concurrent create", test: testConcurrentCreate},
+		{name: "concurrent conditional update", test: testConcurrentConditionalUpdate},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.test(t, newRepo(t))
+		})
+	}
+}
+
+func mustCreate(t *testing.T, repo usecase.TaskRepo, title string) *model.Task {
+	t.Helper()
+
+	task := &model.Task{Title: title, Description: title + " desc"}
+	if _, err := repo.CreateTask(context.Background(), task); err != nil {
+		t.Fatalf("failed to create task %q: %v", title, err)
+	}
+
+	return task
+}
+
+func testIDAssignment(t *testing.T, repo usecase.TaskRepo) {
+	ctx := context.Background()
+
+	for want := 1; want <= 3; want++ {
+		task := &model.Task{Title: fmt.Sprintf("Task%d", want)}
+
+		id, err := repo.CreateTask(ctx, task)
+		if err != nil {
+			t.Fatalf("failed to create task: %v", err)
+		}
+
+		if id != want || task.ID != want {
+			t.Fatalf("expected ids to start at 1 and grow by creation order: want %d, got %d (task.ID %d)", want, id, task.ID)
+		}
+
+		if task.Version != 1 {
+			t.Fatalf("expected new task to have version 1, got %d", task.Version)
+		}
+	}
+
+	if _, err := repo.DeleteTask(ctx, 3, 0); err != nil {
+		t.Fatalf("failed to delete task: %v", err)
+	}
+
+	if _, err := repo.PurgeDeletedTasks(ctx, time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("failed to purge trash: %v", err)
+	}
+
+	id, err := repo.CreateTask(ctx, &model.Task{Title: "Task4"})
+	if err != nil || id != 4 {
+		t.Fatalf("expected purged id not to be reused and next id = 4, got %d (err %v)", id, err)
+	}
+}
+
+func testGetAll(t *testing.T, repo usecase.TaskRepo) {
+	ctx := context.Background()
+
+	tasks, err := repo.GetAllTasks(ctx)
+	if err != nil || len(tasks) != 0 {
+		t.Fatalf("expected empty list from empty repo, got %d tasks (err %v)", len(tasks), err)
+	}
+
+	created := make(map[int]*model.Task)
+	for i := 1; i <= 5; i++ {
+		task := mustCreate(t, repo, fmt.Sprintf("Task%d", i))
+		created[task.ID] = task
+	}
+
+	if _, err := repo.DeleteTask(ctx, 2, 0); err != nil {
+		t.Fatalf("failed to delete task: %v", err)
+	}
+	delete(created, 2)
+
+	tasks, err = repo.GetAllTasks(ctx)
+	if err != nil {
+		t.Fatalf("failed to get all tasks: %v", err)
+	}
+
+	// порядок списка не задан контрактом, поэтому сравниваются множества
+	seen := make(map[int]bool)
+	for _, task := range tasks {
+		want, ok := created[task.ID]
+		if !ok || seen[task.ID] {
+			t.Fatalf("expected every live task exactly once, got unexpected or repeated task %d", task.ID)
+		}
+
+		if task.Title != want.Title || task.Description != want.Description || task.Version != want.Version {
+			t.Fatalf("expected listed task %+v, got %+v", want, task)
+		}
+
+		seen[task.ID] = true
+	}
+
+	if len(seen) != len(created) {
+		t.Fatalf("expected %d tasks, got %d", len(created), len(seen))
+	}
+}
+
+func testNotFound(t *testing.T, repo usecase.TaskRepo) {
+	ctx := context.Background()
+
+	var notFound *usecase.TaskNotFoundError
+
+	if _, err := repo.GetTaskByID(ctx, 42); !errors.As(err, &notFound) || notFound.ID != 42 {
+		t.Fatalf("expected *TaskNotFoundError for id 42 on get, got %v", err)
+	}
+
+	if err := repo.UpdateTask(ctx, &model.Task{ID: 42, Title: "Task"}); !errors.Is(err, usecase.ErrTaskNotFound) {
+		t.Fatalf("expected ErrTaskNotFound on update, got %v", err)
+	}
+
+	if _, err := repo.DeleteTask(ctx, 42, 0); !errors.Is(err, usecase.ErrTaskNotFound) {
+		t.Fatalf("expected ErrTaskNotFound on delete, got %v", err)
+	}
+
+	if _, err := repo.RestoreTask(ctx, 42); !errors.Is(err, usecase.ErrTaskNotFound) {
+		t.Fatalf("expected ErrTaskNotFound on restore, got %v", err)
+	}
+
+	// версия не важна, если задачи нет
+	if err := repo.UpdateTask(ctx, &model.Task{ID: 42, Title: "Task", Version: 7}); !errors.Is(err, usecase.ErrTaskNotFound) {
+		t.Fatalf("expected ErrTaskNotFound on conditional update, got %v", err)
+	}
+}
+
+func testUpdate(t *testing.T, repo usecase.TaskRepo) {
+	ctx := context.Background()
+
+	created := mustCreate(t, repo, "Task1")
+
+	update := &model.Task{ID: created.ID, Title: "Task1 updated", Done: true}
+	if err := repo.UpdateTask(ctx, update); err != nil {
+		t.Fatalf("failed to update task: %v", err)
+	}
+
+	if update.Version <= created.Version {
+		t.Fatalf("expected version to grow after update, got %d after %d", update.Version, created.Version)
+	}
+
+	got, err := repo.GetTaskByID(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("failed to get task: %v", err)
+	}
+
+	// обновление полностью заменяет поля задачи
+	if got.Title != "Task1 updated" || got.Description != "" || !got.Done || got.Version != update.Version {
+		t.Fatalf("expected task to be replaced by update %+v, got %+v", update, got)
+	}
+
+	conditional := &model.Task{ID: created.ID, Title: "Task1 again", Version: update.Version}
+	if err := repo.UpdateTask(ctx, conditional); err != nil {
+		t.Fatalf("failed to update task with current version: %v", err)
+	}
+
+	if conditional.Version <= update.Version {
+		t.Fatalf("expected version to grow after conditional update, got %d after %d", conditional.Version, update.Version)
+	}
+}
+
+func testVersionConflict(t *testing.T, repo usecase.TaskRepo) {
+	ctx := context.Background()
+
+	created := mustCreate(t, repo, "Task1")
+
+	if err := repo.UpdateTask(ctx, &model.Task{ID: created.ID, Title: "Task1 updated"}); err != nil {
+		t.Fatalf("failed to update task: %v", err)
+	}
+
+	stale := &model.Task{ID: created.ID, Title: "Stale", Version: created.Version}
+	if err := repo.UpdateTask(ctx, stale); !errors.Is(err, usecase.ErrVersionConflict) {
+		t.Fatalf("expected ErrVersionConflict on stale update, got %v", err)
+	}
+
+	if _, err := repo.DeleteTask(ctx, created.ID, created.Version); !errors.Is(err, usecase.ErrVersionConflict) {
+		t.Fatalf("expected ErrVersionConflict on stale delete, got %v", err)
+	}
+
+	got, err := repo.GetTaskByID(ctx, created.ID)
+	if err != nil || got.Title != "Task1 updated" {
+		t.Fatalf("expected conflicting calls not to change task, got %+v (err %v)", got, err)
+	}
+}
+
+func testTrash(t *testing.T, repo usecase.TaskRepo) {
+	ctx := context.Background()
+
+	created := mustCreate(t, repo, "Task1")
+	mustCreate(t, repo, "Task2")
+
+	trashed, err := repo.DeleteTask(ctx, created.ID, created.Version)
+	if err != nil {
+		t.Fatalf("failed to delete task: %v", err)
+	}
+
+	if trashed.ID != created.ID || trashed.Title != created.Title || trashed.DeletedAt == nil || trashed.Version <= created.Version {
+		t.Fatalf("expected trashed task with deleted_at and newer version, got %+v", trashed)
+	}
+
+	if _, err := repo.GetTaskByID(ctx, created.ID); !errors.Is(err, usecase.ErrTaskNotFound) {
+		t.Fatalf("expected trashed task to be hidden from get, got %v", err)
+	}
+
+	if err := repo.UpdateTask(ctx, &model.Task{ID: created.ID, Title: "Task"}); !errors.Is(err, usecase.ErrTaskNotFound) {
+		t.Fatalf("expected trashed task to be hidden from update, got %v", err)
+	}
+
+	if _, err := repo.DeleteTask(ctx, created.ID, 0); !errors.Is(err, usecase.ErrTaskNotFound) {
+		t.Fatalf("expected second delete to return ErrTaskNotFound, got %v", err)
+	}
+
+	trash, err := repo.GetDeletedTasks(ctx)
+	if err != nil || len(trash) != 1 || trash[0].ID != created.ID || trash[0].DeletedAt == nil {
+		t.Fatalf("expected only task %d in trash, got %v (err %v)", created.ID, trash, err)
+	}
+
+	if tasks, _ := repo.GetAllTasks(ctx); len(tasks) != 1 {
+		t.Fatalf("expected trashed task to be hidden from list, got %d tasks", len(tasks))
+	}
+
+	restored, err := repo.RestoreTask(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("failed to restore task: %v", err)
+	}
+
+	if restored.DeletedAt != nil || restored.Title != created.Title || restored.Version <= trashed.Version {
+		t.Fatalf("expected restored task without deleted_at and with newer version, got %+v", restored)
+	}
+
+	if _, err := repo.RestoreTask(ctx, created.ID); !errors.Is(err, usecase.ErrTaskNotFound) {
+		t.Fatalf("expected restore of live task to return ErrTaskNotFound, got %v", err)
+	}
+
+	got, err := repo.GetTaskByID(ctx, created.ID)
+	if err != nil || got.Version != restored.Version {
+		t.Fatalf("expected restored task %+v, got %+v (err %v)", restored, got, err)
+	}
+
+	if trash, _ := repo.GetDeletedTasks(ctx); len(trash) != 0 {
+		t.Fatalf("expected empty trash after restore, got %v", trash)
+	}
+}
+
+func testPurge(t *testing.T, repo usecase.TaskRepo) {
+	ctx := context.Background()
+
+	first := mustCreate(t, repo, "Task1")
+	second := mustCreate(t, repo, "Task2")
+	mustCreate(t, repo, "Task3")
+
+	trashed, err := repo.DeleteTask(ctx, first.ID, 0)
+	if err != nil {
+		t.Fatalf("failed to delete task: %v", err)
+	}
+
+	if _, err := repo.DeleteTask(ctx, second.ID, 0); err != nil {
+		t.Fatalf("failed to delete task: %v", err)
+	}
+
+	// удаляются только задачи, попавшие в корзину строго раньше границы
+	purged, err := repo.PurgeDeletedTasks(ctx, *trashed.DeletedAt)
+	if err != nil || purged != 0 {
+		t.Fatalf("expected nothing purged at deletion time, got %d (err %v)", purged, err)
+	}
+
+	purged, err = repo.PurgeDeletedTasks(ctx, time.Now().Add(time.Second))
+	if err != nil || purged != 2 {
+		t.Fatalf("expected 2 purged tasks, got %d (err %v)", purged, err)
+	}
+
+	if _, err := repo.RestoreTask(ctx, first.ID); !errors.Is(err, usecase.ErrTaskNotFound) {
+		t.Fatalf("expected purged task to be gone, got %v", err)
+	}
+
+	if trash, _ := repo.GetDeletedTasks(ctx); len(trash) != 0 {
+		t.Fatalf("expected empty trash after purge, got %v", trash)
+	}
+
+	if tasks, _ := repo.GetAllTasks(ctx); len(tasks) != 1 {
+		t.Fatalf("expected purge to keep live tasks, got %d tasks", len(tasks))
+	}
+}
+
+func testDue(t *testing.T, repo usecase.TaskRepo) {
+	ctx := context.Background()
+
+	moscow := time.FixedZone("MSK", 3*60*60)
+	base := time.Date(2026, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+	due := func(hours int) *time.Time {
+		at := base.Add(time.Duration(hours) * time.Hour).In(moscow)
+		return &at
+	}
+
+	tasks := []*model.Task{
+		{Title: "Task1", DueAt: due(2)},
+		{Title: "Task2", DueAt: due(-1)},
+		{Title: "Task3"},
+		{Title: "Task4", DueAt: due(5)},
+		{Title: "Task5", DueAt: due(2)},
+	}
+
+	for _, task := range tasks {
+		if _, err := repo.CreateTask(ctx, task); err != nil {
+			t.Fatalf("failed to create task: %v", err)
+		}
+	}
+
+	got, err := repo.GetTaskByID(ctx, tasks[0].ID)
+	if err != nil {
+		t.Fatalf("failed to get task: %v", err)
+	}
+
+	// срок хранится как момент времени вместе со смещением часового пояса
+	if got.DueAt == nil || !got.DueAt.Equal(*tasks[0].DueAt) || got.DueAt.Format(time.RFC3339) != tasks[0].DueAt.Format(time.RFC3339) {
+		t.Fatalf("expected due date %v, got %v", tasks[0].DueAt, got.DueAt)
+	}
+
+	byDue := func(from, to time.Time) []int {
+		t.Helper()
+
+		found, err := repo.GetTasksByDue(ctx, from, to)
+		if err != nil {
+			t.Fatalf("failed to get tasks by due date: %v", err)
+		}
+
+		ids := make([]int, 0, len(found))
+		for _, task := range found {
+			ids = append(ids, task.ID)
+		}
+		sort.Ints(ids)
+
+		return ids
+	}
+
+	ranges := []struct {
+		name     string
+		from, to time.Time
+		expected []int
+	}{
+		{name: "unbounded", expected: []int{tasks[0].ID, tasks[1].ID, tasks[3].ID, tasks[4].ID}},
+		{name: "from inclusive", from: *due(2), expected: []int{tasks[0].ID, tasks[3].ID, tasks[4].ID}},
+		{name: "to exclusive", to: *due(2), expected: []int{tasks[1].ID}},
+		{name: "range in other zone", from: base.In(time.Local), to: base.Add(3 * time.Hour), expected: []int{tasks[0].ID, tasks[4].ID}},
+		{name: "empty", from: *due(10), expected: []int{}},
+	}
+
+	for _, r := range ranges {
+		if ids := byDue(r.from, r.to); !equalIDs(ids, r.expected) {
+			t.Fatalf("%s: expected tasks %v, got %v", r.name, r.expected, ids)
+		}
+	}
+
+	// перенос и снятие срока меняют выборку
+	if err := repo.UpdateTask(ctx, &model.Task{ID: tasks[0].ID, Title: "Task1", DueAt: due(-3)}); err != nil {
+		t.Fatalf("failed to update task: %v", err)
+	}
+
+	if err := repo.UpdateTask(ctx, &model.Task{ID: tasks[3].ID, Title: "Task4"}); err != nil {
+		t.Fatalf("failed to update task: %v", err)
+	}
+
+	if ids := byDue(time.Time{}, base); !equalIDs(ids, []int{tasks[0].ID, tasks[1].ID}) {
+		t.Fatalf("expected rescheduled task in range, got %v", ids)
+	}
+
+	if ids := byDue(base, time.Time{}); !equalIDs(ids, []int{tasks[4].ID}) {
+		t.Fatalf("expected task without due date to leave range, got %v", ids)
+	}
+
+	// задачи из корзины в выборку не попадают и возвращаются в нее после восстановления
+	if _, err := repo.DeleteTask(ctx, tasks[4].ID, 0); err != nil {
+		t.Fatalf("failed to delete task: %v", err)
+	}
+
+	if ids := byDue(time.Time{}, time.Time{}); !equalIDs(ids, []int{tasks[0].ID, tasks[1].ID}) {
+		t.Fatalf("expected trashed task to be hidden from due range, got %v", ids)
+	}
+
+	if _, err := repo.RestoreTask(ctx, tasks[4].ID); err != nil {
+		t.Fatalf("failed to restore task: %v", err)
+	}
+
+	if ids := byDue(base, time.Time{}); !equalIDs(ids, []int{tasks[4].ID}) {
+		t.Fatalf("expected restored task in due range, got %v", ids)
+	}
+}
+
+func testPriority(t *testing.T, repo usecase.TaskRepo) {
+	ctx := context.Background()
+
+	task := &model.Task{Title: "Task1", Priority: model.PriorityHigh}
+	if _, err := repo.CreateTask(ctx, task); err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+
+	got, err := repo.GetTaskByID(ctx, task.ID)
+	if err != nil {
+		t.Fatalf("failed to get task: %v", err)
+	}
+
+	if got.Priority != model.PriorityHigh {
+		t.Fatalf("expected priority %q, got %q", model.PriorityHigh, got.Priority)
+	}
+
+	if err := repo.UpdateTask(ctx, &model.Task{ID: task.ID, Title: "Task1", Priority: model.PriorityUrgent}); err != nil {
+		t.Fatalf("failed to update task: %v", err)
+	}
+
+	tasks, err := repo.GetAllTasks(ctx)
+	if err != nil {
+		t.Fatalf("failed to get all tasks: %v", err)
+	}
+
+	if len(tasks) != 1 || tasks[0].Priority != model.PriorityUrgent {
+		t.Fatalf("expected task with priority %q, got %+v", model.PriorityUrgent, tasks)
+	}
+}
+
+func testTags(t *testing.T, repo usecase.TaskRepo) {
+	ctx := context.Background()
+
+	tasks := []*model.Task{
+		{Title: "Task1", Tags: []string{"home", "urgent"}},
+		{Title: "Task2", Tags: []string{"work"}},
+		{Title: "Task3"},
+		{Title: "Task4", Tags: []string{"home", "work"}},
+	}
+
+	for _, task := range tasks {
+		if _, err := repo.CreateTask(ctx, task); err != nil {
+			t.Fatalf("failed to create task: %v", err)
+		}
+	}
+
+	got, err := repo.GetTaskByID(ctx, tasks[0].ID)
+	if err != nil {
+		t.Fatalf("failed to get task: %v", err)
+	}
+
+	if !equalTags(got.Tags, []string{"home", "urgent"}) {
+		t.Fatalf("expected tags [home urgent], got %v", got.Tags)
+	}
+
+	got, err = repo.GetTaskByID(ctx, tasks[2].ID)
+	if err != nil {
+		t.Fatalf("failed to get task: %v", err)
+	}
+
+	if len(got.Tags) != 0 {
+		t.Fatalf("expected task without tags, got %v", got.Tags)
+	}
+
+	byTags := func(all bool, tags ...string) []int {
+		t.Helper()
+
+		found, err := repo.GetTasksByTags(ctx, tags, all)
+		if err != nil {
+			t.Fatalf("failed to get tasks by tags: %v", err)
+		}
+
+		ids := make([]int, 0, len(found))
+		for _, task := range found {
+			ids = append(ids, task.ID)
+		}
+		sort.Ints(ids)
+
+		return ids
+	}
+
+	queries := []struct {
+		name     string
+		tags     []string
+		all      bool
+		expected []int
+	}{
+		{name: "any", tags: []string{"urgent", "work"}, expected: []int{tasks[0].ID, tasks[1].ID, tasks[3].ID}},
+		{name: "all", tags: []string{"home", "work"}, all: true, expected: []int{tasks[3].ID}},
+		{name: "all with unknown", tags: []string{"home", "gym"}, all: true, expected: []int{}},
+		{name: "unknown", tags: []string{"gym"}, expected: []int{}},
+	}
+
+	for _, q := range queries {
+		if ids := byTags(q.all, q.tags...); !equalIDs(ids, q.expected) {
+			t.Fatalf("%s: expected tasks %v, got %v", q.name, q.expected, ids)
+		}
+	}
+
+	assertCounts(t, repo, []model.TagCount{{Tag: "home", Count: 2}, {Tag: "urgent", Count: 1}, {Tag: "work", Count: 2}})
+
+	// смена меток меняет выборку, задачи из корзины в ней не учитываются
+	if err := repo.UpdateTask(ctx, &model.Task{ID: tasks[1].ID, Title: "Task2", Tags: []string{"home"}}); err != nil {
+		t.Fatalf("failed to update task: %v", err)
+	}
+
+	if _, err := repo.DeleteTask(ctx, tasks[0].ID, 0); err != nil {
+		t.Fatalf("failed to delete task: %v", err)
+	}
+
+	if ids := byTags(false, "home"); !equalIDs(ids, []int{tasks[1].ID, tasks[3].ID}) {
+		t.Fatalf("expected retagged task and no trashed task, got %v", ids)
+	}
+
+	assertCounts(t, repo, []model.TagCount{{Tag: "home", Count: 2}, {Tag: "work", Count: 1}})
+
+	if _, err := repo.RestoreTask(ctx, tasks[0].ID); err != nil {
+		t.Fatalf("failed to restore task: %v", err)
+	}
+
+	if ids := byTags(false, "urgent"); !equalIDs(ids, []int{tasks[0].ID}) {
+		t.Fatalf("expected restored task to keep its tags, got %v", ids)
+	}
+}
+
+func testRenameTag(t *testing.T, repo usecase.TaskRepo) {
+	ctx := context.Background()
+
+	tasks := []*model.Task{
+		{Title: "Task1", Tags: []string{"home"}},
+		{Title: "Task2", Tags: []string{"home", "house"}},
+		{Title: "Task3", Tags: []string{"work"}},
+		{Title: "Task4", Tags: []string{"home"}},
+	}
+
+	for _, task := range tasks {
+		if _, err := repo.CreateTask(ctx, task); err != nil {
+			t.Fatalf("failed to create task: %v", err)
+		}
+	}
+
+	trashed, err := repo.DeleteTask(ctx, tasks[3].ID, 0)
+	if err != nil {
+		t.Fatalf("failed to delete task: %v", err)
+	}
+
+	renamed, err := repo.RenameTag(ctx, "home", "house")
+	if err != nil {
+		t.Fatalf("failed to rename tag: %v", err)
+	}
+
+	ids := make([]int, 0, len(renamed))
+	for _, task := range renamed {
+		ids = append(ids, task.ID)
+
+		if task.Version != 2 || !task.HasTag("house") || task.HasTag("home") {
+			t.Fatalf("expected renamed task with bumped version, got %+v", task)
+		}
+	}
+	sort.Ints(ids)
+
+	if !equalIDs(ids, []int{tasks[0].ID, tasks[1].ID}) {
+		t.Fatalf("expected only tasks outside trash to be renamed, got %v", ids)
+	}
+
+	// метки сливаются, если новая метка у задачи уже была
+	got, err := repo.GetTaskByID(ctx, tasks[1].ID)
+	if err != nil {
+		t.Fatalf("failed to get task: %v", err)
+	}
+
+	if !equalTags(got.Tags, []string{"house"}) || got.Version != 2 {
+		t.Fatalf("expected merged tags [house] at version 2, got %v at version %d", got.Tags, got.Version)
+	}
+
+	got, err = repo.GetTaskByID(ctx, tasks[2].ID)
+	if err != nil {
+		t.Fatalf("failed to get task: %v", err)
+	}
+
+	if got.Version != 1 {
+		t.Fatalf("expected task without tag to keep version 1, got %d", got.Version)
+	}
+
+	assertCounts(t, repo, []model.TagCount{{Tag: "house", Count: 2}, {Tag: "work", Count: 1}})
+
+	// задача из корзины сохраняет старую метку
+	restored, err := repo.RestoreTask(ctx, trashed.ID)
+	if err != nil {
+		t.Fatalf("failed to restore task: %v", err)
+	}
+
+	if !equalTags(restored.Tags, []string{"home"}) {
+		t.Fatalf("expected trashed task to keep tag home, got %v", restored.Tags)
+	}
+
+	renamed, err = repo.RenameTag(ctx, "gym", "sport")
+	if err != nil || len(renamed) != 0 {
+		t.Fatalf("expected unknown tag to rename nothing, got %v (err %v)", renamed, err)
+	}
+}
+
+func testParent(t *testing.T, repo usecase.TaskRepo) {
+	ctx := context.Background()
+
+	root := mustCreate(t, repo, "Root")
+	other := mustCreate(t, repo, "Other")
+
+	tasks := []*model.Task{
+		{Title: "Child1", ParentID: root.ID},
+		{Title: "Child2", ParentID: root.ID},
+		{Title: "Child3", ParentID: other.ID},
+	}
+
+	for _, task := range tasks {
+		if _, err := repo.CreateTask(ctx, task); err != nil {
+			t.Fatalf("failed to create task: %v", err)
+		}
+	}
+
+	byParent := func(parentID int) []int {
+		t.Helper()
+
+		found, err := repo.GetTasksByParent(ctx, parentID)
+		if err != nil {
+			t.Fatalf("failed to get tasks by parent: %v", err)
+		}
+
+		ids := make([]int, 0, len(found))
+		for _, task := range found {
+			if task.ParentID != parentID {
+				t.Fatalf("expected task %d with parent %d, got %d", task.ID, parentID, task.ParentID)
+			}
+
+			ids = append(ids, task.ID)
+		}
+		sort.Ints(ids)
+
+		return ids
+	}
+
+	got, err := repo.GetTaskByID(ctx, tasks[0].ID)
+	if err != nil {
+		t.Fatalf("failed to get task: %v", err)
+	}
+
+	if got.ParentID != root.ID {
+		t.Fatalf("expected parent %d, got %d", root.ID, got.ParentID)
+	}
+
+	if ids := byParent(root.ID); !equalIDs(ids, []int{tasks[0].ID, tasks[1].ID}) {
+		t.Fatalf("expected children of root %v, got %v", []int{tasks[0].ID, tasks[1].ID}, ids)
+	}
+
+	if ids := byParent(tasks[0].ID); !equalIDs(ids, []int{}) {
+		t.Fatalf("expected task without children, got %v", ids)
+	}
+
+	// перенос подзадачи меняет выборку у обоих родителей
+	if err := repo.UpdateTask(ctx, &model.Task{ID: tasks[1].ID, Title: "Child2", ParentID: other.ID}); err != nil {
+		t.Fatalf("failed to update task: %v", err)
+	}
+
+	if ids := byParent(root.ID); !equalIDs(ids, []int{tasks[0].ID}) {
+		t.Fatalf("expected moved task to leave root, got %v", ids)
+	}
+
+	if ids := byParent(other.ID); !equalIDs(ids, []int{tasks[1].ID, tasks[2].ID}) {
+		t.Fatalf("expected moved task under other, got %v", ids)
+	}
+
+	// задачи из корзины в выборку не попадают, а после восстановления возвращаются
+	if _, err := repo.DeleteTask(ctx, tasks[2].ID, 0); err != nil {
+		t.Fatalf("failed to delete task: %v", err)
+	}
+
+	if ids := byParent(other.ID); !equalIDs(ids, []int{tasks[1].ID}) {
+		t.Fatalf("expected no trashed task, got %v", ids)
+	}
+
+	if _, err := repo.RestoreTask(ctx, tasks[2].ID); err != nil {
+		t.Fatalf("failed to restore task: %v", err)
+	}
+
+	if ids := byParent(other.ID); !equalIDs(ids, []int{tasks[1].ID, tasks[2].ID}) {
+		t.Fatalf("expected restored task to keep its parent, got %v", ids)
+	}
+}
+
+func testDependsOn(t *testing.T, repo usecase.TaskRepo) {
+	ctx := context.Background()
+
+	first := mustCreate(t, repo, "First")
+	second := mustCreate(t, repo, "Second")
+
+	task := &model.Task{Title: "Third", DependsOn: []int{first.ID, second.ID}}
+	if _, err := repo.CreateTask(ctx, task); err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+
+	dependsOn := func() []int {
+		t.Helper()
+
+		got, err := repo.GetTaskByID(ctx, task.ID)
+		if err != nil {
+			t.Fatalf("failed to get task: %v", err)
+		}
+
+		return got.DependsOn
+	}
+
+	if ids := dependsOn(); !equalIDs(ids, []int{first.ID, second.ID}) {
+		t.Fatalf("expected dependencies %v, got %v", []int{first.ID, second.ID}, ids)
+	}
+
+	if err := repo.UpdateTask(ctx, &model.Task{ID: task.ID, Title: "Third", DependsOn: []int{second.ID}}); err != nil {
+		t.Fatalf("failed to update task: %v", err)
+	}
+
+	if ids := dependsOn(); !equalIDs(ids, []int{second.ID}) {
+		t.Fatalf("expected dependencies %v, got %v", []int{second.ID}, ids)
+	}
+
+	// зависимости переживают корзину
+	if _, err := repo.DeleteTask(ctx, task.ID, 0); err != nil {
+		t.Fatalf("failed to delete task: %v", err)
+	}
+
+	if _, err := repo.RestoreTask(ctx, task.ID); err != nil {
+		t.Fatalf("failed to restore task: %v", err)
+	}
+
+	if ids := dependsOn(); !equalIDs(ids, []int{second.ID}) {
+		t.Fatalf("expected restored task to keep its dependencies, got %v", ids)
+	}
+
+	if err := repo.UpdateTask(ctx, &model.Task{ID: task.ID, Title: "Third"}); err != nil {
+		t.Fatalf("failed to update task: %v", err)
+	}
+
+	if ids := dependsOn(); len(ids) != 0 {
+		t.Fatalf("expected no dependencies, got %v", ids)
+	}
+}
+
+func assertCounts(t *testing.T, repo usecase.TaskRepo, expected []model.TagCount) {
+	t.Helper()
+
+	counts, err := repo.GetTagCounts(context.Background())
+	if err != nil {
+		t.Fatalf("failed to get tag counts: %v", err)
+	}
+
+	if len(counts) != len(expected) {
+		t.Fatalf("expected tag counts %v, got %v", expected, counts)
+	}
+
+	for i := range expected {
+		if counts[i] != expected[i] {
+			t.Fatalf("expected tag counts %v, got %v", expected, counts)
+		}
+	}
+}
+
+func equalTags(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func equalIDs(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func testContextCancellation(t *testing.T, repo usecase.TaskRepo) {
+	created := mustCreate(t, repo, "Task1")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	calls := []struct {
+		name string
+		call func() error
+	}{
+		{name: "create", call: func() error {
+			_, err := repo.CreateTask(ctx, &model.Task{Title: "Task2"})
+			return err
+		}},
+		{name: "get all", call: func() error {
+			_, err := repo.GetAllTasks(ctx)
+			return err
+		}},
+		{name: "get", call: func() error {
+			_, err := repo.GetTaskByID(ctx, created.ID)
+			return err
+		}},
+		{name: "get by due", call: func() error {
+			_, err := repo.GetTasksByDue(ctx, time.Time{}, time.Time{})
+			return err
+		}},
+		{name: "get by tags", call: func() error {
+			_, err := repo.GetTasksByTags(ctx, []string{"home"}, false)
+			return err
+		}},
+		{name: "get by parent", call: func() error {
+			_, err := repo.GetTasksByParent(ctx, created.ID)
+			return err
+		}},
+		{name: "get tag counts", call: func() error {
+			_, err := repo.GetTagCounts(ctx)
+			return err
+		}},
+		{name: "rename tag", call: func() error {
+			_, err := repo.RenameTag(ctx, "home", "house")
+			return err
+		}},
+		{name: "update", call: func() error {
+			return repo.UpdateTask(ctx, &model.Task{ID: created.ID, Title: "Cancelled"})
+		}},
+		{name: "delete", call: func() error {
+			_, err := repo.DeleteTask(ctx, created.ID, 0)
+			return err
+		}},
+		{name: "get deleted", call: func() error {
+			_, err := repo.GetDeletedTasks(ctx)
+			return err
+		}},
+		{name: "restore", call: func() error {
+			_, err := repo.RestoreTask(ctx, created.ID)
+			return err
+		}},
+		{name: "purge", call: func() error {
+			_, err := repo.PurgeDeletedTasks(ctx, time.Now())
+			return err
+		}},
+	}
+
+	for _, c := range calls {
+		if err := c.call(); !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected context.Canceled on %s, got %v", c.name, err)
+		}
+	}
+
+	tasks, err := repo.GetAllTasks(context.Background())
+	if err != nil || len(tasks) != 1 || tasks[0].Title != "Task1" || tasks[0].Version != created.Version {
+		t.Fatalf("expected cancelled calls not to change repo, got %v (err %v)", tasks, err)
+	}
+}
+
+func testConcurrentCreate(t *testing.T, repo usecase.TaskRepo) {
+	ctx := context.Background()
+
+	const total = concurrentWorkers * concurrentOps
+
+	ids := make(chan int, total)
+
+	var wg sync.WaitGroup
+	for w := 0; w < concurrentWorkers; w++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for i := 0; i < concurrentOps; i++ {
+				id, err := repo.CreateTask(ctx, &model.Task{Title: "task"})
+				if err != nil {
+					t.Errorf("failed to create task: %v", err)
+					return
+				}
+
+				ids <- id
+			}
+		}()
+	}
+
+	wg.Wait()
+	close(ids)
+
+	seen := make(map[int]bool, total)
+	for id := range ids {
+		if seen[id] {
+			t.Fatalf("id %d assigned twice", id)
+		}
+
+		seen[id] = true
+	}
+
+	tasks, err := repo.GetAllTasks(ctx)
+	if err != nil || len(tasks) != total {
+		t.Fatalf("expected %d tasks, got %d (err %v)", total, len(tasks), err)
+	}
+}
+
+func testConcurrentConditionalUpdate(t *testing.T, repo usecase.TaskRepo) {
+	ctx := context.Background()
+
+	created := mustCreate(t, repo, "Task1")
+
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		succeeded []string
+	)
+
+	// все пишут с одной ожидаемой версией, поэтому выиграть может только один
+	for w := 0; w < concurrentWorkers; w++ {
+		wg.Add(1)
+
+		go func(worker int) {
+			defer wg.Done()
+
+			title := fmt.Sprintf("worker %d", worker)
+
+			err := repo.UpdateTask(ctx, &model.Task{ID: created.ID, Title: title, Version: created.Version})
+			switch {
+			case err == nil:
+				mu.Lock()
+				succeeded = append(succeeded, title)
+				mu.Unlock()
+			case !errors.Is(err, usecase.ErrVersionConflict):
+				t.Errorf("expected ErrVersionConflict for losers, got %v", err)
+			}
+		}(w)
+	}
+
+	wg.Wait()
+
+	if len(succeeded) != 1 {
+		t.Fatalf("expected exactly one conditional update to win, got %d", len(succeeded))
+	}
+
+	got, err := repo.GetTaskByID(ctx, created.ID)
+	if err != nil || got.Title != succeeded[0] {
+		t.Fatalf("expected winner %q to be stored, got %+v (err %v)", succeeded[0], got, err)
+	}
+}
+
+func testRecurrence(t *testing.T, repo usecase.TaskRepo) {
+	ctx := context.Background()
+
+	due := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	task := &model.Task{Title: "Weekly", DueAt: &due, Recurrence: "FREQ=WEEKLY;BYDAY=MO,TH;COUNT=5"}
+	if _, err := repo.CreateTask(ctx, task); err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+
+	recurrence := func() string {
+		t.Helper()
+
+		got, err := repo.GetTaskByID(ctx, task.ID)
+		if err != nil {
+			t.Fatalf("failed to get task: %v", err)
+		}
+
+		return got.Recurrence
+	}
+
+	if got := recurrence(); got != task.Recurrence {
+		t.Fatalf("expected recurrence %q, got %q", task.Recurrence, got)
+	}
+
+	if err := repo.UpdateTask(ctx, &model.Task{ID: task.ID, Title: "Weekly", Done: true, DueAt: &due}); err != nil {
+		t.Fatalf("failed to update task: %v", err)
+	}
+
+	if got := recurrence(); got != "" {
+		t.Fatalf("expected no recurrence, got %q", got)
+	}
+}
+
+func testStatus(t *testing.T, repo usecase.TaskRepo) {
+	ctx := context.Background()
+
+	task := &model.Task{Title: "Task", Status: model.StatusInProgress}
+	if _, err := repo.CreateTask(ctx, task); err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+
+	get := func() *model.Task {
+		t.Helper()
+
+		got, err := repo.GetTaskByID(ctx, task.ID)
+		if err != nil {
+			t.Fatalf("failed to get task: %v", err)
+		}
+
+		return got
+	}
+
+	if got := get(); got.Status != model.StatusInProgress || got.Done {
+		t.Fatalf("expected status %s, got %s (done %v)", model.StatusInProgress, got.Status, got.Done)
+	}
+
+	if err := repo.UpdateTask(ctx, &model.Task{ID: task.ID, Title: "Task", Status: model.StatusDone, Done: true}); err != nil {
+		t.Fatalf("failed to update task: %v", err)
+	}
+
+	if got := get(); got.Status != model.StatusDone || !got.Done {
+		t.Fatalf("expected status %s, got %s (done %v)", model.StatusDone, got.Status, got.Done)
+	}
+
+	if err := repo.UpdateTask(ctx, &model.Task{ID: task.ID, Title: "Task", Status: model.StatusCancelled}); err != nil {
+		t.Fatalf("failed to update task: %v", err)
+	}
+
+	if got := get(); got.Status != model.StatusCancelled || got.Done {
+		t.Fatalf("expected status %s, got %s (done %v)", model.StatusCancelled, got.Status, got.Done)
+	}
+}
+
+func testProject(t *testing.T, repo usecase.TaskRepo) {
+	ctx := context.Background()
+
+	task := &model.Task{Title: "Task", ProjectID: 2}
+	if _, err := repo.CreateTask(ctx, task); err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+
+	get := func() *model.Task {
+		t.Helper()
+
+		got, err := repo.GetTaskByID(ctx, task.ID)
+		if err != nil {
+			t.Fatalf("failed to get task: %v", err)
+		}
+
+		return got
+	}
+
+	if got := get(); got.ProjectID != 2 {
+		t.Fatalf("expected project 2, got %d", got.ProjectID)
+	}
+
+	for _, projectID := range []int{3, 0} {
+		if err := repo.UpdateTask(ctx, &model.Task{ID: task.ID, Title: "Task", ProjectID: projectID}); err != nil {
+			t.Fatalf("failed to update task: %v", err)
+		}
+
+		if got := get(); got.ProjectID != projectID {
+			t.Fatalf("expected project %d, got %d", projectID, got.ProjectID)
+		}
+	}
+}
+
+func testCreatedAt(t *testing.T, repo usecase.TaskRepo) {
+	ctx := context.Background()
+	createdAt := time.Date(2026, time.January, 10, 9, 0, 0, 123456789, time.UTC)
+
+	task := &model.Task{Title: "Task", CreatedAt: createdAt}
+	if _, err := repo.CreateTask(ctx, task); err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+
+	got, err := repo.GetTaskByID(ctx, task.ID)
+	if err != nil {
+		t.Fatalf("failed to get task: %v", err)
+	}
+
+	if !got.CreatedAt.Equal(createdAt) {
+		t.Fatalf("expected created at %v, got %v", createdAt, got.CreatedAt)
+	}
+
+	// обновление без времени создания его не меняет
+	update := &model.Task{ID: task.ID, Title: "Task updated"}
+	if err := repo.UpdateTask(ctx, update); err != nil {
+		t.Fatalf("failed to update task: %v", err)
+	}
+
+	if !update.CreatedAt.Equal(createdAt) {
+		t.Fatalf("expected updated task to have created at %v, got %v", createdAt, update.CreatedAt)
+	}
+
+	got, err = repo.GetTaskByID(ctx, task.ID)
+	if err != nil {
+		t.Fatalf("failed to get task: %v", err)
+	}
+
+	if !got.CreatedAt.Equal(createdAt) {
+		t.Fatalf("expected created at %v after update, got %v", createdAt, got.CreatedAt)
+	}
+}
@@ -0,0 +1,78 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/solumD/tasks-service/internal/model"
+)
+
+// DumpTasks возвращает все задачи, включая корзину, и ID следующей задачи,
+// прочитанные в одной транзакции
+func (r *taskRepo) DumpTasks(ctx context.Context) ([]*model.Task, int, error) {
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `SELECT `+taskColumns+` FROM tasks`)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to select tasks: %w", err)
+	}
+	defer rows.Close()
+
+	tasks := make([]*model.Task, 0)
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan task: %w", err)
+		}
+
+		tasks = append(tasks, task)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to iterate tasks: %w", err)
+	}
+
+	next, err := nextID(ctx, tx, "tasks")
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return tasks, next, nil
+}
+
+// ReplaceTasks в одной транзакции заменяет все задачи, включая корзину, на tasks.
+// Счетчик ID не уменьшается, а следующая задача получит ID не меньше nextID
+func (r *taskRepo) ReplaceTasks(ctx context.Context, tasks []*model.Task, nextID int) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM tasks`); err != nil {
+		return fmt.Errorf("failed to delete tasks: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM task_tags`); err != nil {
+		return fmt.Errorf("failed to delete task tags: %w", err)
+	}
+
+	if err := upsertTasks(ctx, tx, tasks); err != nil {
+		return err
+	}
+
+	if err := advanceSequence(ctx, tx, "tasks", nextID); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit tx: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,278 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/solumD/tasks-service/internal/model"
+	"github.com/solumD/tasks-service/internal/usecase"
+)
+
+// commentColumns колонки комментария в порядке, который ожидает scanComment
+const commentColumns = `id, task_id, parent_id, author, text, created_at, edited_at`
+
+type commentRepo struct {
+	db *sql.DB
+}
+
+// CommentRepo возвращает хранилище комментариев в той же базе, что и задачи.
+// Соединение закрывается вместе с хранилищем задач
+func (r *taskRepo) CommentRepo() *commentRepo {
+	return &commentRepo{db: r.db}
+}
+
+// CreateComment создает новый комментарий в хранилище
+func (r *commentRepo) CreateComment(ctx context.Context, comment *model.Comment) (int, error) {
+	res, err := r.db.ExecContext(ctx,
+		`INSERT INTO comments (task_id, parent_id, author, text, created_at, edited_at) VALUES (?, ?, ?, ?, ?, ?)`,
+		comment.TaskID, comment.ParentID, comment.Author, comment.Text, comment.CreatedAt.UTC(), editedValue(comment),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to insert comment: %w", err)
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get inserted comment id: %w", err)
+	}
+
+	comment.ID = int(id)
+
+	return comment.ID, nil
+}
+
+// GetComments возвращает до limit комментариев верхнего уровня задачи taskID с ID больше after
+// по индексу на колонках task_id, parent_id, id
+func (r *commentRepo) GetComments(ctx context.Context, taskID int, after int, limit int) ([]*model.Comment, error) {
+	return r.selectComments(ctx,
+		`SELECT `+commentColumns+` FROM comments WHERE task_id = ? AND parent_id = 0 AND id > ? ORDER BY id LIMIT ?`,
+		taskID, after, limit,
+	)
+}
+
+// GetReplies возвращает ответы на комментарии parentIDs по возрастанию ID
+func (r *commentRepo) GetReplies(ctx context.Context, parentIDs []int) ([]*model.Comment, error) {
+	if len(parentIDs) == 0 {
+		return []*model.Comment{}, nil
+	}
+
+	args := make([]any, 0, len(parentIDs))
+	for _, id := range parentIDs {
+		args = append(args, id)
+	}
+
+	return r.selectComments(ctx,
+		`SELECT `+commentColumns+` FROM comments WHERE parent_id IN (?`+strings.Repeat(`, ?`, len(parentIDs)-1)+`) ORDER BY id`,
+		args...,
+	)
+}
+
+// GetCommentByID возвращает комментарий по ID из хранилища
+func (r *commentRepo) GetCommentByID(ctx context.Context, id int) (*model.Comment, error) {
+	comment, err := scanComment(r.db.QueryRowContext(ctx, `SELECT `+commentColumns+` FROM comments WHERE id = ?`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, usecase.NewCommentNotFoundError(id)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get comment: %w", err)
+	}
+
+	return comment, nil
+}
+
+// UpdateComment меняет текст и время изменения комментария
+func (r *commentRepo) UpdateComment(ctx context.Context, comment *model.Comment) error {
+	res, err := r.db.ExecContext(ctx,
+		`UPDATE comments SET text = ?, edited_at = ? WHERE id = ?`,
+		comment.Text, editedValue(comment), comment.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update comment: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if affected == 0 {
+		return usecase.NewCommentNotFoundError(comment.ID)
+	}
+
+	return nil
+}
+
+// DeleteComment удаляет комментарий вместе с ответами на него. Ответов на ответы
+// не бывает, поэтому для ответа условие на parent_id ничего не находит
+func (r *commentRepo) DeleteComment(ctx context.Context, id int) (int, error) {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM comments WHERE id = ? OR parent_id = ?`, id, id)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete comment: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if affected == 0 {
+		return 0, usecase.NewCommentNotFoundError(id)
+	}
+
+	return int(affected), nil
+}
+
+// DeleteTaskComments удаляет все комментарии задач taskIDs
+func (r *commentRepo) DeleteTaskComments(ctx context.Context, taskIDs []int) (int, error) {
+	if len(taskIDs) == 0 {
+		return 0, nil
+	}
+
+	args := make([]any, 0, len(taskIDs))
+	for _, id := range taskIDs {
+		args = append(args, id)
+	}
+
+	res, err := r.db.ExecContext(ctx,
+		`DELETE FROM comments WHERE task_id IN (?`+strings.Repeat(`, ?`, len(taskIDs)-1)+`)`, args...,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete task comments: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	return int(affected), nil
+}
+
+// DumpComments в одной транзакции возвращает все комментарии по возрастанию ID
+// и ID следующего комментария
+func (r *commentRepo) DumpComments(ctx context.Context) ([]*model.Comment, int, error) {
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `SELECT `+commentColumns+` FROM comments ORDER BY id`)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query comments: %w", err)
+	}
+	defer rows.Close()
+
+	comments := make([]*model.Comment, 0)
+	for rows.Next() {
+		comment, err := scanComment(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan comment: %w", err)
+		}
+
+		comments = append(comments, comment)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to iterate comments: %w", err)
+	}
+
+	next, err := nextID(ctx, tx, "comments")
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return comments, next, nil
+}
+
+// ReplaceComments в одной транзакции заменяет все комментарии на comments.
+// Счетчик ID не уменьшается, а следующий комментарий получит ID не меньше nextID
+func (r *commentRepo) ReplaceComments(ctx context.Context, comments []*model.Comment, nextID int) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM comments`); err != nil {
+		return fmt.Errorf("failed to delete comments: %w", err)
+	}
+
+	for _, comment := range comments {
+		_, err := tx.ExecContext(ctx,
+			`INSERT INTO comments (`+commentColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			comment.ID, comment.TaskID, comment.ParentID, comment.Author, comment.Text, comment.CreatedAt.UTC(), editedValue(comment),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to insert comment %d: %w", comment.ID, err)
+		}
+	}
+
+	if err := advanceSequence(ctx, tx, "comments", nextID); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit tx: %w", err)
+	}
+
+	return nil
+}
+
+func (r *commentRepo) selectComments(ctx context.Context, query string, args ...any) ([]*model.Comment, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query comments: %w", err)
+	}
+	defer rows.Close()
+
+	comments := make([]*model.Comment, 0)
+	for rows.Next() {
+		comment, err := scanComment(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan comment: %w", err)
+		}
+
+		comments = append(comments, comment)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate comments: %w", err)
+	}
+
+	return comments, nil
+}
+
+// editedValue значение колонки edited_at
+func editedValue(comment *model.Comment) sql.NullTime {
+	if comment.EditedAt == nil {
+		return sql.NullTime{}
+	}
+
+	return sql.NullTime{Time: comment.EditedAt.UTC(), Valid: true}
+}
+
+// scanComment читает комментарий из строки с колонками commentColumns
+func scanComment(row scanner) (*model.Comment, error) {
+	comment := &model.Comment{}
+
+	var editedAt sql.NullTime
+
+	err := row.Scan(&comment.ID, &comment.TaskID, &comment.ParentID, &comment.Author, &comment.Text, &comment.CreatedAt, &editedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	comment.CreatedAt = comment.CreatedAt.UTC()
+
+	if editedAt.Valid {
+		t := editedAt.Time.UTC()
+		comment.EditedAt = &t
+	}
+
+	return comment, nil
+}
@@ -0,0 +1,124 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/solumD/tasks-service/internal/model"
+)
+
+// NextID возвращает ID, который получит следующая созданная задача.
+// Счетчик AUTOINCREMENT хранится в служебной таблице SQLite sqlite_sequence
+func (r *taskRepo) NextID(ctx context.Context) (int, error) {
+	return nextID(ctx, r.db, "tasks")
+}
+
+// ImportTasks в одной транзакции записывает задачи с их ID, версиями и временем удаления,
+// заменяя задачи с теми же ID, и сдвигает счетчик так, чтобы следующая задача
+// получила ID не меньше nextID
+func (r *taskRepo) ImportTasks(ctx context.Context, tasks []*model.Task, nextID int) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := upsertTasks(ctx, tx, tasks); err != nil {
+		return err
+	}
+
+	if err := advanceSequence(ctx, tx, "tasks", nextID); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit tx: %w", err)
+	}
+
+	return nil
+}
+
+type queryRower interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// nextID возвращает ID, который получит следующая строка таблицы table
+func nextID(ctx context.Context, q queryRower, table string) (int, error) {
+	var seq int
+
+	err := q.QueryRowContext(ctx,
+		`SELECT COALESCE((SELECT seq FROM sqlite_sequence WHERE name = ?), 0)`, table,
+	).Scan(&seq)
+	if err != nil {
+		return 0, fmt.Errorf("failed to select %s id sequence: %w", table, err)
+	}
+
+	return seq + 1, nil
+}
+
+// upsertTasks записывает задачи как есть, заменяя задачи с теми же ID
+func upsertTasks(ctx context.Context, tx *sql.Tx, tasks []*model.Task) error {
+	for _, task := range tasks {
+		var deletedAt any
+		if task.DeletedAt != nil {
+			deletedAt = task.DeletedAt.UTC()
+		}
+
+		dueAt, dueUTC := dueValues(task.DueAt)
+
+		tags, err := tagsValue(task.Tags)
+		if err != nil {
+			return err
+		}
+
+		dependsOn, err := dependsOnValue(task.DependsOn)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx,
+			`INSERT INTO tasks (`+taskColumns+`, due_utc) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT (id) DO UPDATE SET title = excluded.title, description = excluded.description,
+			done = excluded.done, version = excluded.version, deleted_at = excluded.deleted_at,
+			due_at = excluded.due_at, priority = excluded.priority, tags = excluded.tags, parent_id = excluded.parent_id,
+			depends_on = excluded.depends_on, recurrence = excluded.recurrence, status = excluded.status,
+			project_id = excluded.project_id, created_at = excluded.created_at, due_utc = excluded.due_utc`,
+			task.ID, task.Title, task.Description, task.Done, task.Version, deletedAt, dueAt, string(task.Priority), tags, task.ParentID,
+			dependsOn, task.Recurrence, string(task.Status), task.ProjectID, createdValue(task.CreatedAt), dueUTC,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to import task %d: %w", task.ID, err)
+		}
+
+		if err := writeTagIndex(ctx, tx, task.ID, task.Tags); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// advanceSequence сдвигает счетчик AUTOINCREMENT таблицы table так, чтобы следующая
+// строка получила ID не меньше nextID. Явная вставка ID поднимает sqlite_sequence сама,
+// остается учесть ID без строк
+func advanceSequence(ctx context.Context, tx *sql.Tx, table string, nextID int) error {
+	_, err := tx.ExecContext(ctx,
+		`UPDATE sqlite_sequence SET seq = ? WHERE name = ? AND seq < ?`,
+		nextID-1, table, nextID-1,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to advance %s id sequence: %w", table, err)
+	}
+
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO sqlite_sequence (name, seq)
+		SELECT ?, ? WHERE ? > 0 AND NOT EXISTS (SELECT 1 FROM sqlite_sequence WHERE name = ?)`,
+		table, nextID-1, nextID-1, table,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to init %s id sequence: %w", table, err)
+	}
+
+	return nil
+}
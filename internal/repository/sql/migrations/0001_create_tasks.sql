@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS tasks (
+    id          INTEGER PRIMARY KEY AUTOINCREMENT,
+    title       TEXT    NOT NULL,
+    description TEXT    NOT NULL DEFAULT '',
+    done        BOOLEAN NOT NULL DEFAULT FALSE
+);
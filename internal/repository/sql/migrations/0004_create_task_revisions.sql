@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS task_revisions (
+    task_id     INTEGER   NOT NULL,
+    rev         INTEGER   NOT NULL,
+    action      TEXT      NOT NULL,
+    author      TEXT      NOT NULL DEFAULT '',
+    created_at  TIMESTAMP NOT NULL,
+    title       TEXT      NOT NULL,
+    description TEXT      NOT NULL DEFAULT '',
+    done        BOOLEAN   NOT NULL DEFAULT FALSE,
+    deleted_at  TIMESTAMP NULL,
+    PRIMARY KEY (task_id, rev)
+);
@@ -0,0 +1,6 @@
+ALTER TABLE tasks ADD COLUMN due_at TEXT NULL;
+ALTER TABLE tasks ADD COLUMN due_utc TEXT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_tasks_due_utc ON tasks (due_utc);
+
+ALTER TABLE task_revisions ADD COLUMN due_at TEXT NULL;
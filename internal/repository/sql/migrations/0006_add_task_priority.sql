@@ -0,0 +1,3 @@
+ALTER TABLE tasks ADD COLUMN priority TEXT NOT NULL DEFAULT 'none';
+
+ALTER TABLE task_revisions ADD COLUMN priority TEXT NOT NULL DEFAULT 'none';
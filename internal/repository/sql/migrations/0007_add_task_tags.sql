@@ -0,0 +1,11 @@
+ALTER TABLE tasks ADD COLUMN tags TEXT NOT NULL DEFAULT '[]';
+
+CREATE TABLE IF NOT EXISTS task_tags (
+    tag     TEXT    NOT NULL,
+    task_id INTEGER NOT NULL,
+    PRIMARY KEY (tag, task_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_tags_task_id ON task_tags (task_id);
+
+ALTER TABLE task_revisions ADD COLUMN tags TEXT NOT NULL DEFAULT '[]';
@@ -0,0 +1,5 @@
+ALTER TABLE tasks ADD COLUMN parent_id INTEGER NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_tasks_parent_id ON tasks (parent_id);
+
+ALTER TABLE task_revisions ADD COLUMN parent_id INTEGER NOT NULL DEFAULT 0;
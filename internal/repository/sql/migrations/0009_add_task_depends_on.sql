@@ -0,0 +1,3 @@
+ALTER TABLE tasks ADD COLUMN depends_on TEXT NOT NULL DEFAULT '[]';
+
+ALTER TABLE task_revisions ADD COLUMN depends_on TEXT NOT NULL DEFAULT '[]';
@@ -0,0 +1,3 @@
+ALTER TABLE tasks ADD COLUMN recurrence TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE task_revisions ADD COLUMN recurrence TEXT NOT NULL DEFAULT '';
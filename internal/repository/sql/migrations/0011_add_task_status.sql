@@ -0,0 +1,3 @@
+ALTER TABLE tasks ADD COLUMN status TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE task_revisions ADD COLUMN status TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS projects (
+    id          INTEGER PRIMARY KEY AUTOINCREMENT,
+    name        TEXT      NOT NULL,
+    description TEXT      NOT NULL DEFAULT '',
+    archived_at TIMESTAMP NULL,
+    version     INTEGER   NOT NULL DEFAULT 1
+);
+
+ALTER TABLE tasks ADD COLUMN project_id INTEGER NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_tasks_project_id ON tasks (project_id);
+
+ALTER TABLE task_revisions ADD COLUMN project_id INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS comments (
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    task_id    INTEGER   NOT NULL,
+    parent_id  INTEGER   NOT NULL DEFAULT 0,
+    author     TEXT      NOT NULL,
+    text       TEXT      NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    edited_at  TIMESTAMP NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_comments_task_id ON comments (task_id, parent_id, id);
+
+CREATE INDEX IF NOT EXISTS idx_comments_parent_id ON comments (parent_id, id);
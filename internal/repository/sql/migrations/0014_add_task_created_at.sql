@@ -0,0 +1,3 @@
+ALTER TABLE tasks ADD COLUMN created_at TIMESTAMP NULL;
+
+ALTER TABLE task_revisions ADD COLUMN task_created_at TIMESTAMP NULL;
@@ -0,0 +1,243 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/solumD/tasks-service/internal/model"
+	"github.com/solumD/tasks-service/internal/usecase"
+)
+
+// projectColumns колонки проекта в порядке, который ожидает scanProject
+const projectColumns = `id, name, description, archived_at, version`
+
+type projectRepo struct {
+	db *sql.DB
+}
+
+// ProjectRepo возвращает хранилище проектов в той же базе, что и задачи.
+// Соединение закрывается вместе с хранилищем задач
+func (r *taskRepo) ProjectRepo() *projectRepo {
+	return &projectRepo{db: r.db}
+}
+
+// CreateProject создает новый проект в хранилище
+func (r *projectRepo) CreateProject(ctx context.Context, project *model.Project) (int, error) {
+	res, err := r.db.ExecContext(ctx,
+		`INSERT INTO projects (name, description, archived_at) VALUES (?, ?, ?)`,
+		project.Name, project.Description, archivedValue(project.ArchivedAt),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to insert project: %w", err)
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get inserted project id: %w", err)
+	}
+
+	project.ID = int(id)
+	project.Version = 1
+
+	return project.ID, nil
+}
+
+// GetProjects возвращает все проекты по возрастанию ID
+func (r *projectRepo) GetProjects(ctx context.Context) ([]*model.Project, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+projectColumns+` FROM projects ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query projects: %w", err)
+	}
+	defer rows.Close()
+
+	projects := make([]*model.Project, 0)
+	for rows.Next() {
+		project, err := scanProject(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan project: %w", err)
+		}
+
+		projects = append(projects, project)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate projects: %w", err)
+	}
+
+	return projects, nil
+}
+
+// GetProjectByID возвращает проект по ID из хранилища
+func (r *projectRepo) GetProjectByID(ctx context.Context, id int) (*model.Project, error) {
+	project, err := scanProject(r.db.QueryRowContext(ctx, `SELECT `+projectColumns+` FROM projects WHERE id = ?`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, usecase.NewProjectNotFoundError(id)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get project: %w", err)
+	}
+
+	return project, nil
+}
+
+// UpdateProject обновляет проект, если project.Version 0 или совпадает с текущей версией
+func (r *projectRepo) UpdateProject(ctx context.Context, project *model.Project) error {
+	var version int
+
+	err := r.db.QueryRowContext(ctx,
+		`UPDATE projects SET name = ?, description = ?, archived_at = ?, version = version + 1
+		WHERE id = ? AND (? = 0 OR version = ?)
+		RETURNING version`,
+		project.Name, project.Description, archivedValue(project.ArchivedAt), project.ID, project.Version, project.Version,
+	).Scan(&version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return r.notFoundOrConflict(ctx, project.ID)
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to update project: %w", err)
+	}
+
+	project.Version = version
+
+	return nil
+}
+
+// DeleteProject удаляет проект, если version 0 или совпадает с текущей версией
+func (r *projectRepo) DeleteProject(ctx context.Context, id int, version int) error {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM projects WHERE id = ? AND (? = 0 OR version = ?)`, id, version, version)
+	if err != nil {
+		return fmt.Errorf("failed to delete project: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if affected == 0 {
+		return r.notFoundOrConflict(ctx, id)
+	}
+
+	return nil
+}
+
+// DumpProjects возвращает все проекты по возрастанию ID и ID следующего проекта,
+// прочитанные в одной транзакции
+func (r *projectRepo) DumpProjects(ctx context.Context) ([]*model.Project, int, error) {
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `SELECT `+projectColumns+` FROM projects ORDER BY id`)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query projects: %w", err)
+	}
+	defer rows.Close()
+
+	projects := make([]*model.Project, 0)
+	for rows.Next() {
+		project, err := scanProject(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan project: %w", err)
+		}
+
+		projects = append(projects, project)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to iterate projects: %w", err)
+	}
+
+	next, err := nextID(ctx, tx, "projects")
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return projects, next, nil
+}
+
+// ReplaceProjects в одной транзакции заменяет все проекты на projects.
+// Счетчик ID не уменьшается, а следующий проект получит ID не меньше nextID
+func (r *projectRepo) ReplaceProjects(ctx context.Context, projects []*model.Project, nextID int) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM projects`); err != nil {
+		return fmt.Errorf("failed to delete projects: %w", err)
+	}
+
+	for _, project := range projects {
+		_, err := tx.ExecContext(ctx,
+			`INSERT INTO projects (`+projectColumns+`) VALUES (?, ?, ?, ?, ?)`,
+			project.ID, project.Name, project.Description, archivedValue(project.ArchivedAt), project.Version,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to insert project %d: %w", project.ID, err)
+		}
+	}
+
+	if err := advanceSequence(ctx, tx, "projects", nextID); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit tx: %w", err)
+	}
+
+	return nil
+}
+
+// notFoundOrConflict объясняет, почему условное изменение не затронуло проект:
+// его нет или у него другая версия
+func (r *projectRepo) notFoundOrConflict(ctx context.Context, id int) error {
+	var exist bool
+
+	err := r.db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM projects WHERE id = ?)`, id).Scan(&exist)
+	if err != nil {
+		return fmt.Errorf("failed to check project existence: %w", err)
+	}
+
+	if !exist {
+		return usecase.NewProjectNotFoundError(id)
+	}
+
+	return usecase.ErrProjectVersionConflict
+}
+
+// archivedValue значение колонки archived_at
+func archivedValue(archivedAt *time.Time) sql.NullTime {
+	if archivedAt == nil {
+		return sql.NullTime{}
+	}
+
+	return sql.NullTime{Time: archivedAt.UTC(), Valid: true}
+}
+
+// scanProject читает проект из строки с колонками projectColumns
+func scanProject(row scanner) (*model.Project, error) {
+	project := &model.Project{}
+
+	var archivedAt sql.NullTime
+
+	err := row.Scan(&project.ID, &project.Name, &project.Description, &archivedAt, &project.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	if archivedAt.Valid {
+		t := archivedAt.Time.UTC()
+		project.ArchivedAt = &t
+	}
+
+	return project, nil
+}
@@ -0,0 +1,151 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/solumD/tasks-service/internal/model"
+	"github.com/solumD/tasks-service/internal/usecase"
+)
+
+// revisionColumns колонки ревизии в порядке, который ожидает scanRevision
+const revisionColumns = `task_id, rev, action, author, created_at, title, description, done, deleted_at, due_at, priority, tags, parent_id, depends_on, recurrence, status, project_id, task_created_at`
+
+type revisionRepo struct {
+	db *sql.DB
+}
+
+// RevisionRepo возвращает хранилище ревизий в той же базе, что и задачи.
+// Соединение закрывается вместе с хранилищем задач
+func (r *taskRepo) RevisionRepo() *revisionRepo {
+	return &revisionRepo{db: r.db}
+}
+
+// AddRevision сохраняет ревизию, если ревизии с тем же номером у задачи еще нет
+func (r *revisionRepo) AddRevision(ctx context.Context, rev *model.Revision) error {
+	var deletedAt sql.NullTime
+	if rev.Task.DeletedAt != nil {
+		deletedAt = sql.NullTime{Time: rev.Task.DeletedAt.UTC(), Valid: true}
+	}
+
+	dueAt, _ := dueValues(rev.Task.DueAt)
+
+	tags, err := tagsValue(rev.Task.Tags)
+	if err != nil {
+		return err
+	}
+
+	dependsOn, err := dependsOnValue(rev.Task.DependsOn)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.ExecContext(ctx,
+		`INSERT INTO task_revisions (`+revisionColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (task_id, rev) DO NOTHING`,
+		rev.TaskID, rev.Rev, string(rev.Action), rev.Author, rev.CreatedAt.UTC(),
+		rev.Task.Title, rev.Task.Description, rev.Task.Done, deletedAt, dueAt, string(rev.Task.Priority), tags, rev.Task.ParentID,
+		dependsOn, rev.Task.Recurrence, string(rev.Task.Status), rev.Task.ProjectID, createdValue(rev.Task.CreatedAt),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert revision: %w", err)
+	}
+
+	return nil
+}
+
+// GetRevisions возвращает ревизии задачи по возрастанию номера
+func (r *revisionRepo) GetRevisions(ctx context.Context, taskID int) ([]*model.Revision, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+revisionColumns+` FROM task_revisions WHERE task_id = ? ORDER BY rev`, taskID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select revisions: %w", err)
+	}
+	defer rows.Close()
+
+	revisions := make([]*model.Revision, 0)
+	for rows.Next() {
+		rev, err := scanRevision(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan revision: %w", err)
+		}
+
+		revisions = append(revisions, rev)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate revisions: %w", err)
+	}
+
+	return revisions, nil
+}
+
+// GetRevision возвращает ревизию rev задачи
+func (r *revisionRepo) GetRevision(ctx context.Context, taskID int, rev int) (*model.Revision, error) {
+	revision, err := scanRevision(r.db.QueryRowContext(ctx,
+		`SELECT `+revisionColumns+` FROM task_revisions WHERE task_id = ? AND rev = ?`, taskID, rev,
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, usecase.ErrRevisionNotFound
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to select revision: %w", err)
+	}
+
+	return revision, nil
+}
+
+// scanRevision читает ревизию из строки с колонками revisionColumns
+func scanRevision(row scanner) (*model.Revision, error) {
+	rev := &model.Revision{}
+
+	var (
+		action    string
+		deletedAt sql.NullTime
+		dueAt     sql.NullString
+		tags      string
+		dependsOn string
+		createdAt sql.NullTime
+	)
+
+	err := row.Scan(&rev.TaskID, &rev.Rev, &action, &rev.Author, &rev.CreatedAt,
+		&rev.Task.Title, &rev.Task.Description, &rev.Task.Done, &deletedAt, &dueAt, &rev.Task.Priority, &tags, &rev.Task.ParentID,
+		&dependsOn, &rev.Task.Recurrence, &rev.Task.Status, &rev.Task.ProjectID, &createdAt)
+	if err != nil {
+		return nil, err
+	}
+
+	rev.Task.CreatedAt = parseCreated(createdAt)
+
+	rev.Task.DueAt, err = parseDue(dueAt)
+	if err != nil {
+		return nil, err
+	}
+
+	rev.Task.Tags, err = parseTags(tags)
+	if err != nil {
+		return nil, err
+	}
+
+	rev.Task.DependsOn, err = parseDependsOn(dependsOn)
+	if err != nil {
+		return nil, err
+	}
+
+	rev.Action = model.RevisionAction(action)
+	rev.CreatedAt = rev.CreatedAt.UTC()
+	rev.Task.ID = rev.TaskID
+	rev.Task.Version = rev.Rev
+
+	if deletedAt.Valid {
+		t := deletedAt.Time.UTC()
+		rev.Task.DeletedAt = &t
+	}
+
+	return rev, nil
+}
@@ -0,0 +1,613 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/solumD/tasks-service/internal/model"
+	"github.com/solumD/tasks-service/internal/usecase"
+)
+
+// taskColumns колонки задачи в порядке, который ожидает scanTask
+const taskColumns = `id, title, description, done, version, deleted_at, due_at, priority, tags, parent_id, depends_on, recurrence, status, project_id, created_at`
+
+// dueUTCLayout формат колонки due_utc: срок в UTC фиксированной ширины,
+// поэтому строки сравниваются в том же порядке, что и моменты времени
+const dueUTCLayout = "2006-01-02T15:04:05.000000000Z"
+
+type taskRepo struct {
+	db *sql.DB
+}
+
+// NewTaskRepo открывает базу через драйвер driverName, проверяет соединение
+// и применяет миграции
+func NewTaskRepo(ctx context.Context, driverName, dsn string) (*taskRepo, error) {
+	db, err := sql.Open(driverName, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open db: %w", err)
+	}
+
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to ping db: %w", err)
+	}
+
+	if err := Migrate(ctx, db); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &taskRepo{db: db}, nil
+}
+
+// CreateTask создает новую задачу в хранилище
+func (r *taskRepo) CreateTask(ctx context.Context, task *model.Task) (int, error) {
+	dueAt, dueUTC := dueValues(task.DueAt)
+
+	tags, err := tagsValue(task.Tags)
+	if err != nil {
+		return 0, err
+	}
+
+	dependsOn, err := dependsOnValue(task.DependsOn)
+	if err != nil {
+		return 0, err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx,
+		`INSERT INTO tasks (title, description, done, due_at, due_utc, priority, tags, parent_id, depends_on, recurrence, status,
+		project_id, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		task.Title, task.Description, task.Done, dueAt, dueUTC, string(task.Priority), tags, task.ParentID, dependsOn,
+		task.Recurrence, string(task.Status), task.ProjectID, createdValue(task.CreatedAt),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to insert task: %w", err)
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get inserted task id: %w", err)
+	}
+
+	if err := writeTagIndex(ctx, tx, int(id), task.Tags); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit tx: %w", err)
+	}
+
+	task.ID = int(id)
+	task.Version = 1
+
+	return task.ID, nil
+}
+
+// GetAllTasks возвращает все задачи из хранилища
+func (r *taskRepo) GetAllTasks(ctx context.Context) ([]*model.Task, error) {
+	return r.selectTasks(ctx, `SELECT `+taskColumns+` FROM tasks WHERE deleted_at IS NULL`)
+}
+
+// GetTaskByID возвращает задачу по ID из хранилища
+func (r *taskRepo) GetTaskByID(ctx context.Context, id int) (*model.Task, error) {
+	task, err := scanTask(r.db.QueryRowContext(ctx,
+		`SELECT `+taskColumns+` FROM tasks WHERE id = ? AND deleted_at IS NULL`, id,
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, usecase.NewTaskNotFoundError(id)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to select task: %w", err)
+	}
+
+	return task, nil
+}
+
+// GetTasksByDue возвращает задачи со сроком выполнения в диапазоне [from, to)
+// по индексу на колонке due_utc
+func (r *taskRepo) GetTasksByDue(ctx context.Context, from, to time.Time) ([]*model.Task, error) {
+	query := `SELECT ` + taskColumns + ` FROM tasks WHERE deleted_at IS NULL AND due_utc IS NOT NULL`
+	args := make([]any, 0, 2)
+
+	if !from.IsZero() {
+		query += ` AND due_utc >= ?`
+		args = append(args, from.UTC().Format(dueUTCLayout))
+	}
+
+	if !to.IsZero() {
+		query += ` AND due_utc < ?`
+		args = append(args, to.UTC().Format(dueUTCLayout))
+	}
+
+	return r.selectTasks(ctx, query+` ORDER BY due_utc, id`, args...)
+}
+
+// UpdateTask обновляет задачу в хранилище, если ее версия совпадает с task.Version
+func (r *taskRepo) UpdateTask(ctx context.Context, task *model.Task) error {
+	var (
+		version   int
+		createdAt sql.NullTime
+	)
+
+	dueAt, dueUTC := dueValues(task.DueAt)
+
+	tags, err := tagsValue(task.Tags)
+	if err != nil {
+		return err
+	}
+
+	dependsOn, err := dependsOnValue(task.DependsOn)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRowContext(ctx,
+		`UPDATE tasks SET title = ?, description = ?, done = ?, due_at = ?, due_utc = ?, priority = ?, tags = ?,
+		parent_id = ?, depends_on = ?, recurrence = ?, status = ?, project_id = ?, version = version + 1
+		WHERE id = ? AND deleted_at IS NULL AND (? = 0 OR version = ?)
+		RETURNING version, created_at`,
+		task.Title, task.Description, task.Done, dueAt, dueUTC, string(task.Priority), tags, task.ParentID, dependsOn,
+		task.Recurrence, string(task.Status), task.ProjectID, task.ID, task.Version, task.Version,
+	).Scan(&version, &createdAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		tx.Rollback()
+		return r.notFoundOrConflict(ctx, task.ID)
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to update task: %w", err)
+	}
+
+	if err := writeTagIndex(ctx, tx, task.ID, task.Tags); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit tx: %w", err)
+	}
+
+	task.Version = version
+	task.CreatedAt = parseCreated(createdAt)
+
+	return nil
+}
+
+// GetTasksByTags возвращает задачи хотя бы с одной из меток tags, а при all - со всеми,
+// по индексу в таблице task_tags
+func (r *taskRepo) GetTasksByTags(ctx context.Context, tags []string, all bool) ([]*model.Task, error) {
+	tags = slices.Compact(slices.Sorted(slices.Values(tags)))
+	if len(tags) == 0 {
+		return []*model.Task{}, nil
+	}
+
+	args := make([]any, 0, len(tags)+1)
+	for _, tag := range tags {
+		args = append(args, tag)
+	}
+
+	matched := `SELECT task_id FROM task_tags WHERE tag IN (?` + strings.Repeat(`, ?`, len(tags)-1) + `)`
+	if all {
+		matched += ` GROUP BY task_id HAVING COUNT(*) = ?`
+		args = append(args, len(tags))
+	}
+
+	return r.selectTasks(ctx,
+		`SELECT `+taskColumns+` FROM tasks WHERE deleted_at IS NULL AND id IN (`+matched+`) ORDER BY id`, args...,
+	)
+}
+
+// GetTasksByParent возвращает подзадачи задачи parentID по индексу на колонке parent_id
+func (r *taskRepo) GetTasksByParent(ctx context.Context, parentID int) ([]*model.Task, error) {
+	return r.selectTasks(ctx,
+		`SELECT `+taskColumns+` FROM tasks WHERE deleted_at IS NULL AND parent_id = ? ORDER BY id`, parentID,
+	)
+}
+
+// GetTagCounts возвращает метки задач не из корзины с количеством задач у каждой
+func (r *taskRepo) GetTagCounts(ctx context.Context) ([]model.TagCount, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT tt.tag, COUNT(*) FROM task_tags tt JOIN tasks t ON t.id = tt.task_id
+		WHERE t.deleted_at IS NULL GROUP BY tt.tag ORDER BY tt.tag`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select tag counts: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make([]model.TagCount, 0)
+	for rows.Next() {
+		var count model.TagCount
+		if err := rows.Scan(&count.Tag, &count.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan tag count: %w", err)
+		}
+
+		counts = append(counts, count)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate tag counts: %w", err)
+	}
+
+	return counts, nil
+}
+
+// RenameTag в одной транзакции заменяет метку from на to у всех задач. Транзакция
+// начинается с записи, поэтому не конкурирует с другими записями за повышение блокировки
+func (r *taskRepo) RenameTag(ctx context.Context, from, to string) ([]*model.Task, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	renamed, err := queryTasks(ctx, tx,
+		`UPDATE tasks SET version = version + 1
+		WHERE deleted_at IS NULL AND id IN (SELECT task_id FROM task_tags WHERE tag = ?)
+		RETURNING `+taskColumns, from,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, task := range renamed {
+		task.RenameTag(from, to)
+
+		tags, err := tagsValue(task.Tags)
+		if err != nil {
+			return nil, err
+		}
+
+		if _, err := tx.ExecContext(ctx, `UPDATE tasks SET tags = ? WHERE id = ?`, tags, task.ID); err != nil {
+			return nil, fmt.Errorf("failed to update task tags: %w", err)
+		}
+
+		if err := writeTagIndex(ctx, tx, task.ID, task.Tags); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit tx: %w", err)
+	}
+
+	sort.Slice(renamed, func(i, j int) bool {
+		return renamed[i].ID < renamed[j].ID
+	})
+
+	return renamed, nil
+}
+
+// DeleteTask перемещает задачу в корзину, если ее версия совпадает с version,
+// и возвращает ее
+func (r *taskRepo) DeleteTask(ctx context.Context, id int, version int) (*model.Task, error) {
+	task, err := scanTask(r.db.QueryRowContext(ctx,
+		`UPDATE tasks SET deleted_at = ?, version = version + 1
+		WHERE id = ? AND deleted_at IS NULL AND (? = 0 OR version = ?)
+		RETURNING `+taskColumns,
+		time.Now().UTC(), id, version, version,
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, r.notFoundOrConflict(ctx, id)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete task: %w", err)
+	}
+
+	return task, nil
+}
+
+// GetDeletedTasks возвращает все задачи из корзины
+func (r *taskRepo) GetDeletedTasks(ctx context.Context) ([]*model.Task, error) {
+	return r.selectTasks(ctx, `SELECT `+taskColumns+` FROM tasks WHERE deleted_at IS NOT NULL`)
+}
+
+// RestoreTask возвращает задачу из корзины
+func (r *taskRepo) RestoreTask(ctx context.Context, id int) (*model.Task, error) {
+	task, err := scanTask(r.db.QueryRowContext(ctx,
+		`UPDATE tasks SET deleted_at = NULL, version = version + 1
+		WHERE id = ? AND deleted_at IS NOT NULL
+		RETURNING `+taskColumns, id,
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, usecase.NewTaskNotFoundError(id)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore task: %w", err)
+	}
+
+	return task, nil
+}
+
+// PurgeDeletedTasks окончательно удаляет задачи, попавшие в корзину раньше deletedBefore,
+// вместе с их метками
+func (r *taskRepo) PurgeDeletedTasks(ctx context.Context, deletedBefore time.Time) (int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx,
+		`DELETE FROM task_tags WHERE task_id IN (SELECT id FROM tasks WHERE deleted_at IS NOT NULL AND deleted_at < ?)`,
+		deletedBefore.UTC(),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge task tags: %w", err)
+	}
+
+	res, err := tx.ExecContext(ctx,
+		`DELETE FROM tasks WHERE deleted_at IS NOT NULL AND deleted_at < ?`,
+		deletedBefore.UTC(),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge tasks: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit tx: %w", err)
+	}
+
+	return int(affected), nil
+}
+
+// Close закрывает соединение с базой
+func (r *taskRepo) Close() error {
+	return r.db.Close()
+}
+
+func (r *taskRepo) selectTasks(ctx context.Context, query string, args ...any) ([]*model.Task, error) {
+	return queryTasks(ctx, r.db, query, args...)
+}
+
+type querier interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// queryTasks читает задачи, которые вернул запрос query
+func queryTasks(ctx context.Context, q querier, query string, args ...any) ([]*model.Task, error) {
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select tasks: %w", err)
+	}
+	defer rows.Close()
+
+	tasks := make([]*model.Task, 0)
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan task: %w", err)
+		}
+
+		tasks = append(tasks, task)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate tasks: %w", err)
+	}
+
+	return tasks, nil
+}
+
+// exists проверяет, есть ли задача с ID id не в корзине
+func (r *taskRepo) exists(ctx context.Context, id int) (bool, error) {
+	var exist bool
+
+	err := r.db.QueryRowContext(ctx,
+		`SELECT EXISTS (SELECT 1 FROM tasks WHERE id = ? AND deleted_at IS NULL)`, id,
+	).Scan(&exist)
+	if err != nil {
+		return false, fmt.Errorf("failed to check task existence: %w", err)
+	}
+
+	return exist, nil
+}
+
+// notFoundOrConflict объясняет, почему условное изменение не затронуло задачу:
+// ее нет или у нее другая версия
+func (r *taskRepo) notFoundOrConflict(ctx context.Context, id int) error {
+	exist, err := r.exists(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if !exist {
+		return usecase.NewTaskNotFoundError(id)
+	}
+
+	return usecase.ErrVersionConflict
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+// scanTask читает задачу из строки с колонками taskColumns
+func scanTask(row scanner) (*model.Task, error) {
+	task := &model.Task{}
+
+	var (
+		deletedAt sql.NullTime
+		dueAt     sql.NullString
+		tags      string
+		dependsOn string
+		createdAt sql.NullTime
+	)
+
+	err := row.Scan(&task.ID, &task.Title, &task.Description, &task.Done, &task.Version, &deletedAt, &dueAt, &task.Priority, &tags,
+		&task.ParentID, &dependsOn, &task.Recurrence, &task.Status, &task.ProjectID, &createdAt)
+	if err != nil {
+		return nil, err
+	}
+
+	task.CreatedAt = parseCreated(createdAt)
+
+	if deletedAt.Valid {
+		t := deletedAt.Time.UTC()
+		task.DeletedAt = &t
+	}
+
+	due, err := parseDue(dueAt)
+	if err != nil {
+		return nil, err
+	}
+
+	task.DueAt = due
+
+	task.Tags, err = parseTags(tags)
+	if err != nil {
+		return nil, err
+	}
+
+	task.DependsOn, err = parseDependsOn(dependsOn)
+	if err != nil {
+		return nil, err
+	}
+
+	return task, nil
+}
+
+// dueValues возвращает значения колонок due_at и due_utc. Срок хранится в RFC 3339
+// со смещением из запроса, а due_utc используется для выборки по индексу
+func dueValues(due *time.Time) (any, any) {
+	if due == nil {
+		return nil, nil
+	}
+
+	return due.Format(time.RFC3339Nano), due.UTC().Format(dueUTCLayout)
+}
+
+// parseDue читает срок из колонки due_at
+func parseDue(value sql.NullString) (*time.Time, error) {
+	if !value.Valid {
+		return nil, nil
+	}
+
+	due, err := time.Parse(time.RFC3339Nano, value.String)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse due date %q: %w", value.String, err)
+	}
+
+	return &due, nil
+}
+
+// createdValue возвращает значение колонки created_at, у задач без времени создания - NULL
+func createdValue(createdAt time.Time) any {
+	if createdAt.IsZero() {
+		return nil
+	}
+
+	return createdAt.UTC()
+}
+
+// parseCreated читает время создания из колонки created_at
+func parseCreated(value sql.NullTime) time.Time {
+	if !value.Valid {
+		return time.Time{}
+	}
+
+	return value.Time.UTC()
+}
+
+// tagsValue возвращает значение колонки tags: метки задачи массивом JSON
+func tagsValue(tags []string) (string, error) {
+	if len(tags) == 0 {
+		return "[]", nil
+	}
+
+	data, err := json.Marshal(tags)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal task tags: %w", err)
+	}
+
+	return string(data), nil
+}
+
+// parseTags читает метки из колонки tags, пустой массив дает nil
+func parseTags(value string) ([]string, error) {
+	var tags []string
+	if err := json.Unmarshal([]byte(value), &tags); err != nil {
+		return nil, fmt.Errorf("failed to parse task tags %q: %w", value, err)
+	}
+
+	if len(tags) == 0 {
+		return nil, nil
+	}
+
+	return tags, nil
+}
+
+// dependsOnValue возвращает значение колонки depends_on: ID зависимостей массивом JSON
+func dependsOnValue(ids []int) (string, error) {
+	if len(ids) == 0 {
+		return "[]", nil
+	}
+
+	data, err := json.Marshal(ids)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal task dependencies: %w", err)
+	}
+
+	return string(data), nil
+}
+
+// parseDependsOn читает зависимости из колонки depends_on, пустой массив дает nil
+func parseDependsOn(value string) ([]int, error) {
+	var ids []int
+	if err := json.Unmarshal([]byte(value), &ids); err != nil {
+		return nil, fmt.Errorf("failed to parse task dependencies %q: %w", value, err)
+	}
+
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	return ids, nil
+}
+
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// writeTagIndex заменяет строки задачи id в таблице task_tags, по которой
+// ищутся задачи с меткой. Индекс хранит метки и задач из корзины, запросы
+// отбрасывают их по deleted_at
+func writeTagIndex(ctx context.Context, e execer, id int, tags []string) error {
+	if _, err := e.ExecContext(ctx, `DELETE FROM task_tags WHERE task_id = ?`, id); err != nil {
+		return fmt.Errorf("failed to delete task tags: %w", err)
+	}
+
+	for _, tag := range tags {
+		if _, err := e.ExecContext(ctx, `INSERT OR IGNORE INTO task_tags (tag, task_id) VALUES (?, ?)`, tag, id); err != nil {
+			return fmt.Errorf("failed to insert task tag: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,46 @@
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/solumD/tasks-service/internal/repository/repotest"
+	sqlrepo "github.com/solumD/tasks-service/internal/repository/sql"
+	"github.com/solumD/tasks-service/internal/usecase"
+)
+
+func TestConformance(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) usecase.TaskRepo {
+		repo, err := sqlrepo.NewTaskRepo(context.Background(), driverName, newDSN(t))
+		if err != nil {
+			t.Fatalf("failed to open repo: %v", err)
+		}
+		t.Cleanup(func() { repo.Close() })
+
+		return repo
+	})
+}
+
+func TestProjectConformance(t *testing.T) {
+	repotest.RunProjects(t, func(t *testing.T) usecase.ProjectRepo {
+		repo, err := sqlrepo.NewTaskRepo(context.Background(), driverName, newDSN(t))
+		if err != nil {
+			t.Fatalf("failed to open repo: %v", err)
+		}
+		t.Cleanup(func() { repo.Close() })
+
+		return repo.ProjectRepo()
+	})
+}
+
+func TestCommentConformance(t *testing.T) {
+	repotest.RunComments(t, func(t *testing.T) usecase.CommentRepo {
+		repo, err := sqlrepo.NewTaskRepo(context.Background(), driverName, newDSN(t))
+		if err != nil {
+			t.Fatalf("failed to open repo: %v", err)
+		}
+		t.Cleanup(func() { repo.Close() })
+
+		return repo.CommentRepo()
+	})
+}
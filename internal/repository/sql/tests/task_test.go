@@ -0,0 +1,259 @@
+package tests
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/solumD/tasks-service/internal/model"
+	sqlrepo "github.com/solumD/tasks-service/internal/repository/sql"
+	"github.com/solumD/tasks-service/internal/usecase"
+
+	_ "modernc.org/sqlite"
+)
+
+const driverName = "sqlite"
+
+func newDSN(t *testing.T) string {
+	return "file:" + filepath.Join(t.TempDir(), "tasks.db") + "?_pragma=busy_timeout(5000)"
+}
+
+func TestMigrate(t *testing.T) {
+	ctx := context.Background()
+
+	db, err := sql.Open(driverName, newDSN(t))
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	defer db.Close()
+
+	for i := 0; i < 2; i++ {
+		if err := sqlrepo.Migrate(ctx, db); err != nil {
+			t.Fatalf("migration run %d failed: %v", i+1, err)
+		}
+	}
+
+	var applied int
+	if err := db.QueryRowContext(ctx, `SELECT COUNT(*) FROM schema_migrations`).Scan(&applied); err != nil {
+		t.Fatalf("failed to count applied migrations: %v", err)
+	}
+
+	if applied == 0 {
+		t.Fatalf("expected migrations to be recorded")
+	}
+
+	var tasks int
+	if err := db.QueryRowContext(ctx, `SELECT COUNT(*) FROM tasks`).Scan(&tasks); err != nil {
+		t.Fatalf("expected tasks table to exist: %v", err)
+	}
+}
+
+func TestTaskRepo(t *testing.T) {
+	ctx := context.Background()
+
+	repo, err := sqlrepo.NewTaskRepo(ctx, driverName, newDSN(t))
+	if err != nil {
+		t.Fatalf("failed to open repo: %v", err)
+	}
+	defer repo.Close()
+
+	id, err := repo.CreateTask(ctx, &model.Task{Title: "Task1", Description: "Desc"})
+	if err != nil || id != 1 {
+		t.Fatalf("expected id 1, got %d (err %v)", id, err)
+	}
+
+	if err := repo.UpdateTask(ctx, &model.Task{ID: id, Title: "Task1 updated", Done: true}); err != nil {
+		t.Fatalf("failed to update task: %v", err)
+	}
+
+	task, err := repo.GetTaskByID(ctx, id)
+	if err != nil {
+		t.Fatalf("failed to get task: %v", err)
+	}
+
+	if task.Title != "Task1 updated" || task.Description != "" || !task.Done {
+		t.Fatalf("unexpected task after update: %+v", task)
+	}
+
+	if _, err := repo.DeleteTask(ctx, id, 0); err != nil {
+		t.Fatalf("failed to delete task: %v", err)
+	}
+
+	if _, err := repo.GetTaskByID(ctx, id); !errors.Is(err, usecase.ErrTaskNotFound) {
+		t.Fatalf("expected task to be deleted, got err %v", err)
+	}
+
+	id, err = repo.CreateTask(ctx, &model.Task{Title: "Task2"})
+	if err != nil || id != 2 {
+		t.Fatalf("expected deleted id not to be reused and id = 2, got %d (err %v)", id, err)
+	}
+}
+
+func TestTaskRepoNotFound(t *testing.T) {
+	ctx := context.Background()
+
+	repo, err := sqlrepo.NewTaskRepo(ctx, driverName, newDSN(t))
+	if err != nil {
+		t.Fatalf("failed to open repo: %v", err)
+	}
+	defer repo.Close()
+
+	if _, err := repo.GetTaskByID(ctx, 42); !errors.Is(err, usecase.ErrTaskNotFound) {
+		t.Fatalf("expected ErrTaskNotFound on get, got %v", err)
+	}
+
+	if err := repo.UpdateTask(ctx, &model.Task{ID: 42, Title: "Task"}); !errors.Is(err, usecase.ErrTaskNotFound) {
+		t.Fatalf("expected ErrTaskNotFound on update, got %v", err)
+	}
+
+	if _, err := repo.DeleteTask(ctx, 42, 0); !errors.Is(err, usecase.ErrTaskNotFound) {
+		t.Fatalf("expected ErrTaskNotFound on delete, got %v", err)
+	}
+}
+
+func TestTaskRepoVersionConflict(t *testing.T) {
+	ctx := context.Background()
+
+	repo, err := sqlrepo.NewTaskRepo(ctx, driverName, newDSN(t))
+	if err != nil {
+		t.Fatalf("failed to open repo: %v", err)
+	}
+	defer repo.Close()
+
+	id, err := repo.CreateTask(ctx, &model.Task{Title: "Task1"})
+	if err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+
+	task := &model.Task{ID: id, Title: "Task1 updated", Version: 1}
+	if err := repo.UpdateTask(ctx, task); err != nil {
+		t.Fatalf("failed to update task: %v", err)
+	}
+
+	if task.Version != 2 {
+		t.Fatalf("expected version 2 after update, got %d", task.Version)
+	}
+
+	stale := &model.Task{ID: id, Title: "Stale", Version: 1}
+	if err := repo.UpdateTask(ctx, stale); !errors.Is(err, usecase.ErrVersionConflict) {
+		t.Fatalf("expected ErrVersionConflict on stale update, got %v", err)
+	}
+
+	if _, err := repo.DeleteTask(ctx, id, 1); !errors.Is(err, usecase.ErrVersionConflict) {
+		t.Fatalf("expected ErrVersionConflict on stale delete, got %v", err)
+	}
+
+	if _, err := repo.DeleteTask(ctx, id, 2); err != nil {
+		t.Fatalf("failed to delete task with current version: %v", err)
+	}
+}
+
+func TestTaskRepoTrash(t *testing.T) {
+	ctx := context.Background()
+
+	repo, err := sqlrepo.NewTaskRepo(ctx, driverName, newDSN(t))
+	if err != nil {
+		t.Fatalf("failed to open repo: %v", err)
+	}
+	defer repo.Close()
+
+	for _, title := range []string{"Task1", "Task2"} {
+		if _, err := repo.CreateTask(ctx, &model.Task{Title: title}); err != nil {
+			t.Fatalf("failed to create task: %v", err)
+		}
+	}
+
+	for _, id := range []int{1, 2} {
+		if _, err := repo.DeleteTask(ctx, id, 0); err != nil {
+			t.Fatalf("failed to delete task: %v", err)
+		}
+	}
+
+	trash, err := repo.GetDeletedTasks(ctx)
+	if err != nil || len(trash) != 2 {
+		t.Fatalf("expected 2 tasks in trash, got %d (err %v)", len(trash), err)
+	}
+
+	if trash[0].DeletedAt == nil || trash[0].Version != 2 {
+		t.Fatalf("expected trashed task with deleted_at and version 2, got %+v", trash[0])
+	}
+
+	if _, err := repo.DeleteTask(ctx, 1, 0); !errors.Is(err, usecase.ErrTaskNotFound) {
+		t.Fatalf("expected ErrTaskNotFound on second delete, got %v", err)
+	}
+
+	task, err := repo.RestoreTask(ctx, 1)
+	if err != nil || task.DeletedAt != nil || task.Version != 3 {
+		t.Fatalf("expected restored task with version 3, got %+v (err %v)", task, err)
+	}
+
+	if _, err := repo.RestoreTask(ctx, 1); !errors.Is(err, usecase.ErrTaskNotFound) {
+		t.Fatalf("expected ErrTaskNotFound on restore of live task, got %v", err)
+	}
+
+	purged, err := repo.PurgeDeletedTasks(ctx, trash[1].DeletedAt.Add(-time.Second))
+	if err != nil || purged != 0 {
+		t.Fatalf("expected nothing to purge before deletion time, got %d (err %v)", purged, err)
+	}
+
+	purged, err = repo.PurgeDeletedTasks(ctx, time.Now().Add(time.Second))
+	if err != nil || purged != 1 {
+		t.Fatalf("expected 1 purged task, got %d (err %v)", purged, err)
+	}
+
+	if _, err := repo.RestoreTask(ctx, 2); !errors.Is(err, usecase.ErrTaskNotFound) {
+		t.Fatalf("expected purged task to be gone, got %v", err)
+	}
+
+	if _, err := repo.GetTaskByID(ctx, 1); err != nil {
+		t.Fatalf("expected restored task to survive purge, got %v", err)
+	}
+}
+
+func TestRevisionRepo(t *testing.T) {
+	ctx := context.Background()
+
+	repo, err := sqlrepo.NewTaskRepo(ctx, driverName, newDSN(t))
+	if err != nil {
+		t.Fatalf("failed to open repo: %v", err)
+	}
+	defer repo.Close()
+
+	revisions := repo.RevisionRepo()
+	deletedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	for _, rev := range []*model.Revision{
+		{TaskID: 1, Rev: 2, Action: model.RevisionDeleted, Author: "alice", CreatedAt: deletedAt,
+			Task: model.Task{ID: 1, Title: "Task", Done: true, Version: 2, DeletedAt: &deletedAt}},
+		{TaskID: 1, Rev: 1, Action: model.RevisionCreated, CreatedAt: deletedAt,
+			Task: model.Task{ID: 1, Title: "Task", Version: 1}},
+		{TaskID: 1, Rev: 2, Action: model.RevisionUpdated, CreatedAt: deletedAt,
+			Task: model.Task{ID: 1, Title: "Duplicate", Version: 2}},
+	} {
+		if err := revisions.AddRevision(ctx, rev); err != nil {
+			t.Fatalf("failed to add revision: %v", err)
+		}
+	}
+
+	list, err := revisions.GetRevisions(ctx, 1)
+	if err != nil || len(list) != 2 || list[0].Rev != 1 || list[1].Rev != 2 {
+		t.Fatalf("expected revisions 1 and 2, got %+v (err %v)", list, err)
+	}
+
+	rev, err := revisions.GetRevision(ctx, 1, 2)
+	if err != nil {
+		t.Fatalf("failed to get revision: %v", err)
+	}
+
+	if rev.Action != model.RevisionDeleted || rev.Author != "alice" || rev.Task.Title != "Task" ||
+		!rev.Task.Done || rev.Task.DeletedAt == nil || !rev.Task.DeletedAt.Equal(deletedAt) {
+		t.Fatalf("unexpected revision: %+v", rev)
+	}
+
+	if _, err := revisions.GetRevision(ctx, 1, 3); !errors.Is(err, usecase.ErrRevisionNotFound) {
+		t.Fatalf("expected ErrRevisionNotFound, got %v", err)
+	}
+}
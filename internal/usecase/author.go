@@ -0,0 +1,17 @@
+package usecase
+
+import "context"
+
+type authorKey struct{}
+
+// ContextWithAuthor возвращает контекст с автором изменений, которого
+// юзкейс записывает в ревизии задач
+func ContextWithAuthor(ctx context.Context, author string) context.Context {
+	return context.WithValue(ctx, authorKey{}, author)
+}
+
+// AuthorFromContext возвращает автора изменений из контекста или пустую строку
+func AuthorFromContext(ctx context.Context) string {
+	author, _ := ctx.Value(authorKey{}).(string)
+	return author
+}
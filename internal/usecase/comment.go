@@ -0,0 +1,296 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/solumD/tasks-service/internal/model"
+	"github.com/solumD/tasks-service/pkg/logger"
+)
+
+const (
+	// maxCommentLength наибольшая длина текста комментария в символах
+	maxCommentLength = 10000
+	// defaultCommentsLimit количество комментариев верхнего уровня на странице, если limit 0
+	defaultCommentsLimit = 20
+	maxCommentsLimit     = 100
+)
+
+type commentUsecase struct {
+	commentRepo CommentRepo
+	tasks       CommentTasks
+	log         *slog.Logger
+}
+
+// NewCommentUsecase возвращает юзкейс комментариев к задачам tasks. commentRepo должно
+// быть тем же хранилищем, что у юзкейса задач, чтобы комментарии удалялись вместе с задачами
+func NewCommentUsecase(commentRepo CommentRepo, tasks CommentTasks, log *slog.Logger) *commentUsecase {
+	return &commentUsecase{
+		commentRepo: commentRepo,
+		tasks:       tasks,
+		log:         log,
+	}
+}
+
+// AddComment добавляет к задаче comment.TaskID комментарий от автора из контекста
+// и возвращает его ID. Комментарий с ParentID - ответ на комментарий верхнего уровня той же задачи
+func (u *commentUsecase) AddComment(ctx context.Context, comment *model.Comment) (int, error) {
+	const fn = "commentUsecase.AddComment"
+	log := u.log.With(logger.String("fn", fn))
+
+	if err := validateCommentText(comment.Text); err != nil {
+		return 0, err
+	}
+
+	author := AuthorFromContext(ctx)
+	if author == "" {
+		return 0, ErrEmptyCommentAuthor
+	}
+
+	if _, err := u.tasks.GetTaskByID(ctx, comment.TaskID); err != nil {
+		log.Error("failed to get task from repo", logger.Error(err))
+
+		return 0, err
+	}
+
+	if comment.ParentID != 0 {
+		parent, err := u.commentRepo.GetCommentByID(ctx, comment.ParentID)
+		if errors.Is(err, ErrCommentNotFound) {
+			return 0, ErrCommentParentNotFound
+		}
+
+		if err != nil {
+			log.Error("failed to get parent comment from repo", logger.Error(err))
+
+			return 0, err
+		}
+
+		if parent.TaskID != comment.TaskID {
+			return 0, ErrCommentParentNotFound
+		}
+
+		if parent.IsReply() {
+			return 0, ErrNestedReply
+		}
+	}
+
+	comment.Author = author
+	comment.CreatedAt = time.Now().UTC()
+	comment.EditedAt = nil
+
+	id, err := u.commentRepo.CreateComment(ctx, comment)
+	if err != nil {
+		log.Error("failed to create comment in repo", logger.Error(err))
+
+		return 0, err
+	}
+
+	log.Info("created comment in repo", logger.Int("task id", comment.TaskID), logger.Int("comment id", id))
+
+	return id, nil
+}
+
+// GetComments возвращает страницу из не более чем limit комментариев верхнего уровня
+// задачи taskID с ID больше after вместе со всеми ответами на них. limit 0 - значение по умолчанию
+func (u *commentUsecase) GetComments(ctx context.Context, taskID int, after int, limit int) (*model.CommentPage, error) {
+	const fn = "commentUsecase.GetComments"
+	log := u.log.With(logger.String("fn", fn))
+
+	if limit == 0 {
+		limit = defaultCommentsLimit
+	}
+
+	if limit < 0 || limit > maxCommentsLimit {
+		return nil, ErrInvalidCommentsLimit
+	}
+
+	if _, err := u.tasks.GetTaskByID(ctx, taskID); err != nil {
+		log.Error("failed to get task from repo", logger.Error(err))
+
+		return nil, err
+	}
+
+	// лишний комментарий показывает, есть ли следующая страница
+	comments, err := u.commentRepo.GetComments(ctx, taskID, after, limit+1)
+	if err != nil {
+		log.Error("failed to get comments from repo", logger.Error(err))
+
+		return nil, err
+	}
+
+	page := &model.CommentPage{Threads: make([]*model.CommentThread, 0, min(len(comments), limit))}
+
+	if len(comments) > limit {
+		comments = comments[:limit]
+		page.Next = comments[limit-1].ID
+	}
+
+	if len(comments) == 0 {
+		return page, nil
+	}
+
+	threads := make(map[int]*model.CommentThread, len(comments))
+	ids := make([]int, 0, len(comments))
+
+	for _, comment := range comments {
+		thread := &model.CommentThread{Comment: comment, Replies: make([]*model.Comment, 0)}
+		threads[comment.ID] = thread
+		ids = append(ids, comment.ID)
+		page.Threads = append(page.Threads, thread)
+	}
+
+	replies, err := u.commentRepo.GetReplies(ctx, ids)
+	if err != nil {
+		log.Error("failed to get replies from repo", logger.Error(err))
+
+		return nil, err
+	}
+
+	for _, reply := range replies {
+		if thread, ok := threads[reply.ParentID]; ok {
+			thread.Replies = append(thread.Replies, reply)
+		}
+	}
+
+	log.Info("got comments from repo", logger.Int("task id", taskID), logger.Int("threads count", len(page.Threads)))
+
+	return page, nil
+}
+
+// UpdateComment меняет текст комментария comment.ID задачи comment.TaskID и возвращает
+// измененный комментарий. Изменить комментарий может только его автор
+func (u *commentUsecase) UpdateComment(ctx context.Context, comment *model.Comment) (*model.Comment, error) {
+	const fn = "commentUsecase.UpdateComment"
+	log := u.log.With(logger.String("fn", fn))
+
+	if err := validateCommentText(comment.Text); err != nil {
+		return nil, err
+	}
+
+	current, err := u.ownComment(ctx, comment.TaskID, comment.ID)
+	if err != nil {
+		log.Error("failed to get own comment", logger.Error(err))
+
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	current.Text = comment.Text
+	current.EditedAt = &now
+
+	if err := u.commentRepo.UpdateComment(ctx, current); err != nil {
+		log.Error("failed to update comment in repo", logger.Error(err))
+
+		return nil, err
+	}
+
+	log.Info("updated comment in repo", logger.Int("comment id", current.ID))
+
+	return current, nil
+}
+
+// DeleteComment удаляет комментарий id задачи taskID вместе с ответами на него.
+// Удалить комментарий может только его автор, ответы других авторов удаляются вместе с ним
+func (u *commentUsecase) DeleteComment(ctx context.Context, taskID int, id int) error {
+	const fn = "commentUsecase.DeleteComment"
+	log := u.log.With(logger.String("fn", fn))
+
+	if _, err := u.ownComment(ctx, taskID, id); err != nil {
+		log.Error("failed to get own comment", logger.Error(err))
+
+		return err
+	}
+
+	deleted, err := u.commentRepo.DeleteComment(ctx, id)
+	if err != nil {
+		log.Error("failed to delete comment in repo", logger.Error(err))
+
+		return err
+	}
+
+	log.Info("deleted comment in repo", logger.Int("comment id", id), logger.Int("comments count", deleted))
+
+	return nil
+}
+
+// ownComment возвращает комментарий id задачи taskID, если его автор - автор из контекста.
+// Комментарии задач из корзины не видны, как и сами задачи
+func (u *commentUsecase) ownComment(ctx context.Context, taskID int, id int) (*model.Comment, error) {
+	author := AuthorFromContext(ctx)
+	if author == "" {
+		return nil, ErrEmptyCommentAuthor
+	}
+
+	if _, err := u.tasks.GetTaskByID(ctx, taskID); err != nil {
+		return nil, err
+	}
+
+	comment, err := u.commentRepo.GetCommentByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if comment.TaskID != taskID {
+		return nil, NewCommentNotFoundError(id)
+	}
+
+	if comment.Author != author {
+		return nil, ErrCommentForbidden
+	}
+
+	return comment, nil
+}
+
+// purgeComments удаляет комментарии задач candidates, которых больше нет ни среди задач,
+// ни в корзине. Задачи, восстановленные или удаленные повторно во время очистки, сохраняют комментарии
+func (u *taskUsecase) purgeComments(ctx context.Context, candidates []*model.Task) (int, error) {
+	trashed, err := u.taskRepo.GetDeletedTasks(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	inTrash := make(map[int]struct{}, len(trashed))
+	for _, task := range trashed {
+		inTrash[task.ID] = struct{}{}
+	}
+
+	purged := make([]int, 0, len(candidates))
+	for _, task := range candidates {
+		if _, ok := inTrash[task.ID]; ok {
+			continue
+		}
+
+		_, err := u.taskRepo.GetTaskByID(ctx, task.ID)
+		if errors.Is(err, ErrTaskNotFound) {
+			purged = append(purged, task.ID)
+			continue
+		}
+
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if len(purged) == 0 {
+		return 0, nil
+	}
+
+	return u.commentRepo.DeleteTaskComments(ctx, purged)
+}
+
+// validateCommentText проверяет текст комментария перед записью в хранилище
+func validateCommentText(text string) error {
+	if strings.TrimSpace(text) == "" {
+		return ErrEmptyCommentText
+	}
+
+	if utf8.RuneCountInString(text) > maxCommentLength {
+		return ErrCommentTooLong
+	}
+
+	return nil
+}
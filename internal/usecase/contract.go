@@ -2,16 +2,103 @@ package usecase
 
 import (
 	"context"
+	"time"
 
 	"github.com/solumD/tasks-service/internal/model"
 )
 
-// TaskRepo интерфейс репозитория Task
+// TaskRepo интерфейс репозитория Task.
+// GetTaskByID, UpdateTask и DeleteTask атомарны: проверка существования (и версии)
+// и само действие выполняются одной операцией. Если задачи нет, они возвращают
+// *TaskNotFoundError.
+// DeleteTask перемещает задачу в корзину: такая задача не видна остальным методам,
+// кроме GetDeletedTasks, RestoreTask и PurgeDeletedTasks.
+// GetTasksByDue возвращает задачи не из корзины со сроком выполнения не раньше from
+// и раньше to (нулевая граница не ограничивает) и не обходит все задачи хранилища.
+// GetTasksByTags, GetTagCounts и GetTasksByParent тоже используют индекс и видят только
+// задачи не из корзины. GetTasksByParent возвращает подзадачи задачи parentID больше 0.
+// UpdateTask не меняет время создания: после обновления task.CreatedAt содержит
+// время создания из хранилища.
+// RenameTag одной операцией заменяет метку from на to у всех задач не из корзины
+// (если метка to у задачи уже есть, метки сливаются), увеличивает их версии
+// и возвращает измененные задачи.
+// Если контекст уже отменен, методы возвращают его ошибку и не меняют хранилище.
+// Поведение, общее для всех реализаций, проверяет пакет repository/repotest
 type TaskRepo interface {
 	CreateTask(ctx context.Context, task *model.Task) (int, error)
 	GetAllTasks(ctx context.Context) ([]*model.Task, error)
 	GetTaskByID(ctx context.Context, id int) (*model.Task, error)
+	GetTasksByDue(ctx context.Context, from, to time.Time) ([]*model.Task, error)
+	GetTasksByTags(ctx context.Context, tags []string, all bool) ([]*model.Task, error)
+	GetTasksByParent(ctx context.Context, parentID int) ([]*model.Task, error)
+	GetTagCounts(ctx context.Context) ([]model.TagCount, error)
+	RenameTag(ctx context.Context, from, to string) ([]*model.Task, error)
 	UpdateTask(ctx context.Context, task *model.Task) error
-	DeleteTask(ctx context.Context, id int) error
-	IsTaskExistByID(ctx context.Context, id int) (bool, error)
+	DeleteTask(ctx context.Context, id int, version int) (*model.Task, error)
+	GetDeletedTasks(ctx context.Context) ([]*model.Task, error)
+	RestoreTask(ctx context.Context, id int) (*model.Task, error)
+	PurgeDeletedTasks(ctx context.Context, deletedBefore time.Time) (int, error)
+}
+
+// RevisionRepo интерфейс хранилища ревизий задач. Ревизии только добавляются;
+// повторная запись ревизии с тем же TaskID и Rev игнорируется.
+// GetRevisions возвращает ревизии по возрастанию Rev, для неизвестной задачи - пустой список.
+// GetRevision возвращает ErrRevisionNotFound, если ревизии нет
+type RevisionRepo interface {
+	AddRevision(ctx context.Context, rev *model.Revision) error
+	GetRevisions(ctx context.Context, taskID int) ([]*model.Revision, error)
+	GetRevision(ctx context.Context, taskID int, rev int) (*model.Revision, error)
+}
+
+// ProjectRepo интерфейс хранилища проектов. ID выдаются по возрастанию.
+// GetProjectByID, UpdateProject и DeleteProject возвращают *ProjectNotFoundError,
+// если проекта нет. UpdateProject и DeleteProject с version не 0 меняют проект
+// только в этой версии, иначе возвращают ErrProjectVersionConflict.
+// UpdateProject меняет название, описание и время архивации и увеличивает версию,
+// после обновления project.Version содержит новую версию. DeleteProject удаляет
+// проект окончательно, задачи проекта хранилище не меняет.
+// GetProjects возвращает проекты по возрастанию ID
+type ProjectRepo interface {
+	CreateProject(ctx context.Context, project *model.Project) (int, error)
+	GetProjects(ctx context.Context) ([]*model.Project, error)
+	GetProjectByID(ctx context.Context, id int) (*model.Project, error)
+	UpdateProject(ctx context.Context, project *model.Project) error
+	DeleteProject(ctx context.Context, id int, version int) error
+}
+
+// CommentRepo интерфейс хранилища комментариев к задачам. ID выдаются по возрастанию
+// и не используются повторно. GetCommentByID, UpdateComment и DeleteComment возвращают
+// *CommentNotFoundError, если комментария нет.
+// GetComments возвращает до limit комментариев верхнего уровня задачи taskID
+// с ID больше after по возрастанию ID, GetReplies - ответы на комментарии parentIDs
+// по возрастанию ID. UpdateComment меняет текст и время изменения комментария.
+// DeleteComment удаляет комментарий вместе с ответами на него,
+// DeleteTaskComments - все комментарии задач taskIDs; оба возвращают количество
+// удаленных комментариев. Существование задач хранилище не проверяет
+type CommentRepo interface {
+	CreateComment(ctx context.Context, comment *model.Comment) (int, error)
+	GetComments(ctx context.Context, taskID int, after int, limit int) ([]*model.Comment, error)
+	GetReplies(ctx context.Context, parentIDs []int) ([]*model.Comment, error)
+	GetCommentByID(ctx context.Context, id int) (*model.Comment, error)
+	UpdateComment(ctx context.Context, comment *model.Comment) error
+	DeleteComment(ctx context.Context, id int) (int, error)
+	DeleteTaskComments(ctx context.Context, taskIDs []int) (int, error)
+}
+
+// ProjectTasks операции юзкейса задач, через которые юзкейс проектов читает и меняет
+// задачи проектов с теми же проверками и ревизиями, что и запросы к задачам.
+// CreateTask и MoveTask сами берут блокировку связей и не вызываются внутри
+// WithLinksLocked, ReleaseProjectTasks вызывается только внутри него
+type ProjectTasks interface {
+	GetAllTasks(ctx context.Context, filter model.TaskFilter) ([]*model.Task, error)
+	CreateTask(ctx context.Context, task *model.Task) (int, error)
+	MoveTask(ctx context.Context, id int, projectID int, version int) (*model.Task, error)
+	ReleaseProjectTasks(ctx context.Context, id int, tasks []*model.Task, rule model.DeleteRule) error
+	WithLinksLocked(fn func() error) error
+}
+
+// CommentTasks задачи, к которым юзкейс комментариев проверяет доступ.
+// GetTaskByID возвращает *TaskNotFoundError, если задачи нет или она в корзине
+type CommentTasks interface {
+	GetTaskByID(ctx context.Context, id int) (*model.Task, error)
 }
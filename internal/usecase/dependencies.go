@@ -0,0 +1,431 @@
+package usecase
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"slices"
+	"sort"
+
+	"github.com/solumD/tasks-service/internal/model"
+	"github.com/solumD/tasks-service/pkg/logger"
+)
+
+// maxDependencyRetries сколько раз повторяется изменение зависимостей при конфликте версий
+const maxDependencyRetries = 3
+
+// Dependencies правила для зависимостей между задачами. Задача заблокирована,
+// пока хотя бы одна из ее зависимостей не выполнена и не отменена; зависимости
+// от задач в корзине и удаленных окончательно не блокируют
+type Dependencies struct {
+	// BlockDone запрещать отмечать выполненной заблокированную задачу
+	BlockDone bool
+}
+
+// AddDependency добавляет задаче id зависимость от задачи dependsOn и возвращает
+// задачу. Если version не 0, задача меняется только в этой версии
+func (u *taskUsecase) AddDependency(ctx context.Context, id int, dependsOn int, version int) (*model.Task, error) {
+	const fn = "taskUsecase.AddDependency"
+	log := u.log.With(logger.String("fn", fn))
+
+	if dependsOn <= 0 {
+		return nil, ErrInvalidDependency
+	}
+
+	task, err := u.changeDependencies(ctx, id, version, func(ids []int) ([]int, error) {
+		return append(ids, dependsOn), nil
+	})
+	if err != nil {
+		log.Error("failed to add task dependency", logger.Error(err))
+
+		return nil, err
+	}
+
+	log.Info("added task dependency", logger.Int("task id", id), logger.Int("depends on", dependsOn))
+
+	return task, nil
+}
+
+// RemoveDependency убирает у задачи id зависимость от задачи dependsOn и возвращает
+// задачу. Если такой зависимости нет, возвращает ErrDependencyNotFound
+func (u *taskUsecase) RemoveDependency(ctx context.Context, id int, dependsOn int, version int) (*model.Task, error) {
+	const fn = "taskUsecase.RemoveDependency"
+	log := u.log.With(logger.String("fn", fn))
+
+	task, err := u.changeDependencies(ctx, id, version, func(ids []int) ([]int, error) {
+		if !slices.Contains(ids, dependsOn) {
+			return nil, ErrDependencyNotFound
+		}
+
+		return slices.DeleteFunc(ids, func(dep int) bool { return dep == dependsOn }), nil
+	})
+	if err != nil {
+		log.Error("failed to remove task dependency", logger.Error(err))
+
+		return nil, err
+	}
+
+	log.Info("removed task dependency", logger.Int("task id", id), logger.Int("depends on", dependsOn))
+
+	return task, nil
+}
+
+// changeDependencies меняет зависимости задачи id функцией change и сохраняет
+// задачу через UpdateTask. Без version задача перечитывается и обновляется
+// с проверкой версии, чтобы не затереть параллельное изменение других полей
+func (u *taskUsecase) changeDependencies(ctx context.Context, id int, version int, change func([]int) ([]int, error)) (*model.Task, error) {
+	for range maxDependencyRetries {
+		current, err := u.taskRepo.GetTaskByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+
+		if version != 0 && current.Version != version {
+			return nil, ErrVersionConflict
+		}
+
+		// хранилище может отдавать свою копию задачи, меняется отдельная
+		task := current.Clone()
+
+		task.DependsOn, err = change(task.DependsOn)
+		if err != nil {
+			return nil, err
+		}
+
+		task.DependsOn, err = normalizeDependsOn(id, task.DependsOn)
+		if err != nil {
+			return nil, err
+		}
+
+		if slices.Equal(task.DependsOn, current.DependsOn) {
+			return u.withBlocked(ctx, current)
+		}
+
+		err = u.UpdateTask(ctx, task)
+		if errors.Is(err, ErrVersionConflict) && version == 0 {
+			continue
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		return u.withBlocked(ctx, task)
+	}
+
+	return nil, ErrVersionConflict
+}
+
+// GetTasksInOrder возвращает задачи не из корзины в порядке выполнения: каждая
+// задача идет после своих зависимостей, из готовых к выполнению первой идет задача
+// с меньшим ID. Зависимости от задач, которых нет, не учитываются. Задачи из цикла
+// (он может появиться при восстановлении задачи из корзины) идут в конце по возрастанию ID
+func (u *taskUsecase) GetTasksInOrder(ctx context.Context) ([]*model.Task, error) {
+	const fn = "taskUsecase.GetTasksInOrder"
+	log := u.log.With(logger.String("fn", fn))
+
+	tasks, err := u.taskRepo.GetAllTasks(ctx)
+	if err != nil {
+		log.Error("failed to get all tasks from repo", logger.Error(err))
+
+		return nil, err
+	}
+
+	byID := make(map[int]*model.Task, len(tasks))
+	for _, task := range tasks {
+		byID[task.ID] = task
+	}
+
+	waiting := make(map[int]int, len(tasks))
+	dependents := make(map[int][]int)
+
+	for _, task := range tasks {
+		for _, dep := range task.DependsOn {
+			if _, ok := byID[dep]; ok {
+				waiting[task.ID]++
+				dependents[dep] = append(dependents[dep], task.ID)
+			}
+		}
+	}
+
+	ready := &idHeap{}
+	for _, task := range tasks {
+		if waiting[task.ID] == 0 {
+			heap.Push(ready, task.ID)
+		}
+	}
+
+	ordered := make([]*model.Task, 0, len(tasks))
+	placed := make(map[int]struct{}, len(tasks))
+
+	for ready.Len() > 0 {
+		id := heap.Pop(ready).(int)
+		ordered = append(ordered, byID[id])
+		placed[id] = struct{}{}
+
+		for _, dependent := range dependents[id] {
+			waiting[dependent]--
+			if waiting[dependent] == 0 {
+				heap.Push(ready, dependent)
+			}
+		}
+	}
+
+	if len(ordered) < len(tasks) {
+		rest := make([]*model.Task, 0, len(tasks)-len(ordered))
+		for _, task := range tasks {
+			if _, ok := placed[task.ID]; !ok {
+				rest = append(rest, task)
+			}
+		}
+
+		sortTasks(rest, model.SortByID, false)
+		ordered = append(ordered, rest...)
+
+		log.Warn("found dependency cycle", logger.Int("tasks count", len(rest)))
+	}
+
+	if err := u.markBlocked(ctx, ordered); err != nil {
+		log.Error("failed to check task dependencies", logger.Error(err))
+
+		return nil, err
+	}
+
+	log.Info("got tasks in order from repo", logger.Int("tasks count", len(ordered)))
+
+	return ordered, nil
+}
+
+// checkDependencies проверяет зависимости, которых у задачи еще не было: задачи
+// должны существовать, а зависимость не должна замыкать цикл. При
+// Dependencies.BlockDone проверяет, что задачу не отмечают выполненной, пока
+// она заблокирована. Для новой задачи task.ID равен 0
+func (u *taskUsecase) checkDependencies(ctx context.Context, task *model.Task) error {
+	blockDone := task.Done && u.dependencies.BlockDone
+	if len(task.DependsOn) == 0 && !blockDone {
+		return nil
+	}
+
+	var current *model.Task
+	if task.ID != 0 {
+		var err error
+
+		current, err = u.taskRepo.GetTaskByID(ctx, task.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, dep := range task.DependsOn {
+		if current != nil && slices.Contains(current.DependsOn, dep) {
+			continue
+		}
+
+		if err := u.checkDependency(ctx, task.ID, dep); err != nil {
+			return err
+		}
+	}
+
+	// уже выполненная задача остается выполненной
+	if !blockDone || current != nil && current.Done {
+		return nil
+	}
+
+	open, err := u.openDependencies(ctx, task, make(map[int]*model.Task))
+	if err != nil {
+		return err
+	}
+
+	if len(open) > 0 {
+		return ErrTaskBlocked
+	}
+
+	return nil
+}
+
+// checkDependency проверяет, что задача dependsOn существует и не зависит,
+// прямо или через другие задачи, от задачи id
+func (u *taskUsecase) checkDependency(ctx context.Context, id int, dependsOn int) error {
+	if dependsOn == id {
+		return ErrDependencyCycle
+	}
+
+	dep, err := u.taskRepo.GetTaskByID(ctx, dependsOn)
+	if errors.Is(err, ErrTaskNotFound) {
+		return ErrDependencyNotFound
+	}
+
+	if err != nil {
+		return err
+	}
+
+	// у новой задачи еще нет зависимых
+	if id == 0 {
+		return nil
+	}
+
+	visited := map[int]struct{}{dependsOn: {}}
+	stack := slices.Clone(dep.DependsOn)
+
+	for len(stack) > 0 {
+		next := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		if next == id {
+			return ErrDependencyCycle
+		}
+
+		if _, ok := visited[next]; ok {
+			continue
+		}
+
+		visited[next] = struct{}{}
+
+		task, err := u.taskRepo.GetTaskByID(ctx, next)
+		if errors.Is(err, ErrTaskNotFound) {
+			continue
+		}
+
+		if err != nil {
+			return err
+		}
+
+		stack = append(stack, task.DependsOn...)
+	}
+
+	return nil
+}
+
+// existingTasks оставляет из ids только задачи не из корзины
+func (u *taskUsecase) existingTasks(ctx context.Context, ids []int) ([]int, error) {
+	existing := make([]int, 0, len(ids))
+
+	for _, id := range ids {
+		_, err := u.taskRepo.GetTaskByID(ctx, id)
+		if errors.Is(err, ErrTaskNotFound) {
+			continue
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		existing = append(existing, id)
+	}
+
+	if len(existing) == 0 {
+		return nil, nil
+	}
+
+	return existing, nil
+}
+
+// openDependencies возвращает незавершенные зависимости задачи. known кеширует
+// прочитанные задачи, nil в нем - задачи нет
+func (u *taskUsecase) openDependencies(ctx context.Context, task *model.Task, known map[int]*model.Task) ([]int, error) {
+	var open []int
+
+	for _, id := range task.DependsOn {
+		dep, ok := known[id]
+		if !ok {
+			var err error
+
+			dep, err = u.taskRepo.GetTaskByID(ctx, id)
+			if errors.Is(err, ErrTaskNotFound) {
+				dep, err = nil, nil
+			}
+
+			if err != nil {
+				return nil, err
+			}
+
+			known[id] = dep
+		}
+
+		if dep != nil && !dep.CurrentStatus().Closed() {
+			open = append(open, id)
+		}
+	}
+
+	return open, nil
+}
+
+// markBlocked заменяет заблокированные задачи списка копиями с Blocked: задачи
+// хранилища могут быть общими с другими запросами. Состояние зависимостей
+// берется из самого списка, недостающие задачи читаются из хранилища
+func (u *taskUsecase) markBlocked(ctx context.Context, tasks []*model.Task) error {
+	known := make(map[int]*model.Task, len(tasks))
+	for _, task := range tasks {
+		known[task.ID] = task
+	}
+
+	for i, task := range tasks {
+		if len(task.DependsOn) == 0 {
+			continue
+		}
+
+		open, err := u.openDependencies(ctx, task, known)
+		if err != nil {
+			return err
+		}
+
+		if len(open) > 0 {
+			blocked := task.Clone()
+			blocked.Blocked = true
+			tasks[i] = blocked
+		}
+	}
+
+	return nil
+}
+
+// withBlocked возвращает задачу с вычисленным признаком Blocked
+func (u *taskUsecase) withBlocked(ctx context.Context, task *model.Task) (*model.Task, error) {
+	tasks := []*model.Task{task}
+	if err := u.markBlocked(ctx, tasks); err != nil {
+		return nil, err
+	}
+
+	return tasks[0], nil
+}
+
+// normalizeDependsOn сортирует зависимости задачи id и убирает повторы
+func normalizeDependsOn(id int, ids []int) ([]int, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	normalized := slices.Clone(ids)
+	for _, dep := range normalized {
+		if dep <= 0 {
+			return nil, ErrInvalidDependency
+		}
+
+		if dep == id {
+			return nil, ErrDependencyCycle
+		}
+	}
+
+	sort.Ints(normalized)
+
+	return slices.Compact(normalized), nil
+}
+
+// idHeap очередь ID задач, готовых к выполнению, с наименьшим ID в начале
+type idHeap []int
+
+func (h idHeap) Len() int           { return len(h) }
+func (h idHeap) Less(i, j int) bool { return h[i] < h[j] }
+func (h idHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+
+func (h *idHeap) Push(x any) {
+	*h = append(*h, x.(int))
+}
+
+func (h *idHeap) Pop() any {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+
+	return x
+}
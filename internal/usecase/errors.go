@@ -0,0 +1,107 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrEmptyTitle      = errors.New("task title is empty")
+	ErrInvalidDueDate  = errors.New("task due date is out of range")
+	ErrInvalidPriority = errors.New("unknown task priority")
+	ErrInvalidStatus   = errors.New("unknown task status")
+	ErrInvalidTag      = errors.New("task tag is empty, too long or contains a comma")
+	ErrTaskNotFound    = errors.New("task not found")
+	ErrVersionConflict = errors.New("task version conflict")
+	ErrParentNotFound  = errors.New("parent task not found")
+	ErrTaskCycle       = errors.New("task cannot be a subtask of itself or of its subtask")
+	ErrTaskHasChildren = errors.New("task has subtasks")
+	ErrInvalidDepth    = fmt.Errorf("tree depth must be from 0 to %d", maxTreeDepth)
+
+	ErrInvalidDependency  = errors.New("task dependency id must be positive")
+	ErrDependencyNotFound = errors.New("dependency task not found")
+	ErrDependencyCycle    = errors.New("task cannot depend on itself or on a task that depends on it")
+	ErrTaskBlocked        = errors.New("task has unfinished dependencies")
+
+	ErrInvalidTransition = errors.New("task status transition is not allowed")
+
+	ErrInvalidRecurrence    = errors.New("invalid recurrence rule")
+	ErrRecurrenceWithoutDue = errors.New("recurring task must have a due date")
+	ErrInvalidOccurrences   = fmt.Errorf("occurrences count must be from 1 to %d", maxOccurrences)
+
+	ErrRevisionNotFound = errors.New("task revision not found")
+
+	ErrEmptyProjectName       = errors.New("project name is empty")
+	ErrProjectNotFound        = errors.New("project not found")
+	ErrProjectVersionConflict = errors.New("project version conflict")
+	ErrProjectArchived        = errors.New("project is archived")
+	ErrProjectHasTasks        = errors.New("project has tasks")
+	ErrProjectsDisabled       = errors.New("projects are not supported in raft and replication modes")
+
+	ErrEmptyCommentText      = errors.New("comment text is empty")
+	ErrCommentTooLong        = fmt.Errorf("comment text is longer than %d characters", maxCommentLength)
+	ErrEmptyCommentAuthor    = errors.New("comment author is empty")
+	ErrCommentNotFound       = errors.New("comment not found")
+	ErrCommentParentNotFound = errors.New("parent comment not found")
+	ErrNestedReply           = errors.New("cannot reply to a reply")
+	ErrCommentForbidden      = errors.New("comment belongs to another author")
+	ErrInvalidCommentsLimit  = fmt.Errorf("comments limit must be from 1 to %d", maxCommentsLimit)
+	ErrCommentsDisabled      = errors.New("comments are not supported in raft and replication modes")
+)
+
+// TaskNotFoundError ошибка хранилища об отсутствии задачи с заданным ID.
+// errors.Is(err, ErrTaskNotFound) для нее возвращает true
+type TaskNotFoundError struct {
+	ID int
+}
+
+// NewTaskNotFoundError возвращает ошибку отсутствия задачи с ID id
+func NewTaskNotFoundError(id int) *TaskNotFoundError {
+	return &TaskNotFoundError{ID: id}
+}
+
+func (e *TaskNotFoundError) Error() string {
+	return fmt.Sprintf("%s: id %d", ErrTaskNotFound, e.ID)
+}
+
+func (e *TaskNotFoundError) Is(target error) bool {
+	return target == ErrTaskNotFound
+}
+
+// ProjectNotFoundError ошибка хранилища об отсутствии проекта с заданным ID.
+// errors.Is(err, ErrProjectNotFound) для нее возвращает true
+type ProjectNotFoundError struct {
+	ID int
+}
+
+// NewProjectNotFoundError возвращает ошибку отсутствия проекта с ID id
+func NewProjectNotFoundError(id int) *ProjectNotFoundError {
+	return &ProjectNotFoundError{ID: id}
+}
+
+func (e *ProjectNotFoundError) Error() string {
+	return fmt.Sprintf("%s: id %d", ErrProjectNotFound, e.ID)
+}
+
+func (e *ProjectNotFoundError) Is(target error) bool {
+	return target == ErrProjectNotFound
+}
+
+// CommentNotFoundError ошибка хранилища об отсутствии комментария с заданным ID.
+// errors.Is(err, ErrCommentNotFound) для нее возвращает true
+type CommentNotFoundError struct {
+	ID int
+}
+
+// NewCommentNotFoundError возвращает ошибку отсутствия комментария с ID id
+func NewCommentNotFoundError(id int) *CommentNotFoundError {
+	return &CommentNotFoundError{ID: id}
+}
+
+func (e *CommentNotFoundError) Error() string {
+	return fmt.Sprintf("%s: id %d", ErrCommentNotFound, e.ID)
+}
+
+func (e *CommentNotFoundError) Is(target error) bool {
+	return target == ErrCommentNotFound
+}
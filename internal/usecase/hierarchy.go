@@ -0,0 +1,332 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+
+	"github.com/solumD/tasks-service/internal/model"
+	"github.com/solumD/tasks-service/pkg/logger"
+)
+
+// maxTreeDepth наибольшая глубина дерева задач в одном запросе
+const maxTreeDepth = 10
+
+// maxParentRetries сколько раз повторяется смена родителя подзадачи при конфликте версий
+const maxParentRetries = 3
+
+// Hierarchy правила для дерева задач
+type Hierarchy struct {
+	// OnDelete что происходит с подзадачами удаляемой задачи, пустое значение - model.DeleteBlock
+	OnDelete model.DeleteRule
+	// AutoComplete отмечать задачу выполненной, когда выполнены все ее подзадачи
+	AutoComplete bool
+}
+
+// GetChildren возвращает подзадачи задачи id по возрастанию ID
+func (u *taskUsecase) GetChildren(ctx context.Context, id int) ([]*model.Task, error) {
+	const fn = "taskUsecase.GetChildren"
+	log := u.log.With(logger.String("fn", fn))
+
+	if _, err := u.taskRepo.GetTaskByID(ctx, id); err != nil {
+		log.Error("failed to get task from repo", logger.Error(err))
+
+		return nil, err
+	}
+
+	children, err := u.getChildren(ctx, id)
+	if err != nil {
+		log.Error("failed to get children from repo", logger.Error(err))
+
+		return nil, err
+	}
+
+	if err := u.markBlocked(ctx, children); err != nil {
+		log.Error("failed to check task dependencies", logger.Error(err))
+
+		return nil, err
+	}
+
+	log.Info("got children from repo", logger.Int("task id", id), logger.Int("tasks count", len(children)))
+
+	return children, nil
+}
+
+// GetTaskTree возвращает задачу id с подзадачами не глубже depth уровней
+// (не больше maxTreeDepth). При depth 0 возвращается только сама задача
+func (u *taskUsecase) GetTaskTree(ctx context.Context, id int, depth int) (*model.TaskNode, error) {
+	const fn = "taskUsecase.GetTaskTree"
+	log := u.log.With(logger.String("fn", fn))
+
+	if depth < 0 || depth > maxTreeDepth {
+		return nil, ErrInvalidDepth
+	}
+
+	task, err := u.taskRepo.GetTaskByID(ctx, id)
+	if err != nil {
+		log.Error("failed to get task from repo", logger.Error(err))
+
+		return nil, err
+	}
+
+	root := &model.TaskNode{Task: task}
+	level := []*model.TaskNode{root}
+	nodes := []*model.TaskNode{root}
+
+	// дерево обходится по уровням, у узлов последнего уровня только проверяется
+	// наличие подзадач
+	for d := 0; d <= depth && len(level) > 0; d++ {
+		next := make([]*model.TaskNode, 0)
+
+		for _, node := range level {
+			children, err := u.getChildren(ctx, node.Task.ID)
+			if err != nil {
+				log.Error("failed to get children from repo", logger.Error(err))
+
+				return nil, err
+			}
+
+			node.HasChildren = len(children) > 0
+			if d == depth {
+				continue
+			}
+
+			node.Children = make([]*model.TaskNode, 0, len(children))
+			for _, child := range children {
+				childNode := &model.TaskNode{Task: child}
+				node.Children = append(node.Children, childNode)
+				next = append(next, childNode)
+			}
+		}
+
+		nodes = append(nodes, next...)
+		level = next
+	}
+
+	tasks := make([]*model.Task, 0, len(nodes))
+	for _, node := range nodes {
+		tasks = append(tasks, node.Task)
+	}
+
+	if err := u.markBlocked(ctx, tasks); err != nil {
+		log.Error("failed to check task dependencies", logger.Error(err))
+
+		return nil, err
+	}
+
+	for i, node := range nodes {
+		node.Task = tasks[i]
+	}
+
+	log.Info("got task tree from repo", logger.Int("task id", id), logger.Int("tasks count", len(nodes)))
+
+	return root, nil
+}
+
+// getChildren возвращает подзадачи задачи id по возрастанию ID
+func (u *taskUsecase) getChildren(ctx context.Context, id int) ([]*model.Task, error) {
+	children, err := u.taskRepo.GetTasksByParent(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	sortTasks(children, model.SortByID, false)
+
+	return children, nil
+}
+
+// checkParent проверяет, что родитель задачи существует и что задача не станет
+// предком самой себя. Для новой задачи task.ID равен 0
+func (u *taskUsecase) checkParent(ctx context.Context, task *model.Task) error {
+	visited := make(map[int]struct{})
+
+	for id := task.ParentID; id != 0; {
+		if id == task.ID {
+			return ErrTaskCycle
+		}
+
+		// цикл выше по дереву уже есть в хранилище, задача в него не входит
+		if _, ok := visited[id]; ok {
+			return nil
+		}
+
+		visited[id] = struct{}{}
+
+		ancestor, err := u.taskRepo.GetTaskByID(ctx, id)
+		if errors.Is(err, ErrTaskNotFound) {
+			if id == task.ParentID {
+				return ErrParentNotFound
+			}
+
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		id = ancestor.ParentID
+	}
+
+	return nil
+}
+
+// deleteChildren применяет правило удаления к подзадачам уже удаленной задачи id
+func (u *taskUsecase) deleteChildren(ctx context.Context, id int, children []*model.Task) error {
+	for _, child := range children {
+		switch u.hierarchy.OnDelete {
+		case model.DeleteCascade:
+			grandchildren, err := u.getChildren(ctx, child.ID)
+			if err != nil {
+				return err
+			}
+
+			deleted, err := u.taskRepo.DeleteTask(ctx, child.ID, 0)
+			if errors.Is(err, ErrTaskNotFound) {
+				continue
+			}
+
+			if err != nil {
+				return err
+			}
+
+			u.recordRevision(ctx, deleted, model.RevisionDeleted)
+
+			if err := u.deleteChildren(ctx, child.ID, grandchildren); err != nil {
+				return err
+			}
+		case model.DeleteOrphan:
+			if err := u.setParent(ctx, child.ID, id, 0); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// setParent меняет родителя задачи id с from на to, если задача все еще подзадача from.
+// Задача перечитывается и обновляется с проверкой версии, чтобы не затереть
+// параллельное изменение других полей
+func (u *taskUsecase) setParent(ctx context.Context, id int, from int, to int) error {
+	for range maxParentRetries {
+		current, err := u.taskRepo.GetTaskByID(ctx, id)
+		if errors.Is(err, ErrTaskNotFound) {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if current.ParentID != from {
+			return nil
+		}
+
+		// хранилище может отдавать свою копию задачи, меняется отдельная
+		task := current.Clone()
+		task.ParentID = to
+
+		err = u.taskRepo.UpdateTask(ctx, task)
+		if errors.Is(err, ErrVersionConflict) {
+			continue
+		}
+
+		if err != nil {
+			return err
+		}
+
+		u.recordRevision(ctx, task, model.RevisionUpdated)
+
+		return nil
+	}
+
+	return ErrVersionConflict
+}
+
+// completeParents отмечает выполненной задачу parentID, если все ее подзадачи
+// выполнены или отменены и хотя бы одна выполнена, и так же проверяет ее предков.
+// Задача, которой рабочий процесс не разрешает перейти в done, не выполняется
+func (u *taskUsecase) completeParents(ctx context.Context, parentID int) error {
+	if !u.hierarchy.AutoComplete {
+		return nil
+	}
+
+	visited := make(map[int]struct{})
+
+	for parentID != 0 {
+		if _, ok := visited[parentID]; ok {
+			return nil
+		}
+
+		visited[parentID] = struct{}{}
+
+		children, err := u.taskRepo.GetTasksByParent(ctx, parentID)
+		if err != nil {
+			return err
+		}
+
+		if len(children) == 0 {
+			return nil
+		}
+
+		done := false
+		for _, child := range children {
+			status := child.CurrentStatus()
+			if !status.Closed() {
+				return nil
+			}
+
+			done = done || status == model.StatusDone
+		}
+
+		if !done {
+			return nil
+		}
+
+		parent, err := u.taskRepo.GetTaskByID(ctx, parentID)
+		if errors.Is(err, ErrTaskNotFound) {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if !parent.Done && u.dependencies.BlockDone {
+			open, err := u.openDependencies(ctx, parent, make(map[int]*model.Task))
+			if err != nil {
+				return err
+			}
+
+			// заблокированная задача не выполняется, не выполняются и ее предки
+			if len(open) > 0 {
+				return nil
+			}
+		}
+
+		if !parent.Done {
+			if !u.workflow.Allows(parent.CurrentStatus(), model.StatusDone) {
+				return nil
+			}
+
+			parent = parent.Clone()
+			setStatus(parent, model.StatusDone)
+
+			err := u.taskRepo.UpdateTask(ctx, parent)
+			if errors.Is(err, ErrVersionConflict) {
+				// задачу изменили параллельно, ее выполнение проверит следующее изменение
+				return nil
+			}
+
+			if err != nil {
+				return err
+			}
+
+			u.recordRevision(ctx, parent, model.RevisionUpdated)
+		}
+
+		parentID = parent.ParentID
+	}
+
+	return nil
+}
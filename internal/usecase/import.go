@@ -0,0 +1,165 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/solumD/tasks-service/internal/model"
+	"github.com/solumD/tasks-service/pkg/logger"
+)
+
+// ErrDuplicateImportID id задачи из выгрузки повторяется в импорте
+var ErrDuplicateImportID = errors.New("task id is repeated in the import")
+
+// состояние задачи при обходе импорта
+const (
+	importPending = iota
+	importVisiting
+	importDone
+)
+
+// taskImport обход задач импорта: задача создается после задач, на которые
+// ссылается, и ссылки на них заменяются новыми ID
+type taskImport struct {
+	u      *taskUsecase
+	tasks  []*model.Task
+	dryRun bool
+
+	errs  []error
+	state []int
+	// index номер задачи в импорте по ее id из выгрузки
+	index map[int]int
+	// newIDs ID созданных задач, при dryRun - их id из выгрузки
+	newIDs []int
+}
+
+// ImportTasks создает задачи по одной через CreateTask, поэтому к ним применяются
+// те же проверки. Ошибка задачи не останавливает импорт остальных: i-й элемент
+// результата - ошибка задачи tasks[i] или nil. При dryRun задачи только проверяются.
+// ID задачи в tasks - ее id из выгрузки (0 - без id), после импорта его заменяет
+// новый ID. parent_id и depends_on ссылаются на id из выгрузки: родитель
+// и зависимости создаются раньше задачи, а ссылки заменяются их новыми ID.
+// Ссылка на задачу вне импорта или на задачу, которую не удалось
+// импортировать, - ошибка задачи. Проект задачи сохраняется, если он
+// существует и не в архиве, иначе задача не импортируется
+func (u *taskUsecase) ImportTasks(ctx context.Context, tasks []*model.Task, dryRun bool) []error {
+	const fn = "taskUsecase.ImportTasks"
+	log := u.log.With(logger.String("fn", fn))
+
+	imp := &taskImport{
+		u:      u,
+		tasks:  tasks,
+		dryRun: dryRun,
+		errs:   make([]error, len(tasks)),
+		state:  make([]int, len(tasks)),
+		index:  make(map[int]int, len(tasks)),
+		newIDs: make([]int, len(tasks)),
+	}
+
+	for i, task := range tasks {
+		if task.ID == 0 {
+			continue
+		}
+
+		if _, ok := imp.index[task.ID]; ok {
+			imp.errs[i] = fmt.Errorf("%w: %d", ErrDuplicateImportID, task.ID)
+			continue
+		}
+
+		imp.index[task.ID] = i
+	}
+
+	for i := range tasks {
+		imp.visit(ctx, i)
+	}
+
+	failed := 0
+	for _, err := range imp.errs {
+		if err != nil {
+			failed++
+		}
+	}
+
+	log.Info("imported tasks",
+		logger.Int("tasks count", len(tasks)),
+		logger.Int("failed count", failed),
+		logger.Any("dry run", dryRun),
+	)
+
+	return imp.errs
+}
+
+// visit импортирует задачу i после задач, на которые она ссылается
+func (imp *taskImport) visit(ctx context.Context, i int) {
+	if imp.state[i] != importPending {
+		return
+	}
+
+	imp.state[i] = importVisiting
+	defer func() { imp.state[i] = importDone }()
+
+	task := imp.tasks[i]
+	oldID := task.ID
+
+	if imp.errs[i] == nil && task.ParentID != 0 {
+		task.ParentID, imp.errs[i] = imp.resolve(ctx, task.ParentID, ErrParentNotFound, ErrTaskCycle)
+	}
+
+	for k, dep := range task.DependsOn {
+		if imp.errs[i] != nil {
+			break
+		}
+
+		task.DependsOn[k], imp.errs[i] = imp.resolve(ctx, dep, ErrDependencyNotFound, ErrDependencyCycle)
+	}
+
+	// id из выгрузки не должен попасть в проверки и в хранилище
+	task.ID = 0
+
+	if imp.errs[i] != nil {
+		return
+	}
+
+	if imp.dryRun {
+		imp.errs[i] = imp.u.validateImport(ctx, task)
+		imp.newIDs[i] = oldID
+
+		return
+	}
+
+	imp.newIDs[i], imp.errs[i] = imp.u.CreateTask(ctx, task)
+}
+
+// resolve возвращает новый ID задачи с id id из выгрузки, импортируя ее при
+// необходимости. errNotFound - ошибка ссылки на задачу вне импорта или на
+// неимпортированную задачу, errCycle - ошибка циклической ссылки
+func (imp *taskImport) resolve(ctx context.Context, id int, errNotFound, errCycle error) (int, error) {
+	j, ok := imp.index[id]
+	if !ok {
+		return 0, fmt.Errorf("%w: %d is not in the import", errNotFound, id)
+	}
+
+	switch imp.state[j] {
+	case importVisiting:
+		return 0, fmt.Errorf("%w: %d", errCycle, id)
+	case importPending:
+		imp.visit(ctx, j)
+	}
+
+	if imp.errs[j] != nil {
+		return 0, fmt.Errorf("%w: %d was not imported", errNotFound, id)
+	}
+
+	return imp.newIDs[j], nil
+}
+
+// validateImport проверяет задачу импорта без создания: те же проверки полей,
+// что и в CreateTask, и проект задачи
+func (u *taskUsecase) validateImport(ctx context.Context, task *model.Task) error {
+	if err := validateTask(task); err != nil {
+		return err
+	}
+
+	return u.checkProject(ctx, task)
+}
@@ -0,0 +1,103 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/solumD/tasks-service/internal/model"
+)
+
+// MockCommentRepo мок репозитория комментариев
+type MockCommentRepo struct {
+	CreateCommentFunc   func(ctx context.Context, comment *model.Comment) (int, error)
+	CreateCommentCalled bool
+
+	GetCommentsFunc   func(ctx context.Context, taskID int, after int, limit int) ([]*model.Comment, error)
+	GetCommentsCalled bool
+
+	GetRepliesFunc   func(ctx context.Context, parentIDs []int) ([]*model.Comment, error)
+	GetRepliesCalled bool
+
+	GetCommentByIDFunc   func(ctx context.Context, id int) (*model.Comment, error)
+	GetCommentByIDCalled bool
+
+	UpdateCommentFunc   func(ctx context.Context, comment *model.Comment) error
+	UpdateCommentCalled bool
+
+	DeleteCommentFunc   func(ctx context.Context, id int) (int, error)
+	DeleteCommentCalled bool
+
+	DeleteTaskCommentsFunc    func(ctx context.Context, taskIDs []int) (int, error)
+	DeleteTaskCommentsCalled  bool
+	DeleteTaskCommentsTaskIDs []int
+}
+
+func (m *MockCommentRepo) CreateComment(ctx context.Context, comment *model.Comment) (int, error) {
+	m.CreateCommentCalled = true
+
+	if m.CreateCommentFunc != nil {
+		return m.CreateCommentFunc(ctx, comment)
+	}
+
+	return 0, nil
+}
+
+func (m *MockCommentRepo) GetComments(ctx context.Context, taskID int, after int, limit int) ([]*model.Comment, error) {
+	m.GetCommentsCalled = true
+
+	if m.GetCommentsFunc != nil {
+		return m.GetCommentsFunc(ctx, taskID, after, limit)
+	}
+
+	return nil, nil
+}
+
+func (m *MockCommentRepo) GetReplies(ctx context.Context, parentIDs []int) ([]*model.Comment, error) {
+	m.GetRepliesCalled = true
+
+	if m.GetRepliesFunc != nil {
+		return m.GetRepliesFunc(ctx, parentIDs)
+	}
+
+	return nil, nil
+}
+
+func (m *MockCommentRepo) GetCommentByID(ctx context.Context, id int) (*model.Comment, error) {
+	m.GetCommentByIDCalled = true
+
+	if m.GetCommentByIDFunc != nil {
+		return m.GetCommentByIDFunc(ctx, id)
+	}
+
+	return nil, nil
+}
+
+func (m *MockCommentRepo) UpdateComment(ctx context.Context, comment *model.Comment) error {
+	m.UpdateCommentCalled = true
+
+	if m.UpdateCommentFunc != nil {
+		return m.UpdateCommentFunc(ctx, comment)
+	}
+
+	return nil
+}
+
+func (m *MockCommentRepo) DeleteComment(ctx context.Context, id int) (int, error) {
+	m.DeleteCommentCalled = true
+
+	if m.DeleteCommentFunc != nil {
+		return m.DeleteCommentFunc(ctx, id)
+	}
+
+	return 0, nil
+}
+
+func (m *MockCommentRepo) DeleteTaskComments(ctx context.Context, taskIDs []int) (int, error) {
+	m.DeleteTaskCommentsCalled = true
+	m.DeleteTaskCommentsTaskIDs = taskIDs
+
+	if m.DeleteTaskCommentsFunc != nil {
+		return m.DeleteTaskCommentsFunc(ctx, taskIDs)
+	}
+
+	return 0, nil
+}
@@ -0,0 +1,75 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/solumD/tasks-service/internal/model"
+)
+
+// MockProjectRepo мок репозитория проектов
+type MockProjectRepo struct {
+	CreateProjectFunc   func(ctx context.Context, project *model.Project) (int, error)
+	CreateProjectCalled bool
+
+	GetProjectsFunc   func(ctx context.Context) ([]*model.Project, error)
+	GetProjectsCalled bool
+
+	GetProjectByIDFunc   func(ctx context.Context, id int) (*model.Project, error)
+	GetProjectByIDCalled bool
+
+	UpdateProjectFunc   func(ctx context.Context, project *model.Project) error
+	UpdateProjectCalled bool
+
+	DeleteProjectFunc   func(ctx context.Context, id int, version int) error
+	DeleteProjectCalled bool
+}
+
+func (m *MockProjectRepo) CreateProject(ctx context.Context, project *model.Project) (int, error) {
+	m.CreateProjectCalled = true
+
+	if m.CreateProjectFunc != nil {
+		return m.CreateProjectFunc(ctx, project)
+	}
+
+	return 0, nil
+}
+
+func (m *MockProjectRepo) GetProjects(ctx context.Context) ([]*model.Project, error) {
+	m.GetProjectsCalled = true
+
+	if m.GetProjectsFunc != nil {
+		return m.GetProjectsFunc(ctx)
+	}
+
+	return nil, nil
+}
+
+func (m *MockProjectRepo) GetProjectByID(ctx context.Context, id int) (*model.Project, error) {
+	m.GetProjectByIDCalled = true
+
+	if m.GetProjectByIDFunc != nil {
+		return m.GetProjectByIDFunc(ctx, id)
+	}
+
+	return nil, nil
+}
+
+func (m *MockProjectRepo) UpdateProject(ctx context.Context, project *model.Project) error {
+	m.UpdateProjectCalled = true
+
+	if m.UpdateProjectFunc != nil {
+		return m.UpdateProjectFunc(ctx, project)
+	}
+
+	return nil
+}
+
+func (m *MockProjectRepo) DeleteProject(ctx context.Context, id int, version int) error {
+	m.DeleteProjectCalled = true
+
+	if m.DeleteProjectFunc != nil {
+		return m.DeleteProjectFunc(ctx, id, version)
+	}
+
+	return nil
+}
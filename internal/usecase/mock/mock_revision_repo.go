@@ -0,0 +1,51 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/solumD/tasks-service/internal/model"
+)
+
+// MockRevisionRepo мок репозитория ревизий
+type MockRevisionRepo struct {
+	AddRevisionFunc      func(ctx context.Context, rev *model.Revision) error
+	AddRevisionCalled    bool
+	AddRevisionRevisions []*model.Revision
+
+	GetRevisionsFunc   func(ctx context.Context, taskID int) ([]*model.Revision, error)
+	GetRevisionsCalled bool
+
+	GetRevisionFunc   func(ctx context.Context, taskID int, rev int) (*model.Revision, error)
+	GetRevisionCalled bool
+}
+
+func (m *MockRevisionRepo) AddRevision(ctx context.Context, rev *model.Revision) error {
+	m.AddRevisionCalled = true
+	m.AddRevisionRevisions = append(m.AddRevisionRevisions, rev)
+
+	if m.AddRevisionFunc != nil {
+		return m.AddRevisionFunc(ctx, rev)
+	}
+
+	return nil
+}
+
+func (m *MockRevisionRepo) GetRevisions(ctx context.Context, taskID int) ([]*model.Revision, error) {
+	m.GetRevisionsCalled = true
+
+	if m.GetRevisionsFunc != nil {
+		return m.GetRevisionsFunc(ctx, taskID)
+	}
+
+	return nil, nil
+}
+
+func (m *MockRevisionRepo) GetRevision(ctx context.Context, taskID int, rev int) (*model.Revision, error) {
+	m.GetRevisionCalled = true
+
+	if m.GetRevisionFunc != nil {
+		return m.GetRevisionFunc(ctx, taskID, rev)
+	}
+
+	return nil, nil
+}
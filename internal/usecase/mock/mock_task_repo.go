@@ -2,6 +2,7 @@ package mock
 
 import (
 	"context"
+	"time"
 
 	"github.com/solumD/tasks-service/internal/model"
 )
@@ -19,17 +20,47 @@ type MockTaskRepo struct {
 	GetTaskByIDCalled bool
 	GetTaskByIDID     int
 
+	GetTasksByDueFunc   func(ctx context.Context, from, to time.Time) ([]*model.Task, error)
+	GetTasksByDueCalled bool
+	GetTasksByDueFrom   time.Time
+	GetTasksByDueTo     time.Time
+
+	GetTasksByTagsFunc   func(ctx context.Context, tags []string, all bool) ([]*model.Task, error)
+	GetTasksByTagsCalled bool
+	GetTasksByTagsTags   []string
+	GetTasksByTagsAll    bool
+
+	GetTasksByParentFunc     func(ctx context.Context, parentID int) ([]*model.Task, error)
+	GetTasksByParentCalled   bool
+	GetTasksByParentParentID int
+
+	GetTagCountsFunc   func(ctx context.Context) ([]model.TagCount, error)
+	GetTagCountsCalled bool
+
+	RenameTagFunc   func(ctx context.Context, from, to string) ([]*model.Task, error)
+	RenameTagCalled bool
+	RenameTagFrom   string
+	RenameTagTo     string
+
 	UpdateTaskFunc   func(ctx context.Context, task *model.Task) error
 	UpdateTaskCalled bool
 	UpdateTaskTask   *model.Task
 
-	DeleteTaskFunc   func(ctx context.Context, id int) error
-	DeleteTaskCalled bool
-	DeleteTaskID     int
+	DeleteTaskFunc    func(ctx context.Context, id int, version int) (*model.Task, error)
+	DeleteTaskCalled  bool
+	DeleteTaskID      int
+	DeleteTaskVersion int
+
+	GetDeletedTasksFunc   func(ctx context.Context) ([]*model.Task, error)
+	GetDeletedTasksCalled bool
+
+	RestoreTaskFunc   func(ctx context.Context, id int) (*model.Task, error)
+	RestoreTaskCalled bool
+	RestoreTaskID     int
 
-	IsTaskExistByIDFunc   func(ctx context.Context, id int) (bool, error)
-	IsTaskExistByIDCalled bool
-	IsTaskExistByIDID     int
+	PurgeDeletedTasksFunc   func(ctx context.Context, deletedBefore time.Time) (int, error)
+	PurgeDeletedTasksCalled bool
+	PurgeDeletedTasksBefore time.Time
 }
 
 func (m *MockTaskRepo) CreateTask(ctx context.Context, task *model.Task) (int, error) {
@@ -64,6 +95,63 @@ func (m *MockTaskRepo) GetTaskByID(ctx context.Context, id int) (*model.Task, er
 	return nil, nil
 }
 
+func (m *MockTaskRepo) GetTasksByDue(ctx context.Context, from, to time.Time) ([]*model.Task, error) {
+	m.GetTasksByDueCalled = true
+	m.GetTasksByDueFrom = from
+	m.GetTasksByDueTo = to
+
+	if m.GetTasksByDueFunc != nil {
+		return m.GetTasksByDueFunc(ctx, from, to)
+	}
+
+	return nil, nil
+}
+
+func (m *MockTaskRepo) GetTasksByTags(ctx context.Context, tags []string, all bool) ([]*model.Task, error) {
+	m.GetTasksByTagsCalled = true
+	m.GetTasksByTagsTags = tags
+	m.GetTasksByTagsAll = all
+
+	if m.GetTasksByTagsFunc != nil {
+		return m.GetTasksByTagsFunc(ctx, tags, all)
+	}
+
+	return nil, nil
+}
+
+func (m *MockTaskRepo) GetTasksByParent(ctx context.Context, parentID int) ([]*model.Task, error) {
+	m.GetTasksByParentCalled = true
+	m.GetTasksByParentParentID = parentID
+
+	if m.GetTasksByParentFunc != nil {
+		return m.GetTasksByParentFunc(ctx, parentID)
+	}
+
+	return nil, nil
+}
+
+func (m *MockTaskRepo) GetTagCounts(ctx context.Context) ([]model.TagCount, error) {
+	m.GetTagCountsCalled = true
+
+	if m.GetTagCountsFunc != nil {
+		return m.GetTagCountsFunc(ctx)
+	}
+
+	return nil, nil
+}
+
+func (m *MockTaskRepo) RenameTag(ctx context.Context, from, to string) ([]*model.Task, error) {
+	m.RenameTagCalled = true
+	m.RenameTagFrom = from
+	m.RenameTagTo = to
+
+	if m.RenameTagFunc != nil {
+		return m.RenameTagFunc(ctx, from, to)
+	}
+
+	return nil, nil
+}
+
 func (m *MockTaskRepo) UpdateTask(ctx context.Context, task *model.Task) error {
 	m.UpdateTaskCalled = true
 	m.UpdateTaskTask = task
@@ -75,24 +163,46 @@ func (m *MockTaskRepo) UpdateTask(ctx context.Context, task *model.Task) error {
 	return nil
 }
 
-func (m *MockTaskRepo) DeleteTask(ctx context.Context, id int) error {
+func (m *MockTaskRepo) DeleteTask(ctx context.Context, id int, version int) (*model.Task, error) {
 	m.DeleteTaskCalled = true
 	m.DeleteTaskID = id
+	m.DeleteTaskVersion = version
 
 	if m.DeleteTaskFunc != nil {
-		return m.DeleteTaskFunc(ctx, id)
+		return m.DeleteTaskFunc(ctx, id, version)
 	}
 
-	return nil
+	return &model.Task{ID: id}, nil
 }
 
-func (m *MockTaskRepo) IsTaskExistByID(ctx context.Context, id int) (bool, error) {
-	m.IsTaskExistByIDCalled = true
-	m.IsTaskExistByIDID = id
+func (m *MockTaskRepo) GetDeletedTasks(ctx context.Context) ([]*model.Task, error) {
+	m.GetDeletedTasksCalled = true
 
-	if m.IsTaskExistByIDFunc != nil {
-		return m.IsTaskExistByIDFunc(ctx, id)
+	if m.GetDeletedTasksFunc != nil {
+		return m.GetDeletedTasksFunc(ctx)
 	}
 
-	return false, nil
+	return nil, nil
+}
+
+func (m *MockTaskRepo) RestoreTask(ctx context.Context, id int) (*model.Task, error) {
+	m.RestoreTaskCalled = true
+	m.RestoreTaskID = id
+
+	if m.RestoreTaskFunc != nil {
+		return m.RestoreTaskFunc(ctx, id)
+	}
+
+	return nil, nil
+}
+
+func (m *MockTaskRepo) PurgeDeletedTasks(ctx context.Context, deletedBefore time.Time) (int, error) {
+	m.PurgeDeletedTasksCalled = true
+	m.PurgeDeletedTasksBefore = deletedBefore
+
+	if m.PurgeDeletedTasksFunc != nil {
+		return m.PurgeDeletedTasksFunc(ctx, deletedBefore)
+	}
+
+	return 0, nil
 }
@@ -0,0 +1,501 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/solumD/tasks-service/internal/model"
+	"github.com/solumD/tasks-service/pkg/logger"
+)
+
+// maxMoveRetries сколько раз повторяется перенос задачи при конфликте версий
+const maxMoveRetries = 3
+
+type projectUsecase struct {
+	projectRepo ProjectRepo
+	tasks       ProjectTasks
+	onDelete    model.DeleteRule
+	log         *slog.Logger
+}
+
+// NewProjectUsecase возвращает юзкейс проектов. Задачи проектов он меняет через tasks
+// с теми же проверками и ревизиями, tasks должен работать с тем же projectRepo.
+// onDelete - что происходит с задачами удаляемого проекта, пустое значение - model.DeleteBlock
+func NewProjectUsecase(projectRepo ProjectRepo, tasks ProjectTasks, onDelete model.DeleteRule, log *slog.Logger) *projectUsecase {
+	if onDelete == "" {
+		onDelete = model.DeleteBlock
+	}
+
+	return &projectUsecase{
+		projectRepo: projectRepo,
+		tasks:       tasks,
+		onDelete:    onDelete,
+		log:         log,
+	}
+}
+
+// CreateProject создает новый активный проект и возвращает его ID
+func (u *projectUsecase) CreateProject(ctx context.Context, project *model.Project) (int, error) {
+	const fn = "projectUsecase.CreateProject"
+	log := u.log.With(logger.String("fn", fn))
+
+	if len(project.Name) == 0 {
+		return 0, ErrEmptyProjectName
+	}
+
+	project.ArchivedAt = nil
+
+	id, err := u.projectRepo.CreateProject(ctx, project)
+	if err != nil {
+		log.Error("failed to create project in repo", logger.Error(err))
+
+		return 0, err
+	}
+
+	log.Info("created project in repo", logger.Int("project id", id))
+
+	return id, nil
+}
+
+// GetProjects возвращает все проекты по возрастанию ID с количеством их задач
+func (u *projectUsecase) GetProjects(ctx context.Context) ([]*model.Project, error) {
+	const fn = "projectUsecase.GetProjects"
+	log := u.log.With(logger.String("fn", fn))
+
+	projects, err := u.projectRepo.GetProjects(ctx)
+	if err != nil {
+		log.Error("failed to get projects from repo", logger.Error(err))
+
+		return nil, err
+	}
+
+	if err := u.countTasks(ctx, projects); err != nil {
+		log.Error("failed to count project tasks", logger.Error(err))
+
+		return nil, err
+	}
+
+	log.Info("got projects from repo", logger.Int("projects count", len(projects)))
+
+	return projects, nil
+}
+
+// GetProject возвращает проект по ID с количеством его задач
+func (u *projectUsecase) GetProject(ctx context.Context, id int) (*model.Project, error) {
+	const fn = "projectUsecase.GetProject"
+	log := u.log.With(logger.String("fn", fn))
+
+	project, err := u.projectRepo.GetProjectByID(ctx, id)
+	if err != nil {
+		log.Error("failed to get project from repo", logger.Error(err))
+
+		return nil, err
+	}
+
+	if err := u.countTasks(ctx, []*model.Project{project}); err != nil {
+		log.Error("failed to count project tasks", logger.Error(err))
+
+		return nil, err
+	}
+
+	log.Info("got project from repo", logger.Int("project id", id))
+
+	return project, nil
+}
+
+// UpdateProject меняет название и описание проекта и переносит его в архив или из архива.
+// Если project.Version не 0, проект обновляется только в этой версии, после обновления
+// project.Version содержит новую версию. Время архивации уже архивного проекта не меняется
+func (u *projectUsecase) UpdateProject(ctx context.Context, project *model.Project, archived bool) error {
+	const fn = "projectUsecase.UpdateProject"
+	log := u.log.With(logger.String("fn", fn))
+
+	if len(project.Name) == 0 {
+		return ErrEmptyProjectName
+	}
+
+	// архивация не должна разойтись с параллельным добавлением задачи в проект
+	err := u.tasks.WithLinksLocked(func() error {
+		current, err := u.projectRepo.GetProjectByID(ctx, project.ID)
+		if err != nil {
+			log.Error("failed to get project from repo", logger.Error(err))
+
+			return err
+		}
+
+		switch {
+		case !archived:
+			project.ArchivedAt = nil
+		case current.ArchivedAt != nil:
+			project.ArchivedAt = current.ArchivedAt
+		default:
+			now := time.Now().UTC()
+			project.ArchivedAt = &now
+		}
+
+		if err := u.projectRepo.UpdateProject(ctx, project); err != nil {
+			log.Error("failed to update project in repo", logger.Error(err))
+
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Info("updated project in repo", logger.Int("project id", project.ID), logger.Any("archived", archived))
+
+	return nil
+}
+
+// DeleteProject удаляет проект. Если version не 0, проект удаляется только в этой версии.
+// С задачами проекта поступает по правилу onDelete: block запрещает удалять проект
+// с задачами, cascade перемещает их в корзину, orphan выводит их из проекта.
+// Задачи проекта в корзине не мешают удалению и восстанавливаются вне проектов
+func (u *projectUsecase) DeleteProject(ctx context.Context, id int, version int) error {
+	const fn = "projectUsecase.DeleteProject"
+	log := u.log.With(logger.String("fn", fn))
+
+	err := u.tasks.WithLinksLocked(func() error {
+		project, err := u.projectRepo.GetProjectByID(ctx, id)
+		if err != nil {
+			log.Error("failed to get project from repo", logger.Error(err))
+
+			return err
+		}
+
+		if version != 0 && project.Version != version {
+			return ErrProjectVersionConflict
+		}
+
+		tasks, err := u.tasks.GetAllTasks(ctx, model.TaskFilter{ProjectID: id})
+		if err != nil {
+			log.Error("failed to get project tasks", logger.Error(err))
+
+			return err
+		}
+
+		if len(tasks) > 0 && u.onDelete == model.DeleteBlock {
+			return ErrProjectHasTasks
+		}
+
+		// задачи меняются до удаления проекта, чтобы при ошибке проект можно было удалить повторно
+		if err := u.tasks.ReleaseProjectTasks(ctx, id, tasks, u.onDelete); err != nil {
+			log.Error("failed to apply delete rule to project tasks", logger.Int("project id", id), logger.Error(err))
+
+			return err
+		}
+
+		if len(tasks) > 0 {
+			log.Info("applied delete rule to project tasks",
+				logger.Int("project id", id),
+				logger.String("rule", string(u.onDelete)),
+				logger.Int("tasks count", len(tasks)),
+			)
+		}
+
+		// под блокировкой связей версия проекта не могла измениться с момента чтения
+		if err := u.projectRepo.DeleteProject(ctx, id, project.Version); err != nil {
+			log.Error("failed to delete project in repo", logger.Error(err))
+
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Info("deleted project in repo", logger.Int("project id", id))
+
+	return nil
+}
+
+// GetProjectTasks возвращает задачи проекта id, подходящие под filter, в заданном им порядке
+func (u *projectUsecase) GetProjectTasks(ctx context.Context, id int, filter model.TaskFilter) ([]*model.Task, error) {
+	const fn = "projectUsecase.GetProjectTasks"
+	log := u.log.With(logger.String("fn", fn))
+
+	if _, err := u.projectRepo.GetProjectByID(ctx, id); err != nil {
+		log.Error("failed to get project from repo", logger.Error(err))
+
+		return nil, err
+	}
+
+	filter.ProjectID = id
+
+	return u.tasks.GetAllTasks(ctx, filter)
+}
+
+// CreateProjectTask создает задачу в проекте id и возвращает ее ID
+func (u *projectUsecase) CreateProjectTask(ctx context.Context, id int, task *model.Task) (int, error) {
+	task.ProjectID = id
+
+	return u.tasks.CreateTask(ctx, task)
+}
+
+// MoveTask переносит задачу id в проект projectID (0 - вывести из проектов)
+// и возвращает задачу. Если version не 0, задача переносится только в этой версии.
+// Из архивного проекта задачу перенести можно, в архивный - нельзя
+func (u *projectUsecase) MoveTask(ctx context.Context, id int, projectID int, version int) (*model.Task, error) {
+	return u.tasks.MoveTask(ctx, id, projectID, version)
+}
+
+// countTasks заполняет количество задач не из корзины у каждого проекта
+func (u *projectUsecase) countTasks(ctx context.Context, projects []*model.Project) error {
+	if len(projects) == 0 {
+		return nil
+	}
+
+	tasks, err := u.tasks.GetAllTasks(ctx, model.TaskFilter{})
+	if err != nil {
+		return err
+	}
+
+	counts := make(map[int]*model.ProjectCounts, len(projects))
+	for _, project := range projects {
+		project.Counts = model.ProjectCounts{ByStatus: make(map[model.Status]int)}
+		counts[project.ID] = &project.Counts
+	}
+
+	for _, task := range tasks {
+		count, ok := counts[task.ProjectID]
+		if !ok {
+			continue
+		}
+
+		count.Total++
+		count.ByStatus[task.CurrentStatus()]++
+	}
+
+	return nil
+}
+
+// MoveTask переносит задачу id в проект projectID (0 - вывести из проектов)
+// и возвращает задачу. Если version не 0, задача переносится только в этой версии.
+// Из архивного проекта задачу перенести можно, в архивный - нельзя
+func (u *taskUsecase) MoveTask(ctx context.Context, id int, projectID int, version int) (*model.Task, error) {
+	const fn = "taskUsecase.MoveTask"
+	log := u.log.With(logger.String("fn", fn))
+
+	u.linksMu.Lock()
+	defer u.linksMu.Unlock()
+
+	for range maxMoveRetries {
+		current, err := u.taskRepo.GetTaskByID(ctx, id)
+		if err != nil {
+			log.Error("failed to get task from repo", logger.Error(err))
+
+			return nil, err
+		}
+
+		if version != 0 && current.Version != version {
+			return nil, ErrVersionConflict
+		}
+
+		if current.ProjectID == projectID {
+			return u.withBlocked(ctx, current)
+		}
+
+		// хранилище может отдавать свою копию задачи, меняется отдельная.
+		// Задача обновляется в прочитанной версии, чтобы не затереть
+		// параллельное изменение других полей
+		task := current.Clone()
+		task.ProjectID = projectID
+
+		if err := u.checkProject(ctx, task); err != nil {
+			log.Error("failed to check task project", logger.Error(err))
+
+			return nil, err
+		}
+
+		err = u.taskRepo.UpdateTask(ctx, task)
+		if errors.Is(err, ErrVersionConflict) && version == 0 {
+			continue
+		}
+
+		if err != nil {
+			log.Error("failed to move task in repo", logger.Error(err))
+
+			return nil, err
+		}
+
+		log.Info("moved task in repo",
+			logger.Int("task id", id),
+			logger.Int("from project id", current.ProjectID),
+			logger.Int("to project id", projectID),
+		)
+
+		u.recordRevision(ctx, task, model.RevisionUpdated)
+
+		return u.withBlocked(ctx, task)
+	}
+
+	return nil, ErrVersionConflict
+}
+
+// WithLinksLocked выполняет fn под блокировкой связей между задачами и их проектами.
+// Внутри fn нельзя вызывать методы, которые сами берут эту блокировку
+func (u *taskUsecase) WithLinksLocked(fn func() error) error {
+	u.linksMu.Lock()
+	defer u.linksMu.Unlock()
+
+	return fn()
+}
+
+// ReleaseProjectTasks применяет правило rule к задачам tasks удаляемого проекта id:
+// cascade перемещает их в корзину, orphan выводит их из проекта.
+// Вызывается внутри WithLinksLocked
+func (u *taskUsecase) ReleaseProjectTasks(ctx context.Context, id int, tasks []*model.Task, rule model.DeleteRule) error {
+	switch rule {
+	case model.DeleteCascade:
+		return u.trashTasks(ctx, tasks)
+	case model.DeleteOrphan:
+		for _, task := range tasks {
+			if err := u.setProject(ctx, task.ID, id, 0); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// trashTasks перемещает задачи в корзину. Их подзадачи из других проектов
+// становятся задачами верхнего уровня, а родители вне удаляемых задач
+// проверяются на автовыполнение
+func (u *taskUsecase) trashTasks(ctx context.Context, tasks []*model.Task) error {
+	const fn = "taskUsecase.trashTasks"
+	log := u.log.With(logger.String("fn", fn))
+
+	trashed := make(map[int]struct{}, len(tasks))
+	for _, task := range tasks {
+		trashed[task.ID] = struct{}{}
+	}
+
+	for _, task := range tasks {
+		deleted, err := u.taskRepo.DeleteTask(ctx, task.ID, 0)
+		if errors.Is(err, ErrTaskNotFound) {
+			continue
+		}
+
+		if err != nil {
+			return err
+		}
+
+		u.recordRevision(ctx, deleted, model.RevisionDeleted)
+
+		children, err := u.getChildren(ctx, task.ID)
+		if err != nil {
+			return err
+		}
+
+		for _, child := range children {
+			if _, ok := trashed[child.ID]; ok {
+				continue
+			}
+
+			if err := u.setParent(ctx, child.ID, task.ID, 0); err != nil {
+				return err
+			}
+		}
+	}
+
+	// удаленная задача могла быть единственной невыполненной подзадачей
+	for _, task := range tasks {
+		if _, ok := trashed[task.ParentID]; ok || task.Done {
+			continue
+		}
+
+		if err := u.completeParents(ctx, task.ParentID); err != nil {
+			log.Error("failed to complete parent tasks", logger.Error(err))
+		}
+	}
+
+	return nil
+}
+
+// checkProject проверяет, что проект задачи существует и принимает задачи.
+// Задача, которая уже в проекте, остается в нем, даже если проект в архиве
+func (u *taskUsecase) checkProject(ctx context.Context, task *model.Task) error {
+	if task.ProjectID == 0 {
+		return nil
+	}
+
+	if task.ID != 0 {
+		current, err := u.taskRepo.GetTaskByID(ctx, task.ID)
+		if err != nil {
+			return err
+		}
+
+		if current.ProjectID == task.ProjectID {
+			return nil
+		}
+	}
+
+	project, err := u.projectRepo.GetProjectByID(ctx, task.ProjectID)
+	if err != nil {
+		return err
+	}
+
+	if project.Archived() {
+		return ErrProjectArchived
+	}
+
+	return nil
+}
+
+// setProject меняет проект задачи id с from на to, если задача все еще в проекте from.
+// Задача перечитывается и обновляется с проверкой версии, чтобы не затереть
+// параллельное изменение других полей
+func (u *taskUsecase) setProject(ctx context.Context, id int, from int, to int) error {
+	for range maxMoveRetries {
+		current, err := u.taskRepo.GetTaskByID(ctx, id)
+		if errors.Is(err, ErrTaskNotFound) {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if current.ProjectID != from {
+			return nil
+		}
+
+		task := current.Clone()
+		task.ProjectID = to
+
+		err = u.taskRepo.UpdateTask(ctx, task)
+		if errors.Is(err, ErrVersionConflict) {
+			continue
+		}
+
+		if err != nil {
+			return err
+		}
+
+		u.recordRevision(ctx, task, model.RevisionUpdated)
+
+		return nil
+	}
+
+	return ErrVersionConflict
+}
+
+// filterByProject оставляет задачи проекта id
+func filterByProject(tasks []*model.Task, id int) []*model.Task {
+	filtered := make([]*model.Task, 0, len(tasks))
+	for _, task := range tasks {
+		if task.ProjectID == id {
+			filtered = append(filtered, task)
+		}
+	}
+
+	return filtered
+}
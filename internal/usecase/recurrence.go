@@ -0,0 +1,126 @@
+package usecase
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/solumD/tasks-service/internal/model"
+	"github.com/solumD/tasks-service/pkg/logger"
+)
+
+// maxOccurrences наибольшее количество повторений в одном запросе
+const maxOccurrences = 100
+
+// GetOccurrences возвращает до count следующих повторений задачи id после ее срока.
+// У неповторяющейся задачи повторений нет
+func (u *taskUsecase) GetOccurrences(ctx context.Context, id int, count int) ([]time.Time, error) {
+	const fn = "taskUsecase.GetOccurrences"
+	log := u.log.With(logger.String("fn", fn))
+
+	if count < 1 || count > maxOccurrences {
+		return nil, ErrInvalidOccurrences
+	}
+
+	task, err := u.taskRepo.GetTaskByID(ctx, id)
+	if err != nil {
+		log.Error("failed to get task from repo", logger.Error(err))
+
+		return nil, err
+	}
+
+	if task.Recurrence == "" || task.DueAt == nil {
+		return []time.Time{}, nil
+	}
+
+	rule, err := model.ParseRecurrenceRule(task.Recurrence)
+	if err != nil {
+		log.Error("failed to parse recurrence rule", logger.Int("task id", id), logger.Error(err))
+
+		return nil, err
+	}
+
+	occurrences := rule.Occurrences(*task.DueAt, count)
+
+	log.Info("got task occurrences", logger.Int("task id", id), logger.Int("occurrences count", len(occurrences)))
+
+	return occurrences, nil
+}
+
+// rollOver готовит отметку повторяющейся задачи выполненной: правило переходит
+// к следующему повторению, которое возвращается для создания после обновления.
+// У выполненной задачи правила не остается. nil - задача уже была выполнена
+// или повторения закончились
+func (u *taskUsecase) rollOver(ctx context.Context, task *model.Task) (*model.Task, error) {
+	current, err := u.taskRepo.GetTaskByID(ctx, task.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if current.Done {
+		task.Recurrence = ""
+
+		return nil, nil
+	}
+
+	// без ожидаемой версии две параллельные отметки создали бы два повторения
+	if task.Version == 0 {
+		task.Version = current.Version
+	}
+
+	next := nextOccurrence(task)
+	task.Recurrence = ""
+
+	return next, nil
+}
+
+// createOccurrence создает следующее повторение задачи id. Задача уже отмечена
+// выполненной, поэтому ошибка только записывается в лог
+func (u *taskUsecase) createOccurrence(ctx context.Context, id int, next *model.Task) {
+	const fn = "taskUsecase.createOccurrence"
+	log := u.log.With(logger.String("fn", fn))
+
+	next.CreatedAt = time.Now().UTC()
+
+	nextID, err := u.taskRepo.CreateTask(ctx, next)
+	if err != nil {
+		log.Error("failed to create next occurrence in repo", logger.Int("task id", id), logger.Error(err))
+
+		return
+	}
+
+	log.Info("created next occurrence in repo", logger.Int("task id", id), logger.Int("next task id", nextID))
+
+	u.recordRevision(ctx, next, model.RevisionCreated)
+}
+
+// nextOccurrence возвращает невыполненную копию задачи со сроком следующего повторения
+// и оставшейся частью правила. Зависимости не копируются. nil - повторений больше нет
+func nextOccurrence(task *model.Task) *model.Task {
+	rule, err := model.ParseRecurrenceRule(task.Recurrence)
+	if err != nil {
+		return nil
+	}
+
+	dates := rule.Occurrences(*task.DueAt, 1)
+	if len(dates) == 0 {
+		return nil
+	}
+
+	// COUNT учитывает выполненное повторение
+	if rule.Count > 0 {
+		rule.Count--
+	}
+
+	return &model.Task{
+		Title:       task.Title,
+		Description: task.Description,
+		Status:      model.StatusTodo,
+		DueAt:       &dates[0],
+		Priority:    task.Priority,
+		Tags:        slices.Clone(task.Tags),
+		ParentID:    task.ParentID,
+		ProjectID:   task.ProjectID,
+		Recurrence:  rule.String(),
+	}
+}
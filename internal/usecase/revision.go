@@ -0,0 +1,275 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/solumD/tasks-service/internal/model"
+	"github.com/solumD/tasks-service/pkg/logger"
+)
+
+// GetTaskHistory возвращает все ревизии задачи по возрастанию номера,
+// в том числе для задачи в корзине или окончательно удаленной
+func (u *taskUsecase) GetTaskHistory(ctx context.Context, id int) ([]*model.Revision, error) {
+	const fn = "taskUsecase.GetTaskHistory"
+	log := u.log.With(logger.String("fn", fn))
+
+	revisions, err := u.revisionRepo.GetRevisions(ctx, id)
+	if err != nil {
+		log.Error("failed to get task revisions from repo", logger.Error(err))
+
+		return nil, err
+	}
+
+	if len(revisions) == 0 {
+		return nil, NewTaskNotFoundError(id)
+	}
+
+	log.Info("got task revisions from repo", logger.Int("task id", id), logger.Int("revisions count", len(revisions)))
+
+	return revisions, nil
+}
+
+// GetTaskRevision возвращает ревизию rev задачи
+func (u *taskUsecase) GetTaskRevision(ctx context.Context, id int, rev int) (*model.Revision, error) {
+	const fn = "taskUsecase.GetTaskRevision"
+	log := u.log.With(logger.String("fn", fn))
+
+	revision, err := u.revisionRepo.GetRevision(ctx, id, rev)
+	if err != nil {
+		log.Error("failed to get task revision from repo", logger.Error(err))
+
+		return nil, err
+	}
+
+	log.Info("got task revision from repo", logger.Int("task id", id), logger.Int("rev", rev))
+
+	return revision, nil
+}
+
+// DiffTaskRevisions возвращает изменения полей задачи между ревизиями from и to
+func (u *taskUsecase) DiffTaskRevisions(ctx context.Context, id int, from int, to int) ([]model.FieldChange, error) {
+	const fn = "taskUsecase.DiffTaskRevisions"
+	log := u.log.With(logger.String("fn", fn))
+
+	fromRev, err := u.revisionRepo.GetRevision(ctx, id, from)
+	if err != nil {
+		log.Error("failed to get task revision from repo", logger.Error(err))
+
+		return nil, err
+	}
+
+	toRev, err := u.revisionRepo.GetRevision(ctx, id, to)
+	if err != nil {
+		log.Error("failed to get task revision from repo", logger.Error(err))
+
+		return nil, err
+	}
+
+	changes := diffTasks(fromRev.Task, toRev.Task)
+
+	log.Info("diffed task revisions", logger.Int("task id", id), logger.Int("changes count", len(changes)))
+
+	return changes, nil
+}
+
+// RevertTask возвращает поля задачи к состоянию ревизии rev. Откат записывается
+// как новая ревизия. Если version не 0, задача откатывается только в этой версии.
+// Если родителя или проекта из ревизии уже нет, задача откатывается на верхний
+// уровень или вне проектов, зависимости от задач, которых уже нет, не восстанавливаются
+func (u *taskUsecase) RevertTask(ctx context.Context, id int, rev int, version int) (*model.Task, error) {
+	const fn = "taskUsecase.RevertTask"
+	log := u.log.With(logger.String("fn", fn))
+
+	revision, err := u.revisionRepo.GetRevision(ctx, id, rev)
+	if err != nil {
+		log.Error("failed to get task revision from repo", logger.Error(err))
+
+		return nil, err
+	}
+
+	task := &model.Task{
+		ID:          id,
+		Title:       revision.Task.Title,
+		Description: revision.Task.Description,
+		Done:        revision.Task.Done,
+		Status:      revision.Task.CurrentStatus(),
+		DueAt:       revision.Task.Clone().DueAt,
+		Priority:    revision.Task.Priority,
+		Tags:        revision.Task.Clone().Tags,
+		ParentID:    revision.Task.ParentID,
+		DependsOn:   revision.Task.Clone().DependsOn,
+		ProjectID:   revision.Task.ProjectID,
+		Recurrence:  revision.Task.Recurrence,
+		Version:     version,
+	}
+
+	if task.ParentID != 0 || len(task.DependsOn) > 0 || task.ProjectID != 0 {
+		u.linksMu.Lock()
+		defer u.linksMu.Unlock()
+	}
+
+	if err := u.checkTransition(ctx, task); err != nil {
+		log.Error("failed to check task status transition", logger.Error(err))
+
+		return nil, err
+	}
+
+	if task.ParentID != 0 {
+		err := u.checkParent(ctx, task)
+		if errors.Is(err, ErrParentNotFound) {
+			task.ParentID = 0
+		} else if err != nil {
+			log.Error("failed to check parent task", logger.Error(err))
+
+			return nil, err
+		}
+	}
+
+	err = u.checkProject(ctx, task)
+	if errors.Is(err, ErrProjectNotFound) {
+		task.ProjectID = 0
+	} else if err != nil {
+		log.Error("failed to check task project", logger.Error(err))
+
+		return nil, err
+	}
+
+	dependsOn, err := u.existingTasks(ctx, task.DependsOn)
+	if err != nil {
+		log.Error("failed to get dependencies from repo", logger.Error(err))
+
+		return nil, err
+	}
+
+	task.DependsOn = dependsOn
+
+	if err := u.checkDependencies(ctx, task); err != nil {
+		log.Error("failed to check task dependencies", logger.Error(err))
+
+		return nil, err
+	}
+
+	if err := u.taskRepo.UpdateTask(ctx, task); err != nil {
+		log.Error("failed to revert task in repo", logger.Error(err))
+
+		return nil, err
+	}
+
+	log.Info("reverted task in repo", logger.Int("task id", id), logger.Int("rev", rev))
+
+	u.recordRevision(ctx, task, model.RevisionReverted)
+
+	if task.Done {
+		if err := u.completeParents(ctx, task.ParentID); err != nil {
+			log.Error("failed to complete parent tasks", logger.Error(err))
+		}
+	}
+
+	return task, nil
+}
+
+// recordRevision сохраняет снимок задачи после изменения. Изменение уже
+// применено, поэтому ошибка записи ревизии только логируется
+func (u *taskUsecase) recordRevision(ctx context.Context, task *model.Task, action model.RevisionAction) {
+	const fn = "taskUsecase.recordRevision"
+	log := u.log.With(logger.String("fn", fn))
+
+	revision := &model.Revision{
+		TaskID:    task.ID,
+		Rev:       task.Version,
+		Action:    action,
+		Author:    AuthorFromContext(ctx),
+		CreatedAt: time.Now().UTC(),
+		Task:      *task.Clone(),
+	}
+
+	if err := u.revisionRepo.AddRevision(ctx, revision); err != nil {
+		log.Error("failed to add task revision to repo", logger.Int("task id", task.ID), logger.Error(err))
+	}
+}
+
+// diffTasks сравнивает пользовательские поля задачи и признак удаления
+func diffTasks(from, to model.Task) []model.FieldChange {
+	changes := make([]model.FieldChange, 0)
+
+	if from.Title != to.Title {
+		changes = append(changes, model.FieldChange{Field: "title", From: from.Title, To: to.Title})
+	}
+
+	if from.Description != to.Description {
+		changes = append(changes, model.FieldChange{Field: "description", From: from.Description, To: to.Description})
+	}
+
+	if from.Done != to.Done {
+		changes = append(changes, model.FieldChange{Field: "done", From: from.Done, To: to.Done})
+	}
+
+	if fromStatus, toStatus := from.CurrentStatus(), to.CurrentStatus(); fromStatus != toStatus {
+		changes = append(changes, model.FieldChange{Field: "status", From: fromStatus, To: toStatus})
+	}
+
+	if !equalDue(from.DueAt, to.DueAt) {
+		changes = append(changes, model.FieldChange{Field: "due_at", From: from.DueAt, To: to.DueAt})
+	}
+
+	if from.Priority.String() != to.Priority.String() {
+		changes = append(changes, model.FieldChange{Field: "priority", From: from.Priority.String(), To: to.Priority.String()})
+	}
+
+	if !slices.Equal(from.Tags, to.Tags) {
+		changes = append(changes, model.FieldChange{Field: "tags", From: tagsOrEmpty(from.Tags), To: tagsOrEmpty(to.Tags)})
+	}
+
+	if from.ParentID != to.ParentID {
+		changes = append(changes, model.FieldChange{Field: "parent_id", From: from.ParentID, To: to.ParentID})
+	}
+
+	if !slices.Equal(from.DependsOn, to.DependsOn) {
+		changes = append(changes, model.FieldChange{Field: "depends_on", From: idsOrEmpty(from.DependsOn), To: idsOrEmpty(to.DependsOn)})
+	}
+
+	if from.ProjectID != to.ProjectID {
+		changes = append(changes, model.FieldChange{Field: "project_id", From: from.ProjectID, To: to.ProjectID})
+	}
+
+	if from.Recurrence != to.Recurrence {
+		changes = append(changes, model.FieldChange{Field: "recurrence", From: from.Recurrence, To: to.Recurrence})
+	}
+
+	fromDeleted, toDeleted := from.DeletedAt != nil, to.DeletedAt != nil
+	if fromDeleted != toDeleted {
+		changes = append(changes, model.FieldChange{Field: "deleted", From: fromDeleted, To: toDeleted})
+	}
+
+	return changes
+}
+
+// equalDue сравнивает сроки выполнения с точностью до момента времени
+func equalDue(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return a.Equal(*b)
+}
+
+// tagsOrEmpty возвращает пустой список вместо nil, чтобы в JSON изменение выглядело как []
+func tagsOrEmpty(tags []string) []string {
+	if tags == nil {
+		return []string{}
+	}
+
+	return tags
+}
+
+// idsOrEmpty возвращает пустой список вместо nil, чтобы в JSON изменение выглядело как []
+func idsOrEmpty(ids []int) []int {
+	if ids == nil {
+		return []int{}
+	}
+
+	return ids
+}
@@ -0,0 +1,112 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/solumD/tasks-service/internal/model"
+	"github.com/solumD/tasks-service/pkg/logger"
+)
+
+// maxTransitionRetries сколько раз повторяется смена статуса при конфликте версий
+const maxTransitionRetries = 3
+
+// TransitionTask переводит задачу id в статус to по рабочему процессу и возвращает
+// задачу. Если version не 0, задача меняется только в этой версии
+func (u *taskUsecase) TransitionTask(ctx context.Context, id int, to model.Status, version int) (*model.Task, error) {
+	const fn = "taskUsecase.TransitionTask"
+	log := u.log.With(logger.String("fn", fn))
+
+	if !to.Valid() {
+		return nil, ErrInvalidStatus
+	}
+
+	for range maxTransitionRetries {
+		current, err := u.taskRepo.GetTaskByID(ctx, id)
+		if err != nil {
+			log.Error("failed to get task from repo", logger.Error(err))
+
+			return nil, err
+		}
+
+		if version != 0 && current.Version != version {
+			return nil, ErrVersionConflict
+		}
+
+		if current.CurrentStatus() == to {
+			return u.withBlocked(ctx, current)
+		}
+
+		// хранилище может отдавать свою копию задачи, меняется отдельная.
+		// Задача обновляется в прочитанной версии, чтобы не затереть
+		// параллельное изменение других полей
+		task := current.Clone()
+		task.Status = to
+
+		err = u.UpdateTask(ctx, task)
+		if errors.Is(err, ErrVersionConflict) && version == 0 {
+			continue
+		}
+
+		if err != nil {
+			log.Error("failed to change task status", logger.Error(err))
+
+			return nil, err
+		}
+
+		log.Info("changed task status",
+			logger.Int("task id", id),
+			logger.String("from", string(current.CurrentStatus())),
+			logger.String("to", string(to)),
+		)
+
+		return u.withBlocked(ctx, task)
+	}
+
+	return nil, ErrVersionConflict
+}
+
+// checkTransition выводит статус изменяемой задачи, если он не задан, и проверяет
+// переход из текущего статуса по рабочему процессу. Если статус меняется,
+// а task.Version 0, задача будет обновлена только в прочитанной версии
+func (u *taskUsecase) checkTransition(ctx context.Context, task *model.Task) error {
+	current, err := u.taskRepo.GetTaskByID(ctx, task.ID)
+	if err != nil {
+		return err
+	}
+
+	from := current.CurrentStatus()
+
+	// клиенты, которые не знают о статусах, меняют только признак done
+	to := task.Status
+	if to == "" {
+		switch {
+		case task.Done && from != model.StatusDone:
+			to = model.StatusDone
+		case !task.Done && from == model.StatusDone:
+			to = model.StatusTodo
+		default:
+			to = from
+		}
+	}
+
+	setStatus(task, to)
+
+	if !u.workflow.Allows(from, to) {
+		return fmt.Errorf("%w: from %s to %s", ErrInvalidTransition, from, to)
+	}
+
+	// без ожидаемой версии параллельное изменение статуса могло бы обойти рабочий процесс
+	if from != to && task.Version == 0 {
+		task.Version = current.Version
+	}
+
+	return nil
+}
+
+// setStatus задает статус задачи вместе с признаком done
+func setStatus(task *model.Task, status model.Status) {
+	task.Status = status
+	task.Done = status == model.StatusDone
+}
@@ -0,0 +1,106 @@
+package usecase
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/solumD/tasks-service/internal/model"
+	"github.com/solumD/tasks-service/pkg/logger"
+)
+
+// maxTagLen максимальная длина метки в символах
+const maxTagLen = 64
+
+// GetTags возвращает метки задач не из корзины по алфавиту с количеством задач у каждой
+func (u *taskUsecase) GetTags(ctx context.Context) ([]model.TagCount, error) {
+	const fn = "taskUsecase.GetTags"
+	log := u.log.With(logger.String("fn", fn))
+
+	counts, err := u.taskRepo.GetTagCounts(ctx)
+	if err != nil {
+		log.Error("failed to get tag counts from repo", logger.Error(err))
+
+		return nil, err
+	}
+
+	log.Info("got tag counts from repo", logger.Int("tags count", len(counts)))
+
+	return counts, nil
+}
+
+// RenameTag заменяет метку from на to у всех задач не из корзины и возвращает
+// измененные задачи. Если у задачи уже есть метка to, метки сливаются в одну
+func (u *taskUsecase) RenameTag(ctx context.Context, from, to string) ([]*model.Task, error) {
+	const fn = "taskUsecase.RenameTag"
+	log := u.log.With(logger.String("fn", fn))
+
+	from, err := normalizeTag(from)
+	if err != nil {
+		return nil, err
+	}
+
+	to, err = normalizeTag(to)
+	if err != nil {
+		return nil, err
+	}
+
+	if from == to {
+		return []*model.Task{}, nil
+	}
+
+	renamed, err := u.taskRepo.RenameTag(ctx, from, to)
+	if err != nil {
+		log.Error("failed to rename tag in repo", logger.Error(err))
+
+		return nil, err
+	}
+
+	log.Info("renamed tag in repo",
+		logger.String("from", from),
+		logger.String("to", to),
+		logger.Int("tasks count", len(renamed)),
+	)
+
+	for _, task := range renamed {
+		u.recordRevision(ctx, task, model.RevisionUpdated)
+	}
+
+	return renamed, nil
+}
+
+// normalizeTags приводит метки к нижнему регистру без пробелов по краям,
+// убирает повторы и упорядочивает. Пустой список возвращается как nil
+func normalizeTags(tags []string) ([]string, error) {
+	if len(tags) == 0 {
+		return nil, nil
+	}
+
+	normalized := make([]string, 0, len(tags))
+
+	for _, tag := range tags {
+		tag, err := normalizeTag(tag)
+		if err != nil {
+			return nil, err
+		}
+
+		normalized = append(normalized, tag)
+	}
+
+	slices.Sort(normalized)
+
+	return slices.Compact(normalized), nil
+}
+
+// normalizeTag приводит метку к виду, в котором она хранится. Запятая
+// разделяет метки в параметре запроса и в CSV, поэтому в метке ее быть не может
+func normalizeTag(tag string) (string, error) {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+
+	if len(tag) == 0 || utf8.RuneCountInString(tag) > maxTagLen || strings.Contains(tag, ",") {
+		return "", ErrInvalidTag
+	}
+
+	return tag, nil
+}
@@ -1,39 +1,106 @@
 package usecase
 
 import (
+	"cmp"
 	"context"
 	"errors"
+	"fmt"
 	"log/slog"
 	"sort"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/solumD/tasks-service/internal/model"
 	"github.com/solumD/tasks-service/pkg/logger"
 )
 
-var (
-	ErrEmptyTitle   = errors.New("task title is empty")
-	ErrTaskNotFound = errors.New("task not found")
-)
+// Rules правила, которые юзкейс применяет к связям между задачами и к их статусам
+type Rules struct {
+	Hierarchy    Hierarchy
+	Dependencies Dependencies
+	// Workflow допустимые переходы между статусами, nil - model.DefaultWorkflow()
+	Workflow model.Workflow
+}
 
 type taskUsecase struct {
-	taskRepo TaskRepo
-	log      *slog.Logger
+	taskRepo     TaskRepo
+	revisionRepo RevisionRepo
+	projectRepo  ProjectRepo
+	commentRepo  CommentRepo
+	hierarchy    Hierarchy
+	dependencies Dependencies
+	workflow     model.Workflow
+	log          *slog.Logger
+
+	// linksMu упорядочивает изменения связей между задачами и их проектами: без него
+	// параллельные изменения могли бы образовать цикл в дереве или в зависимостях,
+	// подзадача - появиться у удаляемой задачи, а задача - у архивируемого проекта
+	linksMu *sync.Mutex
 }
 
-func NewTaskUsecase(taskRepo TaskRepo, log *slog.Logger) *taskUsecase {
+func NewTaskUsecase(taskRepo TaskRepo, revisionRepo RevisionRepo, projectRepo ProjectRepo, commentRepo CommentRepo, rules Rules, log *slog.Logger) *taskUsecase {
+	if rules.Hierarchy.OnDelete == "" {
+		rules.Hierarchy.OnDelete = model.DeleteBlock
+	}
+
+	if rules.Workflow == nil {
+		rules.Workflow = model.DefaultWorkflow()
+	}
+
 	return &taskUsecase{
-		taskRepo: taskRepo,
-		log:      log,
+		taskRepo:     taskRepo,
+		revisionRepo: revisionRepo,
+		projectRepo:  projectRepo,
+		commentRepo:  commentRepo,
+		hierarchy:    rules.Hierarchy,
+		dependencies: rules.Dependencies,
+		workflow:     rules.Workflow,
+		log:          log,
+		linksMu:      &sync.Mutex{},
 	}
 }
 
-// CreateTask создает новую задачу и возвращает ее ID
+// CreateTask создает новую задачу и возвращает ее ID. Пустое время создания
+// заполняется текущим, импорт передает время создания из выгрузки
 func (u *taskUsecase) CreateTask(ctx context.Context, task *model.Task) (int, error) {
 	const fn = "taskUsecase.CreateTask"
 	log := u.log.With(logger.String("fn", fn))
 
-	if len(task.Title) == 0 {
-		return 0, ErrEmptyTitle
+	if err := validateTask(task); err != nil {
+		return 0, err
+	}
+
+	if task.CreatedAt.IsZero() {
+		task.CreatedAt = time.Now().UTC()
+	}
+
+	// новая задача может начинать с любого статуса, без статуса он выводится из done
+	setStatus(task, task.CurrentStatus())
+
+	if task.ParentID != 0 || len(task.DependsOn) > 0 || task.ProjectID != 0 {
+		u.linksMu.Lock()
+		defer u.linksMu.Unlock()
+	}
+
+	if task.ParentID != 0 {
+		if err := u.checkParent(ctx, task); err != nil {
+			log.Error("failed to check parent task", logger.Error(err))
+
+			return 0, err
+		}
+	}
+
+	if err := u.checkProject(ctx, task); err != nil {
+		log.Error("failed to check task project", logger.Error(err))
+
+		return 0, err
+	}
+
+	if err := u.checkDependencies(ctx, task); err != nil {
+		log.Error("failed to check task dependencies", logger.Error(err))
+
+		return 0, err
 	}
 
 	id, err := u.taskRepo.CreateTask(ctx, task)
@@ -45,46 +112,187 @@ func (u *taskUsecase) CreateTask(ctx context.Context, task *model.Task) (int, er
 
 	log.Info("created task in repo", logger.Int("task id", id))
 
+	u.recordRevision(ctx, task, model.RevisionCreated)
+
+	if task.Done {
+		if err := u.completeParents(ctx, task.ParentID); err != nil {
+			log.Error("failed to complete parent tasks", logger.Error(err))
+		}
+	}
+
 	return id, nil
 }
 
-// GetAllTasks возвращает все задачи
-func (u *taskUsecase) GetAllTasks(ctx context.Context) ([]*model.Task, error) {
+// GetAllTasks возвращает задачи, подходящие под filter, в заданном им порядке.
+// Выборки по меткам и по сроку выполнения используют индексы хранилища
+func (u *taskUsecase) GetAllTasks(ctx context.Context, filter model.TaskFilter) ([]*model.Task, error) {
 	const fn = "taskUsecase.GetAllTasks"
 	log := u.log.With(logger.String("fn", fn))
 
-	tasks, err := u.taskRepo.GetAllTasks(ctx)
+	var (
+		tasks []*model.Task
+		err   error
+	)
+
+	switch {
+	case filter.HasTags():
+		tasks, err = u.getTasksByTags(ctx, filter)
+	case filter.HasDue():
+		tasks, err = u.getTasksByDue(ctx, filter)
+	default:
+		tasks, err = u.taskRepo.GetAllTasks(ctx)
+	}
+
 	if err != nil {
 		log.Error("failed to get all tasks from repo", logger.Error(err))
 
 		return nil, err
 	}
 
-	sort.Slice(tasks, func(i, j int) bool {
-		return tasks[i].ID < tasks[j].ID
-	})
+	if filter.ProjectID != 0 {
+		tasks = filterByProject(tasks, filter.ProjectID)
+	}
+
+	sortTasks(tasks, filter.SortBy, filter.Desc)
+
+	if err := u.markBlocked(ctx, tasks); err != nil {
+		log.Error("failed to check task dependencies", logger.Error(err))
+
+		return nil, err
+	}
 
 	log.Info("got all tasks from repo", logger.Int("tasks count", len(tasks)))
 
 	return tasks, nil
 }
 
-// GetTaskByID возвращает задачу по ID
-func (u *taskUsecase) GetTaskByID(ctx context.Context, id int) (*model.Task, error) {
-	const fn = "taskUsecase.GetTaskByID"
-	log := u.log.With(logger.String("fn", fn))
+// getTasksByDue выбирает задачи по сроку выполнения. Просроченная задача
+// не выполнена, а ее срок уже наступил
+func (u *taskUsecase) getTasksByDue(ctx context.Context, filter model.TaskFilter) ([]*model.Task, error) {
+	to := filter.DueBefore
+	if filter.Overdue {
+		now := time.Now().UTC()
+		if to.IsZero() || now.Before(to) {
+			to = now
+		}
+	}
+
+	// пустой диапазон не требует запроса к хранилищу
+	if !filter.DueAfter.IsZero() && !to.IsZero() && !filter.DueAfter.Before(to) {
+		return []*model.Task{}, nil
+	}
 
-	exist, err := u.taskRepo.IsTaskExistByID(ctx, id)
+	tasks, err := u.taskRepo.GetTasksByDue(ctx, filter.DueAfter, to)
 	if err != nil {
-		log.Error("failed to check if task exist in repo", logger.Error(err))
+		return nil, err
+	}
 
+	if !filter.Overdue {
+		return tasks, nil
+	}
+
+	overdue := make([]*model.Task, 0, len(tasks))
+	for _, task := range tasks {
+		if !task.Done {
+			overdue = append(overdue, task)
+		}
+	}
+
+	return overdue, nil
+}
+
+// getTasksByTags выбирает задачи по индексу меток, а условия на срок
+// выполнения проверяет уже у выбранных задач
+func (u *taskUsecase) getTasksByTags(ctx context.Context, filter model.TaskFilter) ([]*model.Task, error) {
+	tags, err := normalizeTags(filter.Tags)
+	if err != nil {
 		return nil, err
 	}
 
-	if !exist {
-		return nil, ErrTaskNotFound
+	tasks, err := u.taskRepo.GetTasksByTags(ctx, tags, filter.AllTags)
+	if err != nil {
+		return nil, err
+	}
+
+	if !filter.HasDue() {
+		return tasks, nil
+	}
+
+	now := time.Now().UTC()
+	matched := make([]*model.Task, 0, len(tasks))
+
+	for _, task := range tasks {
+		if task.DueAt == nil {
+			continue
+		}
+
+		if !filter.DueAfter.IsZero() && task.DueAt.Before(filter.DueAfter) {
+			continue
+		}
+
+		if !filter.DueBefore.IsZero() && !task.DueAt.Before(filter.DueBefore) {
+			continue
+		}
+
+		if filter.Overdue && (task.Done || !task.DueAt.Before(now)) {
+			continue
+		}
+
+		matched = append(matched, task)
 	}
 
+	return matched, nil
+}
+
+// sortTasks упорядочивает задачи по полю by. Задачи с равным значением поля
+// остаются упорядоченными по возрастанию ID в любом направлении
+func sortTasks(tasks []*model.Task, by model.SortField, desc bool) {
+	sort.Slice(tasks, func(i, j int) bool {
+		a, b := tasks[i], tasks[j]
+
+		// задачи без срока в конце независимо от направления
+		if by == model.SortByDue && (a.DueAt == nil) != (b.DueAt == nil) {
+			return b.DueAt == nil
+		}
+
+		c := compareTasks(a, b, by)
+		if c == 0 {
+			return a.ID < b.ID
+		}
+
+		if desc {
+			return c > 0
+		}
+
+		return c < 0
+	})
+}
+
+// compareTasks сравнивает задачи по полю by
+func compareTasks(a, b *model.Task, by model.SortField) int {
+	switch by {
+	case model.SortByCreated:
+		return a.CreatedAt.Compare(b.CreatedAt)
+	case model.SortByPriority:
+		return cmp.Compare(a.Priority.Rank(), b.Priority.Rank())
+	case model.SortByDue:
+		if a.DueAt == nil || b.DueAt == nil {
+			return 0
+		}
+
+		return a.DueAt.Compare(*b.DueAt)
+	case model.SortByTitle:
+		return strings.Compare(strings.ToLower(a.Title), strings.ToLower(b.Title))
+	default:
+		return cmp.Compare(a.ID, b.ID)
+	}
+}
+
+// GetTaskByID возвращает задачу по ID
+func (u *taskUsecase) GetTaskByID(ctx context.Context, id int) (*model.Task, error) {
+	const fn = "taskUsecase.GetTaskByID"
+	log := u.log.With(logger.String("fn", fn))
+
 	task, err := u.taskRepo.GetTaskByID(ctx, id)
 	if err != nil {
 		log.Error("failed to get task from repo", logger.Error(err))
@@ -92,32 +300,73 @@ func (u *taskUsecase) GetTaskByID(ctx context.Context, id int) (*model.Task, err
 		return nil, err
 	}
 
+	tasks := []*model.Task{task}
+	if err := u.markBlocked(ctx, tasks); err != nil {
+		log.Error("failed to check task dependencies", logger.Error(err))
+
+		return nil, err
+	}
+
 	log.Info("got task from repo", logger.Int("task id", id))
 
-	return task, nil
+	return tasks[0], nil
 }
 
-// UpdateTask обновляет задачу
+// UpdateTask обновляет задачу. Если task.Version не 0, задача обновляется только
+// в этой версии, после обновления task.Version содержит новую версию.
+// Пустой task.Status выводится из task.Done и текущего статуса задачи
 func (u *taskUsecase) UpdateTask(ctx context.Context, task *model.Task) error {
 	const fn = "taskUsecase.UpdateTask"
 	log := u.log.With(logger.String("fn", fn))
 
-	exist, err := u.taskRepo.IsTaskExistByID(ctx, task.ID)
-	if err != nil {
-		log.Error("failed to check if task exist in repo", logger.Error(err))
+	if err := validateTask(task); err != nil {
+		return err
+	}
+
+	if task.ParentID != 0 || len(task.DependsOn) > 0 || task.ProjectID != 0 {
+		u.linksMu.Lock()
+		defer u.linksMu.Unlock()
+	}
+
+	if err := u.checkTransition(ctx, task); err != nil {
+		log.Error("failed to check task status transition", logger.Error(err))
+
+		return err
+	}
+
+	if task.ParentID != 0 {
+		if err := u.checkParent(ctx, task); err != nil {
+			log.Error("failed to check parent task", logger.Error(err))
+
+			return err
+		}
+	}
+
+	if err := u.checkProject(ctx, task); err != nil {
+		log.Error("failed to check task project", logger.Error(err))
 
 		return err
 	}
 
-	if !exist {
-		return ErrTaskNotFound
+	if err := u.checkDependencies(ctx, task); err != nil {
+		log.Error("failed to check task dependencies", logger.Error(err))
+
+		return err
 	}
 
-	if len(task.Title) == 0 {
-		return ErrEmptyTitle
+	var next *model.Task
+	if task.Done && task.Recurrence != "" {
+		var err error
+
+		next, err = u.rollOver(ctx, task)
+		if err != nil {
+			log.Error("failed to get next occurrence", logger.Error(err))
+
+			return err
+		}
 	}
 
-	err = u.taskRepo.UpdateTask(ctx, task)
+	err := u.taskRepo.UpdateTask(ctx, task)
 	if err != nil {
 		log.Error("failed to update task in repo", logger.Error(err))
 
@@ -126,26 +375,43 @@ func (u *taskUsecase) UpdateTask(ctx context.Context, task *model.Task) error {
 
 	log.Info("updated task in repo", logger.Int("task id", task.ID))
 
+	u.recordRevision(ctx, task, model.RevisionUpdated)
+
+	if next != nil {
+		u.createOccurrence(ctx, task.ID, next)
+	}
+
+	if task.Done {
+		if err := u.completeParents(ctx, task.ParentID); err != nil {
+			log.Error("failed to complete parent tasks", logger.Error(err))
+		}
+	}
+
 	return nil
 }
 
-// DeleteTask удаляет задачу
-func (u *taskUsecase) DeleteTask(ctx context.Context, id int) error {
+// DeleteTask удаляет задачу. Если version не 0, задача удаляется только в этой версии.
+// С подзадачами поступает по правилу Hierarchy.OnDelete. Комментарии остаются у задачи
+// в корзине и удаляются вместе с ней при очистке корзины
+func (u *taskUsecase) DeleteTask(ctx context.Context, id int, version int) error {
 	const fn = "taskUsecase.DeleteTask"
 	log := u.log.With(logger.String("fn", fn))
 
-	exist, err := u.taskRepo.IsTaskExistByID(ctx, id)
+	u.linksMu.Lock()
+	defer u.linksMu.Unlock()
+
+	children, err := u.getChildren(ctx, id)
 	if err != nil {
-		log.Error("failed to check if task exist in repo", logger.Error(err))
+		log.Error("failed to get children from repo", logger.Error(err))
 
 		return err
 	}
 
-	if !exist {
-		return ErrTaskNotFound
+	if len(children) > 0 && u.hierarchy.OnDelete == model.DeleteBlock {
+		return ErrTaskHasChildren
 	}
 
-	err = u.taskRepo.DeleteTask(ctx, id)
+	task, err := u.taskRepo.DeleteTask(ctx, id, version)
 	if err != nil {
 		log.Error("failed to delete task in repo", logger.Error(err))
 
@@ -154,5 +420,217 @@ func (u *taskUsecase) DeleteTask(ctx context.Context, id int) error {
 
 	log.Info("deleted task in repo", logger.Int("task id", id))
 
+	u.recordRevision(ctx, task, model.RevisionDeleted)
+
+	if err := u.deleteChildren(ctx, id, children); err != nil {
+		log.Error("failed to apply delete rule to children", logger.Int("task id", id), logger.Error(err))
+
+		return err
+	}
+
+	if len(children) > 0 {
+		log.Info("applied delete rule to children",
+			logger.Int("task id", id),
+			logger.String("rule", string(u.hierarchy.OnDelete)),
+			logger.Int("tasks count", len(children)),
+		)
+	}
+
+	// удаленная задача могла быть единственной невыполненной подзадачей
+	if !task.Done {
+		if err := u.completeParents(ctx, task.ParentID); err != nil {
+			log.Error("failed to complete parent tasks", logger.Error(err))
+		}
+	}
+
+	return nil
+}
+
+// GetDeletedTasks возвращает задачи из корзины
+func (u *taskUsecase) GetDeletedTasks(ctx context.Context) ([]*model.Task, error) {
+	const fn = "taskUsecase.GetDeletedTasks"
+	log := u.log.With(logger.String("fn", fn))
+
+	tasks, err := u.taskRepo.GetDeletedTasks(ctx)
+	if err != nil {
+		log.Error("failed to get deleted tasks from repo", logger.Error(err))
+
+		return nil, err
+	}
+
+	sort.Slice(tasks, func(i, j int) bool {
+		return tasks[i].ID < tasks[j].ID
+	})
+
+	log.Info("got deleted tasks from repo", logger.Int("tasks count", len(tasks)))
+
+	return tasks, nil
+}
+
+// RestoreTask возвращает задачу из корзины. Если ее родителя или проекта уже нет,
+// задача восстанавливается на верхний уровень или вне проектов
+func (u *taskUsecase) RestoreTask(ctx context.Context, id int) (*model.Task, error) {
+	const fn = "taskUsecase.RestoreTask"
+	log := u.log.With(logger.String("fn", fn))
+
+	u.linksMu.Lock()
+	defer u.linksMu.Unlock()
+
+	task, err := u.taskRepo.RestoreTask(ctx, id)
+	if err != nil {
+		log.Error("failed to restore task in repo", logger.Error(err))
+
+		return nil, err
+	}
+
+	log.Info("restored task in repo", logger.Int("task id", id))
+
+	u.recordRevision(ctx, task, model.RevisionRestored)
+
+	detached := false
+
+	// проект задачи мог быть удален, пока она лежала в корзине,
+	// тогда задача восстанавливается вне проектов
+	if task.ProjectID != 0 {
+		_, err := u.projectRepo.GetProjectByID(ctx, task.ProjectID)
+		if errors.Is(err, ErrProjectNotFound) {
+			if err := u.setProject(ctx, id, task.ProjectID, 0); err != nil {
+				log.Error("failed to detach restored task from project", logger.Int("task id", id), logger.Error(err))
+			} else {
+				detached = true
+			}
+		}
+	}
+
+	// родитель мог остаться в корзине или быть удален окончательно,
+	// тогда задача восстанавливается на верхний уровень
+	if task.ParentID != 0 {
+		_, err := u.taskRepo.GetTaskByID(ctx, task.ParentID)
+		if errors.Is(err, ErrTaskNotFound) {
+			if err := u.setParent(ctx, id, task.ParentID, 0); err != nil {
+				log.Error("failed to detach restored task", logger.Int("task id", id), logger.Error(err))
+			} else {
+				detached = true
+			}
+		}
+	}
+
+	if !detached {
+		return task, nil
+	}
+
+	restored, err := u.taskRepo.GetTaskByID(ctx, id)
+	if err != nil {
+		log.Error("failed to get restored task from repo", logger.Error(err))
+
+		return task, nil
+	}
+
+	return restored, nil
+}
+
+// PurgeDeletedTasks окончательно удаляет задачи, которые лежат в корзине дольше retention,
+// вместе с их комментариями и возвращает количество удаленных задач
+func (u *taskUsecase) PurgeDeletedTasks(ctx context.Context, retention time.Duration) (int, error) {
+	const fn = "taskUsecase.PurgeDeletedTasks"
+	log := u.log.With(logger.String("fn", fn))
+
+	deletedBefore := time.Now().UTC().Add(-retention)
+
+	// хранилище не сообщает, какие задачи удалены, поэтому кандидаты запоминаются заранее
+	trashed, err := u.taskRepo.GetDeletedTasks(ctx)
+	if err != nil {
+		log.Error("failed to get deleted tasks from repo", logger.Error(err))
+
+		return 0, err
+	}
+
+	candidates := make([]*model.Task, 0, len(trashed))
+	for _, task := range trashed {
+		if task.DeletedAt != nil && task.DeletedAt.Before(deletedBefore) {
+			candidates = append(candidates, task)
+		}
+	}
+
+	purged, err := u.taskRepo.PurgeDeletedTasks(ctx, deletedBefore)
+	if err != nil {
+		log.Error("failed to purge deleted tasks in repo", logger.Error(err))
+
+		return 0, err
+	}
+
+	log.Info("purged deleted tasks in repo", logger.Int("tasks count", purged))
+
+	if purged == 0 || len(candidates) == 0 {
+		return purged, nil
+	}
+
+	// задачи уже удалены, поэтому ошибка удаления комментариев только записывается в лог
+	comments, err := u.purgeComments(ctx, candidates)
+	if err != nil {
+		log.Error("failed to purge comments of deleted tasks", logger.Error(err))
+
+		return purged, nil
+	}
+
+	log.Info("purged comments of deleted tasks", logger.Int("comments count", comments))
+
+	return purged, nil
+}
+
+// validateTask проверяет поля задачи перед записью в хранилище
+func validateTask(task *model.Task) error {
+	if len(task.Title) == 0 {
+		return ErrEmptyTitle
+	}
+
+	// срок должен записываться в RFC 3339 во всех хранилищах
+	if task.DueAt != nil && (task.DueAt.Year() < 1 || task.DueAt.Year() > 9999) {
+		return ErrInvalidDueDate
+	}
+
+	if task.Priority == "" {
+		task.Priority = model.PriorityNone
+	}
+
+	if task.Priority.Rank() < 0 {
+		return ErrInvalidPriority
+	}
+
+	if task.Status != "" && !task.Status.Valid() {
+		return ErrInvalidStatus
+	}
+
+	tags, err := normalizeTags(task.Tags)
+	if err != nil {
+		return err
+	}
+
+	task.Tags = tags
+
+	dependsOn, err := normalizeDependsOn(task.ID, task.DependsOn)
+	if err != nil {
+		return err
+	}
+
+	task.DependsOn = dependsOn
+
+	if task.Recurrence != "" {
+		rule, err := model.ParseRecurrenceRule(task.Recurrence)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidRecurrence, err)
+		}
+
+		// повторения отсчитываются от срока задачи
+		if task.DueAt == nil {
+			return ErrRecurrenceWithoutDue
+		}
+
+		task.Recurrence = rule.String()
+	}
+
+	// признак вычисляется при чтении и в хранилище не записывается
+	task.Blocked = false
+
 	return nil
 }
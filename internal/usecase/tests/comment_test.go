@@ -0,0 +1,394 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/solumD/tasks-service/internal/model"
+	"github.com/solumD/tasks-service/internal/usecase"
+	"github.com/solumD/tasks-service/internal/usecase/mock"
+	"github.com/solumD/tasks-service/pkg/logger"
+)
+
+// commentTaskRepo возвращает мок хранилища задач, в котором есть только задачи ids
+func commentTaskRepo(ids ...int) *mock.MockTaskRepo {
+	return &mock.MockTaskRepo{
+		GetTaskByIDFunc: func(ctx context.Context, id int) (*model.Task, error) {
+			for _, taskID := range ids {
+				if taskID == id {
+					return &model.Task{ID: id}, nil
+				}
+			}
+
+			return nil, usecase.NewTaskNotFoundError(id)
+		},
+	}
+}
+
+// commentRepo возвращает мок хранилища комментариев поверх comments
+func commentRepo(comments map[int]*model.Comment) *mock.MockCommentRepo {
+	return &mock.MockCommentRepo{
+		CreateCommentFunc: func(ctx context.Context, comment *model.Comment) (int, error) {
+			comment.ID = len(comments) + 1
+			comments[comment.ID] = comment.Clone()
+
+			return comment.ID, nil
+		},
+		GetCommentByIDFunc: func(ctx context.Context, id int) (*model.Comment, error) {
+			comment, ok := comments[id]
+			if !ok {
+				return nil, usecase.NewCommentNotFoundError(id)
+			}
+
+			return comment.Clone(), nil
+		},
+		UpdateCommentFunc: func(ctx context.Context, comment *model.Comment) error {
+			comments[comment.ID] = comment.Clone()
+
+			return nil
+		},
+		DeleteCommentFunc: func(ctx context.Context, id int) (int, error) {
+			delete(comments, id)
+
+			return 1, nil
+		},
+	}
+}
+
+// newComments возвращает комментарии задачи 1: 1 от alice, 2 - ответ bob на 1, и комментарий 3 задачи 2
+func newComments() map[int]*model.Comment {
+	return map[int]*model.Comment{
+		1: {ID: 1, TaskID: 1, Author: "alice", Text: "first"},
+		2: {ID: 2, TaskID: 1, ParentID: 1, Author: "bob", Text: "reply"},
+		3: {ID: 3, TaskID: 2, Author: "alice", Text: "other"},
+	}
+}
+
+func TestAddComment(t *testing.T) {
+	tests := []struct {
+		name        string
+		author      string
+		comment     *model.Comment
+		expectedErr error
+	}{
+		{
+			name:        "empty text",
+			author:      "alice",
+			comment:     &model.Comment{TaskID: 1, Text: "  "},
+			expectedErr: usecase.ErrEmptyCommentText,
+		},
+		{
+			name:        "too long text",
+			author:      "alice",
+			comment:     &model.Comment{TaskID: 1, Text: strings.Repeat("я", 10001)},
+			expectedErr: usecase.ErrCommentTooLong,
+		},
+		{
+			name:        "no author",
+			comment:     &model.Comment{TaskID: 1, Text: "hello"},
+			expectedErr: usecase.ErrEmptyCommentAuthor,
+		},
+		{
+			name:        "task not found",
+			author:      "alice",
+			comment:     &model.Comment{TaskID: 42, Text: "hello"},
+			expectedErr: usecase.ErrTaskNotFound,
+		},
+		{
+			name:        "parent not found",
+			author:      "alice",
+			comment:     &model.Comment{TaskID: 1, ParentID: 42, Text: "hello"},
+			expectedErr: usecase.ErrCommentParentNotFound,
+		},
+		{
+			name:        "parent of another task",
+			author:      "alice",
+			comment:     &model.Comment{TaskID: 1, ParentID: 3, Text: "hello"},
+			expectedErr: usecase.ErrCommentParentNotFound,
+		},
+		{
+			name:        "reply to reply",
+			author:      "alice",
+			comment:     &model.Comment{TaskID: 1, ParentID: 2, Text: "hello"},
+			expectedErr: usecase.ErrNestedReply,
+		},
+		{
+			name:    "comment",
+			author:  "alice",
+			comment: &model.Comment{TaskID: 1, Text: "hello"},
+		},
+		{
+			name:    "reply",
+			author:  "bob",
+			comment: &model.Comment{TaskID: 1, ParentID: 1, Text: "hello"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			comments := newComments()
+			repo := commentRepo(comments)
+			u := usecase.NewCommentUsecase(repo, commentTaskRepo(1, 2), logger.NewMockLogger())
+
+			ctx := usecase.ContextWithAuthor(context.Background(), tt.author)
+
+			id, err := u.AddComment(ctx, tt.comment)
+			if !errors.Is(err, tt.expectedErr) {
+				t.Fatalf("expected error %v, got %v", tt.expectedErr, err)
+			}
+
+			if err != nil {
+				if repo.CreateCommentCalled {
+					t.Fatal("expected CreateComment not to be called")
+				}
+
+				return
+			}
+
+			stored := comments[id]
+			if stored.Author != tt.author || stored.CreatedAt.IsZero() || stored.EditedAt != nil {
+				t.Fatalf("unexpected stored comment: %+v", stored)
+			}
+		})
+	}
+}
+
+func TestGetComments(t *testing.T) {
+	repo := &mock.MockCommentRepo{
+		GetCommentsFunc: func(ctx context.Context, taskID int, after int, limit int) ([]*model.Comment, error) {
+			// в задаче комментарии 1, 3 и 4, ответы 2 и 5 - на 1, 6 - на 3
+			all := []*model.Comment{{ID: 1}, {ID: 3}, {ID: 4}}
+			page := make([]*model.Comment, 0)
+
+			for _, comment := range all {
+				if comment.ID > after && len(page) < limit {
+					page = append(page, comment)
+				}
+			}
+
+			return page, nil
+		},
+		GetRepliesFunc: func(ctx context.Context, parentIDs []int) ([]*model.Comment, error) {
+			return []*model.Comment{{ID: 2, ParentID: 1}, {ID: 5, ParentID: 1}, {ID: 6, ParentID: 3}}, nil
+		},
+	}
+
+	u := usecase.NewCommentUsecase(repo, commentTaskRepo(1), logger.NewMockLogger())
+
+	page, err := u.GetComments(context.Background(), 1, 0, 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(page.Threads) != 2 || page.Next != 3 {
+		t.Fatalf("expected 2 threads with next 3, got %d threads with next %d", len(page.Threads), page.Next)
+	}
+
+	first, second := page.Threads[0], page.Threads[1]
+	if first.Comment.ID != 1 || len(first.Replies) != 2 || first.Replies[0].ID != 2 || first.Replies[1].ID != 5 {
+		t.Fatalf("unexpected first thread: %+v", first)
+	}
+
+	if second.Comment.ID != 3 || len(second.Replies) != 1 || second.Replies[0].ID != 6 {
+		t.Fatalf("unexpected second thread: %+v", second)
+	}
+
+	page, err = u.GetComments(context.Background(), 1, 3, 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(page.Threads) != 1 || page.Threads[0].Comment.ID != 4 || page.Next != 0 {
+		t.Fatalf("expected last page with comment 4, got %+v", page)
+	}
+
+	if _, err := u.GetComments(context.Background(), 1, 0, 101); !errors.Is(err, usecase.ErrInvalidCommentsLimit) {
+		t.Fatalf("expected ErrInvalidCommentsLimit, got %v", err)
+	}
+
+	if _, err := u.GetComments(context.Background(), 42, 0, 0); !errors.Is(err, usecase.ErrTaskNotFound) {
+		t.Fatalf("expected ErrTaskNotFound, got %v", err)
+	}
+}
+
+func TestUpdateComment(t *testing.T) {
+	tests := []struct {
+		name        string
+		author      string
+		comment     *model.Comment
+		expectedErr error
+	}{
+		{
+			name:        "empty text",
+			author:      "alice",
+			comment:     &model.Comment{ID: 1, TaskID: 1},
+			expectedErr: usecase.ErrEmptyCommentText,
+		},
+		{
+			name:        "no author",
+			comment:     &model.Comment{ID: 1, TaskID: 1, Text: "edited"},
+			expectedErr: usecase.ErrEmptyCommentAuthor,
+		},
+		{
+			name:        "comment of another task",
+			author:      "alice",
+			comment:     &model.Comment{ID: 3, TaskID: 1, Text: "edited"},
+			expectedErr: usecase.ErrCommentNotFound,
+		},
+		{
+			name:        "comment of another author",
+			author:      "bob",
+			comment:     &model.Comment{ID: 1, TaskID: 1, Text: "edited"},
+			expectedErr: usecase.ErrCommentForbidden,
+		},
+		{
+			name:    "success",
+			author:  "alice",
+			comment: &model.Comment{ID: 1, TaskID: 1, Text: "edited"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			comments := newComments()
+			repo := commentRepo(comments)
+			u := usecase.NewCommentUsecase(repo, commentTaskRepo(1, 2), logger.NewMockLogger())
+
+			ctx := usecase.ContextWithAuthor(context.Background(), tt.author)
+
+			updated, err := u.UpdateComment(ctx, tt.comment)
+			if !errors.Is(err, tt.expectedErr) {
+				t.Fatalf("expected error %v, got %v", tt.expectedErr, err)
+			}
+
+			if err != nil {
+				if repo.UpdateCommentCalled {
+					t.Fatal("expected UpdateComment not to be called")
+				}
+
+				return
+			}
+
+			if updated.Text != "edited" || updated.Author != "alice" || updated.EditedAt == nil {
+				t.Fatalf("unexpected updated comment: %+v", updated)
+			}
+
+			if comments[1].Text != "edited" {
+				t.Fatalf("expected stored text to change, got %q", comments[1].Text)
+			}
+		})
+	}
+}
+
+func TestDeleteComment(t *testing.T) {
+	tests := []struct {
+		name        string
+		author      string
+		taskID      int
+		id          int
+		expectedErr error
+	}{
+		{
+			name:        "task not found",
+			author:      "alice",
+			taskID:      42,
+			id:          1,
+			expectedErr: usecase.ErrTaskNotFound,
+		},
+		{
+			name:        "comment not found",
+			author:      "alice",
+			taskID:      1,
+			id:          42,
+			expectedErr: usecase.ErrCommentNotFound,
+		},
+		{
+			name:        "comment of another author",
+			author:      "bob",
+			taskID:      1,
+			id:          1,
+			expectedErr: usecase.ErrCommentForbidden,
+		},
+		{
+			name:   "own reply",
+			author: "bob",
+			taskID: 1,
+			id:     2,
+		},
+		{
+			name:   "own comment with reply of another author",
+			author: "alice",
+			taskID: 1,
+			id:     1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := commentRepo(newComments())
+			u := usecase.NewCommentUsecase(repo, commentTaskRepo(1, 2), logger.NewMockLogger())
+
+			ctx := usecase.ContextWithAuthor(context.Background(), tt.author)
+
+			err := u.DeleteComment(ctx, tt.taskID, tt.id)
+			if !errors.Is(err, tt.expectedErr) {
+				t.Fatalf("expected error %v, got %v", tt.expectedErr, err)
+			}
+
+			if repo.DeleteCommentCalled != (err == nil) {
+				t.Fatalf("expected DeleteComment called %v, got %v", err == nil, repo.DeleteCommentCalled)
+			}
+		})
+	}
+}
+
+func TestPurgeDeletedTasksComments(t *testing.T) {
+	old := time.Now().UTC().Add(-48 * time.Hour)
+	recent := time.Now().UTC()
+
+	// 1 и 2 лежат в корзине дольше срока, но 2 восстановлена во время очистки; 3 удалена недавно
+	trash := []*model.Task{{ID: 1, DeletedAt: &old}, {ID: 2, DeletedAt: &old}, {ID: 3, DeletedAt: &recent}}
+
+	repo := commentTaskRepo(2)
+	repo.GetDeletedTasksFunc = func(ctx context.Context) ([]*model.Task, error) {
+		return trash, nil
+	}
+	repo.PurgeDeletedTasksFunc = func(ctx context.Context, deletedBefore time.Time) (int, error) {
+		trash = trash[2:]
+		return 1, nil
+	}
+
+	comments := &mock.MockCommentRepo{}
+	u := usecase.NewTaskUsecase(repo, &mock.MockRevisionRepo{}, &mock.MockProjectRepo{}, comments, usecase.Rules{}, logger.NewMockLogger())
+
+	purged, err := u.PurgeDeletedTasks(context.Background(), 24*time.Hour)
+	if err != nil || purged != 1 {
+		t.Fatalf("expected 1 purged task, got %d (err %v)", purged, err)
+	}
+
+	if len(comments.DeleteTaskCommentsTaskIDs) != 1 || comments.DeleteTaskCommentsTaskIDs[0] != 1 {
+		t.Fatalf("expected comments of task 1 deleted, got %v", comments.DeleteTaskCommentsTaskIDs)
+	}
+}
+
+func TestDeleteTaskKeepsComments(t *testing.T) {
+	repo := commentTaskRepo(1)
+	repo.DeleteTaskFunc = func(ctx context.Context, id int, version int) (*model.Task, error) {
+		return &model.Task{ID: id}, nil
+	}
+
+	comments := &mock.MockCommentRepo{}
+	u := usecase.NewTaskUsecase(repo, &mock.MockRevisionRepo{}, &mock.MockProjectRepo{}, comments, usecase.Rules{}, logger.NewMockLogger())
+
+	if err := u.DeleteTask(context.Background(), 1, 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// задача из корзины может быть восстановлена вместе с комментариями
+	if comments.DeleteTaskCommentsCalled || comments.DeleteCommentCalled {
+		t.Fatal("expected comments to stay while task is in trash")
+	}
+}
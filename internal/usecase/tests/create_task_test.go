@@ -57,7 +57,7 @@ func TestCreateTask(t *testing.T) {
 			}
 
 			log := logger.NewMockLogger()
-			u := usecase.NewTaskUsecase(repo, log)
+			u := usecase.NewTaskUsecase(repo, &mock.MockRevisionRepo{}, &mock.MockProjectRepo{}, &mock.MockCommentRepo{}, usecase.Rules{}, log)
 
 			id, err := u.CreateTask(context.Background(), tt.task)
 
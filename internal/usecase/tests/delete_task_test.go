@@ -5,6 +5,7 @@ import (
 	"errors"
 	"testing"
 
+	"github.com/solumD/tasks-service/internal/model"
 	"github.com/solumD/tasks-service/internal/usecase"
 	"github.com/solumD/tasks-service/internal/usecase/mock"
 	"github.com/solumD/tasks-service/pkg/logger"
@@ -14,58 +15,46 @@ func TestDeleteTask(t *testing.T) {
 	tests := []struct {
 		name                 string
 		id                   int
-		existFunc            func(ctx context.Context, id int) (bool, error)
-		deleteFunc           func(ctx context.Context, id int) error
+		version              int
+		deleteFunc           func(ctx context.Context, id int, version int) (*model.Task, error)
 		expectedErr          error
-		expectedExistCalled  bool
 		expectedDeleteCalled bool
 	}{
 		{
 			name: "task not exist",
 			id:   1,
-			existFunc: func(ctx context.Context, id int) (bool, error) {
-				return false, nil
+			deleteFunc: func(ctx context.Context, id int, version int) (*model.Task, error) {
+				return nil, usecase.NewTaskNotFoundError(id)
 			},
-			deleteFunc:           nil,
 			expectedErr:          usecase.ErrTaskNotFound,
-			expectedExistCalled:  true,
-			expectedDeleteCalled: false,
+			expectedDeleteCalled: true,
 		},
 		{
-			name: "repo error on exist check",
-			id:   1,
-			existFunc: func(ctx context.Context, id int) (bool, error) {
-				return false, errors.New("db exist error")
+			name:    "version conflict",
+			id:      1,
+			version: 2,
+			deleteFunc: func(ctx context.Context, id int, version int) (*model.Task, error) {
+				return nil, usecase.ErrVersionConflict
 			},
-			deleteFunc:           nil,
-			expectedErr:          errors.New("db exist error"),
-			expectedExistCalled:  true,
-			expectedDeleteCalled: false,
+			expectedErr:          usecase.ErrVersionConflict,
+			expectedDeleteCalled: true,
 		},
 		{
 			name: "repo error on delete",
 			id:   1,
-			existFunc: func(ctx context.Context, id int) (bool, error) {
-				return true, nil
-			},
-			deleteFunc: func(ctx context.Context, id int) error {
-				return errors.New("db delete error")
+			deleteFunc: func(ctx context.Context, id int, version int) (*model.Task, error) {
+				return nil, errors.New("db delete error")
 			},
 			expectedErr:          errors.New("db delete error"),
-			expectedExistCalled:  true,
 			expectedDeleteCalled: true,
 		},
 		{
 			name: "success",
 			id:   1,
-			existFunc: func(ctx context.Context, id int) (bool, error) {
-				return true, nil
-			},
-			deleteFunc: func(ctx context.Context, id int) error {
-				return nil
+			deleteFunc: func(ctx context.Context, id int, version int) (*model.Task, error) {
+				return &model.Task{ID: id, Version: 2}, nil
 			},
 			expectedErr:          nil,
-			expectedExistCalled:  true,
 			expectedDeleteCalled: true,
 		},
 	}
@@ -73,27 +62,27 @@ func TestDeleteTask(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			repo := &mock.MockTaskRepo{
-				IsTaskExistByIDFunc: tt.existFunc,
-				DeleteTaskFunc:      tt.deleteFunc,
+				DeleteTaskFunc: tt.deleteFunc,
 			}
 
 			log := logger.NewMockLogger()
-			u := usecase.NewTaskUsecase(repo, log)
+			u := usecase.NewTaskUsecase(repo, &mock.MockRevisionRepo{}, &mock.MockProjectRepo{}, &mock.MockCommentRepo{}, usecase.Rules{}, log)
 
-			err := u.DeleteTask(context.Background(), tt.id)
+			err := u.DeleteTask(context.Background(), tt.id, tt.version)
 
 			if (err != nil && tt.expectedErr == nil) || (err == nil && tt.expectedErr != nil) ||
-				(err != nil && tt.expectedErr != nil && err.Error() != tt.expectedErr.Error()) {
+				(err != nil && tt.expectedErr != nil && !errors.Is(err, tt.expectedErr) && err.Error() != tt.expectedErr.Error()) {
 				t.Fatalf("expected error %v, got %v", tt.expectedErr, err)
 			}
 
-			if repo.IsTaskExistByIDCalled != tt.expectedExistCalled {
-				t.Fatalf("expected IsTaskExistByID called = %v, got %v", tt.expectedExistCalled, repo.IsTaskExistByIDCalled)
-			}
-
 			if repo.DeleteTaskCalled != tt.expectedDeleteCalled {
 				t.Fatalf("expected DeleteTask called = %v, got %v", tt.expectedDeleteCalled, repo.DeleteTaskCalled)
 			}
+
+			if tt.expectedDeleteCalled && (repo.DeleteTaskID != tt.id || repo.DeleteTaskVersion != tt.version) {
+				t.Fatalf("expected DeleteTask(%d, %d), got DeleteTask(%d, %d)",
+					tt.id, tt.version, repo.DeleteTaskID, repo.DeleteTaskVersion)
+			}
 		})
 	}
 }
@@ -0,0 +1,343 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/solumD/tasks-service/internal/model"
+	"github.com/solumD/tasks-service/internal/usecase"
+	"github.com/solumD/tasks-service/internal/usecase/mock"
+	"github.com/solumD/tasks-service/pkg/logger"
+)
+
+// graphRepo возвращает treeRepo, который также отдает все задачи не из корзины
+func graphRepo(tasks map[int]*model.Task) *mock.MockTaskRepo {
+	repo := treeRepo(tasks)
+	repo.GetAllTasksFunc = func(ctx context.Context) ([]*model.Task, error) {
+		all := make([]*model.Task, 0, len(tasks))
+		for _, task := range tasks {
+			if task.DeletedAt == nil {
+				all = append(all, task.Clone())
+			}
+		}
+
+		return all, nil
+	}
+
+	return repo
+}
+
+// newGraph возвращает зависимости 3 -> 2 -> 1, выполненную задачу 4
+// и задачу 5 в корзине
+func newGraph() map[int]*model.Task {
+	deletedAt := time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	return map[int]*model.Task{
+		1: {ID: 1, Title: "First", Version: 1},
+		2: {ID: 2, Title: "Second", DependsOn: []int{1}, Version: 1},
+		3: {ID: 3, Title: "Third", DependsOn: []int{2}, Version: 1},
+		4: {ID: 4, Title: "Done", Done: true, Version: 1},
+		5: {ID: 5, Title: "Trashed", DeletedAt: &deletedAt, Version: 1},
+	}
+}
+
+func TestDependencyChecks(t *testing.T) {
+	tests := []struct {
+		name              string
+		task              *model.Task
+		create            bool
+		expectedErr       error
+		expectedDependsOn []int
+	}{
+		{
+			name:              "create with dependencies",
+			task:              &model.Task{Title: "New", DependsOn: []int{3, 1, 3}},
+			create:            true,
+			expectedDependsOn: []int{1, 3},
+		},
+		{
+			name:        "create with missing",
+			task:        &model.Task{Title: "New", DependsOn: []int{42}},
+			create:      true,
+			expectedErr: usecase.ErrDependencyNotFound,
+		},
+		{
+			name:        "create with trashed",
+			task:        &model.Task{Title: "New", DependsOn: []int{5}},
+			create:      true,
+			expectedErr: usecase.ErrDependencyNotFound,
+		},
+		{
+			name:        "non-positive id",
+			task:        &model.Task{Title: "New", DependsOn: []int{0}},
+			create:      true,
+			expectedErr: usecase.ErrInvalidDependency,
+		},
+		{
+			name:              "add independent",
+			task:              &model.Task{ID: 1, Title: "First", DependsOn: []int{4}},
+			expectedDependsOn: []int{4},
+		},
+		{
+			name:        "depends on itself",
+			task:        &model.Task{ID: 2, Title: "Second", DependsOn: []int{2}},
+			expectedErr: usecase.ErrDependencyCycle,
+		},
+		{
+			name:        "depends on dependent",
+			task:        &model.Task{ID: 1, Title: "First", DependsOn: []int{3}},
+			expectedErr: usecase.ErrDependencyCycle,
+		},
+		{
+			name:        "update with missing",
+			task:        &model.Task{ID: 3, Title: "Third", DependsOn: []int{2, 42}},
+			expectedErr: usecase.ErrDependencyNotFound,
+		},
+		{
+			name:              "remove all",
+			task:              &model.Task{ID: 3, Title: "Third"},
+			expectedDependsOn: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tasks := newGraph()
+			repo := graphRepo(tasks)
+
+			u := usecase.NewTaskUsecase(repo, &mock.MockRevisionRepo{}, &mock.MockProjectRepo{}, &mock.MockCommentRepo{}, usecase.Rules{}, logger.NewMockLogger())
+
+			var err error
+			if tt.create {
+				_, err = u.CreateTask(context.Background(), tt.task)
+			} else {
+				err = u.UpdateTask(context.Background(), tt.task)
+			}
+
+			if !errors.Is(err, tt.expectedErr) {
+				t.Fatalf("expected error %v, got %v", tt.expectedErr, err)
+			}
+
+			written := repo.CreateTaskCalled || repo.UpdateTaskCalled
+			if written != (tt.expectedErr == nil) {
+				t.Fatalf("expected repo written = %v, got %v", tt.expectedErr == nil, written)
+			}
+
+			if tt.expectedErr == nil && !slices.Equal(tasks[tt.task.ID].DependsOn, tt.expectedDependsOn) {
+				t.Fatalf("expected dependencies %v, got %v", tt.expectedDependsOn, tasks[tt.task.ID].DependsOn)
+			}
+		})
+	}
+}
+
+func TestBlockDone(t *testing.T) {
+	tests := []struct {
+		name        string
+		blockDone   bool
+		task        *model.Task
+		expectedErr error
+	}{
+		{name: "allowed by default", task: &model.Task{ID: 3, Title: "Third", Done: true, DependsOn: []int{2}}},
+		{
+			name:        "open dependency",
+			blockDone:   true,
+			task:        &model.Task{ID: 3, Title: "Third", Done: true, DependsOn: []int{2}},
+			expectedErr: usecase.ErrTaskBlocked,
+		},
+		{
+			name:        "create blocked",
+			blockDone:   true,
+			task:        &model.Task{Title: "New", Done: true, DependsOn: []int{4, 1}},
+			expectedErr: usecase.ErrTaskBlocked,
+		},
+		{name: "done dependency", blockDone: true, task: &model.Task{ID: 1, Title: "First", Done: true, DependsOn: []int{4}}},
+		{name: "not done", blockDone: true, task: &model.Task{ID: 3, Title: "Renamed", DependsOn: []int{2}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tasks := newGraph()
+
+			u := usecase.NewTaskUsecase(graphRepo(tasks), &mock.MockRevisionRepo{}, &mock.MockProjectRepo{}, &mock.MockCommentRepo{},
+				usecase.Rules{Dependencies: usecase.Dependencies{BlockDone: tt.blockDone}}, logger.NewMockLogger())
+
+			var err error
+			if tt.task.ID == 0 {
+				_, err = u.CreateTask(context.Background(), tt.task)
+			} else {
+				err = u.UpdateTask(context.Background(), tt.task)
+			}
+
+			if !errors.Is(err, tt.expectedErr) {
+				t.Fatalf("expected error %v, got %v", tt.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestBlockDoneIgnoresTrashedDependency(t *testing.T) {
+	tasks := newGraph()
+	tasks[3].DependsOn = []int{5}
+
+	u := usecase.NewTaskUsecase(graphRepo(tasks), &mock.MockRevisionRepo{}, &mock.MockProjectRepo{}, &mock.MockCommentRepo{},
+		usecase.Rules{Dependencies: usecase.Dependencies{BlockDone: true}}, logger.NewMockLogger())
+
+	// зависимость из корзины не блокирует и не проверяется повторно
+	err := u.UpdateTask(context.Background(), &model.Task{ID: 3, Title: "Third", Done: true, DependsOn: []int{5}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestAddRemoveDependency(t *testing.T) {
+	tests := []struct {
+		name              string
+		remove            bool
+		id                int
+		dependsOn         int
+		version           int
+		expectedErr       error
+		expectedDependsOn []int
+		expectedVersion   int
+	}{
+		{name: "add", id: 3, dependsOn: 4, expectedDependsOn: []int{2, 4}, expectedVersion: 2},
+		{name: "add existing", id: 3, dependsOn: 2, expectedDependsOn: []int{2}, expectedVersion: 1},
+		{name: "add with version", id: 3, dependsOn: 4, version: 1, expectedDependsOn: []int{2, 4}, expectedVersion: 2},
+		{name: "add with stale version", id: 3, dependsOn: 4, version: 5, expectedErr: usecase.ErrVersionConflict},
+		{name: "add cycle", id: 1, dependsOn: 3, expectedErr: usecase.ErrDependencyCycle},
+		{name: "add missing", id: 1, dependsOn: 42, expectedErr: usecase.ErrDependencyNotFound},
+		{name: "add to missing", id: 42, dependsOn: 1, expectedErr: usecase.ErrTaskNotFound},
+		{name: "add invalid", id: 1, dependsOn: -1, expectedErr: usecase.ErrInvalidDependency},
+		{name: "remove", remove: true, id: 3, dependsOn: 2, expectedDependsOn: nil, expectedVersion: 2},
+		{name: "remove unknown", remove: true, id: 3, dependsOn: 1, expectedErr: usecase.ErrDependencyNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tasks := newGraph()
+			revisions := &mock.MockRevisionRepo{}
+
+			u := usecase.NewTaskUsecase(graphRepo(tasks), revisions, &mock.MockProjectRepo{}, &mock.MockCommentRepo{}, usecase.Rules{}, logger.NewMockLogger())
+
+			var (
+				task *model.Task
+				err  error
+			)
+
+			if tt.remove {
+				task, err = u.RemoveDependency(context.Background(), tt.id, tt.dependsOn, tt.version)
+			} else {
+				task, err = u.AddDependency(context.Background(), tt.id, tt.dependsOn, tt.version)
+			}
+
+			if !errors.Is(err, tt.expectedErr) {
+				t.Fatalf("expected error %v, got %v", tt.expectedErr, err)
+			}
+
+			if tt.expectedErr != nil {
+				return
+			}
+
+			if !slices.Equal(task.DependsOn, tt.expectedDependsOn) || !slices.Equal(tasks[tt.id].DependsOn, tt.expectedDependsOn) {
+				t.Fatalf("expected dependencies %v, got %v (stored %v)", tt.expectedDependsOn, task.DependsOn, tasks[tt.id].DependsOn)
+			}
+
+			if task.Version != tt.expectedVersion {
+				t.Fatalf("expected version %d, got %d", tt.expectedVersion, task.Version)
+			}
+
+			if revisions.AddRevisionCalled != (tt.expectedVersion > 1) {
+				t.Fatalf("expected revision recorded = %v", tt.expectedVersion > 1)
+			}
+		})
+	}
+}
+
+func TestBlockedFlag(t *testing.T) {
+	tasks := newGraph()
+	tasks[4].DependsOn = []int{5}
+
+	u := usecase.NewTaskUsecase(graphRepo(tasks), &mock.MockRevisionRepo{}, &mock.MockProjectRepo{}, &mock.MockCommentRepo{}, usecase.Rules{}, logger.NewMockLogger())
+
+	all, err := u.GetAllTasks(context.Background(), model.TaskFilter{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	blocked := make([]int, 0)
+	for _, task := range all {
+		if task.Blocked {
+			blocked = append(blocked, task.ID)
+		}
+	}
+
+	// 4 зависит только от задачи из корзины
+	if !slices.Equal(blocked, []int{2, 3}) {
+		t.Fatalf("expected blocked tasks %v, got %v", []int{2, 3}, blocked)
+	}
+
+	task, err := u.GetTaskByID(context.Background(), 3)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !task.Blocked {
+		t.Fatal("expected task 3 to be blocked")
+	}
+
+	if tasks[3].Blocked {
+		t.Fatal("expected stored task to stay unchanged")
+	}
+
+	tasks[2].Done = true
+
+	task, err = u.GetTaskByID(context.Background(), 3)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if task.Blocked {
+		t.Fatal("expected task 3 to be unblocked after its dependency is done")
+	}
+}
+
+func TestGetTasksInOrder(t *testing.T) {
+	tasks := map[int]*model.Task{
+		1: {ID: 1, Title: "A", DependsOn: []int{3}},
+		2: {ID: 2, Title: "B", Done: true},
+		3: {ID: 3, Title: "C", DependsOn: []int{2}},
+		4: {ID: 4, Title: "D", DependsOn: []int{42}},
+		5: {ID: 5, Title: "E", DependsOn: []int{6}},
+		6: {ID: 6, Title: "F", DependsOn: []int{5}},
+	}
+
+	u := usecase.NewTaskUsecase(graphRepo(tasks), &mock.MockRevisionRepo{}, &mock.MockProjectRepo{}, &mock.MockCommentRepo{}, usecase.Rules{}, logger.NewMockLogger())
+
+	ordered, err := u.GetTasksInOrder(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ids := make([]int, 0, len(ordered))
+	blocked := make([]int, 0)
+
+	for _, task := range ordered {
+		ids = append(ids, task.ID)
+
+		if task.Blocked {
+			blocked = append(blocked, task.ID)
+		}
+	}
+
+	// задачи из цикла 5 <-> 6 идут в конце
+	if !slices.Equal(ids, []int{2, 3, 1, 4, 5, 6}) {
+		t.Fatalf("expected order %v, got %v", []int{2, 3, 1, 4, 5, 6}, ids)
+	}
+
+	if !slices.Equal(blocked, []int{1, 5, 6}) {
+		t.Fatalf("expected blocked tasks %v, got %v", []int{1, 5, 6}, blocked)
+	}
+}
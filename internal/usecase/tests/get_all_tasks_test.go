@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/solumD/tasks-service/internal/model"
 	"github.com/solumD/tasks-service/internal/usecase"
@@ -52,9 +53,9 @@ func TestGetAllTasks(t *testing.T) {
 			}
 
 			log := logger.NewMockLogger()
-			u := usecase.NewTaskUsecase(repo, log)
+			u := usecase.NewTaskUsecase(repo, &mock.MockRevisionRepo{}, &mock.MockProjectRepo{}, &mock.MockCommentRepo{}, usecase.Rules{}, log)
 
-			tasks, err := u.GetAllTasks(context.Background())
+			tasks, err := u.GetAllTasks(context.Background(), model.TaskFilter{})
 
 			if (err != nil && tt.expectedErr == nil) || (err == nil && tt.expectedErr != nil) ||
 				(err != nil && tt.expectedErr != nil && err.Error() != tt.expectedErr.Error()) {
@@ -71,9 +72,203 @@ func TestGetAllTasks(t *testing.T) {
 				}
 			}
 
+			if repo.GetTasksByDueCalled {
+				t.Fatal("expected GetTasksByDue not called without due filter")
+			}
+
 			if repo.GetAllTasksCalled != tt.expectedCalled {
 				t.Fatalf("expected GetAllTasks called = %v, got %v", tt.expectedCalled, repo.GetAllTasksCalled)
 			}
 		})
 	}
 }
+
+func TestGetAllTasksByDue(t *testing.T) {
+	now := time.Now().UTC()
+	past := now.Add(-time.Hour)
+	future := now.Add(time.Hour)
+	after := now.Add(-24 * time.Hour)
+	before := now.Add(24 * time.Hour)
+
+	tests := []struct {
+		name           string
+		filter         model.TaskFilter
+		repoTasks      []*model.Task
+		expectedIDs    []int
+		expectedCalled bool
+		expectedFrom   time.Time
+		expectedTo     time.Time
+		toBeforeNow    bool
+	}{
+		{
+			name:   "range",
+			filter: model.TaskFilter{DueAfter: after, DueBefore: before},
+			repoTasks: []*model.Task{
+				{ID: 2, Title: "B", DueAt: &future},
+				{ID: 1, Title: "A", DueAt: &past, Done: true},
+			},
+			expectedIDs:    []int{1, 2},
+			expectedCalled: true,
+			expectedFrom:   after,
+			expectedTo:     before,
+		},
+		{
+			name:   "overdue skips done tasks",
+			filter: model.TaskFilter{Overdue: true},
+			repoTasks: []*model.Task{
+				{ID: 1, Title: "A", DueAt: &past, Done: true},
+				{ID: 2, Title: "B", DueAt: &past},
+			},
+			expectedIDs:    []int{2},
+			expectedCalled: true,
+			toBeforeNow:    true,
+		},
+		{
+			name:           "overdue keeps earlier upper bound",
+			filter:         model.TaskFilter{Overdue: true, DueBefore: after},
+			repoTasks:      []*model.Task{},
+			expectedIDs:    []int{},
+			expectedCalled: true,
+			expectedTo:     after,
+		},
+		{
+			name:           "empty range",
+			filter:         model.TaskFilter{DueAfter: before, DueBefore: after},
+			expectedIDs:    []int{},
+			expectedCalled: false,
+		},
+		{
+			name:           "overdue after now",
+			filter:         model.TaskFilter{Overdue: true, DueAfter: before},
+			expectedIDs:    []int{},
+			expectedCalled: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mock.MockTaskRepo{
+				GetTasksByDueFunc: func(ctx context.Context, from, to time.Time) ([]*model.Task, error) {
+					return tt.repoTasks, nil
+				},
+			}
+
+			u := usecase.NewTaskUsecase(repo, &mock.MockRevisionRepo{}, &mock.MockProjectRepo{}, &mock.MockCommentRepo{}, usecase.Rules{}, logger.NewMockLogger())
+
+			tasks, err := u.GetAllTasks(context.Background(), tt.filter)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(tasks) != len(tt.expectedIDs) {
+				t.Fatalf("expected %d tasks, got %d", len(tt.expectedIDs), len(tasks))
+			}
+
+			for i := range tasks {
+				if tasks[i].ID != tt.expectedIDs[i] {
+					t.Fatalf("expected task %d at %d, got %d", tt.expectedIDs[i], i, tasks[i].ID)
+				}
+			}
+
+			if repo.GetAllTasksCalled {
+				t.Fatal("expected GetAllTasks not called with due filter")
+			}
+
+			if repo.GetTasksByDueCalled != tt.expectedCalled {
+				t.Fatalf("expected GetTasksByDue called = %v, got %v", tt.expectedCalled, repo.GetTasksByDueCalled)
+			}
+
+			if !tt.expectedCalled {
+				return
+			}
+
+			if !repo.GetTasksByDueFrom.Equal(tt.expectedFrom) {
+				t.Fatalf("expected from %v, got %v", tt.expectedFrom, repo.GetTasksByDueFrom)
+			}
+
+			if tt.toBeforeNow {
+				if repo.GetTasksByDueTo.Before(now) || repo.GetTasksByDueTo.After(time.Now()) {
+					t.Fatalf("expected to at current time, got %v", repo.GetTasksByDueTo)
+				}
+
+				return
+			}
+
+			if !repo.GetTasksByDueTo.Equal(tt.expectedTo) {
+				t.Fatalf("expected to %v, got %v", tt.expectedTo, repo.GetTasksByDueTo)
+			}
+		})
+	}
+}
+
+func TestGetAllTasksSorted(t *testing.T) {
+	base := time.Date(2026, time.March, 1, 12, 0, 0, 0, time.UTC)
+	due := func(hours int) *time.Time {
+		at := base.Add(time.Duration(hours) * time.Hour)
+		return &at
+	}
+
+	created := func(hours int) time.Time {
+		return base.Add(time.Duration(hours) * time.Hour)
+	}
+
+	// задача 5 импортирована с более ранним временем создания, у задачи 3 его нет
+	repoTasks := func() []*model.Task {
+		return []*model.Task{
+			{ID: 4, Title: "delta", Priority: model.PriorityHigh, CreatedAt: created(1)},
+			{ID: 2, Title: "Bravo", Priority: model.PriorityLow, DueAt: due(5), CreatedAt: created(3)},
+			{ID: 5, Title: "alpha", DueAt: due(1), CreatedAt: created(-24)},
+			{ID: 1, Title: "charlie", Priority: model.PriorityHigh, DueAt: due(1), CreatedAt: created(2)},
+			{ID: 3, Title: "Alpha", Priority: model.PriorityUrgent},
+		}
+	}
+
+	tests := []struct {
+		name        string
+		filter      model.TaskFilter
+		expectedIDs []int
+	}{
+		{name: "default", filter: model.TaskFilter{}, expectedIDs: []int{1, 2, 3, 4, 5}},
+		{name: "id desc", filter: model.TaskFilter{SortBy: model.SortByID, Desc: true}, expectedIDs: []int{5, 4, 3, 2, 1}},
+		{name: "created asc", filter: model.TaskFilter{SortBy: model.SortByCreated}, expectedIDs: []int{3, 5, 4, 1, 2}},
+		{name: "created desc", filter: model.TaskFilter{SortBy: model.SortByCreated, Desc: true}, expectedIDs: []int{2, 1, 4, 5, 3}},
+		{name: "priority asc", filter: model.TaskFilter{SortBy: model.SortByPriority}, expectedIDs: []int{5, 2, 1, 4, 3}},
+		{name: "priority desc keeps id order on ties", filter: model.TaskFilter{SortBy: model.SortByPriority, Desc: true}, expectedIDs: []int{3, 1, 4, 2, 5}},
+		{name: "due asc without due last", filter: model.TaskFilter{SortBy: model.SortByDue}, expectedIDs: []int{1, 5, 2, 3, 4}},
+		{name: "due desc without due last", filter: model.TaskFilter{SortBy: model.SortByDue, Desc: true}, expectedIDs: []int{2, 1, 5, 3, 4}},
+		{name: "title ignores case", filter: model.TaskFilter{SortBy: model.SortByTitle}, expectedIDs: []int{3, 5, 2, 1, 4}},
+		{name: "title desc", filter: model.TaskFilter{SortBy: model.SortByTitle, Desc: true}, expectedIDs: []int{4, 1, 2, 3, 5}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mock.MockTaskRepo{
+				GetAllTasksFunc: func(ctx context.Context) ([]*model.Task, error) {
+					return repoTasks(), nil
+				},
+			}
+
+			u := usecase.NewTaskUsecase(repo, &mock.MockRevisionRepo{}, &mock.MockProjectRepo{}, &mock.MockCommentRepo{}, usecase.Rules{}, logger.NewMockLogger())
+
+			tasks, err := u.GetAllTasks(context.Background(), tt.filter)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			ids := make([]int, 0, len(tasks))
+			for _, task := range tasks {
+				ids = append(ids, task.ID)
+			}
+
+			if len(ids) != len(tt.expectedIDs) {
+				t.Fatalf("expected tasks %v, got %v", tt.expectedIDs, ids)
+			}
+
+			for i := range ids {
+				if ids[i] != tt.expectedIDs[i] {
+					t.Fatalf("expected tasks %v, got %v", tt.expectedIDs, ids)
+				}
+			}
+		})
+	}
+}
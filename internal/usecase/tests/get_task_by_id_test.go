@@ -15,63 +15,39 @@ func TestGetTaskByID(t *testing.T) {
 	tests := []struct {
 		name                  string
 		id                    int
-		existFunc             func(ctx context.Context, id int) (bool, error)
 		getByIDFunc           func(ctx context.Context, id int) (*model.Task, error)
 		expected              *model.Task
 		expectedErr           error
-		expectedExistCalled   bool
 		expectedGetByIDCalled bool
 	}{
 		{
 			name: "task not exist",
 			id:   1,
-			existFunc: func(ctx context.Context, id int) (bool, error) {
-				return false, nil
+			getByIDFunc: func(ctx context.Context, id int) (*model.Task, error) {
+				return nil, usecase.NewTaskNotFoundError(id)
 			},
-			getByIDFunc:           nil,
 			expected:              nil,
 			expectedErr:           usecase.ErrTaskNotFound,
-			expectedExistCalled:   true,
-			expectedGetByIDCalled: false,
-		},
-		{
-			name: "repo error on exist check",
-			id:   1,
-			existFunc: func(ctx context.Context, id int) (bool, error) {
-				return false, errors.New("db error")
-			},
-			getByIDFunc:           nil,
-			expected:              nil,
-			expectedErr:           errors.New("db error"),
-			expectedExistCalled:   true,
-			expectedGetByIDCalled: false,
+			expectedGetByIDCalled: true,
 		},
 		{
 			name: "repo error on get task",
 			id:   1,
-			existFunc: func(ctx context.Context, id int) (bool, error) {
-				return true, nil
-			},
 			getByIDFunc: func(ctx context.Context, id int) (*model.Task, error) {
 				return nil, errors.New("db get error")
 			},
 			expected:              nil,
 			expectedErr:           errors.New("db get error"),
-			expectedExistCalled:   true,
 			expectedGetByIDCalled: true,
 		},
 		{
 			name: "success",
 			id:   1,
-			existFunc: func(ctx context.Context, id int) (bool, error) {
-				return true, nil
-			},
 			getByIDFunc: func(ctx context.Context, id int) (*model.Task, error) {
 				return &model.Task{ID: 1, Title: "Task1"}, nil
 			},
 			expected:              &model.Task{ID: 1, Title: "Task1"},
 			expectedErr:           nil,
-			expectedExistCalled:   true,
 			expectedGetByIDCalled: true,
 		},
 	}
@@ -79,17 +55,16 @@ func TestGetTaskByID(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			repo := &mock.MockTaskRepo{
-				IsTaskExistByIDFunc: tt.existFunc,
-				GetTaskByIDFunc:     tt.getByIDFunc,
+				GetTaskByIDFunc: tt.getByIDFunc,
 			}
 
 			log := logger.NewMockLogger()
-			u := usecase.NewTaskUsecase(repo, log)
+			u := usecase.NewTaskUsecase(repo, &mock.MockRevisionRepo{}, &mock.MockProjectRepo{}, &mock.MockCommentRepo{}, usecase.Rules{}, log)
 
 			task, err := u.GetTaskByID(context.Background(), tt.id)
 
 			if (err != nil && tt.expectedErr == nil) || (err == nil && tt.expectedErr != nil) ||
-				(err != nil && tt.expectedErr != nil && err.Error() != tt.expectedErr.Error()) {
+				(err != nil && tt.expectedErr != nil && !errors.Is(err, tt.expectedErr) && err.Error() != tt.expectedErr.Error()) {
 				t.Fatalf("expected error %v, got %v", tt.expectedErr, err)
 			}
 
@@ -97,10 +72,6 @@ func TestGetTaskByID(t *testing.T) {
 				t.Fatalf("expected task %v, got %v", tt.expected, task)
 			}
 
-			if repo.IsTaskExistByIDCalled != tt.expectedExistCalled {
-				t.Fatalf("expected IsTaskExistByID called = %v, got %v", tt.expectedExistCalled, repo.IsTaskExistByIDCalled)
-			}
-
 			if repo.GetTaskByIDCalled != tt.expectedGetByIDCalled {
 				t.Fatalf("expected GetTaskByID called = %v, got %v", tt.expectedGetByIDCalled, repo.GetTaskByIDCalled)
 			}
@@ -0,0 +1,367 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/solumD/tasks-service/internal/model"
+	"github.com/solumD/tasks-service/internal/usecase"
+	"github.com/solumD/tasks-service/internal/usecase/mock"
+	"github.com/solumD/tasks-service/pkg/logger"
+)
+
+// treeRepo возвращает мок хранилища поверх tasks: задачи с DeletedAt считаются
+// лежащими в корзине
+func treeRepo(tasks map[int]*model.Task) *mock.MockTaskRepo {
+	get := func(id int) (*model.Task, error) {
+		task, ok := tasks[id]
+		if !ok || task.DeletedAt != nil {
+			return nil, usecase.NewTaskNotFoundError(id)
+		}
+
+		return task.Clone(), nil
+	}
+
+	return &mock.MockTaskRepo{
+		CreateTaskFunc: func(ctx context.Context, task *model.Task) (int, error) {
+			task.ID = len(tasks) + 1
+			task.Version = 1
+			tasks[task.ID] = task.Clone()
+
+			return task.ID, nil
+		},
+		GetTaskByIDFunc: func(ctx context.Context, id int) (*model.Task, error) {
+			return get(id)
+		},
+		GetTasksByParentFunc: func(ctx context.Context, parentID int) ([]*model.Task, error) {
+			children := make([]*model.Task, 0)
+			for _, task := range tasks {
+				if task.ParentID == parentID && task.DeletedAt == nil {
+					children = append(children, task.Clone())
+				}
+			}
+
+			return children, nil
+		},
+		UpdateTaskFunc: func(ctx context.Context, task *model.Task) error {
+			current, err := get(task.ID)
+			if err != nil {
+				return err
+			}
+
+			if task.Version != 0 && task.Version != current.Version {
+				return usecase.ErrVersionConflict
+			}
+
+			task.Version = current.Version + 1
+			tasks[task.ID] = task.Clone()
+
+			return nil
+		},
+		DeleteTaskFunc: func(ctx context.Context, id int, version int) (*model.Task, error) {
+			task, err := get(id)
+			if err != nil {
+				return nil, err
+			}
+
+			now := time.Now().UTC()
+			task.DeletedAt = &now
+			task.Version++
+			tasks[id] = task.Clone()
+
+			return task, nil
+		},
+		RestoreTaskFunc: func(ctx context.Context, id int) (*model.Task, error) {
+			task, ok := tasks[id]
+			if !ok || task.DeletedAt == nil {
+				return nil, usecase.NewTaskNotFoundError(id)
+			}
+
+			task.DeletedAt = nil
+			task.Version++
+
+			return task.Clone(), nil
+		},
+	}
+}
+
+// newTree возвращает дерево 1 -> 2 -> 3 и 1 -> 4 и отдельную задачу 5
+func newTree() map[int]*model.Task {
+	return map[int]*model.Task{
+		1: {ID: 1, Title: "Root", Version: 1},
+		2: {ID: 2, Title: "Child", ParentID: 1, Version: 1},
+		3: {ID: 3, Title: "Grandchild", ParentID: 2, Version: 1},
+		4: {ID: 4, Title: "Child2", ParentID: 1, Version: 1},
+		5: {ID: 5, Title: "Other", Version: 1},
+	}
+}
+
+func TestSetParentChecks(t *testing.T) {
+	tests := []struct {
+		name        string
+		task        *model.Task
+		create      bool
+		expectedErr error
+	}{
+		{name: "create under existing", task: &model.Task{Title: "New", ParentID: 3}, create: true},
+		{name: "create under missing", task: &model.Task{Title: "New", ParentID: 42}, create: true, expectedErr: usecase.ErrParentNotFound},
+		{name: "move to other", task: &model.Task{ID: 2, Title: "Child", ParentID: 5}},
+		{name: "move to top level", task: &model.Task{ID: 3, Title: "Grandchild"}},
+		{name: "parent of itself", task: &model.Task{ID: 2, Title: "Child", ParentID: 2}, expectedErr: usecase.ErrTaskCycle},
+		{name: "under own grandchild", task: &model.Task{ID: 1, Title: "Root", ParentID: 3}, expectedErr: usecase.ErrTaskCycle},
+		{name: "under missing", task: &model.Task{ID: 2, Title: "Child", ParentID: 42}, expectedErr: usecase.ErrParentNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tasks := newTree()
+			repo := treeRepo(tasks)
+
+			u := usecase.NewTaskUsecase(repo, &mock.MockRevisionRepo{}, &mock.MockProjectRepo{}, &mock.MockCommentRepo{}, usecase.Rules{}, logger.NewMockLogger())
+
+			var err error
+			if tt.create {
+				_, err = u.CreateTask(context.Background(), tt.task)
+			} else {
+				err = u.UpdateTask(context.Background(), tt.task)
+			}
+
+			if !errors.Is(err, tt.expectedErr) {
+				t.Fatalf("expected error %v, got %v", tt.expectedErr, err)
+			}
+
+			written := repo.CreateTaskCalled || repo.UpdateTaskCalled
+			if written != (tt.expectedErr == nil) {
+				t.Fatalf("expected repo written = %v, got %v", tt.expectedErr == nil, written)
+			}
+
+			if tt.expectedErr == nil && tasks[tt.task.ID].ParentID != tt.task.ParentID {
+				t.Fatalf("expected parent %d, got %d", tt.task.ParentID, tasks[tt.task.ID].ParentID)
+			}
+		})
+	}
+}
+
+func TestDeleteTaskWithChildren(t *testing.T) {
+	tests := []struct {
+		name            string
+		rule            model.DeleteRule
+		id              int
+		expectedErr     error
+		expectedDeleted []int
+		expectedParents map[int]int
+	}{
+		{
+			name:            "block by default",
+			id:              1,
+			expectedErr:     usecase.ErrTaskHasChildren,
+			expectedDeleted: []int{},
+			expectedParents: map[int]int{2: 1, 3: 2, 4: 1},
+		},
+		{
+			name:            "block leaf",
+			rule:            model.DeleteBlock,
+			id:              3,
+			expectedDeleted: []int{3},
+			expectedParents: map[int]int{2: 1, 4: 1},
+		},
+		{
+			name:            "cascade",
+			rule:            model.DeleteCascade,
+			id:              1,
+			expectedDeleted: []int{1, 2, 3, 4},
+			expectedParents: map[int]int{2: 1, 3: 2, 4: 1},
+		},
+		{
+			name:            "orphan",
+			rule:            model.DeleteOrphan,
+			id:              1,
+			expectedDeleted: []int{1},
+			expectedParents: map[int]int{2: 0, 3: 2, 4: 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tasks := newTree()
+			revisions := &mock.MockRevisionRepo{}
+
+			u := usecase.NewTaskUsecase(treeRepo(tasks), revisions, &mock.MockProjectRepo{}, &mock.MockCommentRepo{}, usecase.Rules{Hierarchy: usecase.Hierarchy{OnDelete: tt.rule}}, logger.NewMockLogger())
+
+			err := u.DeleteTask(context.Background(), tt.id, 0)
+			if !errors.Is(err, tt.expectedErr) {
+				t.Fatalf("expected error %v, got %v", tt.expectedErr, err)
+			}
+
+			deleted := make([]int, 0)
+			for id, task := range tasks {
+				if task.DeletedAt != nil {
+					deleted = append(deleted, id)
+				}
+			}
+			slices.Sort(deleted)
+
+			if !slices.Equal(deleted, tt.expectedDeleted) {
+				t.Fatalf("expected deleted tasks %v, got %v", tt.expectedDeleted, deleted)
+			}
+
+			for id, parentID := range tt.expectedParents {
+				if tasks[id].ParentID != parentID {
+					t.Fatalf("expected task %d parent %d, got %d", id, parentID, tasks[id].ParentID)
+				}
+			}
+
+			// каждое изменение задачи, включая перенос подзадач, получает ревизию
+			expectedRevisions := len(tt.expectedDeleted)
+			for id, parentID := range tt.expectedParents {
+				if parentID == 0 && newTree()[id].ParentID != 0 {
+					expectedRevisions++
+				}
+			}
+
+			if len(revisions.AddRevisionRevisions) != expectedRevisions {
+				t.Fatalf("expected %d revisions, got %d", expectedRevisions, len(revisions.AddRevisionRevisions))
+			}
+		})
+	}
+}
+
+func TestAutoCompleteParent(t *testing.T) {
+	tests := []struct {
+		name         string
+		autoComplete bool
+		complete     []int
+		expectedDone []int
+	}{
+		{name: "not all children done", autoComplete: true, complete: []int{4}, expectedDone: []int{4}},
+		{name: "roll up to root", autoComplete: true, complete: []int{4, 3}, expectedDone: []int{1, 2, 3, 4}},
+		{name: "disabled", autoComplete: false, complete: []int{4, 3}, expectedDone: []int{3, 4}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tasks := newTree()
+
+			u := usecase.NewTaskUsecase(treeRepo(tasks), &mock.MockRevisionRepo{}, &mock.MockProjectRepo{}, &mock.MockCommentRepo{},
+				usecase.Rules{Hierarchy: usecase.Hierarchy{AutoComplete: tt.autoComplete}}, logger.NewMockLogger())
+
+			for _, id := range tt.complete {
+				task := tasks[id].Clone()
+				task.Done = true
+
+				if err := u.UpdateTask(context.Background(), task); err != nil {
+					t.Fatalf("failed to complete task %d: %v", id, err)
+				}
+			}
+
+			done := make([]int, 0)
+			for id, task := range tasks {
+				if task.Done {
+					done = append(done, id)
+				}
+			}
+			slices.Sort(done)
+
+			if !slices.Equal(done, tt.expectedDone) {
+				t.Fatalf("expected done tasks %v, got %v", tt.expectedDone, done)
+			}
+		})
+	}
+}
+
+func TestAutoCompleteParentOnDelete(t *testing.T) {
+	tasks := newTree()
+	tasks[4].Done = true
+
+	u := usecase.NewTaskUsecase(treeRepo(tasks), &mock.MockRevisionRepo{}, &mock.MockProjectRepo{}, &mock.MockCommentRepo{},
+		usecase.Rules{Hierarchy: usecase.Hierarchy{OnDelete: model.DeleteCascade, AutoComplete: true}}, logger.NewMockLogger())
+
+	// после удаления единственной невыполненной ветки все подзадачи корня выполнены
+	if err := u.DeleteTask(context.Background(), 2, 0); err != nil {
+		t.Fatalf("failed to delete task: %v", err)
+	}
+
+	if !tasks[1].Done {
+		t.Fatal("expected root completed after its last open child was deleted")
+	}
+}
+
+func TestGetTaskTree(t *testing.T) {
+	tests := []struct {
+		name        string
+		id          int
+		depth       int
+		expected    string
+		expectedErr error
+	}{
+		{name: "only root", id: 1, depth: 0, expected: "1+"},
+		{name: "one level", id: 1, depth: 1, expected: "1(2+ 4)"},
+		{name: "full", id: 1, depth: 2, expected: "1(2(3) 4)"},
+		{name: "leaf", id: 3, depth: 5, expected: "3"},
+		{name: "negative depth", id: 1, depth: -1, expectedErr: usecase.ErrInvalidDepth},
+		{name: "too deep", id: 1, depth: 11, expectedErr: usecase.ErrInvalidDepth},
+		{name: "not found", id: 42, depth: 1, expectedErr: usecase.ErrTaskNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := usecase.NewTaskUsecase(treeRepo(newTree()), &mock.MockRevisionRepo{}, &mock.MockProjectRepo{}, &mock.MockCommentRepo{}, usecase.Rules{}, logger.NewMockLogger())
+
+			tree, err := u.GetTaskTree(context.Background(), tt.id, tt.depth)
+			if !errors.Is(err, tt.expectedErr) {
+				t.Fatalf("expected error %v, got %v", tt.expectedErr, err)
+			}
+
+			if tt.expectedErr != nil {
+				return
+			}
+
+			if got := formatTree(tree); got != tt.expected {
+				t.Fatalf("expected tree %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+// formatTree записывает дерево как ID(подзадачи), + отмечает подзадачи за границей глубины
+func formatTree(node *model.TaskNode) string {
+	s := fmt.Sprint(node.Task.ID)
+
+	if node.HasChildren && len(node.Children) == 0 {
+		return s + "+"
+	}
+
+	if len(node.Children) == 0 {
+		return s
+	}
+
+	children := make([]string, 0, len(node.Children))
+	for _, child := range node.Children {
+		children = append(children, formatTree(child))
+	}
+
+	return s + "(" + strings.Join(children, " ") + ")"
+}
+
+func TestRestoreTaskDetachesFromMissingParent(t *testing.T) {
+	tasks := newTree()
+	deletedAt := time.Now().UTC()
+	tasks[1].DeletedAt = &deletedAt
+	tasks[4].DeletedAt = &deletedAt
+
+	u := usecase.NewTaskUsecase(treeRepo(tasks), &mock.MockRevisionRepo{}, &mock.MockProjectRepo{}, &mock.MockCommentRepo{}, usecase.Rules{}, logger.NewMockLogger())
+
+	restored, err := u.RestoreTask(context.Background(), 4)
+	if err != nil {
+		t.Fatalf("failed to restore task: %v", err)
+	}
+
+	if restored.ParentID != 0 || tasks[4].ParentID != 0 {
+		t.Fatalf("expected task restored to top level, got parent %d", restored.ParentID)
+	}
+}
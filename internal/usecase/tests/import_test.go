@@ -0,0 +1,250 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/solumD/tasks-service/internal/model"
+	"github.com/solumD/tasks-service/internal/usecase"
+	"github.com/solumD/tasks-service/internal/usecase/mock"
+	"github.com/solumD/tasks-service/pkg/logger"
+)
+
+func TestImportTasks(t *testing.T) {
+	errRepo := errors.New("db error")
+
+	tests := []struct {
+		name            string
+		dryRun          bool
+		expectedErrs    []error
+		expectedCreated int
+	}{
+		{
+			name:            "import",
+			expectedErrs:    []error{nil, usecase.ErrEmptyTitle, errRepo, nil},
+			expectedCreated: 3,
+		},
+		{
+			name:            "dry run",
+			dryRun:          true,
+			expectedErrs:    []error{nil, usecase.ErrEmptyTitle, nil, nil},
+			expectedCreated: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			created := 0
+			repo := &mock.MockTaskRepo{
+				CreateTaskFunc: func(ctx context.Context, task *model.Task) (int, error) {
+					created++
+					if task.Title == "broken" {
+						return 0, errRepo
+					}
+
+					task.ID = created
+					return created, nil
+				},
+			}
+
+			u := usecase.NewTaskUsecase(repo, &mock.MockRevisionRepo{}, &mock.MockProjectRepo{}, &mock.MockCommentRepo{}, usecase.Rules{}, logger.NewMockLogger())
+
+			tasks := []*model.Task{{Title: "A"}, {Title: ""}, {Title: "broken"}, {Title: "B"}}
+
+			errs := u.ImportTasks(context.Background(), tasks, tt.dryRun)
+			if len(errs) != len(tasks) {
+				t.Fatalf("expected %d results, got %d", len(tasks), len(errs))
+			}
+
+			for i, err := range errs {
+				if !errors.Is(err, tt.expectedErrs[i]) || (err == nil) != (tt.expectedErrs[i] == nil) {
+					t.Fatalf("expected error %v for task %d, got %v", tt.expectedErrs[i], i, err)
+				}
+			}
+
+			if created != tt.expectedCreated {
+				t.Fatalf("expected %d repo creates, got %d", tt.expectedCreated, created)
+			}
+		})
+	}
+}
+
+func TestImportTasksProject(t *testing.T) {
+	archivedAt := time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		dryRun bool
+	}{
+		{name: "import"},
+		{name: "dry run", dryRun: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			projects := map[int]*model.Project{
+				1: {ID: 1, Name: "Active", Version: 1},
+				2: {ID: 2, Name: "Archived", ArchivedAt: &archivedAt, Version: 1},
+			}
+
+			tasks := map[int]*model.Task{}
+			u := usecase.NewTaskUsecase(graphRepo(tasks), &mock.MockRevisionRepo{}, projectRepo(projects), &mock.MockCommentRepo{}, usecase.Rules{}, logger.NewMockLogger())
+
+			imported := []*model.Task{
+				{Title: "Active", ProjectID: 1},
+				{Title: "Archived", ProjectID: 2},
+				{Title: "Missing", ProjectID: 3},
+				{Title: "Outside"},
+			}
+			expectedErrs := []error{nil, usecase.ErrProjectArchived, usecase.ErrProjectNotFound, nil}
+
+			errs := u.ImportTasks(context.Background(), imported, tt.dryRun)
+
+			for i, err := range errs {
+				if !errors.Is(err, expectedErrs[i]) || (err == nil) != (expectedErrs[i] == nil) {
+					t.Fatalf("expected error %v for task %d, got %v", expectedErrs[i], i, err)
+				}
+			}
+
+			if tt.dryRun {
+				if len(tasks) != 0 {
+					t.Fatalf("expected no tasks on dry run, got %d", len(tasks))
+				}
+
+				return
+			}
+
+			if len(tasks) != 2 {
+				t.Fatalf("expected 2 created tasks, got %d", len(tasks))
+			}
+
+			if got := tasks[imported[0].ID].ProjectID; got != 1 {
+				t.Fatalf("expected imported task in project 1, got %d", got)
+			}
+		})
+	}
+}
+
+func TestImportTasksParent(t *testing.T) {
+	tests := []struct {
+		name   string
+		dryRun bool
+	}{
+		{name: "import"},
+		{name: "dry run", dryRun: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tasks := map[int]*model.Task{}
+			u := usecase.NewTaskUsecase(graphRepo(tasks), &mock.MockRevisionRepo{}, &mock.MockProjectRepo{}, &mock.MockCommentRepo{}, usecase.Rules{}, logger.NewMockLogger())
+
+			// подзадача идет в импорте раньше родителя
+			imported := []*model.Task{
+				{ID: 7, Title: "Child", ParentID: 3},
+				{ID: 3, Title: "Parent"},
+				{ID: 8, Title: "Orphan", ParentID: 99},
+				{ID: 3, Title: "Duplicate"},
+				{ID: 9, Title: ""},
+				{ID: 10, Title: "Child of invalid", ParentID: 9},
+				{ID: 11, Title: "Self", ParentID: 11},
+				{Title: "Without id"},
+			}
+			expectedErrs := []error{
+				nil, nil, usecase.ErrParentNotFound, usecase.ErrDuplicateImportID,
+				usecase.ErrEmptyTitle, usecase.ErrParentNotFound, usecase.ErrTaskCycle, nil,
+			}
+
+			errs := u.ImportTasks(context.Background(), imported, tt.dryRun)
+
+			for i, err := range errs {
+				if !errors.Is(err, expectedErrs[i]) || (err == nil) != (expectedErrs[i] == nil) {
+					t.Fatalf("expected error %v for task %d, got %v", expectedErrs[i], i, err)
+				}
+			}
+
+			if tt.dryRun {
+				if len(tasks) != 0 {
+					t.Fatalf("expected no tasks on dry run, got %d", len(tasks))
+				}
+
+				return
+			}
+
+			if len(tasks) != 3 {
+				t.Fatalf("expected 3 created tasks, got %d", len(tasks))
+			}
+
+			parent, child := imported[1], imported[0]
+			if parent.ID != 1 || child.ID != 2 {
+				t.Fatalf("expected parent created before child, got parent %d and child %d", parent.ID, child.ID)
+			}
+
+			if got := tasks[child.ID].ParentID; got != parent.ID {
+				t.Fatalf("expected child parent %d, got %d", parent.ID, got)
+			}
+		})
+	}
+}
+
+func TestImportTasksDependencies(t *testing.T) {
+	tests := []struct {
+		name   string
+		dryRun bool
+	}{
+		{name: "import"},
+		{name: "dry run", dryRun: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tasks := map[int]*model.Task{}
+			u := usecase.NewTaskUsecase(graphRepo(tasks), &mock.MockRevisionRepo{}, &mock.MockProjectRepo{}, &mock.MockCommentRepo{}, usecase.Rules{}, logger.NewMockLogger())
+
+			// задача идет в импорте раньше своих зависимостей
+			imported := []*model.Task{
+				{ID: 5, Title: "Last", DependsOn: []int{4, 3}},
+				{ID: 4, Title: "Second", DependsOn: []int{3}},
+				{ID: 3, Title: "First"},
+				{ID: 6, Title: "Outside", DependsOn: []int{3, 99}},
+				{ID: 7, Title: "Cycle", DependsOn: []int{8}},
+				{ID: 8, Title: "Cycle back", DependsOn: []int{7}},
+			}
+			expectedErrs := []error{
+				nil, nil, nil, usecase.ErrDependencyNotFound, usecase.ErrDependencyNotFound, usecase.ErrDependencyCycle,
+			}
+
+			errs := u.ImportTasks(context.Background(), imported, tt.dryRun)
+
+			for i, err := range errs {
+				if !errors.Is(err, expectedErrs[i]) || (err == nil) != (expectedErrs[i] == nil) {
+					t.Fatalf("expected error %v for task %d, got %v", expectedErrs[i], i, err)
+				}
+			}
+
+			if tt.dryRun {
+				if len(tasks) != 0 {
+					t.Fatalf("expected no tasks on dry run, got %d", len(tasks))
+				}
+
+				return
+			}
+
+			if len(tasks) != 3 {
+				t.Fatalf("expected 3 created tasks, got %d", len(tasks))
+			}
+
+			last, second, first := imported[0], imported[1], imported[2]
+			if first.ID != 1 || second.ID != 2 || last.ID != 3 {
+				t.Fatalf("expected dependencies created first, got ids %d, %d, %d", first.ID, second.ID, last.ID)
+			}
+
+			if got := tasks[last.ID].DependsOn; !slices.Equal(got, []int{first.ID, second.ID}) {
+				t.Fatalf("expected dependencies %v, got %v", []int{first.ID, second.ID}, got)
+			}
+		})
+	}
+}
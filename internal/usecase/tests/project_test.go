@@ -0,0 +1,397 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/solumD/tasks-service/internal/model"
+	"github.com/solumD/tasks-service/internal/usecase"
+	"github.com/solumD/tasks-service/internal/usecase/mock"
+	"github.com/solumD/tasks-service/pkg/logger"
+)
+
+// projectRepo возвращает мок хранилища проектов поверх projects
+func projectRepo(projects map[int]*model.Project) *mock.MockProjectRepo {
+	return &mock.MockProjectRepo{
+		CreateProjectFunc: func(ctx context.Context, project *model.Project) (int, error) {
+			project.ID = len(projects) + 1
+			project.Version = 1
+			projects[project.ID] = project.Clone()
+
+			return project.ID, nil
+		},
+		GetProjectsFunc: func(ctx context.Context) ([]*model.Project, error) {
+			list := make([]*model.Project, 0, len(projects))
+			for _, project := range projects {
+				list = append(list, project.Clone())
+			}
+
+			slices.SortFunc(list, func(a, b *model.Project) int {
+				return a.ID - b.ID
+			})
+
+			return list, nil
+		},
+		GetProjectByIDFunc: func(ctx context.Context, id int) (*model.Project, error) {
+			project, ok := projects[id]
+			if !ok {
+				return nil, usecase.NewProjectNotFoundError(id)
+			}
+
+			return project.Clone(), nil
+		},
+		UpdateProjectFunc: func(ctx context.Context, project *model.Project) error {
+			current, ok := projects[project.ID]
+			if !ok {
+				return usecase.NewProjectNotFoundError(project.ID)
+			}
+
+			if project.Version != 0 && project.Version != current.Version {
+				return usecase.ErrProjectVersionConflict
+			}
+
+			project.Version = current.Version + 1
+			projects[project.ID] = project.Clone()
+
+			return nil
+		},
+		DeleteProjectFunc: func(ctx context.Context, id int, version int) error {
+			current, ok := projects[id]
+			if !ok {
+				return usecase.NewProjectNotFoundError(id)
+			}
+
+			if version != 0 && version != current.Version {
+				return usecase.ErrProjectVersionConflict
+			}
+
+			delete(projects, id)
+
+			return nil
+		},
+	}
+}
+
+// newProjects возвращает активный проект 1 и архивный проект 2
+func newProjects() map[int]*model.Project {
+	archivedAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	return map[int]*model.Project{
+		1: {ID: 1, Name: "Home", Version: 1},
+		2: {ID: 2, Name: "Old", ArchivedAt: &archivedAt, Version: 1},
+	}
+}
+
+// newProjectTasks возвращает задачи 1 (выполнена) и 2 в проекте 1, задачу 3 в архивном
+// проекте 2, задачу 4 вне проектов и задачу 5 - подзадачу задачи 2 вне проектов
+func newProjectTasks() map[int]*model.Task {
+	return map[int]*model.Task{
+		1: {ID: 1, Title: "Clean", Done: true, ProjectID: 1, Version: 1},
+		2: {ID: 2, Title: "Cook", ProjectID: 1, Version: 1},
+		3: {ID: 3, Title: "Archived", ProjectID: 2, Version: 1},
+		4: {ID: 4, Title: "Free", Version: 1},
+		5: {ID: 5, Title: "Buy", ParentID: 2, Version: 1},
+	}
+}
+
+// projectUsecase методы юзкейса проектов
+type projectUsecase interface {
+	GetProjects(ctx context.Context) ([]*model.Project, error)
+	GetProject(ctx context.Context, id int) (*model.Project, error)
+	UpdateProject(ctx context.Context, project *model.Project, archived bool) error
+	DeleteProject(ctx context.Context, id int, version int) error
+	GetProjectTasks(ctx context.Context, id int, filter model.TaskFilter) ([]*model.Task, error)
+	CreateProjectTask(ctx context.Context, id int, task *model.Task) (int, error)
+	MoveTask(ctx context.Context, id int, projectID int, version int) (*model.Task, error)
+}
+
+// newProjectUsecase создает юзкейс проектов поверх юзкейса задач с общим хранилищем проектов
+func newProjectUsecase(tasks map[int]*model.Task, projects map[int]*model.Project, rule model.DeleteRule) projectUsecase {
+	repo := projectRepo(projects)
+	taskUsecase := usecase.NewTaskUsecase(graphRepo(tasks), &mock.MockRevisionRepo{}, repo, &mock.MockCommentRepo{}, usecase.Rules{}, logger.NewMockLogger())
+
+	return usecase.NewProjectUsecase(repo, taskUsecase, rule, logger.NewMockLogger())
+}
+
+func TestGetProjectCounts(t *testing.T) {
+	u := newProjectUsecase(newProjectTasks(), newProjects(), "")
+
+	projects, err := u.GetProjects(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(projects) != 2 {
+		t.Fatalf("expected 2 projects, got %d", len(projects))
+	}
+
+	home := projects[0].Counts
+	if home.Total != 2 || home.ByStatus[model.StatusDone] != 1 || home.ByStatus[model.StatusTodo] != 1 {
+		t.Fatalf("unexpected counts of project 1: %+v", home)
+	}
+
+	if projects[1].Counts.Total != 1 {
+		t.Fatalf("expected 1 task in project 2, got %+v", projects[1].Counts)
+	}
+
+	if _, err := u.GetProject(context.Background(), 42); !errors.Is(err, usecase.ErrProjectNotFound) {
+		t.Fatalf("expected ErrProjectNotFound, got %v", err)
+	}
+}
+
+func TestUpdateProjectArchive(t *testing.T) {
+	projects := newProjects()
+	u := newProjectUsecase(newProjectTasks(), projects, "")
+	archivedAt := *projects[2].ArchivedAt
+
+	if err := u.UpdateProject(context.Background(), &model.Project{ID: 1, Name: ""}, false); !errors.Is(err, usecase.ErrEmptyProjectName) {
+		t.Fatalf("expected ErrEmptyProjectName, got %v", err)
+	}
+
+	if err := u.UpdateProject(context.Background(), &model.Project{ID: 1, Name: "Home", Version: 1}, true); err != nil {
+		t.Fatalf("failed to archive project: %v", err)
+	}
+
+	if !projects[1].Archived() {
+		t.Fatal("expected project 1 archived")
+	}
+
+	// повторная архивация не меняет время архивации
+	if err := u.UpdateProject(context.Background(), &model.Project{ID: 2, Name: "Older"}, true); err != nil {
+		t.Fatalf("failed to update project: %v", err)
+	}
+
+	if !projects[2].ArchivedAt.Equal(archivedAt) || projects[2].Name != "Older" {
+		t.Fatalf("unexpected project 2: %+v", projects[2])
+	}
+
+	if err := u.UpdateProject(context.Background(), &model.Project{ID: 2, Name: "Older", Version: 1}, false); !errors.Is(err, usecase.ErrProjectVersionConflict) {
+		t.Fatalf("expected ErrProjectVersionConflict, got %v", err)
+	}
+
+	if err := u.UpdateProject(context.Background(), &model.Project{ID: 2, Name: "Older"}, false); err != nil {
+		t.Fatalf("failed to unarchive project: %v", err)
+	}
+
+	if projects[2].Archived() {
+		t.Fatal("expected project 2 active")
+	}
+}
+
+func TestDeleteProject(t *testing.T) {
+	tests := []struct {
+		name             string
+		rule             model.DeleteRule
+		id               int
+		version          int
+		expectedErr      error
+		expectedDeleted  []int
+		expectedProjects map[int]int
+		expectedParents  map[int]int
+	}{
+		{
+			name:             "block by default",
+			id:               1,
+			expectedErr:      usecase.ErrProjectHasTasks,
+			expectedDeleted:  []int{},
+			expectedProjects: map[int]int{1: 1, 2: 1, 3: 2},
+			expectedParents:  map[int]int{5: 2},
+		},
+		{
+			name:             "version conflict",
+			rule:             model.DeleteOrphan,
+			id:               1,
+			version:          2,
+			expectedErr:      usecase.ErrProjectVersionConflict,
+			expectedDeleted:  []int{},
+			expectedProjects: map[int]int{1: 1, 2: 1, 3: 2},
+			expectedParents:  map[int]int{5: 2},
+		},
+		{
+			name:             "not found",
+			rule:             model.DeleteCascade,
+			id:               42,
+			expectedErr:      usecase.ErrProjectNotFound,
+			expectedDeleted:  []int{},
+			expectedProjects: map[int]int{1: 1, 2: 1, 3: 2},
+			expectedParents:  map[int]int{5: 2},
+		},
+		{
+			name:             "cascade",
+			rule:             model.DeleteCascade,
+			id:               1,
+			version:          1,
+			expectedDeleted:  []int{1, 2},
+			expectedProjects: map[int]int{1: 1, 2: 1, 3: 2},
+			expectedParents:  map[int]int{5: 0},
+		},
+		{
+			name:             "orphan",
+			rule:             model.DeleteOrphan,
+			id:               1,
+			expectedDeleted:  []int{},
+			expectedProjects: map[int]int{1: 0, 2: 0, 3: 2},
+			expectedParents:  map[int]int{5: 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tasks := newProjectTasks()
+			projects := newProjects()
+
+			u := newProjectUsecase(tasks, projects, tt.rule)
+
+			err := u.DeleteProject(context.Background(), tt.id, tt.version)
+			if !errors.Is(err, tt.expectedErr) {
+				t.Fatalf("expected error %v, got %v", tt.expectedErr, err)
+			}
+
+			_, exist := projects[tt.id]
+			if exist == (tt.expectedErr == nil) && tt.id != 42 {
+				t.Fatalf("expected project deleted = %v", tt.expectedErr == nil)
+			}
+
+			deleted := make([]int, 0)
+			for id, task := range tasks {
+				if task.DeletedAt != nil {
+					deleted = append(deleted, id)
+				}
+			}
+
+			slices.Sort(deleted)
+			if !slices.Equal(deleted, tt.expectedDeleted) {
+				t.Fatalf("expected deleted %v, got %v", tt.expectedDeleted, deleted)
+			}
+
+			for id, projectID := range tt.expectedProjects {
+				if tasks[id].ProjectID != projectID {
+					t.Fatalf("expected task %d in project %d, got %d", id, projectID, tasks[id].ProjectID)
+				}
+			}
+
+			for id, parentID := range tt.expectedParents {
+				if tasks[id].ParentID != parentID {
+					t.Fatalf("expected task %d under %d, got %d", id, parentID, tasks[id].ParentID)
+				}
+			}
+		})
+	}
+}
+
+func TestMoveTask(t *testing.T) {
+	tests := []struct {
+		name            string
+		id              int
+		projectID       int
+		version         int
+		expectedErr     error
+		expectedProject int
+	}{
+		{name: "to project", id: 4, projectID: 1, expectedProject: 1},
+		{name: "out of projects", id: 2, projectID: 0, expectedProject: 0},
+		{name: "out of archived", id: 3, projectID: 1, expectedProject: 1},
+		{name: "same project", id: 2, projectID: 1, version: 1, expectedProject: 1},
+		{name: "to archived", id: 4, projectID: 2, expectedErr: usecase.ErrProjectArchived, expectedProject: 0},
+		{name: "to missing", id: 4, projectID: 42, expectedErr: usecase.ErrProjectNotFound, expectedProject: 0},
+		{name: "missing task", id: 42, projectID: 1, expectedErr: usecase.ErrTaskNotFound},
+		{name: "version conflict", id: 4, projectID: 1, version: 2, expectedErr: usecase.ErrVersionConflict, expectedProject: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tasks := newProjectTasks()
+			u := newProjectUsecase(tasks, newProjects(), "")
+
+			task, err := u.MoveTask(context.Background(), tt.id, tt.projectID, tt.version)
+			if !errors.Is(err, tt.expectedErr) {
+				t.Fatalf("expected error %v, got %v", tt.expectedErr, err)
+			}
+
+			if tt.expectedErr == nil && task.ProjectID != tt.expectedProject {
+				t.Fatalf("expected returned task in project %d, got %d", tt.expectedProject, task.ProjectID)
+			}
+
+			if stored, ok := tasks[tt.id]; ok && stored.ProjectID != tt.expectedProject {
+				t.Fatalf("expected task in project %d, got %d", tt.expectedProject, stored.ProjectID)
+			}
+		})
+	}
+}
+
+func TestCreateProjectTask(t *testing.T) {
+	tests := []struct {
+		name        string
+		id          int
+		expectedErr error
+	}{
+		{name: "active project", id: 1},
+		{name: "archived project", id: 2, expectedErr: usecase.ErrProjectArchived},
+		{name: "missing project", id: 42, expectedErr: usecase.ErrProjectNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tasks := newProjectTasks()
+			u := newProjectUsecase(tasks, newProjects(), "")
+
+			id, err := u.CreateProjectTask(context.Background(), tt.id, &model.Task{Title: "New"})
+			if !errors.Is(err, tt.expectedErr) {
+				t.Fatalf("expected error %v, got %v", tt.expectedErr, err)
+			}
+
+			if tt.expectedErr == nil && tasks[id].ProjectID != tt.id {
+				t.Fatalf("expected task in project %d, got %d", tt.id, tasks[id].ProjectID)
+			}
+
+			if tt.expectedErr != nil && len(tasks) != 5 {
+				t.Fatalf("expected no task created, got %d tasks", len(tasks))
+			}
+		})
+	}
+}
+
+func TestGetProjectTasks(t *testing.T) {
+	u := newProjectUsecase(newProjectTasks(), newProjects(), "")
+
+	tasks, err := u.GetProjectTasks(context.Background(), 1, model.TaskFilter{SortBy: model.SortByID})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ids := make([]int, 0, len(tasks))
+	for _, task := range tasks {
+		ids = append(ids, task.ID)
+	}
+
+	if !slices.Equal(ids, []int{1, 2}) {
+		t.Fatalf("expected tasks [1 2], got %v", ids)
+	}
+
+	if _, err := u.GetProjectTasks(context.Background(), 42, model.TaskFilter{}); !errors.Is(err, usecase.ErrProjectNotFound) {
+		t.Fatalf("expected ErrProjectNotFound, got %v", err)
+	}
+}
+
+func TestRestoreTaskOfDeletedProject(t *testing.T) {
+	tasks := newProjectTasks()
+	projects := newProjects()
+
+	now := time.Now().UTC()
+	tasks[3].DeletedAt = &now
+	delete(projects, 2)
+
+	u := usecase.NewTaskUsecase(graphRepo(tasks), &mock.MockRevisionRepo{}, projectRepo(projects), &mock.MockCommentRepo{}, usecase.Rules{}, logger.NewMockLogger())
+
+	task, err := u.RestoreTask(context.Background(), 3)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if task.ProjectID != 0 || tasks[3].ProjectID != 0 {
+		t.Fatalf("expected restored task out of projects, got %d", tasks[3].ProjectID)
+	}
+}
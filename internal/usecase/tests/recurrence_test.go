@@ -0,0 +1,231 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/solumD/tasks-service/internal/model"
+	"github.com/solumD/tasks-service/internal/usecase"
+	"github.com/solumD/tasks-service/internal/usecase/mock"
+	"github.com/solumD/tasks-service/pkg/logger"
+)
+
+func TestRecurrenceValidation(t *testing.T) {
+	due := time.Date(2026, time.March, 2, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name               string
+		task               *model.Task
+		expectedErr        error
+		expectedRecurrence string
+	}{
+		{
+			name:               "canonical rule",
+			task:               &model.Task{Title: "A", DueAt: &due, Recurrence: "rrule:byday=fr,mo;freq=weekly"},
+			expectedRecurrence: "FREQ=WEEKLY;BYDAY=MO,FR",
+		},
+		{
+			name:        "invalid rule",
+			task:        &model.Task{Title: "A", DueAt: &due, Recurrence: "FREQ=YEARLY"},
+			expectedErr: usecase.ErrInvalidRecurrence,
+		},
+		{
+			name:        "without due date",
+			task:        &model.Task{Title: "A", Recurrence: "FREQ=DAILY"},
+			expectedErr: usecase.ErrRecurrenceWithoutDue,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := usecase.NewTaskUsecase(treeRepo(map[int]*model.Task{}), &mock.MockRevisionRepo{}, &mock.MockProjectRepo{}, &mock.MockCommentRepo{}, usecase.Rules{}, logger.NewMockLogger())
+
+			_, err := u.CreateTask(context.Background(), tt.task)
+			if !errors.Is(err, tt.expectedErr) {
+				t.Fatalf("expected error %v, got %v", tt.expectedErr, err)
+			}
+
+			if err == nil && tt.task.Recurrence != tt.expectedRecurrence {
+				t.Fatalf("expected recurrence %q, got %q", tt.expectedRecurrence, tt.task.Recurrence)
+			}
+		})
+	}
+}
+
+func TestRecurrenceRollOver(t *testing.T) {
+	due := time.Date(2026, time.March, 6, 18, 0, 0, 0, time.FixedZone("", 3*60*60))
+
+	tests := []struct {
+		name               string
+		recurrence         string
+		done               bool
+		expectedNext       time.Time
+		expectedRecurrence string
+	}{
+		{
+			name:               "next weekday",
+			recurrence:         "FREQ=WEEKLY;BYDAY=MO,FR",
+			done:               true,
+			expectedNext:       due.AddDate(0, 0, 3),
+			expectedRecurrence: "FREQ=WEEKLY;BYDAY=MO,FR",
+		},
+		{
+			name:               "count decremented",
+			recurrence:         "FREQ=DAILY;COUNT=3",
+			done:               true,
+			expectedNext:       due.AddDate(0, 0, 1),
+			expectedRecurrence: "FREQ=DAILY;COUNT=2",
+		},
+		{
+			name:       "last occurrence",
+			recurrence: "FREQ=DAILY;COUNT=1",
+			done:       true,
+		},
+		{
+			name:       "until passed",
+			recurrence: "FREQ=DAILY;UNTIL=20260306T235959Z",
+			done:       true,
+		},
+		{
+			name:       "not done",
+			recurrence: "FREQ=DAILY",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			tasks := map[int]*model.Task{
+				1: {ID: 1, Title: "Report", DueAt: &due, Priority: model.PriorityHigh, Tags: []string{"work"},
+					DependsOn: []int{2}, Recurrence: tt.recurrence, Version: 1},
+				2: {ID: 2, Title: "Data", Done: true, Version: 1},
+			}
+			u := usecase.NewTaskUsecase(treeRepo(tasks), &mock.MockRevisionRepo{}, &mock.MockProjectRepo{}, &mock.MockCommentRepo{}, usecase.Rules{}, logger.NewMockLogger())
+
+			task := tasks[1].Clone()
+			task.Done = tt.done
+
+			if err := u.UpdateTask(ctx, task); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if tt.done && tasks[1].Recurrence != "" {
+				t.Fatalf("expected completed task to lose its rule, got %q", tasks[1].Recurrence)
+			}
+
+			next, ok := tasks[3]
+			if tt.expectedNext.IsZero() {
+				if ok {
+					t.Fatalf("expected no next occurrence, got %+v", next)
+				}
+
+				return
+			}
+
+			if !ok {
+				t.Fatal("expected next occurrence to be created")
+			}
+
+			if next.Done || next.Title != "Report" || next.Priority != model.PriorityHigh || !slices.Equal(next.Tags, []string{"work"}) {
+				t.Fatalf("expected undone copy of the task, got %+v", next)
+			}
+
+			if len(next.DependsOn) != 0 {
+				t.Fatalf("expected next occurrence without dependencies, got %v", next.DependsOn)
+			}
+
+			if next.DueAt == nil || !next.DueAt.Equal(tt.expectedNext) {
+				t.Fatalf("expected due date %v, got %v", tt.expectedNext, next.DueAt)
+			}
+
+			if next.Recurrence != tt.expectedRecurrence {
+				t.Fatalf("expected recurrence %q, got %q", tt.expectedRecurrence, next.Recurrence)
+			}
+		})
+	}
+}
+
+func TestRecurrenceRollOverOnce(t *testing.T) {
+	ctx := context.Background()
+	due := time.Date(2026, time.March, 6, 18, 0, 0, 0, time.UTC)
+	tasks := map[int]*model.Task{
+		1: {ID: 1, Title: "Report", DueAt: &due, Recurrence: "FREQ=DAILY", Version: 1},
+	}
+	u := usecase.NewTaskUsecase(treeRepo(tasks), &mock.MockRevisionRepo{}, &mock.MockProjectRepo{}, &mock.MockCommentRepo{}, usecase.Rules{}, logger.NewMockLogger())
+
+	stale := tasks[1].Clone()
+	stale.Version = 0
+	stale.Done = true
+
+	first := stale.Clone()
+	if err := u.UpdateTask(ctx, first); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// повторная отметка по устаревшему снимку не создает второе повторение
+	second := stale.Clone()
+	if err := u.UpdateTask(ctx, second); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(tasks) != 2 {
+		t.Fatalf("expected one next occurrence, got %d tasks", len(tasks))
+	}
+
+	if tasks[1].Recurrence != "" {
+		t.Fatalf("expected completed task to stay without rule, got %q", tasks[1].Recurrence)
+	}
+}
+
+func TestGetOccurrences(t *testing.T) {
+	due := time.Date(2026, time.March, 31, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		task        *model.Task
+		count       int
+		expected    []time.Time
+		expectedErr error
+	}{
+		{
+			name:  "monthly",
+			task:  &model.Task{ID: 1, Title: "Rent", DueAt: &due, Recurrence: "FREQ=MONTHLY;BYMONTHDAY=-1"},
+			count: 2,
+			expected: []time.Time{
+				time.Date(2026, time.April, 30, 9, 0, 0, 0, time.UTC),
+				time.Date(2026, time.May, 31, 9, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name:     "not recurring",
+			task:     &model.Task{ID: 1, Title: "Once", DueAt: &due},
+			count:    5,
+			expected: []time.Time{},
+		},
+		{
+			name:        "invalid count",
+			task:        &model.Task{ID: 1, Title: "Rent", DueAt: &due, Recurrence: "FREQ=DAILY"},
+			count:       101,
+			expectedErr: usecase.ErrInvalidOccurrences,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tasks := map[int]*model.Task{1: tt.task}
+			u := usecase.NewTaskUsecase(treeRepo(tasks), &mock.MockRevisionRepo{}, &mock.MockProjectRepo{}, &mock.MockCommentRepo{}, usecase.Rules{}, logger.NewMockLogger())
+
+			got, err := u.GetOccurrences(context.Background(), 1, tt.count)
+			if !errors.Is(err, tt.expectedErr) {
+				t.Fatalf("expected error %v, got %v", tt.expectedErr, err)
+			}
+
+			if err == nil && !slices.EqualFunc(got, tt.expected, time.Time.Equal) {
+				t.Fatalf("expected occurrences %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,216 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/solumD/tasks-service/internal/model"
+	"github.com/solumD/tasks-service/internal/usecase"
+	"github.com/solumD/tasks-service/internal/usecase/mock"
+	"github.com/solumD/tasks-service/pkg/logger"
+)
+
+func TestMutationsRecordRevisions(t *testing.T) {
+	repo := &mock.MockTaskRepo{
+		CreateTaskFunc: func(ctx context.Context, task *model.Task) (int, error) {
+			task.ID, task.Version = 1, 1
+			return 1, nil
+		},
+		GetTaskByIDFunc: func(ctx context.Context, id int) (*model.Task, error) {
+			return &model.Task{ID: id, Title: "Task1", Version: 1}, nil
+		},
+		UpdateTaskFunc: func(ctx context.Context, task *model.Task) error {
+			task.Version = 2
+			return nil
+		},
+		RestoreTaskFunc: func(ctx context.Context, id int) (*model.Task, error) {
+			return &model.Task{ID: id, Title: "Task1", Version: 4}, nil
+		},
+	}
+	revisions := &mock.MockRevisionRepo{
+		AddRevisionFunc: func(ctx context.Context, rev *model.Revision) error {
+			return errors.New("db error")
+		},
+	}
+
+	u := usecase.NewTaskUsecase(repo, revisions, &mock.MockProjectRepo{}, &mock.MockCommentRepo{}, usecase.Rules{}, logger.NewMockLogger())
+	ctx := usecase.ContextWithAuthor(context.Background(), "alice")
+
+	if _, err := u.CreateTask(ctx, &model.Task{Title: "Task1"}); err != nil {
+		t.Fatalf("expected revision error not to fail create, got %v", err)
+	}
+
+	if err := u.UpdateTask(ctx, &model.Task{ID: 1, Title: "Task1 updated"}); err != nil {
+		t.Fatalf("failed to update task: %v", err)
+	}
+
+	if err := u.DeleteTask(ctx, 1, 0); err != nil {
+		t.Fatalf("failed to delete task: %v", err)
+	}
+
+	if _, err := u.RestoreTask(ctx, 1); err != nil {
+		t.Fatalf("failed to restore task: %v", err)
+	}
+
+	expected := []struct {
+		rev    int
+		action model.RevisionAction
+	}{
+		{rev: 1, action: model.RevisionCreated},
+		{rev: 2, action: model.RevisionUpdated},
+		{rev: 0, action: model.RevisionDeleted},
+		{rev: 4, action: model.RevisionRestored},
+	}
+
+	if len(revisions.AddRevisionRevisions) != len(expected) {
+		t.Fatalf("expected %d revisions, got %d", len(expected), len(revisions.AddRevisionRevisions))
+	}
+
+	for i, rev := range revisions.AddRevisionRevisions {
+		if rev.Action != expected[i].action || rev.Author != "alice" || rev.TaskID != 1 {
+			t.Fatalf("unexpected revision %d: %+v", i, rev)
+		}
+
+		// мок DeleteTask не выставляет версию, поэтому ее номер не проверяется
+		if expected[i].rev != 0 && rev.Rev != expected[i].rev {
+			t.Fatalf("expected revision %d to have rev %d, got %d", i, expected[i].rev, rev.Rev)
+		}
+	}
+}
+
+func TestGetTaskHistory(t *testing.T) {
+	tests := []struct {
+		name        string
+		revisions   []*model.Revision
+		expectedErr error
+	}{
+		{
+			name:        "unknown task",
+			revisions:   nil,
+			expectedErr: usecase.ErrTaskNotFound,
+		},
+		{
+			name:      "success",
+			revisions: []*model.Revision{{TaskID: 1, Rev: 1}, {TaskID: 1, Rev: 2}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			revisions := &mock.MockRevisionRepo{
+				GetRevisionsFunc: func(ctx context.Context, taskID int) ([]*model.Revision, error) {
+					return tt.revisions, nil
+				},
+			}
+
+			u := usecase.NewTaskUsecase(&mock.MockTaskRepo{}, revisions, &mock.MockProjectRepo{}, &mock.MockCommentRepo{}, usecase.Rules{}, logger.NewMockLogger())
+
+			history, err := u.GetTaskHistory(context.Background(), 1)
+			if !errors.Is(err, tt.expectedErr) {
+				t.Fatalf("expected error %v, got %v", tt.expectedErr, err)
+			}
+
+			if err == nil && len(history) != len(tt.revisions) {
+				t.Fatalf("expected %d revisions, got %d", len(tt.revisions), len(history))
+			}
+		})
+	}
+}
+
+func TestDiffTaskRevisions(t *testing.T) {
+	deletedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	// один и тот же срок в разных часовых поясах изменением не считается
+	dueAt := time.Date(2024, 5, 2, 12, 0, 0, 0, time.UTC)
+	dueAtMoscow := dueAt.In(time.FixedZone("MSK", 3*60*60))
+	stored := map[int]*model.Revision{
+		1: {TaskID: 1, Rev: 1, Task: model.Task{Title: "A", Description: "Desc", DueAt: &dueAt}},
+		2: {TaskID: 1, Rev: 2, Task: model.Task{Title: "B", Description: "Desc", Done: true, DueAt: &dueAtMoscow, Priority: model.PriorityHigh, Tags: []string{"home"}, DependsOn: []int{3}, DeletedAt: &deletedAt}},
+	}
+
+	revisions := &mock.MockRevisionRepo{
+		GetRevisionFunc: func(ctx context.Context, taskID int, rev int) (*model.Revision, error) {
+			if r, ok := stored[rev]; ok {
+				return r, nil
+			}
+
+			return nil, usecase.ErrRevisionNotFound
+		},
+	}
+
+	u := usecase.NewTaskUsecase(&mock.MockTaskRepo{}, revisions, &mock.MockProjectRepo{}, &mock.MockCommentRepo{}, usecase.Rules{}, logger.NewMockLogger())
+
+	changes, err := u.DiffTaskRevisions(context.Background(), 1, 1, 2)
+	if err != nil {
+		t.Fatalf("failed to diff revisions: %v", err)
+	}
+
+	expected := []model.FieldChange{
+		{Field: "title", From: "A", To: "B"},
+		{Field: "done", From: false, To: true},
+		{Field: "status", From: model.StatusTodo, To: model.StatusDone},
+		{Field: "priority", From: "none", To: "high"},
+		{Field: "tags", From: []string{}, To: []string{"home"}},
+		{Field: "depends_on", From: []int{}, To: []int{3}},
+		{Field: "deleted", From: false, To: true},
+	}
+
+	if len(changes) != len(expected) {
+		t.Fatalf("expected %d changes, got %+v", len(expected), changes)
+	}
+
+	for i := range expected {
+		if !reflect.DeepEqual(changes[i], expected[i]) {
+			t.Fatalf("expected change %+v, got %+v", expected[i], changes[i])
+		}
+	}
+
+	if _, err := u.DiffTaskRevisions(context.Background(), 1, 1, 3); !errors.Is(err, usecase.ErrRevisionNotFound) {
+		t.Fatalf("expected ErrRevisionNotFound, got %v", err)
+	}
+}
+
+func TestRevertTask(t *testing.T) {
+	dueAt := time.Date(2024, 5, 2, 12, 0, 0, 0, time.UTC)
+
+	repo := &mock.MockTaskRepo{
+		GetTaskByIDFunc: func(ctx context.Context, id int) (*model.Task, error) {
+			return &model.Task{ID: id, Title: "New", Version: 3}, nil
+		},
+		UpdateTaskFunc: func(ctx context.Context, task *model.Task) error {
+			if task.Version != 3 {
+				return usecase.ErrVersionConflict
+			}
+
+			task.Version = 4
+			return nil
+		},
+	}
+	revisions := &mock.MockRevisionRepo{
+		GetRevisionFunc: func(ctx context.Context, taskID int, rev int) (*model.Revision, error) {
+			return &model.Revision{TaskID: taskID, Rev: rev, Task: model.Task{Title: "Old", Description: "Old desc", DueAt: &dueAt}}, nil
+		},
+	}
+
+	u := usecase.NewTaskUsecase(repo, revisions, &mock.MockProjectRepo{}, &mock.MockCommentRepo{}, usecase.Rules{}, logger.NewMockLogger())
+
+	if _, err := u.RevertTask(context.Background(), 1, 1, 2); !errors.Is(err, usecase.ErrVersionConflict) {
+		t.Fatalf("expected ErrVersionConflict, got %v", err)
+	}
+
+	task, err := u.RevertTask(context.Background(), 1, 1, 3)
+	if err != nil {
+		t.Fatalf("failed to revert task: %v", err)
+	}
+
+	if task.Title != "Old" || task.Description != "Old desc" || task.DueAt == nil || !task.DueAt.Equal(dueAt) || task.Version != 4 {
+		t.Fatalf("unexpected reverted task: %+v", task)
+	}
+
+	last := revisions.AddRevisionRevisions[len(revisions.AddRevisionRevisions)-1]
+	if last.Action != model.RevisionReverted || last.Rev != 4 {
+		t.Fatalf("expected reverted revision 4, got %+v", last)
+	}
+}
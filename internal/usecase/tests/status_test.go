@@ -0,0 +1,229 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/solumD/tasks-service/internal/model"
+	"github.com/solumD/tasks-service/internal/usecase"
+	"github.com/solumD/tasks-service/internal/usecase/mock"
+	"github.com/solumD/tasks-service/pkg/logger"
+)
+
+func TestTransitionTask(t *testing.T) {
+	tests := []struct {
+		name            string
+		from            model.Status
+		done            bool
+		to              model.Status
+		version         int
+		workflow        model.Workflow
+		expectedErr     error
+		expectedStatus  model.Status
+		expectedVersion int
+	}{
+		{name: "start", from: model.StatusTodo, to: model.StatusInProgress, expectedStatus: model.StatusInProgress, expectedVersion: 2},
+		{name: "legacy task", to: model.StatusInProgress, expectedStatus: model.StatusInProgress, expectedVersion: 2},
+		{name: "legacy done task", done: true, to: model.StatusTodo, expectedStatus: model.StatusTodo, expectedVersion: 2},
+		{name: "with version", from: model.StatusReview, to: model.StatusDone, version: 1, expectedStatus: model.StatusDone, expectedVersion: 2},
+		{name: "same status", from: model.StatusReview, to: model.StatusReview, expectedStatus: model.StatusReview, expectedVersion: 1},
+		{name: "not allowed", from: model.StatusDone, to: model.StatusReview, expectedErr: usecase.ErrInvalidTransition},
+		{name: "unknown status", from: model.StatusTodo, to: "later", expectedErr: usecase.ErrInvalidStatus},
+		{name: "stale version", from: model.StatusTodo, to: model.StatusInProgress, version: 3, expectedErr: usecase.ErrVersionConflict},
+		{
+			name:        "custom workflow",
+			from:        model.StatusTodo,
+			to:          model.StatusDone,
+			workflow:    model.Workflow{model.StatusTodo: {model.StatusInProgress}, model.StatusInProgress: {model.StatusDone}},
+			expectedErr: usecase.ErrInvalidTransition,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tasks := map[int]*model.Task{
+				1: {ID: 1, Title: "A", Status: tt.from, Done: tt.done || tt.from == model.StatusDone, Version: 1},
+			}
+			u := usecase.NewTaskUsecase(treeRepo(tasks), &mock.MockRevisionRepo{}, &mock.MockProjectRepo{}, &mock.MockCommentRepo{},
+				usecase.Rules{Workflow: tt.workflow}, logger.NewMockLogger())
+
+			task, err := u.TransitionTask(context.Background(), 1, tt.to, tt.version)
+			if !errors.Is(err, tt.expectedErr) {
+				t.Fatalf("expected error %v, got %v", tt.expectedErr, err)
+			}
+
+			if err != nil {
+				return
+			}
+
+			if task.CurrentStatus() != tt.expectedStatus || task.Version != tt.expectedVersion {
+				t.Fatalf("expected status %s in version %d, got %s in version %d",
+					tt.expectedStatus, tt.expectedVersion, task.CurrentStatus(), task.Version)
+			}
+
+			if stored := tasks[1]; stored.CurrentStatus() != tt.expectedStatus || stored.Done != (tt.expectedStatus == model.StatusDone) {
+				t.Fatalf("expected stored status %s, got %s with done = %v", tt.expectedStatus, stored.CurrentStatus(), stored.Done)
+			}
+		})
+	}
+}
+
+func TestUpdateTaskStatus(t *testing.T) {
+	tests := []struct {
+		name           string
+		from           model.Status
+		update         *model.Task
+		expectedErr    error
+		expectedStatus model.Status
+	}{
+		{
+			name:           "done flag completes task",
+			from:           model.StatusInProgress,
+			update:         &model.Task{ID: 1, Title: "A", Done: true},
+			expectedStatus: model.StatusDone,
+		},
+		{
+			name:           "status kept without done flag",
+			from:           model.StatusReview,
+			update:         &model.Task{ID: 1, Title: "Renamed"},
+			expectedStatus: model.StatusReview,
+		},
+		{
+			name:           "done flag reopens task",
+			from:           model.StatusDone,
+			update:         &model.Task{ID: 1, Title: "A"},
+			expectedStatus: model.StatusTodo,
+		},
+		{
+			name:           "status wins over done flag",
+			from:           model.StatusTodo,
+			update:         &model.Task{ID: 1, Title: "A", Done: true, Status: model.StatusInProgress},
+			expectedStatus: model.StatusInProgress,
+		},
+		{
+			name:        "transition not allowed",
+			from:        model.StatusDone,
+			update:      &model.Task{ID: 1, Title: "A", Status: model.StatusReview},
+			expectedErr: usecase.ErrInvalidTransition,
+		},
+		{
+			name:        "done flag not allowed",
+			from:        model.StatusBlocked,
+			update:      &model.Task{ID: 1, Title: "A", Done: true},
+			expectedErr: usecase.ErrInvalidTransition,
+		},
+		{
+			name:        "unknown status",
+			from:        model.StatusTodo,
+			update:      &model.Task{ID: 1, Title: "A", Status: "later"},
+			expectedErr: usecase.ErrInvalidStatus,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tasks := map[int]*model.Task{
+				1: {ID: 1, Title: "A", Status: tt.from, Done: tt.from == model.StatusDone, Version: 1},
+			}
+			u := usecase.NewTaskUsecase(treeRepo(tasks), &mock.MockRevisionRepo{}, &mock.MockProjectRepo{}, &mock.MockCommentRepo{}, usecase.Rules{}, logger.NewMockLogger())
+
+			err := u.UpdateTask(context.Background(), tt.update)
+			if !errors.Is(err, tt.expectedErr) {
+				t.Fatalf("expected error %v, got %v", tt.expectedErr, err)
+			}
+
+			if err != nil {
+				if tasks[1].Version != 1 {
+					t.Fatalf("expected task unchanged, got version %d", tasks[1].Version)
+				}
+
+				return
+			}
+
+			if stored := tasks[1]; stored.Status != tt.expectedStatus || stored.Done != (tt.expectedStatus == model.StatusDone) {
+				t.Fatalf("expected status %s, got %s with done = %v", tt.expectedStatus, stored.Status, stored.Done)
+			}
+		})
+	}
+}
+
+func TestCreateTaskStatus(t *testing.T) {
+	tests := []struct {
+		name           string
+		task           *model.Task
+		expectedStatus model.Status
+		expectedDone   bool
+	}{
+		{name: "default", task: &model.Task{Title: "A"}, expectedStatus: model.StatusTodo},
+		{name: "from done flag", task: &model.Task{Title: "A", Done: true}, expectedStatus: model.StatusDone, expectedDone: true},
+		{name: "any initial status", task: &model.Task{Title: "A", Status: model.StatusReview}, expectedStatus: model.StatusReview},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tasks := map[int]*model.Task{}
+			u := usecase.NewTaskUsecase(treeRepo(tasks), &mock.MockRevisionRepo{}, &mock.MockProjectRepo{}, &mock.MockCommentRepo{}, usecase.Rules{}, logger.NewMockLogger())
+
+			id, err := u.CreateTask(context.Background(), tt.task)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if stored := tasks[id]; stored.Status != tt.expectedStatus || stored.Done != tt.expectedDone {
+				t.Fatalf("expected status %s with done = %v, got %s with done = %v",
+					tt.expectedStatus, tt.expectedDone, stored.Status, stored.Done)
+			}
+		})
+	}
+}
+
+func TestAutoCompleteParentStatus(t *testing.T) {
+	tests := []struct {
+		name           string
+		parent         model.Status
+		sibling        model.Status
+		expectedStatus model.Status
+	}{
+		{name: "cancelled sibling", parent: model.StatusInProgress, sibling: model.StatusCancelled, expectedStatus: model.StatusDone},
+		{name: "open sibling", parent: model.StatusInProgress, sibling: model.StatusReview, expectedStatus: model.StatusInProgress},
+		{name: "not allowed by workflow", parent: model.StatusBlocked, sibling: model.StatusDone, expectedStatus: model.StatusBlocked},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tasks := map[int]*model.Task{
+				1: {ID: 1, Title: "Parent", Status: tt.parent, Version: 1},
+				2: {ID: 2, Title: "Child", ParentID: 1, Status: model.StatusInProgress, Version: 1},
+				3: {ID: 3, Title: "Sibling", ParentID: 1, Status: tt.sibling, Done: tt.sibling == model.StatusDone, Version: 1},
+			}
+			u := usecase.NewTaskUsecase(treeRepo(tasks), &mock.MockRevisionRepo{}, &mock.MockProjectRepo{}, &mock.MockCommentRepo{},
+				usecase.Rules{Hierarchy: usecase.Hierarchy{AutoComplete: true}}, logger.NewMockLogger())
+
+			if _, err := u.TransitionTask(context.Background(), 2, model.StatusDone, 0); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if tasks[1].CurrentStatus() != tt.expectedStatus {
+				t.Fatalf("expected parent status %s, got %s", tt.expectedStatus, tasks[1].CurrentStatus())
+			}
+		})
+	}
+}
+
+func TestCancelledDependencyNotBlocking(t *testing.T) {
+	tasks := newGraph()
+	tasks[2].Status = model.StatusCancelled
+
+	u := usecase.NewTaskUsecase(graphRepo(tasks), &mock.MockRevisionRepo{}, &mock.MockProjectRepo{}, &mock.MockCommentRepo{},
+		usecase.Rules{Dependencies: usecase.Dependencies{BlockDone: true}}, logger.NewMockLogger())
+
+	task, err := u.TransitionTask(context.Background(), 3, model.StatusDone, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if task.Blocked || !task.Done {
+		t.Fatalf("expected done task not blocked, got %+v", task)
+	}
+}
@@ -0,0 +1,239 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/solumD/tasks-service/internal/model"
+	"github.com/solumD/tasks-service/internal/usecase"
+	"github.com/solumD/tasks-service/internal/usecase/mock"
+	"github.com/solumD/tasks-service/pkg/logger"
+)
+
+func TestCreateTaskNormalizesTags(t *testing.T) {
+	tests := []struct {
+		name         string
+		tags         []string
+		expectedTags []string
+		expectedErr  error
+	}{
+		{name: "no tags", tags: nil, expectedTags: nil},
+		{name: "empty list", tags: []string{}, expectedTags: nil},
+		{name: "trim, lower, dedup and sort", tags: []string{" Work", "home", "WORK "}, expectedTags: []string{"home", "work"}},
+		{name: "empty tag", tags: []string{"home", " "}, expectedErr: usecase.ErrInvalidTag},
+		{name: "comma", tags: []string{"home,work"}, expectedErr: usecase.ErrInvalidTag},
+		{name: "too long", tags: []string{strings.Repeat("я", 65)}, expectedErr: usecase.ErrInvalidTag},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mock.MockTaskRepo{}
+			u := usecase.NewTaskUsecase(repo, &mock.MockRevisionRepo{}, &mock.MockProjectRepo{}, &mock.MockCommentRepo{}, usecase.Rules{}, logger.NewMockLogger())
+
+			task := &model.Task{Title: "Task1", Tags: tt.tags}
+
+			_, err := u.CreateTask(context.Background(), task)
+			if !errors.Is(err, tt.expectedErr) {
+				t.Fatalf("expected error %v, got %v", tt.expectedErr, err)
+			}
+
+			if repo.CreateTaskCalled != (tt.expectedErr == nil) {
+				t.Fatalf("expected repo called = %v, got %v", tt.expectedErr == nil, repo.CreateTaskCalled)
+			}
+
+			if tt.expectedErr == nil && !slices.Equal(task.Tags, tt.expectedTags) {
+				t.Fatalf("expected tags %v, got %v", tt.expectedTags, task.Tags)
+			}
+
+			if tt.expectedErr == nil && tt.expectedTags == nil && task.Tags != nil {
+				t.Fatalf("expected nil tags, got %#v", task.Tags)
+			}
+		})
+	}
+}
+
+func TestGetAllTasksByTags(t *testing.T) {
+	now := time.Now().UTC()
+	past := now.Add(-time.Hour)
+	future := now.Add(time.Hour)
+
+	repoTasks := []*model.Task{
+		{ID: 3, Title: "C", DueAt: &future},
+		{ID: 1, Title: "A", DueAt: &past},
+		{ID: 2, Title: "B"},
+		{ID: 4, Title: "D", DueAt: &past, Done: true},
+	}
+
+	tests := []struct {
+		name         string
+		filter       model.TaskFilter
+		expectedIDs  []int
+		expectedTags []string
+		expectedErr  error
+	}{
+		{
+			name:         "any",
+			filter:       model.TaskFilter{Tags: []string{"Work", "home"}},
+			expectedIDs:  []int{1, 2, 3, 4},
+			expectedTags: []string{"home", "work"},
+		},
+		{
+			name:         "with due range",
+			filter:       model.TaskFilter{Tags: []string{"home"}, AllTags: true, DueBefore: now},
+			expectedIDs:  []int{1, 4},
+			expectedTags: []string{"home"},
+		},
+		{
+			name:         "with overdue",
+			filter:       model.TaskFilter{Tags: []string{"home"}, Overdue: true},
+			expectedIDs:  []int{1},
+			expectedTags: []string{"home"},
+		},
+		{
+			name:        "invalid tag",
+			filter:      model.TaskFilter{Tags: []string{"home", ""}},
+			expectedErr: usecase.ErrInvalidTag,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mock.MockTaskRepo{
+				GetTasksByTagsFunc: func(ctx context.Context, tags []string, all bool) ([]*model.Task, error) {
+					return slices.Clone(repoTasks), nil
+				},
+			}
+
+			u := usecase.NewTaskUsecase(repo, &mock.MockRevisionRepo{}, &mock.MockProjectRepo{}, &mock.MockCommentRepo{}, usecase.Rules{}, logger.NewMockLogger())
+
+			tasks, err := u.GetAllTasks(context.Background(), tt.filter)
+			if !errors.Is(err, tt.expectedErr) {
+				t.Fatalf("expected error %v, got %v", tt.expectedErr, err)
+			}
+
+			if tt.expectedErr != nil {
+				if repo.GetTasksByTagsCalled {
+					t.Fatal("expected GetTasksByTags not called with invalid tag")
+				}
+
+				return
+			}
+
+			if repo.GetAllTasksCalled || repo.GetTasksByDueCalled {
+				t.Fatal("expected only GetTasksByTags called with tag filter")
+			}
+
+			if !slices.Equal(repo.GetTasksByTagsTags, tt.expectedTags) || repo.GetTasksByTagsAll != tt.filter.AllTags {
+				t.Fatalf("expected tags %v (all %v), got %v (all %v)",
+					tt.expectedTags, tt.filter.AllTags, repo.GetTasksByTagsTags, repo.GetTasksByTagsAll)
+			}
+
+			ids := make([]int, 0, len(tasks))
+			for _, task := range tasks {
+				ids = append(ids, task.ID)
+			}
+
+			if !slices.Equal(ids, tt.expectedIDs) {
+				t.Fatalf("expected tasks %v, got %v", tt.expectedIDs, ids)
+			}
+		})
+	}
+}
+
+func TestRenameTag(t *testing.T) {
+	tests := []struct {
+		name            string
+		from, to        string
+		repoErr         error
+		expectedErr     error
+		expectedCalled  bool
+		expectedFrom    string
+		expectedTo      string
+		expectedRenamed int
+	}{
+		{
+			name:            "normalized",
+			from:            " Home",
+			to:              "HOUSE",
+			expectedCalled:  true,
+			expectedFrom:    "home",
+			expectedTo:      "house",
+			expectedRenamed: 2,
+		},
+		{
+			name:           "same tag",
+			from:           "home",
+			to:             " Home ",
+			expectedCalled: false,
+		},
+		{
+			name:        "invalid target",
+			from:        "home",
+			to:          "a,b",
+			expectedErr: usecase.ErrInvalidTag,
+		},
+		{
+			name:           "repo error",
+			from:           "home",
+			to:             "house",
+			repoErr:        errors.New("db error"),
+			expectedErr:    errors.New("db error"),
+			expectedCalled: true,
+			expectedFrom:   "home",
+			expectedTo:     "house",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mock.MockTaskRepo{
+				RenameTagFunc: func(ctx context.Context, from, to string) ([]*model.Task, error) {
+					if tt.repoErr != nil {
+						return nil, tt.repoErr
+					}
+
+					return []*model.Task{
+						{ID: 1, Title: "A", Tags: []string{to}, Version: 2},
+						{ID: 3, Title: "C", Tags: []string{to, "work"}, Version: 5},
+					}, nil
+				},
+			}
+			revisions := &mock.MockRevisionRepo{}
+
+			u := usecase.NewTaskUsecase(repo, revisions, &mock.MockProjectRepo{}, &mock.MockCommentRepo{}, usecase.Rules{}, logger.NewMockLogger())
+
+			renamed, err := u.RenameTag(context.Background(), tt.from, tt.to)
+			if (err != nil && tt.expectedErr == nil) || (err == nil && tt.expectedErr != nil) ||
+				(err != nil && tt.expectedErr != nil && err.Error() != tt.expectedErr.Error()) {
+				t.Fatalf("expected error %v, got %v", tt.expectedErr, err)
+			}
+
+			if repo.RenameTagCalled != tt.expectedCalled {
+				t.Fatalf("expected repo called = %v, got %v", tt.expectedCalled, repo.RenameTagCalled)
+			}
+
+			if tt.expectedCalled && (repo.RenameTagFrom != tt.expectedFrom || repo.RenameTagTo != tt.expectedTo) {
+				t.Fatalf("expected rename %q -> %q, got %q -> %q", tt.expectedFrom, tt.expectedTo, repo.RenameTagFrom, repo.RenameTagTo)
+			}
+
+			if len(renamed) != tt.expectedRenamed {
+				t.Fatalf("expected %d renamed tasks, got %d", tt.expectedRenamed, len(renamed))
+			}
+
+			// каждая измененная задача получает ревизию
+			if len(revisions.AddRevisionRevisions) != tt.expectedRenamed {
+				t.Fatalf("expected %d revisions, got %d", tt.expectedRenamed, len(revisions.AddRevisionRevisions))
+			}
+
+			for i, rev := range revisions.AddRevisionRevisions {
+				if rev.Action != model.RevisionUpdated || rev.TaskID != renamed[i].ID || rev.Rev != renamed[i].Version {
+					t.Fatalf("unexpected revision %d: %+v", i, rev)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/solumD/tasks-service/internal/model"
+	"github.com/solumD/tasks-service/internal/usecase"
+	"github.com/solumD/tasks-service/internal/usecase/mock"
+	"github.com/solumD/tasks-service/pkg/logger"
+)
+
+func TestGetDeletedTasksSortedByID(t *testing.T) {
+	repo := &mock.MockTaskRepo{
+		GetDeletedTasksFunc: func(ctx context.Context) ([]*model.Task, error) {
+			return []*model.Task{{ID: 3}, {ID: 1}, {ID: 2}}, nil
+		},
+	}
+
+	u := usecase.NewTaskUsecase(repo, &mock.MockRevisionRepo{}, &mock.MockProjectRepo{}, &mock.MockCommentRepo{}, usecase.Rules{}, logger.NewMockLogger())
+
+	tasks, err := u.GetDeletedTasks(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for i, task := range tasks {
+		if task.ID != i+1 {
+			t.Fatalf("expected tasks sorted by id, got %d at position %d", task.ID, i)
+		}
+	}
+}
+
+func TestRestoreTask(t *testing.T) {
+	tests := []struct {
+		name        string
+		restoreFunc func(ctx context.Context, id int) (*model.Task, error)
+		expectedErr error
+	}{
+		{
+			name: "task not in trash",
+			restoreFunc: func(ctx context.Context, id int) (*model.Task, error) {
+				return nil, usecase.NewTaskNotFoundError(id)
+			},
+			expectedErr: usecase.ErrTaskNotFound,
+		},
+		{
+			name: "success",
+			restoreFunc: func(ctx context.Context, id int) (*model.Task, error) {
+				return &model.Task{ID: id}, nil
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mock.MockTaskRepo{
+				RestoreTaskFunc: tt.restoreFunc,
+			}
+
+			u := usecase.NewTaskUsecase(repo, &mock.MockRevisionRepo{}, &mock.MockProjectRepo{}, &mock.MockCommentRepo{}, usecase.Rules{}, logger.NewMockLogger())
+
+			task, err := u.RestoreTask(context.Background(), 1)
+			if !errors.Is(err, tt.expectedErr) {
+				t.Fatalf("expected error %v, got %v", tt.expectedErr, err)
+			}
+
+			if err == nil && task.ID != 1 {
+				t.Fatalf("expected restored task 1, got %+v", task)
+			}
+
+			if repo.RestoreTaskID != 1 {
+				t.Fatalf("expected RestoreTask(1), got RestoreTask(%d)", repo.RestoreTaskID)
+			}
+		})
+	}
+}
+
+func TestPurgeDeletedTasks(t *testing.T) {
+	repo := &mock.MockTaskRepo{
+		PurgeDeletedTasksFunc: func(ctx context.Context, deletedBefore time.Time) (int, error) {
+			return 2, nil
+		},
+	}
+
+	u := usecase.NewTaskUsecase(repo, &mock.MockRevisionRepo{}, &mock.MockProjectRepo{}, &mock.MockCommentRepo{}, usecase.Rules{}, logger.NewMockLogger())
+
+	before := time.Now().Add(-time.Hour)
+
+	purged, err := u.PurgeDeletedTasks(context.Background(), time.Hour)
+	if err != nil || purged != 2 {
+		t.Fatalf("expected 2 purged tasks, got %d (err %v)", purged, err)
+	}
+
+	after := time.Now().Add(-time.Hour)
+
+	if cutoff := repo.PurgeDeletedTasksBefore; cutoff.Before(before) || cutoff.After(after) {
+		t.Fatalf("expected cutoff between %v and %v, got %v", before, after, cutoff)
+	}
+}
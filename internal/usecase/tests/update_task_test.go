@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/solumD/tasks-service/internal/model"
 	"github.com/solumD/tasks-service/internal/usecase"
@@ -12,61 +13,70 @@ import (
 )
 
 func TestUpdateTask(t *testing.T) {
+	invalidDueAt := time.Date(10000, time.January, 1, 0, 0, 0, 0, time.UTC)
+
 	tests := []struct {
 		name                 string
 		task                 *model.Task
-		existFunc            func(ctx context.Context, id int) (bool, error)
 		updateFunc           func(ctx context.Context, task *model.Task) error
 		expectedErr          error
-		expectedExistCalled  bool
 		expectedUpdateCalled bool
 	}{
 		{
 			name: "task not exist",
 			task: &model.Task{ID: 1, Title: "Task1"},
-			existFunc: func(ctx context.Context, id int) (bool, error) {
-				return false, nil
+			updateFunc: func(ctx context.Context, task *model.Task) error {
+				return usecase.NewTaskNotFoundError(task.ID)
 			},
-			updateFunc:           nil,
 			expectedErr:          usecase.ErrTaskNotFound,
-			expectedExistCalled:  true,
-			expectedUpdateCalled: false,
+			expectedUpdateCalled: true,
 		},
 		{
-			name: "empty title",
-			task: &model.Task{ID: 1, Title: ""},
-			existFunc: func(ctx context.Context, id int) (bool, error) {
-				return true, nil
-			},
+			name:                 "empty title",
+			task:                 &model.Task{ID: 1, Title: ""},
 			updateFunc:           nil,
 			expectedErr:          usecase.ErrEmptyTitle,
-			expectedExistCalled:  true,
 			expectedUpdateCalled: false,
 		},
+		{
+			name:                 "due date out of range",
+			task:                 &model.Task{ID: 1, Title: "Task1", DueAt: &invalidDueAt},
+			updateFunc:           nil,
+			expectedErr:          usecase.ErrInvalidDueDate,
+			expectedUpdateCalled: false,
+		},
+		{
+			name:                 "unknown priority",
+			task:                 &model.Task{ID: 1, Title: "Task1", Priority: "critical"},
+			updateFunc:           nil,
+			expectedErr:          usecase.ErrInvalidPriority,
+			expectedUpdateCalled: false,
+		},
+		{
+			name: "version conflict",
+			task: &model.Task{ID: 1, Title: "Task1", Version: 2},
+			updateFunc: func(ctx context.Context, task *model.Task) error {
+				return usecase.ErrVersionConflict
+			},
+			expectedErr:          usecase.ErrVersionConflict,
+			expectedUpdateCalled: true,
+		},
 		{
 			name: "repo error on update",
 			task: &model.Task{ID: 1, Title: "Task1"},
-			existFunc: func(ctx context.Context, id int) (bool, error) {
-				return true, nil
-			},
 			updateFunc: func(ctx context.Context, task *model.Task) error {
 				return errors.New("db update error")
 			},
 			expectedErr:          errors.New("db update error"),
-			expectedExistCalled:  true,
 			expectedUpdateCalled: true,
 		},
 		{
 			name: "success",
 			task: &model.Task{ID: 1, Title: "Task1"},
-			existFunc: func(ctx context.Context, id int) (bool, error) {
-				return true, nil
-			},
 			updateFunc: func(ctx context.Context, task *model.Task) error {
 				return nil
 			},
 			expectedErr:          nil,
-			expectedExistCalled:  true,
 			expectedUpdateCalled: true,
 		},
 	}
@@ -74,23 +84,21 @@ func TestUpdateTask(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			repo := &mock.MockTaskRepo{
-				IsTaskExistByIDFunc: tt.existFunc,
-				UpdateTaskFunc:      tt.updateFunc,
+				GetTaskByIDFunc: func(ctx context.Context, id int) (*model.Task, error) {
+					return &model.Task{ID: id, Title: "Task1", Version: 2}, nil
+				},
+				UpdateTaskFunc: tt.updateFunc,
 			}
 
 			log := logger.NewMockLogger()
-			u := usecase.NewTaskUsecase(repo, log)
+			u := usecase.NewTaskUsecase(repo, &mock.MockRevisionRepo{}, &mock.MockProjectRepo{}, &mock.MockCommentRepo{}, usecase.Rules{}, log)
 			err := u.UpdateTask(context.Background(), tt.task)
 
 			if (err != nil && tt.expectedErr == nil) || (err == nil && tt.expectedErr != nil) ||
-				(err != nil && tt.expectedErr != nil && err.Error() != tt.expectedErr.Error()) {
+				(err != nil && tt.expectedErr != nil && !errors.Is(err, tt.expectedErr) && err.Error() != tt.expectedErr.Error()) {
 				t.Fatalf("expected error %v, got %v", tt.expectedErr, err)
 			}
 
-			if repo.IsTaskExistByIDCalled != tt.expectedExistCalled {
-				t.Fatalf("expected IsTaskExistByID called = %v, got %v", tt.expectedExistCalled, repo.IsTaskExistByIDCalled)
-			}
-
 			if repo.UpdateTaskCalled != tt.expectedUpdateCalled {
 				t.Fatalf("expected UpdateTask called = %v, got %v", tt.expectedUpdateCalled, repo.UpdateTaskCalled)
 			}
@@ -2,6 +2,7 @@ package httpserver
 
 import (
 	"context"
+	"errors"
 	"log"
 	"net/http"
 	"time"
@@ -31,7 +32,7 @@ func New(addr string, router http.Handler) *server {
 
 func (s *server) Run() {
 	go func() {
-		if err := s.httpServer.ListenAndServe(); err != nil {
+		if err := s.httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 			log.Fatalf("%v\n", err)
 		}
 	}()
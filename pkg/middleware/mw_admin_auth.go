@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+// NewMWAdminAuth возвращает middleware, который пропускает только запросы с заголовком
+// Authorization: Bearer token. При пустом token эндпоинт выключен и отвечает 404
+func NewMWAdminAuth(token string, log *slog.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		logger := log.With(
+			slog.String("component", "middleware/admin_auth"),
+		)
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if token == "" {
+				logger.Info("rejected request to disabled admin endpoint",
+					slog.String("method", r.Method),
+					slog.String("path", r.URL.Path),
+				)
+
+				http.NotFound(w, r)
+				return
+			}
+
+			got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+				logger.Info("rejected unauthorized admin request",
+					slog.String("method", r.Method),
+					slog.String("path", r.URL.Path),
+				)
+
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
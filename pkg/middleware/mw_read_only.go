@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+// NewMWReadOnly возвращает middleware, который обрабатывает только запросы на чтение,
+// а остальные перенаправляет на тот же путь по адресу leaderURL. Код 307 сохраняет
+// метод и тело запроса
+func NewMWReadOnly(leaderURL string, log *slog.Logger) func(http.Handler) http.Handler {
+	leaderURL = strings.TrimRight(leaderURL, "/")
+
+	return func(next http.Handler) http.Handler {
+		logger := log.With(
+			slog.String("component", "middleware/read_only"),
+		)
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions:
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			location := leaderURL + r.URL.RequestURI()
+
+			logger.Info("redirected write request to leader",
+				slog.String("method", r.Method),
+				slog.String("path", r.URL.Path),
+				slog.String("location", location),
+			)
+
+			http.Redirect(w, r, location, http.StatusTemporaryRedirect)
+		})
+	}
+}
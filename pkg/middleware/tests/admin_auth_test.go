@@ -0,0 +1,50 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/solumD/tasks-service/pkg/logger"
+	"github.com/solumD/tasks-service/pkg/middleware"
+)
+
+func TestAdminAuth(t *testing.T) {
+	tests := []struct {
+		name          string
+		token         string
+		authorization string
+		expStatus     int
+	}{
+		{name: "valid token", token: "secret", authorization: "Bearer secret", expStatus: http.StatusOK},
+		{name: "wrong token", token: "secret", authorization: "Bearer other", expStatus: http.StatusUnauthorized},
+		{name: "no header", token: "secret", expStatus: http.StatusUnauthorized},
+		{name: "not bearer", token: "secret", authorization: "Basic secret", expStatus: http.StatusUnauthorized},
+		{name: "disabled", token: "", authorization: "Bearer ", expStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			called := false
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				called = true
+			})
+
+			req := httptest.NewRequest(http.MethodPost, "/admin/restore", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+
+			rec := httptest.NewRecorder()
+			middleware.NewMWAdminAuth(tt.token, logger.NewMockLogger())(next).ServeHTTP(rec, req)
+
+			if rec.Code != tt.expStatus {
+				t.Fatalf("expected status %d, got %d", tt.expStatus, rec.Code)
+			}
+
+			if called != (tt.expStatus == http.StatusOK) {
+				t.Fatalf("unexpected call of next handler: %v", called)
+			}
+		})
+	}
+}